@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"pryx-core/internal/config"
+	"pryx-core/internal/store"
+)
+
+func runDB(args []string) int {
+	if len(args) < 1 {
+		dbUsage()
+		return 2
+	}
+
+	cmd := args[0]
+	cfg := config.Load()
+
+	switch cmd {
+	case "compact":
+		return runDBCompact(args[1:], cfg)
+	case "help", "-h", "--help":
+		dbUsage()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
+		dbUsage()
+		return 2
+	}
+}
+
+// runDBCompact compacts the database. A running runtime is asked to do it, so
+// the compaction is coordinated with its own use of the database; otherwise
+// the database file is compacted directly.
+func runDBCompact(args []string, cfg *config.Config) int {
+	jsonOutput := false
+	for _, arg := range args {
+		if arg == "--json" || arg == "-j" {
+			jsonOutput = true
+		}
+	}
+
+	result, viaRuntime, err := compactViaRuntime(cfg)
+	if err == nil && !viaRuntime {
+		result, err = compactDirectly(cfg)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to compact database: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+		return 0
+	}
+	fmt.Printf("✓ Database compacted in %s\n", (time.Duration(result.DurationMs) * time.Millisecond).String())
+	fmt.Printf("  before:    %s\n", formatBytes(result.SizeBefore))
+	fmt.Printf("  after:     %s\n", formatBytes(result.SizeAfter))
+	fmt.Printf("  reclaimed: %s\n", formatBytes(result.Reclaimed))
+	return 0
+}
+
+// compactViaRuntime asks the running runtime to compact its database. It
+// reports false when no runtime answers.
+func compactViaRuntime(cfg *config.Config) (store.CompactResult, bool, error) {
+	var result store.CompactResult
+	client := &http.Client{Timeout: 30 * time.Minute}
+	resp, err := client.Post(runtimeBaseURL(cfg)+"/api/admin/db/compact", "application/json", nil)
+	if err != nil {
+		return result, false, nil
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+			return result, true, fmt.Errorf("runtime: %s", apiErr.Error)
+		}
+		return result, true, fmt.Errorf("runtime: %s", strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return result, true, fmt.Errorf("invalid response from runtime: %w", err)
+	}
+	return result, true, nil
+}
+
+func compactDirectly(cfg *config.Config) (store.CompactResult, error) {
+	s, err := store.New(cfg.DatabasePath)
+	if err != nil {
+		return store.CompactResult{}, fmt.Errorf("failed to initialize store: %w", err)
+	}
+	defer s.Close()
+	return s.Compact(context.Background())
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func dbUsage() {
+	fmt.Println("pryx-core db - Maintain the runtime database")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  compact                                Reclaim space left by deleted rows")
+	fmt.Println("")
+	fmt.Println("Options:")
+	fmt.Println("  --json, -j                             Output the result as JSON")
+	fmt.Println("")
+	fmt.Println("The running runtime compacts its own database when it is reachable;")
+	fmt.Println("otherwise the database file is compacted directly. Set db_compact_schedule")
+	fmt.Println("to compact on a cron schedule.")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  pryx-core db compact")
+	fmt.Println("  pryx-core db compact --json")
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"pryx-core/internal/config"
+	"pryx-core/internal/debugbundle"
+	"pryx-core/internal/doctor"
+	"pryx-core/internal/keychain"
+	"pryx-core/internal/store"
+)
+
+func runDebug(args []string) int {
+	if len(args) < 1 || args[0] != "bundle" {
+		debugUsage()
+		return 2
+	}
+	if len(args) != 2 {
+		fmt.Println("Usage: pryx-core debug bundle <path>")
+		return 2
+	}
+	return runDebugBundle(args[1])
+}
+
+func debugUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  pryx-core debug bundle <path>   Write a redacted support bundle (zip) to path")
+}
+
+// runDebugBundle collects diagnostics without a running runtime and writes
+// them to path. Parts that cannot be collected are noted in the bundle.
+func runDebugBundle(path string) int {
+	cfg := config.Load()
+	kc := keychain.New("pryx")
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	b := &debugbundle.Bundle{
+		Version: debugbundle.NewVersionInfo(Version, BuildDate),
+		Config:  cfg,
+	}
+	b.Doctor, _ = doctor.Run(ctx, cfg, kc)
+
+	if s, err := store.New(cfg.DatabasePath); err != nil {
+		b.AddProblem("database", err)
+	} else {
+		b.CollectStore(s)
+		s.Close()
+	}
+
+	b.CollectMCP(ctx, nil)
+
+	if configs, err := loadChannels(); err != nil {
+		b.AddProblem("channels", err)
+	} else {
+		for _, c := range configs {
+			b.Channels = append(b.Channels, debugbundle.ChannelStatus{
+				ID:      c.ID,
+				Type:    c.Type,
+				Name:    c.Name,
+				Enabled: c.Enabled,
+			})
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", path, err)
+		return 1
+	}
+	if err := b.Write(f); err != nil {
+		f.Close()
+		fmt.Fprintf(os.Stderr, "Failed to write debug bundle: %v\n", err)
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write debug bundle: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Debug bundle written to %s\n", path)
+	fmt.Println("Secrets have been removed; review it before sharing.")
+	return 0
+}
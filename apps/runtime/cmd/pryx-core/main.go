@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,19 +14,21 @@ import (
 
 	"pryx-core/internal/agent"
 	"pryx-core/internal/agent/spawn"
+	"pryx-core/internal/agent/summary"
 	"pryx-core/internal/auth"
 	"pryx-core/internal/bus"
 	"pryx-core/internal/channels"
-	channelsSlack "pryx-core/internal/channels/slack"
-	"pryx-core/internal/channels/telegram"
 	"pryx-core/internal/config"
 	"pryx-core/internal/constraints"
 	"pryx-core/internal/doctor"
 	"pryx-core/internal/keychain"
+	"pryx-core/internal/llm/providers"
 	"pryx-core/internal/mesh"
 	"pryx-core/internal/models"
 	"pryx-core/internal/performance"
+	"pryx-core/internal/secrets"
 	"pryx-core/internal/server"
+	"pryx-core/internal/skills"
 	"pryx-core/internal/store"
 	"pryx-core/internal/telemetry"
 )
@@ -47,7 +50,9 @@ func main() {
 		case "mcp":
 			os.Exit(runMCP(os.Args[2:]))
 		case "doctor":
-			os.Exit(runDoctor())
+			os.Exit(runDoctor(os.Args[2:]))
+		case "debug":
+			os.Exit(runDebug(os.Args[2:]))
 		case "cost":
 			os.Exit(runCost(os.Args[2:]))
 		case "config":
@@ -58,6 +63,10 @@ func main() {
 			os.Exit(runChannel(os.Args[2:]))
 		case "session":
 			os.Exit(runSession(os.Args[2:]))
+		case "scheduler":
+			os.Exit(runScheduler(os.Args[2:]))
+		case "db":
+			os.Exit(runDB(os.Args[2:]))
 		case "login":
 			os.Exit(runLogin())
 		case "install-service":
@@ -86,6 +95,12 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	configureClients(cfg)
+	if wl := openProviderWireLog(cfg.ProviderWireLog); wl != nil {
+		providers.ConfigureWireLog(wl)
+		defer wl.Close()
+	}
+
 	// Initialize store (database)
 	var s *store.Store
 	if err := profiler.TimeFunc("store.init", func() error {
@@ -102,6 +117,7 @@ func main() {
 		log.Fatalf("Failed to initialize store: %v", err)
 	}
 	defer s.Close()
+	checkStoreIntegrity(s, cfg.RepairOrphansOnStartup)
 
 	var memProfiler *performance.MemoryProfiler
 	if cfg.EnableMemoryProfiling {
@@ -147,7 +163,6 @@ func main() {
 
 	// Load models catalog (may be slow - load async)
 	profiler.StartPhase("models.load")
-	var catalog *models.Catalog
 	catalogLoaded := make(chan *models.Catalog, 1)
 	go func() {
 		modelsService := models.NewService()
@@ -158,9 +173,8 @@ func main() {
 			catalogLoaded <- nil
 			return
 		}
-		catalog = cat
-		catalogLoaded <- catalog
-		log.Printf("Loaded %d providers and %d models from catalog", len(catalog.Providers), len(catalog.Models))
+		catalogLoaded <- cat
+		log.Printf("Loaded %d providers and %d models from catalog", len(cat.Providers), len(cat.Models))
 		profiler.EndPhase("models.load", nil)
 	}()
 
@@ -171,10 +185,7 @@ func main() {
 	var srv *server.Server
 	if err := profiler.TimeFunc("server.init", func() error {
 		srv = server.New(cfg, s.DB, kc)
-		// Set catalog if already loaded (rare race condition)
-		if catalog != nil {
-			srv.SetCatalog(catalog)
-		}
+		srv.SetBuildInfo(Version, BuildDate)
 		return nil
 	}); err != nil {
 		log.Fatalf("Failed to initialize server: %v", err)
@@ -190,16 +201,25 @@ func main() {
 		srv.Scheduler().Stop()
 	}()
 
-	// Wait for catalog to load after server starts and update it
+	// Hand the catalog to the server once loaded; warm-up waits for this load
+	// instead of starting its own.
+	catalogDone := make(chan struct{})
+	srv.SetCatalogPending(catalogDone)
 	go func() {
+		defer close(catalogDone)
 		select {
 		case cat := <-catalogLoaded:
 			if cat != nil {
 				srv.SetCatalog(cat)
 				log.Printf("Catalog updated on server after async load")
 			}
+			return
 		case <-time.After(5 * time.Second):
-			log.Printf("Catalog load timed out, continuing without it")
+			log.Printf("Catalog load is slow, continuing without it for now")
+		}
+		if cat := <-catalogLoaded; cat != nil {
+			srv.SetCatalog(cat)
+			log.Printf("Catalog updated on server after async load")
 		}
 	}()
 
@@ -212,22 +232,33 @@ func main() {
 	}()
 	defer meshMgr.Stop()
 
-	// Initialize channels
-	var chanMgr *channels.ChannelManager
+	// Initialize channels on the server's manager so channels from the config
+	// file and channels managed through the API share one registry.
+	chanMgr := srv.Channels()
+	resolver := secrets.NewResolver(kc)
 	profiler.TimeFunc("channels.init", func() error {
-		chanMgr = channels.NewManager(b)
 		if cfg.TelegramEnabled && cfg.TelegramToken != "" {
 			log.Println("Starting Telegram Bot...")
-			tg := telegram.NewTelegramChannel("telegram-main", cfg.TelegramToken, b)
-			if err := chanMgr.Register(tg); err != nil {
-				log.Printf("Failed to register Telegram: %v", err)
+			if tg, err := newTelegramChannel(cfg, resolver, b); err != nil {
+				log.Printf("Failed to resolve Telegram token: %v", err)
+			} else {
+				registerStartupChannel(chanMgr, tg)
 			}
 		}
 		if cfg.SlackEnabled && cfg.SlackAppToken != "" && cfg.SlackBotToken != "" {
 			log.Println("Starting Slack App...")
-			slackCh := channelsSlack.NewSlackChannel("slack-main", cfg.SlackBotToken, cfg.SlackAppToken, b)
-			if err := chanMgr.Register(slackCh); err != nil {
-				log.Printf("Failed to register Slack: %v", err)
+			if sl, err := newSlackChannel(cfg, resolver, b); err != nil {
+				log.Printf("Failed to start Slack: %v", err)
+			} else {
+				registerStartupChannel(chanMgr, sl)
+			}
+		}
+		if cfg.MattermostEnabled && cfg.MattermostURL != "" && cfg.MattermostToken != "" {
+			log.Println("Starting Mattermost bot...")
+			if mm, err := newMattermostChannel(cfg, resolver, b); err != nil {
+				log.Printf("Failed to start Mattermost: %v", err)
+			} else {
+				registerStartupChannel(chanMgr, mm)
 			}
 		}
 		return nil
@@ -239,12 +270,29 @@ func main() {
 	var agt *agent.Agent
 	go func() {
 		var err error
-		agt, err = agent.New(cfg, b, kc, catalog, srv.Skills(), srv.MCP(), srv.Agents(), srv.Memory())
+		agt, err = agent.New(cfg, b, kc, srv.Catalog(), srv.Skills(), srv.MCP(), srv.Agents(), srv.Memory())
 		if err != nil {
 			log.Printf("Warning: Failed to initialize Agent: %v", err)
 			profiler.EndPhase("agent.init", err)
 			return
 		}
+		agt.SetGreeter(channels.NewGreeter(cfg.ChannelGreetings, s))
+		if inbound, err := channels.NewInboundFilter(cfg.ChannelFilters); err != nil {
+			log.Printf("Warning: Ignoring channel filters: %v", err)
+		} else {
+			agt.SetInboundFilter(inbound)
+		}
+		agt.SetBudgetCheck(s.CheckSessionBudget)
+		agt.SetSessionModelStore(s)
+		srv.SetGenerationLimiter(agt.Generations())
+		srv.SetSessionActivity(agt.Activity())
+		srv.SetModelLimiter(agt.RateLimits())
+		srv.SetModelSettings(agt.Models())
+		srv.OnCatalog(agt.SetCatalog)
+		skillBridges := skills.NewUnifiedManagerFor(srv.Skills(), skills.DefaultOptions())
+		skillBridges.SetExecutionObserver(srv.SkillExecutionObserver())
+		agt.RegisterTool(skills.NewTool(skillBridges))
+		agt.RegisterTool(summary.NewTool(srv.Summarizer()))
 		log.Println("Starting AI Agent...")
 		go agt.Run(context.Background())
 		profiler.EndPhase("agent.init", nil)
@@ -292,6 +340,12 @@ func main() {
 		}
 	}()
 
+	// Warm the MCP tool cache and model catalog in the background so the first
+	// chat does not pay for cold caches.
+	warmupCtx, warmupCancel := context.WithCancel(context.Background())
+	defer warmupCancel()
+	go srv.Warmup(warmupCtx)
+
 	// Start JSON-RPC bridge for host communication
 	startRPCServer(context.Background(), srv)
 	// Give server a moment to start, then mark phase complete
@@ -305,10 +359,23 @@ func main() {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-	// Wait for shutdown signal or server error
+	// SIGHUP reloads the configuration in place, keeping connections open.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		applied := *cfg
+		for range hup {
+			applied = reloadRuntime(context.Background(), srv, resolver, applied)
+		}
+	}()
+
+	// Wait for shutdown signal, idle timeout or server error
 	select {
 	case <-stop:
 		log.Println("Shutting down (received signal)...")
+	case <-srv.IdleShutdown():
+		log.Printf("Shutting down (idle for %s)...", cfg.IdleTimeout)
 	case err := <-serverErrCh:
 		log.Printf("Server error encountered: %v", err)
 		log.Println("Shutting down (server error)...")
@@ -316,6 +383,7 @@ func main() {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
+	warmupCancel()
 	schedulerCancel()
 	srv.Scheduler().Stop()
 	if err := srv.Shutdown(ctx); err != nil {
@@ -332,7 +400,9 @@ func usage() {
 	log.Println("  pryx-core mcp <filesystem|shell|browser|clipboard>")
 	log.Println("  pryx-core channel <command>")
 	log.Println("  pryx-core session <command>")
-	log.Println("  pryx-core doctor")
+	log.Println("  pryx-core scheduler <export|import>")
+	log.Println("  pryx-core db compact")
+	log.Println("  pryx-core doctor [--fix]")
 	log.Println("  pryx-core cost <command>")
 	log.Println("  pryx-core login")
 	log.Println("  pryx-core config <set|get|list>")
@@ -393,20 +463,92 @@ func usage() {
 	log.Println("    test <name>                          Test connection to provider")
 	log.Println("    oauth <provider>                     Authenticate via OAuth (Google)")
 	log.Println("")
-	log.Println("  doctor                               Run diagnostics")
+	log.Println("  doctor [--fix]                       Run diagnostics (--fix repairs orphaned records)")
+	log.Println("  debug bundle <path>                  Write a redacted support bundle (zip)")
 	log.Println("  login                                Log in to Pryx Cloud")
 	log.Println("  install-service                      Install as system service")
 	log.Println("  uninstall-service                    Remove system service")
 	log.Println("  help, -h, --help                    Show this help message")
 }
 
-func runDoctor() int {
+// checkStoreIntegrity reports orphaned database rows and, if repair is set, removes them.
+func checkStoreIntegrity(s *store.Store, repair bool) {
+	report, err := s.CheckIntegrity()
+	if err != nil {
+		log.Printf("Integrity check failed: %v", err)
+		return
+	}
+	if report.Total() == 0 {
+		return
+	}
+	if !repair {
+		log.Printf("Found orphaned records (%s); run `pryx-core doctor --fix` to repair", report)
+		return
+	}
+	repaired, err := s.RepairOrphans()
+	if err != nil {
+		log.Printf("Failed to repair orphaned records: %v", err)
+		return
+	}
+	log.Printf("Repaired orphaned records: %s", repaired)
+}
+
+// registerStartupChannel registers a channel from the config file. A channel
+// already registered under the same ID is left running rather than replaced.
+func registerStartupChannel(mgr *channels.ChannelManager, c channels.Channel) {
+	err := mgr.Register(c)
+	var dup *channels.DuplicateChannelError
+	switch {
+	case errors.As(err, &dup):
+		log.Printf("Channel %s is already registered, keeping the running instance", dup.ID)
+	case err != nil:
+		log.Printf("Failed to register channel %s: %v", c.ID(), err)
+	}
+}
+
+// openProviderWireLog opens the provider wire log when it is enabled. Failing to
+// open it is logged and leaves provider logging off.
+func openProviderWireLog(c config.ProviderWireLog) *providers.WireLog {
+	if !c.Enabled {
+		return nil
+	}
+	path := c.Path
+	if path == "" {
+		path = config.DefaultProviderWireLogPath()
+	}
+	wl, err := providers.OpenWireLog(providers.WireLogOptions{
+		Path:          path,
+		MaxBytes:      int64(c.MaxSizeMB) << 20,
+		MaxFiles:      c.MaxFiles,
+		RedactPrompts: c.RedactPrompts,
+		Providers:     c.Providers,
+	})
+	if err != nil {
+		log.Printf("Provider wire log disabled: %v", err)
+		return nil
+	}
+	log.Printf("Logging provider requests to %s (API keys removed)", path)
+	return wl
+}
+
+func runDoctor(args []string) int {
+	var opts doctor.Options
+	for _, arg := range args {
+		switch arg {
+		case "--fix":
+			opts.Fix = true
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown doctor option: %s\n", arg)
+			return 2
+		}
+	}
+
 	cfg := config.Load()
 	kc := keychain.New("pryx")
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	rep, exitCode := doctor.Run(ctx, cfg, kc)
+	rep, exitCode := doctor.RunWithOptions(ctx, cfg, kc, opts)
 	for _, c := range rep.Checks {
 		status := strings.ToUpper(string(c.Status))
 		if c.Detail != "" {
@@ -456,6 +598,10 @@ func runDoctor() int {
 
 func runLogin() int {
 	cfg := config.Load()
+	auth.ConfigureCloudRetry(auth.RetryPolicy{
+		MaxAttempts: cfg.CloudRetryMaxAttempts,
+		Budget:      cfg.CloudRetryBudget,
+	})
 	kc := keychain.New("pryx")
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -494,7 +640,7 @@ func runLogin() int {
 		log.Printf("\nLogin failed: %v", err)
 		return 1
 	case token := <-tokenCh:
-		if err := kc.Set("cloud_access_token", token.AccessToken); err != nil {
+		if err := auth.SaveCloudToken(kc, token); err != nil {
 			log.Printf("\nFailed to store token: %v", err)
 			return 1
 		}
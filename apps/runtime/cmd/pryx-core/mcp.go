@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"pryx-core/internal/config"
+	"pryx-core/internal/keychain"
 	"pryx-core/internal/mcp"
+	"pryx-core/internal/secrets"
 )
 
 func runMCP(args []string) int {
@@ -87,6 +92,9 @@ func runMCPList(args []string) int {
 				if server.Auth != nil {
 					fmt.Printf("  Auth: %s\n", server.Auth.Type)
 				}
+				if server.LastVerifiedAt != nil {
+					fmt.Printf("  Verified: %s (%d tools)\n", server.LastVerifiedAt.Local().Format(time.RFC3339), server.ToolCount)
+				}
 				fmt.Println()
 			}
 		}
@@ -108,6 +116,7 @@ func runMCPAdd(args []string) int {
 	var authType string
 	var authTokenRef string
 	transport := ""
+	verify := true
 
 	// Parse flags
 	i := 1
@@ -144,6 +153,9 @@ func runMCPAdd(args []string) int {
 			}
 			authTokenRef = args[i+1]
 			i += 2
+		case "--no-verify":
+			verify = false
+			i++
 		default:
 			fmt.Fprintf(os.Stderr, "Error: unknown flag: %s\n", arg)
 			mcpUsage()
@@ -160,6 +172,12 @@ func runMCPAdd(args []string) int {
 		return 2
 	}
 
+	policy := config.Load().MCPServerPolicy
+	if err := mcp.NewServerPolicy(policy.Allow, policy.Deny).Check(name, mcp.ServerConfig{URL: serverURL, Command: command}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
 	// Load existing config
 	cfg, _, err := mcp.LoadServersConfigFromFirstExisting(mcp.DefaultServersConfigPaths())
 	if err != nil {
@@ -179,6 +197,13 @@ func runMCPAdd(args []string) int {
 		Command:   command,
 	}
 
+	if authTokenRef != "" {
+		if err := secrets.NewResolver(keychain.New("pryx")).Validate(authTokenRef); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: --token-ref: %v\n", err)
+			return 1
+		}
+	}
+
 	if authType != "" {
 		serverCfg.Auth = &mcp.AuthConfig{
 			Type:     authType,
@@ -186,6 +211,17 @@ func runMCPAdd(args []string) int {
 		}
 	}
 
+	if verify {
+		fmt.Printf("Verifying MCP server: %s...\n", name)
+		toolCount, err := probeMCPServer(name, &serverCfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: server '%s' failed verification: %v\n", name, err)
+			fmt.Fprintf(os.Stderr, "Fix the configuration, or use --no-verify to save it anyway.\n")
+			return 1
+		}
+		fmt.Printf("✓ Server reachable, %d tools available\n", toolCount)
+	}
+
 	cfg.Servers[name] = serverCfg
 
 	// Save config
@@ -241,7 +277,7 @@ func runMCPTest(args []string) int {
 
 	name := args[0]
 
-	cfg, _, err := mcp.LoadServersConfigFromFirstExisting(mcp.DefaultServersConfigPaths())
+	cfg, path, err := mcp.LoadServersConfigFromFirstExisting(mcp.DefaultServersConfigPaths())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
 		return 1
@@ -256,26 +292,49 @@ func runMCPTest(args []string) int {
 	fmt.Printf("Testing MCP server: %s\n", name)
 	fmt.Println(strings.Repeat("=", 40))
 
-	// Test connection based on transport type
 	if server.URL != "" {
 		fmt.Printf("URL: %s\n", server.URL)
-		fmt.Println("(HTTP transport testing not implemented in CLI)")
-		fmt.Println("✓ Server configuration looks valid")
 	} else if len(server.Command) > 0 {
 		fmt.Printf("Command: %s\n", strings.Join(server.Command, " "))
-		fmt.Println("(Stdio transport testing requires runtime context)")
-		fmt.Println("✓ Server configuration looks valid")
 	} else if server.Transport == "bundled" {
-		fmt.Println("Bundled server - available when runtime starts")
-		fmt.Println("✓ Server configuration looks valid")
+		fmt.Println("Bundled server")
 	} else {
 		fmt.Println("✗ No valid transport configured")
 		return 1
 	}
 
+	toolCount, err := probeMCPServer(name, &server)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return 1
+	}
+	fmt.Printf("✓ Server reachable, %d tools available\n", toolCount)
+
+	if path == "" {
+		path = getDefaultMCPServerPath()
+	}
+	cfg.Servers[name] = server
+	if err := saveMCPServerConfig(path, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record verification: %v\n", err)
+	}
+
 	return 0
 }
 
+// probeMCPServer connects to the server, lists its tools and, on success,
+// records the verification time and tool count in sc.
+func probeMCPServer(name string, sc *mcp.ServerConfig) (int, error) {
+	mgr := mcp.NewManager(nil, nil, keychain.New("pryx"))
+	tools, err := mgr.Probe(context.Background(), name, *sc)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	sc.LastVerifiedAt = &now
+	sc.ToolCount = len(tools)
+	return len(tools), nil
+}
+
 func runMCPAuth(args []string) int {
 	if len(args) < 1 {
 		fmt.Fprintf(os.Stderr, "Error: server name required\n")
@@ -331,7 +390,8 @@ func mcpUsage() {
 	fmt.Println("  --url, -u <url>               Server URL (for HTTP transport)")
 	fmt.Println("  --cmd, -c <command>           Command (for stdio transport)")
 	fmt.Println("  --auth <type>                 Authentication type (bearer, basic)")
-	fmt.Println("  --token-ref <ref>             Token reference (keychain)")
+	fmt.Println("  --token-ref <ref>             Token reference (keychain://, env:// or file:///)")
+	fmt.Println("  --no-verify                   Save without connecting to the server first")
 	fmt.Println("  --json, -j                    Output in JSON format")
 }
 
@@ -2,13 +2,19 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"pryx-core/internal/config"
 	"pryx-core/internal/keychain"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/llm/factory"
 	"pryx-core/internal/models"
+	"pryx-core/internal/secrets"
 )
 
 // PopularProviders is a curated list of commonly used providers for UI prioritization
@@ -72,10 +78,10 @@ func runProvider(args []string) int {
 		return providerUse(args[1], cfg, path, kc)
 	case "test":
 		if len(args) < 2 {
-			fmt.Println("Usage: pryx-core provider test <name>")
+			fmt.Println("Usage: pryx-core provider test <name> [--stream] [--model <id>] [--timeout <duration>]")
 			return 1
 		}
-		return providerTest(args[1], cfg, kc)
+		return providerTest(args[1], args[2:], cfg, kc)
 	case "oauth":
 		if len(args) < 2 {
 			fmt.Println("Usage: pryx-core provider oauth <provider>")
@@ -100,12 +106,14 @@ func usageProvider() {
 	fmt.Println("  pryx-core provider remove <name>           Remove provider config")
 	fmt.Println("  pryx-core provider use <name>              Set as active/default provider")
 	fmt.Println("  pryx-core provider test <name>             Test connection to provider")
+	fmt.Println("  pryx-core provider test <name> --stream    Check that replies stream token by token")
 	fmt.Println("  pryx-core provider oauth <provider>        Authenticate via OAuth (Google)")
 	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("  pryx-core provider add openai")
 	fmt.Println("  pryx-core provider set-key anthropic")
 	fmt.Println("  pryx-core provider use groq")
+	fmt.Println("  pryx-core provider test openai --stream --model gpt-4o-mini")
 	fmt.Println("  pryx-core provider oauth google")
 	fmt.Println("")
 	fmt.Println("Note: Providers are loaded dynamically from models.dev (50+ providers supported)")
@@ -431,6 +439,13 @@ func providerSetKey(name string, kc *keychain.Keychain) int {
 		return 1
 	}
 
+	if secrets.IsRef(apiKey) {
+		if err := secrets.NewResolver(kc).Validate(apiKey); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+	}
+
 	if err := kc.SetProviderKey(name, apiKey); err != nil {
 		fmt.Printf("Error storing API key: %v\n", err)
 		return 1
@@ -491,7 +506,32 @@ func providerUse(name string, cfg *config.Config, path string, kc *keychain.Keyc
 	return 0
 }
 
-func providerTest(name string, cfg *config.Config, kc *keychain.Keychain) int {
+func providerTest(name string, args []string, cfg *config.Config, kc *keychain.Keychain) int {
+	stream := false
+	model := ""
+	timeout := defaultStreamTestTimeout
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--stream":
+			stream = true
+		case "--model":
+			if i+1 < len(args) {
+				model = args[i+1]
+				i++
+			}
+		case "--timeout":
+			if i+1 < len(args) {
+				d, err := time.ParseDuration(args[i+1])
+				if err != nil || d <= 0 {
+					fmt.Printf("Error: invalid timeout %q\n", args[i+1])
+					return 1
+				}
+				timeout = d
+				i++
+			}
+		}
+	}
+
 	// Validate provider exists in catalog
 	catalog, err := loadCatalog()
 	if err != nil {
@@ -537,5 +577,58 @@ func providerTest(name string, cfg *config.Config, kc *keychain.Keychain) int {
 		}
 	}
 
+	if stream {
+		return providerTestStream(name, model, timeout, catalog, cfg, kc)
+	}
+	return 0
+}
+
+// defaultStreamTestTimeout bounds a streaming test, so a provider that never
+// streams is reported instead of waited on.
+const defaultStreamTestTimeout = 30 * time.Second
+
+// providerTestStream sends a tiny prompt through the provider the agent would
+// build for name, with the key from the keychain, and reports how the reply
+// streamed back.
+func providerTestStream(name, model string, timeout time.Duration, catalog *models.Catalog, cfg *config.Config, kc *keychain.Keychain) int {
+	if model == "" {
+		if strings.EqualFold(cfg.ModelProvider, name) && cfg.ModelName != "" {
+			model = cfg.ModelName
+		} else if available := catalog.GetProviderModels(name); len(available) > 0 {
+			model = available[0].ID
+		} else {
+			fmt.Println("✗ No model to test with; pass --model <id>")
+			return 1
+		}
+	}
+
+	configureClients(cfg)
+	provider, err := factory.NewProviderFactory(catalog, kc).CreateProviderFromConfig(name, "")
+	if err != nil {
+		fmt.Printf("✗ Could not create provider: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("\nStreaming a test prompt to %s...\n", model)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	probe, err := llm.ProbeStream(ctx, provider, model)
+	if err != nil {
+		if errors.Is(err, llm.ErrNotStreaming) {
+			fmt.Printf("✗ Streaming not working: %v\n", err)
+			fmt.Println("  The provider accepted the request but did not send tokens as a stream.")
+		} else {
+			fmt.Printf("✗ Streaming test failed: %v\n", err)
+		}
+		return 1
+	}
+
+	fmt.Printf("✓ Streamed %d chunks\n", probe.Chunks)
+	fmt.Printf("  Time to first token: %s\n", probe.FirstToken.Round(time.Millisecond))
+	fmt.Printf("  Total latency:       %s\n", probe.Total.Round(time.Millisecond))
+	fmt.Printf("  Reply:               %q\n", strings.TrimSpace(probe.Content))
+	if !probe.Incremental() {
+		fmt.Println("⚠ The reply arrived in a single chunk; the provider or a proxy may be buffering the stream")
+	}
 	return 0
 }
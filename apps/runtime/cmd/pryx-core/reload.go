@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"log"
+	"reflect"
+
+	"pryx-core/internal/auth"
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+	"pryx-core/internal/channels/mattermost"
+	channelsSlack "pryx-core/internal/channels/slack"
+	"pryx-core/internal/channels/telegram"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm/providers"
+	"pryx-core/internal/secrets"
+	"pryx-core/internal/server"
+)
+
+const (
+	telegramChannelID   = "telegram-main"
+	slackChannelID      = "slack-main"
+	mattermostChannelID = "mattermost-main"
+)
+
+// newTelegramChannel builds the Telegram channel from the config file, or
+// returns nil if it is disabled.
+func newTelegramChannel(cfg *config.Config, resolver *secrets.Resolver, b *bus.Bus) (channels.Channel, error) {
+	if !cfg.TelegramEnabled || cfg.TelegramToken == "" {
+		return nil, nil
+	}
+	token, err := resolver.ResolveValue(cfg.TelegramToken)
+	if err != nil {
+		return nil, err
+	}
+	return telegram.NewTelegramChannel(telegramChannelID, token, b), nil
+}
+
+// newSlackChannel builds the Slack channel from the config file, or returns
+// nil if it is disabled.
+func newSlackChannel(cfg *config.Config, resolver *secrets.Resolver, b *bus.Bus) (channels.Channel, error) {
+	if !cfg.SlackEnabled || cfg.SlackAppToken == "" || cfg.SlackBotToken == "" {
+		return nil, nil
+	}
+	botToken, err := resolver.ResolveValue(cfg.SlackBotToken)
+	if err != nil {
+		return nil, err
+	}
+	appToken, err := resolver.ResolveValue(cfg.SlackAppToken)
+	if err != nil {
+		return nil, err
+	}
+	return channelsSlack.NewSlackChannel(slackChannelID, botToken, appToken, b), nil
+}
+
+// newMattermostChannel builds the Mattermost channel from the config file, or
+// returns nil if it is disabled.
+func newMattermostChannel(cfg *config.Config, resolver *secrets.Resolver, b *bus.Bus) (channels.Channel, error) {
+	if !cfg.MattermostEnabled || cfg.MattermostURL == "" || cfg.MattermostToken == "" {
+		return nil, nil
+	}
+	token, err := resolver.ResolveValue(cfg.MattermostToken)
+	if err != nil {
+		return nil, err
+	}
+	return mattermost.NewMattermostChannel(mattermostChannelID, cfg.MattermostURL, token, cfg.MattermostChannels, b), nil
+}
+
+// configureClients applies the config of the process-wide provider HTTP
+// client and cloud API retries.
+func configureClients(cfg *config.Config) {
+	providers.ConfigureSharedHTTPClient(providers.HTTPClientOptions{
+		ConnectTimeout:  cfg.LLMConnectTimeout,
+		ResponseTimeout: cfg.LLMResponseTimeout,
+		RequestTimeout:  cfg.LLMRequestTimeout,
+	})
+	auth.ConfigureCloudRetry(auth.RetryPolicy{
+		MaxAttempts: cfg.CloudRetryMaxAttempts,
+		Budget:      cfg.CloudRetryBudget,
+	})
+}
+
+// reloadRuntime re-reads the configuration and applies it in place on SIGHUP:
+// server config, skills and catalog, provider clients, and the channels from
+// the config file. Channels whose settings did not change keep their
+// connections. It returns the config now in effect, to compare against on the
+// next reload.
+func reloadRuntime(ctx context.Context, srv *server.Server, resolver *secrets.Resolver, prev config.Config) config.Config {
+	log.Println("Reloading configuration (received SIGHUP)...")
+	next := config.Load()
+
+	server.LogReloadReport(srv.Reload(ctx, next))
+
+	if next.LLMConnectTimeout != prev.LLMConnectTimeout ||
+		next.LLMResponseTimeout != prev.LLMResponseTimeout ||
+		next.LLMRequestTimeout != prev.LLMRequestTimeout ||
+		next.CloudRetryMaxAttempts != prev.CloudRetryMaxAttempts ||
+		next.CloudRetryBudget != prev.CloudRetryBudget {
+		configureClients(next)
+		log.Println("Reload: applied provider timeouts and cloud retry policy")
+	}
+
+	mgr := srv.Channels()
+	if next.TelegramEnabled != prev.TelegramEnabled || next.TelegramToken != prev.TelegramToken {
+		c, err := newTelegramChannel(next, resolver, srv.Bus())
+		resyncChannel(mgr, telegramChannelID, c, err)
+	}
+	if next.SlackEnabled != prev.SlackEnabled ||
+		next.SlackAppToken != prev.SlackAppToken ||
+		next.SlackBotToken != prev.SlackBotToken {
+		c, err := newSlackChannel(next, resolver, srv.Bus())
+		resyncChannel(mgr, slackChannelID, c, err)
+	}
+	if next.MattermostEnabled != prev.MattermostEnabled ||
+		next.MattermostURL != prev.MattermostURL ||
+		next.MattermostToken != prev.MattermostToken ||
+		!reflect.DeepEqual(next.MattermostChannels, prev.MattermostChannels) {
+		c, err := newMattermostChannel(next, resolver, srv.Bus())
+		resyncChannel(mgr, mattermostChannelID, c, err)
+	}
+	return *next
+}
+
+// resyncChannel replaces the channel registered under id with c, or removes
+// it if c is nil. If building c failed, the running channel is kept.
+func resyncChannel(mgr *channels.ChannelManager, id string, c channels.Channel, err error) {
+	switch {
+	case err != nil:
+		log.Printf("Reload: failed to rebuild channel %s, keeping the running instance: %v", id, err)
+	case c == nil:
+		if mgr.Unregister(id) {
+			log.Printf("Reload: channel %s disabled", id)
+		}
+	case mgr.Upsert(c):
+		log.Printf("Reload: channel %s restarted with new settings", id)
+	default:
+		log.Printf("Reload: channel %s started", id)
+	}
+}
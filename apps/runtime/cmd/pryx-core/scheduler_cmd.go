@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"pryx-core/internal/config"
+	"pryx-core/internal/scheduler"
+	"pryx-core/internal/server"
+	"pryx-core/internal/store"
+)
+
+func runScheduler(args []string) int {
+	if len(args) < 1 {
+		schedulerUsage()
+		return 2
+	}
+
+	cmd := args[0]
+	cfg := config.Load()
+
+	switch cmd {
+	case "export":
+		return runSchedulerExport(args[1:], cfg)
+	case "import":
+		return runSchedulerImport(args[1:], cfg)
+	case "run":
+		return runSchedulerRun(args[1:], cfg)
+	case "help", "-h", "--help":
+		schedulerUsage()
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
+		schedulerUsage()
+		return 2
+	}
+}
+
+func runSchedulerExport(args []string, cfg *config.Config) int {
+	outputFile := ""
+	for i, arg := range args {
+		if arg == "--output" && i+1 < len(args) {
+			outputFile = args[i+1]
+		}
+	}
+
+	s, err := store.New(cfg.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to initialize store: %v\n", err)
+		return 1
+	}
+	defer s.Close()
+
+	defs, err := scheduler.New(s.DB).ExportTasks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to export tasks: %v\n", err)
+		return 1
+	}
+
+	data, err := json.MarshalIndent(defs, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to marshal tasks: %v\n", err)
+		return 1
+	}
+
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, data, 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to write file: %v\n", err)
+			return 1
+		}
+		fmt.Printf("✓ Exported %d tasks to: %s\n", len(defs), outputFile)
+	} else {
+		fmt.Println(string(data))
+	}
+	return 0
+}
+
+func runSchedulerImport(args []string, cfg *config.Config) int {
+	inputFile := ""
+	preserveIDs := false
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "--preserve-ids":
+			preserveIDs = true
+		case "--json", "-j":
+			jsonOutput = true
+		default:
+			if inputFile == "" {
+				inputFile = arg
+			}
+		}
+	}
+	if inputFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: input file required\n")
+		return 2
+	}
+
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to read file: %v\n", err)
+		return 1
+	}
+	var defs []scheduler.TaskDefinition
+	if err := json.Unmarshal(data, &defs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid task file: %v\n", err)
+		return 1
+	}
+
+	s, err := store.New(cfg.DatabasePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to initialize store: %v\n", err)
+		return 1
+	}
+	defer s.Close()
+
+	result, err := scheduler.New(s.DB).ImportTasks(defs, scheduler.ImportOptions{PreserveIDs: preserveIDs})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to import tasks: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		fmt.Printf("✓ Imported %d of %d tasks\n", len(result.Created), len(defs))
+		for _, c := range result.Conflicts {
+			fmt.Printf("  conflict #%d %q: %s\n", c.Index, c.Name, c.Reason)
+		}
+		for _, v := range result.Invalid {
+			fmt.Printf("  invalid  #%d %q: %s\n", v.Index, v.Name, v.Reason)
+		}
+	}
+
+	if len(result.Conflicts) > 0 || len(result.Invalid) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runSchedulerRun asks the running runtime to execute a task now. Executors
+// live in the runtime, so the task cannot be run from the CLI process itself.
+func runSchedulerRun(args []string, cfg *config.Config) int {
+	taskID := ""
+	jsonOutput := false
+	for _, arg := range args {
+		switch arg {
+		case "--json", "-j":
+			jsonOutput = true
+		default:
+			if taskID == "" {
+				taskID = arg
+			}
+		}
+	}
+	if taskID == "" {
+		fmt.Fprintf(os.Stderr, "Error: task id required\n")
+		return 2
+	}
+
+	endpoint := runtimeBaseURL(cfg) + "/api/v1/scheduler/tasks/" + url.PathEscape(taskID) + "/run?wait=true"
+	client := &http.Client{Timeout: 35 * time.Minute}
+	resp, err := client.Post(endpoint, "application/json", nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to reach runtime (is it running?): %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", strings.TrimSpace(string(body)))
+		return 1
+	}
+
+	var run scheduler.TaskRun
+	if err := json.Unmarshal(body, &run); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid response from runtime: %v\n", err)
+		return 1
+	}
+
+	if jsonOutput {
+		out, _ := json.MarshalIndent(run, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		duration := ""
+		if run.CompletedAt != nil {
+			duration = fmt.Sprintf(" in %s", run.CompletedAt.Sub(run.StartedAt).Round(time.Millisecond))
+		}
+		fmt.Printf("Run %s: %s%s\n", run.ID, run.Status, duration)
+		if run.Error != "" {
+			fmt.Printf("  error:  %s\n", run.Error)
+		}
+		if run.Output != "" {
+			fmt.Printf("  output: %s\n", run.Output)
+		}
+	}
+
+	if run.Status != scheduler.RunStatusSuccess {
+		return 1
+	}
+	return 0
+}
+
+// runtimeBaseURL returns the address of the running runtime, preferring the
+// port it recorded on startup over the configured listen address.
+func runtimeBaseURL(cfg *config.Config) string {
+	if port, err := server.ReadPortFile(); err == nil && port > 0 {
+		return fmt.Sprintf("http://127.0.0.1:%d", port)
+	}
+	addr := strings.TrimSpace(cfg.ListenAddr)
+	if strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://") {
+		return strings.TrimRight(addr, "/")
+	}
+	if strings.HasPrefix(addr, ":") {
+		return "http://127.0.0.1" + addr
+	}
+	return "http://" + addr
+}
+
+func schedulerUsage() {
+	fmt.Println("pryx-core scheduler - Export, import and run scheduled tasks")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  export [--output <file>]               Export task definitions as JSON")
+	fmt.Println("  import <file> [--preserve-ids]         Recreate tasks from an export")
+	fmt.Println("  run <id>                               Run a task now on the running runtime")
+	fmt.Println("")
+	fmt.Println("Options:")
+	fmt.Println("  --output <file>                        Output file path (default: stdout)")
+	fmt.Println("  --preserve-ids                         Keep task IDs from the export")
+	fmt.Println("  --json, -j                             Output import or run result as JSON")
+	fmt.Println("")
+	fmt.Println("Examples:")
+	fmt.Println("  pryx-core scheduler export --output tasks.json")
+	fmt.Println("  pryx-core scheduler import tasks.json --preserve-ids")
+	fmt.Println("  pryx-core scheduler run 6f1c2a9e-...")
+}
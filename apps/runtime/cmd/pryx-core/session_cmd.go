@@ -91,8 +91,8 @@ func runSessionList(args []string, cfg *config.Config) int {
 
 				fmt.Printf("%d. %s\n", i+1, sess.Title)
 				fmt.Printf("   ID:        %s\n", sess.ID)
-				fmt.Printf("   Created:   %s\n", formatTime(sess.CreatedAt))
-				fmt.Printf("   Updated:   %s\n", formatTime(sess.UpdatedAt))
+				fmt.Printf("   Created:   %s\n", formatTime(cfg, sess, sess.CreatedAt))
+				fmt.Printf("   Updated:   %s\n", formatTime(cfg, sess, sess.UpdatedAt))
 				fmt.Printf("   Messages:  %d\n", msgCount)
 				fmt.Println()
 			}
@@ -134,8 +134,24 @@ func runSessionGet(args []string, cfg *config.Config) int {
 		return 1
 	}
 
+	var stats *store.SessionStats
+	if detailed {
+		stats, err = s.GetSessionStats(sess.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load session stats: %v\n", err)
+			return 1
+		}
+	}
+
 	if jsonOutput {
-		data, err := json.MarshalIndent(sess, "", "  ")
+		var out interface{} = sess
+		if stats != nil {
+			out = struct {
+				*store.Session
+				Stats *store.SessionStats `json:"stats"`
+			}{sess, stats}
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to marshal session: %v\n", err)
 			return 1
@@ -145,12 +161,21 @@ func runSessionGet(args []string, cfg *config.Config) int {
 		fmt.Printf("Session: %s\n", sess.Title)
 		fmt.Println(strings.Repeat("=", 40))
 		fmt.Printf("ID:        %s\n", sess.ID)
-		fmt.Printf("Created:   %s\n", formatTime(sess.CreatedAt))
-		fmt.Printf("Updated:   %s\n", formatTime(sess.UpdatedAt))
+		fmt.Printf("Created:   %s\n", formatTime(cfg, sess, sess.CreatedAt))
+		fmt.Printf("Updated:   %s\n", formatTime(cfg, sess, sess.UpdatedAt))
+		if sess.Timezone != "" {
+			fmt.Printf("Timezone:  %s\n", sess.Timezone)
+		}
 
-		if detailed {
-			msgCount, _ := s.GetMessageCount(sess.ID)
-			fmt.Printf("Messages:  %d\n", msgCount)
+		if stats != nil {
+			fmt.Printf("Messages:  %d\n", stats.MessageCount)
+			fmt.Printf("Tokens:    %d in / %d out (%d total)\n", stats.InputTokens, stats.OutputTokens, stats.TotalTokens)
+			fmt.Printf("Cost:      $%.4f\n", stats.TotalCost)
+			fmt.Printf("Tools:     %d calls\n", stats.ToolCalls)
+			if len(stats.Models) > 0 {
+				fmt.Printf("Models:    %s\n", strings.Join(stats.Models, ", "))
+			}
+			fmt.Printf("Duration:  %s\n", (time.Duration(stats.DurationMs) * time.Millisecond).Round(time.Second))
 		}
 	}
 
@@ -259,7 +284,7 @@ func runSessionExport(args []string, cfg *config.Config) int {
 
 	// Get messages for session
 	rows, err := s.DB.Query(`
-		SELECT id, role, content, created_at 
+		SELECT id, role, content, COALESCE(model, ''), COALESCE(provider, ''), created_at 
 		FROM messages 
 		WHERE session_id = ? 
 		ORDER BY created_at ASC
@@ -274,17 +299,23 @@ func runSessionExport(args []string, cfg *config.Config) int {
 		ID        string    `json:"id"`
 		Role      string    `json:"role"`
 		Content   string    `json:"content"`
+		Model     string    `json:"model,omitempty"`
+		Provider  string    `json:"provider,omitempty"`
 		CreatedAt time.Time `json:"created_at"`
 	}
 
+	// Timestamps are exported in the session's (or default) timezone.
+	loc := cfg.LocationFor("", sess.Timezone)
+
 	var messages []Message
 	for rows.Next() {
 		var msg Message
-		err := rows.Scan(&msg.ID, &msg.Role, &msg.Content, &msg.CreatedAt)
+		err := rows.Scan(&msg.ID, &msg.Role, &msg.Content, &msg.Model, &msg.Provider, &msg.CreatedAt)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to scan message: %v\n", err)
 			continue
 		}
+		msg.CreatedAt = msg.CreatedAt.In(loc)
 		messages = append(messages, msg)
 	}
 
@@ -302,8 +333,8 @@ func runSessionExport(args []string, cfg *config.Config) int {
 		Session: &ExportSession{
 			ID:        sess.ID,
 			Title:     sess.Title,
-			CreatedAt: sess.CreatedAt,
-			UpdatedAt: sess.UpdatedAt,
+			CreatedAt: sess.CreatedAt.In(loc),
+			UpdatedAt: sess.UpdatedAt.In(loc),
 		},
 		Messages: messages,
 	}
@@ -326,13 +357,16 @@ func runSessionExport(args []string, cfg *config.Config) int {
 		}
 	} else if format == "markdown" {
 		output := fmt.Sprintf("# %s\n\n", sess.Title)
-		output += fmt.Sprintf("Exported: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
+		output += fmt.Sprintf("Exported: %s\n\n", cfg.FormatTime(time.Now(), loc))
 		output += "---\n\n"
 		for _, msg := range messages {
 			role := "Unknown"
 			if msg.Role != "" {
 				role = strings.ToUpper(string(msg.Role[0])) + msg.Role[1:]
 			}
+			if msg.Model != "" {
+				role += fmt.Sprintf(" (%s)", messageModelLabel(msg.Provider, msg.Model))
+			}
 			output += fmt.Sprintf("## %s\n", role)
 			output += fmt.Sprintf("%s\n\n", msg.Content)
 		}
@@ -355,6 +389,15 @@ func runSessionExport(args []string, cfg *config.Config) int {
 	return 0
 }
 
+// messageModelLabel names the model that produced a message, qualified by its
+// provider when known.
+func messageModelLabel(provider, model string) string {
+	if provider == "" {
+		return model
+	}
+	return provider + "/" + model
+}
+
 func runSessionFork(args []string, cfg *config.Config) int {
 	if len(args) < 1 {
 		fmt.Fprintf(os.Stderr, "Error: session ID required\n")
@@ -444,6 +487,8 @@ func sessionUsage() {
 	fmt.Println("  pryx-core session fork abc123 --title 'New Chat'")
 }
 
-func formatTime(t time.Time) string {
-	return t.Format("2006-01-02 15:04:05")
+// formatTime formats t in the session's timezone, falling back to the
+// configured default, using the configured locale.
+func formatTime(cfg *config.Config, sess *store.Session, t time.Time) string {
+	return cfg.FormatTime(t, cfg.LocationFor("", sess.Timezone))
 }
@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
 	"pryx-core/internal/config"
 	"pryx-core/internal/skills"
+	"pryx-core/internal/store"
+	"pryx-core/internal/workspace"
 )
 
 func runSkills(args []string) int {
@@ -22,6 +26,11 @@ func runSkills(args []string) int {
 
 	cmd := args[0]
 	cfg := config.Load()
+	if ws, err := workspace.Open(cfg.WorkspaceRoot); err == nil {
+		if dir, err := ws.Resolve(workspace.DirSkills); err == nil {
+			skills.SetManagedRoot(dir)
+		}
+	}
 
 	switch cmd {
 	case "list", "ls":
@@ -38,6 +47,8 @@ func runSkills(args []string) int {
 		return runInstallSkill(args[1:], cfg)
 	case "uninstall":
 		return runUninstallSkill(args[1:], cfg)
+	case "reload":
+		return runReloadSkills(args[1:], cfg)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
 		skillsUsage()
@@ -81,10 +92,7 @@ func runListSkills(args []string, cfg *config.Config) int {
 		skillsToDisplay = append(skillsToDisplay, skill)
 	}
 
-	// Sort by name
-	sort.Slice(skillsToDisplay, func(i, j int) bool {
-		return skillsToDisplay[i].ID < skillsToDisplay[j].ID
-	})
+	skills.SortByCategory(skillsToDisplay)
 
 	if jsonOutput {
 		data, err := json.MarshalIndent(skillsToDisplay, "", "  ")
@@ -96,7 +104,12 @@ func runListSkills(args []string, cfg *config.Config) int {
 	} else {
 		fmt.Printf("Available Skills (%d)\n", len(skillsToDisplay))
 		fmt.Println(strings.Repeat("=", 51))
+		category := ""
 		for _, skill := range skillsToDisplay {
+			if skill.Category != category {
+				category = skill.Category
+				fmt.Printf("\n[%s]\n", category)
+			}
 			status := ""
 			if !skill.Enabled {
 				status = " (disabled)"
@@ -114,7 +127,11 @@ func runListSkills(args []string, cfg *config.Config) int {
 			if skill.Frontmatter.Description != "" {
 				fmt.Printf("  %s\n", skill.Frontmatter.Description)
 			}
-			fmt.Printf("  Source: %s, Enabled: %v\n", skill.Source, skill.Enabled)
+			enabled := fmt.Sprintf("%v", skill.Enabled)
+			if skill.EnabledByDefault {
+				enabled += " (default on)"
+			}
+			fmt.Printf("  Source: %s, Enabled: %s\n", skill.Source, enabled)
 		}
 	}
 
@@ -147,6 +164,8 @@ func runInfoSkill(args []string, cfg *config.Config) int {
 	fmt.Printf("Title:       %s\n", skill.Frontmatter.Name)
 	fmt.Printf("Description: %s\n", skill.Frontmatter.Description)
 	fmt.Printf("Source:      %s\n", skill.Source)
+	fmt.Printf("Category:    %s\n", skill.Category)
+	fmt.Printf("Priority:    %d\n", skill.Priority)
 	fmt.Printf("Path:        %s\n", skill.Path)
 	fmt.Printf("Enabled:     %v\n", skill.Enabled)
 	fmt.Printf("Eligible:    %v\n", skill.Eligible)
@@ -164,9 +183,34 @@ func runInfoSkill(args []string, cfg *config.Config) int {
 		}
 	}
 
+	printSkillStats(cfg, skill.ID)
+
 	return 0
 }
 
+// printSkillStats prints recorded execution stats for a skill, if any.
+func printSkillStats(cfg *config.Config, skillID string) {
+	s, err := store.New(cfg.DatabasePath)
+	if err != nil {
+		return
+	}
+	defer s.Close()
+
+	stats, err := s.GetSkillStats(skillID)
+	if err != nil || stats.RunCount == 0 {
+		fmt.Println("Runs:        none recorded")
+		return
+	}
+	fmt.Printf("Runs:        %d (%d failed, %.0f%% failure rate)\n", stats.RunCount, stats.FailureCount, stats.FailureRate*100)
+	fmt.Printf("Avg time:    %dms\n", stats.AvgDurationMs)
+	if stats.LastRunAt != nil {
+		fmt.Printf("Last run:    %s\n", cfg.FormatTime(*stats.LastRunAt, nil))
+	}
+	if stats.LastError != "" {
+		fmt.Printf("Last error:  %s\n", stats.LastError)
+	}
+}
+
 func runCheckSkills(args []string, cfg *config.Config) int {
 	opts := skills.DefaultOptions()
 
@@ -266,7 +310,7 @@ func runEnableSkill(args []string, cfg *config.Config) int {
 		return 1
 	}
 
-	_, found := skillsRepo.Get(name)
+	skill, found := skillsRepo.Get(name)
 	if !found {
 		fmt.Fprintf(os.Stderr, "Error: skill not found: %s\n", name)
 		return 1
@@ -279,7 +323,7 @@ func runEnableSkill(args []string, cfg *config.Config) int {
 		return 1
 	}
 
-	if enabledCfg.EnabledSkills[name] {
+	if skill.Enabled {
 		fmt.Printf("ℹ Skill %s is already enabled\n", name)
 	} else {
 		enabledCfg.EnabledSkills[name] = true
@@ -308,7 +352,7 @@ func runDisableSkill(args []string, cfg *config.Config) int {
 		return 1
 	}
 
-	_, found := skillsRepo.Get(name)
+	skill, found := skillsRepo.Get(name)
 	if !found {
 		fmt.Fprintf(os.Stderr, "Error: skill not found: %s\n", name)
 		return 1
@@ -321,10 +365,11 @@ func runDisableSkill(args []string, cfg *config.Config) int {
 		return 1
 	}
 
-	if !enabledCfg.EnabledSkills[name] {
+	if !skill.Enabled {
 		fmt.Printf("ℹ Skill %s is already disabled\n", name)
 	} else {
-		delete(enabledCfg.EnabledSkills, name)
+		// Keep an explicit false so a skill enabled by default stays off
+		enabledCfg.EnabledSkills[name] = false
 		if err := skills.SaveEnabledConfig(configPath, enabledCfg); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: failed to save skills config: %v\n", err)
 			return 1
@@ -525,6 +570,60 @@ func runUninstallSkill(args []string, cfg *config.Config) int {
 	return 0
 }
 
+// runReloadSkills asks the running runtime to rediscover skills from disk, so
+// edits to skill files take effect without a restart.
+func runReloadSkills(args []string, cfg *config.Config) int {
+	jsonOutput := false
+	for _, arg := range args {
+		if arg == "--json" || arg == "-j" {
+			jsonOutput = true
+		}
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(runtimeBaseURL(cfg)+"/skills/reload", "application/json", nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to reach runtime (is it running?): %v\n", err)
+		return 1
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Count int                 `json:"count"`
+		Diff  skills.RegistryDiff `json:"diff"`
+		Error string              `json:"error,omitempty"`
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &result); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid response from runtime: %s\n", strings.TrimSpace(string(body)))
+		return 1
+	}
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Error: reload failed, previous skills kept: %s\n", result.Error)
+		return 1
+	}
+
+	if jsonOutput {
+		out, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(out))
+		return 0
+	}
+	fmt.Printf("✓ Reloaded %d skills\n", result.Count)
+	if result.Diff.Empty() {
+		fmt.Println("  no changes")
+	}
+	for _, id := range result.Diff.Added {
+		fmt.Printf("  + %s\n", id)
+	}
+	for _, id := range result.Diff.Changed {
+		fmt.Printf("  ~ %s\n", id)
+	}
+	for _, id := range result.Diff.Removed {
+		fmt.Printf("  - %s\n", id)
+	}
+	return 0
+}
+
 func skillsUsage() {
 	fmt.Println("pryx-core skills - Manage Pryx skills")
 	fmt.Println("")
@@ -536,6 +635,7 @@ func skillsUsage() {
 	fmt.Println("  disable <name>                    Disable a skill")
 	fmt.Println("  install <name>                    Install a skill")
 	fmt.Println("  uninstall <name>                  Uninstall a skill")
+	fmt.Println("  reload [--json]                   Reload skills from disk in the running runtime")
 	fmt.Println("")
 	fmt.Println("Options:")
 	fmt.Println("  --eligible, -e                    Show only eligible skills")
@@ -164,7 +164,7 @@ func runEnableDisable(name string, enable bool) int {
 	if enable {
 		cfg.EnabledSkills[name] = true
 	} else {
-		delete(cfg.EnabledSkills, name)
+		cfg.EnabledSkills[name] = false
 	}
 	if err := skills.SaveEnabledConfig(path, cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: failed to save skills config: %v\n", err)
@@ -272,7 +272,7 @@ func TestMCPCLI_Help(t *testing.T) {
 
 func TestMCPCLI_AddRemove(t *testing.T) {
 	home := t.TempDir()
-	out, code := runPryxCoreWithEnv(t, home, nil, "mcp", "add", "test-server", "--url", "https://example.com")
+	out, code := runPryxCoreWithEnv(t, home, nil, "mcp", "add", "test-server", "--url", "https://example.com", "--no-verify")
 	if code != 0 {
 		t.Fatalf("mcp add failed (code %d):\n%s", code, out)
 	}
@@ -9,7 +9,7 @@ import (
 // TestMCPCLI_TestValidServer tests valid server connection
 func TestMCPCLI_TestValidServer(t *testing.T) {
 	// First add a test server (assuming test-mcp exists)
-	addCmd := exec.Command("/tmp/pryx-core", "mcp", "add", "test-mcp", "--url", "http://localhost:3001")
+	addCmd := exec.Command("/tmp/pryx-core", "mcp", "add", "test-mcp", "--url", "http://localhost:3001", "--no-verify")
 	addOutput, addErr := addCmd.CombinedOutput()
 	if addErr != nil {
 		t.Logf("Add server output: %s", addOutput)
@@ -33,7 +33,7 @@ func TestMCPCLI_TestValidServer(t *testing.T) {
 // TestMCPCLI_AddWithCmd tests stdio transport
 func TestMCPCLI_AddWithCmd(t *testing.T) {
 	// Add MCP server with stdio transport
-	cmd := exec.Command("/tmp/pryx-core", "mcp", "add", "test-stdio", "--cmd", "python -u http://example.com")
+	cmd := exec.Command("/tmp/pryx-core", "mcp", "add", "test-stdio", "--cmd", "python -u http://example.com", "--no-verify")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		t.Logf("Add stdio output: %s", output)
@@ -48,7 +48,7 @@ func TestMCPCLI_AddWithCmd(t *testing.T) {
 // TestMCPCLI_AddWithAuth tests authentication
 func TestMCPCLI_AddWithAuth(t *testing.T) {
 	// Add MCP server with authentication
-	cmd := exec.Command("/tmp/pryx-core", "mcp", "add", "test-auth", "--url", "http://localhost:3001", "--auth", "bearer", "--token-ref", "mytoken")
+	cmd := exec.Command("/tmp/pryx-core", "mcp", "add", "test-auth", "--url", "http://localhost:3001", "--auth", "bearer", "--token-ref", "mytoken", "--no-verify")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		t.Logf("Add auth output: %s", output)
@@ -63,7 +63,7 @@ func TestMCPCLI_AddWithAuth(t *testing.T) {
 // TestMCPCLI_AuthInfo tests auth info display
 func TestMCPCLI_AuthInfo(t *testing.T) {
 	// First add a server with auth
-	addCmd := exec.Command("/tmp/pryx-core", "mcp", "add", "test-auth", "--url", "http://localhost:3001", "--auth", "bearer", "--token-ref", "mytoken")
+	addCmd := exec.Command("/tmp/pryx-core", "mcp", "add", "test-auth", "--url", "http://localhost:3001", "--auth", "bearer", "--token-ref", "mytoken", "--no-verify")
 	addOutput, addErr := addCmd.CombinedOutput()
 	if addErr != nil {
 		t.Logf("Add server output: %s", addOutput)
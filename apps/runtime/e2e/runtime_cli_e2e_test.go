@@ -155,7 +155,7 @@ func TestCLI_MCPConfig_RoundTrip(t *testing.T) {
 		t.Fatalf("expected exit code 0, got %d\n%s", code, out)
 	}
 
-	out, code = runPryxCoreWithEnv(t, home, nil, "mcp", "add", "test-server", "--url", "https://example.com")
+	out, code = runPryxCoreWithEnv(t, home, nil, "mcp", "add", "test-server", "--url", "https://example.com", "--no-verify")
 	if code != 0 {
 		t.Fatalf("expected exit code 0, got %d\n%s", code, out)
 	}
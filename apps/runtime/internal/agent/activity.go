@@ -0,0 +1,52 @@
+package agent
+
+import "sync"
+
+// SessionActivity tracks the sessions with a chat request being handled,
+// including requests still waiting for a generation slot. A nil
+// SessionActivity reports no session as active.
+type SessionActivity struct {
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// NewSessionActivity creates an empty activity tracker.
+func NewSessionActivity() *SessionActivity {
+	return &SessionActivity{active: make(map[string]int)}
+}
+
+// Active reports whether the agent is handling a request for sessionID.
+func (s *SessionActivity) Active(sessionID string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active[sessionID] > 0
+}
+
+// Begin marks sessionID active until the returned function is called.
+func (s *SessionActivity) Begin(sessionID string) func() {
+	if s == nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	s.active[sessionID]++
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.active[sessionID]--; s.active[sessionID] <= 0 {
+				delete(s.active, sessionID)
+			}
+		})
+	}
+}
+
+// Activity returns the tracker of sessions the agent is handling.
+func (a *Agent) Activity() *SessionActivity {
+	return a.activity
+}
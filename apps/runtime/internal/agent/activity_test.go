@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm"
+)
+
+func TestAgent_SessionActivity(t *testing.T) {
+	activity := NewSessionActivity()
+	var activeDuringTurn bool
+	agent := &Agent{
+		cfg:      &config.Config{ModelProvider: "openai", ModelName: "gpt-4o"},
+		bus:      bus.New(),
+		activity: activity,
+		provider: &MockProvider{
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				activeDuringTurn = activity.Active("s1")
+				ch := make(chan llm.StreamChunk, 1)
+				ch <- llm.StreamChunk{Content: "ok", Done: true}
+				close(ch)
+				return ch, nil
+			},
+		},
+	}
+
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{"content": "hi"}))
+	if !activeDuringTurn {
+		t.Error("Expected the session to be active while its turn runs")
+	}
+	if activity.Active("s1") {
+		t.Error("Expected the session to be inactive after its turn")
+	}
+
+	end := activity.Begin("s2")
+	other := activity.Begin("s2")
+	end()
+	end()
+	if !activity.Active("s2") {
+		t.Error("Expected the session to stay active while another request runs")
+	}
+	other()
+	if activity.Active("s2") {
+		t.Error("Expected the session to be inactive once every request ended")
+	}
+
+	var none *SessionActivity
+	none.Begin("s3")()
+	if none.Active("s3") {
+		t.Error("Expected a nil tracker to report no activity")
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"pryx-core/internal/agentbus"
 	"pryx-core/internal/bus"
 	"pryx-core/internal/channels"
 	"pryx-core/internal/config"
+	"pryx-core/internal/contentfilter"
 	"pryx-core/internal/keychain"
 	"pryx-core/internal/llm"
 	"pryx-core/internal/llm/factory"
@@ -23,15 +26,53 @@ import (
 
 // Agent orchestrates the interaction between the user, LLM, and tools.
 type Agent struct {
-	cfg           *config.Config
-	bus           *bus.Bus
-	agentbus      *agentbus.Service
-	provider      llm.Provider
+	cfg      *config.Config
+	bus      *bus.Bus
+	agentbus *agentbus.Service
+	provider llm.Provider
+	// providerID is the provider the client was built for.
+	providerID string
+	// models holds the model selection a config reload may change.
+	models        *ModelSettings
 	promptBuilder *prompt.Builder
 	version       string
 	skills        *skills.Registry
 	mcp           *mcp.Manager
 	ragMemory     *memory.RAGManager
+	tools         *ToolBatchExecutor
+	// nativeTools are the runtime's own tools, offered next to MCP tools.
+	nativeToolsMu sync.RWMutex
+	nativeTools   map[string]NativeTool
+	filter        contentfilter.Hook
+	catalogMu     sync.RWMutex
+	catalog       *models.Catalog
+	greeter       *channels.Greeter
+	inbound       *channels.InboundFilter
+	budgets       BudgetCheck
+
+	// sessionCache holds per-session overrides of the response cache toggle.
+	sessionCacheMu sync.RWMutex
+	sessionCache   map[string]bool
+
+	// sessionModel caches per-session model overrides set via session_model;
+	// sessionModelStore persists them.
+	sessionModelMu    sync.RWMutex
+	sessionModel      map[string]string
+	sessionModelStore SessionModelStore
+
+	// maintenance mirrors the runtime maintenance flag; channel intake stops while set.
+	maintenance atomic.Bool
+
+	// generations bounds concurrent LLM generations across sessions and channels.
+	generations *GenerationLimiter
+	activity    *SessionActivity
+	// rateLimits paces provider requests per model.
+	rateLimits *llm.ModelLimiter
+
+	// injections holds messages injected into in-progress chat turns.
+	injections injections
+	// turns counts conversation turns against the turn limits.
+	turns *TurnCounter
 }
 
 // New creates a new Agent instance with the provided configuration and dependencies.
@@ -55,8 +96,12 @@ func New(cfg *config.Config, eventBus *bus.Bus, kc *keychain.Keychain, catalog *
 	var provider llm.Provider
 	var err error
 
-	// Try to use catalog-aware factory if catalog is available
-	if catalog != nil {
+	if UseCloudProxy(cfg, kc, catalog) {
+		// No local key, but a cloud login: generate through the cloud proxy.
+		log.Printf("Agent: No %s key configured, routing generation through Pryx Cloud", cfg.ModelProvider)
+		provider = newCloudProxiedProvider(cfg, kc, eventBus)
+	} else if catalog != nil {
+		// Try to use catalog-aware factory if catalog is available
 		providerFactory := factory.NewProviderFactory(catalog, kc)
 		provider, err = providerFactory.CreateProvider(cfg.ModelProvider, cfg.ModelName, apiKey)
 		if err != nil {
@@ -75,28 +120,162 @@ func New(cfg *config.Config, eventBus *bus.Bus, kc *keychain.Keychain, catalog *
 		}
 	}
 
+	rateLimits := newModelLimiter(cfg)
+	provider = llm.NewRateLimitedProvider(provider, rateLimits)
+
+	if cfg.LLMCacheEnabled {
+		cache := llm.NewResponseCache(cfg.LLMCacheTTL, cfg.LLMCacheMaxEntries)
+		provider = llm.NewCachingProvider(provider, cache, func(ctx context.Context, req llm.ChatRequest, resp *llm.ChatResponse) {
+			eventBus.Publish(bus.NewEvent(bus.EventLLMCacheHit, sessionIDFromContext(ctx), map[string]interface{}{
+				"model":  req.Model,
+				"length": len(resp.Content),
+			}))
+		})
+	}
+
 	promptBuilder := prompt.NewBuilder(prompt.DefaultPryxDir(), prompt.ModeFull)
 	if err := promptBuilder.EnsureTemplates(); err != nil {
 		log.Printf("Warning: Failed to ensure prompt templates: %v", err)
 	}
 
-	return &Agent{
+	a := &Agent{
 		cfg:           cfg,
 		bus:           eventBus,
 		agentbus:      agentbusService,
 		provider:      provider,
+		providerID:    cfg.ModelProvider,
+		models:        NewModelSettings(cfg),
 		promptBuilder: promptBuilder,
 		version:       "dev",
 		skills:        skillsRegistry,
 		mcp:           mcpManager,
 		ragMemory:     ragMemory,
-	}, nil
+		catalog:       catalog,
+		sessionCache:  make(map[string]bool),
+		sessionModel:  make(map[string]string),
+		generations:   NewGenerationLimiter(cfg.MaxConcurrentGenerations, generationQueueSize(cfg.GenerationQueueSize)),
+		activity:      NewSessionActivity(),
+		rateLimits:    rateLimits,
+		turns:         NewTurnCounter(),
+	}
+	if f := contentfilter.New(cfg.ContentFilter); f != nil {
+		a.filter = f
+	}
+	a.tools = NewToolBatchExecutor(a.invokeTool, eventBus, cfg.AgentMaxParallelTools, cfg.AgentToolTimeout)
+	for channelID, model := range cfg.ChannelModels {
+		if err := catalog.ValidateModel(cfg.ModelProvider, model); err != nil {
+			log.Printf("Warning: Ignoring model override for channel %s: %v", channelID, err)
+		}
+	}
+	a.maintenance.Store(cfg.MaintenanceMode)
+	return a, nil
+}
+
+// SetCatalog replaces the model catalog used to validate models. The
+// provider built from the catalog in New is kept.
+func (a *Agent) SetCatalog(catalog *models.Catalog) {
+	a.catalogMu.Lock()
+	defer a.catalogMu.Unlock()
+	a.catalog = catalog
+}
+
+// modelCatalog returns the current model catalog, which may be nil.
+func (a *Agent) modelCatalog() *models.Catalog {
+	a.catalogMu.RLock()
+	defer a.catalogMu.RUnlock()
+	return a.catalog
+}
+
+// SetContentFilter replaces the content filter hook. A nil hook disables filtering.
+func (a *Agent) SetContentFilter(h contentfilter.Hook) {
+	a.filter = h
+}
+
+// SetGreeter sets the greeter for channel conversations. A nil greeter disables greetings.
+func (a *Agent) SetGreeter(g *channels.Greeter) {
+	a.greeter = g
+}
+
+// SetInboundFilter sets the filter that screens channel messages before they
+// are processed. A nil filter admits every message.
+func (a *Agent) SetInboundFilter(f *channels.InboundFilter) {
+	a.inbound = f
+}
+
+// blockedNotice replaces content rejected by the content filter.
+const blockedNotice = "This message was blocked by the content policy."
+
+// filterContent applies the content filter at stage and reports any matches on the bus.
+// It returns the content to use, and false if the content was blocked.
+func (a *Agent) filterContent(stage contentfilter.Stage, sessionID, channel, content string) (string, bool) {
+	if a.filter == nil {
+		return content, true
+	}
+	res := a.filter.Apply(stage, channel, content)
+	if res.Filtered() {
+		rules := make([]string, 0, len(res.Matches))
+		for _, m := range res.Matches {
+			rules = append(rules, m.Rule)
+		}
+		a.bus.Publish(bus.NewEvent(bus.EventContentFiltered, sessionID, map[string]interface{}{
+			"stage":   string(stage),
+			"channel": channel,
+			"blocked": res.Blocked,
+			"rules":   rules,
+		}))
+	}
+	if res.Blocked {
+		return "", false
+	}
+	return res.Content, true
+}
+
+// ExecuteToolCalls runs the tool calls requested in one assistant turn and returns
+// their results in request order.
+func (a *Agent) ExecuteToolCalls(ctx context.Context, sessionID string, calls []ToolCall) ([]ToolCallResult, error) {
+	if a.tools == nil {
+		return nil, fmt.Errorf("tool execution unavailable")
+	}
+	return a.tools.Execute(ctx, sessionID, calls), nil
+}
+
+type sessionCtxKey struct{}
+
+func withSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionCtxKey{}, sessionID)
+}
+
+func sessionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionCtxKey{}).(string)
+	return id
+}
+
+// SetSessionCache enables or disables the response cache for a single session.
+func (a *Agent) SetSessionCache(sessionID string, enabled bool) {
+	a.sessionCacheMu.Lock()
+	defer a.sessionCacheMu.Unlock()
+	if a.sessionCache == nil {
+		a.sessionCache = make(map[string]bool)
+	}
+	a.sessionCache[sessionID] = enabled
+}
+
+// requestContext annotates ctx with the session and its cache preference.
+func (a *Agent) requestContext(ctx context.Context, sessionID string) context.Context {
+	ctx = withSessionID(ctx, sessionID)
+	a.sessionCacheMu.RLock()
+	enabled, ok := a.sessionCache[sessionID]
+	a.sessionCacheMu.RUnlock()
+	if ok {
+		ctx = llm.WithCache(ctx, enabled)
+	}
+	return ctx
 }
 
 // Run starts the agent's main event loop, listening for chat requests and channel messages.
 func (a *Agent) Run(ctx context.Context) error {
 	// Subscribe to incoming messages
-	events, cancel := a.bus.Subscribe(bus.EventChatRequest, bus.EventChannelMessage)
+	events, cancel := a.bus.Subscribe(bus.EventChatRequest, bus.EventChatInject, bus.EventChannelMessage, bus.EventMaintenanceChanged, bus.EventSessionDeleted)
 	defer cancel()
 
 	log.Println("Agent: Started listening for messages...")
@@ -109,6 +288,22 @@ func (a *Agent) Run(ctx context.Context) error {
 			if !ok {
 				return nil
 			}
+			if evt.Event == bus.EventMaintenanceChanged {
+				if payload, ok := evt.Payload.(map[string]interface{}); ok {
+					enabled, _ := payload["enabled"].(bool)
+					a.maintenance.Store(enabled)
+				}
+				continue
+			}
+			if evt.Event == bus.EventSessionDeleted {
+				a.turns.Reset(evt.SessionID)
+				a.forgetSessionModel(evt.SessionID)
+				continue
+			}
+			if evt.Event == bus.EventChannelMessage && a.maintenance.Load() {
+				log.Println("Agent: Maintenance mode active, dropping channel message")
+				continue
+			}
 			// Handle event in goroutine with panic recovery
 			go func() {
 				defer func() {
@@ -131,6 +326,8 @@ func (a *Agent) handleEvent(ctx context.Context, evt bus.Event) {
 	switch evt.Event {
 	case bus.EventChatRequest:
 		a.handleChatRequest(ctx, evt)
+	case bus.EventChatInject:
+		a.handleChatInject(evt)
 	case bus.EventChannelMessage:
 		a.handleChannelMessage(ctx, evt)
 	}
@@ -157,59 +354,203 @@ func (a *Agent) handleChatRequest(ctx context.Context, evt bus.Event) {
 	content, _ := payload["content"].(string)
 	sessionID := evt.SessionID
 
+	if enabled, ok := payload["cache"].(bool); ok {
+		a.SetSessionCache(sessionID, enabled)
+	}
+	if model, ok := payload["session_model"].(string); ok {
+		if err := a.SetSessionModel(sessionID, model); err != nil {
+			a.publishModelError(sessionID, err)
+			return
+		}
+	}
+
 	if content == "" {
 		return
 	}
+	defer a.activity.Begin(sessionID)()
+
+	requestModel, _ := payload["model"].(string)
+	channelID, _ := payload["channel_id"].(string)
+	model, err := a.resolveModel(sessionID, requestModel, channelID)
+	if err != nil {
+		a.publishModelError(sessionID, err)
+		return
+	}
+	effort, err := a.reasoningEffort(model, payload)
+	if err != nil {
+		a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionID, map[string]interface{}{
+			"kind":  "agent.invalid_reasoning_effort",
+			"error": err.Error(),
+		}))
+		return
+	}
+
+	content, allowed := a.filterContent(contentfilter.StagePreSend, sessionID, "", content)
+	if !allowed {
+		a.bus.Publish(bus.NewEvent(bus.EventSessionMessage, sessionID, map[string]interface{}{
+			"content":  blockedNotice,
+			"done":     true,
+			"filtered": true,
+		}))
+		return
+	}
 
 	log.Printf("Agent: Processing TUI message: %s (session: %s)", content, sessionID)
 
-	systemPrompt, err := a.buildSystemPrompt(sessionID)
+	timezone, _ := payload["timezone"].(string)
+	systemPrompt, err := a.buildSystemPrompt(sessionID, a.cfg.LocationFor(channelID, timezone))
 	if err != nil {
 		log.Printf("Agent: Failed to build system prompt: %v", err)
 		systemPrompt = "You are Pryx, a helpful AI assistant."
 	}
 
+	tools := a.turnTools()
 	req := llm.ChatRequest{
-		Model: a.cfg.ModelName,
+		Model: model,
 		Messages: []llm.Message{
 			{Role: llm.RoleSystem, Content: systemPrompt},
 			{Role: llm.RoleUser, Content: content},
 		},
-		Stream: true,
+		Stream:          true,
+		ReasoningEffort: effort,
+		Tools:           tools.specs,
 	}
 
-	// Stream response
-	stream, err := a.provider.Stream(ctx, req)
-	if err != nil {
-		log.Printf("Agent: LLM error: %v", err)
-		a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionID, map[string]interface{}{
-			"kind":  "agent.llm_error",
-			"error": err.Error(),
-		}))
+	if !a.withinBudget(sessionID) {
 		return
 	}
 
-	var fullResponse strings.Builder
-	for chunk := range stream {
-		if chunk.Err != nil {
-			log.Printf("Agent: Stream error: %v", chunk.Err)
-			a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionID, map[string]interface{}{
-				"kind":  "agent.stream_error",
-				"error": chunk.Err.Error(),
+	// Requests carrying a channel, or flagged as automated, are unattended
+	automated, _ := payload["automated"].(bool)
+	turnKey := sessionID
+	if channelID != "" {
+		automated = true
+		if turnKey == "" {
+			turnKey = "channel:" + channelID
+		}
+	}
+	if ok, summary := a.withinTurnLimit(sessionID, turnKey, automated, map[string]interface{}{"channel_id": channelID}); !ok {
+		if summary != "" {
+			a.bus.Publish(bus.NewEvent(bus.EventSessionMessage, sessionID, map[string]interface{}{
+				"content":    summary,
+				"done":       true,
+				"turn_limit": true,
 			}))
-			break
 		}
-		fullResponse.WriteString(chunk.Content)
+		return
+	}
 
-		// Publish delta to TUI
-		a.bus.Publish(bus.NewEvent(bus.EventSessionMessage, sessionID, map[string]interface{}{
-			"content": chunk.Content,
-			"done":    chunk.Done,
-		}))
+	release := a.acquireGeneration(ctx, sessionID, "session:"+sessionID)
+	if release == nil {
+		return
+	}
+	defer release()
+
+	// Tool calls made for a channel are held to the channel's tool scopes
+	toolCtx := ctx
+	if channelID != "" {
+		toolCtx = mcp.WithChannel(ctx, channelID)
+	}
+
+	// Messages injected while the turn runs are added at the next step
+	// boundary: the end of a streamed response starts another step instead of
+	// finishing the turn. So do tool calls, whose results the next step gets.
+	turn := a.injections.begin(sessionID)
+	defer turn.end()
+
+	// With a content filter the response must be seen whole before anything is
+	// returned, so deltas are buffered and delivered as a single message.
+	buffered := a.filter != nil
 
-		if chunk.Done {
+	var fullResponse strings.Builder
+	for round := 0; ; round++ {
+		// Stream response, failing over to the configured fallback models
+		stream, served, release, err := a.openStream(ctx, sessionID, req)
+		if err != nil {
+			log.Printf("Agent: LLM error: %v", err)
+			a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionID, llmErrorPayload("agent.llm_error", err)))
+			return
+		}
+		model = served
+
+		var step strings.Builder
+		var injected []string
+		var calls []llm.ToolCall
+		for chunk := range stream {
+			if chunk.Err != nil {
+				log.Printf("Agent: Stream error: %v", chunk.Err)
+				a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionID, llmErrorPayload("agent.stream_error", chunk.Err)))
+				break
+			}
+			step.WriteString(chunk.Content)
+			if chunk.Usage != nil {
+				a.publishUsage(sessionID, model, req.ReasoningEffort, *chunk.Usage)
+			}
+
+			done := chunk.Done
+			if done {
+				calls = chunk.ToolCalls
+				injected = turn.take()
+				done = len(injected) == 0 && len(calls) == 0
+			}
+			if !buffered {
+				// Publish delta to TUI
+				delta := map[string]interface{}{
+					"content": chunk.Content,
+					"done":    done,
+				}
+				if done {
+					a.attributeMessage(delta, model)
+				}
+				a.bus.Publish(bus.NewEvent(bus.EventSessionMessage, sessionID, delta))
+			}
+
+			if chunk.Done {
+				break
+			}
+		}
+		release()
+		fullResponse.WriteString(step.String())
+
+		if len(injected) == 0 && len(calls) == 0 {
 			break
 		}
+		answer := step.String()
+		if len(calls) > 0 {
+			req.Messages = append(req.Messages, a.runToolCalls(toolCtx, sessionID, tools, answer, calls)...)
+			answer = ""
+			if round+1 >= maxToolRounds {
+				req.Tools = nil
+			}
+		}
+		if len(injected) > 0 {
+			a.applyInjections(sessionID, model, &req, answer, injected)
+		}
+		if step.Len() == 0 {
+			continue
+		}
+
+		// Separate the answers of consecutive steps.
+		fullResponse.WriteString("\n\n")
+		if !buffered {
+			a.bus.Publish(bus.NewEvent(bus.EventSessionMessage, sessionID, map[string]interface{}{
+				"content": "\n\n",
+				"done":    false,
+			}))
+		}
+	}
+
+	if buffered {
+		response, allowed := a.filterContent(contentfilter.StagePostReceive, sessionID, "", fullResponse.String())
+		if !allowed {
+			response = blockedNotice
+		}
+		final := map[string]interface{}{
+			"content": response,
+			"done":    true,
+		}
+		a.attributeMessage(final, model)
+		a.bus.Publish(bus.NewEvent(bus.EventSessionMessage, sessionID, final))
 	}
 
 	log.Printf("Agent: Completed TUI response (%d chars)", fullResponse.Len())
@@ -233,43 +574,198 @@ func (a *Agent) handleChannelMessage(ctx context.Context, evt bus.Event) {
 		return
 	}
 
-	log.Printf("Agent: Processing channel message from %s (chat: %s): %s", msg.Source, msg.ChannelID, msg.Content)
+	// Echoes of channel self-tests are not conversation
+	if channels.IsSelfTestMessage(msg.Content) {
+		return
+	}
+
+	if !a.admitChannelMessage(msg) {
+		return
+	}
+
+	if a.greet(msg) && channels.IsStartCommand(msg.Content) {
+		return
+	}
+
+	content, allowed := a.filterContent(contentfilter.StagePreSend, "", msg.Source, msg.Content)
+	if !allowed {
+		a.bus.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", map[string]interface{}{
+			"source":     msg.Source,
+			"channel_id": msg.ChannelID,
+			"content":    blockedNotice,
+		}))
+		return
+	}
+
+	log.Printf("Agent: Processing channel message from %s (chat: %s): %s", msg.Source, msg.ChannelID, content)
+
+	model, err := a.resolveModel("", "", msg.Source)
+	if err != nil {
+		log.Printf("Agent: Channel model for %s is invalid, using default: %v", msg.Source, err)
+		model = a.modelConfig().Name
+	}
 
-	systemPrompt, err := a.buildSystemPrompt("")
+	systemPrompt, err := a.buildSystemPrompt("", a.cfg.LocationFor(msg.Source, ""))
 	if err != nil {
 		log.Printf("Agent: Failed to build system prompt: %v", err)
 		systemPrompt = "You are Pryx, a helpful AI assistant."
 	}
 
+	tools := a.turnTools()
 	req := llm.ChatRequest{
-		Model: a.cfg.ModelName,
+		Model: model,
 		Messages: []llm.Message{
 			{Role: llm.RoleSystem, Content: systemPrompt},
-			{Role: llm.RoleUser, Content: msg.Content},
+			{Role: llm.RoleUser, Content: content},
 		},
 		Stream: false,
+		Tools:  tools.specs,
+	}
+
+	if ok, summary := a.withinTurnLimit("", "channel:"+msg.Source+":"+msg.ChannelID, true, map[string]interface{}{
+		"source":     msg.Source,
+		"channel_id": msg.ChannelID,
+	}); !ok {
+		if summary != "" {
+			a.bus.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", map[string]interface{}{
+				"source":     msg.Source,
+				"channel_id": msg.ChannelID,
+				"content":    summary,
+			}))
+		}
+		return
 	}
 
-	resp, err := a.provider.Complete(ctx, req)
+	release := a.acquireGeneration(ctx, "", "channel:"+msg.Source+":"+msg.ChannelID)
+	if release == nil {
+		if ctx.Err() == nil {
+			a.bus.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", map[string]interface{}{
+				"source":     msg.Source,
+				"channel_id": msg.ChannelID,
+				"content":    busyNotice,
+			}))
+		}
+		return
+	}
+	var resp *llm.ChatResponse
+	served := model
+	for round := 0; ; round++ {
+		// Complete, failing over to the configured fallback models
+		resp, served, err = a.complete(ctx, "", req)
+		if err != nil || len(resp.ToolCalls) == 0 {
+			break
+		}
+		toolCtx := mcp.WithChannel(ctx, msg.Source+":"+msg.ChannelID)
+		req.Messages = append(req.Messages, a.runToolCalls(toolCtx, "", tools, resp.Content, resp.ToolCalls)...)
+		if round+1 >= maxToolRounds {
+			req.Tools = nil
+		}
+	}
+	release()
 	if err != nil {
 		log.Printf("Agent: LLM error: %v", err)
-		a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
-			"kind":  "agent.channel.llm_error",
-			"error": err.Error(),
-		}))
+		payload := llmErrorPayload("agent.channel.llm_error", err)
+		payload["channel"] = msg.Source + ":" + msg.ChannelID
+		a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, "", payload))
 		return
 	}
 
-	log.Printf("Agent: Sending channel response (%d chars)", len(resp.Content))
+	response, allowed := a.filterContent(contentfilter.StagePostReceive, "", msg.Source, resp.Content)
+	if !allowed {
+		response = blockedNotice
+	}
+
+	log.Printf("Agent: Sending channel response (%d chars)", len(response))
+
+	outbound := map[string]interface{}{
+		"source":     msg.Source,
+		"channel_id": msg.ChannelID,
+		"content":    response,
+	}
+	a.attributeMessage(outbound, served)
+	a.bus.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", outbound))
+}
 
+// admitChannelMessage applies the inbound filter to msg. Filtered messages are
+// recorded as channel activity and answered with the configured auto-reply, if any.
+func (a *Agent) admitChannelMessage(msg channels.Message) bool {
+	decision := a.inbound.Check(msg)
+	if decision.Allowed {
+		return true
+	}
+
+	log.Printf("Agent: Dropped channel message from %s (chat: %s, sender: %s): %s", msg.Source, msg.ChannelID, msg.SenderID, decision.Reason)
+	a.bus.Publish(bus.NewEvent(bus.EventChannelMessageFiltered, "", map[string]interface{}{
+		"source":     msg.Source,
+		"channel_id": msg.ChannelID,
+		"sender_id":  msg.SenderID,
+		"message_id": msg.ID,
+		"reason":     decision.Reason,
+		"replied":    decision.Reply != "",
+	}))
+	if decision.Reply != "" {
+		a.bus.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", map[string]interface{}{
+			"source":     msg.Source,
+			"channel_id": msg.ChannelID,
+			"content":    decision.Reply,
+		}))
+	}
+	return false
+}
+
+// greet sends the channel greeting if one is due and reports whether it did.
+func (a *Agent) greet(msg channels.Message) bool {
+	if a.greeter == nil {
+		return false
+	}
+	name := prompt.DefaultPersonaName
+	if a.promptBuilder != nil {
+		name = a.promptBuilder.PersonaName()
+	}
+	greeting, err := a.greeter.Greet(msg, channels.GreetingData{Name: name, Capabilities: a.getAvailableSkills()})
+	if err != nil {
+		log.Printf("Agent: Failed to greet %s/%s: %v", msg.Source, msg.ChannelID, err)
+		return false
+	}
+	if greeting == "" {
+		return false
+	}
 	a.bus.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", map[string]interface{}{
 		"source":     msg.Source,
 		"channel_id": msg.ChannelID,
-		"content":    resp.Content,
+		"content":    greeting,
 	}))
+	return true
+}
+
+// Error kinds of a failed provider call, reported as error_kind on its error
+// event and in the audit log.
+const (
+	LLMErrorTimeout  = "timeout"
+	LLMErrorProvider = "provider_error"
+)
+
+// llmErrorPayload builds the error event payload for a failed provider call.
+// Timeouts are reported under a distinct kind so clients can tell a stalled
+// provider apart from one that answered with an error.
+func llmErrorPayload(kind string, err error) map[string]interface{} {
+	timeout := llm.IsTimeout(err)
+	errorKind := LLMErrorProvider
+	if timeout {
+		kind = strings.TrimSuffix(kind, "_error") + "_timeout"
+		errorKind = LLMErrorTimeout
+	}
+	return map[string]interface{}{
+		"kind":       kind,
+		"error":      err.Error(),
+		"timeout":    timeout,
+		"error_kind": errorKind,
+	}
 }
 
-func (a *Agent) buildSystemPrompt(sessionID string) (string, error) {
+// buildSystemPrompt builds the system prompt, giving the current time in loc so
+// relative requests like "remind me at 9" use the user's zone.
+func (a *Agent) buildSystemPrompt(sessionID string, loc *time.Location) (string, error) {
 	if a.promptBuilder == nil {
 		return "You are Pryx, a helpful AI assistant.", nil
 	}
@@ -288,7 +784,7 @@ func (a *Agent) buildSystemPrompt(sessionID string) (string, error) {
 	}
 
 	metadata := prompt.Metadata{
-		CurrentTime:     time.Now(),
+		CurrentTime:     time.Now().In(loc),
 		Version:         a.version,
 		SessionID:       sessionID,
 		AvailableTools:  a.getAvailableTools(),
@@ -3,12 +3,14 @@ package agent
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"pryx-core/internal/bus"
 	"pryx-core/internal/channels"
 	"pryx-core/internal/config"
+	"pryx-core/internal/contentfilter"
 	"pryx-core/internal/keychain"
 	"pryx-core/internal/llm"
 	"pryx-core/internal/models"
@@ -386,3 +388,423 @@ func TestAgent_handleEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestAgent_ContentFilter(t *testing.T) {
+	eventBus := bus.New()
+	var sent string
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "test-model"},
+		bus: eventBus,
+		provider: &MockProvider{
+			CompleteFunc: func(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+				sent = req.Messages[1].Content
+				return &llm.ChatResponse{Content: "reach me at bot@example.com"}, nil
+			},
+		},
+	}
+	agent.SetContentFilter(contentfilter.New(config.ContentFilterConfig{
+		ContentFilterRules: config.ContentFilterRules{RedactPII: true, DenyKeywords: []string{"forbidden"}},
+	}))
+
+	outbound, cancelOut := eventBus.Subscribe(bus.EventChannelOutboundMessage)
+	defer cancelOut()
+	filtered, cancelFiltered := eventBus.Subscribe(bus.EventContentFiltered)
+	defer cancelFiltered()
+
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram-main", ChannelID: "1", Content: "my email is me@example.com",
+	}))
+	if sent != "my email is [REDACTED:email]" {
+		t.Fatalf("expected PII redacted before send, got %q", sent)
+	}
+	out := (<-outbound).Payload.(map[string]interface{})
+	if out["content"] != "reach me at [REDACTED:email]" {
+		t.Fatalf("expected PII redacted in response, got %v", out["content"])
+	}
+	evt := (<-filtered).Payload.(map[string]interface{})
+	if evt["stage"] != "pre_send" || evt["channel"] != "telegram-main" {
+		t.Fatalf("unexpected filter event: %v", evt)
+	}
+
+	sent = ""
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram-main", ChannelID: "1", Content: "something FORBIDDEN",
+	}))
+	if sent != "" {
+		t.Fatalf("blocked content must not reach the provider")
+	}
+	<-outbound // blocked notice
+	evt = (<-filtered).Payload.(map[string]interface{})
+	// Skip the post-receive event from the first message if it is still queued.
+	if evt["stage"] == "post_receive" {
+		evt = (<-filtered).Payload.(map[string]interface{})
+	}
+	if evt["blocked"] != true {
+		t.Fatalf("expected blocked filter event, got %v", evt)
+	}
+}
+
+func TestAgent_ModelPrecedence(t *testing.T) {
+	eventBus := bus.New()
+	used := make(chan string, 4)
+	agent := &Agent{
+		cfg: &config.Config{
+			ModelProvider: "openai",
+			ModelName:     "gpt-4o",
+			ChannelModels: map[string]string{"support": "gpt-4o-mini"},
+		},
+		bus: eventBus,
+		catalog: &models.Catalog{Models: map[string]models.ModelInfo{
+			"gpt-4o":      {ID: "gpt-4o", Provider: "openai"},
+			"gpt-4o-mini": {ID: "gpt-4o-mini", Provider: "openai"},
+			"o3":          {ID: "o3", Provider: "openai"},
+		}},
+		provider: &MockProvider{
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				used <- req.Model
+				ch := make(chan llm.StreamChunk, 1)
+				ch <- llm.StreamChunk{Content: "ok", Done: true}
+				close(ch)
+				return ch, nil
+			},
+		},
+	}
+
+	traces, cancel := eventBus.Subscribe(bus.EventTraceEvent)
+	defer cancel()
+
+	send := func(payload map[string]interface{}) string {
+		payload["content"] = "hi"
+		agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", payload))
+		select {
+		case m := <-used:
+			return m
+		case <-time.After(time.Second):
+			t.Fatal("provider was not called")
+			return ""
+		}
+	}
+
+	if got := send(map[string]interface{}{}); got != "gpt-4o" {
+		t.Errorf("Expected default model, got %s", got)
+	}
+	if got := send(map[string]interface{}{"channel_id": "support"}); got != "gpt-4o-mini" {
+		t.Errorf("Expected channel model, got %s", got)
+	}
+	if got := send(map[string]interface{}{"channel_id": "support", "session_model": "o3"}); got != "o3" {
+		t.Errorf("Expected session model to beat channel model, got %s", got)
+	}
+	if got := send(map[string]interface{}{"model": "gpt-4o"}); got != "gpt-4o" {
+		t.Errorf("Expected request model to beat session model, got %s", got)
+	}
+
+	var sources []string
+	for len(sources) < 4 {
+		select {
+		case evt := <-traces:
+			payload, _ := evt.Payload.(map[string]interface{})
+			if payload["kind"] == "agent.model.resolved" {
+				sources = append(sources, payload["source"].(string))
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 4 model.resolved events, got %v", sources)
+		}
+	}
+	want := []string{models.SourceDefault, models.SourceChannel, models.SourceSession, models.SourceRequest}
+	for i := range want {
+		if sources[i] != want[i] {
+			t.Errorf("Event %d source = %s, want %s", i, sources[i], want[i])
+		}
+	}
+
+	errs, cancelErrs := eventBus.Subscribe(bus.EventErrorOccurred)
+	defer cancelErrs()
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{
+		"content": "hi",
+		"model":   "not-a-model",
+	}))
+	select {
+	case evt := <-errs:
+		payload, _ := evt.Payload.(map[string]interface{})
+		if payload["kind"] != "agent.invalid_model" {
+			t.Errorf("Expected agent.invalid_model error, got %v", payload["kind"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an error for an unknown model")
+	}
+	select {
+	case m := <-used:
+		t.Errorf("Provider should not be called for an invalid model, got %s", m)
+	default:
+	}
+}
+
+func TestAgent_ChannelInboundFilter(t *testing.T) {
+	eventBus := bus.New()
+	inbound, err := channels.NewInboundFilter(map[string]config.ChannelInboundFilter{
+		"telegram": {DenyPatterns: []string{`(?i)free crypto`}, Reply: "Not here, sorry."},
+	})
+	if err != nil {
+		t.Fatalf("NewInboundFilter failed: %v", err)
+	}
+	calls := 0
+	agent := &Agent{
+		cfg:     &config.Config{ModelProvider: "openai", ModelName: "test-model"},
+		bus:     eventBus,
+		inbound: inbound,
+		provider: &MockProvider{
+			CompleteFunc: func(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+				calls++
+				return &llm.ChatResponse{Content: "reply"}, nil
+			},
+		},
+	}
+
+	events, cancel := eventBus.Subscribe(bus.EventChannelMessageFiltered, bus.EventChannelOutboundMessage)
+	defer cancel()
+
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		ID: "m1", Source: "telegram", ChannelID: "1", SenderID: "u1", Content: "FREE CRYPTO here",
+	}))
+	for _, want := range []bus.EventType{bus.EventChannelMessageFiltered, bus.EventChannelOutboundMessage} {
+		select {
+		case evt := <-events:
+			payload, _ := evt.Payload.(map[string]interface{})
+			if evt.Event != want {
+				t.Fatalf("Expected %s, got %s", want, evt.Event)
+			}
+			if want == bus.EventChannelMessageFiltered && (payload["sender_id"] != "u1" || payload["replied"] != true) {
+				t.Errorf("Unexpected filtered payload: %v", payload)
+			}
+			if want == bus.EventChannelOutboundMessage && payload["content"] != "Not here, sorry." {
+				t.Errorf("Expected the auto-reply, got %v", payload["content"])
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %s event", want)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("Expected a filtered message not to reach the model, got %d calls", calls)
+	}
+
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram", ChannelID: "1", SenderID: "u1", Content: "hello",
+	}))
+	if calls != 1 {
+		t.Errorf("Expected an admitted message to reach the model, got %d calls", calls)
+	}
+}
+
+func TestAgent_LLMErrorKinds(t *testing.T) {
+	eventBus := bus.New()
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "test-model"},
+		bus: eventBus,
+		provider: &MockProvider{
+			CompleteFunc: func(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+				return nil, fmt.Errorf("openai: %w", llm.ErrTimeout)
+			},
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				return nil, errors.New("invalid request")
+			},
+		},
+	}
+
+	events, cancel := eventBus.Subscribe(bus.EventErrorOccurred)
+	defer cancel()
+	next := func() map[string]interface{} {
+		t.Helper()
+		select {
+		case evt := <-events:
+			payload, _ := evt.Payload.(map[string]interface{})
+			return payload
+		case <-time.After(time.Second):
+			t.Fatal("Expected an error event")
+			return nil
+		}
+	}
+
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram", ChannelID: "1", SenderID: "u1", Content: "hello",
+	}))
+	payload := next()
+	if payload["kind"] != "agent.channel.llm_timeout" || payload["error_kind"] != LLMErrorTimeout || payload["channel"] != "telegram:1" {
+		t.Errorf("Unexpected channel error payload: %v", payload)
+	}
+
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{"content": "hi"}))
+	payload = next()
+	if payload["kind"] != "agent.llm_error" || payload["error_kind"] != LLMErrorProvider {
+		t.Errorf("Unexpected chat error payload: %v", payload)
+	}
+}
+
+func TestAgent_ChannelGreeting(t *testing.T) {
+	eventBus := bus.New()
+	calls := 0
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "test-model"},
+		bus: eventBus,
+		greeter: channels.NewGreeter(map[string]config.ChannelGreeting{
+			"telegram": {Message: "Hello, I'm {{.Name}}."},
+		}, nil),
+		provider: &MockProvider{
+			CompleteFunc: func(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+				calls++
+				return &llm.ChatResponse{Content: "reply"}, nil
+			},
+		},
+	}
+
+	events, cancel := eventBus.Subscribe(bus.EventChannelOutboundMessage)
+	defer cancel()
+
+	next := func() string {
+		select {
+		case evt := <-events:
+			payload, _ := evt.Payload.(map[string]interface{})
+			content, _ := payload["content"].(string)
+			return content
+		case <-time.After(500 * time.Millisecond):
+			return ""
+		}
+	}
+
+	// /start is answered with the greeting only.
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram", ChannelID: "1", Content: "/start",
+	}))
+	if got := next(); got != "Hello, I'm Pryx." {
+		t.Errorf("Expected greeting for /start, got %q", got)
+	}
+	if calls != 0 {
+		t.Errorf("Expected /start not to reach the model, got %d calls", calls)
+	}
+
+	// A first plain message gets the greeting and then a reply.
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram", ChannelID: "2", Content: "hi",
+	}))
+	if got := next(); got != "Hello, I'm Pryx." {
+		t.Errorf("Expected greeting before first reply, got %q", got)
+	}
+	if got := next(); got != "reply" {
+		t.Errorf("Expected model reply, got %q", got)
+	}
+
+	// Later messages are not greeted again.
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram", ChannelID: "2", Content: "again",
+	}))
+	if got := next(); got != "reply" {
+		t.Errorf("Expected only the reply, got %q", got)
+	}
+}
+
+func TestAgent_ReasoningEffort(t *testing.T) {
+	eventBus := bus.New()
+	efforts := make(chan llm.ReasoningEffort, 2)
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "gpt-4o"},
+		bus: eventBus,
+		catalog: &models.Catalog{Models: map[string]models.ModelInfo{
+			"gpt-4o": {ID: "gpt-4o", Provider: "openai"},
+			"o3":     {ID: "o3", Provider: "openai", Reasoning: true},
+		}},
+		provider: &MockProvider{
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				efforts <- req.ReasoningEffort
+				ch := make(chan llm.StreamChunk, 1)
+				ch <- llm.StreamChunk{Content: "ok", Done: true, Usage: &llm.Usage{PromptTokens: 5, CompletionTokens: 40, ReasoningTokens: 32, TotalTokens: 45}}
+				close(ch)
+				return ch, nil
+			},
+		},
+	}
+	errs, cancelErrs := eventBus.Subscribe(bus.EventErrorOccurred)
+	defer cancelErrs()
+	usage, cancelUsage := eventBus.Subscribe(bus.EventLLMUsage)
+	defer cancelUsage()
+
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{
+		"content": "hi", "model": "o3", "reasoning_effort": "High",
+	}))
+	select {
+	case effort := <-efforts:
+		if effort != llm.ReasoningHigh {
+			t.Errorf("Expected effort high, got %q", effort)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("provider was not called")
+	}
+	select {
+	case evt := <-usage:
+		payload := evt.Payload.(map[string]interface{})
+		if payload["reasoning_tokens"] != 32 || payload["reasoning_effort"] != "high" || payload["model"] != "o3" {
+			t.Errorf("Unexpected usage event: %v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an llm.usage event")
+	}
+
+	for _, payload := range []map[string]interface{}{
+		{"content": "hi", "reasoning_effort": "high"},
+		{"content": "hi", "model": "o3", "reasoning_effort": "extreme"},
+	} {
+		agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", payload))
+		select {
+		case evt := <-errs:
+			if evt.Payload.(map[string]interface{})["kind"] != "agent.invalid_reasoning_effort" {
+				t.Errorf("Unexpected error event: %v", evt.Payload)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the effort in %v to be rejected", payload)
+		}
+	}
+	select {
+	case effort := <-efforts:
+		t.Fatalf("Expected rejected requests not to reach the provider, got effort %q", effort)
+	default:
+	}
+}
+
+type memSessionModels map[string]string
+
+func (m memSessionModels) GetSessionModel(sessionID string) (string, error) {
+	return m[sessionID], nil
+}
+
+func (m memSessionModels) SetSessionModel(sessionID, model string) error {
+	m[sessionID] = model
+	return nil
+}
+
+func TestAgent_SessionModelPersists(t *testing.T) {
+	catalog := &models.Catalog{Models: map[string]models.ModelInfo{
+		"o3": {ID: "o3", Provider: "openai"},
+	}}
+	st := memSessionModels{}
+	cfg := &config.Config{ModelProvider: "openai", ModelName: "gpt-4o"}
+
+	first := &Agent{cfg: cfg, bus: bus.New(), catalog: catalog}
+	first.SetSessionModelStore(st)
+	if err := first.SetSessionModel("s1", "o3"); err != nil {
+		t.Fatalf("SetSessionModel failed: %v", err)
+	}
+	if st["s1"] != "o3" {
+		t.Errorf("Expected the override to be stored, got %q", st["s1"])
+	}
+
+	// A restarted agent picks the override up from the store.
+	restarted := &Agent{cfg: cfg, bus: bus.New(), catalog: catalog}
+	restarted.SetSessionModelStore(st)
+	if got := restarted.SessionModel("s1"); got != "o3" {
+		t.Errorf("Expected the stored override after a restart, got %q", got)
+	}
+	if err := restarted.SetSessionModel("s1", ""); err != nil {
+		t.Fatalf("SetSessionModel failed: %v", err)
+	}
+	if got := restarted.SessionModel("s1"); got != "" || st["s1"] != "" {
+		t.Errorf("Expected the override to be cleared, got %q (stored %q)", got, st["s1"])
+	}
+}
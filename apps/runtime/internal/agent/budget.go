@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"errors"
+	"log"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/store"
+)
+
+// BudgetCheck reports whether a session may start another generation. It
+// returns a *store.SessionBudgetExceededError once the session's budget is used up.
+type BudgetCheck func(sessionID string) error
+
+// SetBudgetCheck sets the per-session budget check run before each chat
+// generation. A nil check disables session budgets.
+func (a *Agent) SetBudgetCheck(check BudgetCheck) {
+	a.budgets = check
+}
+
+// withinBudget runs the budget check for sessionID. When the budget is used
+// up it publishes session.budget_exceeded and reports false. Errors reading
+// the budget are logged and do not block the generation.
+func (a *Agent) withinBudget(sessionID string) bool {
+	if a.budgets == nil || sessionID == "" {
+		return true
+	}
+	err := a.budgets(sessionID)
+	if err == nil {
+		return true
+	}
+
+	var exceeded *store.SessionBudgetExceededError
+	if !errors.As(err, &exceeded) {
+		log.Printf("Agent: Failed to check budget of session %s: %v", sessionID, err)
+		return true
+	}
+	log.Printf("Agent: %v", exceeded)
+	a.bus.Publish(bus.NewEvent(bus.EventSessionBudgetExceeded, sessionID, map[string]interface{}{
+		"limit": exceeded.Limit,
+		"used":  exceeded.Used,
+		"max":   exceeded.Max,
+		"error": exceeded.Error(),
+	}))
+	return false
+}
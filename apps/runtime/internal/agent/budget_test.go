@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/store"
+)
+
+func TestAgent_SessionBudget(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("store.New: %v", err)
+	}
+	defer st.Close()
+	limited, _ := st.CreateSession("limited")
+	other, _ := st.CreateSession("other")
+	maxTokens := int64(100)
+	if err := st.SetSessionBudget(limited.ID, store.SessionBudget{MaxTokens: &maxTokens}); err != nil {
+		t.Fatalf("SetSessionBudget: %v", err)
+	}
+	if _, err := st.DB.Exec(`INSERT INTO audit_log (id, timestamp, session_id, action, cost) VALUES ('a1', ?, ?, 'message.send', ?)`,
+		time.Now().UTC(), limited.ID, fmt.Sprintf(`{"total_tokens":%d,"total_cost":0.1}`, maxTokens)); err != nil {
+		t.Fatalf("insert audit entry: %v", err)
+	}
+
+	eventBus := bus.New()
+	calls := make(chan string, 2)
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "gpt-4o"},
+		bus: eventBus,
+		provider: &MockProvider{
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				calls <- req.Messages[1].Content
+				ch := make(chan llm.StreamChunk, 1)
+				ch <- llm.StreamChunk{Content: "ok", Done: true}
+				close(ch)
+				return ch, nil
+			},
+		},
+	}
+	agent.SetBudgetCheck(st.CheckSessionBudget)
+	exceeded, cancel := eventBus.Subscribe(bus.EventSessionBudgetExceeded)
+	defer cancel()
+
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, limited.ID, map[string]interface{}{"content": "again"}))
+	select {
+	case evt := <-exceeded:
+		payload := evt.Payload.(map[string]interface{})
+		if evt.SessionID != limited.ID || payload["limit"] != "max_tokens" || payload["max"] != float64(maxTokens) {
+			t.Errorf("unexpected budget event %s %v", evt.SessionID, payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected session.budget_exceeded")
+	}
+	select {
+	case c := <-calls:
+		t.Fatalf("provider called for a session over budget: %q", c)
+	default:
+	}
+
+	// Other sessions are unaffected.
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, other.ID, map[string]interface{}{"content": "hi"}))
+	select {
+	case c := <-calls:
+		if c != "hi" {
+			t.Errorf("unexpected request %q", c)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("provider was not called for a session within budget")
+	}
+}
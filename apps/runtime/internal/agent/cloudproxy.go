@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/keychain"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/llm/factory"
+	"pryx-core/internal/models"
+)
+
+// UseCloudProxy reports whether generation should go through the cloud proxy:
+// the fallback is enabled, the active provider has no local key, and the user
+// is logged in to Pryx Cloud.
+func UseCloudProxy(cfg *config.Config, kc *keychain.Keychain, catalog *models.Catalog) bool {
+	if !cfg.CloudProxyFallback || kc == nil || strings.TrimSpace(cfg.CloudAPIUrl) == "" {
+		return false
+	}
+	if factory.NewProviderFactory(catalog, kc).HasCredentials(cfg.ModelProvider) {
+		return false
+	}
+	token, err := kc.Get("cloud_access_token")
+	return err == nil && strings.TrimSpace(token) != ""
+}
+
+// cloudProxiedProvider publishes llm.cloud_proxied for every generation sent
+// through the cloud proxy, so proxied usage shows up in the audit log.
+type cloudProxiedProvider struct {
+	inner llm.Provider
+	bus   *bus.Bus
+}
+
+func newCloudProxiedProvider(cfg *config.Config, kc *keychain.Keychain, eventBus *bus.Bus) *cloudProxiedProvider {
+	proxy := factory.NewCloudProxy(cfg.CloudAPIUrl, strings.ToLower(cfg.ModelProvider), func() (string, error) {
+		return kc.Get("cloud_access_token")
+	})
+	return &cloudProxiedProvider{inner: proxy, bus: eventBus}
+}
+
+func (p *cloudProxiedProvider) Complete(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	resp, err := p.inner.Complete(ctx, req)
+	payload := map[string]interface{}{"model": req.Model, "stream": false}
+	if resp != nil {
+		payload["prompt_tokens"] = resp.Usage.PromptTokens
+		payload["completion_tokens"] = resp.Usage.CompletionTokens
+		payload["total_tokens"] = resp.Usage.TotalTokens
+		payload["reasoning_tokens"] = resp.Usage.ReasoningTokens
+	}
+	p.publish(ctx, payload, err)
+	return resp, err
+}
+
+func (p *cloudProxiedProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	upstream, err := p.inner.Stream(ctx, req)
+	if err != nil {
+		p.publish(ctx, map[string]interface{}{"model": req.Model, "stream": true}, err)
+		return nil, err
+	}
+
+	ch := make(chan llm.StreamChunk)
+	go func() {
+		defer close(ch)
+		length := 0
+		var streamErr error
+		for chunk := range upstream {
+			length += len(chunk.Content)
+			if chunk.Err != nil {
+				streamErr = chunk.Err
+			}
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				// Drain upstream; the loop ends once it closes.
+				for range upstream {
+				}
+				streamErr = ctx.Err()
+			}
+		}
+		p.publish(ctx, map[string]interface{}{"model": req.Model, "stream": true, "length": length}, streamErr)
+	}()
+	return ch, nil
+}
+
+func (p *cloudProxiedProvider) publish(ctx context.Context, payload map[string]interface{}, err error) {
+	if p.bus == nil {
+		return
+	}
+	payload["success"] = err == nil
+	if err != nil {
+		payload["error"] = err.Error()
+	}
+	p.bus.Publish(bus.NewEvent(bus.EventLLMCloudProxied, sessionIDFromContext(ctx), payload))
+}
@@ -0,0 +1,73 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/keychain"
+	"pryx-core/internal/llm"
+)
+
+func TestUseCloudProxy(t *testing.T) {
+	t.Setenv("PRYX_KEYCHAIN_FILE", filepath.Join(t.TempDir(), "keychain.json"))
+	t.Setenv("OPENAI_API_KEY", "")
+	kc := keychain.New("test")
+	cfg := &config.Config{ModelProvider: "openai", CloudAPIUrl: "https://cloud.example", CloudProxyFallback: true}
+
+	if UseCloudProxy(cfg, kc, nil) {
+		t.Fatal("expected no proxy without a cloud login")
+	}
+	if err := kc.Set("cloud_access_token", "cloud-token"); err != nil {
+		t.Fatalf("set token: %v", err)
+	}
+	if !UseCloudProxy(cfg, kc, nil) {
+		t.Fatal("expected proxy with a cloud login and no provider key")
+	}
+	if UseCloudProxy(&config.Config{ModelProvider: "openai", CloudAPIUrl: "https://cloud.example"}, kc, nil) {
+		t.Error("expected no proxy while the fallback is disabled")
+	}
+	if err := kc.SetProviderKey("openai", "sk-local"); err != nil {
+		t.Fatalf("set provider key: %v", err)
+	}
+	if UseCloudProxy(cfg, kc, nil) {
+		t.Error("expected a local key to take precedence over the proxy")
+	}
+}
+
+func TestCloudProxiedProviderPublishesUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"}}],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("PRYX_KEYCHAIN_FILE", filepath.Join(t.TempDir(), "keychain.json"))
+	kc := keychain.New("test")
+	if err := kc.Set("cloud_access_token", "cloud-token"); err != nil {
+		t.Fatalf("set token: %v", err)
+	}
+	b := bus.New()
+	events, cancel := b.Subscribe(bus.EventLLMCloudProxied)
+	defer cancel()
+
+	p := newCloudProxiedProvider(&config.Config{ModelProvider: "openai", CloudAPIUrl: srv.URL}, kc, b)
+	if _, err := p.Complete(withSessionID(context.Background(), "s1"), llm.ChatRequest{Model: "gpt-4o"}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	select {
+	case evt := <-events:
+		payload := evt.Payload.(map[string]interface{})
+		if evt.SessionID != "s1" || payload["total_tokens"] != 7 || payload["success"] != true {
+			t.Errorf("unexpected event %s %v", evt.SessionID, payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected llm.cloud_proxied event")
+	}
+}
@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/llm"
+)
+
+// defaultModelFallbackTimeout bounds the wait for a model to start answering
+// before the next fallback is tried, when ModelFallbackTimeout is unset.
+const defaultModelFallbackTimeout = 30 * time.Second
+
+// failedAttempt is a model given up on while opening a stream.
+type failedAttempt struct {
+	model string
+	err   error
+}
+
+// modelChain returns model followed by the configured fallbacks, skipping
+// blanks and models already in the chain.
+func (a *Agent) modelChain(model string) []string {
+	chain := []string{model}
+	for _, fallback := range a.modelConfig().Fallbacks {
+		fallback = strings.TrimSpace(fallback)
+		if fallback != "" && !slices.Contains(chain, fallback) {
+			chain = append(chain, fallback)
+		}
+	}
+	return chain
+}
+
+// openStream streams req from the first model of its fallback chain that
+// starts answering. A model is given up on when it fails with a transient
+// error (server error, rate limit or timeout) or sends nothing within the
+// fallback timeout; the last model of the chain is waited for as long as the
+// provider allows. It returns the stream, the model serving it and a release
+// func to call once the stream is no longer read. Requests that needed a
+// fallback are reported with an llm.fallback event.
+func (a *Agent) openStream(ctx context.Context, sessionID string, req llm.ChatRequest) (<-chan llm.StreamChunk, string, func(), error) {
+	chain := a.modelChain(req.Model)
+	timeout := a.fallbackTimeout()
+
+	requested := req.Model
+	effort := req.ReasoningEffort
+	var failed []failedAttempt
+	for i, model := range chain {
+		last := i == len(chain)-1
+		req.Model = model
+		req.ReasoningEffort = a.attemptEffort(effort, requested, model)
+		attemptCtx, cancel := context.WithCancel(a.requestContext(ctx, sessionID))
+		var timer *time.Timer
+		if !last {
+			timer = time.AfterFunc(timeout, cancel)
+		}
+
+		stream, err := a.provider.Stream(attemptCtx, req)
+		var first llm.StreamChunk
+		received := false
+		if err == nil {
+			first, received = <-stream
+			err = first.Err
+		}
+		if timer != nil && !timer.Stop() {
+			err = fmt.Errorf("%w: %s sent nothing within %s", llm.ErrTimeout, model, timeout)
+		}
+
+		if err != nil && !last && llm.IsTransient(err) && ctx.Err() == nil {
+			log.Printf("Agent: Model %s failed, falling back to %s: %v", model, chain[i+1], err)
+			cancel()
+			if stream != nil {
+				go drainStream(stream)
+			}
+			failed = append(failed, failedAttempt{model: model, err: err})
+			continue
+		}
+		if stream == nil {
+			cancel()
+			a.publishFallback(sessionID, requested, "", failed, err)
+			return nil, model, nil, err
+		}
+
+		a.publishFallback(sessionID, requested, model, failed, err)
+		out := make(chan llm.StreamChunk)
+		go func() {
+			defer close(out)
+			if received {
+				select {
+				case out <- first:
+				case <-attemptCtx.Done():
+					drainStream(stream)
+					return
+				}
+			}
+			for chunk := range stream {
+				select {
+				case out <- chunk:
+				case <-attemptCtx.Done():
+					drainStream(stream)
+					return
+				}
+			}
+		}()
+		return out, model, cancel, nil
+	}
+	return nil, requested, nil, fmt.Errorf("empty model chain")
+}
+
+// complete is the non-streaming openStream: it returns the response of the
+// first model of req's fallback chain that answers, and that model. A model is
+// given up on when it fails with a transient error or does not answer within
+// the fallback timeout.
+func (a *Agent) complete(ctx context.Context, sessionID string, req llm.ChatRequest) (*llm.ChatResponse, string, error) {
+	chain := a.modelChain(req.Model)
+	timeout := a.fallbackTimeout()
+
+	requested := req.Model
+	effort := req.ReasoningEffort
+	var failed []failedAttempt
+	for i, model := range chain {
+		last := i == len(chain)-1
+		req.Model = model
+		req.ReasoningEffort = a.attemptEffort(effort, requested, model)
+		attemptCtx, cancel := context.WithCancel(a.requestContext(ctx, sessionID))
+		var timer *time.Timer
+		if !last {
+			timer = time.AfterFunc(timeout, cancel)
+		}
+
+		resp, err := a.provider.Complete(attemptCtx, req)
+		if timer != nil && !timer.Stop() && err != nil {
+			err = fmt.Errorf("%w: %s did not answer within %s", llm.ErrTimeout, model, timeout)
+		}
+		cancel()
+
+		if err != nil && !last && llm.IsTransient(err) && ctx.Err() == nil {
+			log.Printf("Agent: Model %s failed, falling back to %s: %v", model, chain[i+1], err)
+			failed = append(failed, failedAttempt{model: model, err: err})
+			continue
+		}
+		if err != nil {
+			a.publishFallback(sessionID, requested, "", failed, err)
+			return nil, model, err
+		}
+		a.publishFallback(sessionID, requested, model, failed, nil)
+		return resp, model, nil
+	}
+	return nil, requested, fmt.Errorf("empty model chain")
+}
+
+// fallbackTimeout returns how long a model that is not the last of its chain
+// is waited for.
+func (a *Agent) fallbackTimeout() time.Duration {
+	if timeout := a.modelConfig().FallbackTimeout; timeout > 0 {
+		return timeout
+	}
+	return defaultModelFallbackTimeout
+}
+
+// attemptEffort returns the reasoning effort to ask model for. Fallbacks that
+// don't reason are asked without the effort.
+func (a *Agent) attemptEffort(effort llm.ReasoningEffort, requested, model string) llm.ReasoningEffort {
+	if effort != "" && model != requested && a.modelCatalog().ValidateReasoning(model) != nil {
+		return ""
+	}
+	return effort
+}
+
+// publishFallback reports the models given up on for a request and the model
+// that served it, if any.
+func (a *Agent) publishFallback(sessionID, requested, served string, failed []failedAttempt, err error) {
+	if len(failed) == 0 {
+		return
+	}
+	attempts := make([]map[string]interface{}, 0, len(failed))
+	for _, f := range failed {
+		attempts = append(attempts, map[string]interface{}{
+			"model":   f.model,
+			"error":   f.err.Error(),
+			"timeout": llm.IsTimeout(f.err),
+		})
+	}
+	payload := map[string]interface{}{
+		"requested_model": requested,
+		"model":           served,
+		"provider":        strings.ToLower(a.providerName()),
+		"failed":          attempts,
+		"success":         err == nil,
+	}
+	if err != nil {
+		payload["error"] = err.Error()
+	}
+	a.bus.Publish(bus.NewEvent(bus.EventLLMFallback, sessionID, payload))
+}
+
+// drainStream reads a stream that is no longer wanted until the provider
+// closes it, so the provider's goroutine can finish.
+func drainStream(stream <-chan llm.StreamChunk) {
+	for range stream {
+	}
+}
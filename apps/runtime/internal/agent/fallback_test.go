@@ -0,0 +1,208 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm"
+)
+
+// fallbackProvider answers for the models in answers and fails the others
+// with the error in errs. Models in neither hang until their request ends.
+type fallbackProvider struct {
+	MockProvider
+	answers map[string]string
+	errs    map[string]error
+
+	mu    sync.Mutex
+	tried []string
+}
+
+func (p *fallbackProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	p.mu.Lock()
+	p.tried = append(p.tried, req.Model)
+	p.mu.Unlock()
+	if err, ok := p.errs[req.Model]; ok {
+		return nil, err
+	}
+	ch := make(chan llm.StreamChunk, 1)
+	go func() {
+		defer close(ch)
+		answer, ok := p.answers[req.Model]
+		if !ok {
+			<-ctx.Done()
+			ch <- llm.StreamChunk{Err: ctx.Err()}
+			return
+		}
+		ch <- llm.StreamChunk{Content: answer, Done: true}
+	}()
+	return ch, nil
+}
+
+func (p *fallbackProvider) Complete(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.mu.Lock()
+	p.tried = append(p.tried, req.Model)
+	p.mu.Unlock()
+	if err, ok := p.errs[req.Model]; ok {
+		return nil, err
+	}
+	answer, ok := p.answers[req.Model]
+	if !ok {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &llm.ChatResponse{Content: answer, Role: llm.RoleAssistant}, nil
+}
+
+func (p *fallbackProvider) Tried() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.tried...)
+}
+
+func TestAgent_FallsBackOnTransientError(t *testing.T) {
+	eventBus := bus.New()
+	provider := &fallbackProvider{
+		answers: map[string]string{"backup": "hello from backup"},
+		errs:    map[string]error{"primary": fmt.Errorf("%w: api error: 503", llm.ErrUnavailable)},
+	}
+	agent := &Agent{
+		cfg: &config.Config{
+			ModelProvider:  "openai",
+			ModelName:      "primary",
+			ModelFallbacks: []string{"primary", "backup"},
+		},
+		bus:      eventBus,
+		provider: provider,
+	}
+
+	messages, cancelMessages := eventBus.Subscribe(bus.EventSessionMessage)
+	defer cancelMessages()
+	fallbacks, cancelFallbacks := eventBus.Subscribe(bus.EventLLMFallback)
+	defer cancelFallbacks()
+
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{"content": "hi"}))
+
+	if tried := provider.Tried(); len(tried) != 2 || tried[0] != "primary" || tried[1] != "backup" {
+		t.Fatalf("expected primary then backup to be tried once each, got %v", tried)
+	}
+	done := (<-messages).Payload.(map[string]interface{})
+	if done["content"] != "hello from backup" || done["model"] != "backup" {
+		t.Errorf("expected the answer to be attributed to the fallback model, got %v", done)
+	}
+
+	select {
+	case evt := <-fallbacks:
+		payload := evt.Payload.(map[string]interface{})
+		failed := payload["failed"].([]map[string]interface{})
+		if payload["requested_model"] != "primary" || payload["model"] != "backup" || payload["success"] != true {
+			t.Errorf("unexpected fallback event: %v", payload)
+		}
+		if len(failed) != 1 || failed[0]["model"] != "primary" {
+			t.Errorf("expected the failed primary to be reported, got %v", failed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an llm.fallback event")
+	}
+}
+
+func TestOpenStream_FailsOverAfterTimeout(t *testing.T) {
+	provider := &fallbackProvider{answers: map[string]string{"backup": "ok"}}
+	agent := &Agent{
+		cfg: &config.Config{
+			ModelProvider:        "openai",
+			ModelFallbacks:       []string{"backup"},
+			ModelFallbackTimeout: 20 * time.Millisecond,
+		},
+		bus:      bus.New(),
+		provider: provider,
+	}
+	fallbacks, cancel := agent.bus.Subscribe(bus.EventLLMFallback)
+	defer cancel()
+
+	stream, served, release, err := agent.openStream(context.Background(), "s1", llm.ChatRequest{Model: "slow"})
+	if err != nil {
+		t.Fatalf("openStream failed: %v", err)
+	}
+	defer release()
+	if served != "backup" {
+		t.Fatalf("expected the stalled model to be given up on, served by %q", served)
+	}
+	if chunk := <-stream; chunk.Content != "ok" {
+		t.Errorf("unexpected chunk: %+v", chunk)
+	}
+
+	payload := (<-fallbacks).Payload.(map[string]interface{})
+	failed := payload["failed"].([]map[string]interface{})
+	if len(failed) != 1 || failed[0]["model"] != "slow" || failed[0]["timeout"] != true {
+		t.Errorf("expected the stalled model to be reported as timed out, got %v", failed)
+	}
+}
+
+func TestOpenStream_NoFallbackOnPermanentError(t *testing.T) {
+	provider := &fallbackProvider{
+		answers: map[string]string{"backup": "ok"},
+		errs:    map[string]error{"primary": errors.New("api error: 401 Unauthorized")},
+	}
+	agent := &Agent{
+		cfg:      &config.Config{ModelProvider: "openai", ModelFallbacks: []string{"backup"}},
+		bus:      bus.New(),
+		provider: provider,
+	}
+
+	if _, _, _, err := agent.openStream(context.Background(), "s1", llm.ChatRequest{Model: "primary"}); err == nil {
+		t.Fatal("expected the error of the primary model")
+	}
+	if tried := provider.Tried(); len(tried) != 1 {
+		t.Errorf("expected no fallback after a permanent error, tried %v", tried)
+	}
+}
+
+func TestAgent_ChannelFallsBackOnTransientError(t *testing.T) {
+	eventBus := bus.New()
+	provider := &fallbackProvider{
+		answers: map[string]string{"backup": "hello from backup"},
+		errs:    map[string]error{"primary": fmt.Errorf("%w: api error: 503", llm.ErrUnavailable)},
+	}
+	agent := &Agent{
+		cfg: &config.Config{
+			ModelProvider:  "openai",
+			ModelName:      "primary",
+			ModelFallbacks: []string{"backup"},
+		},
+		bus:      eventBus,
+		provider: provider,
+	}
+
+	outbound, cancelOutbound := eventBus.Subscribe(bus.EventChannelOutboundMessage)
+	defer cancelOutbound()
+	fallbacks, cancelFallbacks := eventBus.Subscribe(bus.EventLLMFallback)
+	defer cancelFallbacks()
+
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram", ChannelID: "42", Content: "hi",
+	}))
+
+	if tried := provider.Tried(); len(tried) != 2 || tried[0] != "primary" || tried[1] != "backup" {
+		t.Fatalf("expected primary then backup to be tried once each, got %v", tried)
+	}
+	reply := (<-outbound).Payload.(map[string]interface{})
+	if reply["content"] != "hello from backup" || reply["model"] != "backup" || reply["provider"] != "openai" {
+		t.Errorf("expected the reply to be attributed to the fallback model, got %v", reply)
+	}
+	select {
+	case evt := <-fallbacks:
+		if payload := evt.Payload.(map[string]interface{}); payload["requested_model"] != "primary" || payload["model"] != "backup" {
+			t.Errorf("unexpected fallback event: %v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an llm.fallback event")
+	}
+}
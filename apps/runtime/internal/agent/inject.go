@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/contentfilter"
+	"pryx-core/internal/llm"
+)
+
+const (
+	// MaxInjectionsPerTurn caps how many messages can be injected into one
+	// in-progress chat turn.
+	MaxInjectionsPerTurn = 5
+	// MaxInjectedChars caps the total length of the messages injected into one turn.
+	MaxInjectedChars = 4000
+)
+
+var (
+	// ErrNoActiveTurn is returned when injecting into a session with no chat
+	// turn in progress; the message should be sent as a new chat request instead.
+	ErrNoActiveTurn = errors.New("no chat turn in progress for session")
+	// ErrInjectionLimit is returned when a turn has no injection budget left.
+	ErrInjectionLimit = errors.New("injection limit reached for this turn")
+)
+
+// injections tracks the chat turn in progress per session and the messages
+// injected into it that the turn has not picked up yet.
+type injections struct {
+	mu    sync.Mutex
+	turns map[string]*chatTurn
+}
+
+// chatTurn is one in-progress chat turn that accepts injected messages.
+type chatTurn struct {
+	q         *injections
+	sessionID string
+	pending   []string
+	accepted  int
+	chars     int
+}
+
+// begin registers a turn for sessionID. It returns nil if the session already
+// has a turn in progress, in which case injections go to that turn.
+func (q *injections) begin(sessionID string) *chatTurn {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.turns == nil {
+		q.turns = map[string]*chatTurn{}
+	}
+	if _, ok := q.turns[sessionID]; ok {
+		return nil
+	}
+	t := &chatTurn{q: q, sessionID: sessionID}
+	q.turns[sessionID] = t
+	return t
+}
+
+// add queues content for the turn in progress in sessionID and returns the
+// number of messages now pending.
+func (q *injections) add(sessionID, content string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	t, ok := q.turns[sessionID]
+	if !ok {
+		return 0, ErrNoActiveTurn
+	}
+	if t.accepted >= MaxInjectionsPerTurn {
+		return 0, fmt.Errorf("%w: at most %d messages", ErrInjectionLimit, MaxInjectionsPerTurn)
+	}
+	if t.chars+len(content) > MaxInjectedChars {
+		return 0, fmt.Errorf("%w: at most %d characters", ErrInjectionLimit, MaxInjectedChars)
+	}
+	t.accepted++
+	t.chars += len(content)
+	t.pending = append(t.pending, content)
+	return len(t.pending), nil
+}
+
+// take returns the pending messages at a step boundary. When none are pending
+// the turn ends, so later injections into the session are refused rather than
+// lost after the final step.
+func (t *chatTurn) take() []string {
+	if t == nil {
+		return nil
+	}
+	t.q.mu.Lock()
+	defer t.q.mu.Unlock()
+	msgs := t.pending
+	t.pending = nil
+	if len(msgs) == 0 {
+		t.removeLocked()
+	}
+	return msgs
+}
+
+// end closes the turn, dropping anything still pending. It is safe to call
+// after take has already ended the turn.
+func (t *chatTurn) end() {
+	if t == nil {
+		return
+	}
+	t.q.mu.Lock()
+	defer t.q.mu.Unlock()
+	if n := len(t.pending); n > 0 {
+		log.Printf("Agent: Dropping %d injected messages for session %s, turn ended early", n, t.sessionID)
+		t.pending = nil
+	}
+	t.removeLocked()
+}
+
+func (t *chatTurn) removeLocked() {
+	if t.q.turns[t.sessionID] == t {
+		delete(t.q.turns, t.sessionID)
+	}
+}
+
+// handleChatInject queues a message for the chat turn in progress in the
+// event's session. The turn adds it to the conversation at its next step
+// boundary instead of answering it as a separate request.
+func (a *Agent) handleChatInject(evt bus.Event) {
+	sessionID := evt.SessionID
+	payload, _ := evt.Payload.(map[string]interface{})
+	content, _ := payload["content"].(string)
+	content = strings.TrimSpace(content)
+	if sessionID == "" || content == "" {
+		return
+	}
+
+	content, allowed := a.filterContent(contentfilter.StagePreSend, sessionID, "", content)
+	if !allowed {
+		a.publishInjectRejected(sessionID, errors.New("message blocked by content filter"))
+		return
+	}
+
+	pending, err := a.injections.add(sessionID, content)
+	if err != nil {
+		a.publishInjectRejected(sessionID, err)
+		return
+	}
+	a.bus.Publish(bus.NewEvent(bus.EventAgentInjected, sessionID, map[string]interface{}{
+		"state":   "queued",
+		"chars":   len(content),
+		"pending": pending,
+	}))
+}
+
+// applyInjections appends the assistant's partial answer and the injected
+// messages to req for the next step. An empty answer, such as one already
+// recorded with its tool calls, is left out. The applied event carries the
+// messages and the model that wrote the partial answer, for the transcript.
+func (a *Agent) applyInjections(sessionID, model string, req *llm.ChatRequest, answer string, injected []string) {
+	if answer != "" {
+		req.Messages = append(req.Messages, llm.Message{Role: llm.RoleAssistant, Content: answer})
+	}
+	for _, msg := range injected {
+		req.Messages = append(req.Messages, llm.Message{Role: llm.RoleUser, Content: msg})
+	}
+	payload := map[string]interface{}{
+		"state":    "applied",
+		"count":    len(injected),
+		"messages": injected,
+	}
+	a.attributeMessage(payload, model)
+	a.bus.Publish(bus.NewEvent(bus.EventAgentInjected, sessionID, payload))
+}
+
+func (a *Agent) publishInjectRejected(sessionID string, err error) {
+	a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionID, map[string]interface{}{
+		"kind":  "agent.inject_rejected",
+		"error": err.Error(),
+	}))
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm"
+)
+
+func TestAgent_InjectIntoRunningTurn(t *testing.T) {
+	eventBus := bus.New()
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var requests []llm.ChatRequest
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "test-model"},
+		bus: eventBus,
+		provider: &MockProvider{
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				mu.Lock()
+				requests = append(requests, req)
+				step := len(requests)
+				mu.Unlock()
+				ch := make(chan llm.StreamChunk, 2)
+				go func() {
+					defer close(ch)
+					ch <- llm.StreamChunk{Content: "writing it in Go"}
+					if step == 1 {
+						<-release
+					}
+					ch <- llm.StreamChunk{Content: ".", Done: true}
+				}()
+				return ch, nil
+			},
+		},
+	}
+
+	messages, cancelMessages := eventBus.Subscribe(bus.EventSessionMessage)
+	defer cancelMessages()
+	injectedEvents, cancelInjected := eventBus.Subscribe(bus.EventAgentInjected)
+	defer cancelInjected()
+
+	finished := make(chan struct{})
+	go func() {
+		agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{"content": "write a script"}))
+		close(finished)
+	}()
+
+	// Inject once the first step is streaming.
+	<-messages
+	agent.handleChatInject(bus.NewEvent(bus.EventChatInject, "s1", map[string]interface{}{"content": "also, use Python not Go"}))
+	if evt := (<-injectedEvents).Payload.(map[string]interface{}); evt["state"] != "queued" {
+		t.Fatalf("expected queued event, got %v", evt)
+	}
+	close(release)
+
+	select {
+	case <-finished:
+	case <-time.After(2 * time.Second):
+		t.Fatal("chat turn did not finish")
+	}
+	evt := (<-injectedEvents).Payload.(map[string]interface{})
+	if evt["state"] != "applied" || evt["count"] != 1 {
+		t.Fatalf("expected applied event, got %v", evt)
+	}
+	if msgs, _ := evt["messages"].([]string); len(msgs) != 1 || msgs[0] != "also, use Python not Go" || evt["model"] != "test-model" {
+		t.Errorf("expected the applied event to carry the message and model, got %v", evt)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requests) != 2 {
+		t.Fatalf("expected a second step after the injection, got %d requests", len(requests))
+	}
+	second := requests[1].Messages
+	if n := len(second); n != 4 || second[2].Role != llm.RoleAssistant || second[3].Content != "also, use Python not Go" {
+		t.Fatalf("unexpected second step messages: %+v", second)
+	}
+
+	var doneCount int
+	for len(messages) > 0 {
+		payload := (<-messages).Payload.(map[string]interface{})
+		if payload["done"] == true {
+			doneCount++
+			if payload["model"] != "test-model" || payload["provider"] != "openai" {
+				t.Errorf("expected the done message to name its model, got %v", payload)
+			}
+		}
+	}
+	if doneCount != 1 {
+		t.Fatalf("expected exactly one done message, got %d", doneCount)
+	}
+
+	// The turn is over, so further injections are refused.
+	if _, err := agent.injections.add("s1", "too late"); !errors.Is(err, ErrNoActiveTurn) {
+		t.Fatalf("expected ErrNoActiveTurn after the turn, got %v", err)
+	}
+}
+
+func TestInjectionsBounded(t *testing.T) {
+	var q injections
+	if _, err := q.add("s1", "hi"); !errors.Is(err, ErrNoActiveTurn) {
+		t.Fatalf("expected ErrNoActiveTurn, got %v", err)
+	}
+
+	turn := q.begin("s1")
+	if q.begin("s1") != nil {
+		t.Fatal("second turn for the same session should not register")
+	}
+	if _, err := q.add("s1", strings.Repeat("x", MaxInjectedChars+1)); !errors.Is(err, ErrInjectionLimit) {
+		t.Fatalf("expected size limit, got %v", err)
+	}
+	for i := 0; i < MaxInjectionsPerTurn; i++ {
+		if _, err := q.add("s1", "note"); err != nil {
+			t.Fatalf("add %d: %v", i, err)
+		}
+	}
+	if _, err := q.add("s1", "one more"); !errors.Is(err, ErrInjectionLimit) {
+		t.Fatalf("expected count limit, got %v", err)
+	}
+	if got := turn.take(); len(got) != MaxInjectionsPerTurn {
+		t.Fatalf("take returned %d messages", len(got))
+	}
+	// Messages taken still count against the turn's budget.
+	if _, err := q.add("s1", "again"); !errors.Is(err, ErrInjectionLimit) {
+		t.Fatalf("expected count limit after take, got %v", err)
+	}
+	turn.end()
+	if q.begin("s1") == nil {
+		t.Fatal("a new turn should register once the previous one ended")
+	}
+}
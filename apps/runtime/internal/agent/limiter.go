@@ -0,0 +1,227 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm"
+)
+
+// ErrBusy is returned by GenerationLimiter.Acquire when every slot is in use and
+// the wait queue is full.
+var ErrBusy = errors.New("too many generations in progress")
+
+// DefaultGenerationQueueSize is how many generations may wait for a slot when
+// GenerationQueueSize is not configured.
+const DefaultGenerationQueueSize = 32
+
+// generationQueueSize resolves the configured queue size: zero means the
+// default and a negative size means no queue.
+func generationQueueSize(configured int) int {
+	switch {
+	case configured == 0:
+		return DefaultGenerationQueueSize
+	case configured < 0:
+		return 0
+	default:
+		return configured
+	}
+}
+
+// busyNotice is sent to channel users whose message was rejected for load.
+const busyNotice = "I'm handling a lot of requests right now. Please try again in a moment."
+
+// GenerationStats describes current generation load.
+type GenerationStats struct {
+	Active    int `json:"active"`
+	Queued    int `json:"queued"`
+	Max       int `json:"max"`
+	QueueSize int `json:"queue_size"`
+}
+
+// GenerationLimiter bounds how many LLM generations run at once across all
+// sessions and channels. Requests beyond the limit wait in a bounded queue;
+// freed slots are handed out round-robin by key (session or chat), so one busy
+// conversation cannot starve the others. A nil limiter or a max of zero
+// imposes no limit.
+type GenerationLimiter struct {
+	max       int
+	queueSize int
+
+	mu      sync.Mutex
+	active  int
+	queued  int
+	waiters map[string][]chan struct{}
+	order   []string // keys with waiters, in turn order
+}
+
+// NewGenerationLimiter allows max concurrent generations (0 = unlimited) with up
+// to queueSize waiting for a slot.
+func NewGenerationLimiter(max, queueSize int) *GenerationLimiter {
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	return &GenerationLimiter{max: max, queueSize: queueSize, waiters: make(map[string][]chan struct{})}
+}
+
+// Acquire takes a generation slot for key, waiting its turn if all slots are in
+// use. It returns ErrBusy when the queue is full, or ctx's error if ctx ends
+// first. queued is called once if the request has to wait. The returned release
+// must be called when the generation finishes.
+func (l *GenerationLimiter) Acquire(ctx context.Context, key string, queued func()) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.max <= 0 || (l.active < l.max && l.queued == 0) {
+		l.active++
+		l.mu.Unlock()
+		return l.releaseOnce(), nil
+	}
+	if l.queued >= l.queueSize {
+		l.mu.Unlock()
+		return nil, ErrBusy
+	}
+	ch := make(chan struct{})
+	if len(l.waiters[key]) == 0 {
+		l.order = append(l.order, key)
+	}
+	l.waiters[key] = append(l.waiters[key], ch)
+	l.queued++
+	l.mu.Unlock()
+
+	if queued != nil {
+		queued()
+	}
+
+	select {
+	case <-ch:
+		return l.releaseOnce(), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		removed := l.removeWaiter(key, ch)
+		l.mu.Unlock()
+		if !removed {
+			// The slot was granted while ctx ended; hand it on.
+			l.release()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Stats reports current load.
+func (l *GenerationLimiter) Stats() GenerationStats {
+	if l == nil {
+		return GenerationStats{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return GenerationStats{Active: l.active, Queued: l.queued, Max: l.max, QueueSize: l.queueSize}
+}
+
+func (l *GenerationLimiter) releaseOnce() func() {
+	var once sync.Once
+	return func() { once.Do(l.release) }
+}
+
+func (l *GenerationLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.dispatch()
+}
+
+// dispatch hands free slots to waiters, taking one from each key in turn.
+func (l *GenerationLimiter) dispatch() {
+	for l.active < l.max && len(l.order) > 0 {
+		key := l.order[0]
+		l.order = l.order[1:]
+		queue := l.waiters[key]
+		ch := queue[0]
+		if len(queue) == 1 {
+			delete(l.waiters, key)
+		} else {
+			l.waiters[key] = queue[1:]
+			l.order = append(l.order, key)
+		}
+		l.queued--
+		l.active++
+		close(ch)
+	}
+}
+
+// removeWaiter drops ch from key's queue and reports whether it was still waiting.
+func (l *GenerationLimiter) removeWaiter(key string, ch chan struct{}) bool {
+	queue := l.waiters[key]
+	for i, c := range queue {
+		if c != ch {
+			continue
+		}
+		queue = append(queue[:i:i], queue[i+1:]...)
+		l.queued--
+		if len(queue) > 0 {
+			l.waiters[key] = queue
+			return true
+		}
+		delete(l.waiters, key)
+		for j, k := range l.order {
+			if k == key {
+				l.order = append(l.order[:j:j], l.order[j+1:]...)
+				break
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Generations returns the agent's generation limiter.
+func (a *Agent) Generations() *GenerationLimiter {
+	return a.generations
+}
+
+// RateLimits returns the agent's per-model rate limiter.
+func (a *Agent) RateLimits() *llm.ModelLimiter {
+	return a.rateLimits
+}
+
+// newModelLimiter builds the per-model limiter from cfg.ModelRateLimits.
+func newModelLimiter(cfg *config.Config) *llm.ModelLimiter {
+	limits := make(map[string]llm.ModelLimit, len(cfg.ModelRateLimits))
+	for key, l := range cfg.ModelRateLimits {
+		limits[key] = llm.ModelLimit{RequestsPerMinute: l.RequestsPerMinute, MaxConcurrent: l.MaxConcurrent}
+	}
+	return llm.NewModelLimiter(strings.ToLower(cfg.ModelProvider), limits)
+}
+
+// acquireGeneration takes a generation slot for key, publishing agent.busy when
+// the request has to queue or is refused. It returns nil if no slot was obtained.
+func (a *Agent) acquireGeneration(ctx context.Context, sessionID, key string) func() {
+	release, err := a.generations.Acquire(ctx, key, func() {
+		a.publishBusy(sessionID, "queued")
+	})
+	if err != nil {
+		if errors.Is(err, ErrBusy) {
+			log.Printf("Agent: Generation limit reached, rejecting request (%s)", key)
+			a.publishBusy(sessionID, "rejected")
+		}
+		return nil
+	}
+	return release
+}
+
+func (a *Agent) publishBusy(sessionID, state string) {
+	stats := a.generations.Stats()
+	a.bus.Publish(bus.NewEvent(bus.EventAgentBusy, sessionID, map[string]interface{}{
+		"state":  state,
+		"active": stats.Active,
+		"queued": stats.Queued,
+		"max":    stats.Max,
+	}))
+}
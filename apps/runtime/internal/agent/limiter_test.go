@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+	"pryx-core/internal/config"
+)
+
+func TestGenerationLimiterQueuesAndRejects(t *testing.T) {
+	l := NewGenerationLimiter(1, 1)
+	ctx := context.Background()
+
+	release, err := l.Acquire(ctx, "a", nil)
+	if err != nil {
+		t.Fatalf("first Acquire: %v", err)
+	}
+
+	queued := make(chan struct{})
+	granted := make(chan func())
+	go func() {
+		r, err := l.Acquire(ctx, "b", func() { close(queued) })
+		if err != nil {
+			t.Errorf("queued Acquire: %v", err)
+		}
+		granted <- r
+	}()
+	<-queued
+
+	if _, err := l.Acquire(ctx, "c", nil); !errors.Is(err, ErrBusy) {
+		t.Fatalf("expected ErrBusy with a full queue, got %v", err)
+	}
+	if got := l.Stats(); got.Active != 1 || got.Queued != 1 {
+		t.Fatalf("Stats() = %+v, want 1 active and 1 queued", got)
+	}
+
+	release()
+	release() // releasing twice is harmless
+	select {
+	case r := <-granted:
+		r()
+	case <-time.After(time.Second):
+		t.Fatal("queued request was not granted after release")
+	}
+	if got := l.Stats(); got.Active != 0 || got.Queued != 0 {
+		t.Fatalf("Stats() = %+v, want idle", got)
+	}
+}
+
+func TestGenerationLimiterRoundRobin(t *testing.T) {
+	l := NewGenerationLimiter(1, 10)
+	ctx := context.Background()
+	hold, _ := l.Acquire(ctx, "busy", nil)
+
+	order := make(chan string, 4)
+	enqueue := func(key string) {
+		queued := make(chan struct{})
+		go func() {
+			r, err := l.Acquire(ctx, key, func() { close(queued) })
+			if err != nil {
+				t.Errorf("Acquire(%s): %v", key, err)
+				return
+			}
+			order <- key
+			r()
+		}()
+		<-queued
+	}
+	enqueue("a")
+	enqueue("a")
+	enqueue("a")
+	enqueue("b")
+
+	hold()
+	var got []string
+	for i := 0; i < 4; i++ {
+		select {
+		case key := <-order:
+			got = append(got, key)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out, got %v", got)
+		}
+	}
+	if got[0] != "a" || got[1] != "b" {
+		t.Fatalf("expected b to be served second, got %v", got)
+	}
+}
+
+func TestGenerationLimiterCancelledWaiter(t *testing.T) {
+	l := NewGenerationLimiter(1, 1)
+	hold, _ := l.Acquire(context.Background(), "a", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := l.Acquire(ctx, "b", func() { close(queued) })
+		done <- err
+	}()
+	<-queued
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if got := l.Stats(); got.Queued != 0 {
+		t.Fatalf("cancelled waiter still queued: %+v", got)
+	}
+
+	hold()
+	if _, err := l.Acquire(context.Background(), "c", nil); err != nil {
+		t.Fatalf("slot not freed: %v", err)
+	}
+}
+
+func TestGenerationLimiterUnlimited(t *testing.T) {
+	var nilLimiter *GenerationLimiter
+	if _, err := nilLimiter.Acquire(context.Background(), "a", nil); err != nil {
+		t.Fatalf("nil limiter: %v", err)
+	}
+	l := NewGenerationLimiter(0, 0)
+	for i := 0; i < 50; i++ {
+		if _, err := l.Acquire(context.Background(), "a", nil); err != nil {
+			t.Fatalf("unlimited Acquire: %v", err)
+		}
+	}
+	if got := l.Stats().Active; got != 50 {
+		t.Fatalf("expected 50 active, got %d", got)
+	}
+}
+
+func TestAgent_ChannelMessageBusy(t *testing.T) {
+	eventBus := bus.New()
+	a := &Agent{
+		cfg:         &config.Config{ModelProvider: "openai", ModelName: "test-model"},
+		bus:         eventBus,
+		provider:    &MockProvider{},
+		generations: NewGenerationLimiter(1, 0),
+	}
+	hold, _ := a.generations.Acquire(context.Background(), "other", nil)
+	defer hold()
+
+	events, cancel := eventBus.Subscribe(bus.EventChannelOutboundMessage, bus.EventAgentBusy)
+	defer cancel()
+
+	a.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram-main", ChannelID: "42", Content: "hello",
+	}))
+
+	var sawBusy, sawNotice bool
+	timeout := time.After(time.Second)
+	for !(sawBusy && sawNotice) {
+		select {
+		case evt := <-events:
+			payload := evt.Payload.(map[string]interface{})
+			switch evt.Event {
+			case bus.EventAgentBusy:
+				sawBusy = payload["state"] == "rejected"
+			case bus.EventChannelOutboundMessage:
+				sawNotice = payload["content"] == busyNotice
+			}
+		case <-timeout:
+			t.Fatalf("busy=%v notice=%v", sawBusy, sawNotice)
+		}
+	}
+}
+
+func TestGenerationQueueSizeDefaults(t *testing.T) {
+	for configured, want := range map[int]int{0: DefaultGenerationQueueSize, -1: 0, 5: 5} {
+		if got := generationQueueSize(configured); got != want {
+			t.Errorf("generationQueueSize(%d) = %d, want %d", configured, got, want)
+		}
+	}
+
+	a, err := New(&config.Config{ModelProvider: "ollama", ModelName: "llama3", MaxConcurrentGenerations: 1}, bus.New(), nil, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if stats := a.Generations().Stats(); stats.QueueSize != DefaultGenerationQueueSize {
+		t.Errorf("Expected requests over the cap to queue by default, got %+v", stats)
+	}
+}
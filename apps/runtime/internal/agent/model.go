@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/models"
+)
+
+// SessionModelStore persists session model overrides so they survive a
+// restart. GetSessionModel returns "" for a session without an override.
+type SessionModelStore interface {
+	GetSessionModel(sessionID string) (string, error)
+	SetSessionModel(sessionID, model string) error
+}
+
+// SetSessionModelStore sets where session model overrides are persisted. A
+// nil store keeps them in memory only.
+func (a *Agent) SetSessionModelStore(st SessionModelStore) {
+	a.sessionModelMu.Lock()
+	defer a.sessionModelMu.Unlock()
+	a.sessionModelStore = st
+}
+
+// SetSessionModel sets the model used for a session's requests that do not name
+// one. An empty model clears the override. The model is validated against the
+// catalog. Overrides of sessions the store does not know are kept in memory.
+func (a *Agent) SetSessionModel(sessionID, model string) error {
+	model = strings.TrimSpace(model)
+	if model != "" {
+		if err := a.modelCatalog().ValidateModel(a.providerName(), model); err != nil {
+			return err
+		}
+	}
+	a.sessionModelMu.Lock()
+	defer a.sessionModelMu.Unlock()
+	if a.sessionModelStore != nil {
+		if err := a.sessionModelStore.SetSessionModel(sessionID, model); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+	if a.sessionModel == nil {
+		a.sessionModel = make(map[string]string)
+	}
+	a.sessionModel[sessionID] = model
+	return nil
+}
+
+// SessionModel returns the session's model override, if any, loading it from
+// the store the first time the session is seen.
+func (a *Agent) SessionModel(sessionID string) string {
+	a.sessionModelMu.RLock()
+	model, cached := a.sessionModel[sessionID]
+	st := a.sessionModelStore
+	a.sessionModelMu.RUnlock()
+	if cached || st == nil || sessionID == "" {
+		return model
+	}
+
+	model, err := st.GetSessionModel(sessionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("Agent: Failed to load model of session %s: %v", sessionID, err)
+		return ""
+	}
+	a.sessionModelMu.Lock()
+	defer a.sessionModelMu.Unlock()
+	if current, ok := a.sessionModel[sessionID]; ok {
+		return current
+	}
+	if a.sessionModel == nil {
+		a.sessionModel = make(map[string]string)
+	}
+	a.sessionModel[sessionID] = model
+	return model
+}
+
+// forgetSessionModel drops the cached override of a deleted session.
+func (a *Agent) forgetSessionModel(sessionID string) {
+	a.sessionModelMu.Lock()
+	defer a.sessionModelMu.Unlock()
+	delete(a.sessionModel, sessionID)
+}
+
+// resolveModel picks the model for a request by precedence
+// request > session > channel > global default, validates overrides against
+// the catalog and publishes the choice so it is auditable.
+func (a *Agent) resolveModel(sessionID, requestModel, channelID string) (string, error) {
+	sel := models.Selection{
+		Request:     requestModel,
+		Session:     a.SessionModel(sessionID),
+		Scope:       a.cfg.ChannelModels[channelID],
+		ScopeSource: models.SourceChannel,
+		Default:     a.modelConfig().Name,
+	}
+	model, source := sel.Resolve()
+	if source != models.SourceDefault {
+		if err := a.modelCatalog().ValidateModel(a.providerName(), model); err != nil {
+			return "", err
+		}
+	}
+
+	payload := map[string]interface{}{
+		"kind":   "agent.model.resolved",
+		"model":  model,
+		"source": source,
+	}
+	if channelID != "" {
+		payload["channel"] = channelID
+	}
+	a.bus.Publish(bus.NewEvent(bus.EventTraceEvent, sessionID, payload))
+	return model, nil
+}
+
+func (a *Agent) publishModelError(sessionID string, err error) {
+	a.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionID, map[string]interface{}{
+		"kind":  "agent.invalid_model",
+		"error": err.Error(),
+	}))
+}
+
+// reasoningEffort returns the reasoning effort a chat request asks for, if
+// any. It is rejected for models the catalog does not list as reasoning
+// models.
+func (a *Agent) reasoningEffort(model string, payload map[string]interface{}) (llm.ReasoningEffort, error) {
+	raw, _ := payload["reasoning_effort"].(string)
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	effort, err := llm.ParseReasoningEffort(raw)
+	if err != nil {
+		return "", err
+	}
+	if err := a.modelCatalog().ValidateReasoning(model); err != nil {
+		return "", err
+	}
+	return effort, nil
+}
+
+// publishUsage reports the token usage of a generation, with the tokens spent
+// reasoning counted separately, so it can be audited.
+func (a *Agent) publishUsage(sessionID, model string, effort llm.ReasoningEffort, usage llm.Usage) {
+	payload := map[string]interface{}{
+		"model":             model,
+		"prompt_tokens":     usage.PromptTokens,
+		"completion_tokens": usage.CompletionTokens,
+		"reasoning_tokens":  usage.ReasoningTokens,
+		"total_tokens":      usage.TotalTokens,
+	}
+	if effort != "" {
+		payload["reasoning_effort"] = string(effort)
+	}
+	a.bus.Publish(bus.NewEvent(bus.EventLLMUsage, sessionID, payload))
+}
+
+// attributeMessage records on the payload of the session.message event that
+// completes a response, or of a channel reply, which model and provider
+// produced it.
+func (a *Agent) attributeMessage(payload map[string]interface{}, model string) {
+	payload["model"] = model
+	payload["provider"] = strings.ToLower(a.providerName())
+}
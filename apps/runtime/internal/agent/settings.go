@@ -0,0 +1,77 @@
+package agent
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"pryx-core/internal/config"
+)
+
+// ModelConfig is the part of the model selection a reload can change while
+// the agent runs.
+type ModelConfig struct {
+	Name            string
+	Fallbacks       []string
+	FallbackTimeout time.Duration
+}
+
+// ModelConfigFrom copies the live model settings out of cfg.
+func ModelConfigFrom(cfg *config.Config) ModelConfig {
+	return ModelConfig{
+		Name:            cfg.ModelName,
+		Fallbacks:       slices.Clone(cfg.ModelFallbacks),
+		FallbackTimeout: cfg.ModelFallbackTimeout,
+	}
+}
+
+// ModelSettings holds the agent's current ModelConfig. Generations read it
+// while a reload replaces it, so it is safe for concurrent use.
+type ModelSettings struct {
+	mu  sync.RWMutex
+	cfg ModelConfig
+}
+
+// NewModelSettings creates settings holding the model config of cfg.
+func NewModelSettings(cfg *config.Config) *ModelSettings {
+	return &ModelSettings{cfg: ModelConfigFrom(cfg)}
+}
+
+// Load returns a copy of the current model config.
+func (m *ModelSettings) Load() ModelConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	c := m.cfg
+	c.Fallbacks = slices.Clone(c.Fallbacks)
+	return c
+}
+
+// Store replaces the current model config.
+func (m *ModelSettings) Store(c ModelConfig) {
+	c.Fallbacks = slices.Clone(c.Fallbacks)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cfg = c
+}
+
+// Models returns the agent's live model settings.
+func (a *Agent) Models() *ModelSettings {
+	return a.models
+}
+
+// modelConfig returns the model config in effect for the next request.
+func (a *Agent) modelConfig() ModelConfig {
+	if a.models == nil {
+		return ModelConfigFrom(a.cfg)
+	}
+	return a.models.Load()
+}
+
+// providerName returns the provider the agent's client was built for. It is
+// fixed for the agent's lifetime; changing it needs a restart.
+func (a *Agent) providerName() string {
+	if a.providerID == "" {
+		return a.cfg.ModelProvider
+	}
+	return a.providerID
+}
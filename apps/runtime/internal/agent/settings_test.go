@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm"
+)
+
+func TestAgent_ModelSettingsReload(t *testing.T) {
+	cfg := &config.Config{ModelProvider: "openai", ModelName: "gpt-4o"}
+	settings := NewModelSettings(cfg)
+	var mu sync.Mutex
+	var models []string
+	agent := &Agent{
+		cfg:    cfg,
+		bus:    bus.New(),
+		models: settings,
+		provider: &MockProvider{
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				mu.Lock()
+				models = append(models, req.Model)
+				mu.Unlock()
+				ch := make(chan llm.StreamChunk, 1)
+				ch <- llm.StreamChunk{Content: "ok", Done: true}
+				close(ch)
+				return ch, nil
+			},
+		},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		settings.Store(ModelConfig{Name: "gpt-4o-mini", Fallbacks: []string{"gpt-4o"}})
+	}()
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{"content": "hi"}))
+	wg.Wait()
+
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{"content": "hi"}))
+	if got := models[len(models)-1]; got != "gpt-4o-mini" {
+		t.Errorf("Expected the reloaded default model, got %q", got)
+	}
+	if chain := agent.modelChain("gpt-4o-mini"); len(chain) != 2 || chain[1] != "gpt-4o" {
+		t.Errorf("Expected the reloaded fallbacks, got %v", chain)
+	}
+	if cfg.ModelName != "gpt-4o" {
+		t.Errorf("Expected the shared config to be left alone, got %q", cfg.ModelName)
+	}
+}
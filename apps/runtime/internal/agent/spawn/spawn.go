@@ -2,6 +2,7 @@ package spawn
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -45,6 +46,18 @@ const (
 	StatusCancelled Status = "cancelled"
 )
 
+// Finished reports whether the status is terminal.
+func (st Status) Finished() bool {
+	return st == StatusCompleted || st == StatusFailed || st == StatusCancelled
+}
+
+var (
+	// ErrAgentNotFound is returned for an unknown sub-agent ID.
+	ErrAgentNotFound = errors.New("agent not found")
+	// ErrAgentFinished is returned when cancelling a sub-agent that has already finished.
+	ErrAgentFinished = errors.New("agent already finished")
+)
+
 // Result contains the output of a sub-agent execution
 type Result struct {
 	AgentID   string
@@ -136,6 +149,22 @@ func (s *Spawner) Fork(ctx context.Context, sourceSessionID string) (string, err
 	return newSession.ID, nil
 }
 
+// ForkAt creates a new session from sourceSessionID containing messages up to and
+// including messageID.
+func (s *Spawner) ForkAt(ctx context.Context, sourceSessionID string, messageID string) (string, error) {
+	if s.store == nil {
+		return "", fmt.Errorf("store not available for session fork")
+	}
+
+	newSession, err := s.store.CopySessionUpTo(sourceSessionID, messageID, fmt.Sprintf("Fork of %s", sourceSessionID))
+	if err != nil {
+		return "", fmt.Errorf("failed to fork session: %w", err)
+	}
+
+	log.Printf("Forked session %s at message %s to %s", sourceSessionID, messageID, newSession.ID)
+	return newSession.ID, nil
+}
+
 // Get retrieves a sub-agent by ID
 func (s *Spawner) Get(agentID string) (*SubAgent, bool) {
 	s.mu.RLock()
@@ -156,20 +185,32 @@ func (s *Spawner) List() []*SubAgent {
 	return agents
 }
 
-// Cancel stops a running sub-agent
+// Cancel stops a pending or running sub-agent and publishes an
+// EventAgentCancelled event. It returns ErrAgentNotFound for an unknown ID and
+// ErrAgentFinished if the agent has already finished.
 func (s *Spawner) Cancel(agentID string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	agent, ok := s.agents[agentID]
+	agent, ok := s.Get(agentID)
 	if !ok {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, agentID)
 	}
 
-	agent.cancel()
 	agent.mu.Lock()
+	if agent.Status.Finished() {
+		status := agent.Status
+		agent.mu.Unlock()
+		return fmt.Errorf("%w: %s is %s", ErrAgentFinished, agentID, status)
+	}
 	agent.Status = StatusCancelled
 	agent.mu.Unlock()
+	agent.cancel()
+
+	if s.bus != nil {
+		s.bus.Publish(bus.NewEvent(bus.EventAgentCancelled, agent.SessionID, map[string]interface{}{
+			"agent_id":  agent.ID,
+			"parent_id": agent.ParentID,
+		}))
+	}
+	log.Printf("Cancelled sub-agent %s", agentID)
 	return nil
 }
 
@@ -192,6 +233,11 @@ func (s *Spawner) Cleanup(maxAge time.Duration) {
 // run executes the sub-agent's task
 func (a *SubAgent) run(ctx context.Context, task string) {
 	a.mu.Lock()
+	if a.Status != StatusPending {
+		// Cancelled before it started.
+		a.mu.Unlock()
+		return
+	}
 	a.Status = StatusRunning
 	a.mu.Unlock()
 	startTime := time.Now()
@@ -222,9 +268,10 @@ func (a *SubAgent) run(ctx context.Context, task string) {
 	duration := time.Since(startTime)
 
 	if err != nil {
-		a.mu.Lock()
-		a.Status = StatusFailed
-		a.mu.Unlock()
+		if !a.finish(StatusFailed, 0) {
+			a.publishCancelled(duration)
+			return
+		}
 		a.publishResult(Result{
 			AgentID:  a.ID,
 			Status:   StatusFailed,
@@ -234,11 +281,10 @@ func (a *SubAgent) run(ctx context.Context, task string) {
 		return
 	}
 
-	// Update usage stats
-	a.mu.Lock()
-	a.tokenUsed = resp.Usage.TotalTokens
-	a.Status = StatusCompleted
-	a.mu.Unlock()
+	if !a.finish(StatusCompleted, resp.Usage.TotalTokens) {
+		a.publishCancelled(duration)
+		return
+	}
 
 	// Publish completion
 	a.publishResult(Result{
@@ -250,6 +296,28 @@ func (a *SubAgent) run(ctx context.Context, task string) {
 	})
 }
 
+// finish records the final status and token usage unless the agent was
+// cancelled while running, in which case it reports false.
+func (a *SubAgent) finish(status Status, tokens int) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.Status == StatusCancelled {
+		return false
+	}
+	a.tokenUsed = tokens
+	a.Status = status
+	return true
+}
+
+func (a *SubAgent) publishCancelled(duration time.Duration) {
+	a.publishResult(Result{
+		AgentID:  a.ID,
+		Status:   StatusCancelled,
+		Error:    "cancelled",
+		Duration: duration,
+	})
+}
+
 func (a *SubAgent) buildPrompt(task string) string {
 	return fmt.Sprintf(`You are a specialized sub-agent working on a specific task.
 
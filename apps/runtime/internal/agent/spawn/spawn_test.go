@@ -546,3 +546,114 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestSpawner_ForkAt(t *testing.T) {
+	cfg := &config.Config{
+		ModelProvider: "openai",
+	}
+	eventBus := bus.New()
+	kc := keychain.New("test")
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	spawner := NewSpawner(cfg, eventBus, kc, s)
+
+	source, err := s.CreateSession("source")
+	if err != nil {
+		t.Fatalf("Failed to create source session: %v", err)
+	}
+	first, _ := s.AddMessage(source.ID, store.RoleUser, "first")
+	time.Sleep(time.Millisecond)
+	_, _ = s.AddMessage(source.ID, store.RoleAssistant, "second")
+	time.Sleep(time.Millisecond)
+	_, _ = s.AddMessage(source.ID, store.RoleUser, "third")
+
+	forkID, err := spawner.ForkAt(context.Background(), source.ID, first.ID)
+	if err != nil {
+		t.Fatalf("ForkAt() unexpected error = %v", err)
+	}
+	msgs, err := s.GetSessionMessages(forkID)
+	if err != nil {
+		t.Fatalf("GetSessionMessages() error = %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].Content != "first" {
+		t.Errorf("ForkAt() copied %d messages, want only the first", len(msgs))
+	}
+
+	other, _ := s.CreateSession("other")
+	foreign, _ := s.AddMessage(other.ID, store.RoleUser, "elsewhere")
+	if _, err := spawner.ForkAt(context.Background(), source.ID, foreign.ID); !errors.Is(err, store.ErrMessageNotInSession) {
+		t.Errorf("ForkAt() with foreign message error = %v, want ErrMessageNotInSession", err)
+	}
+}
+
+func TestSpawner_CancelFinished(t *testing.T) {
+	eventBus := bus.New()
+	spawner := NewSpawner(&config.Config{ModelProvider: "openai"}, eventBus, nil, nil)
+	events, unsubscribe := eventBus.Subscribe(bus.EventAgentCancelled)
+	defer unsubscribe()
+
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	spawner.agents["done"] = &SubAgent{ID: "done", Status: StatusCompleted, cancel: cancel}
+	spawner.agents["running"] = &SubAgent{ID: "running", SessionID: "s1", Status: StatusRunning, cancel: cancel}
+
+	if err := spawner.Cancel("missing"); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("Cancel(missing) error = %v, want ErrAgentNotFound", err)
+	}
+	if err := spawner.Cancel("done"); !errors.Is(err, ErrAgentFinished) {
+		t.Errorf("Cancel(done) error = %v, want ErrAgentFinished", err)
+	}
+	if err := spawner.Cancel("running"); err != nil {
+		t.Fatalf("Cancel(running) unexpected error = %v", err)
+	}
+	select {
+	case evt := <-events:
+		if evt.SessionID != "s1" {
+			t.Errorf("event session = %q, want s1", evt.SessionID)
+		}
+	case <-time.After(time.Second):
+		t.Error("Cancel() did not publish agent.cancelled")
+	}
+	if err := spawner.Cancel("running"); !errors.Is(err, ErrAgentFinished) {
+		t.Errorf("second Cancel() error = %v, want ErrAgentFinished", err)
+	}
+}
+
+func TestSubAgent_runKeepsCancelledStatus(t *testing.T) {
+	eventBus := bus.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	agent := &SubAgent{
+		ID:      "agent-1",
+		Status:  StatusPending,
+		cancel:  cancel,
+		eventCh: make(chan bus.Event, 10),
+		bus:     eventBus,
+		provider: &MockProvider{
+			CompleteFunc: func(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+	}
+	spawner := NewSpawner(&config.Config{}, eventBus, nil, nil)
+	spawner.agents[agent.ID] = agent
+
+	done := make(chan struct{})
+	go func() {
+		agent.run(ctx, "task")
+		close(done)
+	}()
+	<-started
+	if err := spawner.Cancel(agent.ID); err != nil {
+		t.Fatalf("Cancel() unexpected error = %v", err)
+	}
+	<-done
+
+	agent.mu.RLock()
+	defer agent.mu.RUnlock()
+	if agent.Status != StatusCancelled {
+		t.Errorf("run() overwrote status with %v, want cancelled", agent.Status)
+	}
+}
@@ -3,6 +3,7 @@ package spawn
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -100,6 +101,12 @@ func (t *SpawnTool) Execute(ctx context.Context, params json.RawMessage, parentI
 	// Wait for completion with timeout
 	result, err := t.waitForResult(ctx, agent.ID, 5*time.Minute)
 	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) {
+			// The caller went away; stop the sub-agent rather than leave it
+			// running for nobody.
+			_ = t.spawner.Cancel(agent.ID)
+			return nil, ctx.Err()
+		}
 		agent.mu.RLock()
 		status := agent.Status
 		agent.mu.RUnlock()
@@ -174,6 +181,11 @@ func (t *SpawnTool) GetAgentStatus(agentID string) (map[string]interface{}, erro
 	}, nil
 }
 
+// CancelAgent stops a pending or running agent.
+func (t *SpawnTool) CancelAgent(agentID string) error {
+	return t.spawner.Cancel(agentID)
+}
+
 // ListAgents returns all active agents
 func (t *SpawnTool) ListAgents() []map[string]interface{} {
 	agents := t.spawner.List()
@@ -198,6 +210,11 @@ func (t *SpawnTool) ForkSession(sourceSessionID string) (string, error) {
 	return t.spawner.Fork(context.Background(), sourceSessionID)
 }
 
+// ForkSessionAt creates a fork of a session that ends at the given message
+func (t *SpawnTool) ForkSessionAt(sourceSessionID string, messageID string) (string, error) {
+	return t.spawner.ForkAt(context.Background(), sourceSessionID, messageID)
+}
+
 // RegisterHandlers registers bus event handlers for spawn-related events
 func (t *SpawnTool) RegisterHandlers() {
 	// Subscribe to spawn requests
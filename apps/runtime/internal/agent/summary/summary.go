@@ -0,0 +1,188 @@
+// Package summary produces concise summaries of chat sessions using an LLM.
+package summary
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pryx-core/internal/llm"
+	"pryx-core/internal/memory"
+	"pryx-core/internal/store"
+)
+
+// DefaultMaxWords is the target summary length when none is configured.
+const DefaultMaxWords = 150
+
+// maxTranscriptChars caps the transcript sent to the model; older messages are dropped first.
+const maxTranscriptChars = 24000
+
+var (
+	// ErrSessionNotFound is returned when the session does not exist.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrEmptySession is returned when the session has no messages to summarize.
+	ErrEmptySession = errors.New("session has no messages")
+)
+
+// cheapModels maps providers to an inexpensive model suitable for summaries.
+var cheapModels = map[string]string{
+	"openai":    "gpt-4o-mini",
+	"anthropic": "claude-3-5-haiku-latest",
+	"google":    "gemini-1.5-flash",
+}
+
+// DefaultModel returns the cheap summary model for provider, or fallback if none is known.
+func DefaultModel(provider, fallback string) string {
+	if m, ok := cheapModels[strings.ToLower(strings.TrimSpace(provider))]; ok {
+		return m
+	}
+	return fallback
+}
+
+// ProviderFunc returns the LLM provider used for summaries.
+type ProviderFunc func() (llm.Provider, error)
+
+// Request describes a summarization.
+type Request struct {
+	SessionID string
+	// Model overrides the summarizer's default model.
+	Model string
+	// MaxWords overrides the summarizer's default length.
+	MaxWords int
+	// Persist stores the summary as the session description.
+	Persist bool
+	// Remember writes the summary to long-term memory.
+	Remember bool
+}
+
+// Result is the outcome of a summarization.
+type Result struct {
+	SessionID     string `json:"session_id"`
+	Summary       string `json:"summary"`
+	Model         string `json:"model"`
+	MessageCount  int    `json:"message_count"`
+	Persisted     bool   `json:"persisted"`
+	MemoryEntryID string `json:"memory_entry_id,omitempty"`
+}
+
+// Summarizer summarizes stored sessions.
+type Summarizer struct {
+	store    *store.Store
+	memory   *memory.RAGManager
+	provider ProviderFunc
+	model    string
+	modelFn  func() string
+	maxWords int
+}
+
+// New creates a Summarizer. mem may be nil, in which case Remember is ignored.
+func New(st *store.Store, mem *memory.RAGManager, provider ProviderFunc, model string, maxWords int) *Summarizer {
+	if maxWords <= 0 {
+		maxWords = DefaultMaxWords
+	}
+	return &Summarizer{store: st, memory: mem, provider: provider, model: model, maxWords: maxWords}
+}
+
+// SetModelFunc sets fn to resolve the model for requests that name none, in
+// place of the fixed model passed to New. It lets the model follow config
+// changes.
+func (s *Summarizer) SetModelFunc(fn func() string) {
+	s.modelFn = fn
+}
+
+// Summarize generates a summary of the session in req and optionally persists it.
+func (s *Summarizer) Summarize(ctx context.Context, req Request) (*Result, error) {
+	if _, err := s.store.GetSession(req.SessionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	messages, err := s.store.GetMessages(req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("load messages: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil, ErrEmptySession
+	}
+
+	model := strings.TrimSpace(req.Model)
+	if model == "" && s.modelFn != nil {
+		model = s.modelFn()
+	}
+	if model == "" {
+		model = s.model
+	}
+	maxWords := req.MaxWords
+	if maxWords <= 0 {
+		maxWords = s.maxWords
+	}
+
+	provider, err := s.provider()
+	if err != nil {
+		return nil, fmt.Errorf("create provider: %w", err)
+	}
+	resp, err := provider.Complete(ctx, llm.ChatRequest{
+		Model: model,
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: fmt.Sprintf("Summarize the following conversation in at most %d words. Capture the goals, decisions, and open questions, and keep every [pinned] message's facts. Reply with the summary only.", maxWords)},
+			{Role: llm.RoleUser, Content: transcript(messages)},
+		},
+		MaxTokens: maxWords * 2,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summarize: %w", err)
+	}
+
+	result := &Result{
+		SessionID:    req.SessionID,
+		Summary:      strings.TrimSpace(resp.Content),
+		Model:        model,
+		MessageCount: len(messages),
+	}
+
+	if req.Persist {
+		if err := s.store.SetSessionDescription(req.SessionID, result.Summary); err != nil {
+			return nil, fmt.Errorf("persist summary: %w", err)
+		}
+		result.Persisted = true
+	}
+	if req.Remember && s.memory != nil {
+		entryID, err := s.memory.WriteLongterm(result.Summary, []memory.MemorySource{{
+			SourceType: "conversation",
+			SourcePath: "session:" + req.SessionID,
+		}})
+		if err != nil {
+			return nil, fmt.Errorf("write memory: %w", err)
+		}
+		result.MemoryEntryID = entryID
+	}
+	return result, nil
+}
+
+// transcript renders messages as "role: content" lines, keeping the most recent
+// messages when the transcript would exceed maxTranscriptChars. Pinned messages
+// are always kept and marked so the summary retains them.
+func transcript(messages []*store.Message) string {
+	lines := make([]string, 0, len(messages))
+	total := 0
+	full := false
+	for i := len(messages) - 1; i >= 0; i-- {
+		line := fmt.Sprintf("%s: %s", messages[i].Role, strings.TrimSpace(messages[i].Content))
+		if messages[i].Pinned {
+			line = "[pinned] " + line
+		} else if full || (total+len(line) > maxTranscriptChars && len(lines) > 0) {
+			full = true
+			continue
+		}
+		total += len(line) + 1
+		lines = append(lines, line)
+	}
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return strings.Join(lines, "\n")
+}
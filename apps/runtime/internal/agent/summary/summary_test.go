@@ -0,0 +1,147 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"pryx-core/internal/llm"
+	"pryx-core/internal/memory"
+	"pryx-core/internal/store"
+)
+
+type fakeProvider struct {
+	last llm.ChatRequest
+}
+
+func (p *fakeProvider) Complete(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.last = req
+	return &llm.ChatResponse{Content: "  The user asked about Go.  ", Role: llm.RoleAssistant}, nil
+}
+
+func (p *fakeProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func newTestSummarizer(t *testing.T) (*Summarizer, *store.Store, *fakeProvider) {
+	t.Helper()
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+
+	fp := &fakeProvider{}
+	mem := memory.NewRAGManager(st.DB, true)
+	return New(st, mem, func() (llm.Provider, error) { return fp, nil }, "cheap-model", 0), st, fp
+}
+
+func TestSummarize(t *testing.T) {
+	s, st, fp := newTestSummarizer(t)
+
+	sess, _ := st.CreateSession("Chat")
+	_, _ = st.AddMessage(sess.ID, store.RoleUser, "How do goroutines work?")
+	_, _ = st.AddMessage(sess.ID, store.RoleAssistant, "They are lightweight threads.")
+
+	res, err := s.Summarize(context.Background(), Request{SessionID: sess.ID, MaxWords: 40, Persist: true, Remember: true})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if res.Summary != "The user asked about Go." {
+		t.Errorf("Summary = %q", res.Summary)
+	}
+	if res.Model != "cheap-model" || fp.last.Model != "cheap-model" {
+		t.Errorf("expected default model to be used, got %q", fp.last.Model)
+	}
+	if !strings.Contains(fp.last.Messages[0].Content, "40 words") {
+		t.Errorf("expected max words in prompt, got %q", fp.last.Messages[0].Content)
+	}
+	if !strings.Contains(fp.last.Messages[1].Content, "user: How do goroutines work?") {
+		t.Errorf("expected transcript in prompt, got %q", fp.last.Messages[1].Content)
+	}
+	if res.MessageCount != 2 || !res.Persisted || res.MemoryEntryID == "" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	fetched, _ := st.GetSession(sess.ID)
+	if fetched.Description != res.Summary {
+		t.Errorf("Description = %q, want %q", fetched.Description, res.Summary)
+	}
+}
+
+func TestSummarizeErrors(t *testing.T) {
+	s, st, _ := newTestSummarizer(t)
+
+	if _, err := s.Summarize(context.Background(), Request{SessionID: "missing"}); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("expected ErrSessionNotFound, got %v", err)
+	}
+
+	sess, _ := st.CreateSession("Empty")
+	if _, err := s.Summarize(context.Background(), Request{SessionID: sess.ID}); !errors.Is(err, ErrEmptySession) {
+		t.Errorf("expected ErrEmptySession, got %v", err)
+	}
+}
+
+func TestToolDefaultsToCurrentSession(t *testing.T) {
+	s, st, _ := newTestSummarizer(t)
+	sess, _ := st.CreateSession("Chat")
+	_, _ = st.AddMessage(sess.ID, store.RoleUser, "hello")
+
+	tool := NewTool(s)
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"persist":true}`), sess.ID)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if res := out.(*Result); res.SessionID != sess.ID || !res.Persisted {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+func TestSummarizeModelFunc(t *testing.T) {
+	s, st, fp := newTestSummarizer(t)
+	sess, _ := st.CreateSession("Chat")
+	_, _ = st.AddMessage(sess.ID, store.RoleUser, "hello")
+
+	s.SetModelFunc(func() string { return "gpt-4o-mini" })
+	if _, err := NewTool(s).Execute(context.Background(), nil, sess.ID); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if fp.last.Model != "gpt-4o-mini" {
+		t.Errorf("expected the model func to pick the model, got %q", fp.last.Model)
+	}
+	if _, err := s.Summarize(context.Background(), Request{SessionID: sess.ID, Model: "gpt-4o"}); err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if fp.last.Model != "gpt-4o" {
+		t.Errorf("expected the requested model to win, got %q", fp.last.Model)
+	}
+}
+
+func TestDefaultModel(t *testing.T) {
+	if got := DefaultModel("OpenAI", "gpt-4o"); got != "gpt-4o-mini" {
+		t.Errorf("DefaultModel(openai) = %q", got)
+	}
+	if got := DefaultModel("ollama", "llama3"); got != "llama3" {
+		t.Errorf("DefaultModel(ollama) = %q", got)
+	}
+}
+
+func TestTranscriptKeepsPinnedMessages(t *testing.T) {
+	filler := strings.Repeat("x", maxTranscriptChars/2)
+	messages := []*store.Message{
+		{Role: store.RoleUser, Content: "Budget is capped at $500.", Pinned: true},
+		{Role: store.RoleUser, Content: "old chatter"},
+		{Role: store.RoleAssistant, Content: filler},
+		{Role: store.RoleUser, Content: filler},
+	}
+
+	got := transcript(messages)
+	if !strings.HasPrefix(got, "[pinned] user: Budget is capped at $500.") {
+		t.Errorf("expected pinned message to survive trimming, got %.80q", got)
+	}
+	if strings.Contains(got, "old chatter") {
+		t.Error("expected old unpinned message to be dropped")
+	}
+}
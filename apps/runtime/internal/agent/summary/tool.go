@@ -0,0 +1,84 @@
+package summary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Tool exposes session summarization as an agent tool so the model can
+// condense a long conversation on its own.
+type Tool struct {
+	summarizer *Summarizer
+}
+
+// NewTool creates a summarize tool backed by summarizer.
+func NewTool(summarizer *Summarizer) *Tool {
+	return &Tool{summarizer: summarizer}
+}
+
+// Name returns the tool name
+func (t *Tool) Name() string {
+	return "session_summarize"
+}
+
+// Description returns the tool description for LLM
+func (t *Tool) Description() string {
+	return `Summarize the current conversation.
+
+Use this when the conversation is getting long and earlier details risk being lost.
+The summary can be saved as the session description and to long-term memory.`
+}
+
+// Schema returns the JSON schema for the tool parameters
+func (t *Tool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"session_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional: session to summarize (defaults to current session)",
+			},
+			"max_words": map[string]interface{}{
+				"type":        "integer",
+				"description": "Optional: maximum summary length in words",
+			},
+			"persist": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Save the summary as the session description",
+			},
+			"remember": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Write the summary to long-term memory",
+			},
+		},
+	}
+}
+
+// Execute runs the summarize tool
+func (t *Tool) Execute(ctx context.Context, params json.RawMessage, sessionID string) (interface{}, error) {
+	var args struct {
+		SessionID string `json:"session_id"`
+		MaxWords  int    `json:"max_words"`
+		Persist   bool   `json:"persist"`
+		Remember  bool   `json:"remember"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+	if args.SessionID == "" {
+		args.SessionID = sessionID
+	}
+	if args.SessionID == "" {
+		return nil, fmt.Errorf("session_id is required")
+	}
+
+	return t.summarizer.Summarize(ctx, Request{
+		SessionID: args.SessionID,
+		MaxWords:  args.MaxWords,
+		Persist:   args.Persist,
+		Remember:  args.Remember,
+	})
+}
@@ -0,0 +1,393 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/mcp"
+)
+
+// Defaults for executing the tool calls of a single assistant turn.
+const (
+	DefaultMaxParallelTools = 4
+	// DefaultToolTimeout exceeds the MCP approval window so approvals are not cut short.
+	DefaultToolTimeout = 3 * time.Minute
+)
+
+// maxToolRounds bounds how many times one turn may call tools; the model is
+// then asked again without tools so it has to answer.
+const maxToolRounds = 8
+
+// NativeTool is a tool implemented by the runtime rather than an MCP server,
+// such as session summarization. It is offered to the model next to the MCP
+// tools.
+type NativeTool interface {
+	Name() string
+	Description() string
+	Schema() map[string]interface{}
+	Execute(ctx context.Context, params json.RawMessage, sessionID string) (interface{}, error)
+}
+
+// ToolCall is a single tool invocation requested by the model.
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments map[string]interface{}
+}
+
+// ToolCallResult is the outcome of a ToolCall. Results are returned in call order.
+type ToolCallResult struct {
+	ID       string
+	Name     string
+	Result   mcp.ToolResult
+	Err      error
+	Duration time.Duration
+}
+
+// ToolError returns the classified form of Err, or nil if the call succeeded.
+func (r ToolCallResult) ToolError() *mcp.ToolError {
+	return mcp.ClassifyToolError(r.Err)
+}
+
+// ModelContent renders the result for the model. Failures become a JSON object
+// with error, code and retriable fields so the model can decide whether to retry.
+func (r ToolCallResult) ModelContent() string {
+	if toolErr := r.ToolError(); toolErr != nil {
+		data, _ := json.Marshal(toolErr.Payload())
+		return string(data)
+	}
+	var parts []string
+	for _, c := range r.Result.Content {
+		if c.Type == "text" {
+			parts = append(parts, c.Text)
+		}
+	}
+	if len(parts) == 0 && len(r.Result.StructuredContent) > 0 {
+		return string(r.Result.StructuredContent)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// toolset is the tools offered to the model for one turn. MCP tool names such
+// as "filesystem:read_file" are not valid function names, so each tool is
+// offered under a sanitized name mapped back to the tool it calls.
+type toolset struct {
+	specs []llm.Tool
+	names map[string]string
+}
+
+func (ts *toolset) add(name, description string, schema map[string]interface{}) {
+	fn := functionName(name)
+	for i := 2; ts.names[fn] != ""; i++ {
+		fn = functionName(fmt.Sprintf("%s_%d", name, i))
+	}
+	if ts.names == nil {
+		ts.names = make(map[string]string)
+	}
+	ts.names[fn] = name
+	ts.specs = append(ts.specs, llm.Tool{Name: fn, Description: description, Parameters: schema})
+}
+
+// resolve returns the tool a function name offered to the model calls.
+func (ts toolset) resolve(fn string) string {
+	if name, ok := ts.names[fn]; ok {
+		return name
+	}
+	return fn
+}
+
+// functionName turns a tool name into a function name providers accept:
+// letters, digits, underscores and hyphens, at most 64 characters.
+func functionName(name string) string {
+	name = strings.Replace(name, ":", "__", 1)
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	fn := b.String()
+	if len(fn) > 64 {
+		fn = fn[:64]
+	}
+	return fn
+}
+
+// RegisterTool offers t to the model in every turn, under its name.
+func (a *Agent) RegisterTool(t NativeTool) {
+	a.nativeToolsMu.Lock()
+	defer a.nativeToolsMu.Unlock()
+	if a.nativeTools == nil {
+		a.nativeTools = make(map[string]NativeTool)
+	}
+	a.nativeTools[t.Name()] = t
+}
+
+func (a *Agent) nativeTool(name string) NativeTool {
+	a.nativeToolsMu.RLock()
+	defer a.nativeToolsMu.RUnlock()
+	return a.nativeTools[name]
+}
+
+// turnTools returns the native and MCP tools to offer the model, or an empty
+// set when the agent cannot execute tools.
+func (a *Agent) turnTools() toolset {
+	var ts toolset
+	if a.tools == nil {
+		return ts
+	}
+	a.nativeToolsMu.RLock()
+	for _, t := range a.nativeTools {
+		ts.add(t.Name(), t.Description(), t.Schema())
+	}
+	a.nativeToolsMu.RUnlock()
+	if a.mcp == nil {
+		return ts
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tools, err := a.mcp.ListToolsFlat(ctx, false)
+	if err != nil {
+		log.Printf("Agent: Failed to list MCP tools: %v", err)
+		return ts
+	}
+	for _, t := range tools {
+		var schema map[string]interface{}
+		if len(t.InputSchema) > 0 {
+			_ = json.Unmarshal(t.InputSchema, &schema)
+		}
+		ts.add(t.Name, t.Description, schema)
+	}
+	return ts
+}
+
+// invokeTool runs the native tool called name, or else the MCP tool.
+func (a *Agent) invokeTool(ctx context.Context, sessionID, name string, args map[string]interface{}) (mcp.ToolResult, error) {
+	t := a.nativeTool(name)
+	if t == nil {
+		if a.mcp == nil {
+			return mcp.ToolResult{}, &mcp.ToolError{Code: mcp.ToolErrNotFound, Message: fmt.Sprintf("unknown tool: %s", name)}
+		}
+		return a.mcp.CallTool(ctx, sessionID, name, args)
+	}
+
+	params, err := json.Marshal(args)
+	if err != nil {
+		return mcp.ToolResult{}, &mcp.ToolError{Code: mcp.ToolErrInvalidArguments, Message: err.Error(), Err: err}
+	}
+	out, err := t.Execute(ctx, params, sessionID)
+	if err != nil {
+		return mcp.ToolResult{}, &mcp.ToolError{Code: mcp.ToolErrToolFailed, Message: err.Error(), Err: err}
+	}
+	text, ok := out.(string)
+	if !ok {
+		data, err := json.Marshal(out)
+		if err != nil {
+			return mcp.ToolResult{}, &mcp.ToolError{Code: mcp.ToolErrToolFailed, Message: err.Error(), Err: err}
+		}
+		text = string(data)
+	}
+	return mcp.ToolResult{Content: []mcp.ToolContent{{Type: "text", Text: text}}}, nil
+}
+
+// runToolCalls executes the tool calls the model made in one step and returns
+// the messages recording them for the next step: the assistant message with
+// the calls, then one tool message per call with its result or structured
+// error.
+func (a *Agent) runToolCalls(ctx context.Context, sessionID string, ts toolset, answer string, calls []llm.ToolCall) []llm.Message {
+	results := make([]ToolCallResult, len(calls))
+	var batch []ToolCall
+	var batchIdx []int
+	for i, call := range calls {
+		name := ts.resolve(call.Name)
+		var args map[string]interface{}
+		if strings.TrimSpace(call.Arguments) != "" {
+			if err := json.Unmarshal([]byte(call.Arguments), &args); err != nil {
+				results[i] = ToolCallResult{ID: call.ID, Name: name, Err: &mcp.ToolError{
+					Code:    mcp.ToolErrInvalidArguments,
+					Message: fmt.Sprintf("arguments of %s are not a JSON object: %v", name, err),
+				}}
+				continue
+			}
+		}
+		batch = append(batch, ToolCall{ID: call.ID, Name: name, Arguments: args})
+		batchIdx = append(batchIdx, i)
+	}
+	if len(batch) > 0 {
+		executed, err := a.ExecuteToolCalls(ctx, sessionID, batch)
+		for j, i := range batchIdx {
+			if err != nil {
+				results[i] = ToolCallResult{ID: batch[j].ID, Name: batch[j].Name, Err: err}
+				continue
+			}
+			results[i] = executed[j]
+		}
+	}
+
+	messages := []llm.Message{{Role: llm.RoleAssistant, Content: answer, ToolCalls: calls}}
+	for i, res := range results {
+		messages = append(messages, llm.Message{Role: llm.RoleTool, ToolCallID: calls[i].ID, Content: res.ModelContent()})
+	}
+	return messages
+}
+
+// ToolInvoker executes one tool call. mcp.Manager.CallTool satisfies it and
+// applies policy and the approval flow.
+type ToolInvoker func(ctx context.Context, sessionID, name string, args map[string]interface{}) (mcp.ToolResult, error)
+
+// ToolBatchExecutor runs the tool calls of one assistant turn with bounded parallelism.
+// Calls that write to the same resource run sequentially in the order the model requested them.
+type ToolBatchExecutor struct {
+	invoke      ToolInvoker
+	bus         *bus.Bus
+	maxParallel int
+	timeout     time.Duration
+}
+
+// NewToolBatchExecutor creates an executor. Non-positive limits fall back to the defaults.
+func NewToolBatchExecutor(invoke ToolInvoker, b *bus.Bus, maxParallel int, timeout time.Duration) *ToolBatchExecutor {
+	if maxParallel <= 0 {
+		maxParallel = DefaultMaxParallelTools
+	}
+	if timeout <= 0 {
+		timeout = DefaultToolTimeout
+	}
+	return &ToolBatchExecutor{invoke: invoke, bus: b, maxParallel: maxParallel, timeout: timeout}
+}
+
+// Execute runs calls and returns one result per call, in the same order.
+func (e *ToolBatchExecutor) Execute(ctx context.Context, sessionID string, calls []ToolCall) []ToolCallResult {
+	results := make([]ToolCallResult, len(calls))
+	sem := make(chan struct{}, e.maxParallel)
+
+	var wg sync.WaitGroup
+	for _, lane := range planLanes(calls) {
+		wg.Add(1)
+		go func(lane []int) {
+			defer wg.Done()
+			for _, i := range lane {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					results[i] = ToolCallResult{ID: calls[i].ID, Name: calls[i].Name, Err: ctx.Err()}
+					continue
+				}
+				results[i] = e.run(ctx, sessionID, i, calls[i])
+				<-sem
+			}
+		}(lane)
+	}
+	wg.Wait()
+	return results
+}
+
+func (e *ToolBatchExecutor) run(ctx context.Context, sessionID string, index int, call ToolCall) ToolCallResult {
+	e.publish(bus.EventToolRequest, sessionID, map[string]interface{}{
+		"call_id": call.ID,
+		"tool":    call.Name,
+		"index":   index,
+	})
+
+	callCtx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	callCtx = mcp.WithRequester(callCtx, mcp.Requester{Kind: "agent", ID: call.ID})
+
+	start := time.Now()
+	res, err := e.invoke(callCtx, sessionID, call.Name, call.Arguments)
+	if err != nil && callCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		err = &mcp.ToolError{
+			Code:      mcp.ToolErrTimeout,
+			Message:   fmt.Sprintf("tool %s timed out after %s: %v", call.Name, e.timeout, err),
+			Retriable: true,
+			Err:       err,
+		}
+	}
+	result := ToolCallResult{ID: call.ID, Name: call.Name, Result: res, Err: err, Duration: time.Since(start)}
+
+	payload := map[string]interface{}{
+		"kind":        "agent.tool_call_finished",
+		"call_id":     call.ID,
+		"tool":        call.Name,
+		"index":       index,
+		"duration_ms": result.Duration.Milliseconds(),
+	}
+	if toolErr := result.ToolError(); toolErr != nil {
+		payload["error"] = toolErr.Message
+		payload["code"] = string(toolErr.Code)
+		payload["retriable"] = toolErr.Retriable
+	}
+	e.publish(bus.EventTraceEvent, sessionID, payload)
+	return result
+}
+
+func (e *ToolBatchExecutor) publish(eventType bus.EventType, sessionID string, payload map[string]interface{}) {
+	if e.bus != nil {
+		e.bus.Publish(bus.NewEvent(eventType, sessionID, payload))
+	}
+}
+
+// planLanes groups call indexes into lanes. Calls within a lane run in order;
+// lanes run concurrently. Read-only calls each get their own lane, while writes
+// sharing a resource key are placed in the same lane.
+func planLanes(calls []ToolCall) [][]int {
+	var lanes [][]int
+	byKey := map[string]int{}
+	for i, call := range calls {
+		key := resourceKey(call)
+		if key == "" {
+			lanes = append(lanes, []int{i})
+			continue
+		}
+		if lane, ok := byKey[key]; ok {
+			lanes[lane] = append(lanes[lane], i)
+			continue
+		}
+		byKey[key] = len(lanes)
+		lanes = append(lanes, []int{i})
+	}
+	return lanes
+}
+
+var writeVerbs = []string{"write", "create", "update", "delete", "remove", "edit", "move", "rename", "set", "put", "patch", "insert", "append", "exec", "run"}
+
+var resourceArgs = []string{"path", "file", "filename", "resource", "uri", "url", "id", "key"}
+
+// resourceKey returns the serialization key for a call, or "" if it can run freely.
+// Writes are keyed by server and target resource; writes without a recognizable
+// target are keyed by server alone so they never overlap.
+func resourceKey(call ToolCall) string {
+	server, tool := "", call.Name
+	if idx := strings.IndexAny(call.Name, ":/"); idx >= 0 {
+		server, tool = call.Name[:idx], call.Name[idx+1:]
+	}
+	if !isWriteTool(tool) {
+		return ""
+	}
+	for _, arg := range resourceArgs {
+		if v, ok := call.Arguments[arg]; ok {
+			return fmt.Sprintf("%s\x00%v", server, v)
+		}
+	}
+	return server
+}
+
+func isWriteTool(tool string) bool {
+	tool = strings.ToLower(tool)
+	for _, verb := range writeVerbs {
+		if strings.Contains(tool, verb) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/mcp"
+	"pryx-core/internal/policy"
+)
+
+func TestToolBatchExecutor_PreservesOrderAndBoundsParallelism(t *testing.T) {
+	var running, peak int32
+	invoke := func(ctx context.Context, sessionID, name string, args map[string]interface{}) (mcp.ToolResult, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return mcp.ToolResult{Content: []mcp.ToolContent{{Type: "text", Text: name}}}, nil
+	}
+
+	exec := NewToolBatchExecutor(invoke, nil, 2, time.Second)
+	calls := []ToolCall{
+		{ID: "1", Name: "fs/read", Arguments: map[string]interface{}{"path": "a"}},
+		{ID: "2", Name: "fs/read", Arguments: map[string]interface{}{"path": "b"}},
+		{ID: "3", Name: "web/search"},
+		{ID: "4", Name: "web/fetch"},
+		{ID: "5", Name: "fs/list"},
+	}
+	results := exec.Execute(context.Background(), "s1", calls)
+
+	if len(results) != len(calls) {
+		t.Fatalf("expected %d results, got %d", len(calls), len(results))
+	}
+	for i, r := range results {
+		if r.ID != calls[i].ID || r.Err != nil {
+			t.Fatalf("result %d = %+v, want id %s", i, r, calls[i].ID)
+		}
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent calls, saw %d", peak)
+	}
+	if peak < 2 {
+		t.Fatalf("expected calls to run in parallel, peak was %d", peak)
+	}
+}
+
+func TestToolBatchExecutor_SerializesWritesToSameResource(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	var active int32
+	invoke := func(ctx context.Context, sessionID, name string, args map[string]interface{}) (mcp.ToolResult, error) {
+		if atomic.AddInt32(&active, 1) > 1 {
+			t.Errorf("writes to the same file overlapped")
+		}
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		order = append(order, args["content"].(string))
+		mu.Unlock()
+		atomic.AddInt32(&active, -1)
+		return mcp.ToolResult{}, nil
+	}
+
+	exec := NewToolBatchExecutor(invoke, nil, 4, time.Second)
+	exec.Execute(context.Background(), "s1", []ToolCall{
+		{ID: "1", Name: "fs/write_file", Arguments: map[string]interface{}{"path": "x", "content": "first"}},
+		{ID: "2", Name: "fs/write_file", Arguments: map[string]interface{}{"path": "x", "content": "second"}},
+		{ID: "3", Name: "fs/write_file", Arguments: map[string]interface{}{"path": "x", "content": "third"}},
+	})
+
+	if len(order) != 3 || order[0] != "first" || order[1] != "second" || order[2] != "third" {
+		t.Fatalf("unexpected write order: %v", order)
+	}
+}
+
+func TestToolBatchExecutor_TimeoutAndEvents(t *testing.T) {
+	b := bus.New()
+	events, cancel := b.Subscribe(bus.EventToolRequest, bus.EventTraceEvent)
+	defer cancel()
+
+	invoke := func(ctx context.Context, sessionID, name string, args map[string]interface{}) (mcp.ToolResult, error) {
+		<-ctx.Done()
+		return mcp.ToolResult{}, ctx.Err()
+	}
+	exec := NewToolBatchExecutor(invoke, b, 1, 20*time.Millisecond)
+	results := exec.Execute(context.Background(), "s1", []ToolCall{{ID: "slow", Name: "web/fetch"}})
+
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", results[0].Err)
+	}
+
+	requested := <-events
+	if requested.Event != bus.EventToolRequest || requested.SessionID != "s1" {
+		t.Fatalf("unexpected first event: %+v", requested)
+	}
+	finished := (<-events).Payload.(map[string]interface{})
+	if finished["kind"] != "agent.tool_call_finished" || finished["call_id"] != "slow" || finished["error"] == nil {
+		t.Fatalf("unexpected finish event: %v", finished)
+	}
+	if finished["code"] != "timeout" || finished["retriable"] != true {
+		t.Fatalf("expected retriable timeout, got %v", finished)
+	}
+}
+
+func TestToolCallResult_ModelContent(t *testing.T) {
+	ok := ToolCallResult{Result: mcp.ToolResult{Content: []mcp.ToolContent{{Type: "text", Text: "hello"}}}}
+	if got := ok.ModelContent(); got != "hello" {
+		t.Fatalf("ModelContent() = %q, want hello", got)
+	}
+
+	failed := ToolCallResult{Err: &mcp.HTTPStatusError{StatusCode: 502, Body: "bad gateway"}}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(failed.ModelContent()), &payload); err != nil {
+		t.Fatalf("expected JSON error payload: %v", err)
+	}
+	if payload["error"] != "bad gateway" || payload["code"] != "server_error" || payload["retriable"] != true {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+}
+
+// echoTool is a native tool returning its text argument.
+type echoTool struct{}
+
+func (echoTool) Name() string                   { return "echo" }
+func (echoTool) Description() string            { return "Echo the text back" }
+func (echoTool) Schema() map[string]interface{} { return map[string]interface{}{"type": "object"} }
+func (echoTool) Execute(ctx context.Context, params json.RawMessage, sessionID string) (interface{}, error) {
+	var args struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, err
+	}
+	return "echo: " + args.Text, nil
+}
+
+func TestAgent_ToolLoop(t *testing.T) {
+	eventBus := bus.New()
+	var requests []llm.ChatRequest
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "gpt-4o"},
+		bus: eventBus,
+		provider: &MockProvider{
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				requests = append(requests, req)
+				ch := make(chan llm.StreamChunk, 1)
+				if len(requests) == 1 {
+					ch <- llm.StreamChunk{Done: true, ToolCalls: []llm.ToolCall{
+						{ID: "c1", Name: "echo", Arguments: `{"text":"hi"}`},
+						{ID: "c2", Name: "missing__tool", Arguments: `{}`},
+						{ID: "c3", Name: "echo", Arguments: `not json`},
+					}}
+				} else {
+					ch <- llm.StreamChunk{Content: "done", Done: true}
+				}
+				close(ch)
+				return ch, nil
+			},
+		},
+	}
+	agent.tools = NewToolBatchExecutor(agent.invokeTool, eventBus, 0, time.Second)
+	agent.RegisterTool(echoTool{})
+	messages, cancel := eventBus.Subscribe(bus.EventSessionMessage)
+	defer cancel()
+
+	agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "s1", map[string]interface{}{"content": "hi"}))
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected a second step after the tool calls, got %d requests", len(requests))
+	}
+	if len(requests[0].Tools) != 1 || requests[0].Tools[0].Name != "echo" {
+		t.Errorf("Expected the native tool to be offered, got %+v", requests[0].Tools)
+	}
+	followUp := requests[1].Messages
+	if len(followUp) != 6 || len(followUp[2].ToolCalls) != 3 {
+		t.Fatalf("Expected the assistant tool calls and three results, got %+v", followUp)
+	}
+	if followUp[3].Role != llm.RoleTool || followUp[3].ToolCallID != "c1" || followUp[3].Content != "echo: hi" {
+		t.Errorf("Unexpected tool result %+v", followUp[3])
+	}
+	for i, code := range map[int]string{4: "not_found", 5: "invalid_arguments"} {
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(followUp[i].Content), &payload); err != nil || payload["code"] != code {
+			t.Errorf("Expected a %s error for the model, got %q", code, followUp[i].Content)
+		}
+	}
+
+	var final map[string]interface{}
+	for final == nil {
+		select {
+		case evt := <-messages:
+			if payload := evt.Payload.(map[string]interface{}); payload["done"] == true {
+				final = payload
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the turn to finish")
+		}
+	}
+	if final["content"] != "done" {
+		t.Errorf("Expected the answer after the tool calls, got %v", final)
+	}
+}
+
+func TestAgent_ChannelToolScope(t *testing.T) {
+	eventBus := bus.New()
+	engine := policy.NewEngine(&policy.Policy{Default: policy.DecisionAllow})
+	engine.SetToolScopes(map[string]policy.ToolScope{"channel:telegram": {Deny: []string{"shell.*"}}})
+	var results []string
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "gpt-4o"},
+		bus: eventBus,
+		mcp: mcp.NewManager(eventBus, engine, nil),
+		provider: &MockProvider{
+			CompleteFunc: func(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+				if last := req.Messages[len(req.Messages)-1]; last.Role == llm.RoleTool {
+					results = append(results, last.Content)
+					return &llm.ChatResponse{Content: "done"}, nil
+				}
+				return &llm.ChatResponse{ToolCalls: []llm.ToolCall{{ID: "c1", Name: "shell:exec", Arguments: `{}`}}}, nil
+			},
+		},
+	}
+	agent.tools = NewToolBatchExecutor(agent.invokeTool, eventBus, 0, time.Second)
+
+	agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", channels.Message{
+		Source: "telegram", ChannelID: "42", Content: "run ls",
+	}))
+	if len(results) != 1 {
+		t.Fatalf("Expected one tool result, got %v", results)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(results[0]), &payload); err != nil || payload["code"] != "permission_denied" {
+		t.Errorf("Expected the channel scope to deny the call, got %q", results[0])
+	}
+}
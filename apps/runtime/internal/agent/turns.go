@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"pryx-core/internal/bus"
+)
+
+// Defaults for the turn limits of unattended conversations.
+const (
+	DefaultAutomationMaxTurns = 50
+	DefaultTurnWindow         = time.Hour
+)
+
+// TurnCounter counts the turns each conversation takes within a window.
+// A nil TurnCounter allows every turn.
+type TurnCounter struct {
+	mu    sync.Mutex
+	turns map[string]*turnWindow
+	now   func() time.Time
+}
+
+type turnWindow struct {
+	start    time.Time
+	count    int
+	notified bool
+}
+
+// NewTurnCounter creates an empty turn counter.
+func NewTurnCounter() *TurnCounter {
+	return &TurnCounter{turns: make(map[string]*turnWindow), now: time.Now}
+}
+
+// Take counts a turn for key unless key already took limit turns in the
+// current window. It returns the turns taken, the start of the window, and
+// whether the turn is allowed. A non-positive limit allows every turn.
+func (c *TurnCounter) Take(key string, limit int, window time.Duration) (int, time.Time, bool) {
+	if c == nil || limit <= 0 {
+		return 0, time.Time{}, true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	w := c.turns[key]
+	if w == nil || now.Sub(w.start) >= window {
+		w = &turnWindow{start: now}
+		c.turns[key] = w
+	}
+	if w.count >= limit {
+		return w.count, w.start, false
+	}
+	w.count++
+	return w.count, w.start, true
+}
+
+// notify reports whether the limit of key's current window has not been
+// announced yet, and marks it announced.
+func (c *TurnCounter) notify(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := c.turns[key]
+	if w == nil || w.notified {
+		return false
+	}
+	w.notified = true
+	return true
+}
+
+// Reset forgets the turns taken by key.
+func (c *TurnCounter) Reset(key string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.turns, key)
+}
+
+// turnLimit returns the turn limit and window that apply to a conversation.
+func (a *Agent) turnLimit(automated bool) (int, time.Duration) {
+	window := a.cfg.AutomationTurnWindow
+	if window <= 0 {
+		window = DefaultTurnWindow
+	}
+	if !automated {
+		return a.cfg.InteractiveMaxTurns, window
+	}
+	limit := a.cfg.AutomationMaxTurns
+	if limit == 0 {
+		limit = DefaultAutomationMaxTurns
+	}
+	return limit, window
+}
+
+// withinTurnLimit counts a turn of the conversation identified by key. Once
+// the conversation has used up its limit it reports false; the first refused
+// turn also publishes session.turn_limit_reached and returns a summary to
+// show the conversation.
+func (a *Agent) withinTurnLimit(sessionID, key string, automated bool, details map[string]interface{}) (bool, string) {
+	if a.turns == nil {
+		return true, ""
+	}
+	limit, window := a.turnLimit(automated)
+	turns, start, ok := a.turns.Take(key, limit, window)
+	if ok {
+		return true, ""
+	}
+	if !a.turns.notify(key) {
+		return false, ""
+	}
+
+	resetsAt := start.Add(window)
+	kind := "session"
+	if automated {
+		kind = "unattended conversation"
+	}
+	summary := fmt.Sprintf("Stopped answering this %s after %d turns; it resumes at %s.", kind, turns, resetsAt.Format(time.RFC3339))
+	log.Printf("Agent: Turn limit reached for %s: %s", key, summary)
+
+	payload := map[string]interface{}{
+		"limit":     limit,
+		"turns":     turns,
+		"automated": automated,
+		"resets_at": resetsAt,
+		"summary":   summary,
+	}
+	for k, v := range details {
+		payload[k] = v
+	}
+	a.bus.Publish(bus.NewEvent(bus.EventSessionTurnLimitReached, sessionID, payload))
+	return false, summary
+}
@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+	"pryx-core/internal/config"
+	"pryx-core/internal/llm"
+)
+
+func TestTurnCounter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewTurnCounter()
+	c.now = func() time.Time { return now }
+
+	for i := 1; i <= 2; i++ {
+		if turns, _, ok := c.Take("s1", 2, time.Hour); !ok || turns != i {
+			t.Fatalf("turn %d: got turns=%d ok=%v", i, turns, ok)
+		}
+	}
+	if _, _, ok := c.Take("s1", 2, time.Hour); ok {
+		t.Fatal("expected the third turn to be refused")
+	}
+	if _, _, ok := c.Take("s2", 2, time.Hour); !ok {
+		t.Fatal("expected another conversation to be unaffected")
+	}
+
+	now = now.Add(time.Hour)
+	if turns, _, ok := c.Take("s1", 2, time.Hour); !ok || turns != 1 {
+		t.Fatalf("expected a new window, got turns=%d ok=%v", turns, ok)
+	}
+
+	c.Take("s1", 1, time.Hour)
+	c.Reset("s1")
+	if _, _, ok := c.Take("s1", 1, time.Hour); !ok {
+		t.Fatal("expected Reset to forget the turns taken")
+	}
+	if _, _, ok := c.Take("s1", 0, time.Hour); !ok {
+		t.Fatal("expected no limit to allow every turn")
+	}
+
+	var none *TurnCounter
+	if _, _, ok := none.Take("s1", 1, time.Hour); !ok {
+		t.Fatal("expected a nil counter to allow every turn")
+	}
+}
+
+func TestAgent_TurnLimit(t *testing.T) {
+	eventBus := bus.New()
+	limits, cancel := eventBus.Subscribe(bus.EventSessionTurnLimitReached)
+	defer cancel()
+	outbound, cancelOutbound := eventBus.Subscribe(bus.EventChannelOutboundMessage)
+	defer cancelOutbound()
+
+	var calls atomic.Int32
+	agent := &Agent{
+		cfg: &config.Config{ModelProvider: "openai", ModelName: "gpt-4o", AutomationMaxTurns: 2},
+		bus: eventBus,
+		provider: &MockProvider{
+			StreamFunc: func(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+				calls.Add(1)
+				ch := make(chan llm.StreamChunk, 1)
+				ch <- llm.StreamChunk{Content: "ok", Done: true}
+				close(ch)
+				return ch, nil
+			},
+			CompleteFunc: func(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+				calls.Add(1)
+				return &llm.ChatResponse{Content: "ok"}, nil
+			},
+		},
+		turns: NewTurnCounter(),
+	}
+
+	automated := map[string]interface{}{"content": "hi", "automated": true}
+	for i := 0; i < 4; i++ {
+		agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "auto", automated))
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected 2 generations for the automated session, got %d", got)
+	}
+	select {
+	case evt := <-limits:
+		payload := evt.Payload.(map[string]interface{})
+		if evt.SessionID != "auto" || payload["limit"] != 2 || payload["automated"] != true {
+			t.Errorf("unexpected turn limit event: %+v", evt)
+		}
+		if !strings.Contains(payload["summary"].(string), "after 2 turns") {
+			t.Errorf("unexpected summary: %v", payload["summary"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a session.turn_limit_reached event")
+	}
+	select {
+	case evt := <-limits:
+		t.Fatalf("expected a single turn limit event, got another: %+v", evt)
+	default:
+	}
+
+	// Interactive sessions have no limit by default
+	for i := 0; i < 4; i++ {
+		agent.handleChatRequest(context.Background(), bus.NewEvent(bus.EventChatRequest, "user", map[string]interface{}{"content": "hi"}))
+	}
+	if got := calls.Load(); got != 6 {
+		t.Fatalf("expected the interactive session to be unlimited, got %d generations", got)
+	}
+
+	msg := channels.Message{Source: "telegram", ChannelID: "42", Content: "hello"}
+	for i := 0; i < 3; i++ {
+		agent.handleChannelMessage(context.Background(), bus.NewEvent(bus.EventChannelMessage, "", msg))
+	}
+	if got := calls.Load(); got != 8 {
+		t.Fatalf("expected 2 generations for the channel, got %d", got-6)
+	}
+	for i := 0; i < 3; i++ {
+		select {
+		case evt := <-outbound:
+			content := evt.Payload.(map[string]interface{})["content"].(string)
+			if i == 2 && !strings.Contains(content, "Stopped answering") {
+				t.Errorf("expected the channel to be told about the limit, got %q", content)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected an outbound channel message")
+		}
+	}
+}
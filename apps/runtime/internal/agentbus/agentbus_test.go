@@ -2,6 +2,10 @@ package agentbus
 
 import (
 	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -290,6 +294,373 @@ func TestCircuitBreaker(t *testing.T) {
 	}
 }
 
+func TestServiceSendMessageCircuitBreaker(t *testing.T) {
+	s := NewService(bus.New(), HubConfig{
+		Name: "test",
+		CircuitBreaker: CircuitBreakerConfig{
+			FailureThreshold: 2,
+			RecoveryTimeout:  50 * time.Millisecond,
+			HalfOpenRequests: 1,
+		},
+	})
+	ctx := context.Background()
+
+	calls := 0
+	var sendErr error = errors.New("agent unreachable")
+	s.router.AddRoute("hub", "agent-1", "*", 1, func(msg *UniversalMessage) error {
+		calls++
+		return sendErr
+	})
+	send := func() error {
+		return s.SendMessage(ctx, &UniversalMessage{
+			From: AgentIdentity{ID: "hub"},
+			To:   AgentIdentity{ID: "agent-1"},
+		})
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := send(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("send %d: expected a routing error, got %v", i, err)
+		}
+	}
+	if err := send(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen after %d failures, got %v", calls, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the open breaker to skip the handler, got %d calls", calls)
+	}
+	if state := s.GetMetrics().CircuitBreakers["agent-1"]; state != CircuitBreakerOpen {
+		t.Errorf("expected open state in metrics, got %q", state)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	sendErr = nil
+	if err := send(); err != nil {
+		t.Fatalf("expected the half-open trial to go through, got %v", err)
+	}
+	if state := s.GetMetrics().CircuitBreakers["agent-1"]; state != CircuitBreakerClosed {
+		t.Errorf("expected closed state after a successful trial, got %q", state)
+	}
+}
+
+func TestServiceConnectCircuitBreaker(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	s := NewService(bus.New(), HubConfig{
+		Name:           "test",
+		CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 1, RecoveryTimeout: time.Minute},
+	})
+	s.RegisterAdapter(NewGRPCAdapter("127.0.0.1", []int{port}))
+	ctx := context.Background()
+	s.registry.Register(ctx, &AgentInfo{
+		Identity: AgentIdentity{ID: "dead", Name: "dead"},
+		Endpoint: EndpointInfo{Type: "grpc", Host: "127.0.0.1", Port: port},
+		Protocol: "grpc",
+	})
+
+	config := AgentConfig{Timeout: 200 * time.Millisecond}
+	if _, err := s.Connect(ctx, "dead", config); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected a connection error, got %v", err)
+	}
+	start := time.Now()
+	if _, err := s.Connect(ctx, "dead", config); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("open breaker did not fail fast, took %s", elapsed)
+	}
+}
+
+func TestServiceDropsExpiredMessages(t *testing.T) {
+	b := bus.New()
+	events, cancel := b.Subscribe("agentbus.message.expired")
+	defer cancel()
+	s := NewService(b, HubConfig{Name: "test"})
+	ctx := context.Background()
+
+	calls := 0
+	s.router.AddRoute("hub", "agent-1", "*", 1, func(msg *UniversalMessage) error {
+		calls++
+		return nil
+	})
+
+	past := time.Now().Add(-time.Second)
+	err := s.SendMessage(ctx, &UniversalMessage{
+		ID:        "stale",
+		TraceID:   "trace-1",
+		From:      AgentIdentity{ID: "hub"},
+		To:        AgentIdentity{ID: "agent-1"},
+		ExpiresAt: &past,
+	})
+	if !errors.Is(err, ErrMessageExpired) {
+		t.Fatalf("expected ErrMessageExpired, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expired message reached the route handler")
+	}
+	select {
+	case evt := <-events:
+		payload := evt.Payload.(map[string]interface{})
+		if payload["trace_id"] != "trace-1" || payload["stage"] != "send" {
+			t.Errorf("unexpected expired event payload %v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected agentbus.message.expired event")
+	}
+
+	future := time.Now().Add(time.Minute)
+	if err := s.SendMessage(ctx, &UniversalMessage{
+		From:      AgentIdentity{ID: "hub"},
+		To:        AgentIdentity{ID: "agent-1"},
+		ExpiresAt: &future,
+	}); err != nil {
+		t.Fatalf("unexpired message failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the unexpired message to be routed, got %d calls", calls)
+	}
+}
+
+func TestServiceReceiveDiscardsExpiredMessages(t *testing.T) {
+	addr := startEchoAgent(t)
+	b := bus.New()
+	events, cancel := b.Subscribe("agentbus.message.expired")
+	defer cancel()
+	s := NewService(b, HubConfig{Name: "test"})
+	s.RegisterAdapter(NewGRPCAdapter("127.0.0.1", []int{addr.Port}))
+	ctx, stop := context.WithTimeout(context.Background(), 5*time.Second)
+	defer stop()
+	s.connections.Start(ctx)
+	defer s.connections.Stop(ctx)
+	s.registry.Register(ctx, &AgentInfo{
+		Identity: AgentIdentity{ID: "echo", Name: "echo"},
+		Endpoint: EndpointInfo{Type: "grpc", Host: "127.0.0.1", Port: addr.Port},
+		Protocol: "grpc",
+	})
+	conn, err := s.Connect(ctx, "echo", AgentConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer s.Disconnect(ctx, conn.ID)
+
+	// The echo agent sends both messages back; the stale one must be skipped.
+	past := time.Now().Add(-time.Second)
+	for _, msg := range []*UniversalMessage{
+		{ID: "stale", TraceID: "trace-stale", ExpiresAt: &past},
+		{ID: "fresh", TraceID: "trace-fresh"},
+	} {
+		if err := conn.Adapter.Send(ctx, conn, msg); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	msg, err := s.ReceiveMessage(ctx, conn.ID)
+	if err != nil {
+		t.Fatalf("ReceiveMessage: %v", err)
+	}
+	if msg.ID != "fresh" {
+		t.Errorf("expected the fresh message, got %q", msg.ID)
+	}
+	select {
+	case evt := <-events:
+		payload := evt.Payload.(map[string]interface{})
+		if payload["trace_id"] != "trace-stale" || payload["stage"] != "receive" {
+			t.Errorf("unexpected expired event payload %v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected agentbus.message.expired event")
+	}
+}
+
+// flakyAdapter is a gRPC adapter whose Connect fails while down is set.
+type flakyAdapter struct {
+	*GRPCAdapter
+	down atomic.Bool
+}
+
+func (a *flakyAdapter) Connect(ctx context.Context, agent AgentInfo, config AgentConfig) (AgentConnection, error) {
+	if a.down.Load() {
+		return AgentConnection{State: ConnectionStateFailed}, errors.New("agent down")
+	}
+	conn, err := a.GRPCAdapter.Connect(ctx, agent, config)
+	conn.Adapter = a
+	return conn, err
+}
+
+// newReconnectService connects a service to an echo agent through a
+// flakyAdapter, with reconnection configured by config.
+func newReconnectService(t *testing.T, b *bus.Bus, config AgentConfig) (*Service, *flakyAdapter, *AgentConnection) {
+	t.Helper()
+	addr := startEchoAgent(t)
+	adapter := &flakyAdapter{GRPCAdapter: NewGRPCAdapter("127.0.0.1", []int{addr.Port})}
+	s := NewService(b, HubConfig{Name: "test"})
+	s.RegisterAdapter(adapter)
+	ctx := context.Background()
+	s.connections.Start(ctx)
+	t.Cleanup(func() { s.connections.Stop(ctx) })
+	s.registry.Register(ctx, &AgentInfo{
+		Identity: AgentIdentity{ID: "echo", Name: "echo"},
+		Endpoint: EndpointInfo{Type: "grpc", Host: "127.0.0.1", Port: addr.Port},
+		Protocol: "grpc",
+	})
+	config.Timeout = 2 * time.Second
+	conn, err := s.Connect(ctx, "echo", config)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	return s, adapter, conn
+}
+
+func connState(s *Service, conn *AgentConnection) ConnectionState {
+	s.connections.mu.RLock()
+	defer s.connections.mu.RUnlock()
+	return conn.State
+}
+
+func TestConnectionManagerReconnect(t *testing.T) {
+	b := bus.New()
+	events, cancel := b.Subscribe("agentbus.connection.reconnecting", "agentbus.connection.reconnected")
+	defer cancel()
+	s, _, conn := newReconnectService(t, b, AgentConfig{
+		ReconnectEnabled:     true,
+		ReconnectDelay:       10 * time.Millisecond,
+		MaxReconnectAttempts: 3,
+	})
+	oldID := conn.ID
+
+	s.connections.MarkDisconnected(conn.ID, errors.New("stream reset"))
+
+	for _, want := range []bus.EventType{"agentbus.connection.reconnecting", "agentbus.connection.reconnected"} {
+		select {
+		case evt := <-events:
+			if evt.Event != want {
+				t.Fatalf("expected %s, got %s", want, evt.Event)
+			}
+			if payload := evt.Payload.(map[string]interface{}); payload["attempt"] != 1 {
+				t.Errorf("%s: expected attempt 1, got %v", want, payload["attempt"])
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("expected %s event", want)
+		}
+	}
+
+	if state := connState(s, conn); state != ConnectionStateConnected {
+		t.Fatalf("expected connected state, got %s", state)
+	}
+	if conn.ID == oldID {
+		t.Error("expected the reconnected connection to get the adapter's new ID")
+	}
+	if s.connections.Count() != 1 {
+		t.Errorf("expected one connection, got %d", s.connections.Count())
+	}
+
+	ctx, stop := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stop()
+	if err := conn.Adapter.Send(ctx, conn, &UniversalMessage{ID: "after"}); err != nil {
+		t.Fatalf("Send after reconnect: %v", err)
+	}
+	msg, err := s.ReceiveMessage(ctx, conn.ID)
+	if err != nil || msg.ID != "after" {
+		t.Fatalf("expected the echo after reconnecting, got %v, %v", msg, err)
+	}
+}
+
+func TestConnectionManagerReconnectGivesUp(t *testing.T) {
+	b := bus.New()
+	events, cancel := b.Subscribe("agentbus.connection.reconnecting", "agentbus.connection.failed")
+	defer cancel()
+	s, adapter, conn := newReconnectService(t, b, AgentConfig{
+		ReconnectEnabled:     true,
+		ReconnectDelay:       5 * time.Millisecond,
+		MaxReconnectAttempts: 2,
+	})
+	adapter.down.Store(true)
+
+	s.connections.MarkDisconnected(conn.ID, errors.New("stream reset"))
+	// A second report of the same drop must not start another loop
+	s.connections.MarkDisconnected(conn.ID, errors.New("stream reset"))
+
+	attempts := 0
+	for done := false; !done; {
+		select {
+		case evt := <-events:
+			if evt.Event == "agentbus.connection.failed" {
+				done = true
+			} else {
+				attempts++
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("expected agentbus.connection.failed event")
+		}
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 reconnect attempts, got %d", attempts)
+	}
+	if state := connState(s, conn); state != ConnectionStateFailed {
+		t.Errorf("expected failed state, got %s", state)
+	}
+}
+
+func TestConnectionManagerReconnectStops(t *testing.T) {
+	b := bus.New()
+	events, cancel := b.Subscribe("agentbus.connection.reconnecting")
+	defer cancel()
+	s, adapter, conn := newReconnectService(t, b, AgentConfig{
+		ReconnectEnabled:     true,
+		ReconnectDelay:       time.Minute,
+		MaxReconnectAttempts: 3,
+	})
+	adapter.down.Store(true)
+
+	s.connections.MarkDisconnected(conn.ID, errors.New("stream reset"))
+	select {
+	case <-events:
+	case <-time.After(time.Second):
+		t.Fatal("expected agentbus.connection.reconnecting event")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		s.connections.Stop(context.Background())
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not interrupt the reconnect loop")
+	}
+}
+
+func TestConnectionManagerNoReconnectWhenDisabled(t *testing.T) {
+	b := bus.New()
+	events, cancel := b.Subscribe("agentbus.connection.disconnected", "agentbus.connection.reconnecting")
+	defer cancel()
+	s, _, conn := newReconnectService(t, b, AgentConfig{})
+
+	s.connections.MarkDisconnected(conn.ID, errors.New("stream reset"))
+	select {
+	case evt := <-events:
+		if evt.Event != "agentbus.connection.disconnected" {
+			t.Fatalf("expected disconnected event, got %s", evt.Event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected agentbus.connection.disconnected event")
+	}
+	select {
+	case evt := <-events:
+		t.Fatalf("unexpected %s event with reconnect disabled", evt.Event)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if state := connState(s, conn); state != ConnectionStateDisconnected {
+		t.Errorf("expected disconnected state, got %s", state)
+	}
+}
+
 func TestMessageRouter(t *testing.T) {
 	b := bus.New()
 	mr := NewMessageRouter(b)
@@ -452,3 +823,121 @@ func TestDetectionManagerDetectAll(t *testing.T) {
 		t.Errorf("expected 0 agents with no adapters, got %d", len(agents))
 	}
 }
+
+// stubDetectAdapter reports one agent after delay, ignoring its context, and
+// tracks how many of its kind are detecting at once.
+type stubDetectAdapter struct {
+	*GRPCAdapter
+	protocol string
+	delay    time.Duration
+	calls    atomic.Int32
+	inFlight *atomic.Int32
+	peak     *atomic.Int32
+}
+
+func (a *stubDetectAdapter) Protocol() string { return a.protocol }
+
+func (a *stubDetectAdapter) Detect(ctx context.Context) ([]AgentInfo, error) {
+	a.calls.Add(1)
+	n := a.inFlight.Add(1)
+	defer a.inFlight.Add(-1)
+	for {
+		p := a.peak.Load()
+		if n <= p || a.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	time.Sleep(a.delay)
+	return []AgentInfo{{Identity: AgentIdentity{ID: a.protocol, Name: a.protocol}}}, nil
+}
+
+func TestDetectionManagerDetectAllLimits(t *testing.T) {
+	dm := NewDetectionManager(bus.New())
+	dm.SetLimits(50*time.Millisecond, 2)
+	ctx := context.Background()
+
+	var inFlight, peak atomic.Int32
+	adapters := map[string]AgentAdapter{}
+	fast := map[string]*stubDetectAdapter{}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		a := &stubDetectAdapter{protocol: name, delay: 10 * time.Millisecond, inFlight: &inFlight, peak: &peak}
+		fast[name] = a
+		adapters[name] = a
+	}
+	slow := &stubDetectAdapter{protocol: "slow", delay: time.Second, inFlight: &atomic.Int32{}, peak: &atomic.Int32{}}
+	adapters["slow"] = slow
+
+	start := time.Now()
+	agents, err := dm.DetectAll(ctx, adapters)
+	if err != nil {
+		t.Fatalf("detect all failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("slow adapter held up detection for %v", elapsed)
+	}
+	if len(agents) != 4 {
+		t.Fatalf("expected 4 agents from the fast adapters, got %d", len(agents))
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("expected at most 2 adapters detecting at once, got %d", p)
+	}
+
+	stats := dm.Stats()
+	if st := stats["slow"]; st.Timeouts != 1 || st.ConsecutiveTimeouts != 1 {
+		t.Fatalf("expected one timeout for slow adapter, got %+v", st)
+	}
+	if st := stats["a"]; st.Runs != 1 || st.LastDuration <= 0 {
+		t.Fatalf("expected a recorded run for adapter a, got %+v", st)
+	}
+
+	// The slow adapter sits out the next cycle, then is retried
+	dm.DetectAll(ctx, adapters)
+	if got := slow.calls.Load(); got != 1 {
+		t.Fatalf("expected slow adapter to be skipped after a timeout, got %d calls", got)
+	}
+	if got := fast["a"].calls.Load(); got != 2 {
+		t.Fatalf("expected fast adapter to keep running, got %d calls", got)
+	}
+	dm.DetectAll(ctx, adapters)
+	if got := slow.calls.Load(); got != 2 {
+		t.Fatalf("expected slow adapter to be retried after backing off, got %d calls", got)
+	}
+	// A second consecutive timeout doubles the backoff
+	if st := dm.Stats()["slow"]; st.ConsecutiveTimeouts != 2 || st.skipCycles != 2 || st.Skipped != 1 {
+		t.Fatalf("expected backoff to double, got %+v", st)
+	}
+}
+
+// installAdapter is a low priority adapter that installs packages.
+type installAdapter struct {
+	*GRPCAdapter
+	installed []string
+}
+
+func (a *installAdapter) Protocol() string { return "local" }
+
+func (a *installAdapter) Priority() int { return PriorityGRPC - 10 }
+
+func (a *installAdapter) Install(ctx context.Context, pkg AgentPackage) error {
+	a.installed = append(a.installed, pkg.Name)
+	return nil
+}
+
+func TestInstallPackageSkipsAdaptersThatCannotInstall(t *testing.T) {
+	s := NewService(bus.New(), HubConfig{Name: "test"})
+	s.RegisterAdapter(NewGRPCAdapter("127.0.0.1", []int{}))
+	ctx := context.Background()
+
+	if err := s.InstallPackage(ctx, AgentPackage{Name: "pkg"}); err == nil || !strings.Contains(err.Error(), "no installer available") {
+		t.Errorf("expected no installer available, got %v", err)
+	}
+
+	local := &installAdapter{GRPCAdapter: NewGRPCAdapter("127.0.0.1", []int{})}
+	s.RegisterAdapter(local)
+	if err := s.InstallPackage(ctx, AgentPackage{Name: "pkg"}); err != nil {
+		t.Fatalf("InstallPackage: %v", err)
+	}
+	if len(local.installed) != 1 || local.installed[0] != "pkg" {
+		t.Errorf("expected the local adapter to install pkg, got %v", local.installed)
+	}
+}
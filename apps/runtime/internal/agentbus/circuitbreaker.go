@@ -1,10 +1,14 @@
 package agentbus
 
 import (
+	"errors"
 	"sync"
 	"time"
 )
 
+// ErrCircuitOpen is returned when a circuit breaker rejects a request.
+var ErrCircuitOpen = errors.New("circuit open")
+
 // CircuitBreakerState represents the state of a circuit breaker
 type CircuitBreakerState string
 
@@ -23,6 +27,7 @@ type CircuitBreaker struct {
 	state           CircuitBreakerState
 	failureCount    int
 	successCount    int
+	trialCount      int // requests allowed since entering half-open
 	lastFailureTime time.Time
 	lastSuccessTime time.Time
 	config          CircuitBreakerConfig
@@ -53,7 +58,9 @@ func (cb *CircuitBreaker) State() CircuitBreakerState {
 	return cb.state
 }
 
-// AllowRequest checks if a request should be allowed
+// AllowRequest checks if a request should be allowed. Once the recovery
+// timeout has elapsed an open breaker turns half-open and allows up to
+// HalfOpenRequests trial requests.
 func (cb *CircuitBreaker) AllowRequest() bool {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
@@ -67,11 +74,16 @@ func (cb *CircuitBreaker) AllowRequest() bool {
 			cb.state = CircuitBreakerHalfOpen
 			cb.successCount = 0
 			cb.failureCount = 0
+			cb.trialCount = 1
 			return true
 		}
 		return false
 	case CircuitBreakerHalfOpen:
-		return cb.successCount < cb.config.HalfOpenRequests
+		if cb.trialCount < cb.config.HalfOpenRequests {
+			cb.trialCount++
+			return true
+		}
+		return false
 	}
 	return false
 }
@@ -90,10 +102,11 @@ func (cb *CircuitBreaker) RecordSuccess() {
 		if cb.successCount >= cb.config.HalfOpenRequests {
 			cb.state = CircuitBreakerClosed
 			cb.failureCount = 0
+			cb.trialCount = 0
 		}
 	case CircuitBreakerClosed:
-		// Reset failure count on success (optional strategy)
-		// cb.failureCount = 0
+		// Only consecutive failures open the circuit
+		cb.failureCount = 0
 	}
 }
 
@@ -114,6 +127,7 @@ func (cb *CircuitBreaker) RecordFailure() {
 	case CircuitBreakerHalfOpen:
 		// Any failure in half-open state opens the circuit
 		cb.state = CircuitBreakerOpen
+		cb.trialCount = 0
 	}
 }
 
@@ -139,6 +153,7 @@ func (cb *CircuitBreaker) Reset() {
 	cb.state = CircuitBreakerClosed
 	cb.failureCount = 0
 	cb.successCount = 0
+	cb.trialCount = 0
 	cb.lastFailureTime = time.Time{}
 	cb.lastSuccessTime = time.Time{}
 }
@@ -160,6 +175,7 @@ func (cb *CircuitBreaker) ForceClose() {
 	cb.state = CircuitBreakerClosed
 	cb.failureCount = 0
 	cb.successCount = 0
+	cb.trialCount = 0
 }
 
 // String returns a string representation of the circuit breaker
@@ -16,9 +16,12 @@ type ConnectionManager struct {
 	connections     map[string]*AgentConnection
 	metrics         ConnectionMetrics
 	circuitBreakers map[string]*CircuitBreaker
-	running         bool
-	stopCh          chan struct{}
-	wg              sync.WaitGroup
+	// configs holds the AgentConfig each connection was opened with, used to
+	// reconnect it when it drops
+	configs map[string]AgentConfig
+	running bool
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
 }
 
 // NewConnectionManager creates a new connection manager
@@ -28,6 +31,7 @@ func NewConnectionManager(b *bus.Bus) *ConnectionManager {
 		logger:          NewStructuredLogger("connections", "info"),
 		connections:     make(map[string]*AgentConnection),
 		circuitBreakers: make(map[string]*CircuitBreaker),
+		configs:         make(map[string]AgentConfig),
 		metrics: ConnectionMetrics{
 			ProtocolStats: make(map[string]int64),
 		},
@@ -72,6 +76,7 @@ func (cm *ConnectionManager) Stop(ctx context.Context) error {
 			conn.Adapter.Disconnect(ctx, conn)
 		}
 		delete(cm.connections, id)
+		delete(cm.configs, id)
 	}
 	cm.mu.Unlock()
 
@@ -83,6 +88,12 @@ func (cm *ConnectionManager) Stop(ctx context.Context) error {
 
 // Add registers a new connection
 func (cm *ConnectionManager) Add(ctx context.Context, conn *AgentConnection) {
+	cm.AddWithConfig(ctx, conn, AgentConfig{})
+}
+
+// AddWithConfig registers a new connection opened with config. If
+// config.ReconnectEnabled is set, the connection is reconnected when it drops.
+func (cm *ConnectionManager) AddWithConfig(ctx context.Context, conn *AgentConnection, config AgentConfig) {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
@@ -108,6 +119,7 @@ func (cm *ConnectionManager) Add(ctx context.Context, conn *AgentConnection) {
 
 	// Store connection
 	cm.connections[conn.ID] = conn
+	cm.configs[conn.ID] = config
 
 	// Update metrics
 	cm.metrics.TotalConnections++
@@ -145,6 +157,7 @@ func (cm *ConnectionManager) Remove(ctx context.Context, connID string) {
 
 	// Remove from registry
 	delete(cm.connections, connID)
+	delete(cm.configs, connID)
 
 	// Update metrics
 	cm.metrics.ActiveConnections--
@@ -229,20 +242,25 @@ func (cm *ConnectionManager) GetMetrics() ConnectionMetrics {
 	cm.mu.RLock()
 	defer cm.mu.RUnlock()
 
-	return cm.metrics
+	metrics := cm.metrics
+	metrics.CircuitBreakers = make(map[string]CircuitBreakerState, len(cm.circuitBreakers))
+	for agentID, cb := range cm.circuitBreakers {
+		metrics.CircuitBreakers[agentID] = cb.State()
+	}
+	return metrics
 }
 
-// GetCircuitBreaker returns or creates a circuit breaker for a connection
-func (cm *ConnectionManager) GetCircuitBreaker(connID string, config CircuitBreakerConfig) *CircuitBreaker {
+// GetCircuitBreaker returns or creates the circuit breaker for an agent
+func (cm *ConnectionManager) GetCircuitBreaker(agentID string, config CircuitBreakerConfig) *CircuitBreaker {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	if cb, exists := cm.circuitBreakers[connID]; exists {
+	if cb, exists := cm.circuitBreakers[agentID]; exists {
 		return cb
 	}
 
 	cb := NewCircuitBreaker(config)
-	cm.circuitBreakers[connID] = cb
+	cm.circuitBreakers[agentID] = cb
 	return cb
 }
 
@@ -275,6 +293,7 @@ func (cm *ConnectionManager) HealthCheck(ctx context.Context) error {
 				"agent_name":    conn.AgentInfo.Identity.Name,
 				"error":         err.Error(),
 			})
+			cm.MarkDisconnected(conn.ID, err)
 		} else {
 			cm.mu.Lock()
 			conn.AgentInfo.HealthStatus = "healthy"
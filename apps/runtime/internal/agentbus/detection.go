@@ -2,29 +2,76 @@ package agentbus
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"sync"
+	"time"
 
 	"pryx-core/internal/bus"
 )
 
+const (
+	// defaultDetectTimeout bounds a single adapter's detection run.
+	defaultDetectTimeout = 10 * time.Second
+	// defaultDetectParallel is how many adapters detect at once.
+	defaultDetectParallel = 4
+	// maxDetectSkipCycles caps how many cycles a timing-out adapter sits out.
+	maxDetectSkipCycles = 8
+)
+
+// ProtocolDetectionStats describes the detection runs of one protocol adapter
+type ProtocolDetectionStats struct {
+	Runs                int64         `json:"runs"`
+	Timeouts            int64         `json:"timeouts"`
+	Skipped             int64         `json:"skipped"`
+	ConsecutiveTimeouts int           `json:"consecutive_timeouts"`
+	LastRun             time.Time     `json:"last_run"`
+	LastDuration        time.Duration `json:"last_duration"`
+	LastError           string        `json:"last_error,omitempty"`
+
+	// skipCycles is how many more DetectAll cycles skip this protocol
+	skipCycles int
+}
+
 // DetectionManager manages auto-detection of running agents
 type DetectionManager struct {
-	mu      sync.RWMutex
-	bus     *bus.Bus
-	logger  *StructuredLogger
-	running bool
-	stopCh  chan struct{}
+	mu       sync.RWMutex
+	bus      *bus.Bus
+	logger   *StructuredLogger
+	timeout  time.Duration
+	parallel int
+	stats    map[string]*ProtocolDetectionStats
+	running  bool
+	stopCh   chan struct{}
 }
 
 // NewDetectionManager creates a new detection manager
 func NewDetectionManager(b *bus.Bus) *DetectionManager {
 	return &DetectionManager{
-		bus:    b,
-		logger: NewStructuredLogger("detection", "info"),
-		stopCh: make(chan struct{}),
+		bus:      b,
+		logger:   NewStructuredLogger("detection", "info"),
+		timeout:  defaultDetectTimeout,
+		parallel: defaultDetectParallel,
+		stats:    make(map[string]*ProtocolDetectionStats),
+		stopCh:   make(chan struct{}),
 	}
 }
 
+// SetLimits sets the per-adapter timeout and how many adapters DetectAll runs
+// at once. Non-positive values keep the defaults.
+func (dm *DetectionManager) SetLimits(timeout time.Duration, parallel int) {
+	if timeout <= 0 {
+		timeout = defaultDetectTimeout
+	}
+	if parallel <= 0 {
+		parallel = defaultDetectParallel
+	}
+	dm.mu.Lock()
+	dm.timeout = timeout
+	dm.parallel = parallel
+	dm.mu.Unlock()
+}
+
 // Start initializes the detection manager
 func (dm *DetectionManager) Start(ctx context.Context) error {
 	dm.mu.Lock()
@@ -59,26 +106,64 @@ func (dm *DetectionManager) Stop(ctx context.Context) error {
 	return nil
 }
 
-// DetectAll runs detection using all available adapters
+// DetectAll runs detection with every adapter, at most the configured number
+// at a time, giving each the detection timeout. An adapter that times out sits
+// out the following cycles, twice as many after each consecutive timeout.
 func (dm *DetectionManager) DetectAll(ctx context.Context, adapters map[string]AgentAdapter) ([]AgentInfo, error) {
 	dm.logger.Info("running detection with all adapters", map[string]interface{}{
 		"adapter_count": len(adapters),
 	})
 
+	dm.mu.RLock()
+	timeout, parallel := dm.timeout, dm.parallel
+	dm.mu.RUnlock()
+
+	protocols := make([]string, 0, len(adapters))
+	for protocol := range adapters {
+		protocols = append(protocols, protocol)
+	}
+	sort.Strings(protocols)
+
 	var allAgents []AgentInfo
 	var mu sync.Mutex
 	var wg sync.WaitGroup
+	durations := make(map[string]int64, len(protocols))
+	var skipped []string
+	sem := make(chan struct{}, parallel)
 
-	for _, adapter := range adapters {
+	for _, protocol := range protocols {
+		if dm.shouldSkip(protocol) {
+			skipped = append(skipped, protocol)
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return allAgents, ctx.Err()
+		}
 		wg.Add(1)
-		go func(a AgentAdapter) {
+		go func(protocol string, a AgentAdapter) {
 			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			agents, err := detectWithTimeout(ctx, a, timeout)
+			elapsed := time.Since(start)
+			timedOut := errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil
+			dm.record(protocol, elapsed, err, timedOut)
+
+			mu.Lock()
+			durations[protocol] = elapsed.Milliseconds()
+			mu.Unlock()
 
-			agents, err := a.Detect(ctx)
 			if err != nil {
 				dm.logger.Error("detection failed for adapter", map[string]interface{}{
-					"protocol": a.Protocol(),
-					"error":    err.Error(),
+					"protocol":    protocol,
+					"error":       err.Error(),
+					"timed_out":   timedOut,
+					"duration_ms": elapsed.Milliseconds(),
 				})
 				return
 			}
@@ -86,18 +171,102 @@ func (dm *DetectionManager) DetectAll(ctx context.Context, adapters map[string]A
 			mu.Lock()
 			allAgents = append(allAgents, agents...)
 			mu.Unlock()
-		}(adapter)
+		}(protocol, adapters[protocol])
 	}
 
 	wg.Wait()
 
 	dm.logger.Info("detection complete", map[string]interface{}{
-		"agent_count": len(allAgents),
+		"agent_count":  len(allAgents),
+		"durations_ms": durations,
+		"skipped":      skipped,
 	})
+	dm.bus.Publish(bus.NewEvent("agentbus.detection.completed", "", map[string]interface{}{
+		"agent_count":  len(allAgents),
+		"durations_ms": durations,
+		"skipped":      skipped,
+	}))
 
 	return allAgents, nil
 }
 
+// detectWithTimeout runs a.Detect, giving up after timeout even if the adapter
+// ignores its context.
+func detectWithTimeout(ctx context.Context, a AgentAdapter, timeout time.Duration) ([]AgentInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		agents []AgentInfo
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		agents, err := a.Detect(ctx)
+		done <- result{agents, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.agents, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// shouldSkip reports whether protocol is backing off after timeouts, using up
+// one of its skipped cycles.
+func (dm *DetectionManager) shouldSkip(protocol string) bool {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	st := dm.stats[protocol]
+	if st == nil || st.skipCycles == 0 {
+		return false
+	}
+	st.skipCycles--
+	st.Skipped++
+	return true
+}
+
+// record stores the outcome of a detection run for protocol.
+func (dm *DetectionManager) record(protocol string, elapsed time.Duration, err error, timedOut bool) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+	st := dm.stats[protocol]
+	if st == nil {
+		st = &ProtocolDetectionStats{}
+		dm.stats[protocol] = st
+	}
+	st.Runs++
+	st.LastRun = time.Now().UTC()
+	st.LastDuration = elapsed
+	st.LastError = ""
+	if err != nil {
+		st.LastError = err.Error()
+	}
+	if !timedOut {
+		st.ConsecutiveTimeouts = 0
+		return
+	}
+	st.Timeouts++
+	st.ConsecutiveTimeouts++
+	st.skipCycles = 1 << (st.ConsecutiveTimeouts - 1)
+	if st.skipCycles > maxDetectSkipCycles {
+		st.skipCycles = maxDetectSkipCycles
+	}
+}
+
+// Stats returns the detection statistics of each protocol that has run.
+func (dm *DetectionManager) Stats() map[string]ProtocolDetectionStats {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	out := make(map[string]ProtocolDetectionStats, len(dm.stats))
+	for protocol, st := range dm.stats {
+		out[protocol] = *st
+	}
+	return out
+}
+
 // DetectProtocol runs detection using a specific protocol adapter
 func (dm *DetectionManager) DetectProtocol(ctx context.Context, protocol string, adapters map[string]AgentAdapter) ([]AgentInfo, error) {
 	adapter, exists := adapters[protocol]
@@ -109,11 +278,19 @@ func (dm *DetectionManager) DetectProtocol(ctx context.Context, protocol string,
 		"protocol": protocol,
 	})
 
-	agents, err := adapter.Detect(ctx)
+	dm.mu.RLock()
+	timeout := dm.timeout
+	dm.mu.RUnlock()
+
+	start := time.Now()
+	agents, err := detectWithTimeout(ctx, adapter, timeout)
+	elapsed := time.Since(start)
+	dm.record(protocol, elapsed, err, errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil)
 	if err != nil {
 		dm.logger.Error("detection failed for protocol", map[string]interface{}{
-			"protocol": protocol,
-			"error":    err.Error(),
+			"protocol":    protocol,
+			"error":       err.Error(),
+			"duration_ms": elapsed.Milliseconds(),
 		})
 		return nil, err
 	}
@@ -121,6 +298,7 @@ func (dm *DetectionManager) DetectProtocol(ctx context.Context, protocol string,
 	dm.logger.Info("detection complete for protocol", map[string]interface{}{
 		"protocol":    protocol,
 		"agent_count": len(agents),
+		"duration_ms": elapsed.Milliseconds(),
 	})
 
 	return agents, nil
@@ -0,0 +1,352 @@
+package agentbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// GRPCServiceName is the service gRPC agents expose. Agents implement a
+	// single bidirectional streaming method, Stream, exchanging
+	// UniversalMessage values encoded as JSON (content subtype "json"), and
+	// report readiness for this name on the standard health service.
+	GRPCServiceName = "pryx.agentbus.v1.AgentBus"
+
+	grpcStreamMethod = "/" + GRPCServiceName + "/Stream"
+
+	// grpcProbeTimeout bounds the health probe of each port during detection.
+	grpcProbeTimeout = 500 * time.Millisecond
+)
+
+// DefaultGRPCPorts are the local ports probed for gRPC agents.
+var DefaultGRPCPorts = []int{50051, 50052}
+
+var grpcStreamDesc = grpc.StreamDesc{
+	StreamName:    "Stream",
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// grpcJSONCodec encodes stream messages as JSON, so agents need no generated
+// protobuf types to speak the protocol.
+type grpcJSONCodec struct{}
+
+func (grpcJSONCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (grpcJSONCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (grpcJSONCodec) Name() string                               { return "json" }
+
+// GRPCAdapter connects to agents serving GRPCServiceName.
+type GRPCAdapter struct {
+	mu       sync.Mutex
+	host     string
+	ports    []int
+	sessions map[string]*grpcSession
+}
+
+// grpcSession is the client connection and message stream behind one AgentConnection.
+type grpcSession struct {
+	cc     *grpc.ClientConn
+	stream grpc.ClientStream
+	cancel context.CancelFunc
+	done   <-chan struct{}
+	sendMu sync.Mutex
+	inbox  chan grpcReceived
+}
+
+type grpcReceived struct {
+	msg *UniversalMessage
+	err error
+}
+
+// NewGRPCAdapter creates a gRPC adapter that detects agents on host's ports.
+// An empty host means 127.0.0.1 and nil ports means DefaultGRPCPorts.
+func NewGRPCAdapter(host string, ports []int) *GRPCAdapter {
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if ports == nil {
+		ports = DefaultGRPCPorts
+	}
+	return &GRPCAdapter{
+		host:     host,
+		ports:    ports,
+		sessions: make(map[string]*grpcSession),
+	}
+}
+
+// Protocol returns the protocol name
+func (a *GRPCAdapter) Protocol() string {
+	return "grpc"
+}
+
+// Priority returns the adapter priority
+func (a *GRPCAdapter) Priority() int {
+	return PriorityGRPC
+}
+
+// Detect probes the health service on each configured port and reports the
+// ports where GRPCServiceName is serving.
+func (a *GRPCAdapter) Detect(ctx context.Context) ([]AgentInfo, error) {
+	var agents []AgentInfo
+	for _, port := range a.ports {
+		if err := ctx.Err(); err != nil {
+			return agents, err
+		}
+		target := net.JoinHostPort(a.host, strconv.Itoa(port))
+		if err := probeGRPCHealth(ctx, target); err != nil {
+			continue
+		}
+		agents = append(agents, AgentInfo{
+			Identity: AgentIdentity{
+				ID:   "grpc-" + target,
+				Name: target,
+			},
+			Endpoint: EndpointInfo{
+				Type: "grpc",
+				URL:  target,
+				Host: a.host,
+				Port: port,
+			},
+			Capabilities: []string{},
+			Protocol:     "grpc",
+			LastSeen:     time.Now(),
+			HealthStatus: "healthy",
+		})
+	}
+	return agents, nil
+}
+
+// Connect dials the agent, waiting up to config.Timeout for the connection to
+// become ready, and opens the message stream. If the dial fails the returned
+// connection is in ConnectionStateFailed.
+func (a *GRPCAdapter) Connect(ctx context.Context, agent AgentInfo, config AgentConfig) (AgentConnection, error) {
+	now := time.Now()
+	conn := AgentConnection{
+		ID:           uuid.New().String(),
+		AgentInfo:    agent,
+		State:        ConnectionStateConnecting,
+		Protocol:     "grpc",
+		Adapter:      a,
+		LastActivity: now,
+		CreatedAt:    now,
+	}
+
+	target := grpcTarget(agent.Endpoint)
+	if target == "" {
+		conn.State = ConnectionStateFailed
+		return conn, fmt.Errorf("agent %s has no grpc endpoint", agent.Identity.ID)
+	}
+
+	cc, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		conn.State = ConnectionStateFailed
+		return conn, fmt.Errorf("failed to dial %s: %w", target, err)
+	}
+
+	dialCtx := ctx
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		dialCtx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+	}
+	if err := waitForReady(dialCtx, cc); err != nil {
+		cc.Close()
+		conn.State = ConnectionStateFailed
+		return conn, fmt.Errorf("failed to connect to %s: %w", target, err)
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stream, err := cc.NewStream(streamCtx, &grpcStreamDesc, grpcStreamMethod, grpc.ForceCodec(grpcJSONCodec{}))
+	if err != nil {
+		cancel()
+		cc.Close()
+		conn.State = ConnectionStateFailed
+		return conn, fmt.Errorf("failed to open stream to %s: %w", target, err)
+	}
+
+	sess := &grpcSession{
+		cc:     cc,
+		stream: stream,
+		cancel: cancel,
+		done:   streamCtx.Done(),
+		inbox:  make(chan grpcReceived, 16),
+	}
+	go sess.readLoop()
+
+	a.mu.Lock()
+	a.sessions[conn.ID] = sess
+	a.mu.Unlock()
+
+	connected := time.Now()
+	conn.State = ConnectionStateConnected
+	conn.ConnectedAt = &connected
+	conn.AgentInfo.HealthStatus = "connected"
+	return conn, nil
+}
+
+// Send writes msg to the connection's stream.
+func (a *GRPCAdapter) Send(ctx context.Context, conn *AgentConnection, msg *UniversalMessage) error {
+	sess, err := a.session(conn)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sess.sendMu.Lock()
+	defer sess.sendMu.Unlock()
+	if err := sess.stream.SendMsg(msg); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// Receive returns the next message from the connection's stream.
+func (a *GRPCAdapter) Receive(ctx context.Context, conn *AgentConnection) (*UniversalMessage, error) {
+	sess, err := a.session(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r, ok := <-sess.inbox:
+		if !ok {
+			return nil, fmt.Errorf("stream closed")
+		}
+		if r.err != nil {
+			return nil, fmt.Errorf("failed to receive message: %w", r.err)
+		}
+		return r.msg, nil
+	}
+}
+
+// Disconnect closes the stream and the client connection.
+func (a *GRPCAdapter) Disconnect(ctx context.Context, conn *AgentConnection) error {
+	a.mu.Lock()
+	sess, exists := a.sessions[conn.ID]
+	delete(a.sessions, conn.ID)
+	a.mu.Unlock()
+
+	conn.State = ConnectionStateClosed
+	if !exists {
+		return nil
+	}
+	sess.cancel()
+	return sess.cc.Close()
+}
+
+// HealthCheck asks the agent's health service whether GRPCServiceName is serving.
+func (a *GRPCAdapter) HealthCheck(ctx context.Context, conn *AgentConnection) error {
+	sess, err := a.session(conn)
+	if err != nil {
+		return err
+	}
+	return checkGRPCHealth(ctx, sess.cc)
+}
+
+// Install is not supported; gRPC agents are installed and run externally.
+func (a *GRPCAdapter) Install(ctx context.Context, pkg AgentPackage) error {
+	return ErrInstallUnsupported
+}
+
+// Uninstall is not supported; gRPC agents are installed and run externally.
+func (a *GRPCAdapter) Uninstall(ctx context.Context, pkg AgentPackage) error {
+	return ErrInstallUnsupported
+}
+
+func (a *GRPCAdapter) session(conn *AgentConnection) (*grpcSession, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	sess, exists := a.sessions[conn.ID]
+	if !exists {
+		return nil, fmt.Errorf("grpc connection %s is not open", conn.ID)
+	}
+	return sess, nil
+}
+
+// readLoop moves messages from the stream to the inbox until the stream ends.
+func (s *grpcSession) readLoop() {
+	defer close(s.inbox)
+	for {
+		msg := &UniversalMessage{}
+		err := s.stream.RecvMsg(msg)
+		r := grpcReceived{msg: msg}
+		if err != nil {
+			r = grpcReceived{err: err}
+		}
+		select {
+		case s.inbox <- r:
+		case <-s.done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// grpcTarget returns the dial target for an endpoint.
+func grpcTarget(ep EndpointInfo) string {
+	if ep.URL != "" {
+		return strings.TrimPrefix(ep.URL, "grpc://")
+	}
+	if ep.Host != "" && ep.Port > 0 {
+		return net.JoinHostPort(ep.Host, strconv.Itoa(ep.Port))
+	}
+	return ""
+}
+
+// waitForReady connects cc and blocks until it is ready or ctx is done.
+func waitForReady(ctx context.Context, cc *grpc.ClientConn) error {
+	cc.Connect()
+	for {
+		state := cc.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("connection shut down")
+		}
+		if !cc.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("%w (last state %s)", ctx.Err(), state)
+		}
+	}
+}
+
+func probeGRPCHealth(ctx context.Context, target string) error {
+	ctx, cancel := context.WithTimeout(ctx, grpcProbeTimeout)
+	defer cancel()
+
+	cc, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+	return checkGRPCHealth(ctx, cc)
+}
+
+func checkGRPCHealth(ctx context.Context, cc *grpc.ClientConn) error {
+	resp, err := grpc_health_v1.NewHealthClient(cc).Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: GRPCServiceName})
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("agent unhealthy: %s", resp.GetStatus())
+	}
+	return nil
+}
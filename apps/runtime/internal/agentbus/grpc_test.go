@@ -0,0 +1,148 @@
+package agentbus
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func init() {
+	// The echo agent picks the codec from the request's content subtype, so
+	// health checks keep using protobuf.
+	encoding.RegisterCodec(grpcJSONCodec{})
+}
+
+// startEchoAgent serves GRPCServiceName on a local port, echoing each message
+// back with Action "echo".
+func startEchoAgent(t *testing.T) *net.TCPAddr {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: GRPCServiceName,
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName:    "Stream",
+			ServerStreams: true,
+			ClientStreams: true,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				for {
+					var msg UniversalMessage
+					if err := stream.RecvMsg(&msg); err != nil {
+						return nil
+					}
+					msg.Action = "echo"
+					if err := stream.SendMsg(&msg); err != nil {
+						return err
+					}
+				}
+			},
+		}},
+	}, nil)
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(GRPCServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(srv, healthSrv)
+
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().(*net.TCPAddr)
+}
+
+func TestGRPCAdapterRoundTrip(t *testing.T) {
+	addr := startEchoAgent(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	adapter := NewGRPCAdapter("127.0.0.1", []int{addr.Port})
+	agents, err := adapter.Detect(ctx)
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if len(agents) != 1 || agents[0].Endpoint.Type != "grpc" || agents[0].Endpoint.Port != addr.Port {
+		t.Fatalf("expected the echo agent to be detected, got %+v", agents)
+	}
+
+	conn, err := adapter.Connect(ctx, agents[0], AgentConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if conn.State != ConnectionStateConnected {
+		t.Fatalf("expected connected state, got %s", conn.State)
+	}
+	if err := adapter.HealthCheck(ctx, &conn); err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+
+	msg := &UniversalMessage{ID: "m1", Action: "execute", Payload: map[string]interface{}{"task": "ping"}}
+	if err := adapter.Send(ctx, &conn, msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	reply, err := adapter.Receive(ctx, &conn)
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+	if reply.ID != "m1" || reply.Action != "echo" || reply.Payload["task"] != "ping" {
+		t.Fatalf("unexpected reply %+v", reply)
+	}
+
+	if err := adapter.Disconnect(ctx, &conn); err != nil {
+		t.Fatalf("Disconnect: %v", err)
+	}
+	if conn.State != ConnectionStateClosed {
+		t.Errorf("expected closed state, got %s", conn.State)
+	}
+	if err := adapter.Send(ctx, &conn, msg); err == nil {
+		t.Error("expected Send on a closed connection to fail")
+	}
+}
+
+func TestGRPCAdapterConnectFailure(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	adapter := NewGRPCAdapter("127.0.0.1", []int{port})
+	if agents, _ := adapter.Detect(context.Background()); len(agents) != 0 {
+		t.Fatalf("expected no agents on a closed port, got %+v", agents)
+	}
+
+	start := time.Now()
+	conn, err := adapter.Connect(context.Background(), AgentInfo{
+		Identity: AgentIdentity{ID: "down"},
+		Endpoint: EndpointInfo{Type: "grpc", Host: "127.0.0.1", Port: port},
+	}, AgentConfig{Timeout: 300 * time.Millisecond})
+	if err == nil {
+		t.Fatal("expected Connect to fail")
+	}
+	if conn.State != ConnectionStateFailed {
+		t.Errorf("expected failed state, got %s", conn.State)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Connect ignored the timeout, took %s", elapsed)
+	}
+}
+
+func TestGRPCAdapterPriority(t *testing.T) {
+	s := NewService(nil, HubConfig{Name: "test"})
+	s.registerDefaultAdapters()
+	adapter, err := s.findAdapter("grpc")
+	if err != nil {
+		t.Fatalf("grpc adapter not registered: %v", err)
+	}
+	if adapter.Priority() != PriorityGRPC {
+		t.Errorf("unexpected priority %d", adapter.Priority())
+	}
+}
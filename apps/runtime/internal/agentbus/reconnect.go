@@ -0,0 +1,203 @@
+package agentbus
+
+import (
+	"context"
+	"time"
+
+	"pryx-core/internal/bus"
+)
+
+const (
+	// defaultReconnectDelay is the wait before the first reconnect attempt when
+	// AgentConfig.ReconnectDelay is unset.
+	defaultReconnectDelay = time.Second
+	// defaultMaxReconnectAttempts applies when AgentConfig.MaxReconnectAttempts
+	// is unset.
+	defaultMaxReconnectAttempts = 5
+	// maxReconnectDelay caps the doubling wait between attempts.
+	maxReconnectDelay = 30 * time.Second
+)
+
+// MarkDisconnected records that a connected connection dropped, with cause as
+// the reason. If it was opened with ReconnectEnabled, a background loop retries
+// the adapter's Connect, moving the connection through
+// ConnectionStateReconnecting and, once MaxReconnectAttempts fail,
+// ConnectionStateFailed. Connections that are not in ConnectionStateConnected
+// are left alone, so a drop is only handled once.
+func (cm *ConnectionManager) MarkDisconnected(connID string, cause error) {
+	cm.mu.Lock()
+	conn, exists := cm.connections[connID]
+	if !exists || conn.State != ConnectionStateConnected {
+		cm.mu.Unlock()
+		return
+	}
+	conn.State = ConnectionStateDisconnected
+	conn.AgentInfo.HealthStatus = "disconnected"
+	config := cm.configs[connID]
+	reconnect := config.ReconnectEnabled && cm.running && conn.Adapter != nil
+	stopCh := cm.stopCh
+	if reconnect {
+		cm.wg.Add(1)
+	}
+	cm.mu.Unlock()
+
+	fields := map[string]interface{}{
+		"connection_id": connID,
+		"agent_id":      conn.AgentInfo.Identity.ID,
+		"reconnect":     reconnect,
+	}
+	if cause != nil {
+		fields["error"] = cause.Error()
+	}
+	cm.logger.Warn("connection lost", fields)
+	cm.bus.Publish(bus.NewEvent("agentbus.connection.disconnected", "", fields))
+
+	if reconnect {
+		go cm.reconnectLoop(conn, config, stopCh)
+	}
+}
+
+// reconnectLoop retries the connection until it succeeds, the attempts run out,
+// the connection is removed, or the manager is stopped.
+func (cm *ConnectionManager) reconnectLoop(conn *AgentConnection, config AgentConfig, stopCh chan struct{}) {
+	defer cm.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	cm.mu.RLock()
+	oldID := conn.ID
+	agent := conn.AgentInfo
+	adapter := conn.Adapter
+	stale := *conn
+	cm.mu.RUnlock()
+	agentID := agent.Identity.ID
+
+	// Release whatever the adapter still holds for the dropped connection,
+	// without touching the shared connection's state.
+	adapter.Disconnect(ctx, &stale)
+
+	delay := config.ReconnectDelay
+	if delay <= 0 {
+		delay = defaultReconnectDelay
+	}
+	maxAttempts := config.MaxReconnectAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxReconnectAttempts
+	}
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		cm.mu.Lock()
+		if cm.connections[oldID] != conn {
+			// Removed while we were waiting
+			cm.mu.Unlock()
+			return
+		}
+		conn.State = ConnectionStateReconnecting
+		cm.mu.Unlock()
+
+		cm.bus.Publish(bus.NewEvent("agentbus.connection.reconnecting", "", map[string]interface{}{
+			"connection_id": oldID,
+			"agent_id":      agentID,
+			"attempt":       attempt,
+			"max_attempts":  maxAttempts,
+			"delay_ms":      delay.Milliseconds(),
+		}))
+
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(delay):
+		}
+
+		fresh, err := adapter.Connect(ctx, agent, config)
+		if err == nil {
+			cm.reconnected(ctx, conn, fresh, attempt)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		cm.logger.Warn("reconnect attempt failed", map[string]interface{}{
+			"connection_id": oldID,
+			"agent_id":      agentID,
+			"attempt":       attempt,
+			"error":         err.Error(),
+		})
+		cm.bus.Publish(bus.NewEvent("agentbus.connection.reconnect_failed", "", map[string]interface{}{
+			"connection_id": oldID,
+			"agent_id":      agentID,
+			"attempt":       attempt,
+			"max_attempts":  maxAttempts,
+			"error":         err.Error(),
+		}))
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+
+	cm.mu.Lock()
+	if cm.connections[oldID] == conn {
+		conn.State = ConnectionStateFailed
+		conn.AgentInfo.HealthStatus = "failed"
+	}
+	cm.mu.Unlock()
+
+	cm.logger.Error("giving up reconnecting", map[string]interface{}{
+		"connection_id": oldID,
+		"agent_id":      agentID,
+		"attempts":      maxAttempts,
+	})
+	cm.bus.Publish(bus.NewEvent("agentbus.connection.failed", "", map[string]interface{}{
+		"connection_id": oldID,
+		"agent_id":      agentID,
+		"attempts":      maxAttempts,
+	}))
+}
+
+// reconnected swaps the fresh adapter connection into conn, keeping its
+// counters. Adapters key their sessions by connection ID, so the connection is
+// re-registered under the fresh ID; callers holding conn see the change.
+func (cm *ConnectionManager) reconnected(ctx context.Context, conn *AgentConnection, fresh AgentConnection, attempt int) {
+	cm.mu.Lock()
+	oldID := conn.ID
+	if cm.connections[oldID] != conn {
+		cm.mu.Unlock()
+		fresh.Adapter.Disconnect(ctx, &fresh)
+		return
+	}
+	config := cm.configs[oldID]
+	delete(cm.connections, oldID)
+	delete(cm.configs, oldID)
+
+	fresh.CreatedAt = conn.CreatedAt
+	fresh.MessageCount = conn.MessageCount
+	fresh.ErrorCount = conn.ErrorCount
+	fresh.Metadata = conn.Metadata
+	*conn = fresh
+	cm.connections[conn.ID] = conn
+	cm.configs[conn.ID] = config
+	cm.mu.Unlock()
+
+	cm.logger.Info("connection restored", map[string]interface{}{
+		"connection_id":          conn.ID,
+		"previous_connection_id": oldID,
+		"attempt":                attempt,
+	})
+	cm.bus.Publish(bus.NewEvent("agentbus.connection.reconnected", "", map[string]interface{}{
+		"connection_id":          conn.ID,
+		"previous_connection_id": oldID,
+		"agent_id":               conn.AgentInfo.Identity.ID,
+		"attempt":                attempt,
+	}))
+}
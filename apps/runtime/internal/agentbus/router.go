@@ -2,7 +2,9 @@ package agentbus
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"pryx-core/internal/bus"
 )
@@ -75,8 +77,14 @@ func (mr *MessageRouter) Stop(ctx context.Context) error {
 	return nil
 }
 
-// Route routes a message to its destination
+// Route routes a message to its destination. Expired messages are dropped
+// with ErrMessageExpired.
 func (mr *MessageRouter) Route(ctx context.Context, msg *UniversalMessage) (bool, error) {
+	if msg.Expired(time.Now()) {
+		publishExpired(mr.bus, msg, "send")
+		return false, fmt.Errorf("message %s: %w", msg.ID, ErrMessageExpired)
+	}
+
 	mr.mu.RLock()
 
 	// Direct routing by agent ID
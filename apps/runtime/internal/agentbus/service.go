@@ -3,6 +3,7 @@ package agentbus
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -84,6 +85,31 @@ type UniversalMessage struct {
 	Context     map[string]string      `json:"context,omitempty"` // For passing request context
 }
 
+// ErrMessageExpired is returned for messages whose ExpiresAt has passed.
+var ErrMessageExpired = errors.New("message expired")
+
+// ErrInstallUnsupported is returned by adapters whose agents are installed
+// and run outside the hub.
+var ErrInstallUnsupported = errors.New("adapter does not install packages")
+
+// Expired reports whether m has an expiry at or before now.
+func (m *UniversalMessage) Expired(now time.Time) bool {
+	return m.ExpiresAt != nil && !m.ExpiresAt.After(now)
+}
+
+// publishExpired emits agentbus.message.expired for a dropped message. stage
+// is where it was dropped: "send" or "receive".
+func publishExpired(b *bus.Bus, msg *UniversalMessage, stage string) {
+	b.Publish(bus.NewEvent("agentbus.message.expired", "", map[string]interface{}{
+		"trace_id":   msg.TraceID,
+		"message_id": msg.ID,
+		"from":       msg.From.ID,
+		"to":         msg.To.ID,
+		"expires_at": msg.ExpiresAt,
+		"stage":      stage,
+	}))
+}
+
 // MessageType constants
 const (
 	MessageTypeRequest  = "request"
@@ -152,6 +178,10 @@ type AgentAdapter interface {
 	Uninstall(ctx context.Context, pkg AgentPackage) error
 }
 
+// PriorityGRPC is the priority of the gRPC adapter. When several adapters can
+// serve a request the one with the highest priority is preferred.
+const PriorityGRPC = 20
+
 // AgentConfig contains connection configuration
 type AgentConfig struct {
 	Timeout              time.Duration     `json:"timeout"`
@@ -202,6 +232,8 @@ type ConnectionMetrics struct {
 	BytesReceived     int64            `json:"bytes_received"`
 	LastActivity      time.Time        `json:"last_activity"`
 	ProtocolStats     map[string]int64 `json:"protocol_stats"`
+	// CircuitBreakers holds the breaker state of each agent, keyed by agent ID
+	CircuitBreakers map[string]CircuitBreakerState `json:"circuit_breakers"`
 }
 
 // HubConfig contains hub configuration
@@ -212,6 +244,8 @@ type HubConfig struct {
 	MetricsEnabled     bool                 `json:"metrics_enabled"`
 	AutoDetectEnabled  bool                 `json:"auto_detect_enabled"`
 	AutoDetectInterval time.Duration        `json:"auto_detect_interval"`
+	DetectTimeout      time.Duration        `json:"detect_timeout"`
+	DetectConcurrency  int                  `json:"detect_concurrency"`
 	PackageDir         string               `json:"package_dir"`
 	CacheDir           string               `json:"cache_dir"`
 	MaxConnections     int                  `json:"max_connections"`
@@ -252,6 +286,9 @@ type Service struct {
 
 // NewService creates a new agent connectivity hub
 func NewService(b *bus.Bus, config HubConfig) *Service {
+	detector := NewDetectionManager(b)
+	detector.SetLimits(config.DetectTimeout, config.DetectConcurrency)
+
 	return &Service{
 		bus:    b,
 		config: config,
@@ -260,7 +297,7 @@ func NewService(b *bus.Bus, config HubConfig) *Service {
 		registry:    NewRegistryManager(b),
 		connections: NewConnectionManager(b),
 		packages:    NewPackageManager(b, config.PackageDir),
-		detector:    NewDetectionManager(b),
+		detector:    detector,
 		router:      NewMessageRouter(b),
 
 		adapters:     make(map[string]AgentAdapter),
@@ -384,7 +421,11 @@ func (s *Service) Stop(ctx context.Context) error {
 
 // registerDefaultAdapters registers built-in protocol adapters
 func (s *Service) registerDefaultAdapters() {
-	// Adapters will be registered by the runtime based on available packages
+	// Other adapters will be registered by the runtime based on available packages
+	s.RegisterAdapter(NewGRPCAdapter("", nil))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	s.logger.Info("registered default adapters", map[string]interface{}{
 		"count": len(s.adapters),
 	})
@@ -404,7 +445,14 @@ func (s *Service) autoDetectLoop(ctx context.Context) {
 		case <-s.stopCh:
 			return
 		case <-ticker.C:
-			agents, err := s.detector.DetectAll(ctx, s.adapters)
+			s.mu.RLock()
+			adapters := make(map[string]AgentAdapter, len(s.adapters))
+			for protocol, adapter := range s.adapters {
+				adapters[protocol] = adapter
+			}
+			s.mu.RUnlock()
+
+			agents, err := s.detector.DetectAll(ctx, adapters)
 			if err != nil {
 				s.logger.Error("auto-detection failed", map[string]interface{}{"error": err.Error()})
 				continue
@@ -441,7 +489,8 @@ func (s *Service) healthCheckLoop(ctx context.Context) {
 	}
 }
 
-// Connect establishes a connection to an agent
+// Connect establishes a connection to an agent. Connection attempts to an agent
+// whose circuit breaker is open fail with ErrCircuitOpen.
 func (s *Service) Connect(ctx context.Context, agentID string, config AgentConfig) (*AgentConnection, error) {
 	s.logger.Info("connecting to agent", map[string]interface{}{"agent_id": agentID})
 
@@ -456,6 +505,11 @@ func (s *Service) Connect(ctx context.Context, agentID string, config AgentConfi
 		return nil, err
 	}
 
+	breaker := s.connections.GetCircuitBreaker(agentID, s.config.CircuitBreaker)
+	if !breaker.AllowRequest() {
+		return nil, fmt.Errorf("agent %s: %w", agentID, ErrCircuitOpen)
+	}
+
 	// Apply default config if not provided
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
@@ -464,15 +518,17 @@ func (s *Service) Connect(ctx context.Context, agentID string, config AgentConfi
 	// Establish connection
 	conn, err := adapter.Connect(ctx, *agent, config)
 	if err != nil {
+		breaker.RecordFailure()
 		s.logger.Error("failed to connect to agent", map[string]interface{}{
 			"agent_id": agentID,
 			"error":    err.Error(),
 		})
 		return nil, fmt.Errorf("connection failed: %w", err)
 	}
+	breaker.RecordSuccess()
 
 	// Register connection
-	s.connections.Add(ctx, &conn)
+	s.connections.AddWithConfig(ctx, &conn, config)
 
 	s.logger.Info("connected to agent", map[string]interface{}{
 		"agent_id": agentID,
@@ -515,7 +571,9 @@ func (s *Service) Disconnect(ctx context.Context, connID string) error {
 	return nil
 }
 
-// SendMessage transmits a message to an agent
+// SendMessage transmits a message to an agent. Expired messages are dropped
+// with ErrMessageExpired, and sends to an agent whose circuit breaker is open
+// fail with ErrCircuitOpen.
 func (s *Service) SendMessage(ctx context.Context, msg *UniversalMessage) error {
 	// Generate trace ID if not provided
 	if msg.TraceID == "" {
@@ -523,8 +581,34 @@ func (s *Service) SendMessage(ctx context.Context, msg *UniversalMessage) error
 	}
 	msg.Timestamp = time.Now().UTC()
 
+	// Drop messages that expired before they could be sent
+	if msg.Expired(msg.Timestamp) {
+		publishExpired(s.bus, msg, "send")
+		s.logger.Warn("dropped expired message", map[string]interface{}{
+			"trace_id": msg.TraceID,
+			"to":       msg.To.ID,
+		})
+		return fmt.Errorf("message %s: %w", msg.ID, ErrMessageExpired)
+	}
+
+	// Fail fast while the destination agent's breaker is open
+	var breaker *CircuitBreaker
+	if msg.To.ID != "" {
+		breaker = s.connections.GetCircuitBreaker(msg.To.ID, s.config.CircuitBreaker)
+		if !breaker.AllowRequest() {
+			return fmt.Errorf("agent %s: %w", msg.To.ID, ErrCircuitOpen)
+		}
+	}
+
 	// Route message
 	routed, err := s.router.Route(ctx, msg)
+	if breaker != nil {
+		if err != nil {
+			breaker.RecordFailure()
+		} else {
+			breaker.RecordSuccess()
+		}
+	}
 	if err != nil {
 		s.logger.Error("failed to route message", map[string]interface{}{
 			"trace_id": msg.TraceID,
@@ -547,20 +631,37 @@ func (s *Service) SendMessage(ctx context.Context, msg *UniversalMessage) error
 	return nil
 }
 
-// ReceiveMessage waits for a message from an agent
+// ReceiveMessage waits for a message from an agent, discarding messages that
+// have already expired.
 func (s *Service) ReceiveMessage(ctx context.Context, connID string) (*UniversalMessage, error) {
 	conn, err := s.connections.Get(ctx, connID)
 	if err != nil {
 		return nil, err
 	}
 
-	msg, err := conn.Adapter.Receive(ctx, conn)
-	if err != nil {
-		s.logger.Error("failed to receive message", map[string]interface{}{
+	var msg *UniversalMessage
+	for {
+		msg, err = conn.Adapter.Receive(ctx, conn)
+		if err != nil {
+			s.logger.Error("failed to receive message", map[string]interface{}{
+				"connection_id": connID,
+				"error":         err.Error(),
+			})
+			if ctx.Err() == nil {
+				// The stream itself failed, not the caller's wait
+				s.connections.MarkDisconnected(connID, err)
+			}
+			return nil, fmt.Errorf("receive failed: %w", err)
+		}
+		if !msg.Expired(time.Now()) {
+			break
+		}
+		// A stale reply must not be delivered; wait for the next message
+		publishExpired(s.bus, msg, "receive")
+		s.logger.Warn("discarded expired message", map[string]interface{}{
+			"trace_id":      msg.TraceID,
 			"connection_id": connID,
-			"error":         err.Error(),
 		})
-		return nil, fmt.Errorf("receive failed: %w", err)
 	}
 
 	s.logger.Debug("message received", map[string]interface{}{
@@ -573,6 +674,17 @@ func (s *Service) ReceiveMessage(ctx context.Context, connID string) (*Universal
 	return msg, nil
 }
 
+// orderedAdapters returns the registered adapters, highest priority first.
+func (s *Service) orderedAdapters() []AgentAdapter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	adapters := make([]AgentAdapter, 0, len(s.adapterOrder))
+	for _, protocol := range s.adapterOrder {
+		adapters = append(adapters, s.adapters[protocol])
+	}
+	return adapters
+}
+
 // InstallPackage installs an agent package
 func (s *Service) InstallPackage(ctx context.Context, pkg AgentPackage) error {
 	s.logger.Info("installing agent package", map[string]interface{}{
@@ -580,26 +692,28 @@ func (s *Service) InstallPackage(ctx context.Context, pkg AgentPackage) error {
 		"version": pkg.Version,
 	})
 
-	// Find appropriate adapter for installation
+	// Use the highest priority adapter that installs packages
 	var installer AgentAdapter
-	for _, adapter := range s.adapters {
-		if installer == nil || adapter.Priority() > installer.Priority() {
-			installer = adapter
+	for _, adapter := range s.orderedAdapters() {
+		err := adapter.Install(ctx, pkg)
+		if errors.Is(err, ErrInstallUnsupported) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error("failed to install package", map[string]interface{}{
+				"name":  pkg.Name,
+				"error": err.Error(),
+			})
+			return fmt.Errorf("installation failed: %w", err)
 		}
+		installer = adapter
+		break
 	}
 
 	if installer == nil {
 		return fmt.Errorf("no installer available")
 	}
 
-	if err := installer.Install(ctx, pkg); err != nil {
-		s.logger.Error("failed to install package", map[string]interface{}{
-			"name":  pkg.Name,
-			"error": err.Error(),
-		})
-		return fmt.Errorf("installation failed: %w", err)
-	}
-
 	// Register discovered agent
 	agents, err := s.detector.DetectProtocol(ctx, installer.Protocol(), s.adapters)
 	if err != nil {
@@ -634,24 +748,26 @@ func (s *Service) UninstallPackage(ctx context.Context, pkg AgentPackage) error
 	})
 
 	var uninstaller AgentAdapter
-	for _, adapter := range s.adapters {
-		if uninstaller == nil || adapter.Priority() > uninstaller.Priority() {
-			uninstaller = adapter
+	for _, adapter := range s.orderedAdapters() {
+		err := adapter.Uninstall(ctx, pkg)
+		if errors.Is(err, ErrInstallUnsupported) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error("failed to uninstall package", map[string]interface{}{
+				"name":  pkg.Name,
+				"error": err.Error(),
+			})
+			return fmt.Errorf("uninstallation failed: %w", err)
 		}
+		uninstaller = adapter
+		break
 	}
 
 	if uninstaller == nil {
 		return fmt.Errorf("no uninstaller available")
 	}
 
-	if err := uninstaller.Uninstall(ctx, pkg); err != nil {
-		s.logger.Error("failed to uninstall package", map[string]interface{}{
-			"name":  pkg.Name,
-			"error": err.Error(),
-		})
-		return fmt.Errorf("uninstallation failed: %w", err)
-	}
-
 	s.logger.Info("uninstalled agent package", map[string]interface{}{
 		"name": pkg.Name,
 	})
@@ -668,6 +784,11 @@ func (s *Service) GetMetrics() ConnectionMetrics {
 	return s.connections.GetMetrics()
 }
 
+// GetDetectionStats returns the detection statistics of each protocol
+func (s *Service) GetDetectionStats() map[string]ProtocolDetectionStats {
+	return s.detector.Stats()
+}
+
 // GetRegistry returns the registry manager
 func (s *Service) GetRegistry() *RegistryManager {
 	return s.registry
@@ -0,0 +1,199 @@
+// Package alerts forwards selected bus events to channels as operational alerts.
+package alerts
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+)
+
+// Severity levels, lowest first.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityError    = "error"
+	SeverityCritical = "critical"
+)
+
+var severityRank = map[string]int{
+	SeverityInfo:     0,
+	SeverityWarning:  1,
+	SeverityError:    2,
+	SeverityCritical: 3,
+}
+
+// defaultSeverity is used for events whose payload carries no severity.
+var defaultSeverity = map[bus.EventType]string{
+	bus.EventErrorOccurred: SeverityError,
+	bus.EventIdleShutdown:  SeverityWarning,
+}
+
+type throttleState struct {
+	lastSent   time.Time
+	suppressed int
+}
+
+// Bridge matches bus events against alert rules and publishes an outbound
+// channel message for each match, so delivery reuses the channel send path.
+type Bridge struct {
+	bus   *bus.Bus
+	rules []config.AlertRule
+	now   func() time.Time
+
+	mu       sync.Mutex
+	throttle map[string]*throttleState
+
+	events <-chan bus.Event
+	cancel func()
+}
+
+// New creates a bridge for rules. It returns nil when there are no rules.
+func New(b *bus.Bus, rules []config.AlertRule) *Bridge {
+	if b == nil || len(rules) == 0 {
+		return nil
+	}
+	return &Bridge{
+		bus:      b,
+		rules:    rules,
+		now:      time.Now,
+		throttle: make(map[string]*throttleState),
+	}
+}
+
+// Start subscribes to the bus and begins forwarding alerts. It is nil-safe.
+func (br *Bridge) Start() {
+	if br == nil || br.cancel != nil {
+		return
+	}
+	br.events, br.cancel = br.bus.Subscribe()
+	go func() {
+		for evt := range br.events {
+			br.handle(evt)
+		}
+	}()
+}
+
+// Stop unsubscribes from the bus. It is nil-safe.
+func (br *Bridge) Stop() {
+	if br == nil || br.cancel == nil {
+		return
+	}
+	br.cancel()
+}
+
+func (br *Bridge) handle(evt bus.Event) {
+	// Never alert on channel traffic, including the alerts themselves.
+	if evt.Event == bus.EventChannelOutboundMessage || evt.Event == bus.EventChannelMessage {
+		return
+	}
+	payload, _ := evt.Payload.(map[string]interface{})
+	kind, _ := payload["kind"].(string)
+	severity := Severity(evt.Event, payload)
+
+	for i, rule := range br.rules {
+		if !matches(rule, evt.Event, kind, severity) {
+			continue
+		}
+		suppressed, ok := br.allow(i, rule, evt.Event, kind)
+		if !ok {
+			continue
+		}
+		br.bus.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, evt.SessionID, map[string]interface{}{
+			"source":     rule.Channel,
+			"channel_id": rule.ChatID,
+			"content":    Format(evt, severity, suppressed),
+		}))
+	}
+}
+
+// allow applies the rule's throttle. It returns the number of alerts suppressed
+// since the last delivery and whether this one should be sent.
+func (br *Bridge) allow(index int, rule config.AlertRule, event bus.EventType, kind string) (int, bool) {
+	if rule.Throttle <= 0 {
+		return 0, true
+	}
+	key := fmt.Sprintf("%d\x00%s\x00%s", index, event, kind)
+	now := br.now()
+
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	st, ok := br.throttle[key]
+	if !ok {
+		br.throttle[key] = &throttleState{lastSent: now}
+		return 0, true
+	}
+	if now.Sub(st.lastSent) < rule.Throttle {
+		st.suppressed++
+		return 0, false
+	}
+	suppressed := st.suppressed
+	st.lastSent = now
+	st.suppressed = 0
+	return suppressed, true
+}
+
+func matches(rule config.AlertRule, event bus.EventType, kind, severity string) bool {
+	if strings.TrimSpace(rule.Channel) == "" {
+		return false
+	}
+	if !containsOrWildcard(rule.Events, string(event)) {
+		return false
+	}
+	if len(rule.Kinds) > 0 && !containsOrWildcard(rule.Kinds, kind) {
+		return false
+	}
+	if min, ok := severityRank[strings.ToLower(rule.MinSeverity)]; ok && severityRank[severity] < min {
+		return false
+	}
+	return true
+}
+
+func containsOrWildcard(list []string, value string) bool {
+	for _, v := range list {
+		if v == "*" || strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Severity returns the payload's severity field if it names a known level,
+// otherwise a default for the event type.
+func Severity(event bus.EventType, payload map[string]interface{}) string {
+	if s, ok := payload["severity"].(string); ok {
+		if _, known := severityRank[strings.ToLower(s)]; known {
+			return strings.ToLower(s)
+		}
+	}
+	if s, ok := defaultSeverity[event]; ok {
+		return s
+	}
+	return SeverityInfo
+}
+
+// Format renders an alert message for evt.
+func Format(evt bus.Event, severity string, suppressed int) string {
+	payload, _ := evt.Payload.(map[string]interface{})
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", strings.ToUpper(severity), evt.Event)
+	if kind, ok := payload["kind"].(string); ok && kind != "" {
+		fmt.Fprintf(&b, " %s", kind)
+	}
+	for _, field := range []string{"message", "error"} {
+		if msg, ok := payload[field].(string); ok && msg != "" {
+			fmt.Fprintf(&b, ": %s", msg)
+			break
+		}
+	}
+	if evt.SessionID != "" {
+		fmt.Fprintf(&b, " (session %s)", evt.SessionID)
+	}
+	if suppressed > 0 {
+		fmt.Fprintf(&b, " [%d similar alerts suppressed]", suppressed)
+	}
+	return b.String()
+}
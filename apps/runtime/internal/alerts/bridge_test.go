@@ -0,0 +1,102 @@
+package alerts
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+)
+
+func TestBridge_ForwardsMatchingEvents(t *testing.T) {
+	b := bus.New()
+	out, cancel := b.Subscribe(bus.EventChannelOutboundMessage)
+	defer cancel()
+
+	br := New(b, []config.AlertRule{{
+		Events:      []string{string(bus.EventErrorOccurred)},
+		Kinds:       []string{"mcp.connect_failed"},
+		MinSeverity: SeverityWarning,
+		Channel:     "ops-slack",
+		ChatID:      "C123",
+	}})
+	br.Start()
+	defer br.Stop()
+
+	b.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{"kind": "mcp.connect_failed"}))
+	b.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{"kind": "skills.load_failed", "error": "x"}))
+	b.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{"kind": "mcp.connect_failed", "error": "dial tcp: refused"}))
+
+	select {
+	case evt := <-out:
+		payload := evt.Payload.(map[string]interface{})
+		if payload["source"] != "ops-slack" || payload["channel_id"] != "C123" {
+			t.Fatalf("unexpected destination: %v", payload)
+		}
+		want := "[ERROR] error.occurred mcp.connect_failed: dial tcp: refused"
+		if payload["content"] != want {
+			t.Fatalf("content = %q, want %q", payload["content"], want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an alert")
+	}
+
+	select {
+	case evt := <-out:
+		t.Fatalf("unexpected extra alert: %v", evt.Payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBridge_SeverityFilter(t *testing.T) {
+	rule := config.AlertRule{Events: []string{"*"}, MinSeverity: SeverityCritical, Channel: "ops"}
+	if matches(rule, bus.EventErrorOccurred, "", Severity(bus.EventErrorOccurred, nil)) {
+		t.Fatal("error severity should not pass a critical threshold")
+	}
+	critical := map[string]interface{}{"severity": "critical"}
+	if !matches(rule, "health.alert", "", Severity("health.alert", critical)) {
+		t.Fatal("payload severity should be honoured")
+	}
+	if matches(config.AlertRule{Events: []string{"*"}}, bus.EventErrorOccurred, "", SeverityError) {
+		t.Fatal("rules without a channel should never match")
+	}
+}
+
+func TestBridge_Throttle(t *testing.T) {
+	now := time.Unix(1000, 0)
+	br := New(bus.New(), []config.AlertRule{{Events: []string{"*"}, Channel: "ops", Throttle: time.Minute}})
+	br.now = func() time.Time { return now }
+	rule := br.rules[0]
+
+	if _, ok := br.allow(0, rule, bus.EventErrorOccurred, "k"); !ok {
+		t.Fatal("first alert should be sent")
+	}
+	for i := 0; i < 3; i++ {
+		if _, ok := br.allow(0, rule, bus.EventErrorOccurred, "k"); ok {
+			t.Fatal("alert within the throttle window should be suppressed")
+		}
+	}
+	if _, ok := br.allow(0, rule, bus.EventErrorOccurred, "other"); !ok {
+		t.Fatal("a different kind is throttled separately")
+	}
+
+	now = now.Add(2 * time.Minute)
+	suppressed, ok := br.allow(0, rule, bus.EventErrorOccurred, "k")
+	if !ok || suppressed != 3 {
+		t.Fatalf("allow() = %d, %v; want 3, true", suppressed, ok)
+	}
+	msg := Format(bus.NewEvent(bus.EventErrorOccurred, "", nil), SeverityError, suppressed)
+	if !strings.Contains(msg, "3 similar alerts suppressed") {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+}
+
+func TestNew_NoRules(t *testing.T) {
+	br := New(bus.New(), nil)
+	if br != nil {
+		t.Fatal("expected nil bridge without rules")
+	}
+	br.Start()
+	br.Stop()
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"time"
+
+	"pryx-core/internal/security"
 )
 
 // ExportService handles audit log export operations
@@ -30,6 +32,12 @@ func (s *ExportService) Export(opts ExportOptions) ([]byte, error) {
 		entries = sanitizeEntries(entries)
 	}
 
+	if opts.Location != nil {
+		for _, entry := range entries {
+			entry.Timestamp = entry.Timestamp.In(opts.Location)
+		}
+	}
+
 	switch opts.Format {
 	case "json":
 		return exportJSON(entries, opts.IncludeCost)
@@ -67,7 +75,7 @@ func sanitizeData(data interface{}) interface{} {
 	case map[string]interface{}:
 		result := make(map[string]interface{})
 		for key, value := range v {
-			if isSensitiveKey(key) {
+			if security.IsSensitiveKey(key) {
 				result[key] = "[REDACTED]"
 			} else {
 				result[key] = sanitizeData(value)
@@ -84,36 +92,6 @@ func sanitizeData(data interface{}) interface{} {
 	}
 }
 
-// isSensitiveKey checks if a key might contain sensitive information
-func isSensitiveKey(key string) bool {
-	sensitiveKeys := []string{
-		"password", "secret", "token", "key", "auth", "credential",
-		"private", "apikey", "api_key", "bearer", "authorization",
-	}
-
-	keyLower := fmt.Sprintf("%s", key)
-	for _, sensitive := range sensitiveKeys {
-		if containsString(keyLower, sensitive) {
-			return true
-		}
-	}
-	return false
-}
-
-// containsString checks if a string contains a substring
-func containsString(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsSubstring(s, substr))
-}
-
-func containsSubstring(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
-
 // exportJSON exports entries as formatted JSON
 func exportJSON(entries []*AuditEntry, includeCost bool) ([]byte, error) {
 	type exportEntry struct {
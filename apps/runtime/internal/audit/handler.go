@@ -3,6 +3,7 @@ package audit
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -218,6 +219,14 @@ func parseExportOptions(r *http.Request) (ExportOptions, error) {
 		IncludeCost:  r.URL.Query().Get("include_cost") == "true",
 	}
 
+	if tz := r.URL.Query().Get("timezone"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return ExportOptions{}, fmt.Errorf("invalid timezone: %s", tz)
+		}
+		opts.Location = loc
+	}
+
 	return opts, nil
 }
 
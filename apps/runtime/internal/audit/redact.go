@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"pryx-core/internal/security"
+)
+
+// ContentRetention controls how much prompt and response content is written
+// to the audit log.
+type ContentRetention string
+
+const (
+	RetainFull      ContentRetention = "full"
+	RetainTruncated ContentRetention = "truncated"
+	RetainNone      ContentRetention = "none"
+)
+
+// DefaultTruncateLength is the number of characters kept per content field
+// in truncated mode.
+const DefaultTruncateLength = 200
+
+// contentKeys name payload fields that hold conversation content.
+var contentKeys = map[string]bool{
+	"content":    true,
+	"prompt":     true,
+	"response":   true,
+	"completion": true,
+	"output":     true,
+	"text":       true,
+	"message":    true,
+	"messages":   true,
+	"input":      true,
+	"query":      true,
+	"body":       true,
+}
+
+// RedactionPolicy is applied to payload, metadata and error text before an
+// entry is written. Values under secret-looking keys and strings matching
+// known credential formats are always replaced by a short hash, so entries
+// can still be correlated; conversation content is kept according to Retention.
+type RedactionPolicy struct {
+	Retention ContentRetention
+	// MaxChars is the length kept in truncated mode (DefaultTruncateLength when zero).
+	MaxChars int
+}
+
+// ParseContentRetention parses a retention level. An empty string means full.
+func ParseContentRetention(s string) (ContentRetention, error) {
+	switch ContentRetention(strings.ToLower(strings.TrimSpace(s))) {
+	case "", RetainFull:
+		return RetainFull, nil
+	case RetainTruncated:
+		return RetainTruncated, nil
+	case RetainNone:
+		return RetainNone, nil
+	default:
+		return RetainNone, fmt.Errorf("unknown audit content retention %q (want full, truncated or none)", s)
+	}
+}
+
+// Apply redacts entry in place. Payload and Metadata are replaced with
+// redacted copies; the caller's values are not modified.
+func (p RedactionPolicy) Apply(entry *AuditEntry) {
+	if entry.Payload != nil {
+		entry.Payload = p.redactValue(entry.Payload)
+	}
+	if entry.Metadata != nil {
+		entry.Metadata = p.redactValue(entry.Metadata)
+	}
+	entry.ErrorMsg = hashSecrets(entry.ErrorMsg)
+}
+
+// Redact returns a redacted copy of v, normalized through JSON like an entry
+// payload.
+func (p RedactionPolicy) Redact(v interface{}) interface{} {
+	return p.redactValue(v)
+}
+
+// redactValue normalizes v through JSON, so structs are handled like maps,
+// and returns the redacted copy.
+func (p RedactionPolicy) redactValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+	return p.redact(generic, false)
+}
+
+func (p RedactionPolicy) redact(v interface{}, inContent bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			lower := strings.ToLower(key)
+			switch {
+			case security.IsSensitiveKey(lower):
+				if s, ok := item.(string); ok && s != "" {
+					out[key] = secretHash(s)
+				} else {
+					out[key] = p.redact(item, inContent)
+				}
+			case contentKeys[lower]:
+				out[key] = p.redact(item, true)
+			default:
+				out[key] = p.redact(item, inContent)
+			}
+		}
+		return out
+	case []interface{}:
+		if inContent && p.Retention == RetainNone {
+			return fmt.Sprintf("[redacted %d items]", len(val))
+		}
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = p.redact(item, inContent)
+		}
+		return out
+	case string:
+		s := hashSecrets(val)
+		if inContent {
+			return p.retain(s)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// retain applies the content retention level to a content string.
+func (p RedactionPolicy) retain(s string) string {
+	switch p.Retention {
+	case RetainNone:
+		if s == "" {
+			return s
+		}
+		return fmt.Sprintf("[redacted %d chars]", len([]rune(s)))
+	case RetainTruncated:
+		limit := p.MaxChars
+		if limit <= 0 {
+			limit = DefaultTruncateLength
+		}
+		runes := []rune(s)
+		if len(runes) <= limit {
+			return s
+		}
+		return fmt.Sprintf("%s…[truncated %d chars]", string(runes[:limit]), len(runes)-limit)
+	default:
+		return s
+	}
+}
+
+// hashSecrets replaces credential-looking substrings of s with their hash.
+func hashSecrets(s string) string {
+	return security.ReplaceCredentials(s, secretHash)
+}
+
+func secretHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "[secret sha256:" + hex.EncodeToString(sum[:])[:12] + "]"
+}
@@ -21,6 +21,7 @@ const (
 	ActionToolExecute     AuditAction = "tool.execute"
 	ActionToolComplete    AuditAction = "tool.complete"
 	ActionToolError       AuditAction = "tool.error"
+	ActionToolDenied      AuditAction = "tool.denied"
 	ActionApprovalRequest AuditAction = "approval.request"
 	ActionApprovalGrant   AuditAction = "approval.grant"
 	ActionApprovalDeny    AuditAction = "approval.deny"
@@ -50,13 +51,15 @@ type AuditEntry struct {
 
 // CostInfo represents cost tracking information
 type CostInfo struct {
-	InputTokens  int64   `json:"input_tokens"`
-	OutputTokens int64   `json:"output_tokens"`
-	TotalTokens  int64   `json:"total_tokens"`
-	InputCost    float64 `json:"input_cost"`
-	OutputCost   float64 `json:"output_cost"`
-	TotalCost    float64 `json:"total_cost"`
-	Model        string  `json:"model,omitempty"`
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+	// ReasoningTokens is the part of OutputTokens spent reasoning.
+	ReasoningTokens int64   `json:"reasoning_tokens,omitempty"`
+	InputCost       float64 `json:"input_cost"`
+	OutputCost      float64 `json:"output_cost"`
+	TotalCost       float64 `json:"total_cost"`
+	Model           string  `json:"model,omitempty"`
 }
 
 // QueryOptions defines filtering options for audit log queries
@@ -79,13 +82,15 @@ type QueryOptions struct {
 type ExportOptions struct {
 	Format       string // "json", "csv", "jsonl"
 	QueryOptions QueryOptions
-	Sanitize     bool // Remove sensitive data
-	IncludeCost  bool // Include cost information
+	Sanitize     bool           // Remove sensitive data
+	IncludeCost  bool           // Include cost information
+	Location     *time.Location // Zone for exported timestamps (as stored when nil)
 }
 
 // AuditRepository handles audit log storage operations
 type AuditRepository struct {
-	db *sql.DB
+	db        *sql.DB
+	redaction RedactionPolicy
 }
 
 // NewAuditRepository creates a new audit repository
@@ -93,6 +98,11 @@ func NewAuditRepository(db *sql.DB) *AuditRepository {
 	return &AuditRepository{db: db}
 }
 
+// SetRedactionPolicy sets the redaction applied to entries before they are written.
+func (r *AuditRepository) SetRedactionPolicy(p RedactionPolicy) {
+	r.redaction = p
+}
+
 // Create inserts a new audit entry into the database
 func (r *AuditRepository) Create(entry *AuditEntry) error {
 	id := uuid.New().String()
@@ -104,6 +114,8 @@ func (r *AuditRepository) Create(entry *AuditEntry) error {
 		entry.Timestamp = time.Now().UTC()
 	}
 
+	r.redaction.Apply(entry)
+
 	// Serialize payload and metadata to JSON
 	payloadJSON, _ := json.Marshal(entry.Payload)
 	metadataJSON, _ := json.Marshal(entry.Metadata)
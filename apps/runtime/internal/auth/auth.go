@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	mathrand "math/rand"
 	"net/http"
 	"time"
 )
@@ -85,11 +86,7 @@ func StartDeviceFlow(apiUrl string, pkce *PKCEParams) (*DeviceCodeResponse, erro
 		}
 	}
 
-	resp, err := http.Post(
-		fmt.Sprintf("%s/auth/device/code", apiUrl),
-		"application/json",
-		bytes.NewBuffer(reqBody),
-	)
+	resp, err := postJSON(context.Background(), "device code request", fmt.Sprintf("%s/auth/device/code", apiUrl), reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to request device code: %w", err)
 	}
@@ -137,7 +134,19 @@ func PollForTokenWithContext(ctx context.Context, apiUrl string, deviceCode stri
 	return PollForTokenWithPKCE(ctx, apiUrl, deviceCode, interval, "")
 }
 
-func requestToken(apiUrl string, deviceCode string, pkceVerifier string) (*TokenResponse, error) {
+// postJSON posts body to url through the shared cloud client.
+func postJSON(ctx context.Context, op string, url string, body []byte) (*http.Response, error) {
+	return sharedCloudClient().Do(ctx, op, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+}
+
+func requestToken(ctx context.Context, apiUrl string, deviceCode string, pkceVerifier string) (*TokenResponse, error) {
 	reqData := map[string]string{
 		"device_code": deviceCode,
 		"grant_type":  "urn:ietf:params:oauth:grant-type:device_code",
@@ -153,11 +162,7 @@ func requestToken(apiUrl string, deviceCode string, pkceVerifier string) (*Token
 		return nil, fmt.Errorf("failed to marshal token request: %w", err)
 	}
 
-	resp, err := http.Post(
-		fmt.Sprintf("%s/auth/device/token", apiUrl),
-		"application/json",
-		bytes.NewBuffer(payload),
-	)
+	resp, err := postJSON(ctx, "token request", fmt.Sprintf("%s/auth/device/token", apiUrl), payload)
 	if err != nil {
 		return nil, fmt.Errorf("token request failed: %w", err)
 	}
@@ -178,37 +183,51 @@ func requestToken(apiUrl string, deviceCode string, pkceVerifier string) (*Token
 	return &res, nil
 }
 
-// PollForTokenWithPKCE polls for token with optional PKCE verifier
+// Device flow polling errors defined by RFC 8628.
+const (
+	errAuthorizationPending = "cloud error: authorization_pending"
+	errSlowDown             = "cloud error: slow_down"
+)
+
+// slowDownStep is added to the polling interval each time the server asks us to slow down.
+const slowDownStep = 5 * time.Second
+
+// PollForTokenWithPKCE polls for token with optional PKCE verifier.
+// The interval grows when the server answers slow_down, and each wait is jittered
+// so many clients do not poll in lockstep.
 func PollForTokenWithPKCE(ctx context.Context, apiUrl string, deviceCode string, interval int, pkceVerifier string) (*TokenResponse, error) {
 	if interval <= 0 {
 		interval = 5
 	}
-
-	token, err := requestToken(apiUrl, deviceCode, pkceVerifier)
-	if err == nil {
-		return token, nil
-	}
-	if err.Error() != "cloud error: authorization_pending" {
-		return nil, err
-	}
-
-	ticker := time.NewTicker(time.Duration(interval) * time.Second)
-	defer ticker.Stop()
+	wait := time.Duration(interval) * time.Second
 
 	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-			token, err := requestToken(apiUrl, deviceCode, pkceVerifier)
-			if err != nil {
-				// Continue polling if it's "authorization_pending"
-				if err.Error() == "cloud error: authorization_pending" {
-					continue
-				}
-				return nil, err
-			}
+		token, err := requestToken(ctx, apiUrl, deviceCode, pkceVerifier)
+		if err == nil {
 			return token, nil
 		}
+		switch err.Error() {
+		case errAuthorizationPending:
+		case errSlowDown:
+			wait += slowDownStep
+		default:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		if err := sleepContext(ctx, pollJitter(wait)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// pollJitter spreads d by up to 10% in either direction.
+func pollJitter(d time.Duration) time.Duration {
+	spread := int64(d / 10)
+	if spread <= 0 {
+		return d
 	}
+	return d - time.Duration(spread) + time.Duration(mathrand.Int63n(2*spread+1))
 }
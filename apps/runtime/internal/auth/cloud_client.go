@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Default retry settings for cloud API calls.
+const (
+	DefaultCloudMaxAttempts = 4
+	DefaultCloudBaseDelay   = 500 * time.Millisecond
+	DefaultCloudMaxDelay    = 30 * time.Second
+	DefaultCloudRetryBudget = 2 * time.Minute
+)
+
+// ErrRetryBudgetExhausted is matched by errors.Is when a cloud call gives up
+// after running out of attempts or retry time.
+var ErrRetryBudgetExhausted = errors.New("cloud retry budget exhausted")
+
+// RetryExhaustedError reports a cloud operation that failed on every attempt.
+type RetryExhaustedError struct {
+	Op       string
+	Attempts int
+	// StatusCode is the last HTTP status received, or 0 if the last attempt failed to connect.
+	StatusCode int
+	LastErr    error
+}
+
+func (e *RetryExhaustedError) Error() string {
+	if e.LastErr != nil {
+		return fmt.Sprintf("%s: giving up after %d attempts: %v", e.Op, e.Attempts, e.LastErr)
+	}
+	return fmt.Sprintf("%s: giving up after %d attempts: status %d", e.Op, e.Attempts, e.StatusCode)
+}
+
+func (e *RetryExhaustedError) Is(target error) bool {
+	return target == ErrRetryBudgetExhausted
+}
+
+func (e *RetryExhaustedError) Unwrap() error {
+	return e.LastErr
+}
+
+// RetryPolicy bounds retries of a single cloud operation. Zero values fall back to the defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles on each retry up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Budget caps the total time spent waiting between attempts.
+	Budget time.Duration
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultCloudMaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultCloudBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultCloudMaxDelay
+	}
+	if p.Budget <= 0 {
+		p.Budget = DefaultCloudRetryBudget
+	}
+	return p
+}
+
+// backoff returns the jittered delay before retry number n (starting at 1).
+func (p RetryPolicy) backoff(n int, rnd func(int64) int64) time.Duration {
+	d := p.BaseDelay << uint(n-1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	// Equal jitter: half fixed, half random, so retries never collapse to zero.
+	half := int64(d / 2)
+	return time.Duration(half + rnd(half+1))
+}
+
+// CloudClient sends cloud API requests with bounded retries, exponential backoff
+// with jitter and Retry-After support. Connection errors (except unknown hosts),
+// 429 and 5xx responses are retried; other responses are returned unchanged.
+type CloudClient struct {
+	http   *http.Client
+	policy RetryPolicy
+	rnd    func(int64) int64
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewCloudClient creates a client with the given policy.
+func NewCloudClient(httpClient *http.Client, policy RetryPolicy) *CloudClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &CloudClient{
+		http:   httpClient,
+		policy: policy.withDefaults(),
+		rnd:    rand.Int63n,
+		sleep:  sleepContext,
+	}
+}
+
+var (
+	cloudClientMu sync.RWMutex
+	cloudClient   = NewCloudClient(nil, RetryPolicy{})
+)
+
+// ConfigureCloudRetry replaces the retry policy used for cloud API calls.
+func ConfigureCloudRetry(policy RetryPolicy) {
+	client := NewCloudClient(nil, policy)
+	cloudClientMu.Lock()
+	cloudClient = client
+	cloudClientMu.Unlock()
+}
+
+func sharedCloudClient() *CloudClient {
+	cloudClientMu.RLock()
+	defer cloudClientMu.RUnlock()
+	return cloudClient
+}
+
+// Do sends the request built by newRequest, rebuilding it for each attempt.
+// op names the operation in errors. When every attempt fails, the returned
+// error is a *RetryExhaustedError.
+func (c *CloudClient) Do(ctx context.Context, op string, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.http.Do(req)
+		if err == nil && !retriableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if err != nil && !retriableError(err) {
+			return nil, err
+		}
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+
+		exhausted := &RetryExhaustedError{Op: op, Attempts: attempt, LastErr: err}
+		delay := c.policy.backoff(attempt, c.rnd)
+		if resp != nil {
+			exhausted.StatusCode = resp.StatusCode
+			if ra, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = ra
+			}
+			resp.Body.Close()
+		}
+
+		if attempt >= c.policy.MaxAttempts || waited+delay > c.policy.Budget {
+			return nil, exhausted
+		}
+		if err := c.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		waited += delay
+	}
+}
+
+// retriableError reports whether a transport error may succeed on retry.
+// Unknown hosts are treated as configuration errors.
+func retriableError(err error) bool {
+	var dnsErr *net.DNSError
+	return !(errors.As(err, &dnsErr) && dnsErr.IsNotFound)
+}
+
+func retriableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// parseRetryAfter accepts either delay-seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
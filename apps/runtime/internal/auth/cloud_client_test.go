@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestCloudClient(policy RetryPolicy, slept *[]time.Duration) *CloudClient {
+	c := NewCloudClient(nil, policy)
+	c.rnd = func(n int64) int64 { return n - 1 }
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		*slept = append(*slept, d)
+		return nil
+	}
+	return c
+}
+
+func getRequest(url string) func(ctx context.Context) (*http.Request, error) {
+	return func(ctx context.Context) (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	}
+}
+
+func TestCloudClient_RetriesTransientFailures(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+	c := newTestCloudClient(RetryPolicy{BaseDelay: time.Second}, &slept)
+	resp, err := c.Do(context.Background(), "test", getRequest(srv.URL))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+	if len(slept) != 2 || slept[0] != time.Second || slept[1] != 7*time.Second {
+		t.Fatalf("unexpected backoff %v; want [1s 7s] (jittered base, then Retry-After)", slept)
+	}
+}
+
+func TestCloudClient_ExhaustsBudget(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+	c := newTestCloudClient(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second}, &slept)
+	_, err := c.Do(context.Background(), "device code request", getRequest(srv.URL))
+
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) || !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("expected RetryExhaustedError, got %v", err)
+	}
+	if exhausted.Attempts != 3 || exhausted.StatusCode != http.StatusBadGateway || calls != 3 {
+		t.Fatalf("unexpected exhaustion: %+v after %d calls", exhausted, calls)
+	}
+
+	// A Retry-After beyond the budget stops immediately.
+	calls = 0
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "600")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	_, err = c.Do(context.Background(), "token request", getRequest(srv.URL))
+	if !errors.Is(err, ErrRetryBudgetExhausted) || calls != 1 {
+		t.Fatalf("expected exhaustion after one call, got %v after %d calls", err, calls)
+	}
+}
+
+func TestCloudClient_DoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	var slept []time.Duration
+	c := newTestCloudClient(RetryPolicy{}, &slept)
+	resp, err := c.Do(context.Background(), "test", getRequest(srv.URL))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || calls != 1 || len(slept) != 0 {
+		t.Fatalf("expected a single 400 response, got %d after %d calls", resp.StatusCode, calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d, ok := parseRetryAfter("3", now); !ok || d != 3*time.Second {
+		t.Fatalf("seconds: got %v, %v", d, ok)
+	}
+	date := now.Add(time.Minute).Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date, now); !ok || d != time.Minute {
+		t.Fatalf("http date: got %v, %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Fatal("expected invalid value to be ignored")
+	}
+}
+
+func TestPollForTokenWithPKCE_SlowDown(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"slow_down"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"tok","token_type":"bearer"}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	token, err := PollForTokenWithPKCE(ctx, srv.URL, "dev", 1, "verifier")
+	if err != nil {
+		t.Fatalf("PollForTokenWithPKCE() error = %v", err)
+	}
+	if token.AccessToken != "tok" {
+		t.Fatalf("unexpected token %+v", token)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Second {
+		t.Fatalf("slow_down should lengthen the interval, polled again after %v", elapsed)
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth/token/info" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Header.Get("Authorization") {
+		case "Bearer good":
+			_, _ = w.Write([]byte(`{"active":true,"expires_at":1900000000,"scope":"telemetry.write"}`))
+		case "Bearer inactive":
+			_, _ = w.Write([]byte(`{"active":false}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	info, err := CheckToken(context.Background(), srv.URL, "good")
+	if err != nil {
+		t.Fatalf("CheckToken() error = %v", err)
+	}
+	if !info.ExpiresAt.Equal(time.Unix(1900000000, 0)) || info.Scope != "telemetry.write" {
+		t.Fatalf("unexpected token info %+v", info)
+	}
+
+	for _, token := range []string{"inactive", "revoked"} {
+		if _, err := CheckToken(context.Background(), srv.URL, token); !errors.Is(err, ErrTokenInvalid) {
+			t.Fatalf("CheckToken(%q) error = %v, want ErrTokenInvalid", token, err)
+		}
+	}
+	if _, err := CheckToken(context.Background(), srv.URL+"/old", "good"); !errors.Is(err, ErrTokenCheckUnsupported) {
+		t.Fatalf("CheckToken() error = %v, want ErrTokenCheckUnsupported", err)
+	}
+}
@@ -268,15 +268,15 @@ func (p *ProviderOAuth) RefreshToken(ctx context.Context, providerID string) err
 		"refresh_token": {refreshToken},
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", config.TokenURL, nil)
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.URL.RawQuery = params.Encode()
-
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := sharedCloudClient().Do(ctx, "token refresh", func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", config.TokenURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.URL.RawQuery = params.Encode()
+		return req, nil
+	})
 	if err != nil {
 		return fmt.Errorf("refresh failed: %w", err)
 	}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Keychain entries holding the cloud login.
+const (
+	CloudTokenKey       = "cloud_access_token"
+	CloudTokenExpiryKey = "cloud_access_token_expires_at"
+)
+
+// ErrTokenInvalid is returned by CheckToken when the cloud rejects the token
+// as expired, revoked or unknown.
+var ErrTokenInvalid = errors.New("cloud token is invalid or expired")
+
+// ErrTokenCheckUnsupported is returned by CheckToken when the cloud has no
+// token info endpoint.
+var ErrTokenCheckUnsupported = errors.New("cloud does not support token checks")
+
+// TokenInfo describes a token the cloud accepted.
+type TokenInfo struct {
+	// ExpiresAt is zero if the cloud did not report an expiry.
+	ExpiresAt time.Time
+	Scope     string
+}
+
+// tokenInfoResponse is the body of GET /auth/token/info. ExpiresAt is in Unix
+// seconds.
+type tokenInfoResponse struct {
+	Active    bool   `json:"active"`
+	ExpiresAt int64  `json:"expires_at,omitempty"`
+	Scope     string `json:"scope,omitempty"`
+}
+
+// CheckToken asks the cloud whether token is still accepted. Transient
+// failures are retried under the cloud retry policy; other failures are
+// returned as errors, leaving the token's validity unknown.
+func CheckToken(ctx context.Context, apiUrl string, token string) (*TokenInfo, error) {
+	resp, err := sharedCloudClient().Do(ctx, "token check", func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/auth/token/info", apiUrl), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("token check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrTokenInvalid
+	case http.StatusNotFound, http.StatusMethodNotAllowed:
+		return nil, ErrTokenCheckUnsupported
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var res tokenInfoResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("failed to decode token info: %w", err)
+	}
+	if !res.Active {
+		return nil, ErrTokenInvalid
+	}
+
+	info := &TokenInfo{Scope: res.Scope}
+	if res.ExpiresAt > 0 {
+		info.ExpiresAt = time.Unix(res.ExpiresAt, 0)
+	}
+	return info, nil
+}
+
+// SaveCloudToken stores a cloud login in kc, with its expiry when the cloud
+// reported one so that an expired token can be recognized offline.
+func SaveCloudToken(kc Keychain, token *TokenResponse) error {
+	if err := kc.Set(CloudTokenKey, token.AccessToken); err != nil {
+		return err
+	}
+	if token.ExpiresIn <= 0 {
+		_ = kc.Delete(CloudTokenExpiryKey)
+		return nil
+	}
+	expiresAt := time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return kc.Set(CloudTokenExpiryKey, expiresAt.UTC().Format(time.RFC3339))
+}
+
+// CloudTokenExpiry returns the expiry stored with the cloud login, if any.
+func CloudTokenExpiry(kc Keychain) (time.Time, bool) {
+	v, err := kc.Get(CloudTokenExpiryKey)
+	if err != nil || v == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
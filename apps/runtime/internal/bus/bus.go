@@ -20,13 +20,58 @@ type Subscription struct {
 	closer func()
 }
 
+// Delivery selects how a handler subscription runs its handler.
+type Delivery int
+
+const (
+	// DeliveryOrdered runs the handler on a single goroutine, one event at a
+	// time, in publish order. It is the default.
+	DeliveryOrdered Delivery = iota
+	// DeliveryConcurrent runs the handler on up to Concurrency goroutines at
+	// once. Events may be handled out of order; use it only where order does
+	// not matter.
+	DeliveryConcurrent
+)
+
+// Subscription defaults.
+const (
+	// DefaultBuffer is how many events a subscriber can fall behind before
+	// further events are dropped.
+	DefaultBuffer = 100
+	// DefaultConcurrency bounds the handlers run at once by DeliveryConcurrent.
+	DefaultConcurrency = 8
+)
+
+// SubscribeOptions configures a subscription. Zero values select the defaults.
+type SubscribeOptions struct {
+	// Topics lists the event types to receive; empty receives all events.
+	Topics []EventType
+	// Delivery applies to Handle subscriptions.
+	Delivery Delivery
+	// Concurrency bounds the handlers run at once with DeliveryConcurrent.
+	Concurrency int
+	// Buffer is the number of events queued for the subscriber.
+	Buffer int
+}
+
 // Bus is the central event bus for pub/sub communication.
 // It manages subscriptions and routes events to interested subscribers.
 // The bus is safe for concurrent use.
+//
+// Delivery guarantees: every subscriber receives the events it matches in
+// the order they were published, with increasing versions, including events
+// published concurrently from different goroutines. Publish never blocks; a
+// subscriber that falls more than its buffer behind loses the events that do
+// not fit, which Dropped counts. Channel subscribers read events in order as
+// long as they read from one goroutine; Handle subscriptions run their
+// handler in order unless DeliveryConcurrent is asked for.
 type Bus struct {
 	mu   sync.RWMutex
 	subs map[string]*Subscription
-	ver  int64
+	// pubMu serializes publishing so versions and per-subscriber order agree.
+	pubMu   sync.Mutex
+	ver     int64
+	dropped atomic.Int64
 }
 
 // New creates a new event Bus with no subscribers.
@@ -40,11 +85,23 @@ func New() *Bus {
 // Subscribe subscribes to events. If topics is empty, it subscribes to all events.
 // Returns a channel that receives events. The bus owns the channel; use the closer to unsubscribe.
 func (b *Bus) Subscribe(topics ...EventType) (<-chan Event, func()) {
+	return b.SubscribeWithOptions(SubscribeOptions{Topics: topics})
+}
+
+// SubscribeWithOptions is Subscribe with a configurable buffer. Delivery and
+// Concurrency are ignored: the caller decides how it reads the channel.
+func (b *Bus) SubscribeWithOptions(opts SubscribeOptions) (<-chan Event, func()) {
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	topics := opts.Topics
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	id := uuid.New().String()
-	ch := make(chan Event, 100) // Buffer events
+	ch := make(chan Event, buffer)
 
 	sub := &Subscription{
 		id:     id,
@@ -59,11 +116,46 @@ func (b *Bus) Subscribe(topics ...EventType) (<-chan Event, func()) {
 	return ch, sub.closer
 }
 
+// Handle subscribes handler to the events matching opts.Topics and runs it as
+// opts.Delivery asks, in publish order by default. The returned func
+// unsubscribes; events already queued are still handled.
+func (b *Bus) Handle(handler Handler, opts SubscribeOptions) func() {
+	events, cancel := b.SubscribeWithOptions(opts)
+
+	if opts.Delivery != DeliveryConcurrent {
+		go func() {
+			for evt := range events {
+				handler(evt)
+			}
+		}()
+		return cancel
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	go func() {
+		sem := make(chan struct{}, concurrency)
+		for evt := range events {
+			sem <- struct{}{}
+			go func(evt Event) {
+				defer func() { <-sem }()
+				handler(evt)
+			}(evt)
+		}
+	}()
+	return cancel
+}
+
 // Publish publishes an event to all matching subscribers.
 // The event is assigned a monotonically increasing version number.
 // Events are dropped if a subscriber's channel is full (non-blocking).
 func (b *Bus) Publish(event Event) {
-	event.Version = int(atomic.AddInt64(&b.ver, 1))
+	b.pubMu.Lock()
+	defer b.pubMu.Unlock()
+	b.ver++
+	event.Version = int(b.ver)
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
@@ -74,12 +166,18 @@ func (b *Bus) Publish(event Event) {
 			case sub.ch <- event:
 			default:
 				// Drop event if subscriber is too slow to prevent blocking
-				// In a real system we might want metrics here
+				b.dropped.Add(1)
 			}
 		}
 	}
 }
 
+// Dropped returns how many events were dropped because a subscriber had
+// fallen too far behind.
+func (b *Bus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
 // Unsubscribe removes a subscription by its ID.
 // This closes the subscription's channel and removes it from the bus.
 // Safe to call multiple times for the same ID.
@@ -1,7 +1,11 @@
 package bus
 
 import (
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestBus_AssignsMonotonicVersions(t *testing.T) {
@@ -23,3 +27,96 @@ func TestBus_AssignsMonotonicVersions(t *testing.T) {
 		t.Fatalf("expected second version %d, got %d", first.Version+1, second.Version)
 	}
 }
+
+func TestBus_OrderedDeliveryUnderLoad(t *testing.T) {
+	b := New()
+
+	const sessions, deltas = 8, 500
+	var mu sync.Mutex
+	got := make(map[string][]int)
+	lastVersion := 0
+	var outOfOrder atomic.Bool
+	var wg sync.WaitGroup
+	wg.Add(sessions * deltas)
+
+	cancel := b.Handle(func(evt Event) {
+		defer wg.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		if evt.Version <= lastVersion {
+			outOfOrder.Store(true)
+		}
+		lastVersion = evt.Version
+		got[evt.SessionID] = append(got[evt.SessionID], evt.Payload.(map[string]interface{})["seq"].(int))
+	}, SubscribeOptions{Topics: []EventType{EventSessionMessage}, Buffer: sessions * deltas})
+	defer cancel()
+
+	for i := 0; i < sessions; i++ {
+		go func(session string) {
+			for seq := 0; seq < deltas; seq++ {
+				b.Publish(NewEvent(EventSessionMessage, session, map[string]interface{}{"seq": seq}))
+			}
+		}(fmt.Sprintf("s%d", i))
+	}
+	wg.Wait()
+
+	if outOfOrder.Load() {
+		t.Fatal("handler saw versions out of order")
+	}
+	if b.Dropped() != 0 {
+		t.Fatalf("expected no dropped events, got %d", b.Dropped())
+	}
+	for session, seqs := range got {
+		for i, seq := range seqs {
+			if seq != i {
+				t.Fatalf("session %s: delta %d arrived at position %d", session, seq, i)
+			}
+		}
+	}
+}
+
+func TestBus_ConcurrentDelivery(t *testing.T) {
+	b := New()
+
+	const concurrency = 4
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(concurrency * 3)
+
+	cancel := b.Handle(func(evt Event) {
+		defer wg.Done()
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+	}, SubscribeOptions{Delivery: DeliveryConcurrent, Concurrency: concurrency})
+	defer cancel()
+
+	for i := 0; i < concurrency*3; i++ {
+		b.Publish(NewEvent(EventTraceEvent, "s1", nil))
+	}
+	wg.Wait()
+
+	if p := peak.Load(); p < 2 || p > concurrency {
+		t.Fatalf("expected between 2 and %d handlers at once, got %d", concurrency, p)
+	}
+}
+
+func TestBus_DroppedWhenSubscriberFallsBehind(t *testing.T) {
+	b := New()
+
+	_, cancel := b.SubscribeWithOptions(SubscribeOptions{Buffer: 2})
+	defer cancel()
+
+	for i := 0; i < 5; i++ {
+		b.Publish(NewEvent(EventTraceEvent, "s1", nil))
+	}
+	if b.Dropped() != 3 {
+		t.Fatalf("expected 3 dropped events, got %d", b.Dropped())
+	}
+}
@@ -0,0 +1,97 @@
+package bus
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultErrorWindow is the default interval over which identical error events are collapsed.
+const DefaultErrorWindow = 30 * time.Second
+
+// errorKeyFields are the payload fields that identify an error for deduplication.
+var errorKeyFields = []string{"kind", "channel_id", "tool", "path", "error"}
+
+type errorEntry struct {
+	payload    map[string]interface{}
+	suppressed int
+}
+
+// ErrorEmitter publishes EventErrorOccurred events, collapsing identical errors.
+// The first occurrence is published immediately; repeats within the window are
+// counted and published once as a single event with "count" and "repeated" set
+// when the window closes. A nil ErrorEmitter or one without a bus drops events.
+type ErrorEmitter struct {
+	bus    *Bus
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*errorEntry
+}
+
+// NewErrorEmitter creates an emitter that publishes to b.
+// A non-positive window falls back to DefaultErrorWindow.
+func NewErrorEmitter(b *Bus, window time.Duration) *ErrorEmitter {
+	if window <= 0 {
+		window = DefaultErrorWindow
+	}
+	return &ErrorEmitter{
+		bus:     b,
+		window:  window,
+		entries: make(map[string]*errorEntry),
+	}
+}
+
+// Emit publishes an error event for sessionID unless an identical one was published within the window.
+func (e *ErrorEmitter) Emit(sessionID string, payload map[string]interface{}) {
+	if e == nil || e.bus == nil {
+		return
+	}
+	key := errorKey(sessionID, payload)
+
+	e.mu.Lock()
+	if entry, ok := e.entries[key]; ok {
+		entry.suppressed++
+		entry.payload = payload
+		e.mu.Unlock()
+		return
+	}
+	e.entries[key] = &errorEntry{payload: payload}
+	e.mu.Unlock()
+
+	e.bus.Publish(NewEvent(EventErrorOccurred, sessionID, withCount(payload, 1, false)))
+	time.AfterFunc(e.window, func() { e.flush(sessionID, key) })
+}
+
+// flush closes the window for key and publishes a collapsed event if repeats were suppressed.
+func (e *ErrorEmitter) flush(sessionID, key string) {
+	e.mu.Lock()
+	entry, ok := e.entries[key]
+	delete(e.entries, key)
+	e.mu.Unlock()
+
+	if !ok || entry.suppressed == 0 {
+		return
+	}
+	e.bus.Publish(NewEvent(EventErrorOccurred, sessionID, withCount(entry.payload, entry.suppressed, true)))
+}
+
+func errorKey(sessionID string, payload map[string]interface{}) string {
+	key := sessionID
+	for _, field := range errorKeyFields {
+		key += "\x00" + fmt.Sprint(payload[field])
+	}
+	return key
+}
+
+func withCount(payload map[string]interface{}, count int, repeated bool) map[string]interface{} {
+	out := make(map[string]interface{}, len(payload)+2)
+	for k, v := range payload {
+		out[k] = v
+	}
+	out["count"] = count
+	if repeated {
+		out["repeated"] = true
+	}
+	return out
+}
@@ -0,0 +1,49 @@
+package bus
+
+import (
+	"testing"
+	"time"
+)
+
+func TestErrorEmitter_CollapsesRepeats(t *testing.T) {
+	b := New()
+	ch, cancel := b.Subscribe(EventErrorOccurred)
+	defer cancel()
+
+	e := NewErrorEmitter(b, 50*time.Millisecond)
+	for i := 0; i < 5; i++ {
+		e.Emit("", map[string]interface{}{"kind": "mcp.connect_failed", "error": "refused"})
+	}
+	e.Emit("", map[string]interface{}{"kind": "mcp.connect_failed", "error": "other"})
+
+	first := (<-ch).Payload.(map[string]interface{})
+	if first["count"] != 1 || first["error"] != "refused" {
+		t.Fatalf("unexpected first event: %v", first)
+	}
+	other := (<-ch).Payload.(map[string]interface{})
+	if other["error"] != "other" {
+		t.Fatalf("expected distinct error to be published, got %v", other)
+	}
+
+	select {
+	case evt := <-ch:
+		payload := evt.Payload.(map[string]interface{})
+		if payload["count"] != 4 || payload["repeated"] != true {
+			t.Fatalf("unexpected collapsed event: %v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected collapsed event after window")
+	}
+
+	select {
+	case evt := <-ch:
+		t.Fatalf("unexpected extra event: %v", evt.Payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestErrorEmitter_NilSafe(t *testing.T) {
+	var e *ErrorEmitter
+	e.Emit("", map[string]interface{}{"error": "x"})
+	NewErrorEmitter(nil, 0).Emit("", map[string]interface{}{"error": "x"})
+}
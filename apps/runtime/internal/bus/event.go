@@ -14,14 +14,24 @@ const (
 	EventEnvelope EventType = "event"
 	// EventSessionMessage is emitted when a new message is added to a session.
 	EventSessionMessage EventType = "session.message"
+	// EventSessionCreated is emitted when the runtime creates a session on a client's behalf.
+	EventSessionCreated EventType = "session.created"
+	// EventSessionDeleted is emitted when a session and its messages are deleted.
+	EventSessionDeleted EventType = "session.deleted"
 	// EventSessionTyping is emitted when typing indicators change.
 	EventSessionTyping EventType = "session.typing"
+	// EventSessionBudgetExceeded is emitted when a generation is refused because the
+	// session has used up its token or cost budget.
+	EventSessionBudgetExceeded EventType = "session.budget_exceeded"
 	// EventToolRequest is emitted when a tool execution is requested.
 	EventToolRequest EventType = "tool.request"
 	// EventToolExecuting is emitted when a tool starts executing.
 	EventToolExecuting EventType = "tool.executing"
 	// EventToolComplete is emitted when a tool finishes executing.
 	EventToolComplete EventType = "tool.complete"
+	// EventToolDenied is emitted when a tool call is refused because the tool
+	// is outside the session's or channel's tool scope.
+	EventToolDenied EventType = "tool.denied"
 	// EventApprovalNeeded is emitted when user approval is required.
 	EventApprovalNeeded EventType = "approval.needed"
 	// EventApprovalResolved is emitted when an approval is resolved.
@@ -34,10 +44,57 @@ const (
 	EventChannelStatus EventType = "channel.status"
 	// EventChannelMessage is emitted when a message is received from a channel.
 	EventChannelMessage EventType = "channel.message"
+	// EventChannelMessageFiltered is emitted when an inbound channel message is
+	// dropped by the channel's inbound filter.
+	EventChannelMessageFiltered EventType = "channel.message_filtered"
 	// EventChannelOutboundMessage is emitted when a message is sent to a channel.
 	EventChannelOutboundMessage EventType = "channel.outbound_message"
+	// EventMemoryUpdated is emitted when a memory entry's content is edited.
+	EventMemoryUpdated EventType = "memory.updated"
+	// EventMemoryDeleted is emitted when a memory entry is deleted.
+	EventMemoryDeleted EventType = "memory.deleted"
 	// EventChatRequest is emitted when a chat request is made.
 	EventChatRequest EventType = "chat.request"
+	// EventChatInject carries a message to add to the chat turn in progress
+	// in a session, rather than answering it after the turn.
+	EventChatInject EventType = "chat.inject"
+	// EventAgentInjected is emitted when an injected message is queued for, or
+	// added to, an in-progress chat turn.
+	EventAgentInjected EventType = "agent.injected"
+	// EventMaintenanceChanged is emitted when maintenance mode is toggled.
+	EventMaintenanceChanged EventType = "runtime.maintenance"
+	// EventLLMCacheHit is emitted when an LLM response is served from the response cache.
+	EventLLMCacheHit EventType = "llm.cache_hit"
+	// EventLLMCloudProxied is emitted for each generation routed through the Pryx Cloud proxy.
+	EventLLMCloudProxied EventType = "llm.cloud_proxied"
+	// EventLLMFallback is emitted when a generation was handed to a fallback model
+	// because the requested one failed.
+	EventLLMFallback EventType = "llm.fallback"
+	// EventLLMUsage is emitted with the token usage of a generation whose
+	// provider reported it, including the tokens spent reasoning.
+	EventLLMUsage EventType = "llm.usage"
+	// EventContentFiltered is emitted when the content filter redacts or blocks content.
+	EventContentFiltered EventType = "content.filtered"
+	// EventIdleShutdown is emitted before and when the runtime shuts down after the idle timeout.
+	EventIdleShutdown EventType = "runtime.idle_shutdown"
+	// EventRuntimeWarmed is emitted when the post-startup warm-up has loaded the tool cache and catalog.
+	EventRuntimeWarmed EventType = "runtime.warmed"
+	// EventSessionTurnLimitReached is emitted when the agent stops answering a
+	// conversation that has used up its turn limit.
+	EventSessionTurnLimitReached EventType = "session.turn_limit_reached"
+	// EventAgentBusy is emitted when a generation has to wait for, or is refused, a slot
+	// under the global concurrency limit.
+	EventAgentBusy EventType = "agent.busy"
+	// EventAgentCancelled is emitted when a spawned sub-agent is cancelled.
+	EventAgentCancelled EventType = "agent.cancelled"
+	// EventSkillExecuted is emitted after a skill runs, with its duration and outcome.
+	EventSkillExecuted EventType = "skill.executed"
+	// EventSchedulerTaskFailed is emitted when a scheduled task has failed and used up its retries.
+	EventSchedulerTaskFailed EventType = "scheduler.task.failed"
+	// EventMCPCallTimeout is emitted when an MCP server does not finish a tool call in time.
+	EventMCPCallTimeout EventType = "mcp.call_timeout"
+	// EventSchedulerTaskSucceeded is emitted when a run of a scheduled task succeeds.
+	EventSchedulerTaskSucceeded EventType = "scheduler.task.succeeded"
 )
 
 // Event represents a single event in the system.
@@ -0,0 +1,148 @@
+package channels
+
+import (
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"pryx-core/internal/bus"
+)
+
+// Channel activity event types.
+const (
+	ActivityInbound  = "message.inbound"  // a message received from the platform
+	ActivityOutbound = "message.outbound" // a message delivered to the platform
+	ActivityDropped  = "message.dropped"  // an outbound message given up on
+)
+
+// Activity log limits. Entries older than DefaultActivityRetention and all but
+// the newest DefaultActivityMaxRows entries of each channel are pruned every
+// activityPruneInterval.
+const (
+	DefaultActivityRetention = 30 * 24 * time.Hour
+	DefaultActivityMaxRows   = 1000
+	activityPruneInterval    = time.Hour
+	// activitySnippetLen bounds the message content kept per entry, in runes.
+	activitySnippetLen = 200
+)
+
+// Activity is one entry of a channel's activity log.
+type Activity struct {
+	ID             int64     `json:"id"`
+	ChannelID      string    `json:"channel_id"`
+	EventType      string    `json:"event_type"`
+	ConversationID string    `json:"conversation_id,omitempty"`
+	SenderID       string    `json:"sender_id,omitempty"`
+	Sender         string    `json:"sender,omitempty"`
+	Snippet        string    `json:"snippet"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ActivityLog persists channel activity. PruneChannelActivity removes entries
+// created before cutoff and all but the newest maxPerChannel entries of each
+// channel, returning how many were removed.
+type ActivityLog interface {
+	RecordChannelActivity(a Activity) error
+	PruneChannelActivity(cutoff time.Time, maxPerChannel int) (int64, error)
+}
+
+// SetActivityLog makes the manager record the messages its channels receive
+// and deliver to activity, keeping entries for at most retention and at most
+// maxRows per channel. Zero limits select DefaultActivityRetention and
+// DefaultActivityMaxRows.
+func (m *ChannelManager) SetActivityLog(activity ActivityLog, retention time.Duration, maxRows int) {
+	if retention <= 0 {
+		retention = DefaultActivityRetention
+	}
+	if maxRows <= 0 {
+		maxRows = DefaultActivityMaxRows
+	}
+
+	m.mu.Lock()
+	m.activity = activity
+	m.mu.Unlock()
+
+	if m.eventBus != nil {
+		inbound, unsub := m.eventBus.Subscribe(bus.EventChannelMessage)
+		go m.recordInbound(inbound, unsub)
+	}
+	go m.pruneActivity(activity, retention, maxRows)
+}
+
+// recordActivity adds an entry for msg to the activity log, if there is one.
+func (m *ChannelManager) recordActivity(eventType string, msg Message) {
+	m.mu.RLock()
+	activity := m.activity
+	m.mu.RUnlock()
+	if activity == nil {
+		return
+	}
+
+	entry := Activity{
+		ChannelID:      msg.Source,
+		EventType:      eventType,
+		ConversationID: msg.ChannelID,
+		Snippet:        activitySnippet(msg.Content),
+		CreatedAt:      time.Now().UTC(),
+	}
+	if eventType == ActivityInbound {
+		entry.SenderID = msg.SenderID
+		entry.Sender = msg.Metadata["username"]
+	}
+	if err := activity.RecordChannelActivity(entry); err != nil {
+		log.Printf("Channels: failed to record activity for %s: %v", msg.Source, err)
+	}
+}
+
+// recordInbound logs the messages received by registered channels until the
+// manager shuts down.
+func (m *ChannelManager) recordInbound(events <-chan bus.Event, unsub func()) {
+	defer unsub()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			msg, ok := evt.Payload.(Message)
+			if !ok {
+				continue
+			}
+			if _, registered := m.Get(msg.Source); registered {
+				m.recordActivity(ActivityInbound, msg)
+			}
+		}
+	}
+}
+
+// pruneActivity applies the retention limits to the activity log right away
+// and then every activityPruneInterval until the manager shuts down.
+func (m *ChannelManager) pruneActivity(activity ActivityLog, retention time.Duration, maxRows int) {
+	ticker := time.NewTicker(activityPruneInterval)
+	defer ticker.Stop()
+	for {
+		removed, err := activity.PruneChannelActivity(time.Now().Add(-retention), maxRows)
+		if err != nil {
+			log.Printf("Channels: failed to prune activity log: %v", err)
+		} else if removed > 0 {
+			log.Printf("Channels: pruned %d activity log entries", removed)
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// activitySnippet shortens content to activitySnippetLen runes.
+func activitySnippet(content string) string {
+	if utf8.RuneCountInString(content) <= activitySnippetLen {
+		return content
+	}
+	runes := []rune(content)
+	return string(runes[:activitySnippetLen]) + "…"
+}
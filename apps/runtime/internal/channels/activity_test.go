@@ -0,0 +1,79 @@
+package channels
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+)
+
+type memoryActivity struct {
+	mu      sync.Mutex
+	entries []Activity
+}
+
+func (a *memoryActivity) RecordChannelActivity(entry Activity) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+	return nil
+}
+
+func (a *memoryActivity) PruneChannelActivity(cutoff time.Time, maxPerChannel int) (int64, error) {
+	return 0, nil
+}
+
+func (a *memoryActivity) Entries() []Activity {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]Activity(nil), a.entries...)
+}
+
+func TestActivityLog(t *testing.T) {
+	b := bus.New()
+	m := NewManager(b)
+	defer m.Shutdown()
+	activity := &memoryActivity{}
+	m.SetActivityLog(activity, 0, 0)
+
+	c := &sendingChannel{mockChannel: mockChannel{id: "telegram-main", status: StatusConnected}}
+	if err := m.Register(c); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	b.Publish(bus.NewEvent(bus.EventChannelMessage, "", Message{
+		Source:    "telegram-main",
+		ChannelID: "42",
+		SenderID:  "7",
+		Content:   strings.Repeat("a", activitySnippetLen+10),
+		Metadata:  map[string]string{"username": "alice"},
+	}))
+	// Messages of unregistered channels are not recorded
+	b.Publish(bus.NewEvent(bus.EventChannelMessage, "", Message{Source: "unknown", Content: "hi"}))
+	if err := m.Enqueue(Message{Source: "telegram-main", ChannelID: "42", Content: "hello"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	waitFor(t, "activity", func() bool { return len(activity.Entries()) == 2 })
+	entries := activity.Entries()
+	var inbound, outbound Activity
+	for _, e := range entries {
+		switch e.EventType {
+		case ActivityInbound:
+			inbound = e
+		case ActivityOutbound:
+			outbound = e
+		}
+	}
+	if inbound.ChannelID != "telegram-main" || inbound.SenderID != "7" || inbound.Sender != "alice" || inbound.ConversationID != "42" {
+		t.Errorf("unexpected inbound entry: %+v", inbound)
+	}
+	if got := []rune(inbound.Snippet); len(got) != activitySnippetLen+1 {
+		t.Errorf("expected the snippet to be shortened, got %d runes", len(got))
+	}
+	if outbound.Snippet != "hello" || outbound.SenderID != "" {
+		t.Errorf("unexpected outbound entry: %+v", outbound)
+	}
+}
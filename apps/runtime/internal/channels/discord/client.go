@@ -11,6 +11,8 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
+	"pryx-core/internal/channels"
 )
 
 const (
@@ -84,7 +86,7 @@ func NewClient(token string, opts ...ClientOption) *Client {
 	c := &Client{
 		token:      token,
 		baseURL:    apiBaseURL,
-		httpClient: &http.Client{Timeout: defaultTimeout},
+		httpClient: channels.NewPlatformHTTPClient("discord", defaultTimeout),
 		rateLimits: make(map[string]*RateLimitInfo),
 	}
 
@@ -188,7 +190,8 @@ func (c *Client) updateRateLimit(endpoint string, resp *http.Response) {
 	c.rateMu.Unlock()
 }
 
-// handleResponse processes the API response
+// handleResponse processes the API response. On success the body is kept
+// readable for the caller to decode.
 func (c *Client) handleResponse(resp *http.Response) error {
 	defer resp.Body.Close()
 
@@ -198,6 +201,7 @@ func (c *Client) handleResponse(resp *http.Response) error {
 	}
 
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
 		return nil
 	}
 
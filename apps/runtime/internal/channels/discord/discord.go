@@ -3,6 +3,8 @@ package discord
 import (
 	"context"
 	"fmt"
+	"log"
+	"sync"
 	"time"
 
 	"pryx-core/internal/bus"
@@ -18,6 +20,19 @@ type DiscordChannel struct {
 	eventBus *bus.Bus
 	cancel   context.CancelFunc
 	status   channels.Status
+
+	// Slash commands, set from the config
+	rest               *Client
+	applicationID      string
+	commands           []ApplicationCommand
+	guilds             []string
+	allowedChannels    []string
+	dmPermission       bool
+	defaultPermissions *string
+
+	mu            sync.Mutex
+	pending       map[string][]pendingInteraction
+	guildCommands []guildCommand
 }
 
 // HealthStatus represents the health status of the channel
@@ -33,9 +48,24 @@ func NewDiscordChannel(id, token string, eventBus *bus.Bus) *DiscordChannel {
 		token:    token,
 		eventBus: eventBus,
 		status:   channels.StatusDisconnected,
+		rest:     NewClient(token),
+		pending:  make(map[string][]pendingInteraction),
 	}
 }
 
+// NewDiscordChannelFromConfig creates a channel that also registers the
+// config's slash commands against its application on connect.
+func NewDiscordChannelFromConfig(config Config, eventBus *bus.Bus) *DiscordChannel {
+	d := NewDiscordChannel(config.ID, config.Token, eventBus)
+	d.applicationID = config.ApplicationID
+	d.commands = config.Commands
+	d.guilds = config.AllowedGuilds
+	d.allowedChannels = config.AllowedChannels
+	d.dmPermission = config.DMPermission
+	d.defaultPermissions = config.DefaultPermissions
+	return d
+}
+
 func (d *DiscordChannel) ID() string {
 	return d.id
 }
@@ -50,9 +80,11 @@ func (d *DiscordChannel) Connect(ctx context.Context) error {
 		d.status = channels.StatusError
 		return fmt.Errorf("failed to create discord session: %w", err)
 	}
+	session.Client = channels.NewPlatformHTTPClient("discord", 20*time.Second)
 
-	// Set up message handler
+	// Set up message and slash command handlers
 	session.AddHandler(d.handleMessage)
+	session.AddHandler(d.handleInteractionCreate)
 
 	// Open connection
 	if err := session.Open(); err != nil {
@@ -67,10 +99,11 @@ func (d *DiscordChannel) Connect(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	d.cancel = cancel
 
-	// Subscribe to outbound messages
-	if d.eventBus != nil {
-		outbound, unsub := d.eventBus.Subscribe(bus.EventChannelOutboundMessage)
-		go d.handleOutbound(ctx, outbound, unsub)
+	// A failed registration leaves messaging working, so it is not fatal
+	if d.applicationID != "" && len(d.commands) > 0 {
+		if err := d.RegisterCommands(ctx, d.commands); err != nil {
+			log.Printf("Discord: %v", err)
+		}
 	}
 
 	return nil
@@ -82,6 +115,10 @@ func (d *DiscordChannel) Disconnect(ctx context.Context) error {
 		d.cancel = nil
 	}
 
+	cleanupCtx, cancel := context.WithTimeout(context.Background(), commandCleanupTimeout)
+	d.unregisterGuildCommands(cleanupCtx)
+	cancel()
+
 	if d.session != nil {
 		if err := d.session.Close(); err != nil {
 			return fmt.Errorf("failed to close discord session: %w", err)
@@ -93,7 +130,12 @@ func (d *DiscordChannel) Disconnect(ctx context.Context) error {
 	return nil
 }
 
+// Send delivers msg, answering the oldest deferred slash command in its
+// channel if there is one and posting a regular message otherwise.
 func (d *DiscordChannel) Send(ctx context.Context, msg channels.Message) error {
+	if replied, err := d.replyToInteraction(ctx, msg.ChannelID, msg.Content); replied {
+		return err
+	}
 	if d.session == nil {
 		return fmt.Errorf("discord session not connected")
 	}
@@ -136,13 +178,6 @@ func (d *DiscordChannel) Health() HealthStatus {
 	}
 }
 
-// RegisterCommands registers slash commands for the bot
-func (d *DiscordChannel) RegisterCommands(ctx context.Context, cmds []ApplicationCommand) error {
-	// For now, commands are handled by the discordgo library
-	// This is a placeholder for future custom command registration
-	return nil
-}
-
 func (d *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
 	// Ignore messages from the bot itself
 	if m.Author.ID == s.State.User.ID {
@@ -187,24 +222,3 @@ func (d *DiscordChannel) handleMessage(s *discordgo.Session, m *discordgo.Messag
 		})
 	}
 }
-
-func (d *DiscordChannel) handleOutbound(ctx context.Context, outbound <-chan bus.Event, unsub func()) {
-	defer unsub()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case event := <-outbound:
-			if msg, ok := event.Payload.(channels.Message); ok {
-				if msg.Source == d.id {
-					// This is our message to send
-					if err := d.Send(ctx, msg); err != nil {
-						// Log error but don't crash
-						fmt.Printf("Discord send error: %v\n", err)
-					}
-				}
-			}
-		}
-	}
-}
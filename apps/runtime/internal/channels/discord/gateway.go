@@ -9,6 +9,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"pryx-core/internal/channels"
+
 	"nhooyr.io/websocket"
 )
 
@@ -54,7 +56,7 @@ type Gateway struct {
 func NewGateway(config *Config, eventHandler func(*GatewayEvent)) *Gateway {
 	return &Gateway{
 		config:       config,
-		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		httpClient:   channels.NewPlatformHTTPClient("discord", 30*time.Second),
 		eventHandler: eventHandler,
 	}
 }
@@ -0,0 +1,308 @@
+package discord
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// interactionTokenTTL is how long Discord accepts follow-ups for an
+	// interaction after it was created.
+	interactionTokenTTL = 15 * time.Minute
+	// commandCleanupTimeout bounds unregistering guild commands on disconnect.
+	commandCleanupTimeout = 10 * time.Second
+)
+
+// pendingInteraction is a deferred interaction waiting for the agent's reply.
+type pendingInteraction struct {
+	token   string
+	expires time.Time
+}
+
+// guildCommand is a command registered in one guild, kept to unregister it.
+type guildCommand struct {
+	guildID string
+	id      string
+}
+
+// RegisterCommands registers slash commands for the bot against the
+// configured application. Commands are registered in each allowed guild, or
+// globally if no guilds are configured.
+func (d *DiscordChannel) RegisterCommands(ctx context.Context, cmds []ApplicationCommand) error {
+	if d.applicationID == "" {
+		return fmt.Errorf("application ID is required to register commands")
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+
+	cmds = d.withCommandDefaults(cmds)
+
+	if len(d.guilds) == 0 {
+		// Bulk overwrite keeps global registration idempotent across restarts
+		if _, err := d.rest.BulkOverwriteCommands(ctx, d.applicationID, cmds); err != nil {
+			return fmt.Errorf("failed to register global commands: %w", err)
+		}
+		return nil
+	}
+
+	for _, guildID := range d.guilds {
+		for i := range cmds {
+			created, err := d.rest.CreateGuildSlashCommand(ctx, d.applicationID, guildID, &cmds[i])
+			if err != nil {
+				return fmt.Errorf("failed to register command %s in guild %s: %w", cmds[i].Name, guildID, err)
+			}
+			d.mu.Lock()
+			d.guildCommands = append(d.guildCommands, guildCommand{guildID: guildID, id: created.ID})
+			d.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// unregisterGuildCommands deletes the guild commands registered by this
+// channel. Global commands are left in place: they take up to an hour to
+// propagate and would otherwise vanish on every restart.
+func (d *DiscordChannel) unregisterGuildCommands(ctx context.Context) {
+	d.mu.Lock()
+	registered := d.guildCommands
+	d.guildCommands = nil
+	d.mu.Unlock()
+
+	for _, cmd := range registered {
+		if err := d.rest.DeleteGuildSlashCommand(ctx, d.applicationID, cmd.guildID, cmd.id); err != nil {
+			log.Printf("Discord: failed to unregister command %s in guild %s: %v", cmd.id, cmd.guildID, err)
+		}
+	}
+}
+
+// withCommandDefaults returns a copy of cmds with the config's DM and member
+// permissions applied where a command does not set its own.
+func (d *DiscordChannel) withCommandDefaults(cmds []ApplicationCommand) []ApplicationCommand {
+	out := make([]ApplicationCommand, len(cmds))
+	for i, cmd := range cmds {
+		if cmd.DMPermission == nil {
+			dm := d.dmPermission
+			cmd.DMPermission = &dm
+		}
+		if cmd.DefaultMemberPermissions == nil {
+			cmd.DefaultMemberPermissions = d.defaultPermissions
+		}
+		out[i] = cmd
+	}
+	return out
+}
+
+// isCommand reports whether name is one of the configured commands.
+func (d *DiscordChannel) isCommand(name string) bool {
+	for _, cmd := range d.commands {
+		if cmd.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *DiscordChannel) handleInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Interaction == nil || i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+	d.handleInteraction(context.Background(), interactionFromGateway(i.Interaction))
+}
+
+// handleInteraction acknowledges a slash command with a deferred response and
+// hands it to the agent as a channel message. Discord requires the ack within
+// three seconds; the agent's reply edits the deferred response when it arrives.
+func (d *DiscordChannel) handleInteraction(ctx context.Context, in Interaction) {
+	if in.Type != InteractionTypeApplicationCommand || in.Data == nil || !d.isCommand(in.Data.Name) {
+		return
+	}
+
+	if !stringAllowed(d.guilds, in.GuildID) || !stringAllowed(d.allowedChannels, in.ChannelID) {
+		err := d.rest.RespondToInteraction(ctx, in.ID, in.Token, &InteractionResponse{
+			Type: InteractionResponseTypeChannelMessageWithSource,
+			Data: &InteractionResponseData{
+				Content: "This command is not available here.",
+				Flags:   1 << 6, // Ephemeral flag
+			},
+		})
+		if err != nil {
+			log.Printf("Discord: failed to reject interaction %s: %v", in.ID, err)
+		}
+		return
+	}
+
+	err := d.rest.RespondToInteraction(ctx, in.ID, in.Token, &InteractionResponse{
+		Type: InteractionResponseTypeDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		log.Printf("Discord: failed to acknowledge interaction %s: %v", in.ID, err)
+		return
+	}
+
+	d.mu.Lock()
+	d.pending[in.ChannelID] = append(d.pending[in.ChannelID], pendingInteraction{
+		token:   in.Token,
+		expires: time.Now().Add(interactionTokenTTL),
+	})
+	d.mu.Unlock()
+
+	var user *User
+	if in.Member != nil && in.Member.User != nil {
+		user = in.Member.User
+	} else if in.User != nil {
+		user = in.User
+	}
+
+	msg := channels.Message{
+		ID:        in.ID,
+		Content:   commandContent(in.Data),
+		Source:    d.id,
+		ChannelID: in.ChannelID,
+		Metadata: map[string]string{
+			"channel":        in.ChannelID,
+			"command":        in.Data.Name,
+			"interaction_id": in.ID,
+			"is_dm":          fmt.Sprintf("%v", in.GuildID == ""),
+		},
+		CreatedAt: time.Now(),
+	}
+	if in.GuildID != "" {
+		msg.Metadata["guild_id"] = in.GuildID
+	}
+	if user != nil {
+		msg.SenderID = user.ID
+		msg.Metadata["username"] = user.Username
+	}
+
+	if d.eventBus != nil {
+		d.eventBus.Publish(bus.NewEvent(bus.EventChannelMessage, "", msg))
+	}
+}
+
+// replyToInteraction delivers content as the response to the oldest deferred
+// interaction in channelID. It reports false if there is none still valid, in
+// which case the caller sends a regular message.
+func (d *DiscordChannel) replyToInteraction(ctx context.Context, channelID, content string) (bool, error) {
+	var pending *pendingInteraction
+	d.mu.Lock()
+	queue := d.pending[channelID]
+	for len(queue) > 0 && pending == nil {
+		if time.Now().Before(queue[0].expires) {
+			p := queue[0]
+			pending = &p
+		}
+		queue = queue[1:]
+	}
+	if len(queue) == 0 {
+		delete(d.pending, channelID)
+	} else {
+		d.pending[channelID] = queue
+	}
+	d.mu.Unlock()
+
+	if pending == nil {
+		return false, nil
+	}
+	_, err := d.rest.EditInteractionResponse(ctx, d.applicationID, pending.token, &InteractionResponseData{Content: content})
+	return true, err
+}
+
+// commandContent renders a slash command invocation as message text: the
+// subcommand path followed by the option values, or the command itself if it
+// has no options.
+func commandContent(data *InteractionData) string {
+	var parts []string
+	var walk func(opts []ApplicationCommandInteractionDataOption)
+	walk = func(opts []ApplicationCommandInteractionDataOption) {
+		for _, opt := range opts {
+			switch opt.Type {
+			case ApplicationCommandOptionTypeSubCommand, ApplicationCommandOptionTypeSubCommandGroup:
+				parts = append(parts, opt.Name)
+				walk(opt.Options)
+			default:
+				if opt.Value != nil {
+					parts = append(parts, fmt.Sprint(opt.Value))
+				}
+			}
+		}
+	}
+	walk(data.Options)
+
+	if len(parts) == 0 {
+		return "/" + data.Name
+	}
+	return strings.Join(parts, " ")
+}
+
+// interactionFromGateway converts a gateway interaction to the REST type.
+func interactionFromGateway(i *discordgo.Interaction) Interaction {
+	in := Interaction{
+		ID:            i.ID,
+		ApplicationID: i.AppID,
+		Type:          InteractionType(i.Type),
+		GuildID:       i.GuildID,
+		ChannelID:     i.ChannelID,
+		Token:         i.Token,
+		Version:       i.Version,
+	}
+	if i.Member != nil && i.Member.User != nil {
+		in.Member = &GuildMember{User: userFromGateway(i.Member.User)}
+	}
+	if i.User != nil {
+		in.User = userFromGateway(i.User)
+	}
+	if i.Type == discordgo.InteractionApplicationCommand {
+		data := i.ApplicationCommandData()
+		in.Data = &InteractionData{
+			ID:      data.ID,
+			Name:    data.Name,
+			Type:    ApplicationCommandType(data.CommandType),
+			Options: optionsFromGateway(data.Options),
+		}
+	}
+	return in
+}
+
+func optionsFromGateway(opts []*discordgo.ApplicationCommandInteractionDataOption) []ApplicationCommandInteractionDataOption {
+	out := make([]ApplicationCommandInteractionDataOption, 0, len(opts))
+	for _, opt := range opts {
+		out = append(out, ApplicationCommandInteractionDataOption{
+			Name:    opt.Name,
+			Type:    ApplicationCommandOptionType(opt.Type),
+			Value:   opt.Value,
+			Options: optionsFromGateway(opt.Options),
+		})
+	}
+	return out
+}
+
+func userFromGateway(u *discordgo.User) *User {
+	return &User{
+		ID:            u.ID,
+		Username:      u.Username,
+		Discriminator: u.Discriminator,
+		Bot:           u.Bot,
+	}
+}
+
+// stringAllowed reports whether value is in list; an empty list allows all.
+func stringAllowed(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
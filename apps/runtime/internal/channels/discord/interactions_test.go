@@ -0,0 +1,213 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+)
+
+// apiCall is one request received by the fake Discord API.
+type apiCall struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+type fakeAPI struct {
+	mu    sync.Mutex
+	calls []apiCall
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	var body map[string]interface{}
+	_ = json.Unmarshal(raw, &body)
+
+	f.mu.Lock()
+	f.calls = append(f.calls, apiCall{method: r.Method, path: r.URL.Path, body: body})
+	f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodDelete || strings.HasPrefix(r.URL.Path, "/interactions/"):
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		w.Write(raw)
+	default:
+		id := "msg"
+		if name, ok := body["name"].(string); ok {
+			id = "cmd-" + name
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": id})
+	}
+}
+
+func (f *fakeAPI) Calls() []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]apiCall(nil), f.calls...)
+}
+
+func newTestChannel(t *testing.T, config Config, b *bus.Bus) (*DiscordChannel, *fakeAPI) {
+	api := &fakeAPI{}
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	config.ID = "discord-test"
+	config.Token = "bot-token"
+	d := NewDiscordChannelFromConfig(config, b)
+	d.rest = NewClient(config.Token, WithBaseURL(srv.URL))
+	return d, api
+}
+
+func TestRegisterCommands_GuildScoped(t *testing.T) {
+	d, api := newTestChannel(t, Config{
+		ApplicationID: "app",
+		AllowedGuilds: []string{"g1", "g2"},
+		Commands:      []ApplicationCommand{{Name: "ask", Description: "Ask Pryx"}},
+	}, nil)
+
+	if err := d.RegisterCommands(context.Background(), d.commands); err != nil {
+		t.Fatalf("RegisterCommands: %v", err)
+	}
+	calls := api.Calls()
+	if len(calls) != 2 || calls[0].path != "/applications/app/guilds/g1/commands" || calls[1].path != "/applications/app/guilds/g2/commands" {
+		t.Fatalf("unexpected registration calls: %+v", calls)
+	}
+	if calls[0].body["dm_permission"] != false {
+		t.Errorf("dm_permission = %v, want config default false", calls[0].body["dm_permission"])
+	}
+
+	if err := d.Disconnect(context.Background()); err != nil {
+		t.Fatalf("Disconnect: %v", err)
+	}
+	calls = api.Calls()[2:]
+	if len(calls) != 2 || calls[0].method != http.MethodDelete || calls[0].path != "/applications/app/guilds/g1/commands/cmd-ask" || calls[1].path != "/applications/app/guilds/g2/commands/cmd-ask" {
+		t.Fatalf("unexpected cleanup calls: %+v", calls)
+	}
+}
+
+func TestRegisterCommands_Global(t *testing.T) {
+	d, api := newTestChannel(t, Config{
+		ApplicationID: "app",
+		DMPermission:  true,
+		Commands:      []ApplicationCommand{{Name: "ask", Description: "Ask Pryx"}},
+	}, nil)
+
+	if err := d.RegisterCommands(context.Background(), d.commands); err != nil {
+		t.Fatalf("RegisterCommands: %v", err)
+	}
+	if err := d.Disconnect(context.Background()); err != nil {
+		t.Fatalf("Disconnect: %v", err)
+	}
+
+	// Global commands are overwritten in bulk and survive a disconnect
+	calls := api.Calls()
+	if len(calls) != 1 || calls[0].method != http.MethodPut || calls[0].path != "/applications/app/commands" {
+		t.Fatalf("unexpected calls: %+v", calls)
+	}
+
+	d.applicationID = ""
+	if err := d.RegisterCommands(context.Background(), d.commands); err == nil {
+		t.Error("expected an error without an application ID")
+	}
+}
+
+func TestHandleInteraction_DeferredReply(t *testing.T) {
+	b := bus.New()
+	inbound, unsub := b.Subscribe(bus.EventChannelMessage)
+	defer unsub()
+
+	d, api := newTestChannel(t, Config{
+		ApplicationID:   "app",
+		AllowedChannels: []string{"c1"},
+		Commands:        []ApplicationCommand{{Name: "ask", Description: "Ask Pryx"}},
+	}, b)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Unknown commands are ignored, disallowed channels are refused
+	d.handleInteraction(ctx, Interaction{ID: "i0", Token: "tok0", Type: InteractionTypeApplicationCommand, ChannelID: "c1", Data: &InteractionData{Name: "other"}})
+	d.handleInteraction(ctx, Interaction{ID: "i2", Token: "tok2", Type: InteractionTypeApplicationCommand, ChannelID: "c2", Data: &InteractionData{Name: "ask"}})
+
+	d.handleInteraction(ctx, Interaction{
+		ID:        "i1",
+		Token:     "tok1",
+		Type:      InteractionTypeApplicationCommand,
+		GuildID:   "g1",
+		ChannelID: "c1",
+		Member:    &GuildMember{User: &User{ID: "u1", Username: "alice"}},
+		Data: &InteractionData{Name: "ask", Options: []ApplicationCommandInteractionDataOption{
+			{Name: "question", Type: ApplicationCommandOptionTypeString, Value: "what time is it?"},
+		}},
+	})
+
+	calls := api.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 callbacks, got %+v", calls)
+	}
+	if calls[0].path != "/interactions/i2/tok2/callback" || calls[0].body["type"] != float64(InteractionResponseTypeChannelMessageWithSource) {
+		t.Errorf("unexpected rejection: %+v", calls[0])
+	}
+	if calls[1].path != "/interactions/i1/tok1/callback" || calls[1].body["type"] != float64(InteractionResponseTypeDeferredChannelMessageWithSource) {
+		t.Errorf("unexpected ack: %+v", calls[1])
+	}
+
+	var msg channels.Message
+	select {
+	case evt := <-inbound:
+		msg = evt.Payload.(channels.Message)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for channel message")
+	}
+	if msg.Content != "what time is it?" || msg.Source != "discord-test" || msg.ChannelID != "c1" || msg.SenderID != "u1" || msg.Metadata["command"] != "ask" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+
+	// The agent's reply, delivered through Send, completes the deferred response
+	if err := d.Send(ctx, channels.Message{Source: "discord-test", ChannelID: "c1", Content: "noon"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	calls = api.Calls()
+	if len(calls) != 3 {
+		t.Fatalf("expected the reply call, got %+v", calls)
+	}
+	if calls[2].method != http.MethodPatch || calls[2].path != "/webhooks/app/tok1/messages/@original" || calls[2].body["content"] != "noon" {
+		t.Errorf("unexpected reply: %+v", calls[2])
+	}
+
+	if replied, _ := d.replyToInteraction(ctx, "c1", "again"); replied {
+		t.Error("a deferred interaction should be answered only once")
+	}
+}
+
+func TestCommandContent(t *testing.T) {
+	tests := []struct {
+		data *InteractionData
+		want string
+	}{
+		{&InteractionData{Name: "status"}, "/status"},
+		{&InteractionData{Name: "ask", Options: []ApplicationCommandInteractionDataOption{
+			{Name: "question", Type: ApplicationCommandOptionTypeString, Value: "hello"},
+		}}, "hello"},
+		{&InteractionData{Name: "remind", Options: []ApplicationCommandInteractionDataOption{
+			{Name: "in", Type: ApplicationCommandOptionTypeSubCommand, Options: []ApplicationCommandInteractionDataOption{
+				{Name: "minutes", Type: ApplicationCommandOptionTypeInteger, Value: float64(5)},
+				{Name: "text", Type: ApplicationCommandOptionTypeString, Value: "stretch"},
+			}},
+		}}, "in 5 stretch"},
+	}
+	for _, tt := range tests {
+		if got := commandContent(tt.data); got != tt.want {
+			t.Errorf("commandContent(%s) = %q, want %q", tt.data.Name, got, tt.want)
+		}
+	}
+}
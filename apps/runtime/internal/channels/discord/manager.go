@@ -63,7 +63,7 @@ func (m *Manager) CreateChannel(ctx context.Context, config Config) error {
 		return fmt.Errorf("bot token is required")
 	}
 
-	channel := NewDiscordChannel(config.ID, token, m.eventBus)
+	channel := NewDiscordChannelFromConfig(config, m.eventBus)
 
 	m.channels[config.ID] = channel
 
@@ -0,0 +1,122 @@
+package channels
+
+import (
+	"fmt"
+	"regexp"
+
+	"pryx-core/internal/config"
+)
+
+// FilterDecision is the outcome of screening an inbound message.
+type FilterDecision struct {
+	Allowed bool
+	// Reason names the rule that filtered the message, e.g. "deny_sender" or
+	// "deny_pattern:<expr>".
+	Reason string
+	// Reply is the auto-reply to send for a filtered message, empty for none.
+	Reply string
+}
+
+type inboundRules struct {
+	allowSenders  map[string]bool
+	denySenders   map[string]bool
+	allowPatterns []*regexp.Regexp
+	denyPatterns  []*regexp.Regexp
+	reply         string
+}
+
+// InboundFilter screens inbound channel messages by sender and content before
+// they reach the agent.
+type InboundFilter struct {
+	rules map[string]*inboundRules
+}
+
+// NewInboundFilter compiles the per-channel filters. It returns nil when none
+// are configured, and an error naming the channel if a pattern is invalid.
+func NewInboundFilter(filters map[string]config.ChannelInboundFilter) (*InboundFilter, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+	f := &InboundFilter{rules: make(map[string]*inboundRules, len(filters))}
+	for channel, cfg := range filters {
+		r := &inboundRules{
+			allowSenders: idSet(cfg.AllowSenders),
+			denySenders:  idSet(cfg.DenySenders),
+			reply:        cfg.Reply,
+		}
+		var err error
+		if r.allowPatterns, err = compilePatterns(cfg.AllowPatterns); err != nil {
+			return nil, fmt.Errorf("channel_filters[%s].allow_patterns: %w", channel, err)
+		}
+		if r.denyPatterns, err = compilePatterns(cfg.DenyPatterns); err != nil {
+			return nil, fmt.Errorf("channel_filters[%s].deny_patterns: %w", channel, err)
+		}
+		f.rules[channel] = r
+	}
+	return f, nil
+}
+
+// Check screens msg with the rules for its source channel, falling back to
+// the "*" entry. A nil filter allows everything.
+func (f *InboundFilter) Check(msg Message) FilterDecision {
+	if f == nil {
+		return FilterDecision{Allowed: true}
+	}
+	r, ok := f.rules[msg.Source]
+	if !ok {
+		r, ok = f.rules["*"]
+	}
+	if !ok {
+		return FilterDecision{Allowed: true}
+	}
+
+	reason := r.reject(msg)
+	if reason == "" {
+		return FilterDecision{Allowed: true}
+	}
+	return FilterDecision{Reason: reason, Reply: r.reply}
+}
+
+// reject returns the reason msg is filtered, or "" if it passes.
+func (r *inboundRules) reject(msg Message) string {
+	if r.denySenders[msg.SenderID] {
+		return "deny_sender"
+	}
+	if len(r.allowSenders) > 0 && !r.allowSenders[msg.SenderID] {
+		return "sender_not_allowed"
+	}
+	for _, re := range r.denyPatterns {
+		if re.MatchString(msg.Content) {
+			return "deny_pattern:" + re.String()
+		}
+	}
+	if len(r.allowPatterns) == 0 {
+		return ""
+	}
+	for _, re := range r.allowPatterns {
+		if re.MatchString(msg.Content) {
+			return ""
+		}
+	}
+	return "no_allow_pattern"
+}
+
+func idSet(ids []string) map[string]bool {
+	m := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		m[id] = true
+	}
+	return m
+}
+
+func compilePatterns(exprs []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
@@ -0,0 +1,60 @@
+package channels
+
+import (
+	"testing"
+
+	"pryx-core/internal/config"
+)
+
+func TestInboundFilter_Check(t *testing.T) {
+	f, err := NewInboundFilter(map[string]config.ChannelInboundFilter{
+		"telegram-main": {
+			DenySenders:   []string{"spammer"},
+			AllowPatterns: []string{`(?i)^/ask\b`, `(?i)pryx`},
+			DenyPatterns:  []string{`https?://`},
+			Reply:         "Start your message with /ask.",
+		},
+		"*": {AllowSenders: []string{"alice"}},
+	})
+	if err != nil {
+		t.Fatalf("NewInboundFilter failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		msg    Message
+		reason string
+	}{
+		{"allowed pattern", Message{Source: "telegram-main", SenderID: "bob", Content: "/ask what time is it"}, ""},
+		{"denied sender", Message{Source: "telegram-main", SenderID: "spammer", Content: "/ask hi"}, "deny_sender"},
+		{"denied pattern wins", Message{Source: "telegram-main", SenderID: "bob", Content: "/ask see http://x.test"}, "deny_pattern:https?://"},
+		{"no allow pattern", Message{Source: "telegram-main", SenderID: "bob", Content: "buy now"}, "no_allow_pattern"},
+		{"fallback allowed sender", Message{Source: "slack-main", SenderID: "alice", Content: "anything"}, ""},
+		{"fallback other sender", Message{Source: "slack-main", SenderID: "bob", Content: "anything"}, "sender_not_allowed"},
+	} {
+		got := f.Check(tc.msg)
+		if got.Allowed != (tc.reason == "") || got.Reason != tc.reason {
+			t.Errorf("%s: got %+v, want reason %q", tc.name, got, tc.reason)
+		}
+	}
+
+	if got := f.Check(Message{Source: "telegram-main", SenderID: "bob", Content: "spam"}); got.Reply != "Start your message with /ask." {
+		t.Errorf("Expected the channel's auto-reply, got %q", got.Reply)
+	}
+}
+
+func TestInboundFilter_NilAndInvalid(t *testing.T) {
+	f, err := NewInboundFilter(nil)
+	if err != nil || f != nil {
+		t.Fatalf("Expected no filter without config, got %v, %v", f, err)
+	}
+	if !f.Check(Message{Content: "anything"}).Allowed {
+		t.Error("Expected a nil filter to allow everything")
+	}
+
+	if _, err := NewInboundFilter(map[string]config.ChannelInboundFilter{
+		"telegram-main": {DenyPatterns: []string{"("}},
+	}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
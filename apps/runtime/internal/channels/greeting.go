@@ -0,0 +1,126 @@
+package channels
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"pryx-core/internal/config"
+)
+
+// DefaultGreeting is used for channels whose greeting has no message template.
+const DefaultGreeting = `Hi, I'm {{.Name}}. Send me a message to get started.{{if .Commands}}
+
+Commands:{{range .Commands}}
+- {{.}}{{end}}{{end}}{{if .Capabilities}}
+
+I can help with: {{join .Capabilities ", "}}{{end}}`
+
+// GreetingTracker records which conversations have been greeted.
+// MarkGreeted reports true only the first time a conversation is marked.
+type GreetingTracker interface {
+	MarkGreeted(source, conversationID string) (bool, error)
+}
+
+// GreetingData fills a greeting template.
+type GreetingData struct {
+	Name         string
+	Channel      string
+	Commands     []string
+	Capabilities []string
+}
+
+// Greeter decides when a channel conversation should be greeted and renders
+// the greeting.
+type Greeter struct {
+	greetings map[string]config.ChannelGreeting
+	tracker   GreetingTracker
+
+	// greeted is used when there is no tracker, so greetings are not repeated
+	// for the lifetime of the process.
+	mu      sync.Mutex
+	greeted map[string]bool
+}
+
+// NewGreeter creates a greeter. It returns nil when no greetings are configured.
+// tracker may be nil, in which case greeted conversations are kept in memory.
+func NewGreeter(greetings map[string]config.ChannelGreeting, tracker GreetingTracker) *Greeter {
+	if len(greetings) == 0 {
+		return nil
+	}
+	return &Greeter{greetings: greetings, tracker: tracker, greeted: make(map[string]bool)}
+}
+
+// IsStartCommand reports whether content is a /start command, including the
+// Telegram forms "/start@botname" and "/start <payload>".
+func IsStartCommand(content string) bool {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return false
+	}
+	cmd, _, _ := strings.Cut(fields[0], "@")
+	return strings.EqualFold(cmd, "/start")
+}
+
+// Greet returns the greeting to send for msg, or "" when none is due. A /start
+// command is always greeted; other messages only on a conversation's first message.
+// The data's Channel and Commands are filled in from msg and the config.
+func (g *Greeter) Greet(msg Message, data GreetingData) (string, error) {
+	if g == nil {
+		return "", nil
+	}
+	greeting, ok := g.greetings[msg.Source]
+	if !ok {
+		greeting, ok = g.greetings["*"]
+	}
+	if !ok {
+		return "", nil
+	}
+
+	first, err := g.markGreeted(msg.Source, msg.ChannelID)
+	if err != nil {
+		return "", err
+	}
+	if !first && !IsStartCommand(msg.Content) {
+		return "", nil
+	}
+
+	data.Channel = msg.Source
+	data.Commands = greeting.Commands
+	if !greeting.ListCapabilities {
+		data.Capabilities = nil
+	}
+	return RenderGreeting(greeting.Message, data)
+}
+
+func (g *Greeter) markGreeted(source, conversationID string) (bool, error) {
+	if g.tracker != nil {
+		return g.tracker.MarkGreeted(source, conversationID)
+	}
+	key := source + "\x00" + conversationID
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.greeted[key] {
+		return false, nil
+	}
+	g.greeted[key] = true
+	return true, nil
+}
+
+// RenderGreeting executes a greeting template. An empty text uses DefaultGreeting.
+func RenderGreeting(text string, data GreetingData) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultGreeting
+	}
+	tmpl, err := template.New("greeting").Funcs(template.FuncMap{"join": strings.Join}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid greeting template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render greeting: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,85 @@
+package channels
+
+import (
+	"strings"
+	"testing"
+
+	"pryx-core/internal/config"
+)
+
+func TestIsStartCommand(t *testing.T) {
+	for content, want := range map[string]bool{
+		"/start":              true,
+		"/START":              true,
+		"/start@pryx_bot":     true,
+		"/start ref-campaign": true,
+		"  /start  ":          true,
+		"/started":            false,
+		"start":               false,
+		"hello /start":        false,
+		"":                    false,
+	} {
+		if got := IsStartCommand(content); got != want {
+			t.Errorf("IsStartCommand(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
+
+func TestGreeter_FirstMessageAndStart(t *testing.T) {
+	g := NewGreeter(map[string]config.ChannelGreeting{
+		"telegram-main": {Message: "Welcome to {{.Channel}}, I'm {{.Name}}."},
+	}, nil)
+	data := GreetingData{Name: "Pryx"}
+
+	msg := Message{Source: "telegram-main", ChannelID: "42", Content: "hi"}
+	got, err := g.Greet(msg, data)
+	if err != nil {
+		t.Fatalf("Greet failed: %v", err)
+	}
+	if got != "Welcome to telegram-main, I'm Pryx." {
+		t.Errorf("Unexpected greeting: %q", got)
+	}
+
+	if got, _ := g.Greet(msg, data); got != "" {
+		t.Errorf("Expected no repeat greeting, got %q", got)
+	}
+
+	msg.Content = "/start"
+	if got, _ := g.Greet(msg, data); got == "" {
+		t.Error("Expected /start to greet again")
+	}
+
+	other := Message{Source: "slack-main", ChannelID: "42", Content: "hi"}
+	if got, _ := g.Greet(other, data); got != "" {
+		t.Errorf("Expected no greeting for unconfigured channel, got %q", got)
+	}
+}
+
+func TestGreeter_DefaultTemplate(t *testing.T) {
+	g := NewGreeter(map[string]config.ChannelGreeting{
+		"*": {Commands: []string{"/help - show help"}, ListCapabilities: true},
+	}, nil)
+
+	got, err := g.Greet(Message{Source: "discord-main", ChannelID: "1"}, GreetingData{
+		Name:         "Juniper",
+		Capabilities: []string{"weather", "calendar"},
+	})
+	if err != nil {
+		t.Fatalf("Greet failed: %v", err)
+	}
+	for _, want := range []string{"I'm Juniper", "- /help - show help", "weather, calendar"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected greeting to contain %q, got %q", want, got)
+		}
+	}
+}
+
+func TestNewGreeter_NoGreetings(t *testing.T) {
+	g := NewGreeter(nil, nil)
+	if g != nil {
+		t.Fatal("Expected nil greeter without configuration")
+	}
+	if got, err := g.Greet(Message{Source: "x", Content: "/start"}, GreetingData{}); got != "" || err != nil {
+		t.Errorf("Expected nil greeter to do nothing, got %q, %v", got, err)
+	}
+}
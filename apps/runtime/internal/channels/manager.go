@@ -9,10 +9,30 @@ import (
 	"pryx-core/internal/bus"
 )
 
+// DuplicateChannelError is returned by Register when a channel with the same
+// ID is already registered.
+type DuplicateChannelError struct {
+	ID string
+}
+
+func (e *DuplicateChannelError) Error() string {
+	return fmt.Sprintf("channel %s already registered", e.ID)
+}
+
 type ChannelManager struct {
 	mu       sync.RWMutex
 	channels map[string]Channel
+	loops    map[string]*connectionLoop
 	eventBus *bus.Bus
+	errors   *bus.ErrorEmitter
+
+	// Outbound messages waiting for delivery, per channel
+	outboxMu    sync.Mutex
+	queues      map[string]*outboxQueue
+	outboxStore OutboxStore
+
+	// activity records the messages channels receive and deliver; see SetActivityLog
+	activity ActivityLog
 
 	ctx    context.Context
 	cancel func()
@@ -20,28 +40,97 @@ type ChannelManager struct {
 
 func NewManager(eventBus *bus.Bus) *ChannelManager {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &ChannelManager{
+	m := &ChannelManager{
 		channels: make(map[string]Channel),
+		loops:    make(map[string]*connectionLoop),
 		eventBus: eventBus,
+		errors:   bus.NewErrorEmitter(eventBus, bus.DefaultErrorWindow),
+		queues:   make(map[string]*outboxQueue),
 		ctx:      ctx,
 		cancel:   cancel,
 	}
+
+	// Registered channels receive their outbound messages through the
+	// manager's retry queues
+	if eventBus != nil {
+		outbound, unsub := eventBus.Subscribe(bus.EventChannelOutboundMessage)
+		go m.dispatchOutbound(outbound, unsub)
+	}
+	return m
 }
 
+// connectionLoop tracks the maintainConnection goroutine of one channel so it
+// can be stopped on its own.
+type connectionLoop struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Register adds c and starts connecting it. It returns a *DuplicateChannelError
+// if a channel with the same ID is already registered.
 func (m *ChannelManager) Register(c Channel) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if _, exists := m.channels[c.ID()]; exists {
-		return fmt.Errorf("channel %s already registered", c.ID())
+		return &DuplicateChannelError{ID: c.ID()}
 	}
+	m.start(c)
+	return nil
+}
 
+// Upsert registers c, replacing any channel with the same ID. A replaced
+// channel is disconnected before c starts connecting, so re-registering the
+// same channel restarts it. It reports whether a channel was replaced.
+func (m *ChannelManager) Upsert(c Channel) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, replaced := m.channels[c.ID()]
+	if replaced {
+		m.stop(c.ID())
+	}
+	m.start(c)
+	return replaced
+}
+
+// Unregister stops and removes the channel with the given ID. It reports
+// whether the channel was registered.
+func (m *ChannelManager) Unregister(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.channels[id]; !exists {
+		return false
+	}
+	m.stop(id)
+	delete(m.channels, id)
+	return true
+}
+
+// start stores c and launches its auto-reconnect loop. Callers hold m.mu.
+func (m *ChannelManager) start(c Channel) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	loop := &connectionLoop{cancel: cancel, done: make(chan struct{})}
 	m.channels[c.ID()] = c
+	m.loops[c.ID()] = loop
 
-	// Start auto-reconnect loop
-	go m.maintainConnection(c)
+	go func() {
+		defer close(loop.done)
+		m.maintainConnection(ctx, c)
+	}()
+}
 
-	return nil
+// stop cancels the loop of the channel with the given ID and waits for it to
+// disconnect. Callers hold m.mu.
+func (m *ChannelManager) stop(id string) {
+	loop, ok := m.loops[id]
+	if !ok {
+		return
+	}
+	loop.cancel()
+	<-loop.done
+	delete(m.loops, id)
 }
 
 func (m *ChannelManager) Get(id string) (Channel, bool) {
@@ -71,9 +160,9 @@ func (m *ChannelManager) Shutdown() {
 	}
 }
 
-func (m *ChannelManager) maintainConnection(c Channel) {
+func (m *ChannelManager) maintainConnection(ctx context.Context, c Channel) {
 	// Initial Connect
-	if err := c.Connect(m.ctx); err != nil {
+	if err := c.Connect(ctx); err != nil {
 		m.publishError(c, err)
 	} else {
 		m.publishStatus(c, StatusConnected)
@@ -84,14 +173,15 @@ func (m *ChannelManager) maintainConnection(c Channel) {
 
 	for {
 		select {
-		case <-m.ctx.Done():
+		case <-ctx.Done():
 			c.Disconnect(context.Background())
+			m.publishStatus(c, StatusDisconnected)
 			return
 		case <-ticker.C:
 			currentStatus := c.Status()
 			if currentStatus != StatusConnected && currentStatus != StatusConnecting {
 				m.publishStatus(c, StatusConnecting)
-				if err := c.Connect(m.ctx); err != nil {
+				if err := c.Connect(ctx); err != nil {
 					m.publishError(c, err)
 					m.publishStatus(c, StatusError)
 				} else {
@@ -103,12 +193,10 @@ func (m *ChannelManager) maintainConnection(c Channel) {
 }
 
 func (m *ChannelManager) publishError(c Channel, err error) {
-	if m.eventBus != nil {
-		m.eventBus.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
-			"channel_id": c.ID(),
-			"error":      err.Error(),
-		}))
-	}
+	m.errors.Emit("", map[string]interface{}{
+		"channel_id": c.ID(),
+		"error":      err.Error(),
+	})
 }
 
 func (m *ChannelManager) publishStatus(c Channel, status Status) {
@@ -2,6 +2,7 @@ package channels
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -71,12 +72,63 @@ func TestManager_Register(t *testing.T) {
 		t.Errorf("Expected ID test-channel, got %s", retrieved.ID())
 	}
 
-	// Verify duplicate registration fails
-	if err := m.Register(c); err == nil {
-		t.Error("Expected error for duplicate registration")
+	// Verify duplicate registration fails with a typed error
+	var dup *DuplicateChannelError
+	if err := m.Register(c); !errors.As(err, &dup) || dup.ID != "test-channel" {
+		t.Errorf("Expected DuplicateChannelError for duplicate registration, got %v", err)
 	}
 }
 
+func TestManager_UpsertRestartsChannel(t *testing.T) {
+	m := NewManager(bus.New())
+	defer m.Shutdown()
+
+	old := &mockChannel{id: "upsert-test", status: StatusDisconnected}
+	if replaced := m.Upsert(old); replaced {
+		t.Fatal("Upsert of a new channel reported a replacement")
+	}
+	waitForStatus(t, old, StatusConnected)
+
+	next := &mockChannel{id: "upsert-test", status: StatusDisconnected}
+	if replaced := m.Upsert(next); !replaced {
+		t.Fatal("Upsert of an existing channel did not report a replacement")
+	}
+
+	// The old instance is disconnected before Upsert returns.
+	old.mu.Lock()
+	disconnected := old.disconnectCalled
+	old.mu.Unlock()
+	if !disconnected {
+		t.Error("Replaced channel was not disconnected")
+	}
+	waitForStatus(t, next, StatusConnected)
+	if got, _ := m.Get("upsert-test"); got != next {
+		t.Error("Get did not return the replacement channel")
+	}
+
+	if !m.Unregister("upsert-test") {
+		t.Fatal("Unregister did not find the channel")
+	}
+	if _, ok := m.Get("upsert-test"); ok {
+		t.Error("Channel still registered after Unregister")
+	}
+	if next.Status() != StatusDisconnected {
+		t.Error("Unregistered channel was not disconnected")
+	}
+}
+
+func waitForStatus(t *testing.T, c *mockChannel, want Status) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if c.Status() == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("channel %s status = %s, want %s", c.id, c.Status(), want)
+}
+
 func TestManager_AutoConnect(t *testing.T) {
 	b := bus.New()
 	m := NewManager(b)
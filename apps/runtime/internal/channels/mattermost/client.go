@@ -0,0 +1,138 @@
+package mattermost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"pryx-core/internal/channels"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// APIError represents an error returned by the Mattermost REST API
+type APIError struct {
+	StatusCode int    `json:"status_code"`
+	ID         string `json:"id"`
+	Message    string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("mattermost API error %d: %s", e.StatusCode, e.Message)
+}
+
+// User is the subset of a Mattermost user the channel needs
+type User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// Post is a Mattermost message
+type Post struct {
+	ID        string `json:"id,omitempty"`
+	ChannelID string `json:"channel_id"`
+	UserID    string `json:"user_id,omitempty"`
+	RootID    string `json:"root_id,omitempty"`
+	Message   string `json:"message"`
+	Type      string `json:"type,omitempty"`
+	CreateAt  int64  `json:"create_at,omitempty"`
+}
+
+// Client is a Mattermost REST API v4 client
+type Client struct {
+	serverURL  string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the server at serverURL authenticating with
+// a bot or personal access token.
+func NewClient(serverURL, token string) *Client {
+	return &Client{
+		serverURL:  strings.TrimRight(serverURL, "/"),
+		token:      token,
+		httpClient: channels.NewPlatformHTTPClient("mattermost", defaultTimeout),
+	}
+}
+
+// GetMe returns the user the token belongs to
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodGet, "/users/me", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreatePost posts a message to a channel, as a thread reply when RootID is set
+func (c *Client) CreatePost(ctx context.Context, post Post) (*Post, error) {
+	var created Post
+	if err := c.do(ctx, http.MethodPost, "/posts", post, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// WebSocketURL returns the URL of the server's event websocket
+func (c *Client) WebSocketURL() string {
+	u := c.serverURL
+	switch {
+	case strings.HasPrefix(u, "https://"):
+		u = "wss://" + strings.TrimPrefix(u, "https://")
+	case strings.HasPrefix(u, "http://"):
+		u = "ws://" + strings.TrimPrefix(u, "http://")
+	}
+	return u + "/api/v4/websocket"
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.serverURL+"/api/v4"+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(data, apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		}
+		apiErr.StatusCode = resp.StatusCode
+		return apiErr
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
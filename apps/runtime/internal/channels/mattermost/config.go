@@ -0,0 +1,342 @@
+package mattermost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultConfigDir  = ".pryx/config"
+	defaultConfigFile = "mattermost.json"
+)
+
+// Config represents a Mattermost bot configuration
+type Config struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	ServerURL       string    `json:"server_url"`
+	TokenRef        string    `json:"token_ref"`
+	Token           string    `json:"token,omitempty"`
+	AllowedChannels []string  `json:"allowed_channels"`
+	Enabled         bool      `json:"enabled"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if c.ID == "" {
+		return fmt.Errorf("config ID is required")
+	}
+
+	if c.Name == "" {
+		return fmt.Errorf("config name is required")
+	}
+
+	if c.ServerURL == "" {
+		return fmt.Errorf("server URL is required")
+	}
+	u, err := url.Parse(c.ServerURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("server URL must be an http(s) URL: %q", c.ServerURL)
+	}
+
+	if c.TokenRef == "" {
+		return fmt.Errorf("token reference is required")
+	}
+
+	return nil
+}
+
+// IsChannelAllowed checks if a channel ID is in the whitelist
+func (c *Config) IsChannelAllowed(channelID string) bool {
+	return channelAllowed(c.AllowedChannels, channelID)
+}
+
+func channelAllowed(allowed []string, channelID string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, id := range allowed {
+		if id == channelID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetDefaults sets default values for optional fields
+func (c *Config) SetDefaults() {
+	c.ServerURL = strings.TrimRight(c.ServerURL, "/")
+	if c.AllowedChannels == nil {
+		c.AllowedChannels = []string{}
+	}
+}
+
+// ConfigManager manages Mattermost bot configurations
+type ConfigManager struct {
+	configPath string
+}
+
+// NewConfigManager creates a new config manager
+func NewConfigManager() *ConfigManager {
+	home, _ := os.UserHomeDir()
+	return &ConfigManager{
+		configPath: filepath.Join(home, defaultConfigDir, defaultConfigFile),
+	}
+}
+
+// NewConfigManagerWithPath creates a config manager with a custom path
+func NewConfigManagerWithPath(path string) *ConfigManager {
+	return &ConfigManager{
+		configPath: path,
+	}
+}
+
+// LoadAll loads all Mattermost configurations
+func (cm *ConfigManager) LoadAll() ([]Config, error) {
+	data, err := os.ReadFile(cm.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var configs []Config
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	for i := range configs {
+		configs[i].SetDefaults()
+	}
+
+	return configs, nil
+}
+
+// SaveAll saves all Mattermost configurations. Resolved tokens are not
+// written; only their references are.
+func (cm *ConfigManager) SaveAll(configs []Config) error {
+	dir := filepath.Dir(cm.configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	configsToSave := make([]Config, len(configs))
+	for i, config := range configs {
+		configsToSave[i] = config
+		configsToSave[i].Token = ""
+	}
+
+	data, err := json.MarshalIndent(configsToSave, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(cm.configPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves a configuration by ID
+func (cm *ConfigManager) Get(id string) (*Config, error) {
+	configs, err := cm.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, config := range configs {
+		if config.ID == id {
+			return &config, nil
+		}
+	}
+
+	return nil, fmt.Errorf("mattermost config not found: %s", id)
+}
+
+// Save saves a single configuration (creates or updates)
+func (cm *ConfigManager) Save(config Config) error {
+	configs, err := cm.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	config.UpdatedAt = now
+
+	found := false
+	for i, c := range configs {
+		if c.ID == config.ID {
+			config.CreatedAt = c.CreatedAt
+			configs[i] = config
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		config.CreatedAt = now
+		configs = append(configs, config)
+	}
+
+	return cm.SaveAll(configs)
+}
+
+// Delete removes a configuration by ID
+func (cm *ConfigManager) Delete(id string) error {
+	configs, err := cm.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	filtered := make([]Config, 0, len(configs))
+	found := false
+	for _, config := range configs {
+		if config.ID != id {
+			filtered = append(filtered, config)
+		} else {
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("mattermost config not found: %s", id)
+	}
+
+	return cm.SaveAll(filtered)
+}
+
+// Create creates a new configuration with generated ID
+func (cm *ConfigManager) Create(config Config) (*Config, error) {
+	if config.ID == "" {
+		config.ID = generateID()
+	}
+
+	config.SetDefaults()
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	now := time.Now()
+	config.CreatedAt = now
+	config.UpdatedAt = now
+
+	if err := cm.Save(config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Update updates an existing configuration
+func (cm *ConfigManager) Update(id string, updates map[string]interface{}) (*Config, error) {
+	config, err := cm.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if name, ok := updates["name"].(string); ok {
+		config.Name = name
+	}
+	if serverURL, ok := updates["server_url"].(string); ok {
+		config.ServerURL = serverURL
+	}
+	if tokenRef, ok := updates["token_ref"].(string); ok {
+		config.TokenRef = tokenRef
+	}
+	if allowedChannels, ok := stringList(updates["allowed_channels"]); ok {
+		config.AllowedChannels = allowedChannels
+	}
+	if enabled, ok := updates["enabled"].(bool); ok {
+		config.Enabled = enabled
+	}
+
+	config.SetDefaults()
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	config.UpdatedAt = time.Now()
+
+	if err := cm.Save(*config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// List returns all configurations
+func (cm *ConfigManager) List() ([]Config, error) {
+	return cm.LoadAll()
+}
+
+// ListEnabled returns only enabled configurations
+func (cm *ConfigManager) ListEnabled() ([]Config, error) {
+	configs, err := cm.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	enabled := make([]Config, 0)
+	for _, config := range configs {
+		if config.Enabled {
+			enabled = append(enabled, config)
+		}
+	}
+
+	return enabled, nil
+}
+
+// stringList accepts a []string or the []interface{} a decoded JSON body holds.
+func stringList(v interface{}) ([]string, bool) {
+	switch list := v.(type) {
+	case []string:
+		return list, true
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// generateID generates a unique ID for a configuration
+func generateID() string {
+	return fmt.Sprintf("mattermost-%d", time.Now().UnixNano())
+}
+
+// DefaultConfig returns a default configuration
+func DefaultConfig() Config {
+	return Config{
+		AllowedChannels: []string{},
+		Enabled:         true,
+	}
+}
+
+// NewBotConfig creates a new bot configuration with basic settings
+func NewBotConfig(name, serverURL, tokenRef string) Config {
+	config := DefaultConfig()
+	config.Name = name
+	config.ServerURL = serverURL
+	config.TokenRef = tokenRef
+	return config
+}
@@ -0,0 +1,245 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+
+	"nhooyr.io/websocket"
+)
+
+const (
+	wsReadLimit       = 1 << 20
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// wsEvent is a message received on the Mattermost event websocket
+type wsEvent struct {
+	Event string                 `json:"event"`
+	Data  map[string]interface{} `json:"data"`
+	Seq   int64                  `json:"seq"`
+}
+
+// MattermostChannel connects a Mattermost bot account through the REST API
+// for sending and the event websocket for receiving.
+type MattermostChannel struct {
+	id              string
+	client          *Client
+	allowedChannels []string
+	eventBus        *bus.Bus
+
+	mu     sync.RWMutex
+	botID  string
+	cancel context.CancelFunc
+	status channels.Status
+}
+
+func NewMattermostChannel(id, serverURL, token string, allowedChannels []string, eventBus *bus.Bus) *MattermostChannel {
+	return &MattermostChannel{
+		id:              id,
+		client:          NewClient(serverURL, token),
+		allowedChannels: allowedChannels,
+		eventBus:        eventBus,
+		status:          channels.StatusDisconnected,
+	}
+}
+
+func (m *MattermostChannel) ID() string {
+	return m.id
+}
+
+func (m *MattermostChannel) Type() string {
+	return "mattermost"
+}
+
+func (m *MattermostChannel) Connect(ctx context.Context) error {
+	m.setStatus(channels.StatusConnecting)
+
+	// Test auth and learn our own user ID so we can ignore our own posts
+	me, err := m.client.GetMe(ctx)
+	if err != nil {
+		m.setStatus(channels.StatusError)
+		return fmt.Errorf("mattermost auth failed: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m.mu.Lock()
+	m.botID = me.ID
+	m.cancel = cancel
+	m.mu.Unlock()
+
+	go m.runWebSocket(ctx)
+
+	return nil
+}
+
+func (m *MattermostChannel) Disconnect(ctx context.Context) error {
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.status = channels.StatusDisconnected
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *MattermostChannel) Send(ctx context.Context, msg channels.Message) error {
+	m.mu.RLock()
+	connected := m.cancel != nil
+	m.mu.RUnlock()
+	if !connected {
+		return fmt.Errorf("mattermost client not connected")
+	}
+
+	_, err := m.client.CreatePost(ctx, Post{
+		ChannelID: msg.ChannelID,
+		RootID:    msg.Metadata["root_id"],
+		Message:   msg.Content,
+	})
+	return err
+}
+
+func (m *MattermostChannel) Status() channels.Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.status
+}
+
+func (m *MattermostChannel) setStatus(status channels.Status) {
+	m.mu.Lock()
+	m.status = status
+	m.mu.Unlock()
+}
+
+// runWebSocket keeps the event websocket open until ctx is cancelled,
+// reconnecting with exponential backoff when it drops.
+func (m *MattermostChannel) runWebSocket(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		connected, err := m.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = minReconnectDelay
+		}
+		m.setStatus(channels.StatusConnecting)
+		log.Printf("Mattermost websocket for %s closed: %v; reconnecting in %s", m.id, err, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// listen opens one websocket session and dispatches its events until it
+// fails. It reports whether the session got as far as authenticating.
+func (m *MattermostChannel) listen(ctx context.Context) (bool, error) {
+	conn, _, err := websocket.Dial(ctx, m.client.WebSocketURL(), &websocket.DialOptions{
+		HTTPHeader: http.Header{"Authorization": []string{"Bearer " + m.client.token}},
+	})
+	if err != nil {
+		m.setStatus(channels.StatusError)
+		return false, fmt.Errorf("dial failed: %w", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "disconnecting")
+	conn.SetReadLimit(wsReadLimit)
+
+	challenge, _ := json.Marshal(map[string]interface{}{
+		"seq":    1,
+		"action": "authentication_challenge",
+		"data":   map[string]string{"token": m.client.token},
+	})
+	if err := conn.Write(ctx, websocket.MessageText, challenge); err != nil {
+		m.setStatus(channels.StatusError)
+		return false, fmt.Errorf("authentication failed: %w", err)
+	}
+	m.setStatus(channels.StatusConnected)
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		var event wsEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			continue
+		}
+		if event.Event == "posted" {
+			m.handlePosted(event)
+		}
+	}
+}
+
+// handlePosted publishes a new post as an inbound channel message. The post
+// itself arrives JSON-encoded inside the event data.
+func (m *MattermostChannel) handlePosted(event wsEvent) {
+	raw, _ := event.Data["post"].(string)
+	var post Post
+	if err := json.Unmarshal([]byte(raw), &post); err != nil {
+		return
+	}
+
+	// Ignore our own posts and system messages
+	m.mu.RLock()
+	botID := m.botID
+	m.mu.RUnlock()
+	if post.UserID == botID || post.Type != "" || post.Message == "" {
+		return
+	}
+
+	if !channelAllowed(m.allowedChannels, post.ChannelID) {
+		return
+	}
+
+	metadata := map[string]string{
+		"channel": post.ChannelID,
+	}
+	// Replies go to the thread the post started or belongs to
+	if post.RootID != "" {
+		metadata["root_id"] = post.RootID
+	} else {
+		metadata["root_id"] = post.ID
+	}
+	if channelType, ok := event.Data["channel_type"].(string); ok {
+		metadata["channel_type"] = channelType
+	}
+	if senderName, ok := event.Data["sender_name"].(string); ok {
+		metadata["sender_name"] = senderName
+	}
+
+	createdAt := time.Now()
+	if post.CreateAt > 0 {
+		createdAt = time.UnixMilli(post.CreateAt)
+	}
+
+	msg := channels.Message{
+		ID:        post.ID,
+		Content:   post.Message,
+		Source:    m.id,
+		ChannelID: post.ChannelID,
+		SenderID:  post.UserID,
+		Metadata:  metadata,
+		CreatedAt: createdAt,
+	}
+
+	if m.eventBus != nil {
+		m.eventBus.Publish(bus.NewEvent(bus.EventChannelMessage, "", msg))
+	}
+}
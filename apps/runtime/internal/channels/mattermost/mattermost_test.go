@@ -0,0 +1,272 @@
+package mattermost
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+
+	"nhooyr.io/websocket"
+)
+
+// fakeServer is a minimal Mattermost server: users/me, posts and the event
+// websocket, which sends each post written to events.
+type fakeServer struct {
+	events chan Post
+
+	mu    sync.Mutex
+	posts []Post
+	auth  string
+}
+
+func newFakeServer(t *testing.T) (*httptest.Server, *fakeServer) {
+	fs := &fakeServer{events: make(chan Post, 4)}
+	srv := httptest.NewServer(fs)
+	t.Cleanup(srv.Close)
+	return srv, fs
+}
+
+func (f *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": "api.context.session_expired.app_error", "message": "Invalid or expired session"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/v4/users/me":
+		json.NewEncoder(w).Encode(User{ID: "bot-user", Username: "pryx"})
+	case "/api/v4/posts":
+		var p Post
+		json.NewDecoder(r.Body).Decode(&p)
+		f.mu.Lock()
+		f.posts = append(f.posts, p)
+		f.mu.Unlock()
+		p.ID = "created-post"
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(p)
+	case "/api/v4/websocket":
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close(websocket.StatusNormalClosure, "")
+		ctx := r.Context()
+
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return
+		}
+		var challenge struct {
+			Action string            `json:"action"`
+			Data   map[string]string `json:"data"`
+		}
+		json.Unmarshal(data, &challenge)
+		f.mu.Lock()
+		f.auth = challenge.Action + ":" + challenge.Data["token"]
+		f.mu.Unlock()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case p := <-f.events:
+				raw, _ := json.Marshal(p)
+				event, _ := json.Marshal(map[string]interface{}{
+					"event": "posted",
+					"data": map[string]interface{}{
+						"post":         string(raw),
+						"channel_type": "D",
+						"sender_name":  "@alice",
+					},
+				})
+				if err := conn.Write(ctx, websocket.MessageText, event); err != nil {
+					return
+				}
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestMattermostChannel_ReceiveAndSend(t *testing.T) {
+	srv, fake := newFakeServer(t)
+	b := bus.New()
+	inbound, unsub := b.Subscribe(bus.EventChannelMessage)
+	defer unsub()
+
+	ch := NewMattermostChannel("mattermost-test", srv.URL, "test-token", []string{"town-square", "dm"}, b)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ch.Connect(ctx); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer ch.Disconnect(context.Background())
+
+	// The bot's own post, a disallowed channel and a system message are skipped
+	fake.events <- Post{ID: "p0", ChannelID: "dm", UserID: "bot-user", Message: "echo"}
+	fake.events <- Post{ID: "p1", ChannelID: "off-topic", UserID: "alice", Message: "elsewhere"}
+	fake.events <- Post{ID: "p2", ChannelID: "dm", UserID: "alice", Type: "system_join_channel", Message: "joined"}
+	fake.events <- Post{ID: "p3", ChannelID: "dm", UserID: "alice", Message: "hello", CreateAt: 1700000000000}
+
+	var msg channels.Message
+	select {
+	case evt := <-inbound:
+		msg = evt.Payload.(channels.Message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for inbound message")
+	}
+	if msg.ID != "p3" || msg.Content != "hello" || msg.Source != "mattermost-test" || msg.ChannelID != "dm" || msg.SenderID != "alice" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if msg.Metadata["root_id"] != "p3" || msg.Metadata["channel_type"] != "D" || msg.Metadata["sender_name"] != "@alice" {
+		t.Errorf("unexpected metadata: %v", msg.Metadata)
+	}
+	if !msg.CreatedAt.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("CreatedAt = %v", msg.CreatedAt)
+	}
+	if ch.Status() != channels.StatusConnected {
+		t.Errorf("Status = %s, want connected", ch.Status())
+	}
+
+	fake.mu.Lock()
+	auth := fake.auth
+	fake.mu.Unlock()
+	if auth != "authentication_challenge:test-token" {
+		t.Errorf("websocket auth = %q", auth)
+	}
+
+	// Replies land in the original thread
+	err := ch.Send(ctx, channels.Message{
+		Source:    "mattermost-test",
+		ChannelID: "dm",
+		Content:   "hi there",
+		Metadata:  map[string]string{"root_id": "p3"},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	fake.mu.Lock()
+	posts := append([]Post(nil), fake.posts...)
+	fake.mu.Unlock()
+	if len(posts) != 1 || posts[0].ChannelID != "dm" || posts[0].Message != "hi there" || posts[0].RootID != "p3" {
+		t.Errorf("unexpected posts: %+v", posts)
+	}
+}
+
+func TestMattermostChannel_OutboundMapPayload(t *testing.T) {
+	srv, fake := newFakeServer(t)
+	b := bus.New()
+	mgr := channels.NewManager(b)
+	defer mgr.Shutdown()
+
+	ch := NewMattermostChannel("mattermost-test", srv.URL, "test-token", nil, b)
+	if err := mgr.Register(ch); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for ch.Status() != channels.StatusConnected {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the channel to connect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The agent publishes replies as maps rather than channels.Message
+	b.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", map[string]interface{}{
+		"source":     "mattermost-test",
+		"channel_id": "town-square",
+		"content":    "from the agent",
+	}))
+	for {
+		fake.mu.Lock()
+		posts := append([]Post(nil), fake.posts...)
+		fake.mu.Unlock()
+		if len(posts) == 1 {
+			if posts[0].ChannelID != "town-square" || posts[0].Message != "from the agent" {
+				t.Errorf("unexpected post: %+v", posts[0])
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected one post, got %+v", posts)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMattermostChannel_ConnectAuthFailure(t *testing.T) {
+	srv, _ := newFakeServer(t)
+
+	ch := NewMattermostChannel("mattermost-test", srv.URL, "wrong-token", nil, nil)
+	err := ch.Connect(context.Background())
+	if err == nil {
+		t.Fatal("expected auth error")
+	}
+	if ch.Status() != channels.StatusError {
+		t.Errorf("Status = %s, want error", ch.Status())
+	}
+	if err := ch.Send(context.Background(), channels.Message{ChannelID: "dm", Content: "x"}); err == nil {
+		t.Error("expected Send to fail when not connected")
+	}
+}
+
+func TestConfigManager(t *testing.T) {
+	cm := NewConfigManagerWithPath(filepath.Join(t.TempDir(), "mattermost.json"))
+
+	if _, err := cm.Create(NewBotConfig("Team", "ftp://chat.example.com", "env:MM_TOKEN")); err == nil {
+		t.Error("expected invalid server URL to be rejected")
+	}
+
+	cfg := NewBotConfig("Team", "https://chat.example.com/", "env:MM_TOKEN")
+	cfg.Token = "resolved-secret"
+	created, err := cm.Create(cfg)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ServerURL != "https://chat.example.com" {
+		t.Errorf("ServerURL = %q, want trailing slash trimmed", created.ServerURL)
+	}
+
+	loaded, err := cm.Get(created.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if loaded.Token != "" {
+		t.Error("resolved token should not be persisted")
+	}
+	if !loaded.IsChannelAllowed("anything") {
+		t.Error("empty allow list should allow every channel")
+	}
+
+	updated, err := cm.Update(created.ID, map[string]interface{}{
+		"allowed_channels": []interface{}{"town-square"},
+		"enabled":          false,
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !updated.IsChannelAllowed("town-square") || updated.IsChannelAllowed("off-topic") {
+		t.Errorf("allowed channels = %v", updated.AllowedChannels)
+	}
+
+	enabled, _ := cm.ListEnabled()
+	if len(enabled) != 0 {
+		t.Errorf("ListEnabled = %d configs, want 0", len(enabled))
+	}
+
+	if err := cm.Delete(created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := cm.Delete(created.ID); err == nil {
+		t.Error("expected deleting a missing config to fail")
+	}
+}
@@ -0,0 +1,330 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"pryx-core/internal/bus"
+
+	"github.com/google/uuid"
+)
+
+// Outbound delivery limits. A message is dropped after outboxMaxAttempts
+// failed sends; between attempts the delay doubles from outboxBaseDelay up to
+// outboxMaxDelay unless the provider asks for a specific wait.
+const (
+	DefaultOutboxSize = 100
+	outboxMaxAttempts = 8
+	outboxBaseDelay   = time.Second
+	outboxMaxDelay    = 2 * time.Minute
+	outboxSendTimeout = 30 * time.Second
+	// outboxIdleDelay is how often a queue whose channel is missing or not
+	// connected checks again.
+	outboxIdleDelay = 5 * time.Second
+)
+
+// ErrOutboxFull is returned by Enqueue when a channel's queue is at capacity.
+var ErrOutboxFull = errors.New("outbound queue is full")
+
+// RateLimitError is returned by Channel.Send when the provider rejected a
+// message for rate limiting and said when to try again.
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s: %v", e.RetryAfter, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// OutboxEntry is an outbound message waiting for delivery.
+type OutboxEntry struct {
+	ID          string
+	Message     Message
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+	CreatedAt   time.Time
+}
+
+// OutboxStore persists undelivered outbound messages so they survive a restart.
+type OutboxStore interface {
+	SaveOutbox(entry OutboxEntry) error
+	DeleteOutbox(id string) error
+	LoadOutbox() ([]OutboxEntry, error)
+}
+
+// OutboxStats describes the outbound queue of one channel.
+type OutboxStats struct {
+	Depth     int        `json:"depth"`
+	Capacity  int        `json:"capacity"`
+	Oldest    *time.Time `json:"oldest,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	Dropped   int64      `json:"dropped"`
+}
+
+// outboxQueue holds the pending messages of one channel in delivery order.
+// Its worker sends the head entry and only moves on once it is delivered or
+// dropped, so messages arrive in order.
+type outboxQueue struct {
+	entries   []*OutboxEntry
+	wake      chan struct{}
+	lastError string
+	dropped   int64
+}
+
+// OutboundMessage reads the payload of an EventChannelOutboundMessage, which
+// is either a Message or a map with source, channel_id and content.
+func OutboundMessage(payload interface{}) (Message, bool) {
+	switch p := payload.(type) {
+	case Message:
+		return p, true
+	case map[string]interface{}:
+		source, _ := p["source"].(string)
+		channelID, _ := p["channel_id"].(string)
+		content, _ := p["content"].(string)
+		return Message{Source: source, ChannelID: channelID, Content: content}, true
+	}
+	return Message{}, false
+}
+
+// SetOutbox makes the manager persist pending outbound messages to store and
+// queues the messages left over from a previous run. Call it before
+// registering channels.
+func (m *ChannelManager) SetOutbox(store OutboxStore) error {
+	entries, err := store.LoadOutbox()
+	if err != nil {
+		return fmt.Errorf("failed to load outbound queue: %w", err)
+	}
+
+	m.outboxMu.Lock()
+	defer m.outboxMu.Unlock()
+	m.outboxStore = store
+	for i := range entries {
+		entry := entries[i]
+		q := m.queueLocked(entry.Message.Source)
+		q.entries = append(q.entries, &entry)
+	}
+	if len(entries) > 0 {
+		log.Printf("Channels: restored %d undelivered outbound messages", len(entries))
+	}
+	return nil
+}
+
+// Enqueue queues msg for delivery through the channel msg.Source. It returns
+// ErrOutboxFull if that channel already has DefaultOutboxSize messages pending.
+func (m *ChannelManager) Enqueue(msg Message) error {
+	entry := &OutboxEntry{
+		ID:        uuid.NewString(),
+		Message:   msg,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	m.outboxMu.Lock()
+	q := m.queueLocked(msg.Source)
+	if len(q.entries) >= DefaultOutboxSize {
+		q.dropped++
+		m.outboxMu.Unlock()
+		return ErrOutboxFull
+	}
+	q.entries = append(q.entries, entry)
+	store := m.outboxStore
+	m.outboxMu.Unlock()
+
+	if store != nil {
+		if err := store.SaveOutbox(*entry); err != nil {
+			log.Printf("Channels: failed to persist outbound message for %s: %v", msg.Source, err)
+		}
+	}
+	m.wakeQueue(q)
+	return nil
+}
+
+// OutboxStats returns the state of the outbound queue of channel id.
+func (m *ChannelManager) OutboxStats(id string) OutboxStats {
+	m.outboxMu.Lock()
+	defer m.outboxMu.Unlock()
+
+	stats := OutboxStats{Capacity: DefaultOutboxSize}
+	q, ok := m.queues[id]
+	if !ok {
+		return stats
+	}
+	stats.Depth = len(q.entries)
+	stats.LastError = q.lastError
+	stats.Dropped = q.dropped
+	if len(q.entries) > 0 {
+		oldest := q.entries[0].CreatedAt
+		stats.Oldest = &oldest
+	}
+	return stats
+}
+
+// queueLocked returns the queue of channel id, creating it and starting its
+// worker if needed. Callers hold m.outboxMu.
+func (m *ChannelManager) queueLocked(id string) *outboxQueue {
+	q, ok := m.queues[id]
+	if !ok {
+		q = &outboxQueue{wake: make(chan struct{}, 1)}
+		m.queues[id] = q
+		go m.runOutbox(id, q)
+	}
+	return q
+}
+
+func (m *ChannelManager) wakeQueue(q *outboxQueue) {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatchOutbound queues the outbound bus messages addressed to registered
+// channels until the manager shuts down.
+func (m *ChannelManager) dispatchOutbound(events <-chan bus.Event, unsub func()) {
+	defer unsub()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			msg, ok := OutboundMessage(evt.Payload)
+			if !ok || msg.Content == "" {
+				continue
+			}
+			if _, registered := m.Get(msg.Source); !registered {
+				continue
+			}
+			if err := m.Enqueue(msg); err != nil {
+				m.errors.Emit("", map[string]interface{}{
+					"channel_id": msg.Source,
+					"error":      fmt.Sprintf("dropped outbound message: %v", err),
+				})
+			}
+		}
+	}
+}
+
+// runOutbox delivers the entries of q in order until the manager shuts down.
+func (m *ChannelManager) runOutbox(id string, q *outboxQueue) {
+	for {
+		wait := m.deliverNext(id, q)
+		if wait == 0 {
+			continue
+		}
+
+		var timer *time.Timer
+		var fired <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			fired = timer.C
+		}
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-q.wake:
+		case <-fired:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// deliverNext tries to send the head entry of q. It returns how long to wait
+// before the next attempt: zero to continue right away, or a negative value
+// to wait until the queue is woken.
+func (m *ChannelManager) deliverNext(id string, q *outboxQueue) time.Duration {
+	m.outboxMu.Lock()
+	if len(q.entries) == 0 {
+		m.outboxMu.Unlock()
+		return -1
+	}
+	entry := q.entries[0]
+	m.outboxMu.Unlock()
+
+	if wait := time.Until(entry.NextAttempt); wait > 0 {
+		return wait
+	}
+	c, ok := m.Get(id)
+	if !ok || c.Status() != StatusConnected {
+		return outboxIdleDelay
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, outboxSendTimeout)
+	err := c.Send(ctx, entry.Message)
+	cancel()
+	if m.ctx.Err() != nil {
+		return outboxIdleDelay
+	}
+
+	m.outboxMu.Lock()
+	store := m.outboxStore
+	if err == nil {
+		q.entries = q.entries[1:]
+		m.outboxMu.Unlock()
+		m.forgetOutbox(store, entry)
+		m.recordActivity(ActivityOutbound, entry.Message)
+		return 0
+	}
+
+	entry.Attempts++
+	entry.LastError = err.Error()
+	q.lastError = entry.LastError
+	if entry.Attempts >= outboxMaxAttempts {
+		q.entries = q.entries[1:]
+		q.dropped++
+		m.outboxMu.Unlock()
+		m.forgetOutbox(store, entry)
+		m.recordActivity(ActivityDropped, entry.Message)
+		m.errors.Emit("", map[string]interface{}{
+			"channel_id": id,
+			"error":      fmt.Sprintf("dropped outbound message after %d attempts: %v", entry.Attempts, err),
+		})
+		return 0
+	}
+	delay := outboxRetryDelay(entry.Attempts, err)
+	entry.NextAttempt = time.Now().Add(delay)
+	saved := *entry
+	m.outboxMu.Unlock()
+
+	if store != nil {
+		if err := store.SaveOutbox(saved); err != nil {
+			log.Printf("Channels: failed to persist outbound message for %s: %v", id, err)
+		}
+	}
+	return delay
+}
+
+func (m *ChannelManager) forgetOutbox(store OutboxStore, entry *OutboxEntry) {
+	if store == nil {
+		return
+	}
+	if err := store.DeleteOutbox(entry.ID); err != nil {
+		log.Printf("Channels: failed to remove delivered outbound message %s: %v", entry.ID, err)
+	}
+}
+
+// outboxRetryDelay returns the wait before retrying a send that failed for
+// the given attempt, honoring the provider's requested delay if it gave one.
+func outboxRetryDelay(attempt int, err error) time.Duration {
+	var rateLimited *RateLimitError
+	if errors.As(err, &rateLimited) && rateLimited.RetryAfter > 0 {
+		return rateLimited.RetryAfter
+	}
+	delay := outboxBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > outboxMaxDelay {
+		delay = outboxMaxDelay
+	}
+	return delay
+}
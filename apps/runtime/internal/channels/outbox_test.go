@@ -0,0 +1,212 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+)
+
+// sendingChannel records delivered messages and fails the first sends with
+// the queued errors.
+type sendingChannel struct {
+	mockChannel
+	sendMu   sync.Mutex
+	failures []error
+	sent     []Message
+}
+
+func (c *sendingChannel) Send(ctx context.Context, msg Message) error {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if len(c.failures) > 0 {
+		err := c.failures[0]
+		c.failures = c.failures[1:]
+		return err
+	}
+	c.sent = append(c.sent, msg)
+	return nil
+}
+
+func (c *sendingChannel) Sent() []Message {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	return append([]Message(nil), c.sent...)
+}
+
+type memoryOutbox struct {
+	mu      sync.Mutex
+	entries map[string]OutboxEntry
+	order   []string
+}
+
+func newMemoryOutbox(entries ...OutboxEntry) *memoryOutbox {
+	o := &memoryOutbox{entries: make(map[string]OutboxEntry)}
+	for _, e := range entries {
+		_ = o.SaveOutbox(e)
+	}
+	return o
+}
+
+func (o *memoryOutbox) SaveOutbox(entry OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.entries[entry.ID]; !ok {
+		o.order = append(o.order, entry.ID)
+	}
+	o.entries[entry.ID] = entry
+	return nil
+}
+
+func (o *memoryOutbox) DeleteOutbox(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.entries, id)
+	return nil
+}
+
+func (o *memoryOutbox) LoadOutbox() ([]OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var entries []OutboxEntry
+	for _, id := range o.order {
+		if e, ok := o.entries[id]; ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (o *memoryOutbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.entries)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestOutbox_RetriesInOrder(t *testing.T) {
+	b := bus.New()
+	m := NewManager(b)
+	defer m.Shutdown()
+	store := newMemoryOutbox()
+	if err := m.SetOutbox(store); err != nil {
+		t.Fatalf("SetOutbox failed: %v", err)
+	}
+
+	c := &sendingChannel{
+		mockChannel: mockChannel{id: "telegram-main", status: StatusConnected},
+		failures:    []error{&RateLimitError{RetryAfter: 20 * time.Millisecond, Err: errors.New("too many requests")}},
+	}
+	if err := m.Register(c); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	// Map payloads from the agent and Message payloads are both delivered
+	b.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", map[string]interface{}{
+		"source":     "telegram-main",
+		"channel_id": "42",
+		"content":    "first",
+	}))
+	b.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", Message{Source: "telegram-main", ChannelID: "42", Content: "second"}))
+	// Messages for unknown channels are ignored
+	b.Publish(bus.NewEvent(bus.EventChannelOutboundMessage, "", Message{Source: "unknown", ChannelID: "42", Content: "lost"}))
+
+	waitFor(t, "delivery", func() bool { return len(c.Sent()) == 2 })
+	sent := c.Sent()
+	if sent[0].Content != "first" || sent[1].Content != "second" {
+		t.Fatalf("expected messages in order, got %+v", sent)
+	}
+	waitFor(t, "the store to be emptied", func() bool { return store.Len() == 0 })
+
+	stats := m.OutboxStats("telegram-main")
+	if stats.Depth != 0 || stats.Capacity != DefaultOutboxSize || stats.Oldest != nil {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if stats.LastError == "" {
+		t.Error("expected the rate limit error to be reported")
+	}
+	m.outboxMu.Lock()
+	_, queued := m.queues["unknown"]
+	m.outboxMu.Unlock()
+	if queued {
+		t.Error("expected no queue for an unregistered channel")
+	}
+}
+
+func TestOutbox_RestoresPersistedMessages(t *testing.T) {
+	m := NewManager(bus.New())
+	defer m.Shutdown()
+	store := newMemoryOutbox(OutboxEntry{
+		ID:        "left-over",
+		Message:   Message{Source: "slack-main", ChannelID: "C1", Content: "from before the restart"},
+		CreatedAt: time.Now().Add(-time.Minute),
+	})
+	if err := m.SetOutbox(store); err != nil {
+		t.Fatalf("SetOutbox failed: %v", err)
+	}
+	if stats := m.OutboxStats("slack-main"); stats.Depth != 1 || stats.Oldest == nil {
+		t.Fatalf("expected the persisted message to be queued, got %+v", stats)
+	}
+
+	c := &sendingChannel{mockChannel: mockChannel{id: "slack-main", status: StatusConnected}}
+	if err := m.Register(c); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	m.outboxMu.Lock()
+	q := m.queues["slack-main"]
+	m.outboxMu.Unlock()
+	m.wakeQueue(q)
+
+	waitFor(t, "delivery", func() bool { return len(c.Sent()) == 1 })
+	waitFor(t, "the store to be emptied", func() bool { return store.Len() == 0 })
+}
+
+func TestOutbox_Full(t *testing.T) {
+	m := NewManager(nil)
+	defer m.Shutdown()
+
+	// Nothing is registered, so the queue only fills up
+	for i := 0; i < DefaultOutboxSize; i++ {
+		if err := m.Enqueue(Message{Source: "webhook-main", Content: "ping"}); err != nil {
+			t.Fatalf("Enqueue %d failed: %v", i, err)
+		}
+	}
+	if err := m.Enqueue(Message{Source: "webhook-main", Content: "ping"}); !errors.Is(err, ErrOutboxFull) {
+		t.Fatalf("expected ErrOutboxFull, got %v", err)
+	}
+	stats := m.OutboxStats("webhook-main")
+	if stats.Depth != DefaultOutboxSize || stats.Dropped != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		err     error
+		want    time.Duration
+	}{
+		{1, errors.New("boom"), time.Second},
+		{3, errors.New("boom"), 4 * time.Second},
+		{20, errors.New("boom"), outboxMaxDelay},
+		{2, &RateLimitError{RetryAfter: 7 * time.Second, Err: errors.New("429")}, 7 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := outboxRetryDelay(tt.attempt, tt.err); got != tt.want {
+			t.Errorf("outboxRetryDelay(%d, %v) = %s, want %s", tt.attempt, tt.err, got, tt.want)
+		}
+	}
+}
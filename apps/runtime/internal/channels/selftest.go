@@ -0,0 +1,113 @@
+package channels
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"pryx-core/internal/bus"
+
+	"github.com/google/uuid"
+)
+
+// selfTestMarker starts the content of self-test messages, so their echoes can
+// be recognized and kept away from the agent.
+const selfTestMarker = "[pryx-selftest "
+
+// DefaultSelfTestTimeout bounds the wait for a self-test echo.
+const DefaultSelfTestTimeout = 10 * time.Second
+
+// SelfTestOptions describes a channel self-test.
+type SelfTestOptions struct {
+	// ChatID is the destination chat or channel.
+	ChatID string
+	// ExpectEcho waits for the message to come back as an inbound message.
+	ExpectEcho bool
+	// Timeout bounds the wait for the echo; zero uses DefaultSelfTestTimeout.
+	Timeout time.Duration
+}
+
+// SelfTestResult reports a channel self-test.
+type SelfTestResult struct {
+	ChannelID     string `json:"channel_id"`
+	ChatID        string `json:"chat_id"`
+	OK            bool   `json:"ok"`
+	Sent          bool   `json:"sent"`
+	SendLatencyMS int64  `json:"send_latency_ms"`
+	EchoExpected  bool   `json:"echo_expected"`
+	EchoReceived  bool   `json:"echo_received"`
+	EchoLatencyMS int64  `json:"echo_latency_ms,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// IsSelfTestMessage reports whether content is a self-test message.
+func IsSelfTestMessage(content string) bool {
+	return strings.HasPrefix(content, selfTestMarker)
+}
+
+// RunSelfTest sends a test message through c to opts.ChatID and, if
+// opts.ExpectEcho is set, waits for it to arrive back on b as a channel
+// message from c.
+func RunSelfTest(ctx context.Context, b *bus.Bus, c Channel, opts SelfTestOptions) SelfTestResult {
+	res := SelfTestResult{ChannelID: c.ID(), ChatID: opts.ChatID, EchoExpected: opts.ExpectEcho}
+	if status := c.Status(); status != StatusConnected {
+		res.Error = fmt.Sprintf("channel is %s", status)
+		return res
+	}
+
+	token := uuid.NewString()
+	msg := Message{
+		ID:        token,
+		Content:   selfTestMarker + token + "] Pryx channel self-test",
+		Source:    c.ID(),
+		ChannelID: opts.ChatID,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	// Subscribe before sending so a fast echo is not missed
+	var inbound <-chan bus.Event
+	if opts.ExpectEcho {
+		events, cancel := b.Subscribe(bus.EventChannelMessage)
+		defer cancel()
+		inbound = events
+	}
+
+	start := time.Now()
+	if err := c.Send(ctx, msg); err != nil {
+		res.Error = "send failed: " + err.Error()
+		return res
+	}
+	res.Sent = true
+	res.SendLatencyMS = time.Since(start).Milliseconds()
+	if !opts.ExpectEcho {
+		res.OK = true
+		return res
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSelfTestTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case evt := <-inbound:
+			in, ok := evt.Payload.(Message)
+			if !ok || in.Source != c.ID() || !strings.Contains(in.Content, token) {
+				continue
+			}
+			res.EchoReceived = true
+			res.EchoLatencyMS = time.Since(start).Milliseconds()
+			res.OK = true
+			return res
+		case <-timer.C:
+			res.Error = fmt.Sprintf("no echo within %s", timeout)
+			return res
+		case <-ctx.Done():
+			res.Error = ctx.Err().Error()
+			return res
+		}
+	}
+}
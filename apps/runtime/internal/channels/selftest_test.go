@@ -0,0 +1,71 @@
+package channels
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+)
+
+// loopbackChannel delivers what it sends back to the bus as an inbound message
+// when echo is set, like a webhook pointed at itself.
+type loopbackChannel struct {
+	mockChannel
+	bus     *bus.Bus
+	echo    bool
+	sendErr error
+	sent    []Message
+}
+
+func (c *loopbackChannel) Send(ctx context.Context, msg Message) error {
+	if c.sendErr != nil {
+		return c.sendErr
+	}
+	c.sent = append(c.sent, msg)
+	if c.echo {
+		c.bus.Publish(bus.NewEvent(bus.EventChannelMessage, "", Message{
+			ID:        "echo-" + msg.ID,
+			Content:   msg.Content,
+			Source:    c.id,
+			ChannelID: msg.ChannelID,
+		}))
+	}
+	return nil
+}
+
+func TestRunSelfTest(t *testing.T) {
+	b := bus.New()
+	ctx := context.Background()
+
+	c := &loopbackChannel{mockChannel: mockChannel{id: "hook", status: StatusConnected}, bus: b, echo: true}
+	res := RunSelfTest(ctx, b, c, SelfTestOptions{ChatID: "chat-1", ExpectEcho: true, Timeout: time.Second})
+	if !res.OK || !res.Sent || !res.EchoReceived {
+		t.Fatalf("expected successful round trip, got %+v", res)
+	}
+	if len(c.sent) != 1 || c.sent[0].ChannelID != "chat-1" || !IsSelfTestMessage(c.sent[0].Content) {
+		t.Fatalf("unexpected sent messages: %+v", c.sent)
+	}
+
+	// Sending alone is enough when no echo is expected
+	c.echo = false
+	if res := RunSelfTest(ctx, b, c, SelfTestOptions{ChatID: "chat-1"}); !res.OK || res.EchoExpected {
+		t.Fatalf("expected send-only success, got %+v", res)
+	}
+
+	res = RunSelfTest(ctx, b, c, SelfTestOptions{ChatID: "chat-1", ExpectEcho: true, Timeout: 20 * time.Millisecond})
+	if res.OK || !res.Sent || res.EchoReceived || res.Error == "" {
+		t.Fatalf("expected echo timeout, got %+v", res)
+	}
+
+	c.sendErr = errors.New("chat not found")
+	if res := RunSelfTest(ctx, b, c, SelfTestOptions{ChatID: "chat-1"}); res.OK || res.Sent {
+		t.Fatalf("expected send failure, got %+v", res)
+	}
+
+	c.status = StatusDisconnected
+	if res := RunSelfTest(ctx, b, c, SelfTestOptions{ChatID: "chat-1"}); res.OK || res.Sent {
+		t.Fatalf("expected disconnected channel to fail, got %+v", res)
+	}
+}
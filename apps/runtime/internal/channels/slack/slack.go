@@ -2,6 +2,7 @@ package slack
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -44,7 +45,10 @@ func (s *SlackChannel) Type() string {
 
 func (s *SlackChannel) Connect(ctx context.Context) error {
 	// Create Slack client
-	client := slack.New(s.botToken, slack.OptionAppLevelToken(s.appToken))
+	client := slack.New(s.botToken,
+		slack.OptionAppLevelToken(s.appToken),
+		slack.OptionHTTPClient(channels.NewPlatformHTTPClient("slack", 0)),
+	)
 
 	// Test auth
 	_, err := client.AuthTest()
@@ -70,12 +74,6 @@ func (s *SlackChannel) Connect(ctx context.Context) error {
 	// Start socket mode in background
 	go s.runSocketMode(ctx)
 
-	// Subscribe to outbound messages
-	if s.eventBus != nil {
-		outbound, unsub := s.eventBus.Subscribe(bus.EventChannelOutboundMessage)
-		go s.handleOutbound(ctx, outbound, unsub)
-	}
-
 	return nil
 }
 
@@ -98,6 +96,10 @@ func (s *SlackChannel) Send(ctx context.Context, msg channels.Message) error {
 		msg.ChannelID,
 		slack.MsgOptionText(msg.Content, false),
 	)
+	var rateLimited *slack.RateLimitedError
+	if errors.As(err, &rateLimited) {
+		return &channels.RateLimitError{RetryAfter: rateLimited.RetryAfter, Err: err}
+	}
 	return err
 }
 
@@ -151,10 +153,7 @@ func (s *SlackChannel) handleEventsAPI(event slackevents.EventsAPIEvent) {
 
 			// Publish to event bus
 			if s.eventBus != nil {
-				s.eventBus.Publish(bus.Event{
-					Type:    bus.EventChannelMessage,
-					Payload: msg,
-				})
+				s.eventBus.Publish(bus.NewEvent(bus.EventChannelMessage, "", msg))
 			}
 		}
 
@@ -174,31 +173,7 @@ func (s *SlackChannel) handleEventsAPI(event slackevents.EventsAPIEvent) {
 			}
 
 			if s.eventBus != nil {
-				s.eventBus.Publish(bus.Event{
-					Type:    bus.EventChannelMessage,
-					Payload: msg,
-				})
-			}
-		}
-	}
-}
-
-func (s *SlackChannel) handleOutbound(ctx context.Context, outbound <-chan bus.Event, unsub func()) {
-	defer unsub()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case event := <-outbound:
-			if msg, ok := event.Payload.(channels.Message); ok {
-				if msg.Source == s.id {
-					// This is our message to send
-					if err := s.Send(ctx, msg); err != nil {
-						// Log error but don't crash
-						fmt.Printf("Slack send error: %v\n", err)
-					}
-				}
+				s.eventBus.Publish(bus.NewEvent(bus.EventChannelMessage, "", msg))
 			}
 		}
 	}
@@ -13,6 +13,8 @@ import (
 	"path/filepath"
 	"strconv"
 	"time"
+
+	"pryx-core/internal/channels"
 )
 
 const (
@@ -90,11 +92,9 @@ func WithBaseURL(baseURL string) ClientOption {
 // NewClient creates a new Telegram Bot API client
 func NewClient(token string, opts ...ClientOption) *Client {
 	c := &Client{
-		token:   token,
-		baseURL: defaultAPIEndpoint + token + "/",
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		token:      token,
+		baseURL:    defaultAPIEndpoint + token + "/",
+		httpClient: channels.NewPlatformHTTPClient("telegram", defaultTimeout),
 	}
 
 	for _, opt := range opts {
@@ -14,6 +14,7 @@ type Poller struct {
 	client   *Client
 	handler  *Handler
 	eventBus *bus.Bus
+	errors   *bus.ErrorEmitter
 	offset   int
 	running  bool
 }
@@ -25,6 +26,7 @@ func NewPoller(config *Config, client *Client, handler *Handler, eventBus *bus.B
 		client:   client,
 		handler:  handler,
 		eventBus: eventBus,
+		errors:   bus.NewErrorEmitter(eventBus, bus.DefaultErrorWindow),
 		offset:   0,
 		running:  false,
 	}
@@ -106,12 +108,8 @@ func (p *Poller) SetOffset(offset int) {
 
 // publishError publishes an error event
 func (p *Poller) publishError(errMsg string) {
-	if p.eventBus == nil {
-		return
-	}
-
-	p.eventBus.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
+	p.errors.Emit("", map[string]interface{}{
 		"channel_id": p.config.ID,
 		"error":      errMsg,
-	}))
+	})
 }
@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -17,6 +18,7 @@ type TelegramChannel struct {
 	token    string
 	bot      *tgbotapi.BotAPI
 	eventBus *bus.Bus
+	errors   *bus.ErrorEmitter
 	cancel   context.CancelFunc
 	status   channels.Status
 }
@@ -26,6 +28,7 @@ func NewTelegramChannel(id, token string, eventBus *bus.Bus) *TelegramChannel {
 		id:       id,
 		token:    token,
 		eventBus: eventBus,
+		errors:   bus.NewErrorEmitter(eventBus, bus.DefaultErrorWindow),
 		status:   channels.StatusDisconnected,
 	}
 }
@@ -39,7 +42,7 @@ func (t *TelegramChannel) Type() string {
 }
 
 func (t *TelegramChannel) Connect(ctx context.Context) error {
-	bot, err := tgbotapi.NewBotAPI(t.token)
+	bot, err := tgbotapi.NewBotAPIWithClient(t.token, tgbotapi.APIEndpoint, channels.NewPlatformHTTPClient("telegram", 0))
 	if err != nil {
 		t.status = channels.StatusError
 		return fmt.Errorf("failed to create bot: %w", err)
@@ -52,12 +55,6 @@ func (t *TelegramChannel) Connect(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
 	t.cancel = cancel
 
-	// Subscribe to outbound messages
-	if t.eventBus != nil {
-		outbound, unsub := t.eventBus.Subscribe(bus.EventChannelOutboundMessage)
-		go t.handleOutbound(ctx, outbound, unsub)
-	}
-
 	// Start polling in background
 	go t.poll(ctx)
 
@@ -85,6 +82,10 @@ func (t *TelegramChannel) Send(ctx context.Context, msg channels.Message) error
 
 	tgMsg := tgbotapi.NewMessage(chatID, msg.Content)
 	_, err = t.bot.Send(tgMsg)
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return &channels.RateLimitError{RetryAfter: time.Duration(apiErr.RetryAfter) * time.Second, Err: err}
+	}
 	return err
 }
 
@@ -108,10 +109,10 @@ func (t *TelegramChannel) poll(ctx context.Context) {
 			updates, err := t.bot.GetUpdates(u)
 			if err != nil {
 				// Log error via bus or status
-				t.eventBus.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
+				t.errors.Emit("", map[string]interface{}{
 					"channel_id": t.id,
 					"error":      fmt.Sprintf("polling error: %v", err),
-				}))
+				})
 
 				// If it's a critical error (e.g. 401 Unauthorized), we should disconnect
 				// For network errors, we simple retry (ticks continue)
@@ -175,34 +176,3 @@ func (t *TelegramChannel) handleMessage(msg *tgbotapi.Message) {
 
 	t.eventBus.Publish(bus.NewEvent(bus.EventChannelMessage, "", channelMsg))
 }
-
-func (t *TelegramChannel) handleOutbound(ctx context.Context, events <-chan bus.Event, unsub func()) {
-	defer unsub()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case evt, ok := <-events:
-			if !ok {
-				return
-			}
-			// Parse payload
-			if payload, ok := evt.Payload.(map[string]interface{}); ok {
-				source, _ := payload["source"].(string)
-				if source != t.id {
-					continue // Not for this channel instance
-				}
-				chatID, _ := payload["channel_id"].(string)
-				content, _ := payload["content"].(string)
-				if chatID != "" && content != "" {
-					// Send message
-					msg := channels.Message{
-						ChannelID: chatID,
-						Content:   content,
-					}
-					_ = t.Send(context.Background(), msg)
-				}
-			}
-		}
-	}
-}
@@ -0,0 +1,290 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"pryx-core/internal/config"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultPlatformLimits follow the documented API limits of each platform.
+// Platforms without an entry are not throttled until they answer 429.
+var DefaultPlatformLimits = map[string]config.ChannelRateLimit{
+	// About 30 messages per second per bot
+	"telegram": {RequestsPerSecond: 30, Burst: 30, MaxRetries: 3},
+	// chat.postMessage allows about one message per second per channel
+	"slack": {RequestsPerSecond: 1, Burst: 5, MaxRetries: 3},
+	// 50 requests per second across the bot
+	"discord": {RequestsPerSecond: 50, Burst: 50, MaxRetries: 3},
+	// Server default of 10 requests per second with a burst of 100
+	"mattermost": {RequestsPerSecond: 10, Burst: 100, MaxRetries: 3},
+}
+
+const (
+	// defaultRetryAfter is the wait after a 429 that did not say how long to wait.
+	defaultRetryAfter = time.Second
+	// maxRetryAfter caps the wait a platform can impose on a retried request.
+	maxRetryAfter = 5 * time.Minute
+	// maxRateLimitBody bounds how much of a 429 body is read for its retry_after.
+	maxRateLimitBody = 64 << 10
+)
+
+// Throttle paces the API requests made to one platform with a token bucket
+// and holds all of them back while the platform has asked to wait.
+type Throttle struct {
+	platform string
+
+	mu           sync.Mutex
+	limit        config.ChannelRateLimit
+	limiter      *rate.Limiter // nil when the platform has no known limit
+	blockedUntil time.Time
+	waiting      int
+	rateLimited  int64
+}
+
+// ThrottleStats describes the throttle state of a platform.
+type ThrottleStats struct {
+	Platform string `json:"platform"`
+	// RequestsPerSecond and Burst are zero for platforms without a known limit.
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+	// Available is the number of requests that can be made right away.
+	Available float64 `json:"available"`
+	// Waiting counts requests held back by the throttle.
+	Waiting int `json:"waiting"`
+	// Throttled is set while requests have to wait.
+	Throttled    bool       `json:"throttled"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+	// RateLimited counts the 429 responses received.
+	RateLimited int64 `json:"rate_limited"`
+}
+
+var platformThrottles = struct {
+	sync.Mutex
+	throttles map[string]*Throttle
+	overrides map[string]config.ChannelRateLimit
+}{throttles: make(map[string]*Throttle)}
+
+// PlatformThrottle returns the throttle shared by all channels of platform.
+func PlatformThrottle(platform string) *Throttle {
+	platformThrottles.Lock()
+	defer platformThrottles.Unlock()
+	t, ok := platformThrottles.throttles[platform]
+	if !ok {
+		t = &Throttle{platform: platform}
+		t.setLimit(platformLimit(platform, platformThrottles.overrides))
+		platformThrottles.throttles[platform] = t
+	}
+	return t
+}
+
+// SetPlatformLimits overrides the default limits of the platforms in limits.
+// Zero fields keep the default. Existing throttles pick up the new limits.
+func SetPlatformLimits(limits map[string]config.ChannelRateLimit) {
+	platformThrottles.Lock()
+	defer platformThrottles.Unlock()
+	platformThrottles.overrides = limits
+	for platform, t := range platformThrottles.throttles {
+		t.setLimit(platformLimit(platform, limits))
+	}
+}
+
+func platformLimit(platform string, overrides map[string]config.ChannelRateLimit) config.ChannelRateLimit {
+	limit := DefaultPlatformLimits[platform]
+	if o, ok := overrides[platform]; ok {
+		if o.RequestsPerSecond > 0 {
+			limit.RequestsPerSecond = o.RequestsPerSecond
+		}
+		if o.Burst > 0 {
+			limit.Burst = o.Burst
+		}
+		if o.MaxRetries > 0 {
+			limit.MaxRetries = o.MaxRetries
+		}
+	}
+	if limit.RequestsPerSecond > 0 && limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return limit
+}
+
+func (t *Throttle) setLimit(limit config.ChannelRateLimit) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limit = limit
+	switch {
+	case limit.RequestsPerSecond <= 0:
+		t.limiter = nil
+	case t.limiter == nil:
+		t.limiter = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), limit.Burst)
+	default:
+		t.limiter.SetLimit(rate.Limit(limit.RequestsPerSecond))
+		t.limiter.SetBurst(limit.Burst)
+	}
+}
+
+// Wait blocks until a request may be made or ctx is done.
+func (t *Throttle) Wait(ctx context.Context) error {
+	t.mu.Lock()
+	t.waiting++
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.waiting--
+		t.mu.Unlock()
+	}()
+
+	for {
+		t.mu.Lock()
+		wait := time.Until(t.blockedUntil)
+		limiter := t.limiter
+		t.mu.Unlock()
+		if wait <= 0 {
+			if limiter == nil {
+				return nil
+			}
+			return limiter.Wait(ctx)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Block holds back all requests for d, as asked by a 429 response.
+func (t *Throttle) Block(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rateLimited++
+	if until := time.Now().Add(d); until.After(t.blockedUntil) {
+		t.blockedUntil = until
+	}
+}
+
+func (t *Throttle) maxRetries() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limit.MaxRetries
+}
+
+// Stats returns the current throttle state.
+func (t *Throttle) Stats() ThrottleStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := ThrottleStats{
+		Platform:    t.platform,
+		Waiting:     t.waiting,
+		RateLimited: t.rateLimited,
+	}
+	if t.limiter != nil {
+		stats.RequestsPerSecond = t.limit.RequestsPerSecond
+		stats.Burst = t.limit.Burst
+		stats.Available = max(t.limiter.Tokens(), 0)
+	}
+	if time.Now().Before(t.blockedUntil) {
+		until := t.blockedUntil
+		stats.BlockedUntil = &until
+	}
+	stats.Throttled = stats.BlockedUntil != nil || t.waiting > 0 || (t.limiter != nil && stats.Available < 1)
+	return stats
+}
+
+// NewPlatformHTTPClient returns an HTTP client whose requests are paced by the
+// throttle of platform. A zero timeout means no timeout, as long polling needs.
+func NewPlatformHTTPClient(platform string, timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &throttledTransport{base: http.DefaultTransport, throttle: PlatformThrottle(platform)},
+	}
+}
+
+// throttledTransport waits for the platform throttle before each request.
+// Requests rejected with 429 block the throttle for the wait the platform
+// asked for and are retried up to the platform's MaxRetries.
+type throttledTransport struct {
+	base     http.RoundTripper
+	throttle *Throttle
+}
+
+func (tt *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := tt.throttle.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+
+		r := req
+		if attempt > 0 {
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+		resp, err := tt.base.RoundTrip(r)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+
+		tt.throttle.Block(retryAfter(resp))
+		replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if attempt >= tt.throttle.maxRetries() || !replayable {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// retryAfter reads how long a 429 response asks to wait: the Retry-After or
+// X-RateLimit-Reset-After headers, or the retry_after field Telegram and
+// Discord put in the body. The body is left readable.
+func retryAfter(resp *http.Response) time.Duration {
+	d := defaultRetryAfter
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			d = time.Duration(secs * float64(time.Second))
+		} else if at, err := http.ParseTime(v); err == nil {
+			d = time.Until(at)
+		}
+	} else if v := resp.Header.Get("X-RateLimit-Reset-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			d = time.Duration(secs * float64(time.Second))
+		}
+	} else if resp.Body != nil {
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxRateLimitBody))
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(raw))
+
+		var body struct {
+			RetryAfter float64 `json:"retry_after"`
+			Parameters struct {
+				RetryAfter float64 `json:"retry_after"`
+			} `json:"parameters"`
+		}
+		if json.Unmarshal(raw, &body) == nil {
+			if body.Parameters.RetryAfter > 0 {
+				d = time.Duration(body.Parameters.RetryAfter * float64(time.Second))
+			} else if body.RetryAfter > 0 {
+				d = time.Duration(body.RetryAfter * float64(time.Second))
+			}
+		}
+	}
+	return min(max(d, 0), maxRetryAfter)
+}
@@ -0,0 +1,126 @@
+package channels
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pryx-core/internal/config"
+)
+
+func TestThrottledTransport_RetriesAfterRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "hello" {
+			t.Errorf("retried request lost its body: %q", body)
+		}
+		if calls.Add(1) == 1 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"ok":false,"error_code":429,"parameters":{"retry_after":0.05}}`)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	throttle := &Throttle{platform: "test"}
+	throttle.setLimit(config.ChannelRateLimit{RequestsPerSecond: 100, Burst: 10, MaxRetries: 2})
+	client := &http.Client{Transport: &throttledTransport{base: http.DefaultTransport, throttle: throttle}}
+
+	start := time.Now()
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("expected a successful retry, got status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected the retry to wait for retry_after, took %s", elapsed)
+	}
+	if stats := throttle.Stats(); stats.RateLimited != 1 || stats.Platform != "test" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestThrottledTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	throttle := &Throttle{platform: "test"}
+	throttle.setLimit(config.ChannelRateLimit{MaxRetries: 1})
+	client := &http.Client{Transport: &throttledTransport{base: http.DefaultTransport, throttle: throttle}}
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 2 {
+		t.Fatalf("expected the 429 after one retry, got status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestThrottle_WaitsForTokens(t *testing.T) {
+	throttle := &Throttle{platform: "test"}
+	throttle.setLimit(config.ChannelRateLimit{RequestsPerSecond: 20, Burst: 1})
+
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := throttle.Wait(ctx); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+	// One token is available right away, the others arrive every 50ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected requests beyond the burst to wait, took %s", elapsed)
+	}
+	if stats := throttle.Stats(); !stats.Throttled || stats.Burst != 1 {
+		t.Errorf("expected an empty bucket to report throttling, got %+v", stats)
+	}
+
+	throttle.Block(time.Hour)
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := throttle.Wait(ctx); err == nil {
+		t.Fatal("expected Wait to give up while the platform is blocked")
+	}
+	if stats := throttle.Stats(); stats.BlockedUntil == nil {
+		t.Errorf("expected the block to be reported, got %+v", stats)
+	}
+}
+
+func TestPlatformLimits(t *testing.T) {
+	defer SetPlatformLimits(nil)
+
+	throttle := PlatformThrottle("telegram")
+	if throttle != PlatformThrottle("telegram") {
+		t.Fatal("expected channels of a platform to share a throttle")
+	}
+	if stats := throttle.Stats(); stats.RequestsPerSecond != 30 || stats.Burst != 30 {
+		t.Errorf("expected the documented Telegram limit, got %+v", stats)
+	}
+
+	SetPlatformLimits(map[string]config.ChannelRateLimit{"telegram": {RequestsPerSecond: 5}})
+	if stats := throttle.Stats(); stats.RequestsPerSecond != 5 || stats.Burst != 30 {
+		t.Errorf("expected the override to apply with the default burst, got %+v", stats)
+	}
+
+	if stats := PlatformThrottle("webhook").Stats(); stats.RequestsPerSecond != 0 || stats.Throttled {
+		t.Errorf("expected platforms without a limit to be unthrottled, got %+v", stats)
+	}
+}
@@ -134,6 +134,14 @@ func (cm *ConfigManager) Validate(config WebhookConfig) error {
 		return fmt.Errorf("max retries cannot be negative")
 	}
 
+	if err := ValidateSignatureAlgorithm(config.SignatureAlgorithm); err != nil {
+		return err
+	}
+
+	if err := ValidateSignatureAlgorithm(config.InboundSignatureAlgorithm); err != nil {
+		return fmt.Errorf("inbound: %w", err)
+	}
+
 	return nil
 }
 
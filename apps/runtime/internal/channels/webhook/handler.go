@@ -2,6 +2,7 @@ package webhook
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -44,6 +45,10 @@ func (h *Handler) handleIncomingWebhook(w http.ResponseWriter, r *http.Request)
 
 	receiver := NewReceiver(channel.Config())
 	msg, err := receiver.Handle(r)
+	if errors.Is(err, ErrInvalidSignature) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -63,7 +63,13 @@ func (r *Receiver) Handle(req *http.Request) (*IncomingWebhook, error) {
 	}, nil
 }
 
+// verifySignature accepts the channel's own inbound signature (see
+// VerifyInbound) or a Stripe, GitHub or generic one.
 func (r *Receiver) verifySignature(req *http.Request, body []byte) error {
+	if err := VerifyInbound(r.config, req, body); err == nil {
+		return nil
+	}
+
 	formats := []SignatureFormat{
 		SignatureFormatStripe,
 		SignatureFormatGitHub,
@@ -76,7 +82,7 @@ func (r *Receiver) verifySignature(req *http.Request, body []byte) error {
 		}
 	}
 
-	return fmt.Errorf("no valid signature found")
+	return fmt.Errorf("%w: no valid signature found", ErrInvalidSignature)
 }
 
 func (r *Receiver) verifySignatureFormat(req *http.Request, body []byte, format SignatureFormat) error {
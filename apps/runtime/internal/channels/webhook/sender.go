@@ -3,9 +3,6 @@ package webhook
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -103,11 +100,10 @@ func (s *Sender) sendRequest(ctx context.Context, payload []byte) (int, error) {
 		req.Header.Set(name, value)
 	}
 
-	if s.config.Secret != "" {
-		mac := hmac.New(sha256.New, []byte(s.config.Secret))
-		mac.Write(payload)
-		signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
-		req.Header.Set("X-Webhook-Signature", "sha256="+signature)
+	signer := NewSigner(s.config)
+	signer.LegacyPrefix = "sha256="
+	if err := signer.Sign(req, payload); err != nil {
+		return 0, fmt.Errorf("failed to sign request: %w", err)
 	}
 
 	resp, err := s.client.Do(req)
@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSignatureHeader carries the timestamped HMAC of outgoing requests.
+	DefaultSignatureHeader = "X-Webhook-Signature-V2"
+	// LegacySignatureHeader carries the untimestamped HMAC-SHA256 of the body
+	// that outgoing requests were signed with before timestamps were added.
+	LegacySignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader carries the Unix time the outgoing request was signed at.
+	TimestampHeader = "X-Webhook-Timestamp"
+	// DefaultInboundSignatureHeader carries the HMAC of inbound requests.
+	DefaultInboundSignatureHeader = "X-Pryx-Signature"
+	// DefaultSignatureAlgorithm is used when WebhookConfig.SignatureAlgorithm is empty.
+	DefaultSignatureAlgorithm = "sha256"
+	// SignatureTolerance is how old a signed timestamp may be before Verify
+	// rejects it, matching the window applied to inbound Stripe signatures.
+	SignatureTolerance = 5 * time.Minute
+)
+
+// ErrInvalidSignature is returned when an inbound request is unsigned or its
+// signature does not match.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+var signatureAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Signer signs outgoing webhook requests. The signature is an HMAC over
+// "<timestamp>.<body>", sent as "<algorithm>=<hex>" in the signature header
+// with the timestamp in X-Webhook-Timestamp, so receivers can reject replays.
+// Receivers written against the old format keep working: the base64
+// HMAC-SHA256 of the body is still sent in LegacySignatureHeader, prefixed
+// with LegacyPrefix.
+type Signer struct {
+	Secret       string
+	Header       string
+	Algorithm    string
+	LegacyPrefix string
+
+	now func() time.Time // replaced in tests
+}
+
+// NewSigner returns a signer for config's secret, header and algorithm.
+func NewSigner(config WebhookConfig) *Signer {
+	return &Signer{
+		Secret:    config.Secret,
+		Header:    config.SignatureHeader,
+		Algorithm: config.SignatureAlgorithm,
+	}
+}
+
+// ValidateSignatureAlgorithm reports whether alg is empty or a supported algorithm.
+func ValidateSignatureAlgorithm(alg string) error {
+	if alg == "" {
+		return nil
+	}
+	if _, ok := signatureAlgorithms[strings.ToLower(alg)]; !ok {
+		return fmt.Errorf("unsupported signature algorithm %q (want sha1, sha256 or sha512)", alg)
+	}
+	return nil
+}
+
+func (s *Signer) header() string {
+	if s.Header != "" {
+		return s.Header
+	}
+	return DefaultSignatureHeader
+}
+
+func (s *Signer) algorithm() string {
+	if s.Algorithm != "" {
+		return strings.ToLower(s.Algorithm)
+	}
+	return DefaultSignatureAlgorithm
+}
+
+func (s *Signer) clock() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+	return time.Now()
+}
+
+// Sign sets the timestamp and signature headers on req for body. It does
+// nothing when no secret is configured.
+func (s *Signer) Sign(req *http.Request, body []byte) error {
+	if s == nil || s.Secret == "" {
+		return nil
+	}
+	timestamp := strconv.FormatInt(s.clock().Unix(), 10)
+	signature, err := s.compute(timestamp, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(TimestampHeader, timestamp)
+	if s.header() != LegacySignatureHeader {
+		mac := hmac.New(sha256.New, []byte(s.Secret))
+		mac.Write(body)
+		req.Header.Set(LegacySignatureHeader, s.LegacyPrefix+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	}
+	req.Header.Set(s.header(), s.algorithm()+"="+signature)
+	return nil
+}
+
+// Verify checks a request signed by Sign: the signature must match body and
+// the timestamp must be within SignatureTolerance of now.
+func (s *Signer) Verify(req *http.Request, body []byte) error {
+	sigHeader := req.Header.Get(s.header())
+	if sigHeader == "" {
+		return fmt.Errorf("no %s header", s.header())
+	}
+	timestamp := req.Header.Get(TimestampHeader)
+	if timestamp == "" {
+		return fmt.Errorf("no %s header", TimestampHeader)
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp")
+	}
+	age := s.clock().Sub(time.Unix(ts, 0))
+	if age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("timestamp outside tolerance")
+	}
+
+	signature, ok := strings.CutPrefix(sigHeader, s.algorithm()+"=")
+	if !ok {
+		return fmt.Errorf("signature is not %s", s.algorithm())
+	}
+	expected, err := s.compute(timestamp, body)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func (s *Signer) compute(timestamp string, body []byte) (string, error) {
+	newHash, ok := signatureAlgorithms[s.algorithm()]
+	if !ok {
+		return "", fmt.Errorf("unsupported signature algorithm %q", s.Algorithm)
+	}
+	mac := hmac.New(newHash, []byte(s.Secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// VerifyInbound checks the HMAC of an inbound request to a channel with a
+// secret. The signature is computed over the raw body with
+// config.InboundSignatureAlgorithm (sha256 when empty) and read from
+// config.InboundSignatureHeader (DefaultInboundSignatureHeader when empty),
+// as hex or base64 with an optional "<algorithm>=" prefix. Failures wrap
+// ErrInvalidSignature. Requests to channels without a secret pass.
+func VerifyInbound(config WebhookConfig, req *http.Request, body []byte) error {
+	if config.Secret == "" {
+		return nil
+	}
+	header := config.InboundSignatureHeader
+	if header == "" {
+		header = DefaultInboundSignatureHeader
+	}
+	alg := strings.ToLower(config.InboundSignatureAlgorithm)
+	if alg == "" {
+		alg = DefaultSignatureAlgorithm
+	}
+	newHash, ok := signatureAlgorithms[alg]
+	if !ok {
+		return fmt.Errorf("unsupported signature algorithm %q", config.InboundSignatureAlgorithm)
+	}
+
+	sigHeader := strings.TrimSpace(req.Header.Get(header))
+	if sigHeader == "" {
+		return fmt.Errorf("%w: no %s header", ErrInvalidSignature, header)
+	}
+	sigHeader = strings.TrimPrefix(sigHeader, alg+"=")
+
+	mac := hmac.New(newHash, []byte(config.Secret))
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	if got, err := hex.DecodeString(sigHeader); err == nil && hmac.Equal(got, expected) {
+		return nil
+	}
+	if got, err := base64.StdEncoding.DecodeString(sigHeader); err == nil && hmac.Equal(got, expected) {
+		return nil
+	}
+	return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+}
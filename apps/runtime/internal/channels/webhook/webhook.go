@@ -3,9 +3,6 @@ package webhook
 import (
 	"bytes"
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -31,6 +28,16 @@ type WebhookConfig struct {
 	Enabled     bool
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+
+	// SignatureHeader and SignatureAlgorithm control how outgoing requests are
+	// signed with Secret (DefaultSignatureHeader and sha256 when empty).
+	SignatureHeader    string
+	SignatureAlgorithm string
+	// InboundSignatureHeader and InboundSignatureAlgorithm control how inbound
+	// requests are verified against Secret (DefaultInboundSignatureHeader and
+	// sha256 when empty).
+	InboundSignatureHeader    string
+	InboundSignatureAlgorithm string
 }
 
 type WebhookChannel struct {
@@ -38,6 +45,7 @@ type WebhookChannel struct {
 	server   *http.Server
 	eventBus *bus.Bus
 	status   channels.Status
+	cancel   context.CancelFunc
 }
 
 func NewWebhookChannel(config WebhookConfig, eventBus *bus.Bus) *WebhookChannel {
@@ -68,6 +76,11 @@ func (w *WebhookChannel) Type() string {
 }
 
 func (w *WebhookChannel) Connect(ctx context.Context) error {
+	if w.cancel != nil {
+		w.cancel()
+	}
+	ctx, w.cancel = context.WithCancel(ctx)
+
 	if w.config.Port <= 0 {
 		// Outgoing only or no server
 		w.status = channels.StatusConnected
@@ -100,6 +113,10 @@ func (w *WebhookChannel) Connect(ctx context.Context) error {
 
 func (w *WebhookChannel) Disconnect(ctx context.Context) error {
 	w.status = channels.StatusDisconnected
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
 	if w.server != nil {
 		return w.server.Shutdown(ctx)
 	}
@@ -157,11 +174,8 @@ func (w *WebhookChannel) Send(ctx context.Context, msg channels.Message) error {
 
 	req.Header.Set("Content-Type", contentType)
 
-	if w.config.Secret != "" {
-		mac := hmac.New(sha256.New, []byte(w.config.Secret))
-		mac.Write(bodyBytes)
-		signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
-		req.Header.Set("X-Webhook-Signature", signature)
+	if err := NewSigner(w.config).Sign(req, bodyBytes); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
 	}
 
 	// Retry loop
@@ -263,33 +277,23 @@ func (w *WebhookChannel) GetDeliveryLogs(limit int) ([]DeliveryLog, error) {
 	return []DeliveryLog{}, nil
 }
 
+// ValidateSignature verifies the signature of req against secret, or the
+// channel's own secret when secret is empty. The body is left readable.
 func (w *WebhookChannel) ValidateSignature(req *http.Request, secret string) (bool, error) {
-	// If secret passed, use it, else use internal
-	key := secret
-	if key == "" {
-		key = w.config.Secret
-	}
-	if key == "" {
-		return true, nil // No secret, strictly valid? Or fail? Assume valid if no auth configured.
+	config := w.config
+	if secret != "" {
+		config.Secret = secret
 	}
-
-	signature := req.Header.Get("X-Webhook-Signature")
-	if signature == "" {
-		return false, fmt.Errorf("missing signature")
-	}
-
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
 		return false, err
 	}
-	// Important: Reset body for subsequent handlers
-	req.Body = io.NopCloser(bytes.NewBuffer(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
 
-	mac := hmac.New(sha256.New, []byte(key))
-	mac.Write(body)
-	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
-
-	return hmac.Equal([]byte(signature), []byte(expected)), nil
+	if err := VerifyInbound(config, req, body); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (w *WebhookChannel) handleWebhook(rw http.ResponseWriter, req *http.Request) {
@@ -298,18 +302,16 @@ func (w *WebhookChannel) handleWebhook(rw http.ResponseWriter, req *http.Request
 		return
 	}
 
-	// Validate Signature
-	if w.config.Secret != "" {
-		valid, _ := w.ValidateSignature(req, "") // Use internal secret
-		if !valid {
-			http.Error(rw, "Invalid signature", http.StatusUnauthorized)
-			return
-		}
-	}
-
-	// Read (re-read) Body
-	body, _ := io.ReadAll(req.Body)
+	body, err := io.ReadAll(req.Body)
 	defer req.Body.Close()
+	if err != nil {
+		http.Error(rw, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+	if err := VerifyInbound(w.config, req, body); err != nil {
+		http.Error(rw, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
 
 	var payload map[string]interface{}
 	if err := json.Unmarshal(body, &payload); err != nil {
@@ -2,8 +2,17 @@ package webhook
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -155,3 +164,177 @@ func TestWebhookChannel_RetryLogic(t *testing.T) {
 		t.Errorf("expected 3 retry attempts, got %d", attempts)
 	}
 }
+
+func TestWebhookChannel_Send_SignsBodyAndTimestamp(t *testing.T) {
+	config := WebhookConfig{
+		ID:                 "test-webhook",
+		Secret:             "test-secret",
+		SignatureHeader:    "X-Pryx-Signature",
+		SignatureAlgorithm: "sha512",
+	}
+
+	verified := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		// What a receiver does: recompute the HMAC over "<timestamp>.<body>"
+		// with the shared secret and compare, rejecting stale timestamps.
+		timestamp := r.Header.Get(TimestampHeader)
+		ts, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(ts, 0)) > SignatureTolerance {
+			verified <- fmt.Errorf("bad timestamp %q", timestamp)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mac := hmac.New(sha512.New, []byte("test-secret"))
+		mac.Write([]byte(timestamp + "." + string(body)))
+		expected := "sha512=" + hex.EncodeToString(mac.Sum(nil))
+		if !hmac.Equal([]byte(r.Header.Get("X-Pryx-Signature")), []byte(expected)) {
+			verified <- fmt.Errorf("signature mismatch")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// The same check through the package's Signer.
+		verified <- NewSigner(config).Verify(r, body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config.TargetURL = server.URL
+	w := NewWebhookChannel(config, bus.New())
+	msg := channels.Message{ID: "msg-1", Content: "signed content", CreatedAt: time.Now()}
+	if err := w.Send(context.Background(), msg); err != nil {
+		t.Fatalf("failed to send message: %v", err)
+	}
+	if err := <-verified; err != nil {
+		t.Fatalf("receiver could not verify signature: %v", err)
+	}
+}
+
+func TestSigner_KeepsLegacySignature(t *testing.T) {
+	body := []byte(`{"content":"hello"}`)
+	mac := hmac.New(sha256.New, []byte("test-secret"))
+	mac.Write(body)
+	legacy := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	signer := &Signer{Secret: "test-secret"}
+	req := httptest.NewRequest(http.MethodPost, "/hook", nil)
+	if err := signer.Sign(req, body); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if got := req.Header.Get(LegacySignatureHeader); got != legacy {
+		t.Errorf("expected legacy signature %q, got %q", legacy, got)
+	}
+
+	signer.LegacyPrefix = "sha256="
+	if err := signer.Sign(req, body); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if got := req.Header.Get(LegacySignatureHeader); got != "sha256="+legacy {
+		t.Errorf("expected prefixed legacy signature, got %q", got)
+	}
+	if err := signer.Verify(req, body); err != nil {
+		t.Errorf("verify: %v", err)
+	}
+}
+
+func TestSigner_VerifyRejectsReplayAndTampering(t *testing.T) {
+	signedAt := time.Unix(1_700_000_000, 0)
+	signer := &Signer{Secret: "test-secret", now: func() time.Time { return signedAt }}
+
+	body := []byte(`{"content":"hello"}`)
+	req := httptest.NewRequest(http.MethodPost, "/hook", nil)
+	if err := signer.Sign(req, body); err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if !strings.HasPrefix(req.Header.Get(DefaultSignatureHeader), "sha256=") {
+		t.Fatalf("unexpected signature header %q", req.Header.Get(DefaultSignatureHeader))
+	}
+	if err := signer.Verify(req, body); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	if err := signer.Verify(req, []byte(`{"content":"tampered"}`)); err == nil {
+		t.Error("expected tampered body to fail verification")
+	}
+
+	signer.now = func() time.Time { return signedAt.Add(SignatureTolerance + time.Second) }
+	if err := signer.Verify(req, body); err == nil {
+		t.Error("expected replayed request to fail verification")
+	}
+
+	if err := ValidateSignatureAlgorithm("md5"); err == nil {
+		t.Error("expected md5 to be rejected")
+	}
+}
+
+func TestWebhookChannel_HandleWebhookVerifiesSignature(t *testing.T) {
+	body := `{"content":"hello"}`
+	sign := func(newHash func() hash.Hash, secret, payload string) []byte {
+		mac := hmac.New(newHash, []byte(secret))
+		mac.Write([]byte(payload))
+		return mac.Sum(nil)
+	}
+	validHex := hex.EncodeToString(sign(sha256.New, "test-secret", body))
+
+	tests := []struct {
+		name   string
+		config WebhookConfig
+		body   string
+		header string
+		value  string
+		want   int
+	}{
+		{"valid", WebhookConfig{}, body, DefaultInboundSignatureHeader, validHex, http.StatusOK},
+		{"valid with prefix", WebhookConfig{}, body, DefaultInboundSignatureHeader, "sha256=" + validHex, http.StatusOK},
+		{"tampered", WebhookConfig{}, `{"content":"tampered"}`, DefaultInboundSignatureHeader, validHex, http.StatusUnauthorized},
+		{"missing", WebhookConfig{}, body, "", "", http.StatusUnauthorized},
+		{"wrong secret", WebhookConfig{}, body, DefaultInboundSignatureHeader, hex.EncodeToString(sign(sha256.New, "other", body)), http.StatusUnauthorized},
+		{
+			"custom header and algorithm",
+			WebhookConfig{InboundSignatureHeader: "X-Signature", InboundSignatureAlgorithm: "sha512"},
+			body, "X-Signature", base64.StdEncoding.EncodeToString(sign(sha512.New, "test-secret", body)),
+			http.StatusOK,
+		},
+		{
+			"default header ignored when customised",
+			WebhookConfig{InboundSignatureHeader: "X-Signature"},
+			body, DefaultInboundSignatureHeader, validHex,
+			http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventBus := bus.New()
+			events, cancel := eventBus.Subscribe(bus.EventChannelMessage)
+			defer cancel()
+
+			config := tt.config
+			config.ID = "hook"
+			config.Secret = "test-secret"
+			w := NewWebhookChannel(config, eventBus)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			w.handleWebhook(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+			select {
+			case evt := <-events:
+				if tt.want != http.StatusOK {
+					t.Fatalf("rejected request published %v", evt.Payload)
+				}
+			case <-time.After(50 * time.Millisecond):
+				if tt.want == http.StatusOK {
+					t.Fatal("accepted request was not published")
+				}
+			}
+		})
+	}
+}
@@ -5,18 +5,167 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// ContentFilterRules configures content filtering for one scope.
+type ContentFilterRules struct {
+	// RedactPII replaces emails, phone numbers, card numbers and similar identifiers.
+	RedactPII bool `yaml:"redact_pii"`
+	// DenyKeywords blocks content containing any of these words (case-insensitive).
+	DenyKeywords []string `yaml:"deny_keywords"`
+	// AllowKeywords exempts the DenyKeywords matches that fall within one of
+	// these phrases; deny matches elsewhere in the content still block it.
+	AllowKeywords []string `yaml:"allow_keywords"`
+}
+
+// Enabled reports whether the rules filter anything.
+func (r ContentFilterRules) Enabled() bool {
+	return r.RedactPII || len(r.DenyKeywords) > 0
+}
+
+// ContentFilterConfig holds the default filter rules and per-channel overrides.
+// A channel entry replaces the default rules for messages from that channel.
+type ContentFilterConfig struct {
+	ContentFilterRules `yaml:",inline"`
+	Channels           map[string]ContentFilterRules `yaml:"channels"`
+}
+
+// MCPServerPolicy restricts which MCP servers may be added. Patterns match the
+// server name or its URL/command and support * and ? wildcards. Deny wins over
+// allow; an empty allow list permits everything not denied.
+type MCPServerPolicy struct {
+	Allow []string `yaml:"allow" json:"allow"`
+	Deny  []string `yaml:"deny" json:"deny"`
+}
+
+// ToolApproval is the approval default for the tools matching a pattern.
+type ToolApproval struct {
+	// Decision is allow, ask or deny.
+	Decision string `yaml:"decision" json:"decision"`
+	// Risk overrides the risk level shown in approval prompts: read, write,
+	// exec, network, system or dangerous. Empty derives it from the tool.
+	Risk string `yaml:"risk,omitempty" json:"risk,omitempty"`
+}
+
+// ToolScope restricts the tools a session or channel may call, as glob
+// patterns such as "filesystem.*". Deny wins over Allow; an empty Allow
+// permits every tool not denied.
+type ToolScope struct {
+	Allow []string `yaml:"allow" json:"allow"`
+	Deny  []string `yaml:"deny" json:"deny"`
+}
+
+// AlertRule forwards matching bus events to a channel as operational alerts.
+type AlertRule struct {
+	// Events lists the bus event types to match, e.g. "error.occurred"; "*" matches all.
+	Events []string `yaml:"events"`
+	// Kinds optionally restricts matches to events whose payload kind is listed.
+	Kinds []string `yaml:"kinds"`
+	// MinSeverity drops events below this level: info, warning, error or critical.
+	MinSeverity string `yaml:"min_severity"`
+	// Channel is the channel instance ID to deliver to, and ChatID the chat within it.
+	Channel string `yaml:"channel"`
+	ChatID  string `yaml:"chat_id"`
+	// Throttle is the minimum time between alerts for the same event and kind (0 = none).
+	Throttle time.Duration `yaml:"throttle"`
+}
+
+// ChannelRateLimit overrides the API request rate allowed towards a channel
+// platform. Zero fields keep the platform's default.
+type ChannelRateLimit struct {
+	// RequestsPerSecond and Burst size the token bucket shared by all channels
+	// of the platform.
+	RequestsPerSecond float64 `yaml:"requests_per_second"`
+	Burst             int     `yaml:"burst"`
+	// MaxRetries bounds how often a request rejected with 429 is retried after
+	// the wait the platform asked for.
+	MaxRetries int `yaml:"max_retries"`
+}
+
+// ChannelGreeting is the welcome sent on a conversation's first message or on /start.
+type ChannelGreeting struct {
+	// Message is a text/template with fields .Name (persona name), .Channel,
+	// .Commands and .Capabilities. Empty uses a default greeting.
+	Message string `yaml:"message"`
+	// Commands are listed in the greeting, e.g. "/help - show help".
+	Commands []string `yaml:"commands"`
+	// ListCapabilities fills .Capabilities with the available skills.
+	ListCapabilities bool `yaml:"list_capabilities"`
+}
+
+// ChannelInboundFilter screens inbound channel messages before they reach the
+// agent. It complements the channels' own identity checks (AllowedChats,
+// AllowedGuilds), which run first. Deny rules win over allow rules.
+type ChannelInboundFilter struct {
+	// AllowSenders, if set, admits only messages from these sender IDs.
+	AllowSenders []string `yaml:"allow_senders"`
+	// DenySenders drops messages from these sender IDs.
+	DenySenders []string `yaml:"deny_senders"`
+	// AllowPatterns, if set, admits only content matching one of these regular expressions.
+	AllowPatterns []string `yaml:"allow_patterns"`
+	// DenyPatterns drops content matching any of these regular expressions.
+	DenyPatterns []string `yaml:"deny_patterns"`
+	// Reply is sent back for filtered messages. Empty drops them silently.
+	Reply string `yaml:"reply"`
+}
+
+// ChannelSelfTest configures the round-trip self-test of a channel.
+type ChannelSelfTest struct {
+	// ChatID is the chat or channel the test message is sent to.
+	ChatID string `yaml:"chat_id"`
+	// ExpectEcho waits for the test message to come back as an inbound message,
+	// for destinations that deliver the bot's own messages (e.g. a webhook loopback).
+	ExpectEcho bool `yaml:"expect_echo"`
+	// Timeout bounds the wait for the echo (default: 10s).
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ModelRateLimit is a static request limit for a model or provider, applied
+// where the provider sends no rate-limit headers. Zero fields impose no limit.
+type ModelRateLimit struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	MaxConcurrent     int `yaml:"max_concurrent"`
+}
+
+// ProviderWireLog logs provider HTTP requests and responses for debugging. API
+// keys are always removed; entries never reach the event stream.
+type ProviderWireLog struct {
+	Enabled bool `yaml:"enabled"`
+	// Path is the log file (empty = ~/.pryx/logs/provider-wire.log).
+	Path string `yaml:"path"`
+	// MaxSizeMB rotates the file at this size (0 = 10).
+	MaxSizeMB int `yaml:"max_size_mb"`
+	// MaxFiles is how many rotated files are kept (0 = 3).
+	MaxFiles int `yaml:"max_files"`
+	// RedactPrompts replaces message and prompt content in logged requests with its length.
+	RedactPrompts bool `yaml:"redact_prompts"`
+	// Providers limits logging to these provider IDs (empty = all).
+	Providers []string `yaml:"providers"`
+}
+
+// ChatWithoutSession values.
+const (
+	ChatWithoutSessionCreate = "create"
+	ChatWithoutSessionReject = "reject"
+)
+
 // Config holds all configuration settings for the Pryx runtime.
 type Config struct {
 	// ListenAddr is the address to listen on (e.g., ":3000" or ":0" for dynamic port).
 	ListenAddr string `yaml:"listen_addr"`
+	// PreferredPort is tried first when ListenAddr requests a dynamic port, so the
+	// runtime keeps a stable port across restarts while it is free. 0 disables it.
+	PreferredPort int `yaml:"preferred_port"`
 	// DatabasePath is the path to the SQLite database file.
 	DatabasePath string `yaml:"database_path"`
+	// DBCompactSchedule is the cron expression of a built-in scheduled task that
+	// compacts the database, e.g. "0 4 * * 0" (empty = not scheduled).
+	DBCompactSchedule string `yaml:"db_compact_schedule"`
 	// SkillsPath is the directory where skills are installed.
 	SkillsPath string `yaml:"skills_path"`
 	// CachePath is the directory for cached data.
@@ -29,17 +178,111 @@ type Config struct {
 	AgentDetectEnabled bool `yaml:"agent_detect_enabled"`
 	// AgentDetectInterval is how often to scan for agents.
 	AgentDetectInterval time.Duration `yaml:"agent_detect_interval"`
+	// AgentDetectTimeout bounds each protocol adapter's detection run (default: 10s).
+	AgentDetectTimeout time.Duration `yaml:"agent_detect_timeout"`
+	// AgentDetectConcurrency is how many adapters detect at once (default: 4).
+	AgentDetectConcurrency int `yaml:"agent_detect_concurrency"`
 
 	// AI Configuration
 	// ModelProvider is the LLM provider to use (openai, anthropic, ollama, glm).
 	ModelProvider string `yaml:"model_provider"`
 	// ModelName is the specific model to use (e.g., gpt-4, claude-3-opus, llama3).
 	ModelName string `yaml:"model_name"`
+	// ModelFallbacks are model IDs tried in order when a request to the selected
+	// model fails with a server error, a rate limit or a timeout.
+	ModelFallbacks []string `yaml:"model_fallbacks"`
+	// ModelFallbackTimeout bounds the wait for a model to start answering before
+	// the next fallback is tried (0 = default 30s). Unused without fallbacks.
+	ModelFallbackTimeout time.Duration `yaml:"model_fallback_timeout"`
 	// OllamaEndpoint is the URL of the Ollama server (when using Ollama provider).
 	OllamaEndpoint string `yaml:"ollama_endpoint"`
 	// ConfiguredProviders is the list of providers that have been explicitly configured.
 	// This tracks providers added via 'provider add' even without API keys (e.g., Ollama).
 	ConfiguredProviders []string `yaml:"configured_providers"`
+	// ValidateProviderKeys checks new provider keys with an authenticated call before
+	// storing them. The validate query parameter on the key endpoint overrides it.
+	ValidateProviderKeys bool `yaml:"validate_provider_keys"`
+	// CloudProxyFallback routes generation through the Pryx Cloud API, authenticated with
+	// the cloud login, when the active provider has no local key.
+	CloudProxyFallback bool `yaml:"cloud_proxy_fallback"`
+
+	// LLM Transport
+	// LLMConnectTimeout bounds dialing a provider (0 = default 10s).
+	LLMConnectTimeout time.Duration `yaml:"llm_connect_timeout"`
+	// LLMResponseTimeout bounds the wait for a provider's response headers (0 = default 60s).
+	LLMResponseTimeout time.Duration `yaml:"llm_response_timeout"`
+	// LLMRequestTimeout bounds an entire provider request, including streaming (0 = default 120s).
+	LLMRequestTimeout time.Duration `yaml:"llm_request_timeout"`
+	// ProviderWireLog writes redacted provider traffic to a rotating file. Off by default.
+	ProviderWireLog ProviderWireLog `yaml:"provider_wire_log"`
+
+	// CloudRetryMaxAttempts caps attempts per cloud API call, including the first (0 = default 4).
+	CloudRetryMaxAttempts int `yaml:"cloud_retry_max_attempts"`
+	// CloudRetryBudget caps the total backoff time per cloud API call (0 = default 2m).
+	CloudRetryBudget time.Duration `yaml:"cloud_retry_budget"`
+	// LLMCacheEnabled enables caching of deterministic (temperature 0) LLM responses.
+	LLMCacheEnabled bool `yaml:"llm_cache_enabled"`
+	// LLMCacheTTL is how long cached responses stay valid (0 = default 10m).
+	LLMCacheTTL time.Duration `yaml:"llm_cache_ttl"`
+	// LLMCacheMaxEntries caps the number of cached responses (0 = default 256).
+	LLMCacheMaxEntries int `yaml:"llm_cache_max_entries"`
+
+	// AgentMaxParallelTools bounds how many tool calls from one assistant turn run at once (0 = default 4).
+	AgentMaxParallelTools int `yaml:"agent_max_parallel_tools"`
+	// AgentToolTimeout bounds a single tool call, including any approval wait (0 = default 3m).
+	AgentToolTimeout time.Duration `yaml:"agent_tool_timeout"`
+	// MaxConcurrentGenerations caps LLM generations in flight across all sessions and
+	// channels (0 = unlimited). GenerationQueueSize is how many more may wait for a slot
+	// (0 = default 32, negative = none, so every request over the cap is rejected); beyond
+	// the queue requests are rejected with an agent.busy event.
+	MaxConcurrentGenerations int `yaml:"max_concurrent_generations"`
+	GenerationQueueSize      int `yaml:"generation_queue_size"`
+	// AutomationMaxTurns caps the turns an unattended conversation, one started
+	// by a channel or an automation, may take within AutomationTurnWindow
+	// (0 = default 50, negative = no limit). AutomationTurnWindow defaults to 1h.
+	AutomationMaxTurns   int           `yaml:"automation_max_turns"`
+	AutomationTurnWindow time.Duration `yaml:"automation_turn_window"`
+	// InteractiveMaxTurns caps the turns of a user-driven session within
+	// AutomationTurnWindow (0 = no limit).
+	InteractiveMaxTurns int `yaml:"interactive_max_turns"`
+	// ModelRateLimits sets static limits keyed by model ID or provider ID (a model's
+	// own entry wins). Requests over a limit wait; provider rate-limit headers are
+	// followed either way.
+	ModelRateLimits map[string]ModelRateLimit `yaml:"model_rate_limits"`
+	// ProviderOverrides adds headers and default body parameters to requests,
+	// keyed by provider ID.
+	ProviderOverrides map[string]ProviderOverride `yaml:"provider_overrides"`
+
+	// SummaryModel is the model used to summarize sessions. Empty picks a cheap model for the provider.
+	SummaryModel string `yaml:"summary_model"`
+	// SummaryMaxWords is the target length of session summaries (0 = default 150).
+	SummaryMaxWords int `yaml:"summary_max_words"`
+
+	// ContentFilter redacts or blocks content before it is sent to the provider
+	// and before responses are returned. Filtering is off when nothing is configured.
+	ContentFilter ContentFilterConfig `yaml:"content_filter"`
+
+	// MCPServerPolicy limits the MCP servers users can add via the CLI or API.
+	MCPServerPolicy MCPServerPolicy `yaml:"mcp_server_policy"`
+	// MCPCallTimeout bounds how long an MCP server may take to run a tool,
+	// not counting any approval wait (0 = default 60s).
+	MCPCallTimeout time.Duration `yaml:"mcp_call_timeout"`
+	// MCPApprovalTTL is how long a tool call waits for approval before it is
+	// denied (0 = default 2m).
+	MCPApprovalTTL time.Duration `yaml:"mcp_approval_ttl"`
+	// ToolApprovals sets the approval default per tool, keyed by tool pattern
+	// such as "mcp.shell.exec" or "mcp.filesystem.*". Exact patterns win over
+	// wildcards, longer over shorter; tools matching none are asked about.
+	ToolApprovals map[string]ToolApproval `yaml:"tool_approvals"`
+
+	// ToolScopes restricts the tools a session may call, keyed by session ID
+	// or by "channel:<source>" / "channel:<source>:<chat>". The "default" scope
+	// applies to calls no other key matches, including calls without a
+	// session. Calls outside the scope are refused before approval.
+	ToolScopes map[string]ToolScope `yaml:"tool_scopes"`
+
+	// Alerts routes critical runtime events to ops channels.
+	Alerts []AlertRule `yaml:"alerts"`
 
 	// Channels
 	// TelegramToken is the bot token for Telegram integration.
@@ -51,6 +294,91 @@ type Config struct {
 	SlackAppToken string `yaml:"slack_app_token"`
 	SlackBotToken string `yaml:"slack_bot_token"`
 	SlackEnabled  bool   `yaml:"slack_enabled"`
+	// MattermostURL and MattermostToken connect a Mattermost bot account;
+	// MattermostChannels limits it to those channel IDs (empty allows all).
+	// MattermostEnabled enables or disables the Mattermost bot.
+	MattermostURL      string   `yaml:"mattermost_url"`
+	MattermostToken    string   `yaml:"mattermost_token"`
+	MattermostChannels []string `yaml:"mattermost_channels"`
+	MattermostEnabled  bool     `yaml:"mattermost_enabled"`
+	// ChannelModels overrides the model per channel, keyed by channel ID
+	// (e.g. "telegram-main"). Request and session overrides still take precedence.
+	ChannelModels map[string]string `yaml:"channel_models"`
+	// ChannelGreetings configures a welcome message per channel ID; "*" applies to
+	// channels without their own entry. Channels without a greeting send none.
+	ChannelGreetings map[string]ChannelGreeting `yaml:"channel_greetings"`
+	// ChannelFilters screens inbound messages per channel ID before they reach the
+	// agent; "*" applies to channels without their own entry.
+	ChannelFilters map[string]ChannelInboundFilter `yaml:"channel_filters"`
+	// ChannelRateLimits overrides the API rate limits per platform ("telegram",
+	// "slack", "discord", "mattermost").
+	ChannelRateLimits map[string]ChannelRateLimit `yaml:"channel_rate_limits"`
+	// ChannelSelfTests sets the self-test destination per channel ID, used by
+	// POST /api/v1/channels/{id}/selftest when the request names none.
+	ChannelSelfTests map[string]ChannelSelfTest `yaml:"channel_selftests"`
+
+	// ChannelTimezones overrides Timezone per channel ID, e.g. for a team channel in
+	// another zone. A session's own timezone still takes precedence.
+	ChannelTimezones map[string]string `yaml:"channel_timezones"`
+
+	// ChatWithoutSession controls chat.send requests that name no session:
+	// "create" (default) starts a new session and emits session.created with its id,
+	// "reject" answers with a chat.session_required error.
+	ChatWithoutSession string `yaml:"chat_without_session"`
+
+	// IdempotencyKeyTTL is how long an Idempotency-Key on session creation keeps
+	// returning the session it created (0 = 24h).
+	IdempotencyKeyTTL time.Duration `yaml:"idempotency_key_ttl"`
+
+	// MaintenanceMode rejects new chat, tool and spawn requests and pauses the scheduler
+	// and channel intake while in-flight work drains.
+	MaintenanceMode bool `yaml:"maintenance_mode"`
+
+	// IdleTimeout shuts the runtime down gracefully after this long without HTTP, WebSocket,
+	// channel or scheduler activity (0 = disabled).
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+
+	// SchedulerLocking makes scheduled runs take a lock in the database so runtimes
+	// sharing it run each task once per schedule window. Only needed for multi-instance
+	// deployments. SchedulerLockLease bounds how long a crashed instance's lock blocks
+	// the next run (0 = 30m).
+	SchedulerLocking   bool          `yaml:"scheduler_locking"`
+	SchedulerLockLease time.Duration `yaml:"scheduler_lock_lease"`
+
+	// SchedulerMaxRunsPerTask keeps only this many of each task's newest runs in the run
+	// history, and SchedulerRunRetention drops runs older than this (0 = keep all).
+	SchedulerMaxRunsPerTask int           `yaml:"scheduler_max_runs_per_task"`
+	SchedulerRunRetention   time.Duration `yaml:"scheduler_run_retention"`
+
+	// ChannelActivityRetention drops channel activity log entries older than this
+	// (0 = 30 days) and ChannelActivityMaxRows keeps at most this many of each
+	// channel's newest entries (0 = 1000).
+	ChannelActivityRetention time.Duration `yaml:"channel_activity_retention"`
+	ChannelActivityMaxRows   int           `yaml:"channel_activity_max_rows"`
+
+	// WorkspaceRoot is the directory under which managed skills, media, cached tool output,
+	// exports and screen captures are written. Empty uses $PRYX_WORKSPACE_ROOT/.pryx, or
+	// ~/.pryx. PRYX_MANAGED_SKILLS_DIR still overrides where managed skills go.
+	WorkspaceRoot string `yaml:"workspace_root"`
+
+	// AuditContentRetention controls how much prompt and response content the audit log
+	// keeps: "full" (default), "truncated" or "none". Secret-looking values are hashed in
+	// every mode; cost and usage fields are never touched.
+	AuditContentRetention string `yaml:"audit_content_retention"`
+	// AuditContentMaxChars is the length kept per content field when truncating (0 = 200).
+	AuditContentMaxChars int `yaml:"audit_content_max_chars"`
+
+	// RepairOrphansOnStartup removes orphaned database rows at startup instead of only
+	// reporting them. The same repair is available on demand via `pryx-core doctor --fix`.
+	RepairOrphansOnStartup bool `yaml:"repair_orphans_on_startup"`
+
+	// Timezone is the IANA zone (e.g. "Europe/Berlin") used to display timestamps in CLI
+	// output and exports, and the default for new scheduled tasks. Empty uses the server's
+	// local zone.
+	Timezone string `yaml:"timezone"`
+	// Locale selects the date and time layout for displayed timestamps, e.g. "en-US" or
+	// "de-DE". Empty uses ISO 8601 style dates.
+	Locale string `yaml:"locale"`
 
 	// Memory Management
 	// MaxMessagesPerSession limits the number of messages kept per session (0 = unlimited).
@@ -75,12 +403,26 @@ type Config struct {
 	// WebSocketAllowedOrigins is a list of allowed WebSocket origins.
 	// If empty, defaults to AllowedOrigins.
 	WebSocketAllowedOrigins []string `yaml:"websocket_allowed_origins"`
+	// WebSocketSkipOriginVerify accepts WebSocket upgrades from any origin. It is off
+	// unless set; otherwise only same-host origins and WebSocketAllowedOrigins are accepted.
+	WebSocketSkipOriginVerify *bool `yaml:"websocket_skip_origin_verify"`
 	// MaxWebSocketConnections limits concurrent WebSocket connections (0 = unlimited).
 	MaxWebSocketConnections int `yaml:"max_websocket_connections"`
 	// MaxWebSocketMessageSize sets the maximum message size in bytes (default: 10MB).
 	MaxWebSocketMessageSize int64 `yaml:"max_websocket_message_size"`
-	// WebSocketRateLimitPerMinute sets max connections per minute per IP (default: 60).
+	// WebSocketRateLimitPerMinute sets max connections per minute per IP, and max inbound
+	// messages per minute per connection (default: 60).
 	WebSocketRateLimitPerMinute int `yaml:"websocket_rate_limit_per_minute"`
+	// WebSocketPingInterval is how often WebSocket clients are pinged; a client that
+	// does not answer within the interval is disconnected (default: 30s, negative
+	// disables).
+	WebSocketPingInterval time.Duration `yaml:"websocket_ping_interval"`
+}
+
+// SkipWebSocketOriginVerify reports whether WebSocket upgrades skip the Origin
+// check, which only happens when WebSocketSkipOriginVerify is explicitly enabled.
+func (c *Config) SkipWebSocketOriginVerify() bool {
+	return c.WebSocketSkipOriginVerify != nil && *c.WebSocketSkipOriginVerify
 }
 
 // ProviderKeyNames maps provider IDs to their keychain key names.
@@ -104,6 +446,12 @@ func DefaultPath() string {
 	return filepath.Join(defaultPryxDir(), "config.yaml")
 }
 
+// DefaultProviderWireLogPath returns the default provider wire log file,
+// ~/.pryx/logs/provider-wire.log.
+func DefaultProviderWireLogPath() string {
+	return filepath.Join(defaultPryxDir(), "logs", "provider-wire.log")
+}
+
 func defaultPryxDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil || strings.TrimSpace(home) == "" {
@@ -127,6 +475,11 @@ func Load() *Config {
 		ModelProvider:               "ollama",
 		ModelName:                   "llama3",
 		OllamaEndpoint:              "http://localhost:11434",
+		LLMConnectTimeout:           10 * time.Second,
+		LLMResponseTimeout:          60 * time.Second,
+		LLMRequestTimeout:           120 * time.Second,
+		AgentMaxParallelTools:       4,
+		AgentToolTimeout:            3 * time.Minute,
 		TelegramEnabled:             false,
 		SlackEnabled:                false,
 		SlackAppToken:               "",
@@ -140,6 +493,7 @@ func Load() *Config {
 		MaxWebSocketConnections:     1000,
 		MaxWebSocketMessageSize:     10 * 1024 * 1024, // 10MB
 		WebSocketRateLimitPerMinute: 60,
+		WebSocketPingInterval:       30 * time.Second,
 	}
 
 	// Try loading from default file
@@ -154,12 +508,128 @@ func Load() *Config {
 	if v := os.Getenv("PRYX_LISTEN_ADDR"); v != "" {
 		cfg.ListenAddr = v
 	}
+	if v := os.Getenv("PRYX_PREFERRED_PORT"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.PreferredPort = n
+		}
+	}
 	if v := os.Getenv("PRYX_DB_PATH"); v != "" {
 		cfg.DatabasePath = v
 	}
+	if v := os.Getenv("PRYX_DB_COMPACT_SCHEDULE"); v != "" {
+		cfg.DBCompactSchedule = v
+	}
 	if v := os.Getenv("PRYX_CLOUD_API_URL"); v != "" {
 		cfg.CloudAPIUrl = v
 	}
+	if v := os.Getenv("PRYX_LLM_REQUEST_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.LLMRequestTimeout = d
+		}
+	}
+	if v := os.Getenv("PRYX_MODEL_FALLBACKS"); v != "" {
+		cfg.ModelFallbacks = nil
+		for _, model := range strings.Split(v, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				cfg.ModelFallbacks = append(cfg.ModelFallbacks, model)
+			}
+		}
+	}
+	if v := os.Getenv("PRYX_MODEL_FALLBACK_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.ModelFallbackTimeout = d
+		}
+	}
+	if v := os.Getenv("PRYX_MCP_CALL_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.MCPCallTimeout = d
+		}
+	}
+	if v := os.Getenv("PRYX_MCP_APPROVAL_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.MCPApprovalTTL = d
+		}
+	}
+	if v := os.Getenv("PRYX_IDLE_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.IdleTimeout = d
+		}
+	}
+	if v := os.Getenv("PRYX_WEBSOCKET_SKIP_ORIGIN_VERIFY"); v != "" {
+		skip := v == "true" || v == "1"
+		cfg.WebSocketSkipOriginVerify = &skip
+	}
+	if v := os.Getenv("PRYX_WEBSOCKET_PING_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.WebSocketPingInterval = d
+		}
+	}
+	if v := os.Getenv("PRYX_SCHEDULER_LOCKING"); v != "" {
+		cfg.SchedulerLocking = v == "true" || v == "1"
+	}
+	if v := os.Getenv("PRYX_SCHEDULER_LOCK_LEASE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.SchedulerLockLease = d
+		}
+	}
+	if v := os.Getenv("PRYX_SCHEDULER_MAX_RUNS_PER_TASK"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.SchedulerMaxRunsPerTask = n
+		}
+	}
+	if v := os.Getenv("PRYX_SCHEDULER_RUN_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.SchedulerRunRetention = d
+		}
+	}
+	if v := os.Getenv("PRYX_CHANNEL_ACTIVITY_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.ChannelActivityRetention = d
+		}
+	}
+	if v := os.Getenv("PRYX_CHANNEL_ACTIVITY_MAX_ROWS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.ChannelActivityMaxRows = n
+		}
+	}
+	if v := os.Getenv("PRYX_AUDIT_CONTENT_RETENTION"); v != "" {
+		cfg.AuditContentRetention = v
+	}
+	if v := os.Getenv("PRYX_CHAT_WITHOUT_SESSION"); v != "" {
+		cfg.ChatWithoutSession = v
+	}
+	if v := os.Getenv("PRYX_MAX_CONCURRENT_GENERATIONS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MaxConcurrentGenerations = n
+		}
+	}
+	if v := os.Getenv("PRYX_GENERATION_QUEUE_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.GenerationQueueSize = n
+		}
+	}
+	if v := os.Getenv("PRYX_AUTOMATION_MAX_TURNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.AutomationMaxTurns = n
+		}
+	}
+	if v := os.Getenv("PRYX_INTERACTIVE_MAX_TURNS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.InteractiveMaxTurns = n
+		}
+	}
+	if v := os.Getenv("PRYX_TIMEZONE"); v != "" {
+		cfg.Timezone = v
+	}
+	if v := os.Getenv("PRYX_LOCALE"); v != "" {
+		cfg.Locale = v
+	}
+	if v := os.Getenv("PRYX_CLOUD_PROXY_FALLBACK"); v != "" {
+		cfg.CloudProxyFallback = v == "true" || v == "1"
+	}
+	if v := os.Getenv("PRYX_REPAIR_ORPHANS_ON_STARTUP"); v != "" {
+		cfg.RepairOrphansOnStartup = v == "true" || v == "1"
+	}
 	if v := os.Getenv("PRYX_SLACK_APP_TOKEN"); v != "" {
 		cfg.SlackAppToken = v
 	}
@@ -169,6 +639,15 @@ func Load() *Config {
 	if v := os.Getenv("PRYX_SLACK_ENABLED"); v != "" {
 		cfg.SlackEnabled = true
 	}
+	if v := os.Getenv("PRYX_MATTERMOST_URL"); v != "" {
+		cfg.MattermostURL = v
+	}
+	if v := os.Getenv("PRYX_MATTERMOST_TOKEN"); v != "" {
+		cfg.MattermostToken = v
+	}
+	if v := os.Getenv("PRYX_MATTERMOST_ENABLED"); v != "" {
+		cfg.MattermostEnabled = true
+	}
 
 	_ = os.MkdirAll(pryxDir, 0o755)
 	if strings.TrimSpace(cfg.SkillsPath) != "" {
@@ -53,6 +53,17 @@ func TestLoadFromEnvironment(t *testing.T) {
 	assert.Equal(t, "https://custom.api.com", cfg.CloudAPIUrl)
 }
 
+func TestLoadGenerationQueueSizeFromEnvironment(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PRYX_MAX_CONCURRENT_GENERATIONS", "2")
+	t.Setenv("PRYX_GENERATION_QUEUE_SIZE", "8")
+
+	cfg := Load()
+
+	assert.Equal(t, 2, cfg.MaxConcurrentGenerations)
+	assert.Equal(t, 8, cfg.GenerationQueueSize)
+}
+
 func TestLoadFromEnvironment_Partial(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -268,3 +279,39 @@ model_name: "llama3"
 		_, _ = LoadFromFile(configPath)
 	}
 }
+
+func TestConfig_SkipWebSocketOriginVerify(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:3000", "localhost:3000", "0.0.0.0:3000"} {
+		cfg := &Config{ListenAddr: addr}
+		assert.False(t, cfg.SkipWebSocketOriginVerify(), addr)
+	}
+
+	on := true
+	cfg := &Config{ListenAddr: "127.0.0.1:3000", WebSocketSkipOriginVerify: &on}
+	assert.True(t, cfg.SkipWebSocketOriginVerify())
+
+	t.Setenv("PRYX_WEBSOCKET_SKIP_ORIGIN_VERIFY", "true")
+	loaded := Load()
+	require.NotNil(t, loaded.WebSocketSkipOriginVerify)
+	assert.True(t, loaded.SkipWebSocketOriginVerify())
+}
+
+func TestProviderOverride_Validate(t *testing.T) {
+	valid := ProviderOverride{
+		Headers: map[string]string{"OpenAI-Organization": "org-1", "anthropic-beta": "tools-2024"},
+		Params:  map[string]interface{}{"user": "pryx"},
+	}
+	assert.NoError(t, valid.Validate())
+
+	invalid := []ProviderOverride{
+		{Headers: map[string]string{"Authorization": "Bearer x"}},
+		{Headers: map[string]string{"x-api-key": "k"}},
+		{Headers: map[string]string{"Bad Header": "v"}},
+		{Headers: map[string]string{"X-Gateway": "a\r\nInjected: b"}},
+		{Params: map[string]interface{}{"model": "gpt-4"}},
+		{Params: map[string]interface{}{" ": 1}},
+	}
+	for _, o := range invalid {
+		assert.Error(t, o.Validate(), "%+v", o)
+	}
+}
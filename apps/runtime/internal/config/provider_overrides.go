@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Limits on a single ProviderOverride.
+const (
+	MaxProviderOverrideHeaders = 32
+	MaxProviderOverrideParams  = 32
+)
+
+// ProviderOverride adds headers and default body parameters to every request
+// sent to one provider, e.g. an OpenAI organization header, an Anthropic beta
+// flag or a header a gateway requires.
+type ProviderOverride struct {
+	// Headers are set on each request, replacing headers of the same name the
+	// client would send. Authentication and framing headers cannot be set.
+	Headers map[string]string `yaml:"headers" json:"headers,omitempty"`
+	// Params are added to each JSON request body where the request does not
+	// already set them. The model, messages and stream fields cannot be set.
+	Params map[string]interface{} `yaml:"params" json:"params,omitempty"`
+}
+
+var headerNamePattern = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// protectedProviderHeaders are managed by the provider clients themselves.
+var protectedProviderHeaders = map[string]bool{
+	"Authorization":     true,
+	"X-Api-Key":         true,
+	"Content-Type":      true,
+	"Content-Length":    true,
+	"Host":              true,
+	"Transfer-Encoding": true,
+}
+
+// reservedProviderParams are request fields built from the chat request.
+var reservedProviderParams = map[string]bool{
+	"model":    true,
+	"messages": true,
+	"stream":   true,
+}
+
+// Validate checks header names and values and parameter names.
+func (o ProviderOverride) Validate() error {
+	if len(o.Headers) > MaxProviderOverrideHeaders {
+		return fmt.Errorf("at most %d headers allowed", MaxProviderOverrideHeaders)
+	}
+	if len(o.Params) > MaxProviderOverrideParams {
+		return fmt.Errorf("at most %d params allowed", MaxProviderOverrideParams)
+	}
+	for name, value := range o.Headers {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if protectedProviderHeaders[http.CanonicalHeaderKey(name)] {
+			return fmt.Errorf("header %q cannot be overridden", name)
+		}
+		if strings.ContainsAny(value, "\r\n\x00") {
+			return fmt.Errorf("invalid value for header %q", name)
+		}
+	}
+	for name := range o.Params {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("param name is required")
+		}
+		if reservedProviderParams[name] {
+			return fmt.Errorf("param %q cannot be overridden", name)
+		}
+	}
+	return nil
+}
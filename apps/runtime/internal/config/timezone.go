@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayout is used for displayed timestamps when no locale is set.
+const DefaultTimeLayout = "2006-01-02 15:04:05"
+
+// localeLayouts maps a language or language-region to its date and time layout.
+// Region entries win over language entries.
+var localeLayouts = map[string]string{
+	"en-us": "01/02/2006 3:04:05 PM",
+	"es-us": "01/02/2006 3:04:05 PM",
+	"en-ca": DefaultTimeLayout,
+	"en":    "02/01/2006 15:04:05",
+	"fr":    "02/01/2006 15:04:05",
+	"es":    "02/01/2006 15:04:05",
+	"it":    "02/01/2006 15:04:05",
+	"pt":    "02/01/2006 15:04:05",
+	"nl":    "02-01-2006 15:04:05",
+	"de":    "02.01.2006 15:04:05",
+	"ru":    "02.01.2006 15:04:05",
+	"pl":    "02.01.2006 15:04:05",
+	"cs":    "02.01.2006 15:04:05",
+	"fi":    "02.01.2006 15:04:05",
+	"nb":    "02.01.2006 15:04:05",
+	"tr":    "02.01.2006 15:04:05",
+	"ja":    "2006/01/02 15:04:05",
+	"zh":    "2006/01/02 15:04:05",
+	"ko":    "2006. 01. 02. 15:04:05",
+	"sv":    DefaultTimeLayout,
+}
+
+// LoadTimezone resolves an IANA zone name with time.LoadLocation. An empty name
+// means the server's local zone.
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// ValidateTimezone reports whether name is empty or a zone time.LoadLocation knows.
+func ValidateTimezone(name string) error {
+	_, err := LoadTimezone(name)
+	return err
+}
+
+// Location returns the configured default zone, or the server's local zone when
+// Timezone is empty or invalid.
+func (c *Config) Location() *time.Location {
+	if loc, err := LoadTimezone(c.Timezone); err == nil {
+		return loc
+	}
+	return time.Local
+}
+
+// LocationFor returns the zone for a conversation: the session's own timezone,
+// then the channel's entry in ChannelTimezones, then the default. Invalid names
+// are skipped.
+func (c *Config) LocationFor(channelID, sessionTimezone string) *time.Location {
+	for _, name := range []string{sessionTimezone, c.ChannelTimezones[channelID]} {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		if loc, err := LoadTimezone(name); err == nil {
+			return loc
+		}
+	}
+	return c.Location()
+}
+
+// TimeLayout returns the date and time layout for a locale such as "en-US",
+// "en_GB.UTF-8" or "de". Unknown or empty locales get DefaultTimeLayout.
+func TimeLayout(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ReplaceAll(locale, "_", "-")
+	if layout, ok := localeLayouts[locale]; ok {
+		return layout
+	}
+	lang, _, _ := strings.Cut(locale, "-")
+	if layout, ok := localeLayouts[lang]; ok {
+		return layout
+	}
+	return DefaultTimeLayout
+}
+
+// FormatTime formats t for display in loc using the configured locale. A nil
+// loc uses the default zone.
+func (c *Config) FormatTime(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = c.Location()
+	}
+	return t.In(loc).Format(TimeLayout(c.Locale))
+}
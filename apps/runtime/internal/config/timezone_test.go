@@ -0,0 +1,65 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	if loc, err := LoadTimezone(""); err != nil || loc != time.Local {
+		t.Fatalf("LoadTimezone(\"\") = %v, %v; want time.Local", loc, err)
+	}
+	loc, err := LoadTimezone("Asia/Tokyo")
+	if err != nil || loc.String() != "Asia/Tokyo" {
+		t.Fatalf("LoadTimezone(Asia/Tokyo) = %v, %v", loc, err)
+	}
+	if err := ValidateTimezone("Mars/Olympus_Mons"); err == nil {
+		t.Fatal("expected an error for an unknown zone")
+	}
+}
+
+func TestLocationFor(t *testing.T) {
+	cfg := &Config{
+		Timezone:         "Europe/Berlin",
+		ChannelTimezones: map[string]string{"telegram-ops": "America/New_York", "broken": "Nowhere/Zone"},
+	}
+
+	tests := []struct {
+		channel, session, want string
+	}{
+		{"", "", "Europe/Berlin"},
+		{"telegram-ops", "", "America/New_York"},
+		{"telegram-ops", "Asia/Tokyo", "Asia/Tokyo"},
+		{"broken", "", "Europe/Berlin"},
+		{"", "Nowhere/Zone", "Europe/Berlin"},
+	}
+	for _, tt := range tests {
+		if got := cfg.LocationFor(tt.channel, tt.session).String(); got != tt.want {
+			t.Errorf("LocationFor(%q, %q) = %s, want %s", tt.channel, tt.session, got, tt.want)
+		}
+	}
+
+	if got := (&Config{Timezone: "Nowhere/Zone"}).Location(); got != time.Local {
+		t.Errorf("invalid default zone should fall back to local, got %s", got)
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 17, 5, 6, 0, time.UTC)
+
+	tests := []struct {
+		locale, zone, want string
+	}{
+		{"", "UTC", "2026-03-04 17:05:06"},
+		{"en-US", "America/New_York", "03/04/2026 12:05:06 PM"},
+		{"en_GB.UTF-8", "Europe/London", "04/03/2026 17:05:06"},
+		{"de", "Europe/Berlin", "04.03.2026 18:05:06"},
+		{"xx-YY", "Asia/Tokyo", "2026-03-05 02:05:06"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Locale: tt.locale, Timezone: tt.zone}
+		if got := cfg.FormatTime(ts, nil); got != tt.want {
+			t.Errorf("FormatTime(locale=%q, zone=%q) = %q, want %q", tt.locale, tt.zone, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package contentfilter redacts personal data and blocks disallowed content
+// on its way to and from the model.
+package contentfilter
+
+import (
+	"regexp"
+	"strings"
+
+	"pryx-core/internal/config"
+)
+
+// Stage identifies where in the chat pipeline content is filtered.
+type Stage string
+
+const (
+	// StagePreSend filters user content before it is sent to the provider.
+	StagePreSend Stage = "pre_send"
+	// StagePostReceive filters model output before it is returned to the user.
+	StagePostReceive Stage = "post_receive"
+)
+
+// Match records a rule that matched content.
+type Match struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+}
+
+// Result is the outcome of filtering content.
+type Result struct {
+	// Content is the filtered content. It is empty when Blocked is set.
+	Content string
+	Blocked bool
+	Matches []Match
+}
+
+// Filtered reports whether any rule matched.
+func (r Result) Filtered() bool {
+	return len(r.Matches) > 0
+}
+
+// Hook filters content at a pipeline stage. channel is the channel instance the
+// content belongs to, or empty for direct (TUI/WebSocket) chat.
+type Hook interface {
+	Apply(stage Stage, channel string, content string) Result
+}
+
+type piiPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// piiPatterns are applied in order; card numbers run before phone numbers so
+// long digit runs are labelled as cards.
+var piiPatterns = []piiPattern{
+	{"email", regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	{"ssn", regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	{"credit_card", regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)},
+	{"phone", regexp.MustCompile(`(?:\+\d{1,3}[ .\-]?)?(?:\(\d{2,4}\)[ .\-]?)?\d{3,4}[ .\-]\d{3,4}(?:[ .\-]\d{2,4})?\b`)},
+	{"ipv4", regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)},
+}
+
+type rules struct {
+	redactPII bool
+	deny      []string
+	allow     []string
+}
+
+func compile(r config.ContentFilterRules) *rules {
+	if !r.Enabled() {
+		return nil
+	}
+	return &rules{redactPII: r.RedactPII, deny: lower(r.DenyKeywords), allow: lower(r.AllowKeywords)}
+}
+
+// Filter is the built-in rule-based Hook.
+type Filter struct {
+	defaults *rules
+	channels map[string]*rules
+}
+
+// New builds a Filter from cfg. It returns nil when no rules are configured so
+// callers can skip filtering entirely.
+func New(cfg config.ContentFilterConfig) *Filter {
+	f := &Filter{defaults: compile(cfg.ContentFilterRules), channels: map[string]*rules{}}
+	enabled := f.defaults != nil
+	for name, r := range cfg.Channels {
+		f.channels[name] = compile(r)
+		enabled = enabled || r.Enabled()
+	}
+	if !enabled {
+		return nil
+	}
+	return f
+}
+
+// Apply filters content using the rules for channel. A nil Filter passes content through.
+func (f *Filter) Apply(stage Stage, channel string, content string) Result {
+	if f == nil {
+		return Result{Content: content}
+	}
+	r := f.defaults
+	if cr, ok := f.channels[channel]; ok {
+		r = cr
+	}
+	if r == nil {
+		return Result{Content: content}
+	}
+
+	res := Result{Content: content}
+	if word := r.denied(content); word != "" {
+		return Result{Blocked: true, Matches: []Match{{Rule: "deny:" + word, Action: "block"}}}
+	}
+	if r.redactPII {
+		for _, p := range piiPatterns {
+			if p.re.MatchString(res.Content) {
+				res.Content = p.re.ReplaceAllString(res.Content, "[REDACTED:"+p.name+"]")
+				res.Matches = append(res.Matches, Match{Rule: "pii:" + p.name, Action: "redact"})
+			}
+		}
+	}
+	return res
+}
+
+// denied returns the first deny keyword found in content outside the
+// matches of allow keywords. An allow keyword exempts only the deny matches it
+// overlaps, so other denied words in the same content still block it.
+func (r *rules) denied(content string) string {
+	if len(r.deny) == 0 {
+		return ""
+	}
+	text := strings.ToLower(content)
+	var allowed [][2]int
+	for _, word := range r.allow {
+		for _, i := range indexAll(text, word) {
+			allowed = append(allowed, [2]int{i, i + len(word)})
+		}
+	}
+	for _, word := range r.deny {
+		for _, i := range indexAll(text, word) {
+			if !overlapsAny(i, i+len(word), allowed) {
+				return word
+			}
+		}
+	}
+	return ""
+}
+
+// indexAll returns the start of every, possibly overlapping, match of word in text.
+func indexAll(text, word string) []int {
+	var out []int
+	for i := 0; i < len(text); {
+		j := strings.Index(text[i:], word)
+		if j < 0 {
+			break
+		}
+		out = append(out, i+j)
+		i += j + 1
+	}
+	return out
+}
+
+func overlapsAny(start, end int, spans [][2]int) bool {
+	for _, s := range spans {
+		if start < s[1] && s[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+func lower(words []string) []string {
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			out = append(out, w)
+		}
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package contentfilter
+
+import (
+	"strings"
+	"testing"
+
+	"pryx-core/internal/config"
+)
+
+func TestNewReturnsNilWhenUnconfigured(t *testing.T) {
+	if f := New(config.ContentFilterConfig{}); f != nil {
+		t.Fatalf("expected nil filter for empty config")
+	}
+}
+
+func TestRedactPII(t *testing.T) {
+	f := New(config.ContentFilterConfig{ContentFilterRules: config.ContentFilterRules{RedactPII: true}})
+
+	res := f.Apply(StagePreSend, "", "mail me at jane.doe@example.com or call +1 555-123-4567, card 4111 1111 1111 1111")
+	if res.Blocked {
+		t.Fatalf("unexpected block")
+	}
+	for _, leaked := range []string{"jane.doe@example.com", "555-123-4567", "4111 1111 1111 1111"} {
+		if strings.Contains(res.Content, leaked) {
+			t.Errorf("expected %q to be redacted: %s", leaked, res.Content)
+		}
+	}
+	if !strings.Contains(res.Content, "[REDACTED:email]") || !strings.Contains(res.Content, "[REDACTED:credit_card]") {
+		t.Errorf("missing redaction markers: %s", res.Content)
+	}
+	if !res.Filtered() {
+		t.Errorf("expected matches to be recorded")
+	}
+
+	clean := f.Apply(StagePreSend, "", "nothing personal here")
+	if clean.Filtered() || clean.Content != "nothing personal here" {
+		t.Errorf("clean content was modified: %+v", clean)
+	}
+}
+
+func TestDenyAndAllowKeywords(t *testing.T) {
+	f := New(config.ContentFilterConfig{ContentFilterRules: config.ContentFilterRules{
+		DenyKeywords:  []string{"Password"},
+		AllowKeywords: []string{"password reset"},
+	}})
+
+	res := f.Apply(StagePostReceive, "", "your PASSWORD is hunter2")
+	if !res.Blocked || res.Content != "" || res.Matches[0].Rule != "deny:password" {
+		t.Fatalf("expected block, got %+v", res)
+	}
+	if res := f.Apply(StagePreSend, "", "How do I do a password reset?"); res.Blocked {
+		t.Fatalf("allow keyword should exempt content")
+	}
+}
+
+func TestAllowKeywordOnlyExemptsItsOwnMatch(t *testing.T) {
+	f := New(config.ContentFilterConfig{ContentFilterRules: config.ContentFilterRules{
+		DenyKeywords:  []string{"password", "exploit"},
+		AllowKeywords: []string{"password reset"},
+	}})
+
+	res := f.Apply(StagePreSend, "", "After the password reset, send me the exploit")
+	if !res.Blocked || res.Matches[0].Rule != "deny:exploit" {
+		t.Fatalf("expected the exploit to block, got %+v", res)
+	}
+	res = f.Apply(StagePreSend, "", "Password reset done, the new password is hunter2")
+	if !res.Blocked || res.Matches[0].Rule != "deny:password" {
+		t.Fatalf("expected the second password to block, got %+v", res)
+	}
+}
+
+func TestPerChannelRules(t *testing.T) {
+	f := New(config.ContentFilterConfig{Channels: map[string]config.ContentFilterRules{
+		"telegram-main": {DenyKeywords: []string{"secret"}},
+	}})
+	if f == nil {
+		t.Fatal("expected filter when only channel rules are set")
+	}
+	if res := f.Apply(StagePreSend, "", "a secret"); res.Blocked {
+		t.Errorf("default scope has no rules and should pass")
+	}
+	if res := f.Apply(StagePreSend, "telegram-main", "a secret"); !res.Blocked {
+		t.Errorf("channel rules should block")
+	}
+}
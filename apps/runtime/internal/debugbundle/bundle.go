@@ -0,0 +1,319 @@
+// Package debugbundle builds the zip users attach to bug reports: doctor
+// output, redacted config, versions, schema, recent errors and MCP/channel
+// status, with secrets stripped from every file.
+package debugbundle
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"pryx-core/internal/audit"
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/doctor"
+	"pryx-core/internal/mcp"
+	"pryx-core/internal/store"
+)
+
+// DefaultRecentErrors is how many failed audit entries a bundle includes.
+const DefaultRecentErrors = 100
+
+// VersionInfo identifies the build and platform a bundle came from.
+type VersionInfo struct {
+	Version     string    `json:"version"`
+	BuildDate   string    `json:"build_date"`
+	GoVersion   string    `json:"go_version"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// NewVersionInfo returns version information for the running binary.
+func NewVersionInfo(version, buildDate string) VersionInfo {
+	return VersionInfo{
+		Version:     version,
+		BuildDate:   buildDate,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		GeneratedAt: time.Now().UTC(),
+	}
+}
+
+// ErrorEntry is a failed action from the audit log. Payloads are left out, as
+// they can hold message content and tool arguments.
+type ErrorEntry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Action      string    `json:"action"`
+	Tool        string    `json:"tool,omitempty"`
+	Surface     string    `json:"surface,omitempty"`
+	SessionID   string    `json:"session_id,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// MCPServerStatus describes a configured MCP server. Only the names of its
+// environment variables and headers are kept, never their values.
+type MCPServerStatus struct {
+	Transport  string   `json:"transport"`
+	URL        string   `json:"url,omitempty"`
+	Command    []string `json:"command,omitempty"`
+	EnvKeys    []string `json:"env_keys,omitempty"`
+	HeaderKeys []string `json:"header_keys,omitempty"`
+	AuthType   string   `json:"auth_type,omitempty"`
+	Connected  bool     `json:"connected"`
+	Tools      int      `json:"tools"`
+}
+
+// MCPStatus describes MCP configuration and connection state.
+type MCPStatus struct {
+	ConfigPath string                     `json:"config_path,omitempty"`
+	Servers    map[string]MCPServerStatus `json:"servers"`
+	Error      string                     `json:"error,omitempty"`
+}
+
+// ChannelStatus describes a configured or running channel.
+type ChannelStatus struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name,omitempty"`
+	Enabled bool   `json:"enabled"`
+	Status  string `json:"status,omitempty"`
+}
+
+// Bundle is the content of a debug bundle.
+type Bundle struct {
+	Version  VersionInfo
+	Doctor   doctor.Report
+	Config   *config.Config
+	Schema   *store.SchemaInfo
+	Errors   []ErrorEntry
+	MCP      MCPStatus
+	Channels []ChannelStatus
+	// Events are recent error and trace events seen by a running runtime;
+	// empty when the bundle is built offline.
+	Events []bus.Event
+	// Problems records parts that could not be collected.
+	Problems []string
+}
+
+// AddProblem notes that part of the bundle could not be collected.
+func (b *Bundle) AddProblem(part string, err error) {
+	b.Problems = append(b.Problems, fmt.Sprintf("%s: %v", part, err))
+}
+
+// CollectStore fills the schema and recent errors from s.
+func (b *Bundle) CollectStore(s *store.Store) {
+	if s == nil {
+		b.AddProblem("database", fmt.Errorf("not available"))
+		return
+	}
+	if info, err := s.SchemaInfo(); err != nil {
+		b.AddProblem("schema", err)
+	} else {
+		b.Schema = &info
+	}
+
+	failed := false
+	entries, err := audit.NewAuditRepository(s.DB).Query(audit.QueryOptions{
+		Success:  &failed,
+		Limit:    DefaultRecentErrors,
+		OrderBy:  "timestamp",
+		OrderDir: "DESC",
+	})
+	if err != nil {
+		b.AddProblem("errors", err)
+		return
+	}
+	for _, e := range entries {
+		b.Errors = append(b.Errors, ErrorEntry{
+			Timestamp:   e.Timestamp,
+			Action:      string(e.Action),
+			Tool:        e.Tool,
+			Surface:     e.Surface,
+			SessionID:   e.SessionID,
+			Description: e.Description,
+			Error:       e.ErrorMsg,
+		})
+	}
+}
+
+// CollectMCP fills MCP status from the server configuration on disk and the
+// servers mgr is connected to. mgr may be nil.
+func (b *Bundle) CollectMCP(ctx context.Context, mgr *mcp.Manager) {
+	cfg, path, err := mcp.LoadServersConfigFromFirstExisting(mcp.DefaultServersConfigPaths())
+	if err != nil {
+		b.MCP.Error = err.Error()
+		return
+	}
+	b.MCP.ConfigPath = path
+	b.MCP.Servers = make(map[string]MCPServerStatus, len(cfg.Servers))
+	for name, sc := range cfg.Servers {
+		st := MCPServerStatus{
+			Transport:  sc.Transport,
+			URL:        sc.URL,
+			Command:    sc.Command,
+			EnvKeys:    sortedKeys(sc.Env),
+			HeaderKeys: sortedKeys(sc.Headers),
+		}
+		if sc.Auth != nil {
+			st.AuthType = sc.Auth.Type
+		}
+		b.MCP.Servers[name] = st
+	}
+	if mgr == nil {
+		return
+	}
+
+	tools, err := mgr.ListTools(ctx, false)
+	if err != nil {
+		b.MCP.Error = err.Error()
+		return
+	}
+	for name, list := range tools {
+		st, ok := b.MCP.Servers[name]
+		if !ok {
+			st = MCPServerStatus{Transport: "bundled"}
+		}
+		st.Connected = true
+		st.Tools = len(list)
+		b.MCP.Servers[name] = st
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Write writes b as a zip archive to w. Every file is redacted before it is
+// written.
+func (b *Bundle) Write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		v    interface{}
+	}{
+		{"version.json", b.Version},
+		{"doctor.json", b.Doctor},
+		{"schema.json", map[string]interface{}{"schema": b.Schema}},
+		{"errors.json", map[string]interface{}{"errors": b.Errors}},
+		{"events.json", map[string]interface{}{"events": b.Events}},
+		{"mcp.json", b.MCP},
+		{"channels.json", map[string]interface{}{"channels": b.Channels}},
+		{"problems.json", map[string]interface{}{"problems": b.Problems}},
+	}
+	for _, f := range files {
+		data, err := redactJSON(f.v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		if err := b.writeFile(zw, f.name, data); err != nil {
+			return err
+		}
+	}
+
+	if b.Config != nil {
+		data, err := redactConfig(b.Config)
+		if err != nil {
+			return fmt.Errorf("config.yaml: %w", err)
+		}
+		if err := b.writeFile(zw, "config.yaml", data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (b *Bundle) writeFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: b.Version.GeneratedAt})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// redactConfig renders cfg as YAML with secrets removed.
+func redactConfig(cfg *config.Config) ([]byte, error) {
+	raw, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(Redact(m))
+}
+
+// redactJSON renders v as indented JSON with secrets removed.
+func redactJSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(Redact(m), "", "  ")
+}
+
+// EventRecorder keeps the most recent error and trace events published on a
+// bus, for inclusion in bundles built by the running runtime.
+type EventRecorder struct {
+	size int
+
+	mu     sync.Mutex
+	events []bus.Event
+}
+
+// DefaultRecordedEvents is how many events an EventRecorder keeps by default.
+const DefaultRecordedEvents = 200
+
+// NewEventRecorder returns a recorder that keeps the last size events
+// (DefaultRecordedEvents if size <= 0).
+func NewEventRecorder(size int) *EventRecorder {
+	if size <= 0 {
+		size = DefaultRecordedEvents
+	}
+	return &EventRecorder{size: size}
+}
+
+// Record adds event, dropping the oldest once the recorder is full.
+func (r *EventRecorder) Record(event bus.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	if len(r.events) > r.size {
+		r.events = r.events[len(r.events)-r.size:]
+	}
+}
+
+// Events returns the recorded events, oldest first.
+func (r *EventRecorder) Events() []bus.Event {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]bus.Event(nil), r.events...)
+}
@@ -0,0 +1,98 @@
+package debugbundle
+
+import (
+	"regexp"
+	"strings"
+
+	"pryx-core/internal/security"
+)
+
+// Redacted replaces values removed from a bundle.
+const Redacted = "[REDACTED]"
+
+// safeKeys are bundle fields whose names look sensitive but hold no secrets.
+var safeKeys = map[string]bool{
+	"env_keys":    true,
+	"header_keys": true,
+	"auth_type":   true,
+}
+
+// urlCredentials matches the user:password part of a URL.
+var urlCredentials = regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://)[^/\s:@]+:[^/\s@]+@`)
+
+// valueRedactor scrubs credentials and personal data from free text. It adds
+// to the shared security redactor a catch-all for long opaque tokens, which
+// bundles can afford to lose but the audit log cannot.
+var valueRedactor = func() *security.Redactor {
+	r := security.NewRedactor()
+	r.AddPattern(regexp.MustCompile(`\b[A-Za-z0-9_-]{40,}\b`))
+	return r
+}()
+
+// Redact returns a copy of m with secrets removed: values of fields whose
+// names suggest a secret are replaced, and credentials and personal data in
+// all remaining text are masked.
+func Redact(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if isSensitiveKey(k) {
+			out[k] = redactSecret(v)
+			continue
+		}
+		out[k] = redactValue(v)
+	}
+	return out
+}
+
+// RedactString masks credentials and personal data in s.
+func RedactString(s string) string {
+	s = urlCredentials.ReplaceAllString(s, "${1}"+Redacted+"@")
+	return valueRedactor.RedactString(s)
+}
+
+func isSensitiveKey(key string) bool {
+	return !safeKeys[strings.ToLower(key)] && security.IsSensitiveKey(key)
+}
+
+// redactSecret replaces a secret field's value. Empty values are kept so the
+// bundle shows the field is unset; numbers and booleans cannot hold a secret
+// in pryx configs and are kept too.
+func redactSecret(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil, bool, int, int64, uint64, float64:
+		return v
+	case string:
+		if t == "" {
+			return t
+		}
+	case []interface{}:
+		if len(t) == 0 {
+			return t
+		}
+	case map[string]interface{}:
+		if len(t) == 0 {
+			return t
+		}
+	}
+	return Redacted
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return RedactString(t)
+	case map[string]interface{}:
+		return Redact(t)
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = redactValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"pryx-core/internal/mcp"
 	"pryx-core/internal/policy"
 	"pryx-core/internal/store"
+	"pryx-core/internal/workspace"
 )
 
 type Status string
@@ -43,7 +44,17 @@ func (r *Report) Add(c Check) {
 	r.Checks = append(r.Checks, c)
 }
 
+// Options controls optional doctor behaviour.
+type Options struct {
+	// Fix repairs problems that can be fixed safely, such as orphaned database rows.
+	Fix bool
+}
+
 func Run(ctx context.Context, cfg *config.Config, kc *keychain.Keychain) (Report, int) {
+	return RunWithOptions(ctx, cfg, kc, Options{})
+}
+
+func RunWithOptions(ctx context.Context, cfg *config.Config, kc *keychain.Keychain, opts Options) (Report, int) {
 	rep := Report{}
 
 	rep.Add(checkInstallation())
@@ -54,10 +65,12 @@ func Run(ctx context.Context, cfg *config.Config, kc *keychain.Keychain) (Report
 	rep.Add(dbCheck)
 	if dbConn != nil {
 		defer dbConn.Close()
+		rep.Add(checkIntegrity(dbConn, opts.Fix))
 	}
 
 	rep.Add(checkMCP(ctx, kc))
 	rep.Add(checkChannels())
+	rep.Add(checkWorkspace(cfg))
 
 	exitCode := 0
 	for _, c := range rep.Checks {
@@ -131,6 +144,25 @@ func checkDatabase(cfg *config.Config) (Check, *sql.DB) {
 	return Check{Name: "sqlite", Status: StatusOK, Detail: filepath.Clean(path)}, s.DB
 }
 
+func checkIntegrity(db *sql.DB, fix bool) Check {
+	s := store.NewFromDB(db)
+	report, err := s.CheckIntegrity()
+	if err != nil {
+		return Check{Name: "integrity", Status: StatusFail, Detail: err.Error(), Suggestion: "check the database file for corruption"}
+	}
+	if report.Total() == 0 {
+		return Check{Name: "integrity", Status: StatusOK, Detail: report.String()}
+	}
+	if !fix {
+		return Check{Name: "integrity", Status: StatusWarn, Detail: "orphaned records: " + report.String(), Suggestion: "run `pryx-core doctor --fix` to repair"}
+	}
+	repaired, err := s.RepairOrphans()
+	if err != nil {
+		return Check{Name: "integrity", Status: StatusFail, Detail: err.Error(), Suggestion: "check the database file for corruption"}
+	}
+	return Check{Name: "integrity", Status: StatusOK, Detail: "repaired orphaned records: " + repaired.String()}
+}
+
 func checkMCP(ctx context.Context, kc *keychain.Keychain) Check {
 	p := policy.NewEngine(nil)
 	mgr := mcp.NewManager(nil, p, kc)
@@ -163,6 +195,21 @@ func checkChannels() Check {
 	return Check{Name: "channels", Status: StatusWarn, Detail: "no channel configuration found", Suggestion: "create .pryx/channels.json to enable channels"}
 }
 
+func checkWorkspace(cfg *config.Config) Check {
+	root := ""
+	if cfg != nil {
+		root = cfg.WorkspaceRoot
+	}
+	ws, err := workspace.Open(root)
+	if err != nil {
+		return Check{Name: "workspace", Status: StatusFail, Detail: err.Error(), Suggestion: "set workspace_root to a valid directory"}
+	}
+	if err := ws.CheckWritable(); err != nil {
+		return Check{Name: "workspace", Status: StatusFail, Detail: err.Error(), Suggestion: "check permissions on " + ws.Root()}
+	}
+	return Check{Name: "workspace", Status: StatusOK, Detail: ws.Root()}
+}
+
 func healthURL(listenAddr string) string {
 	addr := strings.TrimSpace(listenAddr)
 	if strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://") {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"pryx-core/internal/config"
+	"pryx-core/internal/store"
 )
 
 func TestCheckInstallation(t *testing.T) {
@@ -82,6 +83,36 @@ func TestCheckDatabaseMissingPath(t *testing.T) {
 	}
 }
 
+func TestCheckIntegrity(t *testing.T) {
+	s, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	check := checkIntegrity(s.DB, false)
+	if check.Status != StatusOK {
+		t.Errorf("Expected status OK for a clean database, got %s: %s", check.Status, check.Detail)
+	}
+
+	if _, err := s.DB.Exec(`INSERT INTO messages (id, session_id, role, content) VALUES ('m1', 'gone', 'user', 'x')`); err != nil {
+		t.Fatalf("Failed to insert orphan: %v", err)
+	}
+
+	check = checkIntegrity(s.DB, false)
+	if check.Status != StatusWarn || check.Suggestion == "" {
+		t.Errorf("Expected warning with suggestion for orphaned rows, got %s: %s", check.Status, check.Detail)
+	}
+
+	check = checkIntegrity(s.DB, true)
+	if check.Status != StatusOK {
+		t.Errorf("Expected status OK after fix, got %s: %s", check.Status, check.Detail)
+	}
+	if report, _ := s.CheckIntegrity(); report.Total() != 0 {
+		t.Errorf("Expected orphans to be repaired, got %s", report)
+	}
+}
+
 func TestHealthURL(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -137,3 +168,15 @@ func TestCheckChannelsNotFound(t *testing.T) {
 		t.Errorf("Expected status Warn for missing channels config, got %s", check.Status)
 	}
 }
+
+func TestCheckWorkspace(t *testing.T) {
+	cfg := &config.Config{WorkspaceRoot: t.TempDir()}
+	check := checkWorkspace(cfg)
+
+	if check.Name != "workspace" {
+		t.Errorf("Expected check name 'workspace', got '%s'", check.Name)
+	}
+	if check.Status != StatusOK {
+		t.Errorf("Expected status OK, got %s (%s)", check.Status, check.Detail)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"sync"
 
+	"pryx-core/internal/secrets"
+
 	"github.com/zalando/go-keyring"
 )
 
@@ -126,10 +128,15 @@ func (k *Keychain) SetProviderKey(provider, key string) error {
 }
 
 // GetProviderKey retrieves the API key for the specified LLM provider.
+// A stored secret reference (env://, file://, keychain://) is resolved to the key it points at.
 // Returns an error if the key is not found.
 func (k *Keychain) GetProviderKey(provider string) (string, error) {
 	keyName := fmt.Sprintf("provider:%s", provider)
-	return k.Get(keyName)
+	value, err := k.Get(keyName)
+	if err != nil || !secrets.IsRef(value) {
+		return value, err
+	}
+	return secrets.NewResolver(k).Resolve(value)
 }
 
 // DeleteProviderKey removes the API key for the specified LLM provider.
@@ -0,0 +1,231 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default limits for the response cache.
+const (
+	DefaultCacheTTL        = 10 * time.Minute
+	DefaultCacheMaxEntries = 256
+)
+
+type cacheCtxKey struct{}
+
+// WithCache returns a context that enables or disables response caching for requests made with it.
+func WithCache(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, cacheCtxKey{}, enabled)
+}
+
+// cacheEnabled reports whether caching is allowed for ctx. Caching is on unless explicitly disabled.
+func cacheEnabled(ctx context.Context) bool {
+	if v, ok := ctx.Value(cacheCtxKey{}).(bool); ok {
+		return v
+	}
+	return true
+}
+
+// CacheKey returns the cache key for req and whether the request is cacheable.
+// Only deterministic requests, those explicitly setting temperature 0, are
+// cacheable; an unset temperature means the provider's default, which is not.
+func CacheKey(req ChatRequest) (string, bool) {
+	if req.Temperature == nil || *req.Temperature != 0 {
+		return "", false
+	}
+	normalized := make([]Message, len(req.Messages))
+	for i, m := range req.Messages {
+		normalized[i] = Message{Role: m.Role, Content: strings.TrimSpace(m.Content), ToolCalls: m.ToolCalls, ToolCallID: m.ToolCallID}
+	}
+	data, err := json.Marshal(struct {
+		Model           string          `json:"model"`
+		Messages        []Message       `json:"messages"`
+		MaxTokens       int             `json:"max_tokens"`
+		ReasoningEffort ReasoningEffort `json:"reasoning_effort"`
+		Tools           []Tool          `json:"tools,omitempty"`
+	}{
+		Model:           strings.TrimSpace(req.Model),
+		Messages:        normalized,
+		MaxTokens:       req.MaxTokens,
+		ReasoningEffort: req.ReasoningEffort,
+		Tools:           req.Tools,
+	})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), true
+}
+
+type cacheEntry struct {
+	key       string
+	resp      ChatResponse
+	expiresAt time.Time
+}
+
+// ResponseCache is a bounded, TTL-based LRU cache of chat responses.
+// It is safe for concurrent use.
+type ResponseCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+// NewResponseCache creates a cache with the given TTL and size limit.
+// Non-positive values fall back to the defaults.
+func NewResponseCache(ttl time.Duration, maxEntries int) *ResponseCache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheMaxEntries
+	}
+	return &ResponseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached response for key if present and not expired.
+func (c *ResponseCache) Get(key string) (*ChatResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	resp := entry.resp
+	return &resp, true
+}
+
+// Put stores resp under key, evicting the least recently used entry when full.
+func (c *ResponseCache) Put(key string, resp ChatResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.resp = resp
+		entry.expiresAt = time.Now().Add(c.ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&cacheEntry{key: key, resp: resp, expiresAt: time.Now().Add(c.ttl)})
+	c.entries[key] = el
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns the number of cached entries.
+func (c *ResponseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// CachingProvider wraps a Provider and serves repeated deterministic requests from a ResponseCache.
+type CachingProvider struct {
+	inner Provider
+	cache *ResponseCache
+	onHit func(ctx context.Context, req ChatRequest, resp *ChatResponse)
+}
+
+// NewCachingProvider wraps inner with cache. onHit, if non-nil, is called for every cache hit.
+func NewCachingProvider(inner Provider, cache *ResponseCache, onHit func(ctx context.Context, req ChatRequest, resp *ChatResponse)) *CachingProvider {
+	return &CachingProvider{inner: inner, cache: cache, onHit: onHit}
+}
+
+// Complete returns a cached response when available, otherwise calls the wrapped provider.
+func (p *CachingProvider) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	key, ok := p.lookupKey(ctx, req)
+	if ok {
+		if resp, hit := p.cache.Get(key); hit {
+			p.hit(ctx, req, resp)
+			return resp, nil
+		}
+	}
+
+	resp, err := p.inner.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		p.cache.Put(key, *resp)
+	}
+	return resp, nil
+}
+
+// Stream replays a cached response as a single chunk, or streams from the wrapped
+// provider and caches the assembled response once it completes without error.
+func (p *CachingProvider) Stream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
+	key, ok := p.lookupKey(ctx, req)
+	if ok {
+		if resp, hit := p.cache.Get(key); hit {
+			p.hit(ctx, req, resp)
+			ch := make(chan StreamChunk, 1)
+			ch <- StreamChunk{Content: resp.Content, Done: true, ToolCalls: resp.ToolCalls}
+			close(ch)
+			return ch, nil
+		}
+	}
+
+	upstream, err := p.inner.Stream(ctx, req)
+	if err != nil || !ok {
+		return upstream, err
+	}
+
+	ch := make(chan StreamChunk)
+	go func() {
+		defer close(ch)
+		var content strings.Builder
+		for chunk := range upstream {
+			content.WriteString(chunk.Content)
+			if chunk.Done && chunk.Err == nil {
+				p.cache.Put(key, ChatResponse{Content: content.String(), Role: RoleAssistant, FinishReason: "stop", ToolCalls: chunk.ToolCalls})
+			}
+			select {
+			case ch <- chunk:
+			case <-ctx.Done():
+				for range upstream {
+				}
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (p *CachingProvider) lookupKey(ctx context.Context, req ChatRequest) (string, bool) {
+	if p.cache == nil || !cacheEnabled(ctx) {
+		return "", false
+	}
+	return CacheKey(req)
+}
+
+func (p *CachingProvider) hit(ctx context.Context, req ChatRequest, resp *ChatResponse) {
+	if p.onHit != nil {
+		p.onHit(ctx, req, resp)
+	}
+}
@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// zero is an explicit temperature of 0, which makes a request cacheable.
+var zero = 0.0
+
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.calls++
+	return &ChatResponse{Content: "hello", Role: RoleAssistant}, nil
+}
+
+func (p *countingProvider) Stream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
+	p.calls++
+	ch := make(chan StreamChunk, 2)
+	ch <- StreamChunk{Content: "hel"}
+	ch <- StreamChunk{Content: "lo", Done: true}
+	close(ch)
+	return ch, nil
+}
+
+func TestCachingProvider_Complete(t *testing.T) {
+	inner := &countingProvider{}
+	hits := 0
+	p := NewCachingProvider(inner, NewResponseCache(time.Minute, 10), func(ctx context.Context, req ChatRequest, resp *ChatResponse) {
+		hits++
+	})
+
+	req := ChatRequest{Model: "m", Temperature: &zero, Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	for i := 0; i < 3; i++ {
+		resp, err := p.Complete(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		if resp.Content != "hello" {
+			t.Errorf("Content = %q, want %q", resp.Content, "hello")
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("inner calls = %d, want 1", inner.calls)
+	}
+	if hits != 2 {
+		t.Errorf("hits = %d, want 2", hits)
+	}
+}
+
+func TestCachingProvider_SkipsNonDeterministic(t *testing.T) {
+	inner := &countingProvider{}
+	p := NewCachingProvider(inner, NewResponseCache(time.Minute, 10), nil)
+
+	warm := 0.7
+	for _, temperature := range []*float64{&warm, nil} {
+		inner.calls = 0
+		req := ChatRequest{Model: "m", Temperature: temperature, Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+		_, _ = p.Complete(context.Background(), req)
+		_, _ = p.Complete(context.Background(), req)
+		if inner.calls != 2 {
+			t.Errorf("inner calls = %d, want 2", inner.calls)
+		}
+	}
+}
+
+func TestCacheKey_ReasoningEffort(t *testing.T) {
+	req := ChatRequest{Model: "m", Temperature: &zero, Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	low, ok := CacheKey(req)
+	if !ok {
+		t.Fatal("expected an explicit temperature 0 request to be cacheable")
+	}
+	req.ReasoningEffort = ReasoningHigh
+	high, _ := CacheKey(req)
+	if low == high {
+		t.Error("expected the reasoning effort to change the cache key")
+	}
+}
+
+func TestCachingProvider_DisabledByContext(t *testing.T) {
+	inner := &countingProvider{}
+	p := NewCachingProvider(inner, NewResponseCache(time.Minute, 10), nil)
+
+	ctx := WithCache(context.Background(), false)
+	req := ChatRequest{Model: "m", Temperature: &zero, Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	_, _ = p.Complete(ctx, req)
+	_, _ = p.Complete(ctx, req)
+	if inner.calls != 2 {
+		t.Errorf("inner calls = %d, want 2", inner.calls)
+	}
+}
+
+func TestCachingProvider_Stream(t *testing.T) {
+	inner := &countingProvider{}
+	p := NewCachingProvider(inner, NewResponseCache(time.Minute, 10), nil)
+	req := ChatRequest{Model: "m", Temperature: &zero, Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+
+	for i := 0; i < 2; i++ {
+		ch, err := p.Stream(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		var got string
+		for chunk := range ch {
+			got += chunk.Content
+		}
+		if got != "hello" {
+			t.Errorf("streamed = %q, want %q", got, "hello")
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("inner calls = %d, want 1", inner.calls)
+	}
+}
+
+func TestResponseCache_EvictsAndExpires(t *testing.T) {
+	c := NewResponseCache(20*time.Millisecond, 2)
+	c.Put("a", ChatResponse{Content: "a"})
+	c.Put("b", ChatResponse{Content: "b"})
+	c.Put("c", ChatResponse{Content: "c"})
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("expected oldest entry to be evicted")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected entry to expire")
+	}
+}
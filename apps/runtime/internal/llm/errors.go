@@ -0,0 +1,21 @@
+package llm
+
+import "errors"
+
+// ErrTimeout is returned (wrapped) when a provider request exceeds its configured timeout.
+var ErrTimeout = errors.New("llm request timed out")
+
+// ErrUnavailable is returned (wrapped) when a provider answers with a 5xx server error.
+var ErrUnavailable = errors.New("llm provider unavailable")
+
+// IsTimeout reports whether err was caused by a provider request timing out.
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout)
+}
+
+// IsTransient reports whether err is a provider failure that another attempt,
+// possibly with another model, may not hit: a server error, a rate limit or
+// a timeout.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrUnavailable) || IsRateLimited(err) || IsTimeout(err)
+}
@@ -0,0 +1,77 @@
+package factory
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"pryx-core/internal/llm"
+	"pryx-core/internal/llm/providers"
+	"pryx-core/internal/models"
+)
+
+// CloudProxyPath is the path on the Pryx Cloud API under which generation
+// requests are proxied. Each provider gets an OpenAI-compatible endpoint at
+// CloudProxyPath + "/" + providerID.
+const CloudProxyPath = "/v1/llm"
+
+// ErrCloudNotLoggedIn is returned by the cloud proxy when no cloud token is stored.
+var ErrCloudNotLoggedIn = errors.New("not logged in to Pryx Cloud")
+
+// CloudProxyProvider sends generation requests through the Pryx Cloud API,
+// authenticated with the cloud access token instead of a provider key. The
+// token is read on every request so a re-login or logout takes effect at once.
+type CloudProxyProvider struct {
+	baseURL    string
+	providerID string
+	token      func() (string, error)
+}
+
+// NewCloudProxy returns a provider that proxies providerID's models through the
+// cloud API at cloudAPIURL. token returns the current cloud access token.
+func NewCloudProxy(cloudAPIURL, providerID string, token func() (string, error)) *CloudProxyProvider {
+	return &CloudProxyProvider{
+		baseURL:    strings.TrimSuffix(cloudAPIURL, "/") + CloudProxyPath + "/" + providerID,
+		providerID: providerID,
+		token:      token,
+	}
+}
+
+// Complete performs a non-streaming completion through the cloud proxy.
+func (p *CloudProxyProvider) Complete(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	inner, err := p.inner()
+	if err != nil {
+		return nil, err
+	}
+	return inner.Complete(ctx, req)
+}
+
+// Stream performs a streaming completion through the cloud proxy.
+func (p *CloudProxyProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	inner, err := p.inner()
+	if err != nil {
+		return nil, err
+	}
+	return inner.Stream(ctx, req)
+}
+
+func (p *CloudProxyProvider) inner() (llm.Provider, error) {
+	token, err := p.token()
+	if err != nil || strings.TrimSpace(token) == "" {
+		return nil, ErrCloudNotLoggedIn
+	}
+	return providers.NewOpenAI(token, p.baseURL).WithProviderID(p.providerID), nil
+}
+
+// HasCredentials reports whether a key or OAuth token for providerID is
+// available locally, from the keychain or the environment. Ollama needs none.
+func (f *ProviderFactory) HasCredentials(providerID string) bool {
+	if providerID == ProviderOllama {
+		return true
+	}
+	var providerInfo models.ProviderInfo
+	if f.catalog != nil {
+		providerInfo, _ = f.catalog.GetProvider(providerID)
+	}
+	return strings.TrimSpace(f.resolveAPIKey(providerID, "", providerInfo)) != ""
+}
@@ -54,14 +54,14 @@ func (f *ProviderFactory) CreateProvider(providerID, modelID, apiKey string) (ll
 	switch implType {
 	case "openai", "openai-compatible":
 		baseURL := f.getBaseURL(providerID, providerInfo)
-		return providers.NewOpenAI(apiKey, baseURL), nil
+		return providers.NewOpenAI(apiKey, baseURL).WithProviderID(providerID), nil
 
 	case "anthropic":
 		return providers.NewAnthropic(apiKey), nil
 
 	default:
 		baseURL := f.getBaseURL(providerID, providerInfo)
-		return providers.NewOpenAI(apiKey, baseURL), nil
+		return providers.NewOpenAI(apiKey, baseURL).WithProviderID(providerID), nil
 	}
 }
 
@@ -83,11 +83,11 @@ func (f *ProviderFactory) CreateProviderFromConfig(providerID, apiKey string) (l
 
 	switch implType {
 	case "openai", "openai-compatible":
-		return providers.NewOpenAI(apiKey, baseURL), nil
+		return providers.NewOpenAI(apiKey, baseURL).WithProviderID(providerID), nil
 	case "anthropic":
 		return providers.NewAnthropic(apiKey), nil
 	default:
-		return providers.NewOpenAI(apiKey, baseURL), nil
+		return providers.NewOpenAI(apiKey, baseURL).WithProviderID(providerID), nil
 	}
 }
 
@@ -294,22 +294,22 @@ func NewProvider(pt string, apiKey string, baseURL string) (llm.Provider, error)
 				baseURL = "https://api.openai.com/v1"
 			}
 		}
-		return providers.NewOpenAI(apiKey, baseURL), nil
+		return providers.NewOpenAI(apiKey, baseURL).WithProviderID(pt), nil
 
 	case ProviderAnthropic:
 		return providers.NewAnthropic(apiKey), nil
 
 	case ProviderOpenRouter:
-		return providers.NewOpenAI(apiKey, "https://openrouter.ai/api/v1"), nil
+		return providers.NewOpenAI(apiKey, "https://openrouter.ai/api/v1").WithProviderID(pt), nil
 
 	case ProviderOllama:
 		if baseURL == "" {
 			baseURL = "http://localhost:11434"
 		}
-		return providers.NewOpenAI(apiKey, ensureV1Suffix(baseURL)), nil
+		return providers.NewOpenAI(apiKey, ensureV1Suffix(baseURL)).WithProviderID(pt), nil
 
 	case ProviderGLM:
-		return providers.NewOpenAI(apiKey, "https://open.bigmodel.cn/api/paas/v4"), nil
+		return providers.NewOpenAI(apiKey, "https://open.bigmodel.cn/api/paas/v4").WithProviderID(pt), nil
 
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", pt)
@@ -1,9 +1,14 @@
 package factory
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
+	"pryx-core/internal/llm"
 	"pryx-core/internal/llm/providers"
 )
 
@@ -60,3 +65,34 @@ func TestNewProvider_CustomBaseURL(t *testing.T) {
 		t.Errorf("Expected providers.OpenAIProvider type")
 	}
 }
+
+func TestCloudProxyProvider(t *testing.T) {
+	var gotPath, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"total_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	token := "cloud-token"
+	p := NewCloudProxy(srv.URL+"/", "openai", func() (string, error) { return token, nil })
+	resp, err := p.Complete(context.Background(), llm.ChatRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if resp.Content != "hi" || resp.Usage.TotalTokens != 7 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if gotPath != CloudProxyPath+"/openai/chat/completions" {
+		t.Errorf("request path = %s", gotPath)
+	}
+	if gotAuth != "Bearer cloud-token" {
+		t.Errorf("Authorization = %q, want the cloud token", gotAuth)
+	}
+
+	token = ""
+	if _, err := p.Complete(context.Background(), llm.ChatRequest{Model: "gpt-4o"}); !errors.Is(err, ErrCloudNotLoggedIn) {
+		t.Errorf("expected ErrCloudNotLoggedIn after logout, got %v", err)
+	}
+}
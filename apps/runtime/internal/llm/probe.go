@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotStreaming is returned (wrapped) by ProbeStream when a provider accepts
+// a streaming request but sends no tokens back as a stream.
+var ErrNotStreaming = errors.New("provider did not stream a reply")
+
+// probePrompt asks for a reply short enough to keep the probe cheap.
+const probePrompt = "Count from 1 to 5, separated by spaces."
+
+// StreamProbe describes how a provider streamed a short reply.
+type StreamProbe struct {
+	Model string `json:"model"`
+	// Chunks counts the chunks that carried content.
+	Chunks int `json:"chunks"`
+	// FirstToken is the time until the first content arrived.
+	FirstToken time.Duration `json:"first_token"`
+	// Total is the time until the stream finished.
+	Total   time.Duration `json:"total"`
+	Content string        `json:"content"`
+}
+
+// Incremental reports whether the reply arrived in more than one chunk. A
+// reply delivered whole suggests the response was buffered on the way.
+func (p StreamProbe) Incremental() bool {
+	return p.Chunks > 1
+}
+
+// ProbeStream sends a tiny streaming request for model and times the reply.
+// It gives up when ctx is done, so a provider that holds the response back
+// fails with ErrNotStreaming instead of hanging.
+func ProbeStream(ctx context.Context, provider Provider, model string) (StreamProbe, error) {
+	probe := StreamProbe{Model: model}
+	start := time.Now()
+	stream, err := provider.Stream(ctx, ChatRequest{
+		Model:     model,
+		Messages:  []Message{{Role: RoleUser, Content: probePrompt}},
+		MaxTokens: 16,
+		Stream:    true,
+	})
+	if err != nil {
+		return probe, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			probe.Total = time.Since(start)
+			if probe.Chunks == 0 {
+				return probe, fmt.Errorf("%w: no tokens within %s", ErrNotStreaming, probe.Total.Round(time.Millisecond))
+			}
+			return probe, fmt.Errorf("stream did not finish: %w", ctx.Err())
+		case chunk, ok := <-stream:
+			if ok && chunk.Err != nil {
+				probe.Total = time.Since(start)
+				if probe.Chunks == 0 {
+					return probe, fmt.Errorf("%w: %v", ErrNotStreaming, chunk.Err)
+				}
+				return probe, chunk.Err
+			}
+			if ok && chunk.Content != "" {
+				if probe.Chunks == 0 {
+					probe.FirstToken = time.Since(start)
+				}
+				probe.Chunks++
+				probe.Content += chunk.Content
+			}
+			if !ok || chunk.Done {
+				probe.Total = time.Since(start)
+				if probe.Chunks == 0 {
+					return probe, fmt.Errorf("%w: the stream ended without content", ErrNotStreaming)
+				}
+				return probe, nil
+			}
+		}
+	}
+}
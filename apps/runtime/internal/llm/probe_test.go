@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// scriptedProvider streams chunks, or holds the stream open when chunks is nil.
+type scriptedProvider struct {
+	chunks []StreamChunk
+}
+
+func (p *scriptedProvider) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *scriptedProvider) Stream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
+	ch := make(chan StreamChunk, len(p.chunks))
+	if p.chunks == nil {
+		return ch, nil
+	}
+	for _, c := range p.chunks {
+		ch <- c
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestProbeStream(t *testing.T) {
+	probe, err := ProbeStream(context.Background(), &scriptedProvider{chunks: []StreamChunk{
+		{Content: "1 2"}, {Content: " 3 4"}, {Content: " 5", Done: true},
+	}}, "m")
+	if err != nil {
+		t.Fatalf("ProbeStream failed: %v", err)
+	}
+	if probe.Chunks != 3 || probe.Content != "1 2 3 4 5" || !probe.Incremental() {
+		t.Errorf("unexpected probe: %+v", probe)
+	}
+	if probe.FirstToken > probe.Total {
+		t.Errorf("first token after the end of the stream: %+v", probe)
+	}
+}
+
+func TestProbeStream_NotStreaming(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []StreamChunk
+	}{
+		{"error before any token", []StreamChunk{{Err: io.EOF}}},
+		{"empty stream", []StreamChunk{{Done: true}}},
+		{"held back", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, err := ProbeStream(ctx, &scriptedProvider{chunks: tt.chunks}, "m")
+			if !errors.Is(err, ErrNotStreaming) {
+				t.Fatalf("expected ErrNotStreaming, got %v", err)
+			}
+		})
+	}
+}
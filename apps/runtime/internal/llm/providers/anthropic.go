@@ -23,6 +23,14 @@ func NewAnthropic(apiKey string) *AnthropicProvider {
 const anthropicURL = "https://api.anthropic.com/v1/messages"
 const anthropicVersion = "2023-06-01"
 
+// anthropicThinkingBudgets maps reasoning efforts to extended thinking token
+// budgets; 1024 is the smallest budget Anthropic accepts.
+var anthropicThinkingBudgets = map[llm.ReasoningEffort]int{
+	llm.ReasoningLow:    1024,
+	llm.ReasoningMedium: 4096,
+	llm.ReasoningHigh:   16384,
+}
+
 func (p *AnthropicProvider) Complete(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
 	req.Stream = false
 	respBody, err := p.sendRequest(ctx, req)
@@ -33,8 +41,11 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req llm.ChatRequest) (
 
 	var apiResp struct {
 		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			ID    string          `json:"id"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
 		} `json:"content"`
 		Role       string    `json:"role"`
 		StopReason string    `json:"stop_reason"`
@@ -49,11 +60,26 @@ func (p *AnthropicProvider) Complete(ctx context.Context, req llm.ChatRequest) (
 		return nil, fmt.Errorf("no content returned")
 	}
 
+	// With extended thinking the answer follows the thinking blocks
+	text := apiResp.Content[0].Text
+	found := false
+	var calls []llm.ToolCall
+	for _, block := range apiResp.Content {
+		switch {
+		case block.Type == "text" && !found:
+			text = block.Text
+			found = true
+		case block.Type == "tool_use":
+			calls = append(calls, llm.ToolCall{ID: block.ID, Name: block.Name, Arguments: string(block.Input)})
+		}
+	}
+
 	return &llm.ChatResponse{
-		Content:      apiResp.Content[0].Text,
+		Content:      text,
 		Role:         llm.RoleAssistant,
 		FinishReason: apiResp.StopReason,
 		Usage:        apiResp.Usage,
+		ToolCalls:    calls,
 	}, nil
 }
 
@@ -69,10 +95,17 @@ func (p *AnthropicProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-
 		defer close(ch)
 		defer respBody.Close()
 
+		// Tool calls are opened by content_block_start and their input
+		// streamed as JSON fragments, keyed by the block index
+		var calls []llm.ToolCall
+		callAt := map[int]int{}
 		reader := bufio.NewReader(respBody)
 		for {
 			line, err := reader.ReadBytes('\n')
 			if err != nil {
+				if isTimeout(err) {
+					err = fmt.Errorf("%w: %v", llm.ErrTimeout, err)
+				}
 				ch <- llm.StreamChunk{Err: err}
 				return
 			}
@@ -89,9 +122,16 @@ func (p *AnthropicProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-
 
 			var event struct {
 				Type  string `json:"type"`
+				Index int    `json:"index"`
 				Delta struct {
-					Text string `json:"text"`
+					Text        string `json:"text"`
+					PartialJSON string `json:"partial_json"`
 				} `json:"delta"`
+				ContentBlock struct {
+					Type string `json:"type"`
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"content_block"`
 			}
 
 			if err := json.Unmarshal(data, &event); err != nil {
@@ -99,12 +139,20 @@ func (p *AnthropicProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-
 			}
 
 			switch event.Type {
+			case "content_block_start":
+				if event.ContentBlock.Type == "tool_use" {
+					callAt[event.Index] = len(calls)
+					calls = append(calls, llm.ToolCall{ID: event.ContentBlock.ID, Name: event.ContentBlock.Name})
+				}
 			case "content_block_delta":
 				if event.Delta.Text != "" {
 					ch <- llm.StreamChunk{Content: event.Delta.Text}
 				}
+				if i, ok := callAt[event.Index]; ok {
+					calls[i].Arguments += event.Delta.PartialJSON
+				}
 			case "message_stop":
-				ch <- llm.StreamChunk{Done: true}
+				ch <- llm.StreamChunk{Done: true, ToolCalls: calls}
 				return
 			}
 		}
@@ -113,16 +161,78 @@ func (p *AnthropicProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-
 	return ch, nil
 }
 
+// anthropicMessage is a message in the Messages API format; Content is a
+// string or a list of content blocks.
+type anthropicMessage struct {
+	Role    llm.Role    `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// anthropicMessages converts messages to the Messages API format. Tool calls
+// become tool_use blocks, and as the API has no tool role, tool results become
+// tool_result blocks of a user message, one per assistant turn.
+func anthropicMessages(messages []llm.Message) []anthropicMessage {
+	out := make([]anthropicMessage, 0, len(messages))
+	results := -1
+	for _, m := range messages {
+		switch {
+		case m.Role == llm.RoleTool:
+			block := map[string]interface{}{"type": "tool_result", "tool_use_id": m.ToolCallID, "content": m.Content}
+			if results >= 0 && results == len(out)-1 {
+				out[results].Content = append(out[results].Content.([]map[string]interface{}), block)
+				continue
+			}
+			results = len(out)
+			out = append(out, anthropicMessage{Role: llm.RoleUser, Content: []map[string]interface{}{block}})
+		case len(m.ToolCalls) > 0:
+			var blocks []map[string]interface{}
+			if m.Content != "" {
+				blocks = append(blocks, map[string]interface{}{"type": "text", "text": m.Content})
+			}
+			for _, call := range m.ToolCalls {
+				input := json.RawMessage(call.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, map[string]interface{}{"type": "tool_use", "id": call.ID, "name": call.Name, "input": input})
+			}
+			out = append(out, anthropicMessage{Role: m.Role, Content: blocks})
+		default:
+			out = append(out, anthropicMessage{Role: m.Role, Content: m.Content})
+		}
+	}
+	return out
+}
+
 func (p *AnthropicProvider) sendRequest(ctx context.Context, req llm.ChatRequest) (io.ReadCloser, error) {
 	payload := map[string]interface{}{
 		"model":      req.Model,
-		"messages":   req.Messages,
+		"messages":   anthropicMessages(req.Messages),
 		"max_tokens": req.MaxTokens,
 		"stream":     req.Stream,
 	}
+	if len(req.Tools) > 0 {
+		tools := make([]map[string]interface{}, 0, len(req.Tools))
+		for _, t := range req.Tools {
+			schema := t.Parameters
+			if schema == nil {
+				schema = map[string]interface{}{"type": "object"}
+			}
+			tools = append(tools, map[string]interface{}{"name": t.Name, "description": t.Description, "input_schema": schema})
+		}
+		payload["tools"] = tools
+	}
 	if req.MaxTokens == 0 {
 		payload["max_tokens"] = 1000
 	}
+	if budget, ok := anthropicThinkingBudgets[req.ReasoningEffort]; ok {
+		payload["thinking"] = map[string]interface{}{"type": "enabled", "budget_tokens": budget}
+		// The thinking budget counts against max_tokens, which must exceed it
+		payload["max_tokens"] = budget + payload["max_tokens"].(int)
+	} else if req.Temperature != nil {
+		// Extended thinking only runs at the default temperature
+		payload["temperature"] = *req.Temperature
+	}
 
 	bodyBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -138,16 +248,14 @@ func (p *AnthropicProvider) sendRequest(ctx context.Context, req llm.ChatRequest
 	httpReq.Header.Set("x-api-key", p.apiKey)
 	httpReq.Header.Set("anthropic-version", anthropicVersion)
 
-	resp, err := SharedHTTPClient.Do(httpReq)
+	resp, err := doRequest("anthropic", httpReq)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		return nil, fmt.Errorf("api error: %s - %s", resp.Status, buf.String())
+		return nil, apiError(resp)
 	}
 
 	return resp.Body, nil
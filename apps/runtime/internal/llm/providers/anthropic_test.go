@@ -0,0 +1,33 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"pryx-core/internal/llm"
+)
+
+func TestAnthropicMessages(t *testing.T) {
+	got := anthropicMessages([]llm.Message{
+		{Role: llm.RoleUser, Content: "Read both files"},
+		{Role: llm.RoleAssistant, Content: "Reading", ToolCalls: []llm.ToolCall{
+			{ID: "t1", Name: "fs__read", Arguments: `{"path":"a"}`},
+			{ID: "t2", Name: "fs__read", Arguments: `bad`},
+		}},
+		{Role: llm.RoleTool, ToolCallID: "t1", Content: "A"},
+		{Role: llm.RoleTool, ToolCallID: "t2", Content: "B"},
+	})
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"role":"user","content":"Read both files"},` +
+		`{"role":"assistant","content":[{"text":"Reading","type":"text"},` +
+		`{"id":"t1","input":{"path":"a"},"name":"fs__read","type":"tool_use"},` +
+		`{"id":"t2","input":{},"name":"fs__read","type":"tool_use"}]},` +
+		`{"role":"user","content":[{"content":"A","tool_use_id":"t1","type":"tool_result"},` +
+		`{"content":"B","tool_use_id":"t2","type":"tool_result"}]}]`
+	if string(data) != want {
+		t.Errorf("anthropicMessages() =\n%s\nwant\n%s", data, want)
+	}
+}
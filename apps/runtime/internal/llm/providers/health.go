@@ -3,12 +3,18 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 )
 
+// ErrInvalidAPIKey is returned by ValidateKey when the provider rejects the key.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
 // ConnectionStatus represents the health status of a provider connection
 type ConnectionStatus string
 
@@ -108,7 +114,7 @@ func (h *HealthChecker) checkOpenAI(ctx context.Context, health *ProviderHealth,
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		health.Status = StatusError
-		health.LastError = "invalid API key"
+		health.LastError = invalidKeyMessage(resp)
 		health.APIKeyValid = false
 		return
 	}
@@ -138,8 +144,11 @@ func (h *HealthChecker) checkOpenAI(ctx context.Context, health *ProviderHealth,
 
 // checkAnthropic checks Anthropic API health
 func (h *HealthChecker) checkAnthropic(ctx context.Context, health *ProviderHealth, apiKey, baseURL string) {
-	// Anthropic doesn't have a simple models endpoint, check with minimal request
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.anthropic.com/v1/models", nil)
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com/v1"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models", nil)
 	if err != nil {
 		health.Status = StatusError
 		health.LastError = err.Error()
@@ -159,7 +168,7 @@ func (h *HealthChecker) checkAnthropic(ctx context.Context, health *ProviderHeal
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		health.Status = StatusError
-		health.LastError = "invalid API key"
+		health.LastError = invalidKeyMessage(resp)
 		health.APIKeyValid = false
 		return
 	}
@@ -177,15 +186,17 @@ func (h *HealthChecker) checkAnthropic(ctx context.Context, health *ProviderHeal
 
 // checkGoogle checks Google AI API health
 func (h *HealthChecker) checkGoogle(ctx context.Context, health *ProviderHealth, apiKey, baseURL string) {
-	// Google uses API key in query param for some endpoints
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models?key=%s", apiKey)
+	if baseURL == "" {
+		baseURL = "https://generativelanguage.googleapis.com/v1beta"
+	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(baseURL, "/")+"/models", nil)
 	if err != nil {
 		health.Status = StatusError
 		health.LastError = err.Error()
 		return
 	}
+	req.Header.Set("x-goog-api-key", apiKey)
 
 	resp, err := h.client.Do(req)
 	if err != nil {
@@ -197,11 +208,22 @@ func (h *HealthChecker) checkGoogle(ctx context.Context, health *ProviderHealth,
 
 	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
 		health.Status = StatusError
-		health.LastError = "invalid API key"
+		health.LastError = invalidKeyMessage(resp)
 		health.APIKeyValid = false
 		return
 	}
 
+	// Google answers a malformed or unknown key with 400 INVALID_ARGUMENT
+	// rather than 401.
+	if resp.StatusCode == http.StatusBadRequest {
+		if msg := invalidKeyMessage(resp); strings.Contains(msg, "API key not valid") {
+			health.Status = StatusError
+			health.LastError = msg
+			health.APIKeyValid = false
+			return
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		health.Status = StatusDegraded
 		health.LastError = fmt.Sprintf("HTTP %d", resp.StatusCode)
@@ -271,7 +293,11 @@ func (h *HealthChecker) checkOllama(ctx context.Context, health *ProviderHealth,
 
 // checkOpenRouter checks OpenRouter API health
 func (h *HealthChecker) checkOpenRouter(ctx context.Context, health *ProviderHealth, apiKey, baseURL string) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://openrouter.ai/api/v1/models", nil)
+	if baseURL == "" {
+		baseURL = "https://openrouter.ai/api/v1"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models", nil)
 	if err != nil {
 		health.Status = StatusError
 		health.LastError = err.Error()
@@ -290,7 +316,7 @@ func (h *HealthChecker) checkOpenRouter(ctx context.Context, health *ProviderHea
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		health.Status = StatusError
-		health.LastError = "invalid API key"
+		health.LastError = invalidKeyMessage(resp)
 		health.APIKeyValid = false
 		return
 	}
@@ -318,6 +344,74 @@ func (h *HealthChecker) checkOpenRouter(ctx context.Context, health *ProviderHea
 	health.ModelsCount = len(result.Data)
 }
 
+// ValidateKey makes a lightweight authenticated call to check apiKey. It returns
+// an error wrapping ErrInvalidAPIKey, with the provider's message, when the key is
+// rejected, and a plain error when the provider could not be checked. Providers
+// without a dedicated check are treated as OpenAI-compatible at baseURL.
+func (h *HealthChecker) ValidateKey(ctx context.Context, providerID, apiKey, baseURL string) error {
+	health, err := h.Probe(ctx, providerID, apiKey, baseURL)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case health.APIKeyValid:
+		return nil
+	case health.Status == StatusError && strings.HasPrefix(health.LastError, ErrInvalidAPIKey.Error()):
+		detail := strings.TrimPrefix(strings.TrimPrefix(health.LastError, ErrInvalidAPIKey.Error()), ": ")
+		if detail == "" {
+			return ErrInvalidAPIKey
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidAPIKey, detail)
+	default:
+		return fmt.Errorf("could not validate key: %s", health.LastError)
+	}
+}
+
+// Probe checks a provider with an authenticated models list request, like
+// CheckProvider, and also handles providers without a dedicated check by
+// treating them as OpenAI-compatible at baseURL. ResponseTime is the latency
+// of the round trip.
+func (h *HealthChecker) Probe(ctx context.Context, providerID, apiKey, baseURL string) (*ProviderHealth, error) {
+	switch providerID {
+	case "openai", "anthropic", "google", "ollama", "openrouter":
+		return h.CheckProvider(ctx, providerID, apiKey, baseURL)
+	}
+	if baseURL == "" {
+		return nil, fmt.Errorf("no API endpoint known for provider %s", providerID)
+	}
+	start := time.Now()
+	health := &ProviderHealth{ProviderID: providerID, LastChecked: start}
+	h.checkOpenAI(ctx, health, apiKey, baseURL)
+	health.ResponseTime = time.Since(start)
+	return health, nil
+}
+
+// invalidKeyMessage describes a rejected key, including the provider's own
+// error message when the response carries one.
+func invalidKeyMessage(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var parsed struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) != nil || len(parsed.Error) == 0 {
+		return ErrInvalidAPIKey.Error()
+	}
+	var nested struct {
+		Message string `json:"message"`
+	}
+	var msg string
+	if json.Unmarshal(parsed.Error, &nested) == nil && nested.Message != "" {
+		msg = nested.Message
+	} else {
+		_ = json.Unmarshal(parsed.Error, &msg)
+	}
+	if msg = strings.TrimSpace(msg); msg == "" {
+		return ErrInvalidAPIKey.Error()
+	}
+	return ErrInvalidAPIKey.Error() + ": " + msg
+}
+
 // GetHealth returns the cached health status for a provider
 func (h *HealthChecker) GetHealth(providerID string) (*ProviderHealth, bool) {
 	h.mu.RLock()
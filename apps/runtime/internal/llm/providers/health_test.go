@@ -2,6 +2,10 @@ package providers
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -111,3 +115,60 @@ func TestProviderHealth_IsStale(t *testing.T) {
 		t.Error("Expected IsStale to return false for fresh check")
 	}
 }
+
+func TestHealthChecker_ValidateKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-key" && r.Header.Get("x-api-key") != "good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"Incorrect API key provided"}}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"model-a"}]}`))
+	}))
+	defer srv.Close()
+
+	hc := NewHealthChecker()
+	ctx := context.Background()
+
+	for _, provider := range []string{"openai", "anthropic", "openrouter", "groq"} {
+		if err := hc.ValidateKey(ctx, provider, "good-key", srv.URL); err != nil {
+			t.Errorf("%s: expected valid key, got %v", provider, err)
+		}
+		err := hc.ValidateKey(ctx, provider, "typo-key", srv.URL)
+		if !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("%s: expected ErrInvalidAPIKey, got %v", provider, err)
+		} else if !strings.Contains(err.Error(), "Incorrect API key provided") {
+			t.Errorf("%s: expected provider message in error, got %v", provider, err)
+		}
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	if err := hc.ValidateKey(ctx, "openai", "good-key", down.URL); err == nil || errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("expected non-key error for unavailable provider, got %v", err)
+	}
+}
+
+func TestHealthChecker_ValidateKeyGoogle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-goog-api-key") != "good-key" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT"}}`))
+			return
+		}
+		w.Write([]byte(`{"models":[{"name":"models/gemini-pro"}]}`))
+	}))
+	defer srv.Close()
+
+	hc := NewHealthChecker()
+	ctx := context.Background()
+	if err := hc.ValidateKey(ctx, "google", "good-key", srv.URL); err != nil {
+		t.Errorf("expected valid key, got %v", err)
+	}
+	err := hc.ValidateKey(ctx, "google", "typo-key", srv.URL)
+	if !errors.Is(err, ErrInvalidAPIKey) || !strings.Contains(err.Error(), "API key not valid") {
+		t.Errorf("expected ErrInvalidAPIKey with the provider message, got %v", err)
+	}
+}
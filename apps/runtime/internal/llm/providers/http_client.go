@@ -1,28 +1,161 @@
 package providers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"sync"
 	"time"
+
+	"pryx-core/internal/llm"
+)
+
+// Default timeouts applied to provider HTTP clients when none are configured.
+const (
+	DefaultConnectTimeout  = 10 * time.Second
+	DefaultResponseTimeout = 60 * time.Second
+	DefaultRequestTimeout  = 120 * time.Second
+	DefaultKeepAlive       = 30 * time.Second
 )
 
-var SharedHTTPClient = &http.Client{
-	Timeout: 120 * time.Second,
-	Transport: &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 10,
-		IdleConnTimeout:     90 * time.Second,
-		DisableCompression:  false,
-	},
+// HTTPClientOptions configures timeouts and connection reuse for provider HTTP clients.
+// Zero values fall back to the package defaults.
+type HTTPClientOptions struct {
+	// ConnectTimeout bounds dialing and the TLS handshake.
+	ConnectTimeout time.Duration
+	// ResponseTimeout bounds the wait for response headers once the request is sent.
+	ResponseTimeout time.Duration
+	// RequestTimeout bounds the whole request, including reading the body.
+	RequestTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period for pooled connections.
+	KeepAlive time.Duration
 }
 
-func SharedHTTPClientWithTimeout(timeout time.Duration) *http.Client {
+func (o HTTPClientOptions) withDefaults() HTTPClientOptions {
+	if o.ConnectTimeout <= 0 {
+		o.ConnectTimeout = DefaultConnectTimeout
+	}
+	if o.ResponseTimeout <= 0 {
+		o.ResponseTimeout = DefaultResponseTimeout
+	}
+	if o.RequestTimeout <= 0 {
+		o.RequestTimeout = DefaultRequestTimeout
+	}
+	if o.KeepAlive <= 0 {
+		o.KeepAlive = DefaultKeepAlive
+	}
+	return o
+}
+
+// NewHTTPClient builds an HTTP client with the given timeouts and keep-alive enabled.
+func NewHTTPClient(opts HTTPClientOptions) *http.Client {
+	opts = opts.withDefaults()
+	dialer := &net.Dialer{
+		Timeout:   opts.ConnectTimeout,
+		KeepAlive: opts.KeepAlive,
+	}
 	return &http.Client{
-		Timeout: timeout,
+		Timeout: opts.RequestTimeout,
 		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     90 * time.Second,
-			DisableCompression:  false,
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			ForceAttemptHTTP2:     true,
+			TLSHandshakeTimeout:   opts.ConnectTimeout,
+			ResponseHeaderTimeout: opts.ResponseTimeout,
+			ExpectContinueTimeout: 1 * time.Second,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   10,
+			IdleConnTimeout:       90 * time.Second,
+			DisableCompression:    false,
+			DisableKeepAlives:     false,
 		},
 	}
 }
+
+var (
+	sharedClientMu sync.RWMutex
+	// SharedHTTPClient is the pooled client used by all providers.
+	SharedHTTPClient = NewHTTPClient(HTTPClientOptions{})
+)
+
+// ConfigureSharedHTTPClient replaces the shared provider client with one built from opts.
+func ConfigureSharedHTTPClient(opts HTTPClientOptions) {
+	client := NewHTTPClient(opts)
+	sharedClientMu.Lock()
+	SharedHTTPClient = client
+	sharedClientMu.Unlock()
+}
+
+func sharedHTTPClient() *http.Client {
+	sharedClientMu.RLock()
+	defer sharedClientMu.RUnlock()
+	return SharedHTTPClient
+}
+
+func SharedHTTPClientWithTimeout(timeout time.Duration) *http.Client {
+	return NewHTTPClient(HTTPClientOptions{RequestTimeout: timeout})
+}
+
+// doRequest sends req for provider with the shared client and tags timeouts with
+// llm.ErrTimeout so callers can tell a stalled provider apart from an API error.
+// Rate-limit headers on the response are reported to the request's limiter, and
+// the exchange is written to the wire log when one is configured for provider.
+// Headers and params configured for provider are applied first.
+func doRequest(provider string, req *http.Request) (*http.Response, error) {
+	if err := applyOverrides(provider, req); err != nil {
+		return nil, fmt.Errorf("apply %s request overrides: %w", provider, err)
+	}
+	wl := currentWireLog()
+	if !wl.Enabled(provider) {
+		wl = nil
+	}
+	var (
+		id      int64
+		secrets []string
+		start   = time.Now()
+	)
+	if wl != nil {
+		secrets = requestSecrets(req)
+		id = wl.logRequest(provider, req, secrets)
+	}
+
+	resp, err := sharedHTTPClient().Do(req)
+	if err != nil {
+		if wl != nil {
+			wl.logError(provider, id, start, err, secrets)
+		}
+		if isTimeout(err) {
+			return nil, fmt.Errorf("%w: %v", llm.ErrTimeout, err)
+		}
+		return nil, err
+	}
+	if wl != nil {
+		wl.logResponse(provider, id, start, resp, secrets)
+	}
+	llm.ReportRateLimit(req.Context(), resp)
+	return resp, nil
+}
+
+// apiError reads a non-200 response into an error, tagging 429s with
+// llm.ErrRateLimited and server errors with llm.ErrUnavailable.
+func apiError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("%w: api error: %s - %s", llm.ErrRateLimited, resp.Status, body)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: api error: %s - %s", llm.ErrUnavailable, resp.Status, body)
+	}
+	return fmt.Errorf("api error: %s - %s", resp.Status, body)
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
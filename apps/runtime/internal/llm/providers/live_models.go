@@ -0,0 +1,243 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrLiveModelsUnsupported is returned by ModelLister.List for providers
+// without a known list-models API.
+var ErrLiveModelsUnsupported = errors.New("provider does not support listing models")
+
+// DefaultLiveModelsTTL is how long a provider's live model list is reused.
+const DefaultLiveModelsTTL = 10 * time.Minute
+
+// LiveModel is a model as reported by the provider's own list-models API.
+type LiveModel struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ModelLister fetches the models providers currently serve, caching each
+// provider's list for a TTL. It is safe for concurrent use.
+type ModelLister struct {
+	client *http.Client
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]liveModelList
+}
+
+type liveModelList struct {
+	fetchedAt time.Time
+	models    []LiveModel
+}
+
+// NewModelLister creates a lister. A non-positive ttl uses DefaultLiveModelsTTL.
+func NewModelLister(ttl time.Duration) *ModelLister {
+	if ttl <= 0 {
+		ttl = DefaultLiveModelsTTL
+	}
+	return &ModelLister{
+		client: &http.Client{Timeout: 30 * time.Second},
+		ttl:    ttl,
+		cache:  make(map[string]liveModelList),
+	}
+}
+
+// List returns the models providerID serves, sorted by ID, and when the list
+// was fetched. The cached list is returned while it is fresh unless refresh
+// is set. An empty baseURL uses the provider's public API.
+func (l *ModelLister) List(ctx context.Context, providerID, apiKey, baseURL string, refresh bool) ([]LiveModel, time.Time, error) {
+	cacheKey := providerID + "|" + baseURL
+	if !refresh {
+		l.mu.Lock()
+		cached, ok := l.cache[cacheKey]
+		l.mu.Unlock()
+		if ok && time.Since(cached.fetchedAt) < l.ttl {
+			return cached.models, cached.fetchedAt, nil
+		}
+	}
+
+	var models []LiveModel
+	var err error
+	switch providerID {
+	case "openai", "openrouter", "groq", "together", "xai", "mistral":
+		models, err = l.listOpenAICompatible(ctx, providerID, apiKey, baseURL)
+	case "anthropic":
+		models, err = l.listAnthropic(ctx, apiKey, baseURL)
+	case "google":
+		models, err = l.listGoogle(ctx, apiKey, baseURL)
+	case "ollama":
+		models, err = l.listOllama(ctx, baseURL)
+	default:
+		// Like Probe, other providers with an endpoint are taken to be OpenAI-compatible
+		if baseURL == "" {
+			return nil, time.Time{}, fmt.Errorf("%w: %s", ErrLiveModelsUnsupported, providerID)
+		}
+		models, err = l.listOpenAICompatible(ctx, providerID, apiKey, baseURL)
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+
+	fetchedAt := time.Now().UTC()
+	l.mu.Lock()
+	l.cache[cacheKey] = liveModelList{fetchedAt: fetchedAt, models: models}
+	l.mu.Unlock()
+	return models, fetchedAt, nil
+}
+
+var openAICompatibleURLs = map[string]string{
+	"openai":     "https://api.openai.com/v1",
+	"openrouter": "https://openrouter.ai/api/v1",
+	"groq":       "https://api.groq.com/openai/v1",
+	"together":   "https://api.together.xyz/v1",
+	"xai":        "https://api.x.ai/v1",
+	"mistral":    "https://api.mistral.ai/v1",
+}
+
+func (l *ModelLister) listOpenAICompatible(ctx context.Context, providerID, apiKey, baseURL string) ([]LiveModel, error) {
+	if baseURL == "" {
+		baseURL = openAICompatibleURLs[providerID]
+	}
+	var result struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := l.getJSON(ctx, strings.TrimRight(baseURL, "/")+"/models", map[string]string{"Authorization": "Bearer " + apiKey}, &result); err != nil {
+		return nil, err
+	}
+	models := make([]LiveModel, 0, len(result.Data))
+	for _, m := range result.Data {
+		models = append(models, LiveModel{ID: m.ID, Name: m.Name})
+	}
+	return models, nil
+}
+
+func (l *ModelLister) listAnthropic(ctx context.Context, apiKey, baseURL string) ([]LiveModel, error) {
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com/v1"
+	}
+	headers := map[string]string{"x-api-key": apiKey, "anthropic-version": "2023-06-01"}
+
+	var models []LiveModel
+	afterID := ""
+	for {
+		endpoint := strings.TrimRight(baseURL, "/") + "/models?limit=1000"
+		if afterID != "" {
+			endpoint += "&after_id=" + url.QueryEscape(afterID)
+		}
+		var page struct {
+			Data []struct {
+				ID          string `json:"id"`
+				DisplayName string `json:"display_name"`
+			} `json:"data"`
+			HasMore bool   `json:"has_more"`
+			LastID  string `json:"last_id"`
+		}
+		if err := l.getJSON(ctx, endpoint, headers, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Data {
+			models = append(models, LiveModel{ID: m.ID, Name: m.DisplayName})
+		}
+		if !page.HasMore || page.LastID == "" {
+			return models, nil
+		}
+		afterID = page.LastID
+	}
+}
+
+func (l *ModelLister) listGoogle(ctx context.Context, apiKey, baseURL string) ([]LiveModel, error) {
+	if baseURL == "" {
+		baseURL = "https://generativelanguage.googleapis.com/v1beta"
+	}
+	headers := map[string]string{"x-goog-api-key": apiKey}
+
+	var models []LiveModel
+	pageToken := ""
+	for {
+		endpoint := strings.TrimRight(baseURL, "/") + "/models?pageSize=1000"
+		if pageToken != "" {
+			endpoint += "&pageToken=" + url.QueryEscape(pageToken)
+		}
+		var page struct {
+			Models []struct {
+				Name        string `json:"name"`
+				DisplayName string `json:"displayName"`
+			} `json:"models"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := l.getJSON(ctx, endpoint, headers, &page); err != nil {
+			return nil, err
+		}
+		for _, m := range page.Models {
+			models = append(models, LiveModel{ID: strings.TrimPrefix(m.Name, "models/"), Name: m.DisplayName})
+		}
+		if page.NextPageToken == "" {
+			return models, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+func (l *ModelLister) listOllama(ctx context.Context, baseURL string) ([]LiveModel, error) {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	var result struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := l.getJSON(ctx, strings.TrimRight(baseURL, "/")+"/api/tags", nil, &result); err != nil {
+		return nil, err
+	}
+	models := make([]LiveModel, 0, len(result.Models))
+	for _, m := range result.Models {
+		models = append(models, LiveModel{ID: m.Name})
+	}
+	return models, nil
+}
+
+func (l *ModelLister) getJSON(ctx context.Context, endpoint string, headers map[string]string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		if detail := strings.TrimPrefix(invalidKeyMessage(resp), ErrInvalidAPIKey.Error()+": "); detail != ErrInvalidAPIKey.Error() {
+			return fmt.Errorf("%w: %s", ErrInvalidAPIKey, detail)
+		}
+		return ErrInvalidAPIKey
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("list models: HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("list models: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestModelLister_List(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.URL.Path == "/openai/models":
+			if r.Header.Get("Authorization") != "Bearer sk-test" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key provided"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":[{"id":"gpt-5"},{"id":"gpt-4o"}]}`))
+		case r.URL.Path == "/anthropic/models" && r.URL.Query().Get("after_id") == "":
+			_, _ = w.Write([]byte(`{"data":[{"id":"claude-a","display_name":"Claude A"}],"has_more":true,"last_id":"claude-a"}`))
+		case r.URL.Path == "/anthropic/models":
+			_, _ = w.Write([]byte(`{"data":[{"id":"claude-b","display_name":"Claude B"}],"has_more":false}`))
+		case r.URL.Path == "/google/models":
+			_, _ = w.Write([]byte(`{"models":[{"name":"models/gemini-x","displayName":"Gemini X"}]}`))
+		case r.URL.Path == "/api/tags":
+			_, _ = w.Write([]byte(`{"models":[{"name":"llama3"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	l := NewModelLister(0)
+	ctx := context.Background()
+
+	models, _, err := l.List(ctx, "openai", "sk-test", srv.URL+"/openai", false)
+	if err != nil {
+		t.Fatalf("List(openai) failed: %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "gpt-4o" || models[1].ID != "gpt-5" {
+		t.Errorf("unexpected openai models: %+v", models)
+	}
+
+	models, _, err = l.List(ctx, "anthropic", "key", srv.URL+"/anthropic", false)
+	if err != nil || len(models) != 2 || models[1].Name != "Claude B" {
+		t.Errorf("unexpected anthropic models: %+v, %v", models, err)
+	}
+
+	models, _, err = l.List(ctx, "google", "key", srv.URL+"/google", false)
+	if err != nil || len(models) != 1 || models[0].ID != "gemini-x" {
+		t.Errorf("unexpected google models: %+v, %v", models, err)
+	}
+
+	models, _, err = l.List(ctx, "ollama", "", srv.URL, false)
+	if err != nil || len(models) != 1 || models[0].ID != "llama3" {
+		t.Errorf("unexpected ollama models: %+v, %v", models, err)
+	}
+
+	before := requests.Load()
+	if _, _, err := l.List(ctx, "openai", "sk-test", srv.URL+"/openai", false); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != before {
+		t.Error("expected the cached list to be reused")
+	}
+	if _, _, err := l.List(ctx, "openai", "sk-test", srv.URL+"/openai", true); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != before+1 {
+		t.Error("expected refresh to fetch the list again")
+	}
+
+	_, _, err = l.List(ctx, "openai", "sk-wrong", srv.URL+"/openai", true)
+	if !errors.Is(err, ErrInvalidAPIKey) || err.Error() != "invalid API key: Incorrect API key provided" {
+		t.Errorf("expected ErrInvalidAPIKey with the provider message, got %v", err)
+	}
+
+	_, _, err = l.List(ctx, "unknown", "key", "", false)
+	if !errors.Is(err, ErrLiveModelsUnsupported) {
+		t.Errorf("expected ErrLiveModelsUnsupported, got %v", err)
+	}
+}
@@ -16,6 +16,8 @@ import (
 type OpenAIProvider struct {
 	apiKey  string
 	baseURL string
+	// providerID names the provider behind baseURL in the wire log.
+	providerID string
 }
 
 func NewOpenAI(apiKey string, baseURL string) *OpenAIProvider {
@@ -25,11 +27,21 @@ func NewOpenAI(apiKey string, baseURL string) *OpenAIProvider {
 	// Normalize base URL (remove trailing slash)
 	baseURL = strings.TrimSuffix(baseURL, "/")
 	return &OpenAIProvider{
-		apiKey:  apiKey,
-		baseURL: baseURL,
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		providerID: "openai",
 	}
 }
 
+// WithProviderID sets the provider ID used to select and label wire log
+// entries, for OpenAI-compatible providers other than OpenAI itself.
+func (p *OpenAIProvider) WithProviderID(id string) *OpenAIProvider {
+	if id != "" {
+		p.providerID = id
+	}
+	return p
+}
+
 func (p *OpenAIProvider) Complete(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
 	req.Stream = false
 	respBody, err := p.sendRequest(ctx, req)
@@ -41,12 +53,13 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req llm.ChatRequest) (*ll
 	var apiResp struct {
 		Choices []struct {
 			Message struct {
-				Content string `json:"content"`
-				Role    string `json:"role"`
+				Content   string           `json:"content"`
+				Role      string           `json:"role"`
+				ToolCalls []openAIToolCall `json:"tool_calls"`
 			} `json:"message"`
 			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
-		Usage llm.Usage `json:"usage"`
+		Usage openAIUsage `json:"usage"`
 	}
 
 	if err := json.NewDecoder(respBody).Decode(&apiResp); err != nil {
@@ -62,7 +75,8 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req llm.ChatRequest) (*ll
 		Content:      choice.Message.Content,
 		Role:         llm.Role(choice.Message.Role),
 		FinishReason: choice.FinishReason,
-		Usage:        apiResp.Usage,
+		Usage:        apiResp.Usage.toUsage(),
+		ToolCalls:    fromOpenAIToolCalls(choice.Message.ToolCalls),
 	}, nil
 }
 
@@ -78,10 +92,25 @@ func (p *OpenAIProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-cha
 		defer close(ch)
 		defer respBody.Close()
 
+		// With usage requested the usage chunk follows the finish reason
+		wantUsage := req.ReasoningEffort != ""
+		finished := false
+		// Tool call arguments arrive in pieces, keyed by the call's index
+		var calls []openAIToolCall
+		done := func(usage *llm.Usage) llm.StreamChunk {
+			return llm.StreamChunk{Done: true, Usage: usage, ToolCalls: fromOpenAIToolCalls(calls)}
+		}
 		reader := bufio.NewReader(respBody)
 		for {
 			line, err := reader.ReadBytes('\n')
 			if err != nil {
+				if finished {
+					ch <- done(nil)
+					return
+				}
+				if isTimeout(err) {
+					err = fmt.Errorf("%w: %v", llm.ErrTimeout, err)
+				}
 				ch <- llm.StreamChunk{Err: err}
 				return
 			}
@@ -93,16 +122,21 @@ func (p *OpenAIProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-cha
 
 			data := bytes.TrimPrefix(line, []byte("data: "))
 			if string(data) == "[DONE]" {
+				if finished {
+					ch <- done(nil)
+				}
 				return
 			}
 
 			var chunk struct {
 				Choices []struct {
 					Delta struct {
-						Content string `json:"content"`
+						Content   string           `json:"content"`
+						ToolCalls []openAIToolCall `json:"tool_calls"`
 					} `json:"delta"`
 					FinishReason string `json:"finish_reason"`
 				} `json:"choices"`
+				Usage *openAIUsage `json:"usage"`
 			}
 
 			if err := json.Unmarshal(data, &chunk); err != nil {
@@ -114,19 +148,140 @@ func (p *OpenAIProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-cha
 				if delta != "" {
 					ch <- llm.StreamChunk{Content: delta}
 				}
+				for _, tc := range chunk.Choices[0].Delta.ToolCalls {
+					for len(calls) <= tc.Index {
+						calls = append(calls, openAIToolCall{})
+					}
+					call := &calls[tc.Index]
+					if tc.ID != "" {
+						call.ID = tc.ID
+					}
+					call.Function.Name += tc.Function.Name
+					call.Function.Arguments += tc.Function.Arguments
+				}
 				if chunk.Choices[0].FinishReason != "" {
-					ch <- llm.StreamChunk{Done: true}
-					return
+					finished = true
 				}
 			}
+			if finished && (chunk.Usage != nil || !wantUsage) {
+				var usage *llm.Usage
+				if chunk.Usage != nil {
+					u := chunk.Usage.toUsage()
+					usage = &u
+				}
+				ch <- done(usage)
+				return
+			}
 		}
 	}()
 
 	return ch, nil
 }
 
+// openAIUsage is the usage block of a chat completion, which reports
+// reasoning tokens among the completion token details.
+type openAIUsage struct {
+	llm.Usage
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+func (u openAIUsage) toUsage() llm.Usage {
+	usage := u.Usage
+	usage.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
+	return usage
+}
+
+// openAIMessage is a chat message in the chat completions format.
+type openAIMessage struct {
+	Role       llm.Role         `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openAIToolCall is a function call in the chat completions format. Index
+// orders the calls of a streamed response.
+type openAIToolCall struct {
+	Index    int    `json:"index,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openAITool is a function declaration in the chat completions format.
+type openAITool struct {
+	Type     string   `json:"type"`
+	Function llm.Tool `json:"function"`
+}
+
+func openAIMessages(messages []llm.Message) []openAIMessage {
+	out := make([]openAIMessage, 0, len(messages))
+	for _, m := range messages {
+		msg := openAIMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, call := range m.ToolCalls {
+			tc := openAIToolCall{ID: call.ID, Type: "function"}
+			tc.Function.Name = call.Name
+			tc.Function.Arguments = call.Arguments
+			msg.ToolCalls = append(msg.ToolCalls, tc)
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
+func openAITools(tools []llm.Tool) []openAITool {
+	var out []openAITool
+	for _, t := range tools {
+		if t.Parameters == nil {
+			t.Parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		out = append(out, openAITool{Type: "function", Function: t})
+	}
+	return out
+}
+
+func fromOpenAIToolCalls(calls []openAIToolCall) []llm.ToolCall {
+	var out []llm.ToolCall
+	for _, c := range calls {
+		if c.Function.Name == "" {
+			continue
+		}
+		out = append(out, llm.ToolCall{ID: c.ID, Name: c.Function.Name, Arguments: c.Function.Arguments})
+	}
+	return out
+}
+
+// requestBody is the chat completions body for req. OpenRouter takes the
+// reasoning effort as reasoning.effort; with an effort set, streams ask for
+// usage so the reasoning tokens spent are reported.
+func (p *OpenAIProvider) requestBody(req llm.ChatRequest) interface{} {
+	body := struct {
+		llm.ChatRequest
+		Messages      []openAIMessage   `json:"messages"`
+		Tools         []openAITool      `json:"tools,omitempty"`
+		Reasoning     map[string]string `json:"reasoning,omitempty"`
+		StreamOptions map[string]bool   `json:"stream_options,omitempty"`
+	}{ChatRequest: req, Messages: openAIMessages(req.Messages), Tools: openAITools(req.Tools)}
+	if req.ReasoningEffort == "" {
+		return body
+	}
+	if p.providerID == "openrouter" {
+		body.Reasoning = map[string]string{"effort": string(req.ReasoningEffort)}
+		body.ChatRequest.ReasoningEffort = ""
+	}
+	if req.Stream {
+		body.StreamOptions = map[string]bool{"include_usage": true}
+	}
+	return body
+}
+
 func (p *OpenAIProvider) sendRequest(ctx context.Context, req llm.ChatRequest) (io.ReadCloser, error) { // Updated to use standard io.ReadCloser
-	bodyBytes, err := json.Marshal(req)
+	bodyBytes, err := json.Marshal(p.requestBody(req))
 	if err != nil {
 		return nil, err
 	}
@@ -143,16 +298,14 @@ func (p *OpenAIProvider) sendRequest(ctx context.Context, req llm.ChatRequest) (
 	httpReq.Header.Set("HTTP-Referer", "https://pryx.app")
 	httpReq.Header.Set("X-Title", "Pryx")
 
-	resp, err := SharedHTTPClient.Do(httpReq)
+	resp, err := doRequest(p.providerID, httpReq)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(resp.Body)
-		return nil, fmt.Errorf("api error: %s - %s", resp.Status, buf.String())
+		return nil, apiError(resp)
 	}
 
 	return resp.Body, nil
@@ -3,6 +3,7 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -154,6 +155,84 @@ func TestOpenAIProvider_Complete_Error(t *testing.T) {
 	if err == nil {
 		t.Error("Complete() expected error, got nil")
 	}
+	if llm.IsTransient(err) {
+		t.Errorf("Complete() error = %v, want a non-transient error for 401", err)
+	}
+}
+
+func TestOpenAIProvider_Complete_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	req := llm.ChatRequest{Model: "gpt-4", Messages: []llm.Message{{Role: llm.RoleUser, Content: "Hello"}}}
+	_, err := NewOpenAI("test-key", server.URL).Complete(context.Background(), req)
+	if !errors.Is(err, llm.ErrUnavailable) || !llm.IsTransient(err) {
+		t.Fatalf("Complete() error = %v, want a transient unavailable error", err)
+	}
+}
+
+func TestOpenAIProvider_Complete_RateLimited(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("x-ratelimit-remaining-requests", "0")
+			w.Header().Set("x-ratelimit-reset-requests", "20ms")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("x-ratelimit-remaining-requests", "9")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "Hi"}, "finish_reason": "stop"},
+			},
+		})
+	}))
+	defer server.Close()
+
+	req := llm.ChatRequest{Model: "gpt-4", Messages: []llm.Message{{Role: llm.RoleUser, Content: "Hello"}}}
+
+	_, err := NewOpenAI("test-key", server.URL).Complete(context.Background(), req)
+	if !llm.IsRateLimited(err) {
+		t.Fatalf("Complete() error = %v, want rate limited", err)
+	}
+
+	limiter := llm.NewModelLimiter("openai", nil)
+	resp, err := llm.NewRateLimitedProvider(NewOpenAI("test-key", server.URL), limiter).Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("rate-limited Complete() error = %v", err)
+	}
+	if resp.Content != "Hi" {
+		t.Errorf("Content = %q, want Hi", resp.Content)
+	}
+	if got := limiter.Stats()["gpt-4"].Remaining; got != 9 {
+		t.Errorf("Remaining = %d, want 9 from headers", got)
+	}
+}
+
+func TestOpenAIProvider_Complete_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ConfigureSharedHTTPClient(HTTPClientOptions{ResponseTimeout: 50 * time.Millisecond})
+	defer ConfigureSharedHTTPClient(HTTPClientOptions{})
+
+	provider := NewOpenAI("test-key", server.URL)
+	_, err := provider.Complete(context.Background(), llm.ChatRequest{
+		Model:    "gpt-4",
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: "Hello"}},
+	})
+	if err == nil {
+		t.Fatal("Complete() expected timeout error, got nil")
+	}
+	if !llm.IsTimeout(err) {
+		t.Errorf("Complete() error = %v, want timeout", err)
+	}
 }
 
 func TestOpenAIProvider_Stream(t *testing.T) {
@@ -221,3 +300,130 @@ func TestOpenAIProvider_Stream(t *testing.T) {
 		t.Errorf("Second chunk = %v, want %v", nonEmptyChunks[1], " world")
 	}
 }
+
+func TestOpenAIProvider_ReasoningEffort(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		events := []string{
+			`data: {"choices":[{"delta":{"content":"Hi"}}],"usage":null}` + "\n\n",
+			`data: {"choices":[{"delta":{},"finish_reason":"stop"}],"usage":null}` + "\n\n",
+			`data: {"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":40,"total_tokens":45,"completion_tokens_details":{"reasoning_tokens":32}}}` + "\n\n",
+			"data: [DONE]\n\n",
+		}
+		for _, event := range events {
+			w.Write([]byte(event))
+		}
+	}))
+	defer server.Close()
+
+	req := llm.ChatRequest{
+		Model:           "o3",
+		Messages:        []llm.Message{{Role: llm.RoleUser, Content: "Hello"}},
+		ReasoningEffort: llm.ReasoningHigh,
+	}
+
+	stream, err := NewOpenAI("test-api-key", server.URL).Stream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	var content string
+	var usage *llm.Usage
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("Stream chunk error: %v", chunk.Err)
+		}
+		content += chunk.Content
+		if chunk.Done {
+			usage = chunk.Usage
+		}
+	}
+	if content != "Hi" {
+		t.Errorf("content = %q, want %q", content, "Hi")
+	}
+	if usage == nil || usage.ReasoningTokens != 32 || usage.CompletionTokens != 40 {
+		t.Errorf("expected reasoning usage on the final chunk, got %+v", usage)
+	}
+	if bodies[0]["reasoning_effort"] != "high" || bodies[0]["stream_options"] == nil {
+		t.Errorf("expected reasoning_effort and stream_options in the body, got %v", bodies[0])
+	}
+
+	// OpenRouter takes the effort in its reasoning object
+	stream, err = NewOpenAI("test-api-key", server.URL).WithProviderID("openrouter").Stream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	for range stream {
+	}
+	if _, ok := bodies[1]["reasoning_effort"]; ok {
+		t.Errorf("expected no reasoning_effort for openrouter, got %v", bodies[1])
+	}
+	if reasoning, _ := bodies[1]["reasoning"].(map[string]interface{}); reasoning["effort"] != "high" {
+		t.Errorf("expected reasoning.effort for openrouter, got %v", bodies[1]["reasoning"])
+	}
+}
+
+func TestOpenAIProvider_ToolCalls(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		events := []string{
+			`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"fs__read","arguments":""}}]}}]}` + "\n\n",
+			`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"path\":"}}]}}]}` + "\n\n",
+			`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"a.txt\"}"}}]}}]}` + "\n\n",
+			`data: {"choices":[{"delta":{},"finish_reason":"tool_calls"}]}` + "\n\n",
+			"data: [DONE]\n\n",
+		}
+		for _, event := range events {
+			w.Write([]byte(event))
+		}
+	}))
+	defer server.Close()
+
+	req := llm.ChatRequest{
+		Model: "gpt-4o",
+		Messages: []llm.Message{
+			{Role: llm.RoleUser, Content: "Read a.txt"},
+			{Role: llm.RoleAssistant, ToolCalls: []llm.ToolCall{{ID: "call_0", Name: "fs__list", Arguments: "{}"}}},
+			{Role: llm.RoleTool, ToolCallID: "call_0", Content: "a.txt"},
+		},
+		Tools: []llm.Tool{{Name: "fs__read", Description: "Read a file"}},
+	}
+	stream, err := NewOpenAI("test-api-key", server.URL).Stream(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	var calls []llm.ToolCall
+	for chunk := range stream {
+		if chunk.Err != nil {
+			t.Fatalf("Stream chunk error: %v", chunk.Err)
+		}
+		if chunk.Done {
+			calls = chunk.ToolCalls
+		}
+	}
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Name != "fs__read" || calls[0].Arguments != `{"path":"a.txt"}` {
+		t.Errorf("unexpected tool calls %+v", calls)
+	}
+
+	tools, _ := body["tools"].([]interface{})
+	if len(tools) != 1 || tools[0].(map[string]interface{})["type"] != "function" {
+		t.Fatalf("expected function tools in the body, got %v", body["tools"])
+	}
+	messages := body["messages"].([]interface{})
+	assistant := messages[1].(map[string]interface{})
+	call := assistant["tool_calls"].([]interface{})[0].(map[string]interface{})
+	if call["id"] != "call_0" || call["function"].(map[string]interface{})["name"] != "fs__list" {
+		t.Errorf("unexpected assistant tool call %v", call)
+	}
+	if messages[2].(map[string]interface{})["tool_call_id"] != "call_0" {
+		t.Errorf("expected the tool result to name its call, got %v", messages[2])
+	}
+}
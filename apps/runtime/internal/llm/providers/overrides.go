@@ -0,0 +1,83 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// RequestOverrides are headers and default JSON body parameters added to
+// every request sent to a provider.
+type RequestOverrides struct {
+	Headers map[string]string
+	Params  map[string]interface{}
+}
+
+var (
+	overridesMu      sync.RWMutex
+	requestOverrides map[string]RequestOverrides
+)
+
+// ConfigureRequestOverrides replaces the overrides applied to provider
+// requests, keyed by provider ID. A nil map removes them all.
+func ConfigureRequestOverrides(overrides map[string]RequestOverrides) {
+	overridesMu.Lock()
+	requestOverrides = overrides
+	overridesMu.Unlock()
+}
+
+func overridesFor(provider string) (RequestOverrides, bool) {
+	overridesMu.RLock()
+	defer overridesMu.RUnlock()
+	o, ok := requestOverrides[provider]
+	return o, ok
+}
+
+// applyOverrides sets the provider's override headers on req and adds its
+// params to the JSON body where the body does not set them already.
+func applyOverrides(provider string, req *http.Request) error {
+	o, ok := overridesFor(provider)
+	if !ok {
+		return nil
+	}
+	for name, value := range o.Headers {
+		req.Header.Set(name, value)
+	}
+	if len(o.Params) == 0 || req.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err == nil {
+		changed := false
+		for name, value := range o.Params {
+			if _, set := fields[name]; set {
+				continue
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			fields[name] = raw
+			changed = true
+		}
+		if changed {
+			if body, err = json.Marshal(fields); err != nil {
+				return err
+			}
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return nil
+}
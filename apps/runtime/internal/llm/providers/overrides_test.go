@@ -0,0 +1,66 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pryx-core/internal/llm"
+)
+
+func TestRequestOverridesAppliedToProvider(t *testing.T) {
+	var gotHeader, gotAuth string
+	var gotBody map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("OpenAI-Organization")
+		gotAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	ConfigureRequestOverrides(map[string]RequestOverrides{
+		"gateway": {
+			Headers: map[string]string{"OpenAI-Organization": "org-123"},
+			Params:  map[string]interface{}{"user": "pryx", "temperature": 0.9},
+		},
+	})
+	defer ConfigureRequestOverrides(nil)
+
+	temperature := 0.2
+	p := NewOpenAI("test-key", server.URL).WithProviderID("gateway")
+	_, err := p.Complete(context.Background(), llm.ChatRequest{
+		Model:       "gpt-4",
+		Messages:    []llm.Message{{Role: llm.RoleUser, Content: "hi"}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if gotHeader != "org-123" {
+		t.Errorf("OpenAI-Organization = %q, want org-123", gotHeader)
+	}
+	if gotAuth != "Bearer test-key" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotBody["user"] != "pryx" {
+		t.Errorf("user param = %v, want pryx", gotBody["user"])
+	}
+	if gotBody["temperature"] != 0.2 {
+		t.Errorf("request temperature was replaced: %v", gotBody["temperature"])
+	}
+
+	// Other providers are left alone.
+	gotHeader, gotBody = "", nil
+	if _, err := NewOpenAI("test-key", server.URL).Complete(context.Background(), llm.ChatRequest{Model: "gpt-4"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if gotHeader != "" || gotBody["user"] != nil {
+		t.Errorf("overrides applied to another provider: header=%q body=%v", gotHeader, gotBody)
+	}
+}
@@ -0,0 +1,400 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults for the provider wire log.
+const (
+	DefaultWireLogMaxBytes = 10 << 20
+	DefaultWireLogMaxFiles = 3
+	// maxWireBody caps how much of each request or response body is logged.
+	maxWireBody = 256 << 10
+)
+
+const wireRedacted = "[REDACTED]"
+
+// secretHeaders carry provider credentials and are never logged.
+var secretHeaders = map[string]bool{
+	"authorization":       true,
+	"x-api-key":           true,
+	"api-key":             true,
+	"x-goog-api-key":      true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// secretQueryParams carry provider credentials in URLs.
+var secretQueryParams = []string{"key", "api_key", "apikey", "token", "access_token"}
+
+// WireLogOptions configures a WireLog. Zero sizes use the package defaults.
+type WireLogOptions struct {
+	// Path is the log file. Rotated files get a numeric suffix (path.1, path.2, ...).
+	Path string
+	// MaxBytes rotates the file once writing would grow it past this size.
+	MaxBytes int64
+	// MaxFiles is how many rotated files are kept besides the current one.
+	MaxFiles int
+	// RedactPrompts replaces message and prompt content in logged requests with its length.
+	RedactPrompts bool
+	// Providers limits logging to these provider IDs; empty logs all providers.
+	Providers []string
+}
+
+// WireLog writes provider HTTP requests and responses, with credentials
+// removed, as JSON lines to a size-capped rotating file. It is meant for
+// diagnosing provider formatting issues and never publishes to the event bus.
+type WireLog struct {
+	opts      WireLogOptions
+	providers map[string]bool
+	seq       atomic.Int64
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// wireEntry is one logged request or response.
+type wireEntry struct {
+	Time       time.Time         `json:"time"`
+	ID         int64             `json:"id"`
+	Provider   string            `json:"provider"`
+	Direction  string            `json:"direction"`
+	Method     string            `json:"method,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	Status     int               `json:"status,omitempty"`
+	DurationMs int64             `json:"duration_ms,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	Truncated  bool              `json:"truncated,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// OpenWireLog opens (or creates) the log file at opts.Path, creating its
+// directory. The file is only readable by the current user.
+func OpenWireLog(opts WireLogOptions) (*WireLog, error) {
+	if strings.TrimSpace(opts.Path) == "" {
+		return nil, fmt.Errorf("wire log path is required")
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultWireLogMaxBytes
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = DefaultWireLogMaxFiles
+	}
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0700); err != nil {
+		return nil, fmt.Errorf("creating wire log directory: %w", err)
+	}
+
+	l := &WireLog{opts: opts}
+	if len(opts.Providers) > 0 {
+		l.providers = make(map[string]bool, len(opts.Providers))
+		for _, p := range opts.Providers {
+			l.providers[strings.ToLower(strings.TrimSpace(p))] = true
+		}
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Close closes the log file.
+func (l *WireLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// Enabled reports whether requests to provider are logged.
+func (l *WireLog) Enabled(provider string) bool {
+	if l == nil {
+		return false
+	}
+	return l.providers == nil || l.providers[strings.ToLower(provider)]
+}
+
+func (l *WireLog) open() error {
+	f, err := os.OpenFile(l.opts.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("opening wire log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("opening wire log: %w", err)
+	}
+	l.f = f
+	l.size = info.Size()
+	return nil
+}
+
+// rotate shifts path.N to path.N+1, dropping the oldest, and starts a new file.
+func (l *WireLog) rotate() error {
+	if l.f != nil {
+		l.f.Close()
+		l.f = nil
+	}
+	for i := l.opts.MaxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", l.opts.Path, i), fmt.Sprintf("%s.%d", l.opts.Path, i+1))
+	}
+	if err := os.Rename(l.opts.Path, l.opts.Path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return l.open()
+}
+
+func (l *WireLog) write(e wireEntry) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.opts.MaxBytes {
+		if err := l.rotate(); err != nil {
+			return
+		}
+	}
+	n, _ := l.f.Write(line)
+	l.size += int64(n)
+}
+
+// logRequest logs req and returns the entry ID used to pair it with its response.
+func (l *WireLog) logRequest(provider string, req *http.Request, secrets []string) int64 {
+	id := l.seq.Add(1)
+	e := wireEntry{
+		Time:      time.Now().UTC(),
+		ID:        id,
+		Provider:  provider,
+		Direction: "request",
+		Method:    req.Method,
+		URL:       redactURL(req.URL, secrets),
+		Headers:   redactHeaders(req.Header, secrets),
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, truncated := readCapped(body)
+			body.Close()
+			if l.opts.RedactPrompts {
+				data = redactPrompts(data)
+			}
+			e.Body = redactSecrets(string(data), secrets)
+			e.Truncated = truncated
+		}
+	}
+	l.write(e)
+	return id
+}
+
+// logResponse wraps resp's body so that it is logged when the caller closes it.
+func (l *WireLog) logResponse(provider string, id int64, start time.Time, resp *http.Response, secrets []string) {
+	e := wireEntry{
+		ID:        id,
+		Provider:  provider,
+		Direction: "response",
+		Status:    resp.StatusCode,
+		Headers:   redactHeaders(resp.Header, secrets),
+	}
+	resp.Body = &wireBody{ReadCloser: resp.Body, done: func(body []byte, truncated bool) {
+		e.Time = time.Now().UTC()
+		e.DurationMs = time.Since(start).Milliseconds()
+		e.Body = redactSecrets(string(body), secrets)
+		e.Truncated = truncated
+		l.write(e)
+	}}
+}
+
+func (l *WireLog) logError(provider string, id int64, start time.Time, err error, secrets []string) {
+	l.write(wireEntry{
+		Time:       time.Now().UTC(),
+		ID:         id,
+		Provider:   provider,
+		Direction:  "response",
+		DurationMs: time.Since(start).Milliseconds(),
+		Error:      redactSecrets(err.Error(), secrets),
+	})
+}
+
+// wireBody records up to maxWireBody bytes of a response body as it is read
+// and hands them to done once, on Close.
+type wireBody struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	truncated bool
+	once      sync.Once
+	done      func(body []byte, truncated bool)
+}
+
+func (b *wireBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		room := maxWireBody - b.buf.Len()
+		switch {
+		case room >= n:
+			b.buf.Write(p[:n])
+		case room > 0:
+			b.buf.Write(p[:room])
+			b.truncated = true
+		default:
+			b.truncated = true
+		}
+	}
+	return n, err
+}
+
+func (b *wireBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.buf.Bytes(), b.truncated) })
+	return err
+}
+
+func readCapped(r io.Reader) ([]byte, bool) {
+	data, _ := io.ReadAll(io.LimitReader(r, maxWireBody+1))
+	if len(data) > maxWireBody {
+		return data[:maxWireBody], true
+	}
+	return data, false
+}
+
+// requestSecrets returns the credential values sent in req's headers, so they
+// can also be removed wherever they are echoed in bodies or URLs.
+func requestSecrets(req *http.Request) []string {
+	var secrets []string
+	for name, values := range req.Header {
+		if !secretHeaders[strings.ToLower(name)] {
+			continue
+		}
+		for _, v := range values {
+			for _, prefix := range []string{"Bearer ", "bearer ", "Basic ", "basic "} {
+				v = strings.TrimPrefix(v, prefix)
+			}
+			if v = strings.TrimSpace(v); len(v) >= 8 {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+	return secrets
+}
+
+func redactHeaders(h http.Header, secrets []string) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if secretHeaders[strings.ToLower(name)] {
+			out[name] = wireRedacted
+			continue
+		}
+		out[name] = redactSecrets(strings.Join(values, ", "), secrets)
+	}
+	return out
+}
+
+func redactURL(u *url.URL, secrets []string) string {
+	c := *u
+	c.User = nil
+	q := c.Query()
+	changed := false
+	for _, p := range secretQueryParams {
+		if q.Has(p) {
+			q.Set(p, wireRedacted)
+			changed = true
+		}
+	}
+	if changed {
+		c.RawQuery = q.Encode()
+	}
+	return redactSecrets(c.String(), secrets)
+}
+
+func redactSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, wireRedacted)
+	}
+	return s
+}
+
+// promptFields hold prompt content in OpenAI- and Anthropic-style request bodies.
+var promptFields = []string{"system", "prompt", "input"}
+
+// redactPrompts replaces message content and top-level prompt fields in a JSON
+// request body with a note of their length. Bodies that are not JSON objects
+// are dropped entirely, as their content cannot be told apart.
+func redactPrompts(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(body, &m); err != nil {
+		return []byte(wireRedacted)
+	}
+	if messages, ok := m["messages"].([]interface{}); ok {
+		for _, msg := range messages {
+			if mm, ok := msg.(map[string]interface{}); ok {
+				if c, ok := mm["content"]; ok {
+					mm["content"] = redactedContent(c)
+				}
+			}
+		}
+	}
+	for _, f := range promptFields {
+		if c, ok := m[f]; ok {
+			m[f] = redactedContent(c)
+		}
+	}
+	out, err := json.Marshal(m)
+	if err != nil {
+		return []byte(wireRedacted)
+	}
+	return out
+}
+
+func redactedContent(c interface{}) string {
+	if s, ok := c.(string); ok {
+		return fmt.Sprintf("[%d chars redacted]", len(s))
+	}
+	return wireRedacted
+}
+
+var (
+	wireLogMu sync.RWMutex
+	wireLog   *WireLog
+)
+
+// ConfigureWireLog makes providers log their HTTP traffic to l. A nil l turns
+// logging off. The previous log, if any, is not closed.
+func ConfigureWireLog(l *WireLog) {
+	wireLogMu.Lock()
+	wireLog = l
+	wireLogMu.Unlock()
+}
+
+func currentWireLog() *WireLog {
+	wireLogMu.RLock()
+	defer wireLogMu.RUnlock()
+	return wireLog
+}
@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pryx-core/internal/llm"
+)
+
+const wireTestKey = "sk-wire-test-secret-0123456789"
+
+func readWireLog(t *testing.T, path string) []wireEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open wire log: %v", err)
+	}
+	defer f.Close()
+	var entries []wireEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for sc.Scan() {
+		var e wireEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("bad wire log line %q: %v", sc.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func openTestWireLog(t *testing.T, opts WireLogOptions) string {
+	t.Helper()
+	opts.Path = filepath.Join(t.TempDir(), "wire.log")
+	wl, err := OpenWireLog(opts)
+	if err != nil {
+		t.Fatalf("OpenWireLog: %v", err)
+	}
+	ConfigureWireLog(wl)
+	t.Cleanup(func() {
+		ConfigureWireLog(nil)
+		wl.Close()
+	})
+	return opts.Path
+}
+
+func TestWireLogRecordsRedactedExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": "pong"}, "finish_reason": "stop"}},
+			"echo":    "key was " + wireTestKey,
+		})
+	}))
+	defer server.Close()
+
+	path := openTestWireLog(t, WireLogOptions{RedactPrompts: true})
+
+	p := NewOpenAI(wireTestKey, server.URL).WithProviderID("groq")
+	if _, err := p.Complete(context.Background(), llm.ChatRequest{
+		Model:    "llama",
+		Messages: []llm.Message{{Role: llm.RoleUser, Content: "my private question"}},
+	}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	entries := readWireLog(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want request and response", len(entries))
+	}
+	req, resp := entries[0], entries[1]
+	if req.Direction != "request" || resp.Direction != "response" || req.ID != resp.ID {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if req.Provider != "groq" || resp.Status != http.StatusOK {
+		t.Errorf("provider = %q, status = %d", req.Provider, resp.Status)
+	}
+	if req.Headers["Authorization"] != wireRedacted {
+		t.Errorf("Authorization header = %q, want redacted", req.Headers["Authorization"])
+	}
+	if strings.Contains(req.Body, "my private question") || !strings.Contains(req.Body, "chars redacted") {
+		t.Errorf("prompt not redacted: %s", req.Body)
+	}
+	if !strings.Contains(req.Body, `"model":"llama"`) {
+		t.Errorf("request body lost non-prompt fields: %s", req.Body)
+	}
+	if !strings.Contains(resp.Body, "pong") {
+		t.Errorf("response body not logged: %s", resp.Body)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), wireTestKey) {
+		t.Fatalf("wire log leaks the API key:\n%s", data)
+	}
+}
+
+func TestWireLogProviderFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	path := openTestWireLog(t, WireLogOptions{Providers: []string{"anthropic"}})
+
+	if _, err := NewOpenAI("key", server.URL).Complete(context.Background(), llm.ChatRequest{Model: "m"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if entries := readWireLog(t, path); len(entries) != 0 {
+		t.Fatalf("expected openai traffic to be skipped, got %+v", entries)
+	}
+}
+
+func TestWireLogRotates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "wire.log")
+	wl, err := OpenWireLog(WireLogOptions{Path: path, MaxBytes: 300, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("OpenWireLog: %v", err)
+	}
+	defer wl.Close()
+
+	for i := 0; i < 20; i++ {
+		wl.write(wireEntry{Provider: "openai", Direction: "request", Body: strings.Repeat("x", 100)})
+	}
+
+	for _, name := range []string{"wire.log", "wire.log.1", "wire.log.2"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+		if info.Size() > 300 {
+			t.Errorf("%s is %d bytes, over the cap", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "wire.log.3")); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 rotated files, found wire.log.3")
+	}
+}
@@ -0,0 +1,416 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned (wrapped) when a provider answers 429 Too Many Requests.
+var ErrRateLimited = errors.New("llm rate limited")
+
+// IsRateLimited reports whether err was caused by a provider rate limit.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// RateLimitInfo is a provider's view of a model's remaining rate limit, read
+// from response headers. Counts are -1 and durations zero when not reported.
+type RateLimitInfo struct {
+	RemainingRequests int
+	RemainingTokens   int
+	ResetRequests     time.Duration
+	ResetTokens       time.Duration
+	// RetryAfter is set from Retry-After, usually on a 429.
+	RetryAfter time.Duration
+	// Limited means the response was a 429.
+	Limited bool
+}
+
+// rateLimitHeaders lists, per field, the header names used by OpenAI-compatible
+// providers (OpenAI, Groq, Together, OpenRouter) and Anthropic.
+var rateLimitHeaders = struct {
+	remainingRequests, remainingTokens, resetRequests, resetTokens []string
+}{
+	remainingRequests: []string{"x-ratelimit-remaining-requests", "anthropic-ratelimit-requests-remaining", "x-ratelimit-remaining"},
+	remainingTokens:   []string{"x-ratelimit-remaining-tokens", "anthropic-ratelimit-tokens-remaining"},
+	resetRequests:     []string{"x-ratelimit-reset-requests", "anthropic-ratelimit-requests-reset", "x-ratelimit-reset"},
+	resetTokens:       []string{"x-ratelimit-reset-tokens", "anthropic-ratelimit-tokens-reset"},
+}
+
+// ParseRateLimitHeaders reads rate-limit headers from a provider response. It
+// reports false when the response carries none.
+func ParseRateLimitHeaders(h http.Header, status int, now time.Time) (RateLimitInfo, bool) {
+	info := RateLimitInfo{RemainingRequests: -1, RemainingTokens: -1, Limited: status == http.StatusTooManyRequests}
+	found := info.Limited
+
+	if v, ok := firstHeader(h, rateLimitHeaders.remainingRequests); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			info.RemainingRequests, found = n, true
+		}
+	}
+	if v, ok := firstHeader(h, rateLimitHeaders.remainingTokens); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			info.RemainingTokens, found = n, true
+		}
+	}
+	if v, ok := firstHeader(h, rateLimitHeaders.resetRequests); ok {
+		info.ResetRequests, found = parseReset(v, now), true
+	}
+	if v, ok := firstHeader(h, rateLimitHeaders.resetTokens); ok {
+		info.ResetTokens, found = parseReset(v, now), true
+	}
+	if v := strings.TrimSpace(h.Get("Retry-After")); v != "" {
+		info.RetryAfter, found = parseReset(v, now), true
+	}
+	return info, found
+}
+
+func firstHeader(h http.Header, names []string) (string, bool) {
+	for _, name := range names {
+		if v := strings.TrimSpace(h.Get(name)); v != "" {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// parseReset reads a reset time given as a Go-style duration ("6m0s", "20ms"),
+// seconds, a Unix timestamp in seconds or milliseconds, or an RFC 3339 or HTTP date.
+func parseReset(v string, now time.Time) time.Duration {
+	if d, err := time.ParseDuration(v); err == nil {
+		return max(d, 0)
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		switch {
+		case f > 1e12:
+			return max(time.UnixMilli(int64(f)).Sub(now), 0)
+		case f > 1e9:
+			return max(time.Unix(int64(f), 0).Sub(now), 0)
+		default:
+			return time.Duration(f * float64(time.Second))
+		}
+	}
+	for _, layout := range []string{time.RFC3339, http.TimeFormat} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+	return 0
+}
+
+type rateLimitObserverKey struct{}
+
+func withRateLimitObserver(ctx context.Context, fn func(RateLimitInfo)) context.Context {
+	return context.WithValue(ctx, rateLimitObserverKey{}, fn)
+}
+
+// ReportRateLimit passes the rate-limit headers of a provider response to the
+// limiter handling ctx, if any. Providers call it for every response.
+func ReportRateLimit(ctx context.Context, resp *http.Response) {
+	fn, _ := ctx.Value(rateLimitObserverKey{}).(func(RateLimitInfo))
+	if fn == nil || resp == nil {
+		return
+	}
+	if info, ok := ParseRateLimitHeaders(resp.Header, resp.StatusCode, time.Now()); ok {
+		fn(info)
+	}
+}
+
+// ModelLimit is a static limit for a model or provider, used where the provider
+// sends no rate-limit headers. Zero fields impose no limit.
+type ModelLimit struct {
+	RequestsPerMinute int
+	MaxConcurrent     int
+}
+
+// ModelLimitStats describes one model's limiter state.
+type ModelLimitStats struct {
+	Active int `json:"active"`
+	Queued int `json:"queued"`
+	// Remaining is the last reported remaining request count, or -1 if unknown.
+	Remaining int `json:"remaining"`
+	// BlockedForMs is how long new requests will wait for the limit to reset.
+	BlockedForMs int64 `json:"blocked_for_ms,omitempty"`
+}
+
+// ModelLimiter paces requests per model. It adapts to the rate-limit headers
+// providers return, holding requests while a model's limit is exhausted (or
+// about to be, given the requests already in flight) until it resets, and falls
+// back to static per-model or per-provider limits. Requests wait rather than fail.
+type ModelLimiter struct {
+	provider string
+	limits   map[string]ModelLimit
+
+	mu     sync.Mutex
+	models map[string]*modelState
+	now    func() time.Time // replaced in tests
+}
+
+type modelState struct {
+	active    int
+	queued    int
+	remaining int
+	// blockedUntil holds requests until the provider's limit resets.
+	blockedUntil time.Time
+	// recent are start times within the last minute, for RequestsPerMinute.
+	recent []time.Time
+	// wake is closed and replaced whenever the state changes.
+	wake chan struct{}
+}
+
+// NewModelLimiter returns a limiter for provider's models. limits is keyed by
+// model ID or provider ID; a model's own entry wins.
+func NewModelLimiter(provider string, limits map[string]ModelLimit) *ModelLimiter {
+	return &ModelLimiter{provider: provider, limits: limits, models: make(map[string]*modelState), now: time.Now}
+}
+
+func (l *ModelLimiter) limitFor(model string) ModelLimit {
+	if lim, ok := l.limits[model]; ok {
+		return lim
+	}
+	return l.limits[l.provider]
+}
+
+func (l *ModelLimiter) state(model string) *modelState {
+	st, ok := l.models[model]
+	if !ok {
+		st = &modelState{remaining: -1, wake: make(chan struct{})}
+		l.models[model] = st
+	}
+	return st
+}
+
+// Acquire waits until a request for model may be sent and returns a release
+// to call when it finishes. It returns ctx's error if ctx ends first.
+func (l *ModelLimiter) Acquire(ctx context.Context, model string) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	limit := l.limitFor(model)
+
+	l.mu.Lock()
+	st := l.state(model)
+	queued := false
+	for {
+		now := l.now()
+		wait := l.waitLocked(st, limit, now)
+		if wait == 0 {
+			break
+		}
+		if !queued {
+			queued = true
+			st.queued++
+		}
+		wake := st.wake
+		l.mu.Unlock()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-timeout:
+		case <-wake:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		l.mu.Lock()
+		if ctx.Err() != nil {
+			st.queued--
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+	if queued {
+		st.queued--
+	}
+	st.active++
+	if limit.RequestsPerMinute > 0 {
+		st.recent = append(st.recent, l.now())
+	}
+	if st.remaining > 0 {
+		st.remaining--
+	}
+	l.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			st.active--
+			l.wakeLocked(st)
+			l.mu.Unlock()
+		})
+	}, nil
+}
+
+// waitLocked returns how long a new request must wait: 0 to go now, a positive
+// duration for a known reset, or -1 to wait for a release.
+func (l *ModelLimiter) waitLocked(st *modelState, limit ModelLimit, now time.Time) time.Duration {
+	if now.Before(st.blockedUntil) {
+		return st.blockedUntil.Sub(now)
+	}
+	if limit.MaxConcurrent > 0 && st.active >= limit.MaxConcurrent {
+		return -1
+	}
+	if limit.RequestsPerMinute > 0 {
+		cutoff := now.Add(-time.Minute)
+		i := 0
+		for i < len(st.recent) && !st.recent[i].After(cutoff) {
+			i++
+		}
+		st.recent = st.recent[i:]
+		if len(st.recent) >= limit.RequestsPerMinute {
+			return st.recent[0].Add(time.Minute).Sub(now)
+		}
+	}
+	// Near the provider's limit: the requests in flight will use up what is
+	// left, so hold new ones for their responses and a fresh count.
+	if st.remaining >= 0 && st.remaining <= st.active && st.active > 0 {
+		return -1
+	}
+	return 0
+}
+
+// Observe records rate-limit headers returned for model.
+func (l *ModelLimiter) Observe(model string, info RateLimitInfo) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st := l.state(model)
+	now := l.now()
+
+	if info.RemainingRequests >= 0 {
+		st.remaining = info.RemainingRequests
+	}
+	var block time.Duration
+	if info.Limited {
+		block = max(info.RetryAfter, info.ResetRequests, info.ResetTokens, time.Second)
+	}
+	if info.RemainingRequests == 0 {
+		block = max(block, info.ResetRequests)
+	}
+	if info.RemainingTokens == 0 {
+		block = max(block, info.ResetTokens)
+	}
+	if block > 0 {
+		if until := now.Add(block); until.After(st.blockedUntil) {
+			st.blockedUntil = until
+		}
+	} else if st.remaining != 0 {
+		st.blockedUntil = time.Time{}
+	}
+	l.wakeLocked(st)
+}
+
+func (l *ModelLimiter) wakeLocked(st *modelState) {
+	close(st.wake)
+	st.wake = make(chan struct{})
+}
+
+// Stats reports limiter state per model that has seen requests.
+func (l *ModelLimiter) Stats() map[string]ModelLimitStats {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	stats := make(map[string]ModelLimitStats, len(l.models))
+	for model, st := range l.models {
+		s := ModelLimitStats{Active: st.active, Queued: st.queued, Remaining: st.remaining}
+		if now.Before(st.blockedUntil) {
+			s.BlockedForMs = st.blockedUntil.Sub(now).Milliseconds()
+		}
+		stats[model] = s
+	}
+	return stats
+}
+
+// RateLimitedProvider wraps a Provider with a ModelLimiter: each request waits
+// for its model's turn, its response headers update the limiter, and a request
+// refused with 429 is retried once after the limit resets.
+type RateLimitedProvider struct {
+	inner   Provider
+	limiter *ModelLimiter
+}
+
+// NewRateLimitedProvider wraps inner with limiter.
+func NewRateLimitedProvider(inner Provider, limiter *ModelLimiter) *RateLimitedProvider {
+	return &RateLimitedProvider{inner: inner, limiter: limiter}
+}
+
+// Complete waits for the model's limit and performs the completion.
+func (p *RateLimitedProvider) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	var resp *ChatResponse
+	err := p.do(ctx, req.Model, func(ctx context.Context, release func()) error {
+		defer release()
+		var err error
+		resp, err = p.inner.Complete(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+// Stream waits for the model's limit and starts the stream. The model's slot
+// is held until the stream ends.
+func (p *RateLimitedProvider) Stream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
+	var out chan StreamChunk
+	err := p.do(ctx, req.Model, func(ctx context.Context, release func()) error {
+		upstream, err := p.inner.Stream(ctx, req)
+		if err != nil {
+			release()
+			return err
+		}
+		out = make(chan StreamChunk)
+		go func() {
+			defer close(out)
+			defer release()
+			for chunk := range upstream {
+				select {
+				case out <- chunk:
+				case <-ctx.Done():
+					for range upstream {
+					}
+					return
+				}
+			}
+		}()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// do acquires the model's limit and runs fn, retrying once if the provider
+// answers 429. fn must call release when its request is finished.
+func (p *RateLimitedProvider) do(ctx context.Context, model string, fn func(ctx context.Context, release func()) error) error {
+	ctx = withRateLimitObserver(ctx, func(info RateLimitInfo) {
+		p.limiter.Observe(model, info)
+	})
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		var release func()
+		release, err = p.limiter.Acquire(ctx, model)
+		if err != nil {
+			return err
+		}
+		if err = fn(ctx, release); !IsRateLimited(err) {
+			return err
+		}
+	}
+	return err
+}
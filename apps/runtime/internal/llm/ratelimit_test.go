@@ -0,0 +1,215 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+		want    RateLimitInfo
+		found   bool
+	}{
+		{
+			name:    "none",
+			headers: nil,
+			status:  http.StatusOK,
+			want:    RateLimitInfo{RemainingRequests: -1, RemainingTokens: -1},
+		},
+		{
+			name: "openai",
+			headers: map[string]string{
+				"x-ratelimit-remaining-requests": "59",
+				"x-ratelimit-remaining-tokens":   "149000",
+				"x-ratelimit-reset-requests":     "1s",
+				"x-ratelimit-reset-tokens":       "6m0s",
+			},
+			status: http.StatusOK,
+			want:   RateLimitInfo{RemainingRequests: 59, RemainingTokens: 149000, ResetRequests: time.Second, ResetTokens: 6 * time.Minute},
+			found:  true,
+		},
+		{
+			name: "anthropic",
+			headers: map[string]string{
+				"anthropic-ratelimit-requests-remaining": "0",
+				"anthropic-ratelimit-requests-reset":     "2026-01-01T12:00:30Z",
+				"retry-after":                            "30",
+			},
+			status: http.StatusTooManyRequests,
+			want:   RateLimitInfo{RemainingRequests: 0, RemainingTokens: -1, ResetRequests: 30 * time.Second, RetryAfter: 30 * time.Second, Limited: true},
+			found:  true,
+		},
+		{
+			name: "openrouter",
+			headers: map[string]string{
+				"X-RateLimit-Remaining": "3",
+				"X-RateLimit-Reset":     fmt.Sprint(now.Add(10 * time.Second).UnixMilli()),
+			},
+			status: http.StatusOK,
+			want:   RateLimitInfo{RemainingRequests: 3, RemainingTokens: -1, ResetRequests: 10 * time.Second},
+			found:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			got, found := ParseRateLimitHeaders(h, tt.status, now)
+			if found != tt.found {
+				t.Errorf("found = %v, want %v", found, tt.found)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRateLimitHeaders() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModelLimiter_MaxConcurrentQueues(t *testing.T) {
+	l := NewModelLimiter("openai", map[string]ModelLimit{"openai": {MaxConcurrent: 1}})
+
+	release, err := l.Acquire(context.Background(), "gpt-4o")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		r, err := l.Acquire(context.Background(), "gpt-4o")
+		if err != nil {
+			t.Errorf("queued Acquire() error = %v", err)
+		}
+		acquired <- r
+	}()
+
+	waitFor(t, func() bool { return l.Stats()["gpt-4o"].Queued == 1 })
+	if got := l.Stats()["gpt-4o"]; got.Active != 1 {
+		t.Errorf("Active = %d, want 1", got.Active)
+	}
+
+	// Each model has its own slots under the provider-wide limit.
+	r, err := l.Acquire(context.Background(), "gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("Acquire() for another model error = %v", err)
+	}
+	r()
+
+	release()
+	select {
+	case r := <-acquired:
+		r()
+	case <-time.After(2 * time.Second):
+		t.Fatal("queued request was not admitted after release")
+	}
+	if got := l.Stats()["gpt-4o"]; got.Active != 0 || got.Queued != 0 {
+		t.Errorf("Stats() = %+v, want idle", got)
+	}
+}
+
+func TestModelLimiter_ObserveBlocksUntilReset(t *testing.T) {
+	l := NewModelLimiter("anthropic", nil)
+	l.Observe("claude", RateLimitInfo{RemainingRequests: 0, RemainingTokens: -1, ResetRequests: 50 * time.Millisecond})
+
+	if got := l.Stats()["claude"]; got.BlockedForMs <= 0 {
+		t.Fatalf("BlockedForMs = %d, want > 0", got.BlockedForMs)
+	}
+
+	start := time.Now()
+	release, err := l.Acquire(context.Background(), "claude")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	release()
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("Acquire() waited %v, want it to wait for the reset", waited)
+	}
+}
+
+func TestModelLimiter_AcquireCanceled(t *testing.T) {
+	l := NewModelLimiter("openai", nil)
+	l.Observe("gpt-4o", RateLimitInfo{RemainingRequests: -1, RemainingTokens: -1, Limited: true, RetryAfter: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, "gpt-4o"); err != context.DeadlineExceeded {
+		t.Fatalf("Acquire() error = %v, want deadline exceeded", err)
+	}
+	if got := l.Stats()["gpt-4o"].Queued; got != 0 {
+		t.Errorf("Queued = %d after cancel, want 0", got)
+	}
+}
+
+// limitedOnceProvider answers the first request with a 429 and a short reset.
+type limitedOnceProvider struct {
+	calls int
+}
+
+func (p *limitedOnceProvider) Complete(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.calls++
+	if p.calls == 1 {
+		resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+		resp.Header.Set("x-ratelimit-reset-requests", "10ms")
+		ReportRateLimit(ctx, resp)
+		return nil, fmt.Errorf("%w: api error: 429", ErrRateLimited)
+	}
+	return &ChatResponse{Content: "ok", Role: RoleAssistant}, nil
+}
+
+func (p *limitedOnceProvider) Stream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func TestRateLimitedProvider_RetriesAfter429(t *testing.T) {
+	inner := &limitedOnceProvider{}
+	l := NewModelLimiter("openai", nil)
+	p := NewRateLimitedProvider(inner, l)
+
+	resp, err := p.Complete(context.Background(), ChatRequest{Model: "gpt-4o"})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if resp.Content != "ok" || inner.calls != 2 {
+		t.Errorf("got %q after %d calls, want ok after 2", resp.Content, inner.calls)
+	}
+	if got := l.Stats()["gpt-4o"].Active; got != 0 {
+		t.Errorf("Active = %d, want 0", got)
+	}
+}
+
+func TestRateLimitedProvider_StreamHoldsSlot(t *testing.T) {
+	l := NewModelLimiter("openai", map[string]ModelLimit{"m": {MaxConcurrent: 1}})
+	p := NewRateLimitedProvider(&countingProvider{}, l)
+
+	ch, err := p.Stream(context.Background(), ChatRequest{Model: "m"})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if got := l.Stats()["m"].Active; got != 1 {
+		t.Errorf("Active during stream = %d, want 1", got)
+	}
+	for range ch {
+	}
+	waitFor(t, func() bool { return l.Stats()["m"].Active == 0 })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,26 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ReasoningEffort is how hard a reasoning model thinks before answering.
+type ReasoningEffort string
+
+// Reasoning efforts, from fastest to most thorough.
+const (
+	ReasoningLow    ReasoningEffort = "low"
+	ReasoningMedium ReasoningEffort = "medium"
+	ReasoningHigh   ReasoningEffort = "high"
+)
+
+// ParseReasoningEffort parses low, medium or high.
+func ParseReasoningEffort(s string) (ReasoningEffort, error) {
+	switch e := ReasoningEffort(strings.ToLower(strings.TrimSpace(s))); e {
+	case ReasoningLow, ReasoningMedium, ReasoningHigh:
+		return e, nil
+	default:
+		return "", fmt.Errorf("unknown reasoning effort %q (want low, medium or high)", s)
+	}
+}
@@ -13,14 +13,40 @@ const (
 	RoleAssistant Role = "assistant"
 	// RoleSystem represents a system message that sets context/behavior.
 	RoleSystem Role = "system"
+	// RoleTool represents the result of a tool call the assistant requested.
+	RoleTool Role = "tool"
 )
 
 // Message represents a single message in a chat conversation.
 type Message struct {
-	// Role is the sender's role (user, assistant, or system).
+	// Role is the sender's role (user, assistant, system, or tool).
 	Role Role `json:"role"`
 	// Content is the message text content.
 	Content string `json:"content"`
+	// ToolCalls are the tool calls an assistant message requested.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID is the call a tool message answers.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// Tool describes a function the model may call.
+type Tool struct {
+	// Name is the function name the model calls it by.
+	Name string `json:"name"`
+	// Description tells the model what the tool does.
+	Description string `json:"description,omitempty"`
+	// Parameters is the JSON schema of the tool's argument object.
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ToolCall is a call of a Tool requested by the model.
+type ToolCall struct {
+	// ID identifies the call; the tool message with its result carries it.
+	ID string `json:"id"`
+	// Name is the name of the called tool.
+	Name string `json:"name"`
+	// Arguments is the JSON-encoded argument object.
+	Arguments string `json:"arguments"`
 }
 
 // ChatRequest represents a request to an LLM for chat completion.
@@ -31,10 +57,17 @@ type ChatRequest struct {
 	Messages []Message `json:"messages"`
 	// MaxTokens limits the number of tokens in the response.
 	MaxTokens int `json:"max_tokens,omitempty"`
-	// Temperature controls randomness (0.0-2.0, lower is more deterministic).
-	Temperature float64 `json:"temperature,omitempty"`
+	// Temperature controls randomness (0.0-2.0, lower is more deterministic);
+	// nil leaves it to the provider's default.
+	Temperature *float64 `json:"temperature,omitempty"`
 	// Stream indicates whether to stream the response.
 	Stream bool `json:"stream,omitempty"`
+	// ReasoningEffort trades speed for quality on reasoning models; empty
+	// leaves it to the provider. Providers map it to their own parameter.
+	ReasoningEffort ReasoningEffort `json:"reasoning_effort,omitempty"`
+	// Tools are the functions the model may call instead of answering.
+	// Providers map them to their own format.
+	Tools []Tool `json:"-"`
 }
 
 // ChatResponse represents a response from an LLM chat completion.
@@ -47,6 +80,8 @@ type ChatResponse struct {
 	FinishReason string `json:"finish_reason,omitempty"`
 	// Usage contains token count information.
 	Usage Usage `json:"usage"`
+	// ToolCalls are the tool calls the model requested, if any.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 }
 
 // Usage contains token usage statistics for an LLM request.
@@ -57,6 +92,9 @@ type Usage struct {
 	CompletionTokens int `json:"completion_tokens"`
 	// TotalTokens is the sum of prompt and completion tokens.
 	TotalTokens int `json:"total_tokens"`
+	// ReasoningTokens is the part of CompletionTokens spent reasoning, when
+	// the provider reports it.
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
 }
 
 // StreamChunk represents a single chunk in a streaming response.
@@ -65,6 +103,11 @@ type StreamChunk struct {
 	Content string `json:"content"`
 	// Done indicates if this is the final chunk.
 	Done bool `json:"done"`
+	// Usage is set on the final chunk when the provider reports token usage.
+	Usage *Usage `json:"usage,omitempty"`
+	// ToolCalls is set on the final chunk to the tool calls the model
+	// requested, once their arguments have been streamed in full.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
 	// Err contains any error that occurred during streaming (not serialized).
 	Err error `json:"-"`
 }
@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pryx-core/internal/audit"
+	"pryx-core/internal/mcp/security"
+)
+
+// DefaultApprovalTTL is how long a tool call waits for the user to approve it
+// before it is denied.
+const DefaultApprovalTTL = 2 * time.Minute
+
+// approvalArgRedaction keeps approval prompts short and free of credentials.
+var approvalArgRedaction = audit.RedactionPolicy{Retention: audit.RetainTruncated, MaxChars: 200}
+
+// Requester identifies what asked for a tool call, e.g. the agent on behalf
+// of a chat turn or a client of the HTTP API.
+type Requester struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+func (r Requester) label() string {
+	switch {
+	case r.Name != "":
+		return r.Name
+	case r.Kind != "":
+		return "The " + r.Kind
+	default:
+		return "Something"
+	}
+}
+
+type requesterKey struct{}
+
+// WithRequester records who is making the tool calls run on ctx, so approval
+// prompts can say who is asking.
+func WithRequester(ctx context.Context, r Requester) context.Context {
+	return context.WithValue(ctx, requesterKey{}, r)
+}
+
+// RequesterFrom returns the requester recorded on ctx, or kind "unknown".
+func RequesterFrom(ctx context.Context) Requester {
+	if r, ok := ctx.Value(requesterKey{}).(Requester); ok {
+		return r
+	}
+	return Requester{Kind: "unknown"}
+}
+
+type channelKey struct{}
+
+// WithChannel records the channel the tool calls run on ctx serve, e.g.
+// "telegram:42", so channel tool scopes apply to them.
+func WithChannel(ctx context.Context, channel string) context.Context {
+	return context.WithValue(ctx, channelKey{}, channel)
+}
+
+// scopeKeys returns the tool scope keys that apply to a call: the session ID
+// and, when ctx carries a channel, the channel and its source.
+func scopeKeys(ctx context.Context, sessionID string) []string {
+	var keys []string
+	if sessionID != "" {
+		keys = append(keys, sessionID)
+	}
+	if channel, _ := ctx.Value(channelKey{}).(string); channel != "" {
+		if source, _, ok := strings.Cut(channel, ":"); ok {
+			keys = append(keys, "channel:"+source)
+		}
+		keys = append(keys, "channel:"+channel)
+	}
+	return keys
+}
+
+// ApprovalRequest describes a tool call waiting for the user's approval, with
+// enough context to render a meaningful approve/deny prompt.
+type ApprovalRequest struct {
+	ApprovalID string                 `json:"approval_id"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	Tool       string                 `json:"tool"`
+	Server     string                 `json:"server"`
+	ToolName   string                 `json:"tool_name"`
+	Args       map[string]interface{} `json:"args"`
+	Requester  Requester              `json:"requester"`
+	Risk       string                 `json:"risk"`
+	Warnings   []string               `json:"warnings,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	Summary    string                 `json:"summary"`
+	CreatedAt  time.Time              `json:"created_at"`
+	ExpiresAt  time.Time              `json:"expires_at"`
+	// AgeMS is how long the call has been waiting, set by PendingApprovals.
+	AgeMS int64 `json:"age_ms,omitempty"`
+}
+
+// payload is the approval.needed event payload. It keeps the fields earlier
+// clients read (approval_id, tool, args, reason) at the top level.
+func (a ApprovalRequest) payload() map[string]interface{} {
+	return map[string]interface{}{
+		"approval_id": a.ApprovalID,
+		"tool":        a.Tool,
+		"server":      a.Server,
+		"tool_name":   a.ToolName,
+		"args":        a.Args,
+		"requester":   a.Requester,
+		"risk":        a.Risk,
+		"warnings":    a.Warnings,
+		"reason":      a.Reason,
+		"summary":     a.Summary,
+		"created_at":  a.CreatedAt,
+		"expires_at":  a.ExpiresAt,
+	}
+}
+
+// describeApproval builds the descriptor for a call to server's tool name.
+// The risk level comes from the policy rule when it sets one, otherwise it is
+// derived from the tool's name and description.
+func (m *Manager) describeApproval(ctx context.Context, approvalID, sessionID, server, name, fullName, reason, risk string, args map[string]interface{}) ApprovalRequest {
+	assessment := security.ClassifyToolRisk(name, m.cachedToolDescription(server, name))
+	if risk == "" {
+		risk = string(assessment.Level)
+	}
+	redacted, _ := approvalArgRedaction.Redact(args).(map[string]interface{})
+	if redacted == nil {
+		redacted = map[string]interface{}{}
+	}
+	requester := RequesterFrom(ctx)
+	now := time.Now().UTC()
+	return ApprovalRequest{
+		ApprovalID: approvalID,
+		SessionID:  sessionID,
+		Tool:       fullName,
+		Server:     server,
+		ToolName:   name,
+		Args:       redacted,
+		Requester:  requester,
+		Risk:       risk,
+		Warnings:   assessment.Warnings,
+		Reason:     reason,
+		Summary:    approvalSummary(requester, server, name, risk, redacted),
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(m.ApprovalTTL()),
+	}
+}
+
+// cachedToolDescription returns the description of a tool from the tool list
+// cache, without contacting the server.
+func (m *Manager) cachedToolDescription(server, name string) string {
+	m.cacheMu.RLock()
+	defer m.cacheMu.RUnlock()
+	for _, t := range m.cache[server].tools {
+		if t.Name == name {
+			return t.Description
+		}
+	}
+	return ""
+}
+
+// approvalSummary renders a one-line description such as
+// `The agent wants to run shell:exec (exec risk) with command="ls -la"`.
+func approvalSummary(r Requester, server, name, risk string, args map[string]interface{}) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s wants to run %s:%s (%s risk)", r.label(), server, name, risk)
+
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	const maxArgs, maxValue = 3, 60
+	for i, k := range keys {
+		if i == maxArgs {
+			fmt.Fprintf(&b, ", +%d more", len(keys)-maxArgs)
+			break
+		}
+		if i == 0 {
+			b.WriteString(" with ")
+		} else {
+			b.WriteString(", ")
+		}
+		v, _ := json.Marshal(args[k])
+		s := string(v)
+		if r := []rune(s); len(r) > maxValue {
+			s = string(r[:maxValue]) + "…"
+		}
+		fmt.Fprintf(&b, "%s=%s", k, s)
+	}
+	return b.String()
+}
+
+// PendingApprovals returns the tool calls waiting for approval, oldest first,
+// so a client that connects late can still prompt for them.
+func (m *Manager) PendingApprovals() []ApprovalRequest {
+	now := time.Now()
+	m.approvalMu.Lock()
+	out := make([]ApprovalRequest, 0, len(m.pendingApprovals))
+	for _, pa := range m.pendingApprovals {
+		req := pa.request
+		req.AgeMS = now.Sub(req.CreatedAt).Milliseconds()
+		out = append(out, req)
+	}
+	m.approvalMu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ApprovalID < out[j].ApprovalID
+	})
+	return out
+}
+
+// SetApprovalTTL sets how long a tool call waits for approval before it is
+// denied. Non-positive values restore DefaultApprovalTTL.
+func (m *Manager) SetApprovalTTL(d time.Duration) {
+	m.approvalTTL.Store(int64(max(d, 0)))
+}
+
+// ApprovalTTL returns how long a tool call waits for approval.
+func (m *Manager) ApprovalTTL() time.Duration {
+	if d := time.Duration(m.approvalTTL.Load()); d > 0 {
+		return d
+	}
+	return DefaultApprovalTTL
+}
+
+// DenyPendingApprovals denies every tool call waiting for approval and
+// returns how many there were.
+func (m *Manager) DenyPendingApprovals() int {
+	m.approvalMu.Lock()
+	ids := make([]string, 0, len(m.pendingApprovals))
+	for id := range m.pendingApprovals {
+		ids = append(ids, id)
+	}
+	m.approvalMu.Unlock()
+
+	denied := 0
+	for _, id := range ids {
+		if m.ResolveApproval(id, false) {
+			denied++
+		}
+	}
+	return denied
+}
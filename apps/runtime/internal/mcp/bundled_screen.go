@@ -9,16 +9,39 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
+
+	"pryx-core/internal/workspace"
 )
 
 type ScreenProvider struct {
 	captureDir string
 }
 
+var (
+	captureDirMu sync.RWMutex
+	captureDir   string
+)
+
+// SetCaptureDir sets the directory screen captures are written to.
+func SetCaptureDir(dir string) {
+	captureDirMu.Lock()
+	defer captureDirMu.Unlock()
+	captureDir = dir
+}
+
+func screenCaptureDir() string {
+	captureDirMu.RLock()
+	defer captureDirMu.RUnlock()
+	if captureDir != "" {
+		return captureDir
+	}
+	return filepath.Join(workspace.DefaultRoot(), workspace.DirCaptures)
+}
+
 func NewScreenProvider() *ScreenProvider {
-	home, _ := os.UserHomeDir()
-	captureDir := filepath.Join(home, ".pryx", "captures")
+	captureDir := screenCaptureDir()
 	os.MkdirAll(captureDir, 0755)
 	return &ScreenProvider{captureDir: captureDir}
 }
@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// cancelNotifyTimeout bounds sending notifications/cancelled for an abandoned request.
+const cancelNotifyTimeout = 2 * time.Second
+
 type Transport interface {
 	Call(ctx context.Context, req RPCRequest) (RPCResponse, error)
 	Notify(ctx context.Context, notif RPCNotification) error
@@ -122,7 +124,7 @@ func (c *Client) CallTool(ctx context.Context, name string, arguments map[string
 		return ToolResult{}, err
 	}
 	if out.IsError {
-		return out, errors.New("tool returned error")
+		return out, toolResultError(out)
 	}
 	return out, nil
 }
@@ -144,10 +146,13 @@ func (c *Client) call(ctx context.Context, method string, params interface{}, ou
 	}
 	resp, err := c.transport.Call(ctx, req)
 	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) && method != "initialize" {
+			c.notifyCancelled(ctx, id)
+		}
 		return err
 	}
 	if resp.Error != nil {
-		return fmt.Errorf("mcp error %d: %s", resp.Error.Code, resp.Error.Message)
+		return resp.Error
 	}
 	if len(resp.Result) == 0 {
 		return errors.New("empty result")
@@ -160,3 +165,18 @@ func (c *Client) call(ctx context.Context, method string, params interface{}, ou
 	}
 	return nil
 }
+
+// notifyCancelled tells the server to stop working on request id, whose caller
+// has gone away. The notice is best effort, sent on a short context of its own.
+func (c *Client) notifyCancelled(ctx context.Context, id int64) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelNotifyTimeout)
+	defer cancel()
+	_ = c.transport.Notify(ctx, RPCNotification{
+		JSONRPC: "2.0",
+		Method:  "notifications/cancelled",
+		Params: map[string]interface{}{
+			"requestId": id,
+			"reason":    "client cancelled the request",
+		},
+	})
+}
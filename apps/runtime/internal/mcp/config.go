@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type ServersConfig struct {
@@ -13,14 +14,24 @@ type ServersConfig struct {
 }
 
 type ServerConfig struct {
-	Transport       string            `json:"transport"`
-	URL             string            `json:"url,omitempty"`
-	Command         []string          `json:"command,omitempty"`
-	Cwd             string            `json:"cwd,omitempty"`
+	Transport string   `json:"transport"`
+	URL       string   `json:"url,omitempty"`
+	Command   []string `json:"command,omitempty"`
+	// WorkingDir is the directory a stdio server runs in and must exist. Empty
+	// keeps the runtime's working directory.
+	WorkingDir string `json:"working_dir,omitempty"`
+	// Cwd is the former name of WorkingDir, still read from older configs.
+	Cwd string `json:"cwd,omitempty"`
+	// Env is set for a stdio server on top of a minimal default environment;
+	// other runtime variables are only passed through if listed in InheritEnv.
 	Env             map[string]string `json:"env,omitempty"`
+	InheritEnv      []string          `json:"inherit_env,omitempty"`
 	Headers         map[string]string `json:"headers,omitempty"`
 	ProtocolVersion string            `json:"protocol_version,omitempty"`
 	Auth            *AuthConfig       `json:"auth,omitempty"`
+	// LastVerifiedAt and ToolCount record the last successful probe of the server.
+	LastVerifiedAt *time.Time `json:"last_verified_at,omitempty"`
+	ToolCount      int        `json:"tool_count,omitempty"`
 }
 
 type AuthConfig struct {
@@ -78,6 +89,10 @@ func normalizeServerTransports(cfg *ServersConfig) {
 		} else if transport == "stdio" && server.URL != "" && len(server.Command) == 0 {
 			server.Transport = "http"
 		}
+		if server.WorkingDir == "" {
+			server.WorkingDir = server.Cwd
+		}
+		server.Cwd = ""
 		cfg.Servers[name] = server
 	}
 }
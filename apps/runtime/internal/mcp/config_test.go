@@ -39,3 +39,23 @@ func TestLoadServersConfigFromFirstExisting_Parse(t *testing.T) {
 		t.Fatalf("expected server x transport http, got %q", cfg.Servers["x"].Transport)
 	}
 }
+
+func TestLoadServersConfigFromFirstExisting_LegacyCwd(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "servers.json")
+	data := `{"servers":{"old":{"command":["srv"],"cwd":"/srv/old"},"new":{"command":["srv"],"cwd":"/ignored","working_dir":"/srv/new"}}}`
+	if err := os.WriteFile(p, []byte(data), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	cfg, _, err := LoadServersConfigFromFirstExisting([]string{p})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if got := cfg.Servers["old"].WorkingDir; got != "/srv/old" {
+		t.Fatalf("expected cwd to be read as working_dir, got %q", got)
+	}
+	if got := cfg.Servers["new"].WorkingDir; got != "/srv/new" {
+		t.Fatalf("expected working_dir to win over cwd, got %q", got)
+	}
+}
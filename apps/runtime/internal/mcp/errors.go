@@ -0,0 +1,176 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ToolErrorCode classifies why a tool call failed.
+type ToolErrorCode string
+
+// Tool error codes reported back to the model.
+const (
+	ToolErrTimeout          ToolErrorCode = "timeout"
+	ToolErrUnavailable      ToolErrorCode = "unavailable"
+	ToolErrServer           ToolErrorCode = "server_error"
+	ToolErrRateLimited      ToolErrorCode = "rate_limited"
+	ToolErrInvalidArguments ToolErrorCode = "invalid_arguments"
+	ToolErrPermissionDenied ToolErrorCode = "permission_denied"
+	ToolErrNotFound         ToolErrorCode = "not_found"
+	ToolErrToolFailed       ToolErrorCode = "tool_failed"
+	ToolErrCancelled        ToolErrorCode = "cancelled"
+	ToolErrUnknown          ToolErrorCode = "unknown"
+)
+
+// JSON-RPC error codes used by MCP servers.
+const (
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+)
+
+// ToolError is a classified tool call failure. Retriable errors are transient
+// (timeouts, 5xx, rate limits); the rest will fail again with the same input.
+type ToolError struct {
+	Code      ToolErrorCode
+	Message   string
+	Retriable bool
+	Err       error
+}
+
+func (e *ToolError) Error() string {
+	return e.Message
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.Err
+}
+
+// Payload returns the structured form sent to the model and recorded in the transcript.
+func (e *ToolError) Payload() map[string]interface{} {
+	return map[string]interface{}{
+		"error":     e.Message,
+		"code":      string(e.Code),
+		"retriable": e.Retriable,
+	}
+}
+
+func newToolError(code ToolErrorCode, message string) *ToolError {
+	return &ToolError{Code: code, Message: message, Retriable: isRetriableCode(code)}
+}
+
+func isRetriableCode(code ToolErrorCode) bool {
+	switch code {
+	case ToolErrTimeout, ToolErrUnavailable, ToolErrServer, ToolErrRateLimited:
+		return true
+	}
+	return false
+}
+
+// Error implements error so JSON-RPC failures can be classified by code.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+// HTTPStatusError is returned by HTTP-based transports for non-2xx responses.
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPStatusError) Error() string {
+	if e.Body != "" {
+		return e.Body
+	}
+	return fmt.Sprintf("request failed: %d", e.StatusCode)
+}
+
+// ClassifyToolError converts err into a ToolError. It returns nil for a nil error
+// and err itself when it is already classified.
+func ClassifyToolError(err error) *ToolError {
+	if err == nil {
+		return nil
+	}
+	var te *ToolError
+	if errors.As(err, &te) {
+		return te
+	}
+
+	code := classify(err)
+	return &ToolError{Code: code, Message: err.Error(), Retriable: isRetriableCode(code), Err: err}
+}
+
+func classify(err error) ToolErrorCode {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ToolErrTimeout
+	}
+	if errors.Is(err, context.Canceled) {
+		return ToolErrCancelled
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return ToolErrTimeout
+		}
+		return ToolErrUnavailable
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.StatusCode == 429:
+			return ToolErrRateLimited
+		case statusErr.StatusCode >= 500:
+			return ToolErrServer
+		case statusErr.StatusCode == 401 || statusErr.StatusCode == 403:
+			return ToolErrPermissionDenied
+		case statusErr.StatusCode == 404:
+			return ToolErrNotFound
+		case statusErr.StatusCode == 408:
+			return ToolErrTimeout
+		default:
+			return ToolErrInvalidArguments
+		}
+	}
+
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.Code {
+		case rpcInvalidParams:
+			return ToolErrInvalidArguments
+		case rpcMethodNotFound:
+			return ToolErrNotFound
+		case rpcInternalError:
+			return ToolErrServer
+		default:
+			return ToolErrToolFailed
+		}
+	}
+
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "transport closed"), strings.Contains(msg, "connection refused"), strings.Contains(msg, "broken pipe"):
+		return ToolErrUnavailable
+	case strings.Contains(msg, "timed out"), strings.Contains(msg, "timeout"):
+		return ToolErrTimeout
+	}
+	return ToolErrUnknown
+}
+
+// toolResultError builds the error for a result the tool itself flagged with isError.
+func toolResultError(res ToolResult) *ToolError {
+	var parts []string
+	for _, c := range res.Content {
+		if c.Type == "text" && strings.TrimSpace(c.Text) != "" {
+			parts = append(parts, strings.TrimSpace(c.Text))
+		}
+	}
+	msg := "tool returned error"
+	if len(parts) > 0 {
+		msg = msg + ": " + strings.Join(parts, "\n")
+	}
+	return newToolError(ToolErrToolFailed, msg)
+}
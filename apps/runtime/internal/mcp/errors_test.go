@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyToolError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      ToolErrorCode
+		retriable bool
+	}{
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), ToolErrTimeout, true},
+		{"http 503", &HTTPStatusError{StatusCode: 503, Body: "unavailable"}, ToolErrServer, true},
+		{"http 429", &HTTPStatusError{StatusCode: 429}, ToolErrRateLimited, true},
+		{"http 403", &HTTPStatusError{StatusCode: 403}, ToolErrPermissionDenied, false},
+		{"invalid params", &RPCError{Code: rpcInvalidParams, Message: "missing path"}, ToolErrInvalidArguments, false},
+		{"method not found", &RPCError{Code: rpcMethodNotFound, Message: "no such tool"}, ToolErrNotFound, false},
+		{"transport closed", errors.New("transport closed"), ToolErrUnavailable, true},
+		{"cancelled", fmt.Errorf("call: %w", context.Canceled), ToolErrCancelled, false},
+		{"opaque", errors.New("boom"), ToolErrUnknown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyToolError(tt.err)
+			if got.Code != tt.code || got.Retriable != tt.retriable {
+				t.Fatalf("ClassifyToolError(%v) = %s/%v, want %s/%v", tt.err, got.Code, got.Retriable, tt.code, tt.retriable)
+			}
+			if got.Message != tt.err.Error() {
+				t.Fatalf("message = %q, want %q", got.Message, tt.err.Error())
+			}
+		})
+	}
+
+	if ClassifyToolError(nil) != nil {
+		t.Fatal("expected nil for nil error")
+	}
+	denied := newToolError(ToolErrPermissionDenied, "denied by policy")
+	if got := ClassifyToolError(fmt.Errorf("wrapped: %w", denied)); got != denied {
+		t.Fatal("expected an already classified error to be returned as is")
+	}
+}
+
+func TestToolResultError(t *testing.T) {
+	err := toolResultError(ToolResult{IsError: true, Content: []ToolContent{{Type: "text", Text: "file not found"}}})
+	if err.Code != ToolErrToolFailed || err.Retriable {
+		t.Fatalf("unexpected classification: %s/%v", err.Code, err.Retriable)
+	}
+	payload := err.Payload()
+	if payload["error"] != "tool returned error: file not found" || payload["code"] != "tool_failed" || payload["retriable"] != false {
+		t.Fatalf("unexpected payload: %v", payload)
+	}
+}
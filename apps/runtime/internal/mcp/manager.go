@@ -5,29 +5,45 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"pryx-core/internal/bus"
 	"pryx-core/internal/hostrpc"
 	"pryx-core/internal/keychain"
 	"pryx-core/internal/policy"
+	"pryx-core/internal/secrets"
 )
 
+// listCacheTTL is how long tool, resource and prompt lists are served from cache.
+const listCacheTTL = 30 * time.Second
+
+// DefaultCallTimeout bounds a tool call when no other timeout is set.
+const DefaultCallTimeout = 60 * time.Second
+
 type Manager struct {
 	bus      *bus.Bus
+	errors   *bus.ErrorEmitter
 	policy   *policy.Engine
 	keychain *keychain.Keychain
 
 	mu      sync.RWMutex
 	clients map[string]*Client
 
-	cacheMu sync.RWMutex
-	cache   map[string]cachedTools
+	cacheMu       sync.RWMutex
+	cache         map[string]cachedTools
+	resourceCache map[string]cachedList[Resource]
+	promptCache   map[string]cachedList[Prompt]
 
 	approvalMu       sync.Mutex
 	pendingApprovals map[string]pendingApproval
+
+	// callTimeout and approvalTTL are time.Durations; zero means the default.
+	callTimeout atomic.Int64
+	approvalTTL atomic.Int64
 }
 
 type cachedTools struct {
@@ -39,8 +55,7 @@ type pendingApproval struct {
 	ch        chan bool
 	sessionID string
 	tool      string
-	reason    string
-	args      map[string]interface{}
+	request   ApprovalRequest
 }
 
 func NewManager(b *bus.Bus, p *policy.Engine, kc *keychain.Keychain) *Manager {
@@ -49,14 +64,31 @@ func NewManager(b *bus.Bus, p *policy.Engine, kc *keychain.Keychain) *Manager {
 	}
 	return &Manager{
 		bus:              b,
+		errors:           bus.NewErrorEmitter(b, bus.DefaultErrorWindow),
 		policy:           p,
 		keychain:         kc,
 		clients:          map[string]*Client{},
 		cache:            map[string]cachedTools{},
+		resourceCache:    map[string]cachedList[Resource]{},
+		promptCache:      map[string]cachedList[Prompt]{},
 		pendingApprovals: map[string]pendingApproval{},
 	}
 }
 
+// SetCallTimeout sets how long a server may take to run a tool once the call
+// is approved. Non-positive values restore DefaultCallTimeout.
+func (m *Manager) SetCallTimeout(d time.Duration) {
+	m.callTimeout.Store(int64(max(d, 0)))
+}
+
+// CallTimeout returns the timeout applied to each tool call.
+func (m *Manager) CallTimeout() time.Duration {
+	if d := time.Duration(m.callTimeout.Load()); d > 0 {
+		return d
+	}
+	return DefaultCallTimeout
+}
+
 func (m *Manager) ResolveApproval(approvalID string, approved bool) bool {
 	m.approvalMu.Lock()
 	pa, ok := m.pendingApprovals[approvalID]
@@ -135,13 +167,7 @@ func (m *Manager) LoadAndConnect(ctx context.Context) (string, error) {
 }
 
 func (m *Manager) ListTools(ctx context.Context, refresh bool) (map[string][]Tool, error) {
-	m.mu.RLock()
-	clients := make(map[string]*Client, len(m.clients))
-	for k, v := range m.clients {
-		clients[k] = v
-	}
-	m.mu.RUnlock()
-
+	clients := m.clientsSnapshot()
 	out := make(map[string][]Tool, len(clients))
 	for name, c := range clients {
 		tools, err := m.listToolsCached(ctx, name, c, refresh)
@@ -174,20 +200,48 @@ func (m *Manager) ListToolsFlat(ctx context.Context, refresh bool) ([]Tool, erro
 	return all, nil
 }
 
+// CallTool runs a tool after policy checks and approval. Failures are returned as
+// *ToolError and published as a tool.complete event carrying the structured error.
 func (m *Manager) CallTool(ctx context.Context, sessionID string, toolName string, args map[string]interface{}) (ToolResult, error) {
+	res, err := m.callTool(ctx, sessionID, toolName, args)
+	if err == nil {
+		return res, nil
+	}
+	toolErr := ClassifyToolError(err)
+	if m.bus != nil {
+		m.bus.Publish(bus.NewEvent(bus.EventToolComplete, sessionID, map[string]interface{}{
+			"tool":  toolName,
+			"error": toolErr.Payload(),
+		}))
+	}
+	return res, toolErr
+}
+
+func (m *Manager) callTool(ctx context.Context, sessionID string, toolName string, args map[string]interface{}) (ToolResult, error) {
 	server, name := splitToolName(toolName)
 	if server == "" || name == "" {
-		return ToolResult{}, errors.New("invalid tool name")
+		return ToolResult{}, newToolError(ToolErrInvalidArguments, "invalid tool name")
+	}
+
+	fullName := fmt.Sprintf("mcp.%s.%s", server, name)
+	if scope := m.policy.EvaluateScope(scopeKeys(ctx, sessionID), fullName); scope.Decision == policy.DecisionDeny {
+		if m.bus != nil {
+			m.bus.Publish(bus.NewEvent(bus.EventToolDenied, sessionID, map[string]interface{}{
+				"tool":      fullName,
+				"reason":    scope.Reason,
+				"requester": RequesterFrom(ctx),
+			}))
+		}
+		return ToolResult{}, newToolError(ToolErrPermissionDenied, scope.Reason)
 	}
 
 	m.mu.RLock()
 	client := m.clients[server]
 	m.mu.RUnlock()
 	if client == nil {
-		return ToolResult{}, fmt.Errorf("unknown mcp server: %s", server)
+		return ToolResult{}, newToolError(ToolErrNotFound, fmt.Sprintf("unknown mcp server: %s", server))
 	}
 
-	fullName := fmt.Sprintf("mcp.%s.%s", server, name)
 	decision := m.policy.Evaluate(fullName, args)
 	if m.bus != nil {
 		m.bus.Publish(bus.NewEvent(bus.EventToolRequest, sessionID, map[string]interface{}{
@@ -210,47 +264,64 @@ func (m *Manager) CallTool(ctx context.Context, sessionID string, toolName strin
 				break
 			}
 			if err != nil {
-				return ToolResult{}, fmt.Errorf("approval failed: %w", err)
+				return ToolResult{}, &ToolError{Code: ToolErrPermissionDenied, Message: fmt.Sprintf("approval failed: %v", err), Err: err}
 			}
-			return ToolResult{}, errors.New("denied by user")
+			return ToolResult{}, newToolError(ToolErrPermissionDenied, "denied by user")
 		}
 		approvalID := fmt.Sprintf("%s-%d", sessionID, time.Now().UnixNano())
 		ch := make(chan bool, 1)
 
+		request := m.describeApproval(ctx, approvalID, sessionID, server, name, fullName, decision.Reason, decision.Risk, args)
+
 		m.approvalMu.Lock()
 		m.pendingApprovals[approvalID] = pendingApproval{
 			ch:        ch,
 			sessionID: sessionID,
 			tool:      fullName,
-			reason:    decision.Reason,
-			args:      args,
+			request:   request,
 		}
 		m.approvalMu.Unlock()
 
 		if m.bus != nil {
-			m.bus.Publish(bus.NewEvent(bus.EventApprovalNeeded, sessionID, map[string]interface{}{
-				"approval_id": approvalID,
-				"tool":        fullName,
-				"args":        args,
-				"reason":      decision.Reason,
-			}))
+			m.bus.Publish(bus.NewEvent(bus.EventApprovalNeeded, sessionID, request.payload()))
 		}
 
-		waitCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		waitCtx, cancel := context.WithDeadline(ctx, request.ExpiresAt)
 		defer cancel()
 		select {
 		case approved := <-ch:
 			if !approved {
-				return ToolResult{}, errors.New("denied by user")
+				return ToolResult{}, newToolError(ToolErrPermissionDenied, "denied by user")
 			}
 		case <-waitCtx.Done():
 			m.approvalMu.Lock()
 			delete(m.pendingApprovals, approvalID)
 			m.approvalMu.Unlock()
-			return ToolResult{}, errors.New("approval timed out")
+			if errors.Is(ctx.Err(), context.Canceled) {
+				// The caller went away; withdraw the prompt from other clients
+				if m.bus != nil {
+					m.bus.Publish(bus.NewEvent(bus.EventApprovalResolved, sessionID, map[string]interface{}{
+						"approval_id": approvalID,
+						"tool":        fullName,
+						"approved":    false,
+						"cancelled":   true,
+					}))
+				}
+				return ToolResult{}, &ToolError{Code: ToolErrCancelled, Message: "cancelled while awaiting approval", Err: ctx.Err()}
+			}
+			// Nobody answered in time; withdraw the prompt and deny the call
+			if m.bus != nil {
+				m.bus.Publish(bus.NewEvent(bus.EventApprovalResolved, sessionID, map[string]interface{}{
+					"approval_id": approvalID,
+					"tool":        fullName,
+					"approved":    false,
+					"expired":     true,
+				}))
+			}
+			return ToolResult{}, newToolError(ToolErrPermissionDenied, "approval timed out")
 		}
 	case policy.DecisionDeny:
-		return ToolResult{}, errors.New("denied by policy")
+		return ToolResult{}, newToolError(ToolErrPermissionDenied, "denied by policy")
 	default:
 		return ToolResult{}, errors.New("unknown policy decision")
 	}
@@ -261,14 +332,30 @@ func (m *Manager) CallTool(ctx context.Context, sessionID string, toolName strin
 		}))
 	}
 
-	res, err := client.CallTool(ctx, name, args)
-	if err != nil {
+	timeout := m.CallTimeout()
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	res, err := client.CallTool(callCtx, name, args)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		err = &ToolError{
+			Code:      ToolErrTimeout,
+			Message:   fmt.Sprintf("mcp server %q did not finish tool %q within %s", server, name, timeout),
+			Retriable: true,
+			Err:       err,
+		}
 		if m.bus != nil {
-			m.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionID, map[string]interface{}{
-				"tool":  fullName,
-				"error": err.Error(),
+			m.bus.Publish(bus.NewEvent(bus.EventMCPCallTimeout, sessionID, map[string]interface{}{
+				"server":     server,
+				"tool":       name,
+				"timeout_ms": timeout.Milliseconds(),
 			}))
 		}
+	}
+	if err != nil {
+		m.errors.Emit(sessionID, map[string]interface{}{
+			"tool":  fullName,
+			"error": err.Error(),
+		})
 		return ToolResult{}, err
 	}
 
@@ -292,7 +379,11 @@ func (m *Manager) buildClient(name string, sc ServerConfig) (*Client, error) {
 		tr := NewBundledTransport(provider)
 		return NewClient(tr, proto), nil
 	case "stdio":
-		tr := NewStdioTransport(sc.Command, sc.Cwd, sc.Env)
+		dir, err := stdioWorkingDir(sc)
+		if err != nil {
+			return nil, err
+		}
+		tr := NewStdioTransport(sc.Command, dir, stdioEnvOverrides(sc))
 		return NewClient(tr, proto), nil
 	case "http":
 		headers := map[string]string{}
@@ -323,6 +414,82 @@ func (m *Manager) buildClient(name string, sc ServerConfig) (*Client, error) {
 	}
 }
 
+// stdioWorkingDir returns the directory to start a stdio server in, with a
+// leading ~ expanded. It fails if the directory does not exist.
+func stdioWorkingDir(sc ServerConfig) (string, error) {
+	dir := strings.TrimSpace(sc.WorkingDir)
+	if dir == "" {
+		dir = strings.TrimSpace(sc.Cwd)
+	}
+	if dir == "" {
+		return "", nil
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("working_dir %q: %w", dir, err)
+		}
+		dir = filepath.Join(home, strings.TrimPrefix(dir, "~"))
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("working_dir %q: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("working_dir %q is not a directory", dir)
+	}
+	return dir, nil
+}
+
+// stdioEnvOverrides returns the variables a stdio server gets on top of the
+// minimal default environment: those passed through by InheritEnv, then Env.
+func stdioEnvOverrides(sc ServerConfig) map[string]string {
+	env := map[string]string{}
+	for _, k := range sc.InheritEnv {
+		k = strings.TrimSpace(k)
+		if v, ok := os.LookupEnv(k); ok && k != "" {
+			env[k] = v
+		}
+	}
+	for k, v := range sc.Env {
+		env[k] = v
+	}
+	return env
+}
+
+// DefaultProbeTimeout bounds Probe when ctx has no earlier deadline.
+const DefaultProbeTimeout = 15 * time.Second
+
+// Probe connects to the server described by sc, lists its tools and
+// disconnects. It is used to check a configuration before saving it and does
+// not touch the manager's connected clients.
+func (m *Manager) Probe(ctx context.Context, name string, sc ServerConfig) ([]Tool, error) {
+	client, err := m.buildClient(name, sc)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultProbeTimeout)
+	defer cancel()
+	if err := client.Initialize(ctx); err != nil {
+		return nil, fmt.Errorf("initialize: %w", err)
+	}
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list tools: %w", err)
+	}
+	return tools, nil
+}
+
+// secretResolver resolves secret references, using the keychain when one is configured.
+func (m *Manager) secretResolver() *secrets.Resolver {
+	if m.keychain == nil {
+		return secrets.NewResolver(nil)
+	}
+	return secrets.NewResolver(m.keychain)
+}
+
 func (m *Manager) applyAuth(headers map[string]string, ac AuthConfig) error {
 	if strings.ToLower(strings.TrimSpace(ac.Type)) != "oauth" {
 		return nil
@@ -332,21 +499,13 @@ func (m *Manager) applyAuth(headers map[string]string, ac AuthConfig) error {
 	if ref == "" {
 		return errors.New("oauth requires token_ref")
 	}
-	if !strings.HasPrefix(ref, "keychain:") {
-		return errors.New("unsupported token_ref")
+	if _, err := secrets.Parse(ref); err != nil {
+		return fmt.Errorf("unsupported token_ref: %w", err)
 	}
-	if m.keychain == nil {
-		return errors.New("keychain not available")
-	}
-
-	key := strings.TrimPrefix(ref, "keychain:")
-	token, err := m.keychain.Get(key)
+	token, err := m.secretResolver().Resolve(ref)
 	if err != nil {
 		return err
 	}
-	if strings.TrimSpace(token) == "" {
-		return errors.New("empty token")
-	}
 
 	headers["Authorization"] = "Bearer " + token
 	return nil
@@ -357,7 +516,7 @@ func (m *Manager) listToolsCached(ctx context.Context, name string, c *Client, r
 		m.cacheMu.RLock()
 		item, ok := m.cache[name]
 		m.cacheMu.RUnlock()
-		if ok && time.Since(item.fetchedAt) < 30*time.Second {
+		if ok && time.Since(item.fetchedAt) < listCacheTTL {
 			return item.tools, nil
 		}
 	}
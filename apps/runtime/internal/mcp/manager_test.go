@@ -250,3 +250,398 @@ func BenchmarkManager_ResolveApproval(b *testing.B) {
 		mgr.pendingApprovals[id] = pendingApproval{ch: make(chan bool, 1)}
 	}
 }
+
+func TestManager_Probe(t *testing.T) {
+	mgr := NewManager(bus.New(), nil, nil)
+
+	tools, err := mgr.Probe(context.Background(), "clipboard", ServerConfig{Transport: "bundled"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, tools)
+
+	_, err = mgr.Probe(context.Background(), "broken", ServerConfig{Transport: "http", URL: "http://127.0.0.1:1/mcp"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "initialize")
+
+	_, err = mgr.Probe(context.Background(), "odd", ServerConfig{Transport: "carrier-pigeon"})
+	assert.Error(t, err)
+}
+
+// cancellingTransport behaves like a network transport: a call whose context
+// ends reports the context error, and notifications are recorded.
+type cancellingTransport struct {
+	*MockTransport
+	notified chan RPCNotification
+}
+
+func (t *cancellingTransport) Call(ctx context.Context, req RPCRequest) (RPCResponse, error) {
+	resp, err := t.MockTransport.Call(ctx, req)
+	if ctx.Err() != nil {
+		return RPCResponse{}, ctx.Err()
+	}
+	return resp, err
+}
+
+func (t *cancellingTransport) Notify(ctx context.Context, notif RPCNotification) error {
+	if notif.Method == "notifications/cancelled" {
+		t.notified <- notif
+	}
+	return t.MockTransport.Notify(ctx, notif)
+}
+
+func TestManager_CallTool_CancelledInFlight(t *testing.T) {
+	server := NewMockServer()
+	started := make(chan struct{})
+	server.CallToolFunc = func(ctx context.Context, name string, args map[string]interface{}) (ToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		return ToolResult{}, ctx.Err()
+	}
+	tr := &cancellingTransport{MockTransport: NewMockTransport(server), notified: make(chan RPCNotification, 1)}
+
+	mgr := NewManager(bus.New(), policy.NewEngine(&policy.Policy{Default: policy.DecisionAllow}), nil)
+	mgr.clients["slow"] = NewClient(tr, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := mgr.CallTool(ctx, "session-1", "slow:echo", map[string]interface{}{"message": "hi"})
+		errCh <- err
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("tool call never reached the server")
+	}
+	cancel()
+
+	select {
+	case err := <-errCh:
+		var toolErr *ToolError
+		assert.ErrorAs(t, err, &toolErr)
+		assert.Equal(t, ToolErrCancelled, toolErr.Code)
+		assert.False(t, toolErr.Retriable)
+	case <-time.After(2 * time.Second):
+		t.Fatal("CallTool did not return after cancellation")
+	}
+
+	select {
+	case notif := <-tr.notified:
+		assert.Equal(t, "notifications/cancelled", notif.Method)
+		params := notif.Params.(map[string]interface{})
+		assert.NotNil(t, params["requestId"])
+	case <-time.After(time.Second):
+		t.Fatal("expected a cancelled notification")
+	}
+}
+
+func TestManager_CallTool_Timeout(t *testing.T) {
+	server := NewMockServer()
+	server.CallToolFunc = func(ctx context.Context, name string, args map[string]interface{}) (ToolResult, error) {
+		<-ctx.Done()
+		return ToolResult{}, ctx.Err()
+	}
+	tr := &cancellingTransport{MockTransport: NewMockTransport(server), notified: make(chan RPCNotification, 1)}
+
+	b := bus.New()
+	events, unsubscribe := b.Subscribe(bus.EventMCPCallTimeout)
+	defer unsubscribe()
+
+	mgr := NewManager(b, policy.NewEngine(&policy.Policy{Default: policy.DecisionAllow}), nil)
+	mgr.clients["slow"] = NewClient(tr, "")
+	mgr.SetCallTimeout(20 * time.Millisecond)
+
+	_, err := mgr.CallTool(context.Background(), "session-1", "slow:echo", map[string]interface{}{"message": "hi"})
+	var toolErr *ToolError
+	if !assert.ErrorAs(t, err, &toolErr) {
+		return
+	}
+	assert.Equal(t, ToolErrTimeout, toolErr.Code)
+	assert.True(t, toolErr.Retriable)
+	assert.Contains(t, toolErr.Message, `"slow"`)
+	assert.Contains(t, toolErr.Message, `"echo"`)
+
+	select {
+	case evt := <-events:
+		payload := evt.Payload.(map[string]interface{})
+		assert.Equal(t, "slow", payload["server"])
+		assert.Equal(t, "echo", payload["tool"])
+		assert.Equal(t, int64(20), payload["timeout_ms"])
+	case <-time.After(time.Second):
+		t.Fatal("expected an mcp.call_timeout event")
+	}
+
+	mgr.SetCallTimeout(0)
+	assert.Equal(t, DefaultCallTimeout, mgr.CallTimeout())
+}
+
+func TestManager_CallTool_CancelledAwaitingApproval(t *testing.T) {
+	b := bus.New()
+	events, unsubscribe := b.Subscribe(bus.EventApprovalNeeded, bus.EventApprovalResolved)
+	defer unsubscribe()
+
+	mgr := NewManager(b, nil, nil)
+	mgr.clients["mock"] = NewClient(NewMockTransport(NewMockServer()), "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := mgr.CallTool(ctx, "session-1", "mock:echo", map[string]interface{}{"message": "hi"})
+		errCh <- err
+	}()
+
+	var approvalID string
+	select {
+	case evt := <-events:
+		assert.Equal(t, bus.EventApprovalNeeded, evt.Event)
+		approvalID = evt.Payload.(map[string]interface{})["approval_id"].(string)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an approval request")
+	}
+	cancel()
+
+	select {
+	case err := <-errCh:
+		var toolErr *ToolError
+		assert.ErrorAs(t, err, &toolErr)
+		assert.Equal(t, ToolErrCancelled, toolErr.Code)
+	case <-time.After(2 * time.Second):
+		t.Fatal("CallTool did not return after cancellation")
+	}
+
+	select {
+	case evt := <-events:
+		assert.Equal(t, bus.EventApprovalResolved, evt.Event)
+		payload := evt.Payload.(map[string]interface{})
+		assert.Equal(t, approvalID, payload["approval_id"])
+		assert.Equal(t, true, payload["cancelled"])
+	case <-time.After(time.Second):
+		t.Fatal("expected the approval to be withdrawn")
+	}
+	assert.False(t, mgr.ResolveApproval(approvalID, true))
+}
+
+func TestManager_ApprovalDescriptor(t *testing.T) {
+	b := bus.New()
+	events, unsubscribe := b.Subscribe(bus.EventApprovalNeeded)
+	defer unsubscribe()
+
+	p := policy.NewDefaultPolicy()
+	p.Rules = append(p.Rules, policy.Rule{Tool: "mcp.mock.echo", Decision: policy.DecisionAsk, Description: "Echo needs a look", Risk: "network"})
+	mgr := NewManager(b, policy.NewEngine(p), nil)
+	mgr.clients["mock"] = NewClient(NewMockTransport(NewMockServer()), "")
+
+	ctx := WithRequester(context.Background(), Requester{Kind: "agent", ID: "call-1"})
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := mgr.CallTool(ctx, "session-1", "mock:echo", map[string]interface{}{
+			"message": "hi",
+			"api_key": "sk-abcdefghijklmnopqrstuvwxyz",
+		})
+		errCh <- err
+	}()
+
+	var payload map[string]interface{}
+	select {
+	case evt := <-events:
+		payload = evt.Payload.(map[string]interface{})
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an approval request")
+	}
+
+	approvalID := payload["approval_id"].(string)
+	assert.Equal(t, "mcp.mock.echo", payload["tool"])
+	assert.Equal(t, "network", payload["risk"])
+	assert.Equal(t, "Echo needs a look", payload["reason"])
+	assert.Equal(t, Requester{Kind: "agent", ID: "call-1"}, payload["requester"])
+	args := payload["args"].(map[string]interface{})
+	assert.Equal(t, "hi", args["message"])
+	assert.NotContains(t, args["api_key"], "sk-abc")
+	assert.Contains(t, payload["summary"], "The agent wants to run mock:echo (network risk)")
+	assert.NotContains(t, payload["summary"], "sk-abc")
+	expires := payload["expires_at"].(time.Time)
+	assert.WithinDuration(t, time.Now().Add(DefaultApprovalTTL), expires, 5*time.Second)
+
+	pending := mgr.PendingApprovals()
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, approvalID, pending[0].ApprovalID)
+		assert.Equal(t, "session-1", pending[0].SessionID)
+	}
+
+	assert.True(t, mgr.ResolveApproval(approvalID, true))
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("CallTool did not return after approval")
+	}
+	assert.Empty(t, mgr.PendingApprovals())
+}
+
+func TestManager_ApprovalExpires(t *testing.T) {
+	b := bus.New()
+	events, unsubscribe := b.Subscribe(bus.EventApprovalResolved)
+	defer unsubscribe()
+
+	p := policy.NewDefaultPolicy()
+	p.Rules = append(p.Rules, policy.Rule{Tool: "mcp.mock.echo", Decision: policy.DecisionAsk})
+	mgr := NewManager(b, policy.NewEngine(p), nil)
+	mgr.clients["mock"] = NewClient(NewMockTransport(NewMockServer()), "")
+	mgr.SetApprovalTTL(20 * time.Millisecond)
+
+	_, err := mgr.CallTool(context.Background(), "session-1", "mock:echo", map[string]interface{}{"message": "hi"})
+	var toolErr *ToolError
+	if assert.ErrorAs(t, err, &toolErr) {
+		assert.Equal(t, ToolErrPermissionDenied, toolErr.Code)
+		assert.Equal(t, "approval timed out", toolErr.Message)
+	}
+	assert.Empty(t, mgr.PendingApprovals())
+
+	select {
+	case evt := <-events:
+		payload := evt.Payload.(map[string]interface{})
+		assert.Equal(t, false, payload["approved"])
+		assert.Equal(t, true, payload["expired"])
+	case <-time.After(time.Second):
+		t.Fatal("expected an approval.resolved event on expiry")
+	}
+}
+
+func TestManager_DenyPendingApprovals(t *testing.T) {
+	b := bus.New()
+	events, unsubscribe := b.Subscribe(bus.EventApprovalNeeded)
+	defer unsubscribe()
+
+	p := policy.NewDefaultPolicy()
+	p.Rules = append(p.Rules, policy.Rule{Tool: "mcp.mock.echo", Decision: policy.DecisionAsk})
+	mgr := NewManager(b, policy.NewEngine(p), nil)
+	mgr.clients["mock"] = NewClient(NewMockTransport(NewMockServer()), "")
+
+	errCh := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := mgr.CallTool(context.Background(), "session-1", "mock:echo", map[string]interface{}{"message": "hi"})
+			errCh <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-events:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected an approval request")
+		}
+	}
+	pending := mgr.PendingApprovals()
+	if assert.Len(t, pending, 2) {
+		assert.GreaterOrEqual(t, pending[0].AgeMS, pending[1].AgeMS)
+	}
+
+	assert.Equal(t, 2, mgr.DenyPendingApprovals())
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errCh:
+			assert.ErrorContains(t, err, "denied by user")
+		case <-time.After(2 * time.Second):
+			t.Fatal("CallTool did not return after the approvals were cleared")
+		}
+	}
+	assert.Empty(t, mgr.PendingApprovals())
+}
+
+func TestApprovalSummary(t *testing.T) {
+	got := approvalSummary(Requester{Kind: "api", Name: "An API client"}, "shell", "exec", "exec", map[string]interface{}{
+		"a": 1, "b": "two", "c": true, "d": nil,
+	})
+	assert.Equal(t, `An API client wants to run shell:exec (exec risk) with a=1, b="two", c=true, +1 more`, got)
+}
+
+func TestManager_ResourcesAndPrompts(t *testing.T) {
+	docs := NewMockServer()
+	docs.AddResource(Resource{URI: "file:///b.md", Name: "b.md", MimeType: "text/markdown"}, "# B")
+	docs.AddResource(Resource{URI: "file:///a.md", Name: "a.md", MimeType: "text/markdown"}, "# A")
+	docs.AddPrompt(Prompt{Name: "summarize", Arguments: []PromptArgument{{Name: "text", Required: true}}})
+
+	mgr := NewManager(bus.New(), nil, nil)
+	mgr.clients["docs"] = NewClient(NewMockTransport(docs), "")
+	mgr.clients["plain"] = NewClient(NewMockTransport(NewMockServer()), "")
+
+	resources, err := mgr.ListResources(context.Background(), false)
+	assert.NoError(t, err)
+	if assert.Len(t, resources, 2) {
+		assert.Equal(t, "file:///a.md", resources[0].URI)
+		assert.Equal(t, "docs", resources[0].Server)
+	}
+
+	prompts, err := mgr.ListPrompts(context.Background(), false)
+	assert.NoError(t, err)
+	if assert.Len(t, prompts, 1) {
+		assert.Equal(t, "summarize", prompts[0].Name)
+		assert.Equal(t, "docs", prompts[0].Server)
+		assert.True(t, prompts[0].Arguments[0].Required)
+	}
+
+	// Served from cache until refreshed
+	docs.AddResource(Resource{URI: "file:///c.md", Name: "c.md"}, "# C")
+	resources, _ = mgr.ListResources(context.Background(), false)
+	assert.Len(t, resources, 2)
+	resources, _ = mgr.ListResources(context.Background(), true)
+	assert.Len(t, resources, 3)
+
+	contents, err := mgr.ReadResource(context.Background(), "", "file:///a.md")
+	assert.NoError(t, err)
+	if assert.Len(t, contents, 1) {
+		assert.Equal(t, "# A", contents[0].Text)
+		assert.Equal(t, "text/markdown", contents[0].MimeType)
+	}
+
+	_, err = mgr.ReadResource(context.Background(), "", "file:///missing.md")
+	assert.ErrorIs(t, err, ErrResourceNotFound)
+	_, err = mgr.ReadResource(context.Background(), "nope", "file:///a.md")
+	assert.Error(t, err)
+}
+
+func TestManager_CallTool_ToolScope(t *testing.T) {
+	server := NewMockServer()
+	var calls int
+	server.CallToolFunc = func(ctx context.Context, name string, args map[string]interface{}) (ToolResult, error) {
+		calls++
+		return ToolResult{Content: []ToolContent{{Type: "text", Text: "ok"}}}, nil
+	}
+
+	b := bus.New()
+	denied, unsubscribe := b.Subscribe(bus.EventToolDenied)
+	defer unsubscribe()
+
+	p := policy.NewEngine(&policy.Policy{Default: policy.DecisionAllow})
+	p.SetToolScopes(map[string]policy.ToolScope{
+		"session-1":   {Allow: []string{"filesystem.*"}},
+		"channel:ops": {Deny: []string{"shell.*"}},
+	})
+	mgr := NewManager(b, p, nil)
+	mgr.clients["filesystem"] = NewClient(NewMockTransport(server), "")
+	mgr.clients["shell"] = NewClient(NewMockTransport(server), "")
+
+	_, err := mgr.CallTool(context.Background(), "session-1", "filesystem:echo", nil)
+	assert.NoError(t, err)
+
+	_, err = mgr.CallTool(context.Background(), "session-1", "shell:echo", nil)
+	var toolErr *ToolError
+	if !assert.ErrorAs(t, err, &toolErr) {
+		return
+	}
+	assert.Equal(t, ToolErrPermissionDenied, toolErr.Code)
+	select {
+	case evt := <-denied:
+		assert.Equal(t, "session-1", evt.SessionID)
+		assert.Equal(t, "mcp.shell.echo", evt.Payload.(map[string]interface{})["tool"])
+	case <-time.After(time.Second):
+		t.Fatal("expected a tool.denied event")
+	}
+
+	ctx := WithChannel(context.Background(), "ops:42")
+	_, err = mgr.CallTool(ctx, "session-2", "shell:echo", nil)
+	assert.ErrorAs(t, err, &toolErr)
+	_, err = mgr.CallTool(context.Background(), "session-2", "shell:echo", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
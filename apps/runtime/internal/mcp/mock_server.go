@@ -13,6 +13,9 @@ import (
 type MockServer struct {
 	mu           sync.RWMutex
 	tools        []Tool
+	resources    map[string]ResourceContents
+	resourceList []Resource
+	prompts      []Prompt
 	initialized  atomic.Bool
 	callCount    map[string]int
 	lastCallArgs map[string]map[string]interface{}
@@ -57,6 +60,32 @@ func (m *MockServer) HandleRequest(ctx context.Context, req RPCRequest) RPCRespo
 		return m.handleListTools(ctx, req)
 	case "tools/call":
 		return m.handleCallTool(ctx, req)
+	case "resources/list":
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return mockResult(req, ListResourcesResult{Resources: m.resourceList})
+	case "resources/read":
+		var params struct {
+			URI string `json:"uri"`
+		}
+		if b, err := json.Marshal(req.Params); err == nil {
+			_ = json.Unmarshal(b, &params)
+		}
+		m.mu.RLock()
+		contents, ok := m.resources[params.URI]
+		m.mu.RUnlock()
+		if !ok {
+			return RPCResponse{
+				JSONRPC: "2.0",
+				ID:      mustMarshalID(req.ID),
+				Error:   &RPCError{Code: -32002, Message: "resource not found: " + params.URI},
+			}
+		}
+		return mockResult(req, ReadResourceResult{Contents: []ResourceContents{contents}})
+	case "prompts/list":
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return mockResult(req, ListPromptsResult{Prompts: m.prompts})
 	case "ping":
 		return m.handlePing(ctx, req)
 	default:
@@ -84,13 +113,23 @@ func (m *MockServer) defaultInitialize(ctx context.Context, req RPCRequest) RPCR
 		_ = json.Unmarshal(b, &params)
 	}
 
+	capabilities := map[string]interface{}{
+		"tools": map[string]interface{}{
+			"listChanged": true,
+		},
+	}
+	m.mu.RLock()
+	if len(m.resourceList) > 0 {
+		capabilities["resources"] = map[string]interface{}{}
+	}
+	if len(m.prompts) > 0 {
+		capabilities["prompts"] = map[string]interface{}{}
+	}
+	m.mu.RUnlock()
+
 	result := map[string]interface{}{
 		"protocolVersion": "2024-11-05",
-		"capabilities": map[string]interface{}{
-			"tools": map[string]interface{}{
-				"listChanged": true,
-			},
-		},
+		"capabilities":    capabilities,
 		"serverInfo": map[string]interface{}{
 			"name":    "mock-mcp-server",
 			"version": "1.0.0",
@@ -210,6 +249,29 @@ func (m *MockServer) AddTool(tool Tool) {
 	m.tools = append(m.tools, tool)
 }
 
+// AddResource makes the server advertise resources and serve text at res.URI.
+func (m *MockServer) AddResource(res Resource, text string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.resources == nil {
+		m.resources = map[string]ResourceContents{}
+	}
+	m.resourceList = append(m.resourceList, res)
+	m.resources[res.URI] = ResourceContents{URI: res.URI, MimeType: res.MimeType, Text: text}
+}
+
+// AddPrompt makes the server advertise prompts, including p.
+func (m *MockServer) AddPrompt(p Prompt) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prompts = append(m.prompts, p)
+}
+
+func mockResult(req RPCRequest, result interface{}) RPCResponse {
+	b, _ := json.Marshal(result)
+	return RPCResponse{JSONRPC: "2.0", ID: mustMarshalID(req.ID), Result: b}
+}
+
 func (m *MockServer) GetCallCount(tool string) int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
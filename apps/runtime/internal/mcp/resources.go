@@ -0,0 +1,269 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Resource is a piece of context, such as a file, that a server offers to read.
+// Server is set by the Manager when it aggregates several servers.
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Server      string `json:"server,omitempty"`
+}
+
+type ListResourcesResult struct {
+	Resources  []Resource `json:"resources"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+// ResourceContents is the content of a resource; Text for text resources,
+// base64 Blob for binary ones.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+type ReadResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
+
+// Prompt is a reusable prompt template a server offers.
+type Prompt struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	Server      string           `json:"server,omitempty"`
+}
+
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+type ListPromptsResult struct {
+	Prompts    []Prompt `json:"prompts"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// ErrResourceNotFound is returned by ReadResource when no connected server
+// lists the requested URI.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// HasCapability reports whether the server advertised capability (e.g.
+// "resources" or "prompts") when the client initialized.
+func (c *Client) HasCapability(capability string) bool {
+	c.mu.RLock()
+	raw := c.serverCapabilities
+	c.mu.RUnlock()
+	var caps map[string]json.RawMessage
+	if json.Unmarshal(raw, &caps) != nil {
+		return false
+	}
+	_, ok := caps[capability]
+	return ok
+}
+
+func (c *Client) ListResources(ctx context.Context) ([]Resource, error) {
+	if err := c.Initialize(ctx); err != nil {
+		return nil, err
+	}
+
+	var all []Resource
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var out ListResourcesResult
+		if err := c.call(ctx, "resources/list", params, &out); err != nil {
+			return nil, err
+		}
+		all = append(all, out.Resources...)
+		if out.NextCursor == "" {
+			break
+		}
+		cursor = out.NextCursor
+	}
+	return all, nil
+}
+
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	if err := c.Initialize(ctx); err != nil {
+		return nil, err
+	}
+	var out ReadResourceResult
+	if err := c.call(ctx, "resources/read", map[string]interface{}{"uri": uri}, &out); err != nil {
+		return nil, err
+	}
+	return out.Contents, nil
+}
+
+func (c *Client) ListPrompts(ctx context.Context) ([]Prompt, error) {
+	if err := c.Initialize(ctx); err != nil {
+		return nil, err
+	}
+
+	var all []Prompt
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var out ListPromptsResult
+		if err := c.call(ctx, "prompts/list", params, &out); err != nil {
+			return nil, err
+		}
+		all = append(all, out.Prompts...)
+		if out.NextCursor == "" {
+			break
+		}
+		cursor = out.NextCursor
+	}
+	return all, nil
+}
+
+type cachedList[T any] struct {
+	fetchedAt time.Time
+	items     []T
+}
+
+// ListResources returns the resources of every connected server that
+// advertises them, sorted by server and URI. Lists are cached like tools;
+// refresh bypasses the cache.
+func (m *Manager) ListResources(ctx context.Context, refresh bool) ([]Resource, error) {
+	all := []Resource{}
+	for name, c := range m.clientsSnapshot() {
+		if err := c.Initialize(ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if !c.HasCapability("resources") {
+			continue
+		}
+		resources, err := listCached(m, m.resourceCache, name, refresh, func() ([]Resource, error) {
+			return c.ListResources(ctx)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, r := range resources {
+			r.Server = name
+			all = append(all, r)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Server != all[j].Server {
+			return all[i].Server < all[j].Server
+		}
+		return all[i].URI < all[j].URI
+	})
+	return all, nil
+}
+
+// ListPrompts returns the prompts of every connected server that advertises
+// them, sorted by server and name, cached like ListResources.
+func (m *Manager) ListPrompts(ctx context.Context, refresh bool) ([]Prompt, error) {
+	all := []Prompt{}
+	for name, c := range m.clientsSnapshot() {
+		if err := c.Initialize(ctx); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if !c.HasCapability("prompts") {
+			continue
+		}
+		prompts, err := listCached(m, m.promptCache, name, refresh, func() ([]Prompt, error) {
+			return c.ListPrompts(ctx)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, p := range prompts {
+			p.Server = name
+			all = append(all, p)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Server != all[j].Server {
+			return all[i].Server < all[j].Server
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all, nil
+}
+
+// ReadResource fetches the resource at uri from server. With no server it
+// reads from the server that lists uri, returning ErrResourceNotFound when
+// none does.
+func (m *Manager) ReadResource(ctx context.Context, server, uri string) ([]ResourceContents, error) {
+	if server == "" {
+		resources, err := m.ListResources(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resources {
+			if r.URI == uri {
+				server = r.Server
+				break
+			}
+		}
+		if server == "" {
+			return nil, fmt.Errorf("%w: %s", ErrResourceNotFound, uri)
+		}
+	}
+
+	m.mu.RLock()
+	client := m.clients[server]
+	m.mu.RUnlock()
+	if client == nil {
+		return nil, newToolError(ToolErrNotFound, fmt.Sprintf("unknown mcp server: %s", server))
+	}
+	return client.ReadResource(ctx, uri)
+}
+
+func (m *Manager) clientsSnapshot() map[string]*Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	clients := make(map[string]*Client, len(m.clients))
+	for k, v := range m.clients {
+		clients[k] = v
+	}
+	return clients
+}
+
+// listCached returns the cached list for server from cache, fetching it when
+// it is missing, stale or refresh is set.
+func listCached[T any](m *Manager, cache map[string]cachedList[T], server string, refresh bool, fetch func() ([]T, error)) ([]T, error) {
+	if !refresh {
+		m.cacheMu.RLock()
+		item, ok := cache[server]
+		m.cacheMu.RUnlock()
+		if ok && time.Since(item.fetchedAt) < listCacheTTL {
+			return item.items, nil
+		}
+	}
+
+	items, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	m.cacheMu.Lock()
+	cache[server] = cachedList[T]{fetchedAt: time.Now().UTC(), items: items}
+	m.cacheMu.Unlock()
+	return items, nil
+}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrServerNotAllowed is returned when an MCP server is rejected by the server policy.
+var ErrServerNotAllowed = errors.New("mcp server not allowed")
+
+// ServerPolicy decides which MCP servers may be added. Patterns are matched
+// case-insensitively against the server name and its URL or command; * matches
+// any run of characters and ? a single one.
+type ServerPolicy struct {
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+}
+
+// NewServerPolicy compiles allow and deny patterns. Blank patterns are ignored.
+func NewServerPolicy(allow, deny []string) *ServerPolicy {
+	return &ServerPolicy{allow: compilePatterns(allow), deny: compilePatterns(deny)}
+}
+
+// Check returns an error wrapping ErrServerNotAllowed if the server may not be added.
+// A nil policy allows everything.
+func (p *ServerPolicy) Check(name string, sc ServerConfig) error {
+	if p == nil {
+		return nil
+	}
+	candidates := []string{name}
+	if target := serverTarget(sc); target != "" {
+		candidates = append(candidates, target)
+	}
+
+	for _, re := range p.deny {
+		for _, c := range candidates {
+			if re.MatchString(c) {
+				return fmt.Errorf("%w: %q is blocked by the administrator's deny list", ErrServerNotAllowed, c)
+			}
+		}
+	}
+	if len(p.allow) == 0 {
+		return nil
+	}
+	for _, re := range p.allow {
+		for _, c := range candidates {
+			if re.MatchString(c) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %q is not on the administrator's allow list", ErrServerNotAllowed, name)
+}
+
+// serverTarget returns the URL or command line that identifies where sc connects.
+func serverTarget(sc ServerConfig) string {
+	if strings.TrimSpace(sc.URL) != "" {
+		return strings.TrimSpace(sc.URL)
+	}
+	return strings.TrimSpace(strings.Join(sc.Command, " "))
+}
+
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	var out []*regexp.Regexp
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		expr := regexp.QuoteMeta(p)
+		expr = strings.ReplaceAll(expr, `\*`, `.*`)
+		expr = strings.ReplaceAll(expr, `\?`, `.`)
+		out = append(out, regexp.MustCompile(`(?i)^`+expr+`$`))
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestServerPolicy_Check(t *testing.T) {
+	policy := NewServerPolicy(
+		[]string{"https://*.corp.example.com/*", "filesystem"},
+		[]string{"*evil*", "npx *"},
+	)
+
+	tests := []struct {
+		name    string
+		server  string
+		sc      ServerConfig
+		allowed bool
+	}{
+		{"allowed by url", "tickets", ServerConfig{URL: "https://mcp.corp.example.com/v1"}, true},
+		{"allowed by name", "filesystem", ServerConfig{Command: []string{"pryx-core", "mcp", "filesystem"}}, true},
+		{"name case-insensitive", "FileSystem", ServerConfig{}, true},
+		{"not on allow list", "weather", ServerConfig{URL: "https://weather.example.org"}, false},
+		{"deny by name wins", "evil-corp", ServerConfig{URL: "https://mcp.corp.example.com/v1"}, false},
+		{"deny by command", "filesystem", ServerConfig{Command: []string{"npx", "some-server"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Check(tt.server, tt.sc)
+			if tt.allowed && err != nil {
+				t.Fatalf("expected allowed, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrServerNotAllowed) {
+				t.Fatalf("expected ErrServerNotAllowed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestServerPolicy_Unrestricted(t *testing.T) {
+	var nilPolicy *ServerPolicy
+	if err := nilPolicy.Check("anything", ServerConfig{}); err != nil {
+		t.Fatalf("nil policy should allow: %v", err)
+	}
+	if err := NewServerPolicy(nil, []string{" "}).Check("anything", ServerConfig{URL: "http://x"}); err != nil {
+		t.Fatalf("empty policy should allow: %v", err)
+	}
+}
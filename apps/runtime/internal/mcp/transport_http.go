@@ -57,7 +57,7 @@ func (t *HTTPTransport) Call(ctx context.Context, req RPCRequest) (RPCResponse,
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return RPCResponse{}, errors.New(strings.TrimSpace(string(b)))
+		return RPCResponse{}, &HTTPStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(b))}
 	}
 
 	ct := strings.ToLower(resp.Header.Get("Content-Type"))
@@ -105,7 +105,7 @@ func (t *HTTPTransport) Notify(ctx context.Context, notif RPCNotification) error
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return errors.New(strings.TrimSpace(string(b)))
+		return &HTTPStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(b))}
 	}
 	return nil
 }
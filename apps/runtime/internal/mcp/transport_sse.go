@@ -177,7 +177,7 @@ func (t *SSETransport) Call(ctx context.Context, req RPCRequest) (RPCResponse, e
 		t.mu.Lock()
 		delete(t.pending, key)
 		t.mu.Unlock()
-		return RPCResponse{}, fmt.Errorf("request failed: %d", resp.StatusCode)
+		return RPCResponse{}, &HTTPStatusError{StatusCode: resp.StatusCode}
 	}
 
 	select {
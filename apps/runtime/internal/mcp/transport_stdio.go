@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
+	"sort"
 	"sync"
 )
 
@@ -53,13 +55,7 @@ func (t *StdioTransport) Start(ctx context.Context) error {
 		if t.cwd != "" {
 			t.cmd.Dir = t.cwd
 		}
-		if len(t.env) > 0 {
-			var out []string
-			for k, v := range t.env {
-				out = append(out, fmt.Sprintf("%s=%s", k, v))
-			}
-			t.cmd.Env = append(t.cmd.Environ(), out...)
-		}
+		t.cmd.Env = stdioEnvironment(t.env)
 
 		stdout, err := t.cmd.StdoutPipe()
 		if err != nil {
@@ -89,6 +85,40 @@ func (t *StdioTransport) Start(ctx context.Context) error {
 	return t.startErr
 }
 
+// stdioBaseEnv lists the runtime environment variables every stdio server
+// gets: enough to find binaries, a home and a temp directory, and nothing that
+// is likely to hold credentials.
+var stdioBaseEnv = []string{
+	"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TMPDIR", "TEMP", "TMP",
+	"LANG", "LC_ALL", "LC_CTYPE", "TZ", "TERM",
+	// Windows
+	"SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT",
+	"USERPROFILE", "APPDATA", "LOCALAPPDATA", "PROGRAMDATA", "PROGRAMFILES",
+}
+
+// stdioEnvironment returns the environment of a stdio server: the variables
+// in stdioBaseEnv that are set in the runtime, overridden by env.
+func stdioEnvironment(env map[string]string) []string {
+	out := make([]string, 0, len(stdioBaseEnv)+len(env))
+	for _, k := range stdioBaseEnv {
+		if _, ok := env[k]; ok {
+			continue
+		}
+		if v, ok := os.LookupEnv(k); ok {
+			out = append(out, k+"="+v)
+		}
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		out = append(out, fmt.Sprintf("%s=%s", k, env[k]))
+	}
+	return out
+}
+
 func (t *StdioTransport) Close() error {
 	t.mu.Lock()
 	if t.closed {
@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -73,3 +75,73 @@ func TestMCPHelperProcess(t *testing.T) {
 	}
 	os.Exit(0)
 }
+
+func TestStdioEnvironment(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("HOME", "/home/pryx")
+	t.Setenv("OPENAI_API_KEY", "sk-secret")
+	t.Setenv("PRYX_EXTRA", "extra")
+
+	sc := ServerConfig{
+		Env:        map[string]string{"HOME": "/srv/mcp", "MCP_MODE": "strict"},
+		InheritEnv: []string{"PRYX_EXTRA", "PRYX_UNSET"},
+	}
+	env := map[string]string{}
+	for _, kv := range stdioEnvironment(stdioEnvOverrides(sc)) {
+		k, v, _ := strings.Cut(kv, "=")
+		if _, dup := env[k]; dup {
+			t.Fatalf("duplicate variable %s", k)
+		}
+		env[k] = v
+	}
+
+	if env["PATH"] != "/usr/bin" {
+		t.Errorf("PATH = %q, want it passed through", env["PATH"])
+	}
+	if env["HOME"] != "/srv/mcp" || env["MCP_MODE"] != "strict" {
+		t.Errorf("Env overrides not applied: %v", env)
+	}
+	if env["PRYX_EXTRA"] != "extra" {
+		t.Errorf("PRYX_EXTRA = %q, want it inherited", env["PRYX_EXTRA"])
+	}
+	if _, ok := env["PRYX_UNSET"]; ok {
+		t.Error("unset inherited variable should be left out")
+	}
+	if _, ok := env["OPENAI_API_KEY"]; ok {
+		t.Error("runtime secrets must not be passed to stdio servers")
+	}
+}
+
+func TestStdioWorkingDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := stdioWorkingDir(ServerConfig{}); err != nil || got != "" {
+		t.Errorf("empty working_dir = %q, %v; want runtime cwd", got, err)
+	}
+	if got, err := stdioWorkingDir(ServerConfig{WorkingDir: dir}); err != nil || got != dir {
+		t.Errorf("working_dir = %q, %v; want %q", got, err, dir)
+	}
+	if got, err := stdioWorkingDir(ServerConfig{Cwd: dir}); err != nil || got != dir {
+		t.Errorf("legacy cwd = %q, %v; want %q", got, err, dir)
+	}
+	if _, err := stdioWorkingDir(ServerConfig{WorkingDir: filepath.Join(dir, "missing")}); err == nil {
+		t.Error("expected error for missing working_dir")
+	}
+	if _, err := stdioWorkingDir(ServerConfig{WorkingDir: file}); err == nil {
+		t.Error("expected error for working_dir that is a file")
+	}
+
+	m := NewManager(nil, nil, nil)
+	_, err := m.Probe(context.Background(), "x", ServerConfig{
+		Transport:  "stdio",
+		Command:    []string{"true"},
+		WorkingDir: filepath.Join(dir, "missing"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "working_dir") {
+		t.Errorf("Probe error = %v, want working_dir error", err)
+	}
+}
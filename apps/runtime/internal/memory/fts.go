@@ -207,17 +207,37 @@ func (fts *FTSSearch) searchWithFilterFallback(query string, opts SearchOptions)
 	return fts.scanResults(rows)
 }
 
-// Insert adds a new entry to the FTS index
-func (fts *FTSSearch) Insert(entryID string, content string) error {
-	// FTS5 virtual table is automatically populated via content_rowid
-	// when we insert into memory_entries
-	return nil
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
 }
 
-// Delete removes an entry from the FTS index
-func (fts *FTSSearch) Delete(entryID string) error {
-	// FTS5 is automatically maintained via foreign key
-	return nil
+// Insert adds an entry to the FTS index. It is a no-op when the FTS5 table
+// does not exist, in which case searches fall back to LIKE.
+func (fts *FTSSearch) Insert(db execer, entryID string, content string) error {
+	if !ftsAvailable(db) {
+		return nil
+	}
+	_, err := db.Exec(`INSERT INTO memory_fts(rowid, content) SELECT rowid, ? FROM memory_entries WHERE id = ?`, content, entryID)
+	return err
+}
+
+// Delete removes an entry from the FTS index, so searches stop returning it.
+// It must run before the entry itself is deleted.
+func (fts *FTSSearch) Delete(db execer, entryID string) error {
+	if !ftsAvailable(db) {
+		return nil
+	}
+	_, err := db.Exec(`DELETE FROM memory_fts WHERE rowid IN (SELECT rowid FROM memory_entries WHERE id = ?)`, entryID)
+	return err
+}
+
+// ftsAvailable reports whether the memory_fts table exists.
+func ftsAvailable(db execer) bool {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'memory_fts'`).Scan(&n)
+	return err == nil && n > 0
 }
 
 // Rebuild rebuilds the FTS index (useful for maintenance)
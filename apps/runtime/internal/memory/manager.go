@@ -1,19 +1,32 @@
 package memory
 
 import (
+	"context"
 	"database/sql"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrEntryNotFound is returned for memory entry IDs that do not exist.
+var ErrEntryNotFound = errors.New("memory entry not found")
+
+// Embedder computes the vector embedding of memory content for vector search.
+type Embedder interface {
+	Embed(ctx context.Context, content string) ([]float32, error)
+}
+
 // RAGManager manages the RAG memory system
 type RAGManager struct {
-	db      *sql.DB
-	enabled bool
-	fts     *FTSSearch
-	flush   *AutoFlush
+	db       *sql.DB
+	enabled  bool
+	fts      *FTSSearch
+	flush    *AutoFlush
+	embedder Embedder
 }
 
 // NewRAGManager creates a new RAG memory manager
@@ -29,6 +42,12 @@ func NewRAGManager(db *sql.DB, enabled bool) *RAGManager {
 	return m
 }
 
+// SetEmbedder sets the embedder used to index entries for vector search. Without
+// one, entries have no embedding.
+func (m *RAGManager) SetEmbedder(e Embedder) {
+	m.embedder = e
+}
+
 // WriteDaily writes to the daily log (append-only)
 func (m *RAGManager) WriteDaily(content string, sources []MemorySource) (string, error) {
 	if !m.enabled {
@@ -74,6 +93,13 @@ func (m *RAGManager) writeEntry(entryType MemoryType, content string, date strin
 		)
 	}
 
+	if err := m.fts.Insert(tx, entryID, content); err != nil {
+		return "", fmt.Errorf("failed to index memory entry: %w", err)
+	}
+	if err := m.embed(context.Background(), tx, entryID, content); err != nil {
+		return "", err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return "", fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -163,7 +189,7 @@ func (m *RAGManager) Get(entryID string) (*MemoryEntry, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("memory entry not found")
+		return nil, ErrEntryNotFound
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get memory entry: %w", err)
@@ -183,14 +209,103 @@ func (m *RAGManager) Get(entryID string) (*MemoryEntry, error) {
 	return &entry, nil
 }
 
-// Delete removes a memory entry
+// Delete removes a memory entry with its sources and its FTS and vector index
+// rows. It returns ErrEntryNotFound for unknown IDs.
 func (m *RAGManager) Delete(entryID string) error {
 	if !m.enabled {
 		return fmt.Errorf("memory system is disabled")
 	}
 
-	_, err := m.db.Exec("DELETE FROM memory_entries WHERE id = ?", entryID)
-	return err
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The index rows are keyed by the entry, so they go first.
+	if err := m.fts.Delete(tx, entryID); err != nil {
+		return fmt.Errorf("failed to unindex memory entry: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM memory_vectors WHERE entry_id = ?", entryID); err != nil {
+		return fmt.Errorf("failed to delete memory embedding: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM memory_sources WHERE entry_id = ?", entryID); err != nil {
+		return fmt.Errorf("failed to delete memory sources: %w", err)
+	}
+	res, err := tx.Exec("DELETE FROM memory_entries WHERE id = ?", entryID)
+	if err != nil {
+		return fmt.Errorf("failed to delete memory entry: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrEntryNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Update replaces the content of a memory entry, re-indexing it for full-text
+// search and, if an embedder is set, re-embedding it. A stale embedding is
+// dropped either way. It returns ErrEntryNotFound for unknown IDs.
+func (m *RAGManager) Update(ctx context.Context, entryID, content string) (*MemoryEntry, error) {
+	if !m.enabled {
+		return nil, fmt.Errorf("memory system is disabled")
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := m.fts.Delete(tx, entryID); err != nil {
+		return nil, fmt.Errorf("failed to unindex memory entry: %w", err)
+	}
+	res, err := tx.Exec(
+		"UPDATE memory_entries SET content = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?",
+		content, entryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update memory entry: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, ErrEntryNotFound
+	}
+	if err := m.fts.Insert(tx, entryID, content); err != nil {
+		return nil, fmt.Errorf("failed to index memory entry: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM memory_vectors WHERE entry_id = ?", entryID); err != nil {
+		return nil, fmt.Errorf("failed to delete memory embedding: %w", err)
+	}
+	if err := m.embed(ctx, tx, entryID, content); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return m.Get(entryID)
+}
+
+// embed stores the embedding of content for entryID when an embedder is set.
+func (m *RAGManager) embed(ctx context.Context, tx *sql.Tx, entryID, content string) error {
+	if m.embedder == nil {
+		return nil
+	}
+	vector, err := m.embedder.Embed(ctx, content)
+	if err != nil {
+		return fmt.Errorf("failed to embed memory entry: %w", err)
+	}
+	blob := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(v))
+	}
+	if _, err := tx.Exec(
+		"INSERT OR REPLACE INTO memory_vectors (entry_id, embedding) VALUES (?, ?)",
+		entryID, blob,
+	); err != nil {
+		return fmt.Errorf("failed to store memory embedding: %w", err)
+	}
+	return nil
 }
 
 // Stats returns statistics about the memory system
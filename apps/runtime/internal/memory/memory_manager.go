@@ -109,13 +109,20 @@ func (m *Manager) SummarizeSession(ctx context.Context, sessionID string) (*Comp
 		compressCount = 1
 	}
 
-	// Create summary of compressed messages
+	// Create summary of compressed messages; pinned messages are never compressed
 	totalTokens := 0
-	for i, msg := range messages {
-		if i < compressCount {
-			totalTokens += estimateTokens(msg.Content)
+	compressed := 0
+	for _, msg := range messages {
+		if compressed == compressCount {
+			break
 		}
+		if msg.Pinned {
+			continue
+		}
+		totalTokens += estimateTokens(msg.Content)
+		compressed++
 	}
+	compressCount = compressed
 
 	summary := fmt.Sprintf("Compressed %d messages (%d tokens)", compressCount, totalTokens)
 
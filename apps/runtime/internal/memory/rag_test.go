@@ -219,6 +219,94 @@ func TestRAGManager_Delete(t *testing.T) {
 	}
 }
 
+// fakeEmbedder embeds content as its length.
+type fakeEmbedder struct{ calls []string }
+
+func (e *fakeEmbedder) Embed(ctx context.Context, content string) ([]float32, error) {
+	e.calls = append(e.calls, content)
+	return []float32{float32(len(content))}, nil
+}
+
+func countRows(t *testing.T, db *sql.DB, table, entryID string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE entry_id = ?", entryID).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestRAGManager_DeleteRemovesIndexRows(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	mgr := NewRAGManager(db, true)
+	mgr.SetEmbedder(&fakeEmbedder{})
+
+	entryID, err := mgr.WriteLongterm("Uses tabs for indentation", []MemorySource{{SourceType: "file", SourcePath: "notes.md"}})
+	if err != nil {
+		t.Fatalf("WriteLongterm failed: %v", err)
+	}
+	if countRows(t, db, "memory_vectors", entryID) != 1 || countRows(t, db, "memory_sources", entryID) != 1 {
+		t.Fatal("Expected an embedding and a source for the new entry")
+	}
+
+	if err := mgr.Delete(entryID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if countRows(t, db, "memory_vectors", entryID) != 0 || countRows(t, db, "memory_sources", entryID) != 0 {
+		t.Error("Expected the embedding and sources to be deleted with the entry")
+	}
+	results, err := mgr.Search(context.Background(), "indentation", SearchOptions{IncludeFTS: true, Limit: 10})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results for a deleted entry, got %d", len(results))
+	}
+
+	if err := mgr.Delete(entryID); err != ErrEntryNotFound {
+		t.Errorf("Expected ErrEntryNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestRAGManager_Update(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	mgr := NewRAGManager(db, true)
+	entryID, _ := mgr.WriteLongterm("Prefers spaces", nil)
+
+	embedder := &fakeEmbedder{}
+	mgr.SetEmbedder(embedder)
+	entry, err := mgr.Update(context.Background(), entryID, "Prefers tabs")
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if entry.Content != "Prefers tabs" || entry.ID != entryID {
+		t.Errorf("Unexpected updated entry: %+v", entry)
+	}
+	if len(embedder.calls) != 1 || embedder.calls[0] != "Prefers tabs" {
+		t.Errorf("Expected the new content to be embedded, got %v", embedder.calls)
+	}
+	if countRows(t, db, "memory_vectors", entryID) != 1 {
+		t.Error("Expected the entry to have its new embedding")
+	}
+
+	results, _ := mgr.Search(context.Background(), "spaces", SearchOptions{IncludeFTS: true, Limit: 10})
+	if len(results) != 0 {
+		t.Errorf("Expected the old content not to match, got %d results", len(results))
+	}
+	results, _ = mgr.Search(context.Background(), "tabs", SearchOptions{IncludeFTS: true, Limit: 10})
+	if len(results) != 1 {
+		t.Errorf("Expected the new content to match, got %d results", len(results))
+	}
+
+	if _, err := mgr.Update(context.Background(), "missing", "x"); err != ErrEntryNotFound {
+		t.Errorf("Expected ErrEntryNotFound, got %v", err)
+	}
+}
+
 func TestRAGManager_Disabled(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownModel is returned when a model is not in the catalog for the provider.
+var ErrUnknownModel = errors.New("unknown model")
+
+// ErrReasoningUnsupported is returned when a reasoning parameter is requested
+// for a model the catalog does not list as a reasoning model.
+var ErrReasoningUnsupported = errors.New("model does not support reasoning effort")
+
+// Sources a resolved model can come from, highest precedence first.
+const (
+	SourceRequest = "request"
+	SourceSession = "session"
+	SourceChannel = "channel"
+	SourceTask    = "task"
+	SourceDefault = "default"
+)
+
+// Selection holds the candidate models for one request. Empty fields are skipped.
+type Selection struct {
+	Request string
+	Session string
+	// Scope is the channel or scheduled task model; ScopeSource says which.
+	Scope       string
+	ScopeSource string
+	Default     string
+}
+
+// Resolve picks the model by precedence request > session > channel/task > default
+// and reports where it came from.
+func (s Selection) Resolve() (model string, source string) {
+	if m := strings.TrimSpace(s.Request); m != "" {
+		return m, SourceRequest
+	}
+	if m := strings.TrimSpace(s.Session); m != "" {
+		return m, SourceSession
+	}
+	if m := strings.TrimSpace(s.Scope); m != "" {
+		source := s.ScopeSource
+		if source == "" {
+			source = SourceChannel
+		}
+		return m, source
+	}
+	return strings.TrimSpace(s.Default), SourceDefault
+}
+
+// ValidateModel checks that modelID is a catalog model usable with provider.
+// A nil or empty catalog cannot validate anything and accepts every model, as
+// does the ollama provider, whose models are local and not catalogued.
+func (c *Catalog) ValidateModel(provider, modelID string) error {
+	modelID = strings.TrimSpace(modelID)
+	if modelID == "" {
+		return fmt.Errorf("%w: empty model id", ErrUnknownModel)
+	}
+	if c == nil || len(c.Models) == 0 || strings.EqualFold(provider, "ollama") {
+		return nil
+	}
+	model, ok := c.GetModel(modelID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownModel, modelID)
+	}
+	if provider != "" && model.Provider != "" && !strings.EqualFold(model.Provider, provider) {
+		return fmt.Errorf("%w: %s belongs to provider %s, not %s", ErrUnknownModel, modelID, model.Provider, provider)
+	}
+	return nil
+}
+
+// ValidateReasoning checks that modelID is a reasoning model, so it accepts a
+// reasoning effort. Like ValidateModel, a nil or empty catalog accepts every
+// model.
+func (c *Catalog) ValidateReasoning(modelID string) error {
+	if c == nil || len(c.Models) == 0 {
+		return nil
+	}
+	model, ok := c.GetModel(strings.TrimSpace(modelID))
+	if !ok || !model.Reasoning {
+		return fmt.Errorf("%w: %s", ErrReasoningUnsupported, modelID)
+	}
+	return nil
+}
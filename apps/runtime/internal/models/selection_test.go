@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSelection_Resolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		sel        Selection
+		wantModel  string
+		wantSource string
+	}{
+		{"default only", Selection{Default: "gpt-4o"}, "gpt-4o", SourceDefault},
+		{"channel beats default", Selection{Scope: "gpt-4o-mini", ScopeSource: SourceChannel, Default: "gpt-4o"}, "gpt-4o-mini", SourceChannel},
+		{"task scope", Selection{Scope: "gpt-4o-mini", ScopeSource: SourceTask, Default: "gpt-4o"}, "gpt-4o-mini", SourceTask},
+		{"session beats scope", Selection{Session: "o3", Scope: "gpt-4o-mini", Default: "gpt-4o"}, "o3", SourceSession},
+		{"request beats all", Selection{Request: "o1", Session: "o3", Scope: "gpt-4o-mini", Default: "gpt-4o"}, "o1", SourceRequest},
+		{"blank values skipped", Selection{Request: "  ", Session: "", Default: "gpt-4o"}, "gpt-4o", SourceDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, source := tt.sel.Resolve()
+			if model != tt.wantModel || source != tt.wantSource {
+				t.Errorf("Resolve() = %q/%q, want %q/%q", model, source, tt.wantModel, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestCatalog_ValidateModel(t *testing.T) {
+	catalog := &Catalog{
+		Models: map[string]ModelInfo{
+			"gpt-4o":          {ID: "gpt-4o", Provider: "openai"},
+			"claude-sonnet-4": {ID: "claude-sonnet-4", Provider: "anthropic"},
+		},
+	}
+
+	if err := catalog.ValidateModel("openai", "gpt-4o"); err != nil {
+		t.Errorf("Expected gpt-4o to be valid for openai, got %v", err)
+	}
+	if err := catalog.ValidateModel("openai", "nope"); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("Expected ErrUnknownModel for missing model, got %v", err)
+	}
+	if err := catalog.ValidateModel("openai", "claude-sonnet-4"); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("Expected ErrUnknownModel for another provider's model, got %v", err)
+	}
+	if err := catalog.ValidateModel("ollama", "llama3"); err != nil {
+		t.Errorf("Expected local ollama models to be accepted, got %v", err)
+	}
+
+	var empty *Catalog
+	if err := empty.ValidateModel("openai", "anything"); err != nil {
+		t.Errorf("Expected nil catalog to accept any model, got %v", err)
+	}
+}
+
+func TestCatalog_ValidateReasoning(t *testing.T) {
+	catalog := &Catalog{
+		Models: map[string]ModelInfo{
+			"o3":     {ID: "o3", Provider: "openai", Reasoning: true},
+			"gpt-4o": {ID: "gpt-4o", Provider: "openai"},
+		},
+	}
+
+	if err := catalog.ValidateReasoning("o3"); err != nil {
+		t.Errorf("Expected o3 to accept a reasoning effort, got %v", err)
+	}
+	if err := catalog.ValidateReasoning("gpt-4o"); !errors.Is(err, ErrReasoningUnsupported) {
+		t.Errorf("Expected ErrReasoningUnsupported for gpt-4o, got %v", err)
+	}
+	if err := catalog.ValidateReasoning("nope"); !errors.Is(err, ErrReasoningUnsupported) {
+		t.Errorf("Expected ErrReasoningUnsupported for an unknown model, got %v", err)
+	}
+
+	var empty *Catalog
+	if err := empty.ValidateReasoning("anything"); err != nil {
+		t.Errorf("Expected nil catalog to accept any model, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"pryx-core/internal/config"
+)
+
+// ParseDecision parses allow, ask or deny.
+func ParseDecision(s string) (Decision, error) {
+	switch d := Decision(strings.ToLower(strings.TrimSpace(s))); d {
+	case DecisionAllow, DecisionAsk, DecisionDeny:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown decision %q (want allow, ask or deny)", s)
+	}
+}
+
+// FromToolApprovals builds a policy from the configured per-tool approval
+// defaults. Exact patterns are tried before wildcards and longer patterns
+// before shorter ones; tools matching none are asked about. It returns an
+// error naming the pattern if a decision is invalid.
+func FromToolApprovals(approvals map[string]config.ToolApproval) (*Policy, error) {
+	p := NewDefaultPolicy()
+	for pattern, a := range approvals {
+		d, err := ParseDecision(a.Decision)
+		if err != nil {
+			return nil, fmt.Errorf("tool_approvals[%s]: %w", pattern, err)
+		}
+		p.Rules = append(p.Rules, Rule{
+			ID:          "tool_approvals:" + pattern,
+			Description: "Configured default for " + pattern,
+			Tool:        pattern,
+			Decision:    d,
+			Risk:        strings.ToLower(strings.TrimSpace(a.Risk)),
+		})
+	}
+	sort.Slice(p.Rules, func(i, j int) bool {
+		a, b := p.Rules[i].Tool, p.Rules[j].Tool
+		if wa, wb := isWildcard(a), isWildcard(b); wa != wb {
+			return !wa
+		}
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return a < b
+	})
+	return p, nil
+}
+
+func isWildcard(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[]()|+^$")
+}
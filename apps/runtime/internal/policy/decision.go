@@ -13,6 +13,7 @@ const (
 type Result struct {
 	Decision Decision `json:"decision"`
 	Reason   string   `json:"reason,omitempty"`
+	Risk     string   `json:"risk,omitempty"`
 }
 
 func Allow(reason string) Result {
@@ -11,6 +11,7 @@ import (
 type Engine struct {
 	mu     sync.RWMutex
 	policy *Policy
+	scopes map[string]ToolScope
 }
 
 func NewEngine(p *Policy) *Engine {
@@ -38,7 +39,7 @@ func (e *Engine) Evaluate(toolName string, args map[string]interface{}) Result {
 			if len(rule.Args) > 0 && !matchArgs(rule.Args, args) {
 				continue
 			}
-			return Result{Decision: rule.Decision, Reason: rule.Description}
+			return Result{Decision: rule.Decision, Reason: rule.Description, Risk: rule.Risk}
 		}
 	}
 
@@ -24,6 +24,7 @@ type Rule struct {
 	Scope       ScopeType    `json:"scope"`          // scope requirement
 	Args        []ArgMatcher `json:"args,omitempty"` // argument matchers
 	Decision    Decision     `json:"decision"`
+	Risk        string       `json:"risk,omitempty"` // risk level shown when asking, empty to derive it
 }
 
 // Policy is a collection of rules
@@ -2,6 +2,8 @@ package policy
 
 import (
 	"testing"
+
+	"pryx-core/internal/config"
 )
 
 func TestDefaultPolicy(t *testing.T) {
@@ -152,3 +154,90 @@ func TestCombinedScopeAndArgMatching(t *testing.T) {
 		t.Errorf("Expected Ask when scope doesn't match, got %v", res.Decision)
 	}
 }
+
+func TestFromToolApprovals(t *testing.T) {
+	p, err := FromToolApprovals(map[string]config.ToolApproval{
+		"mcp.shell.*":              {Decision: "deny"},
+		"mcp.shell.exec":           {Decision: "Ask", Risk: "exec"},
+		"mcp.filesystem.read_file": {Decision: "allow"},
+	})
+	if err != nil {
+		t.Fatalf("FromToolApprovals: %v", err)
+	}
+	engine := NewEngine(p)
+
+	tests := []struct {
+		tool     string
+		decision Decision
+		risk     string
+	}{
+		{"mcp.shell.exec", DecisionAsk, "exec"},
+		{"mcp.shell.kill", DecisionDeny, ""},
+		{"mcp.filesystem.read_file", DecisionAllow, ""},
+		{"mcp.filesystem.write_file", DecisionAsk, ""},
+	}
+	for _, tt := range tests {
+		res := engine.Evaluate(tt.tool, nil)
+		if res.Decision != tt.decision || res.Risk != tt.risk {
+			t.Errorf("Evaluate(%s) = %s/%q, want %s/%q", tt.tool, res.Decision, res.Risk, tt.decision, tt.risk)
+		}
+	}
+
+	if _, err := FromToolApprovals(map[string]config.ToolApproval{"mcp.x": {Decision: "maybe"}}); err == nil {
+		t.Error("expected an error for an unknown decision")
+	}
+}
+
+func TestEvaluateScope(t *testing.T) {
+	scopes, err := ToolScopesFromConfig(map[string]config.ToolScope{
+		"session-1":        {Allow: []string{"filesystem.*", "mcp.search.query"}, Deny: []string{"filesystem.delete_*"}},
+		"channel:telegram": {Deny: []string{"shell.*"}},
+	})
+	if err != nil {
+		t.Fatalf("ToolScopesFromConfig: %v", err)
+	}
+	engine := NewEngine(nil)
+	engine.SetToolScopes(scopes)
+
+	tests := []struct {
+		keys []string
+		tool string
+		want Decision
+	}{
+		{[]string{"session-1"}, "mcp.filesystem.read_file", DecisionAllow},
+		{[]string{"session-1"}, "mcp.search.query", DecisionAllow},
+		{[]string{"session-1"}, "mcp.filesystem.delete_file", DecisionDeny},
+		{[]string{"session-1"}, "mcp.shell.exec", DecisionDeny},
+		{[]string{"session-2"}, "mcp.shell.exec", DecisionAllow},
+		{[]string{"session-2", "channel:telegram"}, "mcp.shell.exec", DecisionDeny},
+		{[]string{"session-2", "channel:telegram"}, "mcp.filesystem.read_file", DecisionAllow},
+		{nil, "mcp.shell.exec", DecisionAllow},
+	}
+	for _, tt := range tests {
+		if res := engine.EvaluateScope(tt.keys, tt.tool); res.Decision != tt.want {
+			t.Errorf("EvaluateScope(%v, %s) = %v (%s), want %v", tt.keys, tt.tool, res.Decision, res.Reason, tt.want)
+		}
+	}
+
+	// The default scope covers calls no other scope matches
+	scopes[DefaultToolScope] = ToolScope{Allow: []string{"search.*"}}
+	engine.SetToolScopes(scopes)
+	for _, tt := range []struct {
+		keys []string
+		tool string
+		want Decision
+	}{
+		{nil, "mcp.shell.exec", DecisionDeny},
+		{[]string{"session-2"}, "mcp.search.query", DecisionAllow},
+		{[]string{"session-2"}, "mcp.filesystem.read_file", DecisionDeny},
+		{[]string{"session-1"}, "mcp.filesystem.read_file", DecisionAllow},
+	} {
+		if res := engine.EvaluateScope(tt.keys, tt.tool); res.Decision != tt.want {
+			t.Errorf("EvaluateScope(%v, %s) with a default = %v (%s), want %v", tt.keys, tt.tool, res.Decision, res.Reason, tt.want)
+		}
+	}
+
+	if _, err := ToolScopesFromConfig(map[string]config.ToolScope{"s": {Deny: []string{"shell.[x"}}}); err == nil {
+		t.Error("expected an error for a malformed pattern")
+	}
+}
@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"pryx-core/internal/config"
+)
+
+// ToolScope restricts the tools a session or channel may call. Patterns are
+// globs over the tool name with or without the "mcp." prefix, e.g.
+// "filesystem.*". A tool matching a Deny pattern is refused; with a non-empty
+// Allow list, so is a tool matching none of it.
+type ToolScope struct {
+	Allow []string
+	Deny  []string
+}
+
+// check reports whether tool is within the scope, and the reason if not.
+func (s ToolScope) check(tool string) (bool, string) {
+	for _, pattern := range s.Deny {
+		if matchToolGlob(pattern, tool) {
+			return false, fmt.Sprintf("tool %s is denied by %q", tool, pattern)
+		}
+	}
+	if len(s.Allow) == 0 {
+		return true, ""
+	}
+	for _, pattern := range s.Allow {
+		if matchToolGlob(pattern, tool) {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("tool %s is not in the allowed tools", tool)
+}
+
+// ToolScopesFromConfig converts the configured tool scopes, returning an
+// error naming the key if a pattern is malformed.
+func ToolScopesFromConfig(scopes map[string]config.ToolScope) (map[string]ToolScope, error) {
+	out := make(map[string]ToolScope, len(scopes))
+	for key, s := range scopes {
+		for _, pattern := range append(append([]string{}, s.Allow...), s.Deny...) {
+			if _, err := path.Match(trimToolPrefix(pattern), ""); err != nil {
+				return nil, fmt.Errorf("tool_scopes[%s]: bad pattern %q: %w", key, pattern, err)
+			}
+		}
+		out[key] = ToolScope{Allow: s.Allow, Deny: s.Deny}
+	}
+	return out, nil
+}
+
+// DefaultToolScope is the tool scope key applied to calls none of whose keys
+// has a scope, including calls made without a session.
+const DefaultToolScope = "default"
+
+// SetToolScopes replaces the tool scopes, keyed by session ID, by
+// "channel:<source>" and "channel:<source>:<chat>", or by DefaultToolScope.
+func (e *Engine) SetToolScopes(scopes map[string]ToolScope) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scopes = scopes
+}
+
+// EvaluateScope checks tool against the scopes configured for keys. Every
+// matching scope must admit the tool; when no key has a scope, the
+// DefaultToolScope applies if configured. It returns DecisionAllow or
+// DecisionDeny.
+func (e *Engine) EvaluateScope(keys []string, tool string) Result {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	matched := false
+	for _, key := range keys {
+		scope, ok := e.scopes[key]
+		if !ok {
+			continue
+		}
+		matched = true
+		if allowed, reason := scope.check(tool); !allowed {
+			return Result{Decision: DecisionDeny, Reason: fmt.Sprintf("%s for %s", reason, key)}
+		}
+	}
+	if scope, ok := e.scopes[DefaultToolScope]; ok && !matched {
+		if allowed, reason := scope.check(tool); !allowed {
+			return Result{Decision: DecisionDeny, Reason: fmt.Sprintf("%s for %s", reason, DefaultToolScope)}
+		}
+	}
+	return Result{Decision: DecisionAllow}
+}
+
+func matchToolGlob(pattern, tool string) bool {
+	matched, _ := path.Match(trimToolPrefix(pattern), trimToolPrefix(tool))
+	return matched
+}
+
+func trimToolPrefix(name string) string {
+	return strings.TrimPrefix(strings.TrimSpace(name), "mcp.")
+}
@@ -4,10 +4,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 )
 
+// DefaultPersonaName is used when SOUL.md does not name the assistant.
+const DefaultPersonaName = "Pryx"
+
+var personaNamePattern = regexp.MustCompile(`(?m)\bYou are ([A-Z][\w-]*)`)
+
 type Mode string
 
 const (
@@ -143,6 +149,18 @@ func (b *Builder) loadFile(filename string) (string, error) {
 	return string(data), nil
 }
 
+// PersonaName returns the assistant's name from the "You are <Name>" line of SOUL.md.
+func (b *Builder) PersonaName() string {
+	soul, err := b.loadFile("SOUL.md")
+	if err != nil {
+		return DefaultPersonaName
+	}
+	if m := personaNamePattern.FindStringSubmatch(soul); m != nil {
+		return m[1]
+	}
+	return DefaultPersonaName
+}
+
 func (b *Builder) SetMode(mode Mode) {
 	b.mode = mode
 }
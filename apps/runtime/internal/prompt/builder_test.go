@@ -255,3 +255,20 @@ func TestBuilder_Build_ConfidenceLow(t *testing.T) {
 		t.Errorf("Expected LOW confidence message, got: %s", resultStr)
 	}
 }
+
+func TestBuilder_PersonaName(t *testing.T) {
+	pryxDir := t.TempDir()
+	builder := NewBuilder(pryxDir, ModeFull)
+
+	if got := builder.PersonaName(); got != DefaultPersonaName {
+		t.Errorf("Expected default persona name without SOUL.md, got %s", got)
+	}
+
+	soul := "# Persona\n\n## Identity\nYou are Juniper, a support assistant for Acme.\n"
+	if err := os.WriteFile(pryxDir+"/SOUL.md", []byte(soul), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := builder.PersonaName(); got != "Juniper" {
+		t.Errorf("Expected persona name Juniper, got %s", got)
+	}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+)
+
+// missedRun returns the first cron firing of task after its last run (or its
+// creation, if it never ran) that is not after now. Event tasks never miss
+// runs, as events that arrive while the runtime is down are not replayed.
+func missedRun(task *ScheduledTask, now time.Time) (time.Time, bool) {
+	expr, kind, _, err := normalizeTriggerExpression(task.CronExpression)
+	if err != nil || kind != triggerKindCron {
+		return time.Time{}, false
+	}
+	schedule, err := scheduleParser.Parse(cronSpec(expr, task.Timezone))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	since := task.CreatedAt
+	if task.LastRunAt != nil {
+		since = *task.LastRunAt
+	}
+	if since.IsZero() {
+		return time.Time{}, false
+	}
+	next := schedule.Next(since)
+	if next.IsZero() || next.After(now) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// catchUpMissedRuns starts one run for each task with CatchUp set that missed
+// a cron firing before now. While the scheduler is paused, for example in
+// maintenance mode, the runs are deferred until Resume instead of dropped.
+func (s *Scheduler) catchUpMissedRuns(tasks []*ScheduledTask, now time.Time) {
+	for _, task := range tasks {
+		if !task.CatchUp {
+			continue
+		}
+		missed, ok := missedRun(task, now)
+		if !ok {
+			continue
+		}
+		s.mu.Lock()
+		paused := s.paused
+		if paused {
+			if s.deferredCatchUp == nil {
+				s.deferredCatchUp = make(map[string]*ScheduledTask)
+			}
+			s.deferredCatchUp[task.ID] = task
+		}
+		s.mu.Unlock()
+		if paused {
+			log.Printf("Task %s (%s) missed runs since %s, deferring catch-up until the scheduler resumes", task.ID, task.Name, missed.Format(time.RFC3339))
+			continue
+		}
+		log.Printf("Task %s (%s) missed runs since %s, running catch-up", task.ID, task.Name, missed.Format(time.RFC3339))
+		go s.executeTask(task, RunTriggerCatchUp)
+	}
+}
+
+// runDeferredCatchUps starts the catch-up runs deferred while paused, skipping
+// tasks deleted or disabled in the meantime.
+func (s *Scheduler) runDeferredCatchUps(deferred map[string]*ScheduledTask) {
+	for id := range deferred {
+		task, err := s.GetTask(id)
+		if err != nil || !task.Enabled {
+			continue
+		}
+		log.Printf("Task %s (%s) running deferred catch-up", task.ID, task.Name)
+		go s.executeTask(task, RunTriggerCatchUp)
+	}
+}
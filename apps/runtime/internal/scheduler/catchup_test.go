@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pryx-core/internal/store"
+)
+
+func TestMissedRun(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	lastRun := func(d time.Duration) *time.Time {
+		at := now.Add(-d)
+		return &at
+	}
+
+	cases := []struct {
+		name   string
+		task   ScheduledTask
+		missed bool
+	}{
+		{"missed daily run", ScheduledTask{CronExpression: "0 9 * * *", Timezone: "UTC", LastRunAt: lastRun(48 * time.Hour)}, true},
+		{"ran today", ScheduledTask{CronExpression: "0 9 * * *", Timezone: "UTC", LastRunAt: lastRun(2 * time.Hour)}, false},
+		{"never ran, created before a firing", ScheduledTask{CronExpression: "0 9 * * *", Timezone: "UTC", CreatedAt: now.Add(-5 * time.Hour)}, true},
+		{"interval", ScheduledTask{CronExpression: "@every 1h", LastRunAt: lastRun(3 * time.Hour)}, true},
+		{"event task", ScheduledTask{CronExpression: "event:user.login", LastRunAt: lastRun(48 * time.Hour)}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, missed := missedRun(&c.task, now); missed != c.missed {
+				t.Fatalf("missedRun = %v, want %v", missed, c.missed)
+			}
+		})
+	}
+}
+
+func TestStartRunsOneCatchUpForMissedRuns(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s1 := New(st.DB)
+	catchUp := &ScheduledTask{Name: "daily-summary", CronExpression: "0 9 * * *", TaskType: TaskTypeMessage, Enabled: true, CatchUp: true}
+	plain := &ScheduledTask{Name: "daily-plain", CronExpression: "0 9 * * *", TaskType: TaskTypeMessage, Enabled: true}
+	for _, task := range []*ScheduledTask{catchUp, plain} {
+		if err := s1.CreateTask(task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+	// Pretend both tasks last ran three days ago, so three firings were missed.
+	if _, err := st.DB.Exec(`UPDATE scheduled_tasks SET last_run_at = ?`, time.Now().Add(-72*time.Hour)); err != nil {
+		t.Fatalf("failed to backdate tasks: %v", err)
+	}
+
+	s2 := New(st.DB)
+	exec := &testExecutor{ch: make(chan *ScheduledTask, 4)}
+	s2.RegisterExecutor(TaskTypeMessage, exec)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := s2.Start(ctx); err != nil {
+		t.Fatalf("failed to start scheduler: %v", err)
+	}
+	defer s2.Stop()
+
+	select {
+	case got := <-exec.ch:
+		if got.ID != catchUp.ID {
+			t.Fatalf("expected catch-up of %s, got %s", catchUp.Name, got.Name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for catch-up run")
+	}
+	select {
+	case got := <-exec.ch:
+		t.Fatalf("unexpected extra run of %s", got.Name)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	var runs []*TaskRun
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runs, err = s2.GetTaskRuns(catchUp.ID, 10); err == nil && len(runs) == 1 && runs[0].Status == RunStatusSuccess {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(runs) != 1 || runs[0].Trigger != RunTriggerCatchUp {
+		t.Fatalf("expected a single catch_up run, got %+v", runs)
+	}
+}
+
+func TestCatchUpDeferredWhilePaused(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s1 := New(st.DB)
+	task := &ScheduledTask{Name: "daily-summary", CronExpression: "0 9 * * *", TaskType: TaskTypeMessage, Enabled: true, CatchUp: true}
+	if err := s1.CreateTask(task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	if _, err := st.DB.Exec(`UPDATE scheduled_tasks SET last_run_at = ?`, time.Now().Add(-72*time.Hour)); err != nil {
+		t.Fatalf("failed to backdate tasks: %v", err)
+	}
+
+	s2 := New(st.DB)
+	exec := &testExecutor{ch: make(chan *ScheduledTask, 4)}
+	s2.RegisterExecutor(TaskTypeMessage, exec)
+	s2.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := s2.Start(ctx); err != nil {
+		t.Fatalf("failed to start scheduler: %v", err)
+	}
+	defer s2.Stop()
+
+	select {
+	case got := <-exec.ch:
+		t.Fatalf("unexpected run of %s while paused", got.Name)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s2.Resume()
+	select {
+	case got := <-exec.ch:
+		if got.ID != task.ID {
+			t.Fatalf("expected catch-up of %s, got %s", task.Name, got.Name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for deferred catch-up run")
+	}
+}
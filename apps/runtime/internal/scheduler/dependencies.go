@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TaskSucceededEvent is the event name dependent tasks see in their payload
+// when a task they depend on succeeds.
+const TaskSucceededEvent = "scheduler.task.succeeded"
+
+var (
+	// ErrDependencyCycle is returned when a task's DependsOn would make it
+	// trigger itself, directly or through other tasks.
+	ErrDependencyCycle = errors.New("task dependency cycle")
+	// ErrUnknownDependency is returned when DependsOn names a task that does not exist.
+	ErrUnknownDependency = errors.New("depends_on names an unknown task")
+)
+
+// TriggerDependents fires the enabled tasks that list taskID in DependsOn.
+// payload describes the successful run and is passed to each task under the
+// "event" key of its payload object, as for event tasks. It returns the
+// number of tasks triggered.
+func (s *Scheduler) TriggerDependents(taskID string, payload map[string]interface{}) int {
+	s.mu.RLock()
+	byID := s.dependents[taskID]
+	tasks := make([]*ScheduledTask, 0, len(byID))
+	for _, task := range byID {
+		tasks = append(tasks, task)
+	}
+	s.mu.RUnlock()
+
+	for _, task := range tasks {
+		go s.executeTask(withEventPayload(task, TaskSucceededEvent, payload), RunTriggerDependency)
+	}
+	return len(tasks)
+}
+
+// registerDependentLocked indexes task under each task it depends on.
+// s.mu must be held.
+func (s *Scheduler) registerDependentLocked(task *ScheduledTask) {
+	for _, upstream := range task.DependsOn {
+		if _, exists := s.dependents[upstream]; !exists {
+			s.dependents[upstream] = make(map[string]*ScheduledTask)
+		}
+		s.dependents[upstream][task.ID] = task
+	}
+}
+
+// unregisterDependentLocked removes taskID from the dependents index.
+// s.mu must be held.
+func (s *Scheduler) unregisterDependentLocked(taskID string) {
+	for upstream, byID := range s.dependents {
+		delete(byID, taskID)
+		if len(byID) == 0 {
+			delete(s.dependents, upstream)
+		}
+	}
+}
+
+// validateDependencies normalizes task.DependsOn and checks that every listed
+// task exists and that the dependency graph stays acyclic with task's new
+// edges in place.
+func (s *Scheduler) validateDependencies(task *ScheduledTask) error {
+	deps := make([]string, 0, len(task.DependsOn))
+	seen := make(map[string]bool, len(task.DependsOn))
+	for _, id := range task.DependsOn {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		if id == task.ID {
+			return fmt.Errorf("%w: task cannot depend on itself", ErrDependencyCycle)
+		}
+		seen[id] = true
+		deps = append(deps, id)
+	}
+	task.DependsOn = deps
+	if len(deps) == 0 {
+		return nil
+	}
+
+	tasks, err := s.ListTasks("")
+	if err != nil {
+		return err
+	}
+	graph := make(map[string][]string, len(tasks)+1)
+	for _, t := range tasks {
+		graph[t.ID] = t.DependsOn
+	}
+	for _, id := range deps {
+		if _, exists := graph[id]; !exists {
+			return fmt.Errorf("%w: %s", ErrUnknownDependency, id)
+		}
+	}
+	graph[task.ID] = deps
+
+	// Walk upstream from task; reaching task again closes a cycle.
+	visited := map[string]bool{}
+	var walk func(id string, path []string) error
+	walk = func(id string, path []string) error {
+		path = append(path, id)
+		for _, next := range graph[id] {
+			if next == task.ID {
+				return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(append(path, next), " -> "))
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			if err := walk(next, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(task.ID, nil)
+}
+
+// encodeDependsOn returns the depends_on column value for ids.
+func encodeDependsOn(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(ids)
+	return string(data)
+}
+
+func decodeDependsOn(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var ids []string
+	if err := json.Unmarshal([]byte(value), &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"pryx-core/internal/store"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	newTask := func(name string, deps ...string) *ScheduledTask {
+		t.Helper()
+		task := &ScheduledTask{Name: name, CronExpression: "0 9 * * *", TaskType: TaskTypeMessage, Enabled: true, DependsOn: deps}
+		if err := s.CreateTask(task); err != nil {
+			t.Fatalf("CreateTask(%s): %v", name, err)
+		}
+		return task
+	}
+
+	fetch := newTask("fetch")
+	summarize := newTask("summarize", fetch.ID, " "+fetch.ID, "")
+	if len(summarize.DependsOn) != 1 || summarize.DependsOn[0] != fetch.ID {
+		t.Fatalf("expected normalized depends_on, got %v", summarize.DependsOn)
+	}
+	publish := newTask("publish", summarize.ID)
+
+	loaded, err := s.GetTask(publish.ID)
+	if err != nil || loaded == nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if len(loaded.DependsOn) != 1 || loaded.DependsOn[0] != summarize.ID {
+		t.Fatalf("depends_on not stored, got %v", loaded.DependsOn)
+	}
+
+	bad := &ScheduledTask{Name: "orphan", CronExpression: "0 9 * * *", TaskType: TaskTypeMessage, DependsOn: []string{"missing"}}
+	if err := s.CreateTask(bad); !errors.Is(err, ErrUnknownDependency) {
+		t.Fatalf("expected ErrUnknownDependency, got %v", err)
+	}
+
+	fetch.DependsOn = []string{fetch.ID}
+	if err := s.UpdateTask(fetch); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("expected self-dependency to be rejected, got %v", err)
+	}
+	fetch.DependsOn = []string{publish.ID}
+	if err := s.UpdateTask(fetch); !errors.Is(err, ErrDependencyCycle) {
+		t.Fatalf("expected cycle to be rejected, got %v", err)
+	}
+}
+
+func TestTriggerDependents(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	exec := &testExecutor{ch: make(chan *ScheduledTask, 2)}
+	s.RegisterExecutor(TaskTypeMessage, exec)
+
+	fetch := &ScheduledTask{Name: "fetch", CronExpression: "0 9 * * *", TaskType: TaskTypeMessage, Enabled: true}
+	if err := s.CreateTask(fetch); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	summarize := &ScheduledTask{Name: "summarize", CronExpression: "event:never", TaskType: TaskTypeMessage, Payload: `{"prompt":"sum"}`, Enabled: true, DependsOn: []string{fetch.ID}}
+	if err := s.CreateTask(summarize); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	disabled := &ScheduledTask{Name: "disabled", CronExpression: "0 9 * * *", TaskType: TaskTypeMessage, DependsOn: []string{fetch.ID}}
+	if err := s.CreateTask(disabled); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	if n := s.TriggerDependents(fetch.ID, map[string]interface{}{"task_id": fetch.ID}); n != 1 {
+		t.Fatalf("expected 1 dependent triggered, got %d", n)
+	}
+	select {
+	case got := <-exec.ch:
+		if got.ID != summarize.ID {
+			t.Fatalf("expected %s to run, got %s", summarize.Name, got.Name)
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal([]byte(got.Payload), &payload); err != nil {
+			t.Fatalf("payload: %v", err)
+		}
+		event, _ := payload["event"].(map[string]interface{})
+		if event["name"] != TaskSucceededEvent || payload["prompt"] != "sum" {
+			t.Fatalf("unexpected payload %s", got.Payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for dependent run")
+	}
+
+	var runs []*TaskRun
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if runs, err = s.GetTaskRuns(summarize.ID, 10); err == nil && len(runs) == 1 && runs[0].Status == RunStatusSuccess {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(runs) != 1 || runs[0].Trigger != RunTriggerDependency {
+		t.Fatalf("expected a single dependency run, got %+v", runs)
+	}
+
+	if err := s.DisableTask(summarize.ID); err != nil {
+		t.Fatalf("DisableTask: %v", err)
+	}
+	if n := s.TriggerDependents(fetch.ID, nil); n != 0 {
+		t.Fatalf("expected disabled dependents to be skipped, got %d", n)
+	}
+}
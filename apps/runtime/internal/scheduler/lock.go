@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultLockLease is how long a run lock is held before another instance may
+// treat its owner as crashed. It matches the task execution timeout.
+const DefaultLockLease = 30 * time.Minute
+
+// lockConfig enables run locking for instances sharing a database.
+type lockConfig struct {
+	owner string
+	lease time.Duration
+}
+
+// EnableLocking makes scheduled runs take a lock row in the shared database so
+// that when several runtimes use the same database, each run window executes on
+// only one of them. A lock is released when its run completes; if the owner
+// dies first, the lock expires after lease (DefaultLockLease when zero).
+func (s *Scheduler) EnableLocking(lease time.Duration) {
+	if lease <= 0 {
+		lease = DefaultLockLease
+	}
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.New().String()[:8])
+
+	s.mu.Lock()
+	s.locking = &lockConfig{owner: owner, lease: lease}
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) lockSettings() *lockConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.locking
+}
+
+// runWindow returns the start of the schedule window a run fired in. Cron
+// schedules have minute resolution; intervals are aligned to multiples of the
+// interval so that every instance computes the same window.
+func runWindow(cronExpr string, now time.Time) time.Time {
+	if rest, ok := strings.CutPrefix(cronExpr, "@every "); ok {
+		if d, err := time.ParseDuration(rest); err == nil && d > 0 {
+			return now.Truncate(d)
+		}
+	}
+	return now.Truncate(time.Minute)
+}
+
+// acquireLock claims window for task. It succeeds when no lock exists, or when
+// the previous lock is for an earlier window and was released or has expired.
+func (s *Scheduler) acquireLock(lc *lockConfig, taskID string, window, now time.Time) (bool, error) {
+	res, err := s.db.Exec(`
+		INSERT INTO scheduler_locks (task_id, run_window, owner, expires_at, released)
+		VALUES (?, ?, ?, ?, 0)
+		ON CONFLICT(task_id) DO UPDATE SET
+			run_window = excluded.run_window,
+			owner = excluded.owner,
+			expires_at = excluded.expires_at,
+			released = 0
+		WHERE scheduler_locks.run_window < excluded.run_window
+		  AND (scheduler_locks.released = 1 OR scheduler_locks.expires_at < ?)
+	`, taskID, window.Unix(), lc.owner, now.Add(lc.lease).Unix(), now.Unix())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// releaseLock marks the lock for window as released if this instance still holds it.
+func (s *Scheduler) releaseLock(lc *lockConfig, taskID string, window time.Time) {
+	_, err := s.db.Exec(`
+		UPDATE scheduler_locks SET released = 1
+		WHERE task_id = ? AND run_window = ? AND owner = ?
+	`, taskID, window.Unix(), lc.owner)
+	if err != nil {
+		log.Printf("Failed to release lock for task %s: %v", taskID, err)
+	}
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"pryx-core/internal/store"
+)
+
+func TestRunWindow(t *testing.T) {
+	now := time.Date(2026, 1, 2, 9, 17, 42, 0, time.UTC)
+	if got := runWindow("*/5 * * * *", now); !got.Equal(time.Date(2026, 1, 2, 9, 17, 0, 0, time.UTC)) {
+		t.Fatalf("cron window = %v", got)
+	}
+	if got := runWindow("@every 10m0s", now); !got.Equal(time.Date(2026, 1, 2, 9, 10, 0, 0, time.UTC)) {
+		t.Fatalf("interval window = %v", got)
+	}
+}
+
+func TestLockingRunsWindowOnce(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	// Two schedulers on one database stand in for two runtime instances.
+	a, b := New(st.DB), New(st.DB)
+	execA := &testExecutor{ch: make(chan *ScheduledTask, 4)}
+	execB := &testExecutor{ch: make(chan *ScheduledTask, 4)}
+	a.RegisterExecutor(TaskTypeMessage, execA)
+	b.RegisterExecutor(TaskTypeMessage, execB)
+	a.EnableLocking(time.Minute)
+	b.EnableLocking(time.Minute)
+
+	task := &ScheduledTask{
+		Name:           "daily",
+		CronExpression: "0 9 * * *",
+		TaskType:       TaskTypeMessage,
+		Enabled:        true,
+	}
+	if err := a.CreateTask(task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	a.executeTask(task, RunTriggerSchedule)
+	b.executeTask(task, RunTriggerSchedule)
+	if len(execA.ch) != 1 || len(execB.ch) != 0 {
+		t.Fatalf("expected one run on the first instance, got %d and %d", len(execA.ch), len(execB.ch))
+	}
+
+	// Manual runs bypass the lock.
+	if _, err := b.RunNow(task.ID); err != nil {
+		t.Fatalf("RunNow failed: %v", err)
+	}
+	if len(execB.ch) != 1 {
+		t.Fatal("expected manual run to execute despite lock")
+	}
+}
+
+func TestAcquireLock(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	one := &lockConfig{owner: "one", lease: time.Minute}
+	two := &lockConfig{owner: "two", lease: time.Minute}
+	now := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	w1, w2 := now, now.Add(time.Minute)
+
+	mustAcquire := func(lc *lockConfig, window, at time.Time, want bool) {
+		t.Helper()
+		got, err := s.acquireLock(lc, "task", window, at)
+		if err != nil {
+			t.Fatalf("acquireLock failed: %v", err)
+		}
+		if got != want {
+			t.Fatalf("acquireLock(%s, %v) = %v, want %v", lc.owner, window, got, want)
+		}
+	}
+
+	mustAcquire(one, w1, now, true)
+	mustAcquire(two, w1, now, false)
+	// The next window waits for release while the lease is live.
+	mustAcquire(two, w2, w2, false)
+	s.releaseLock(one, "task", w1)
+	mustAcquire(two, w2, w2, true)
+	// A released window is never run twice.
+	mustAcquire(one, w1, w2, false)
+
+	// A crashed owner's lock is taken over once its lease expires.
+	w3 := w2.Add(time.Minute)
+	mustAcquire(one, w3, w3, false)
+	mustAcquire(one, w3, w2.Add(2*time.Minute), true)
+}
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TaskDefinition is the portable form of a ScheduledTask. It carries the
+// definition only; run history and computed fields stay on the machine.
+type TaskDefinition struct {
+	ID             string   `json:"id,omitempty"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	CronExpression string   `json:"cron_expression"`
+	TaskType       TaskType `json:"task_type"`
+	Payload        string   `json:"payload,omitempty"`
+	Timezone       string   `json:"timezone,omitempty"`
+	Enabled        bool     `json:"enabled"`
+	MaxRetries     int      `json:"max_retries,omitempty"`
+	RetryBackoff   string   `json:"retry_backoff,omitempty"`
+	CatchUp        bool     `json:"catch_up,omitempty"`
+	DependsOn      []string `json:"depends_on,omitempty"`
+}
+
+// ImportOptions controls how task definitions are imported.
+type ImportOptions struct {
+	// PreserveIDs keeps the IDs from the definitions instead of generating new ones.
+	PreserveIDs bool
+}
+
+// ImportIssue describes a definition that was not imported.
+type ImportIssue struct {
+	Index  int    `json:"index"`
+	ID     string `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ImportResult summarizes an import.
+type ImportResult struct {
+	Created   []string      `json:"created"`
+	Conflicts []ImportIssue `json:"conflicts"`
+	Invalid   []ImportIssue `json:"invalid"`
+}
+
+// IsValidTaskType reports whether t is a known task type.
+func IsValidTaskType(t TaskType) bool {
+	switch t {
+	case TaskTypeMessage, TaskTypeWorkflow, TaskTypeReminder, TaskTypeWebhook, TaskTypeDBCompact:
+		return true
+	}
+	return false
+}
+
+// ExportTasks returns the definitions of all tasks, oldest first.
+func (s *Scheduler) ExportTasks() ([]TaskDefinition, error) {
+	tasks, err := s.ListTasks("")
+	if err != nil {
+		return nil, err
+	}
+	defs := make([]TaskDefinition, 0, len(tasks))
+	for i := len(tasks) - 1; i >= 0; i-- {
+		t := tasks[i]
+		defs = append(defs, TaskDefinition{
+			ID:             t.ID,
+			Name:           t.Name,
+			Description:    t.Description,
+			CronExpression: t.CronExpression,
+			TaskType:       t.TaskType,
+			Payload:        t.Payload,
+			Timezone:       t.Timezone,
+			Enabled:        t.Enabled,
+			MaxRetries:     t.MaxRetries,
+			RetryBackoff:   t.RetryBackoff,
+			CatchUp:        t.CatchUp,
+			DependsOn:      t.DependsOn,
+		})
+	}
+	return defs, nil
+}
+
+// ImportTasks validates and creates tasks from defs. Definitions whose name
+// (or ID, when preserving IDs) matches an existing or earlier imported task are
+// reported as conflicts and skipped; invalid definitions are reported and skipped.
+// DependsOn entries naming an earlier definition's ID are rewritten to the ID
+// that definition was imported under.
+func (s *Scheduler) ImportTasks(defs []TaskDefinition, opts ImportOptions) (*ImportResult, error) {
+	existing, err := s.ListTasks("")
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(existing))
+	names := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		ids[t.ID] = true
+		names[strings.ToLower(t.Name)] = true
+	}
+
+	imported := map[string]string{}
+	result := &ImportResult{Created: []string{}, Conflicts: []ImportIssue{}, Invalid: []ImportIssue{}}
+	for i, def := range defs {
+		issue := ImportIssue{Index: i, ID: def.ID, Name: def.Name}
+
+		if err := validateDefinition(def); err != nil {
+			issue.Reason = err.Error()
+			result.Invalid = append(result.Invalid, issue)
+			continue
+		}
+		nameKey := strings.ToLower(strings.TrimSpace(def.Name))
+		if opts.PreserveIDs && def.ID != "" && ids[def.ID] {
+			issue.Reason = "task id already exists"
+			result.Conflicts = append(result.Conflicts, issue)
+			continue
+		}
+		if names[nameKey] {
+			issue.Reason = "task name already exists"
+			result.Conflicts = append(result.Conflicts, issue)
+			continue
+		}
+
+		task := &ScheduledTask{
+			Name:           strings.TrimSpace(def.Name),
+			Description:    def.Description,
+			CronExpression: def.CronExpression,
+			TaskType:       def.TaskType,
+			Payload:        def.Payload,
+			Timezone:       def.Timezone,
+			Enabled:        def.Enabled,
+			MaxRetries:     def.MaxRetries,
+			RetryBackoff:   def.RetryBackoff,
+			CatchUp:        def.CatchUp,
+		}
+		for _, dep := range def.DependsOn {
+			if id, ok := imported[dep]; ok {
+				dep = id
+			}
+			task.DependsOn = append(task.DependsOn, dep)
+		}
+		if opts.PreserveIDs {
+			task.ID = def.ID
+		}
+		if err := s.CreateTask(task); err != nil {
+			issue.Reason = err.Error()
+			result.Invalid = append(result.Invalid, issue)
+			continue
+		}
+		ids[task.ID] = true
+		names[nameKey] = true
+		if def.ID != "" {
+			imported[def.ID] = task.ID
+		}
+		result.Created = append(result.Created, task.ID)
+	}
+	return result, nil
+}
+
+func validateDefinition(def TaskDefinition) error {
+	if strings.TrimSpace(def.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !IsValidTaskType(def.TaskType) {
+		return fmt.Errorf("invalid task type: %s", def.TaskType)
+	}
+	if err := ValidateCronExpression(def.CronExpression); err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+	return ValidateRetryPolicy(def.MaxRetries, def.RetryBackoff)
+}
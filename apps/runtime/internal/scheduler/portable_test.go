@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"pryx-core/internal/store"
+)
+
+func newTestScheduler(t *testing.T) *Scheduler {
+	t.Helper()
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return New(st.DB)
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := newTestScheduler(t)
+	for _, name := range []string{"first", "second"} {
+		if err := src.CreateTask(&ScheduledTask{Name: name, CronExpression: "@every 5m", TaskType: TaskTypeReminder, Payload: `{"m":"` + name + `"}`, Enabled: true}); err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+	}
+	if _, err := src.db.Exec(`UPDATE scheduled_tasks SET run_count = 3, last_run_at = ?`, time.Now()); err != nil {
+		t.Fatalf("seed run history: %v", err)
+	}
+
+	defs, err := src.ExportTasks()
+	if err != nil {
+		t.Fatalf("ExportTasks: %v", err)
+	}
+	if len(defs) != 2 || defs[0].Name != "first" || defs[1].Name != "second" {
+		t.Fatalf("unexpected export: %+v", defs)
+	}
+
+	dst := newTestScheduler(t)
+	res, err := dst.ImportTasks(defs, ImportOptions{PreserveIDs: true})
+	if err != nil {
+		t.Fatalf("ImportTasks: %v", err)
+	}
+	if len(res.Created) != 2 || len(res.Conflicts) != 0 || len(res.Invalid) != 0 {
+		t.Fatalf("unexpected import result: %+v", res)
+	}
+	imported, err := dst.GetTask(defs[0].ID)
+	if err != nil || imported == nil {
+		t.Fatalf("expected task with preserved id, err=%v", err)
+	}
+	if imported.RunCount != 0 || imported.LastRunAt != nil {
+		t.Fatalf("run history should not be imported: %+v", imported)
+	}
+
+	// A second import conflicts on every definition.
+	res, err = dst.ImportTasks(defs, ImportOptions{PreserveIDs: true})
+	if err != nil {
+		t.Fatalf("ImportTasks: %v", err)
+	}
+	if len(res.Created) != 0 || len(res.Conflicts) != 2 {
+		t.Fatalf("expected conflicts, got %+v", res)
+	}
+}
+
+func TestImportRejectsInvalidDefinitions(t *testing.T) {
+	s := newTestScheduler(t)
+	res, err := s.ImportTasks([]TaskDefinition{
+		{Name: "", CronExpression: "@every 5m", TaskType: TaskTypeMessage},
+		{Name: "bad type", CronExpression: "@every 5m", TaskType: "unknown"},
+		{Name: "bad cron", CronExpression: "every bananas", TaskType: TaskTypeMessage},
+		{Name: "ok", CronExpression: "event:mesh.sync", TaskType: TaskTypeWebhook},
+		{Name: "OK", CronExpression: "@every 1h", TaskType: TaskTypeMessage},
+	}, ImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportTasks: %v", err)
+	}
+	if len(res.Invalid) != 3 || len(res.Created) != 1 || len(res.Conflicts) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if res.Conflicts[0].Index != 4 {
+		t.Fatalf("expected duplicate name within import to conflict, got %+v", res.Conflicts)
+	}
+}
@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// SetRunRetention bounds the run history kept in scheduled_task_runs.
+// maxRunsPerTask keeps only the newest runs of each task and maxAge drops runs
+// that started longer ago; zero disables either limit. Runs still in progress
+// are never removed. The history is pruned on the scheduler's refresh loop.
+func (s *Scheduler) SetRunRetention(maxRunsPerTask int, maxAge time.Duration) {
+	if maxRunsPerTask < 0 {
+		maxRunsPerTask = 0
+	}
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	s.mu.Lock()
+	s.maxRunsPerTask = maxRunsPerTask
+	s.runRetention = maxAge
+	s.mu.Unlock()
+}
+
+// PruneRuns deletes runs outside the retention set by SetRunRetention and
+// returns how many were removed.
+func (s *Scheduler) PruneRuns(now time.Time) (int64, error) {
+	s.mu.RLock()
+	maxRuns, maxAge := s.maxRunsPerTask, s.runRetention
+	s.mu.RUnlock()
+
+	var removed int64
+	if maxAge > 0 {
+		res, err := s.db.Exec(`
+			DELETE FROM scheduled_task_runs
+			WHERE started_at < ? AND status != ?
+		`, now.Add(-maxAge), RunStatusRunning)
+		if err != nil {
+			return removed, fmt.Errorf("prune runs by age: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		removed += n
+	}
+	if maxRuns > 0 {
+		res, err := s.db.Exec(`
+			DELETE FROM scheduled_task_runs
+			WHERE status != ? AND id IN (
+				SELECT id FROM (
+					SELECT id, ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY started_at DESC, id DESC) AS rn
+					FROM scheduled_task_runs
+				) WHERE rn > ?
+			)
+		`, RunStatusRunning, maxRuns)
+		if err != nil {
+			return removed, fmt.Errorf("prune runs by count: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		removed += n
+	}
+	return removed, nil
+}
+
+// pruneRuns runs PruneRuns from the refresh loop, logging the outcome.
+func (s *Scheduler) pruneRuns() {
+	removed, err := s.PruneRuns(time.Now())
+	if err != nil {
+		log.Printf("Failed to prune task runs: %v", err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("Pruned %d old task runs", removed)
+	}
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"pryx-core/internal/store"
+)
+
+func TestPruneRuns(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	busy := &ScheduledTask{Name: "every-minute", CronExpression: "* * * * *", TaskType: TaskTypeMessage, Enabled: true}
+	quiet := &ScheduledTask{Name: "weekly", CronExpression: "0 9 * * 1", TaskType: TaskTypeMessage, Enabled: true}
+	for _, task := range []*ScheduledTask{busy, quiet} {
+		if err := s.CreateTask(task); err != nil {
+			t.Fatalf("failed to create task: %v", err)
+		}
+	}
+
+	now := time.Now()
+	addRun := func(task *ScheduledTask, i int, age time.Duration, status RunStatus) {
+		run := &TaskRun{ID: fmt.Sprintf("%s-%d", task.Name, i), TaskID: task.ID, StartedAt: now.Add(-age), Status: status, Trigger: RunTriggerSchedule, Attempt: 1}
+		if err := s.saveRun(run); err != nil {
+			t.Fatalf("failed to save run: %v", err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		addRun(busy, i, time.Duration(i)*time.Minute, RunStatusSuccess)
+	}
+	addRun(busy, 10, 20*time.Minute, RunStatusRunning)
+	addRun(quiet, 0, time.Hour, RunStatusSuccess)
+	addRun(quiet, 1, 40*24*time.Hour, RunStatusFailed)
+
+	// No limits configured: nothing is removed.
+	if removed, err := s.PruneRuns(now); err != nil || removed != 0 {
+		t.Fatalf("PruneRuns without retention = %d, %v", removed, err)
+	}
+
+	s.SetRunRetention(3, 30*24*time.Hour)
+	removed, err := s.PruneRuns(now)
+	if err != nil {
+		t.Fatalf("PruneRuns: %v", err)
+	}
+	// 7 of the busy task's successful runs by count, the month-old quiet run by age.
+	if removed != 8 {
+		t.Fatalf("removed %d runs, want 8", removed)
+	}
+
+	runs, _ := s.GetTaskRuns(busy.ID, 100)
+	ids := map[string]bool{}
+	for _, r := range runs {
+		ids[r.ID] = true
+	}
+	for _, want := range []string{"every-minute-0", "every-minute-1", "every-minute-2", "every-minute-10"} {
+		if !ids[want] {
+			t.Errorf("expected %s to be kept, got %v", want, ids)
+		}
+	}
+	if len(runs) != 4 {
+		t.Errorf("busy task has %d runs, want 4", len(runs))
+	}
+	if runs, _ := s.GetTaskRuns(quiet.ID, 100); len(runs) != 1 || runs[0].ID != "weekly-0" {
+		t.Errorf("quiet task runs = %+v, want only weekly-0", runs)
+	}
+}
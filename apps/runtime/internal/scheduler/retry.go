@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRetryBackoff is the wait before the first retry when a task sets
+	// MaxRetries but no RetryBackoff.
+	DefaultRetryBackoff = 30 * time.Second
+	// MaxTaskRetries caps MaxRetries.
+	MaxTaskRetries = 10
+	// maxRetryDelay caps the wait between two attempts.
+	maxRetryDelay = time.Hour
+)
+
+// ValidateRetryPolicy checks that maxRetries is within [0, MaxTaskRetries] and
+// that backoff is empty or a positive duration.
+func ValidateRetryPolicy(maxRetries int, backoff string) error {
+	if maxRetries < 0 || maxRetries > MaxTaskRetries {
+		return fmt.Errorf("max_retries must be between 0 and %d", MaxTaskRetries)
+	}
+	if strings.TrimSpace(backoff) == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(backoff))
+	if err != nil {
+		return fmt.Errorf("invalid retry_backoff %q: %w", backoff, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("retry_backoff must be positive")
+	}
+	return nil
+}
+
+// retryDelay returns how long to wait before running attempt (2 or later):
+// the task's backoff, doubled for each earlier retry and capped at maxRetryDelay.
+func retryDelay(task *ScheduledTask, attempt int) time.Duration {
+	base := DefaultRetryBackoff
+	if d, err := time.ParseDuration(strings.TrimSpace(task.RetryBackoff)); err == nil && d > 0 {
+		base = d
+	}
+	delay := base
+	for i := 2; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// RetriesExhausted reports whether r is a failed run of a task with retries
+// configured and no retries left.
+func (r *TaskRun) RetriesExhausted(task *ScheduledTask) bool {
+	return r.Status == RunStatusFailed && task.MaxRetries > 0 && r.Attempt > task.MaxRetries
+}
+
+// retryIfFailed schedules the next attempt of a failed run while the task has
+// retries left.
+func (s *Scheduler) retryIfFailed(task *ScheduledTask, run *TaskRun) {
+	if run.Status != RunStatusFailed || run.Attempt > task.MaxRetries {
+		return
+	}
+	select {
+	case <-s.stopChan:
+		return
+	default:
+	}
+
+	attempt := run.Attempt + 1
+	delay := retryDelay(task, attempt)
+	log.Printf("Task %s (%s) failed, retry %d/%d in %s", task.ID, task.Name, run.Attempt, task.MaxRetries, delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		s.mu.Lock()
+		_, pending := s.retries[timer]
+		delete(s.retries, timer)
+		s.mu.Unlock()
+		if !pending {
+			return
+		}
+		s.executeAttempt(task, run.Trigger, attempt)
+	})
+	s.retries[timer] = task.ID
+}
+
+// cancelRetries stops pending retries of taskID, or of every task when taskID
+// is empty.
+func (s *Scheduler) cancelRetries(taskID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for timer, id := range s.retries {
+		if taskID == "" || id == taskID {
+			timer.Stop()
+			delete(s.retries, timer)
+		}
+	}
+}
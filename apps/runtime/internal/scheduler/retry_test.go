@@ -0,0 +1,239 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pryx-core/internal/store"
+)
+
+func TestFailedRunIsRetriedWithBackoff(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	defer s.Stop()
+	s.RegisterExecutor(TaskTypeMessage, &testExecutor{err: errors.New("upstream down")})
+	exhausted := make(chan *TaskRun, 1)
+	s.SetRunHook(func(task *ScheduledTask, run *TaskRun) {
+		if run.RetriesExhausted(task) {
+			exhausted <- run
+		}
+	})
+
+	task := &ScheduledTask{
+		Name:           "flaky",
+		CronExpression: "0 9 * * *",
+		TaskType:       TaskTypeMessage,
+		Enabled:        true,
+		MaxRetries:     2,
+		RetryBackoff:   "10ms",
+	}
+	if err := s.CreateTask(task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	run, err := s.RunNow(task.ID)
+	if err != nil {
+		t.Fatalf("RunNow failed: %v", err)
+	}
+	if run.Status != RunStatusFailed || run.Attempt != 1 {
+		t.Fatalf("unexpected first run: %+v", run)
+	}
+
+	select {
+	case last := <-exhausted:
+		if last.Attempt != 3 {
+			t.Fatalf("expected retries to end on attempt 3, got %d", last.Attempt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("retries were not exhausted")
+	}
+
+	runs, err := s.GetTaskRuns(task.ID, 10)
+	if err != nil {
+		t.Fatalf("failed to get runs: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(runs))
+	}
+	attempts := map[int]bool{}
+	for _, r := range runs {
+		if r.Status != RunStatusFailed || r.Trigger != RunTriggerManual {
+			t.Fatalf("unexpected run: %+v", r)
+		}
+		attempts[r.Attempt] = true
+	}
+	if !attempts[1] || !attempts[2] || !attempts[3] {
+		t.Fatalf("expected attempts 1-3, got %v", attempts)
+	}
+
+	got, err := s.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if got.Status != TaskStatusError {
+		t.Fatalf("expected task status error, got %q", got.Status)
+	}
+}
+
+func TestFailedRunWithoutRetries(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	defer s.Stop()
+	s.RegisterExecutor(TaskTypeMessage, &testExecutor{err: errors.New("boom")})
+
+	task := &ScheduledTask{
+		Name:           "once",
+		CronExpression: "0 9 * * *",
+		TaskType:       TaskTypeMessage,
+		Enabled:        true,
+	}
+	if err := s.CreateTask(task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	run, err := s.RunNow(task.ID)
+	if err != nil {
+		t.Fatalf("RunNow failed: %v", err)
+	}
+	if run.RetriesExhausted(task) {
+		t.Fatal("a task without retries should not report exhausted retries")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	runs, err := s.GetTaskRuns(task.ID, 10)
+	if err != nil {
+		t.Fatalf("failed to get runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Attempt != 1 {
+		t.Fatalf("expected a single run, got %+v", runs)
+	}
+	got, err := s.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if got.Status != TaskStatusActive {
+		t.Fatalf("expected task status to stay active, got %q", got.Status)
+	}
+}
+
+func TestValidateRetryPolicy(t *testing.T) {
+	valid := []struct {
+		retries int
+		backoff string
+	}{{0, ""}, {3, "30s"}, {MaxTaskRetries, "1m"}}
+	for _, c := range valid {
+		if err := ValidateRetryPolicy(c.retries, c.backoff); err != nil {
+			t.Errorf("ValidateRetryPolicy(%d, %q) = %v, want nil", c.retries, c.backoff, err)
+		}
+	}
+	invalid := []struct {
+		retries int
+		backoff string
+	}{{-1, ""}, {MaxTaskRetries + 1, ""}, {1, "soon"}, {1, "-5s"}}
+	for _, c := range invalid {
+		if err := ValidateRetryPolicy(c.retries, c.backoff); err == nil {
+			t.Errorf("ValidateRetryPolicy(%d, %q) = nil, want error", c.retries, c.backoff)
+		}
+	}
+
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+	s := New(st.DB)
+	task := &ScheduledTask{Name: "bad", CronExpression: "0 9 * * *", TaskType: TaskTypeMessage, RetryBackoff: "soon", MaxRetries: 1}
+	if err := s.CreateTask(task); err == nil {
+		t.Fatal("expected CreateTask to reject an invalid retry backoff")
+	}
+}
+
+func TestRetryDelayDoublesUpToCap(t *testing.T) {
+	task := &ScheduledTask{RetryBackoff: "1m"}
+	for attempt, want := range map[int]time.Duration{2: time.Minute, 3: 2 * time.Minute, 4: 4 * time.Minute, 10: time.Hour} {
+		if got := retryDelay(task, attempt); got != want {
+			t.Errorf("retryDelay(attempt %d) = %s, want %s", attempt, got, want)
+		}
+	}
+	if got := retryDelay(&ScheduledTask{}, 2); got != DefaultRetryBackoff {
+		t.Errorf("default delay = %s, want %s", got, DefaultRetryBackoff)
+	}
+}
+
+func TestRetryTakesTheLock(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	// Two schedulers on one database stand in for two runtime instances.
+	a, b := New(st.DB), New(st.DB)
+	defer a.Stop()
+	execA := &testExecutor{ch: make(chan *ScheduledTask, 4), err: errors.New("upstream down")}
+	a.RegisterExecutor(TaskTypeMessage, execA)
+	exhausted := make(chan struct{}, 1)
+	a.SetRunHook(func(task *ScheduledTask, run *TaskRun) {
+		if run.RetriesExhausted(task) {
+			exhausted <- struct{}{}
+		}
+	})
+	a.EnableLocking(time.Minute)
+	b.EnableLocking(time.Minute)
+
+	task := &ScheduledTask{
+		Name:           "flaky",
+		CronExpression: "0 9 * * *",
+		TaskType:       TaskTypeMessage,
+		Enabled:        true,
+		MaxRetries:     1,
+		RetryBackoff:   "10ms",
+	}
+	if err := a.CreateTask(task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+
+	a.executeTask(task, RunTriggerSchedule)
+	select {
+	case <-exhausted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the retry to run once the first attempt released its lock")
+	}
+	if len(execA.ch) != 2 {
+		t.Fatalf("expected two attempts, got %d", len(execA.ch))
+	}
+	<-execA.ch
+	<-execA.ch
+
+	// While another instance runs the task, a retry is skipped. The retry's
+	// lock is released just after its run hook, so wait for it.
+	deadline := time.Now().Add(time.Second)
+	for {
+		now := time.Now()
+		ok, err := b.acquireLock(b.lockSettings(), task.ID, now.Add(time.Hour), now)
+		if err != nil {
+			t.Fatalf("failed to take the lock on the second instance: %v", err)
+		}
+		if ok {
+			break
+		}
+		if now.After(deadline) {
+			t.Fatal("the retry did not release its lock")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	a.executeAttempt(task, RunTriggerSchedule, 2)
+	if len(execA.ch) != 0 {
+		t.Fatal("expected the retry to be skipped while another instance holds the lock")
+	}
+}
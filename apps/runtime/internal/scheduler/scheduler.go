@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"regexp"
@@ -12,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"pryx-core/internal/config"
 	"pryx-core/internal/store"
 
 	"github.com/google/uuid"
@@ -43,10 +45,11 @@ var (
 type TaskType string
 
 const (
-	TaskTypeMessage  TaskType = "message"
-	TaskTypeWorkflow TaskType = "workflow"
-	TaskTypeReminder TaskType = "reminder"
-	TaskTypeWebhook  TaskType = "webhook"
+	TaskTypeMessage   TaskType = "message"
+	TaskTypeWorkflow  TaskType = "workflow"
+	TaskTypeReminder  TaskType = "reminder"
+	TaskTypeWebhook   TaskType = "webhook"
+	TaskTypeDBCompact TaskType = "db_compact"
 )
 
 // TaskStatus defines the status of a scheduled task
@@ -68,6 +71,30 @@ const (
 	RunStatusFailed  RunStatus = "failed"
 )
 
+// RunTrigger records what started a task run
+type RunTrigger string
+
+const (
+	RunTriggerSchedule RunTrigger = "schedule"
+	RunTriggerEvent    RunTrigger = "event"
+	RunTriggerManual   RunTrigger = "manual"
+	// RunTriggerCatchUp marks the single run made on start for cron firings
+	// missed while the runtime was down.
+	RunTriggerCatchUp RunTrigger = "catch_up"
+	// RunTriggerDependency marks a run started by the success of a task
+	// listed in DependsOn.
+	RunTriggerDependency RunTrigger = "dependency"
+)
+
+var (
+	// ErrTaskNotFound is returned when a task ID does not exist.
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrSchedulerPaused is returned by RunNow while the scheduler is paused.
+	ErrSchedulerPaused = errors.New("scheduler is paused")
+	// ErrTaskRunning is returned by RunNow and StartRun while a run of the task is in progress.
+	ErrTaskRunning = errors.New("task is already running")
+)
+
 // ScheduledTask represents a scheduled task in the database
 type ScheduledTask struct {
 	ID             string     `json:"id"`
@@ -86,6 +113,20 @@ type ScheduledTask struct {
 	UserID         string     `json:"user_id,omitempty"`
 	CreatedAt      time.Time  `json:"created_at"`
 	UpdatedAt      time.Time  `json:"updated_at"`
+	// MaxRetries is how many times a failed run is retried (0 = never).
+	MaxRetries int `json:"max_retries"`
+	// RetryBackoff is the wait before the first retry, as a duration such as "30s";
+	// each later retry waits twice as long. Empty uses DefaultRetryBackoff.
+	RetryBackoff string `json:"retry_backoff,omitempty"`
+	// Status is TaskStatusError once a run has failed with no retries left, and
+	// TaskStatusActive again after the next successful run.
+	Status TaskStatus `json:"status,omitempty"`
+	// CatchUp runs the task once on start if cron firings were missed while the
+	// runtime was down. Several misses are coalesced into one run.
+	CatchUp bool `json:"catch_up"`
+	// DependsOn lists task IDs whose successful runs also trigger this task,
+	// in addition to its own schedule.
+	DependsOn []string `json:"depends_on,omitempty"`
 }
 
 // TaskRun represents a single execution of a scheduled task
@@ -95,8 +136,11 @@ type TaskRun struct {
 	StartedAt   time.Time  `json:"started_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	Status      RunStatus  `json:"status"`
+	Trigger     RunTrigger `json:"trigger"`
 	Error       string     `json:"error,omitempty"`
 	Output      string     `json:"output,omitempty"`
+	// Attempt numbers the runs of one firing: 1 for the first, 2 for the first retry.
+	Attempt int `json:"attempt"`
 }
 
 // TaskExecutor defines the interface for executing scheduled tasks
@@ -112,10 +156,27 @@ type Scheduler struct {
 	executors  map[TaskType]TaskExecutor
 	tasks      map[string]cron.EntryID
 	eventTasks map[string]map[string]*ScheduledTask
+	// dependents maps a task ID to the enabled tasks that depend on it.
+	dependents map[string]map[string]*ScheduledTask
 	mu         sync.RWMutex
 	stopChan   chan struct{}
 	wg         sync.WaitGroup
 	stopOnce   sync.Once
+	paused     bool
+	runHook    func(task *ScheduledTask, run *TaskRun)
+	locking    *lockConfig
+	// retries holds pending retry timers and the task each belongs to.
+	retries map[*time.Timer]string
+	// running counts the runs in progress per task ID.
+	running map[string]int
+	// deferredCatchUp holds the catch-up runs found while paused, by task ID;
+	// Resume starts them.
+	deferredCatchUp map[string]*ScheduledTask
+	// defaultTimezone is given to new tasks that do not name a zone.
+	defaultTimezone string
+	// maxRunsPerTask and runRetention bound the run history; see SetRunRetention.
+	maxRunsPerTask int
+	runRetention   time.Duration
 }
 
 // New creates a new Scheduler instance
@@ -127,10 +188,20 @@ func New(db *sql.DB) *Scheduler {
 		executors:  make(map[TaskType]TaskExecutor),
 		tasks:      make(map[string]cron.EntryID),
 		eventTasks: make(map[string]map[string]*ScheduledTask),
+		dependents: make(map[string]map[string]*ScheduledTask),
 		stopChan:   make(chan struct{}),
+		retries:    make(map[*time.Timer]string),
+		running:    make(map[string]int),
 	}
 }
 
+// SetRunHook registers fn to be called after every task run completes.
+func (s *Scheduler) SetRunHook(fn func(task *ScheduledTask, run *TaskRun)) {
+	s.mu.Lock()
+	s.runHook = fn
+	s.mu.Unlock()
+}
+
 // RegisterExecutor registers a task executor for a specific task type
 func (s *Scheduler) RegisterExecutor(taskType TaskType, executor TaskExecutor) {
 	s.executors[taskType] = executor
@@ -155,6 +226,8 @@ func (s *Scheduler) Start(ctx context.Context) error {
 		}
 	}
 
+	s.catchUpMissedRuns(tasks, time.Now())
+
 	log.Printf("Scheduler started with %d tasks", len(tasks))
 	return nil
 }
@@ -164,16 +237,44 @@ func (s *Scheduler) Stop() {
 	s.stopOnce.Do(func() {
 		close(s.stopChan)
 	})
+	s.cancelRetries("")
 	s.cron.Stop()
 	s.wg.Wait()
 	log.Println("Scheduler stopped")
 }
 
+// Pause stops new task runs from starting until Resume is called.
+// Runs already in progress are allowed to finish.
+func (s *Scheduler) Pause() {
+	s.mu.Lock()
+	s.paused = true
+	s.mu.Unlock()
+	log.Println("Scheduler paused")
+}
+
+// Resume allows task runs to start again after Pause.
+func (s *Scheduler) Resume() {
+	s.mu.Lock()
+	s.paused = false
+	deferred := s.deferredCatchUp
+	s.deferredCatchUp = nil
+	s.mu.Unlock()
+	log.Println("Scheduler resumed")
+	s.runDeferredCatchUps(deferred)
+}
+
+// IsPaused reports whether the scheduler is paused.
+func (s *Scheduler) IsPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.paused
+}
+
 // run is the main scheduler loop
 func (s *Scheduler) run(ctx context.Context) {
 	defer s.wg.Done()
 
-	// Periodic task refresh (every 5 minutes)
+	// Periodic task refresh and run history pruning (every 5 minutes)
 	refreshTicker := time.NewTicker(5 * time.Minute)
 	defer refreshTicker.Stop()
 
@@ -185,6 +286,7 @@ func (s *Scheduler) run(ctx context.Context) {
 			return
 		case <-refreshTicker.C:
 			s.refreshTasks()
+			s.pruneRuns()
 		}
 	}
 }
@@ -255,13 +357,7 @@ func (s *Scheduler) isTaskScheduled(taskID string) bool {
 
 // loadEnabledTasks loads all enabled tasks from the database
 func (s *Scheduler) loadEnabledTasks() ([]*ScheduledTask, error) {
-	rows, err := s.db.Query(`
-		SELECT id, name, description, cron_expression, task_type, payload,
-		       timezone, enabled, last_run_at, last_run_status, last_run_error,
-		       next_run_at, run_count, user_id, created_at, updated_at
-		FROM scheduled_tasks
-		WHERE enabled = 1
-	`)
+	rows, err := s.db.Query(`SELECT ` + taskColumns + ` FROM scheduled_tasks WHERE enabled = 1`)
 	if err != nil {
 		return nil, err
 	}
@@ -269,31 +365,51 @@ func (s *Scheduler) loadEnabledTasks() ([]*ScheduledTask, error) {
 
 	var tasks []*ScheduledTask
 	for rows.Next() {
-		task := &ScheduledTask{}
-		var lastRunStatus sql.NullString
-		var lastRunError sql.NullString
-		err := rows.Scan(
-			&task.ID, &task.Name, &task.Description, &task.CronExpression,
-			&task.TaskType, &task.Payload, &task.Timezone, &task.Enabled,
-			&task.LastRunAt, &lastRunStatus, &lastRunError,
-			&task.NextRunAt, &task.RunCount, &task.UserID,
-			&task.CreatedAt, &task.UpdatedAt,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
-		if lastRunStatus.Valid {
-			task.LastRunStatus = lastRunStatus.String
-		}
-		if lastRunError.Valid {
-			task.LastRunError = lastRunError.String
-		}
 		tasks = append(tasks, task)
 	}
 
 	return tasks, nil
 }
 
+// taskColumns lists the scheduled_tasks columns read by scanTask.
+const taskColumns = `id, name, description, cron_expression, task_type, payload,
+	timezone, enabled, last_run_at, last_run_status, last_run_error,
+	next_run_at, run_count, user_id, created_at, updated_at,
+	max_retries, retry_backoff, status, catch_up, depends_on`
+
+// scanTask reads a task selected with taskColumns.
+func scanTask(row interface{ Scan(...interface{}) error }) (*ScheduledTask, error) {
+	task := &ScheduledTask{}
+	var lastRunStatus sql.NullString
+	var lastRunError sql.NullString
+	var retryBackoff sql.NullString
+	var status sql.NullString
+	var dependsOn sql.NullString
+	err := row.Scan(
+		&task.ID, &task.Name, &task.Description, &task.CronExpression,
+		&task.TaskType, &task.Payload, &task.Timezone, &task.Enabled,
+		&task.LastRunAt, &lastRunStatus, &lastRunError,
+		&task.NextRunAt, &task.RunCount, &task.UserID,
+		&task.CreatedAt, &task.UpdatedAt,
+		&task.MaxRetries, &retryBackoff, &status, &task.CatchUp, &dependsOn,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if task.DependsOn, err = decodeDependsOn(dependsOn.String); err != nil {
+		return nil, fmt.Errorf("task %s depends_on: %w", task.ID, err)
+	}
+	task.LastRunStatus = lastRunStatus.String
+	task.LastRunError = lastRunError.String
+	task.RetryBackoff = retryBackoff.String
+	task.Status = TaskStatus(status.String)
+	return task, nil
+}
+
 // scheduleTask adds a task to the cron scheduler
 func (s *Scheduler) scheduleTask(task *ScheduledTask) error {
 	normalizedExpr, kind, eventName, err := normalizeTriggerExpression(task.CronExpression)
@@ -304,6 +420,7 @@ func (s *Scheduler) scheduleTask(task *ScheduledTask) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	task.CronExpression = normalizedExpr
+	s.registerDependentLocked(task)
 
 	if kind == triggerKindEvent {
 		if _, exists := s.eventTasks[eventName]; !exists {
@@ -314,13 +431,18 @@ func (s *Scheduler) scheduleTask(task *ScheduledTask) error {
 		return nil
 	}
 
+	// Rescheduling a task replaces its previous cron entry
+	if entryID, ok := s.tasks[task.ID]; ok {
+		s.cron.Remove(entryID)
+	}
+
 	// Create runner function
 	runner := func() {
-		s.executeTask(task)
+		s.executeTask(task, RunTriggerSchedule)
 	}
 
 	// Add to cron scheduler
-	entryID, err := s.cron.AddFunc(task.CronExpression, runner)
+	entryID, err := s.cron.AddFunc(cronSpec(task.CronExpression, task.Timezone), runner)
 	if err != nil {
 		return fmt.Errorf("failed to add cron job: %w", err)
 	}
@@ -351,35 +473,107 @@ func (s *Scheduler) removeTask(taskID string) {
 			log.Printf("Removed event task %s from event scheduler", taskID)
 		}
 	}
+	s.unregisterDependentLocked(taskID)
 }
 
 // executeTask runs a single scheduled task
-func (s *Scheduler) executeTask(task *ScheduledTask) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-	defer cancel()
+func (s *Scheduler) executeTask(task *ScheduledTask, trigger RunTrigger) {
+	s.executeAttempt(task, trigger, 1)
+}
 
-	// Create run record
-	runID := uuid.New().String()
+// executeAttempt runs attempt of task unless the scheduler is paused or, for
+// runs every instance fires, another instance holds the task's lock.
+func (s *Scheduler) executeAttempt(task *ScheduledTask, trigger RunTrigger, attempt int) {
+	if s.IsPaused() {
+		log.Printf("Scheduler paused, skipping task %s (%s)", task.ID, task.Name)
+		return
+	}
+	// Event and manual runs arrive at a single instance; only cron firings and
+	// catch-up runs on start are duplicated across instances sharing a database.
+	if lc := s.lockSettings(); lc != nil && (trigger == RunTriggerSchedule || trigger == RunTriggerCatchUp) {
+		now := time.Now()
+		// Each retry claims its own slot just past the window it fires in, so
+		// the lock of the attempt it retries does not refuse it.
+		window := runWindow(task.CronExpression, now).Add(time.Duration(attempt-1) * time.Second)
+		acquired, err := s.acquireLock(lc, task.ID, window, now)
+		if err != nil {
+			log.Printf("Failed to acquire lock for task %s (%s), skipping: %v", task.ID, task.Name, err)
+			return
+		}
+		if !acquired {
+			log.Printf("Task %s (%s) is locked by another instance, skipping", task.ID, task.Name)
+			return
+		}
+		defer s.releaseLock(lc, task.ID, window)
+	}
+	s.runTask(task, trigger, attempt)
+}
+
+// runTask executes task once, records the run and returns it. A failed run is
+// retried later while attempt is within the task's MaxRetries.
+func (s *Scheduler) runTask(task *ScheduledTask, trigger RunTrigger, attempt int) *TaskRun {
+	s.mu.Lock()
+	s.running[task.ID]++
+	s.mu.Unlock()
+	run := s.startRun(task, trigger, attempt)
+	s.execute(task, run)
+	return run
+}
+
+// claimRun marks a manual run of task as in progress and records its start,
+// unless another run of the task is already in progress.
+func (s *Scheduler) claimRun(task *ScheduledTask) (*TaskRun, error) {
+	s.mu.Lock()
+	if s.running[task.ID] > 0 {
+		s.mu.Unlock()
+		return nil, ErrTaskRunning
+	}
+	s.running[task.ID]++
+	s.mu.Unlock()
+	return s.startRun(task, RunTriggerManual, 1), nil
+}
+
+// startRun creates and saves the record of a run that is starting.
+func (s *Scheduler) startRun(task *ScheduledTask, trigger RunTrigger, attempt int) *TaskRun {
 	run := &TaskRun{
-		ID:        runID,
+		ID:        uuid.New().String(),
 		TaskID:    task.ID,
 		StartedAt: time.Now(),
 		Status:    RunStatusRunning,
+		Trigger:   trigger,
+		Attempt:   attempt,
 	}
-
-	// Save run start
 	if err := s.saveRun(run); err != nil {
 		log.Printf("Failed to save run start: %v", err)
 	}
+	return run
+}
+
+// execute performs a started run, records its outcome and releases the task's
+// in-progress mark.
+func (s *Scheduler) execute(task *ScheduledTask, run *TaskRun) {
+	defer func() {
+		s.mu.Lock()
+		if s.running[task.ID]--; s.running[task.ID] <= 0 {
+			delete(s.running, task.ID)
+		}
+		s.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
 
 	// Get executor
 	s.mu.RLock()
 	executor, exists := s.executors[task.TaskType]
 	s.mu.RUnlock()
 	if !exists {
+		now := time.Now()
+		run.CompletedAt = &now
 		run.Status = RunStatusFailed
 		run.Error = fmt.Sprintf("no executor for task type: %s", task.TaskType)
 		s.completeRun(run, task)
+		s.retryIfFailed(task, run)
 		return
 	}
 
@@ -397,6 +591,55 @@ func (s *Scheduler) executeTask(task *ScheduledTask) {
 	}
 
 	s.completeRun(run, task)
+	s.retryIfFailed(task, run)
+}
+
+// RunNow executes a task once, immediately and synchronously, and returns the
+// run. The run is recorded with the manual trigger; the task's schedule is
+// left untouched and disabled tasks can be run too.
+func (s *Scheduler) RunNow(taskID string) (*TaskRun, error) {
+	task, err := s.manualRunTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.claimRun(task)
+	if err != nil {
+		return nil, err
+	}
+	s.execute(task, run)
+	return run, nil
+}
+
+// StartRun starts a manual run of a task in the background, like RunNow, and
+// returns the run as recorded at its start. Its ID can be looked up with
+// GetTaskRuns to follow the run to completion.
+func (s *Scheduler) StartRun(taskID string) (*TaskRun, error) {
+	task, err := s.manualRunTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.claimRun(task)
+	if err != nil {
+		return nil, err
+	}
+	started := *run
+	go s.execute(task, run)
+	return &started, nil
+}
+
+// manualRunTask loads a task for a manual run.
+func (s *Scheduler) manualRunTask(taskID string) (*ScheduledTask, error) {
+	if s.IsPaused() {
+		return nil, ErrSchedulerPaused
+	}
+	task, err := s.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+	return task, nil
 }
 
 // completeRun updates the task and run records after execution
@@ -408,28 +651,42 @@ func (s *Scheduler) completeRun(run *TaskRun, task *ScheduledTask) {
 
 	// Update task status
 	now := time.Now()
-	nextRun := s.getNextRunTime(task.CronExpression)
+	nextRun := s.getNextRunTime(task.CronExpression, task.Timezone)
+	switch {
+	case run.Status == RunStatusSuccess:
+		task.Status = TaskStatusActive
+	case run.RetriesExhausted(task):
+		task.Status = TaskStatusError
+	}
 
 	_, err := s.db.Exec(`
 		UPDATE scheduled_tasks
 		SET last_run_at = ?, last_run_status = ?, last_run_error = ?,
-		    next_run_at = ?, run_count = run_count + 1, updated_at = ?
+		    next_run_at = ?, run_count = run_count + 1, updated_at = ?,
+		    status = COALESCE(NULLIF(?, ''), status)
 		WHERE id = ?
 	`,
 		run.StartedAt, run.Status, run.Error,
-		nextRun, now, task.ID,
+		nextRun, now, task.Status, task.ID,
 	)
 
 	if err != nil {
 		log.Printf("Failed to update task: %v", err)
 	}
+
+	s.mu.RLock()
+	hook := s.runHook
+	s.mu.RUnlock()
+	if hook != nil {
+		hook(task, run)
+	}
 }
 
 // saveRun saves a task run record
 func (s *Scheduler) saveRun(run *TaskRun) error {
 	_, err := s.db.Exec(`
-		INSERT INTO scheduled_task_runs (id, task_id, started_at, completed_at, status, error, output)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO scheduled_task_runs (id, task_id, started_at, completed_at, status, run_trigger, error, output, attempt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			started_at = excluded.started_at,
 			completed_at = excluded.completed_at,
@@ -437,14 +694,14 @@ func (s *Scheduler) saveRun(run *TaskRun) error {
 			error = excluded.error,
 			output = excluded.output
 	`,
-		run.ID, run.TaskID, run.StartedAt, run.CompletedAt, run.Status, run.Error, run.Output,
+		run.ID, run.TaskID, run.StartedAt, run.CompletedAt, run.Status, run.Trigger, run.Error, run.Output, run.Attempt,
 	)
 	return err
 }
 
-// getNextRunTime calculates the next run time from a cron expression
-func (s *Scheduler) getNextRunTime(cronExpr string) *time.Time {
-	nextRuns, err := PreviewNextRuns(cronExpr, 1)
+// getNextRunTime calculates the next run time from a cron expression in timezone
+func (s *Scheduler) getNextRunTime(cronExpr, timezone string) *time.Time {
+	nextRuns, err := PreviewNextRunsIn(cronExpr, timezone, 1)
 	if err != nil {
 		return nil
 	}
@@ -463,28 +720,48 @@ func (s *Scheduler) CreateTask(task *ScheduledTask) error {
 	}
 	task.CronExpression = normalizedExpr
 
+	if strings.TrimSpace(task.Timezone) == "" {
+		task.Timezone = s.DefaultTimezone()
+	}
+	if err := config.ValidateTimezone(task.Timezone); err != nil {
+		return err
+	}
+
+	if err := ValidateRetryPolicy(task.MaxRetries, task.RetryBackoff); err != nil {
+		return err
+	}
+
 	if task.ID == "" {
 		task.ID = uuid.New().String()
 	}
+	if err := s.validateDependencies(task); err != nil {
+		return err
+	}
+	if task.Status == "" {
+		task.Status = TaskStatusActive
+	}
 
 	now := time.Now()
 	task.CreatedAt = now
 	task.UpdatedAt = now
 
 	// Calculate next run time
-	nextRun := s.getNextRunTime(task.CronExpression)
+	nextRun := s.getNextRunTime(task.CronExpression, task.Timezone)
 	task.NextRunAt = nextRun
 
 	// Insert into database
 	_, err = s.db.Exec(`
 		INSERT INTO scheduled_tasks (
 			id, name, description, cron_expression, task_type, payload,
-			timezone, enabled, next_run_at, run_count, user_id, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			timezone, enabled, next_run_at, run_count, user_id, created_at, updated_at,
+			max_retries, retry_backoff, status, catch_up, depends_on
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		task.ID, task.Name, task.Description, task.CronExpression,
 		task.TaskType, task.Payload, task.Timezone, task.Enabled,
 		task.NextRunAt, task.RunCount, task.UserID, task.CreatedAt, task.UpdatedAt,
+		task.MaxRetries, task.RetryBackoff, task.Status, task.CatchUp,
+		encodeDependsOn(task.DependsOn),
 	)
 	if err != nil {
 		return err
@@ -502,33 +779,13 @@ func (s *Scheduler) CreateTask(task *ScheduledTask) error {
 
 // GetTask retrieves a task by ID
 func (s *Scheduler) GetTask(id string) (*ScheduledTask, error) {
-	task := &ScheduledTask{}
-	var lastRunStatus sql.NullString
-	var lastRunError sql.NullString
-	err := s.db.QueryRow(`
-		SELECT id, name, description, cron_expression, task_type, payload,
-		       timezone, enabled, last_run_at, last_run_status, last_run_error,
-		       next_run_at, run_count, user_id, created_at, updated_at
-		FROM scheduled_tasks WHERE id = ?
-	`, id).Scan(
-		&task.ID, &task.Name, &task.Description, &task.CronExpression,
-		&task.TaskType, &task.Payload, &task.Timezone, &task.Enabled,
-		&task.LastRunAt, &lastRunStatus, &lastRunError,
-		&task.NextRunAt, &task.RunCount, &task.UserID,
-		&task.CreatedAt, &task.UpdatedAt,
-	)
+	task, err := scanTask(s.db.QueryRow(`SELECT `+taskColumns+` FROM scheduled_tasks WHERE id = ?`, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	if lastRunStatus.Valid {
-		task.LastRunStatus = lastRunStatus.String
-	}
-	if lastRunError.Valid {
-		task.LastRunError = lastRunError.String
-	}
 	return task, nil
 }
 
@@ -538,15 +795,9 @@ func (s *Scheduler) ListTasks(userID string) ([]*ScheduledTask, error) {
 	var args []interface{}
 
 	if userID == "" {
-		query = `SELECT id, name, description, cron_expression, task_type, payload,
-		        timezone, enabled, last_run_at, last_run_status, last_run_error,
-		        next_run_at, run_count, user_id, created_at, updated_at
-		    FROM scheduled_tasks ORDER BY created_at DESC`
+		query = `SELECT ` + taskColumns + ` FROM scheduled_tasks ORDER BY created_at DESC`
 	} else {
-		query = `SELECT id, name, description, cron_expression, task_type, payload,
-		        timezone, enabled, last_run_at, last_run_status, last_run_error,
-		        next_run_at, run_count, user_id, created_at, updated_at
-		    FROM scheduled_tasks WHERE user_id = ? ORDER BY created_at DESC`
+		query = `SELECT ` + taskColumns + ` FROM scheduled_tasks WHERE user_id = ? ORDER BY created_at DESC`
 		args = []interface{}{userID}
 	}
 
@@ -558,25 +809,10 @@ func (s *Scheduler) ListTasks(userID string) ([]*ScheduledTask, error) {
 
 	var tasks []*ScheduledTask
 	for rows.Next() {
-		task := &ScheduledTask{}
-		var lastRunStatus sql.NullString
-		var lastRunError sql.NullString
-		err := rows.Scan(
-			&task.ID, &task.Name, &task.Description, &task.CronExpression,
-			&task.TaskType, &task.Payload, &task.Timezone, &task.Enabled,
-			&task.LastRunAt, &lastRunStatus, &lastRunError,
-			&task.NextRunAt, &task.RunCount, &task.UserID,
-			&task.CreatedAt, &task.UpdatedAt,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
-		if lastRunStatus.Valid {
-			task.LastRunStatus = lastRunStatus.String
-		}
-		if lastRunError.Valid {
-			task.LastRunError = lastRunError.String
-		}
 		tasks = append(tasks, task)
 	}
 
@@ -591,21 +827,36 @@ func (s *Scheduler) UpdateTask(task *ScheduledTask) error {
 	}
 	task.CronExpression = normalizedExpr
 
+	if strings.TrimSpace(task.Timezone) == "" {
+		task.Timezone = s.DefaultTimezone()
+	}
+	if err := config.ValidateTimezone(task.Timezone); err != nil {
+		return err
+	}
+	if err := ValidateRetryPolicy(task.MaxRetries, task.RetryBackoff); err != nil {
+		return err
+	}
+	if err := s.validateDependencies(task); err != nil {
+		return err
+	}
+
 	task.UpdatedAt = time.Now()
 
 	// Recalculate next run
-	nextRun := s.getNextRunTime(task.CronExpression)
+	nextRun := s.getNextRunTime(task.CronExpression, task.Timezone)
 	task.NextRunAt = nextRun
 
 	_, err = s.db.Exec(`
 		UPDATE scheduled_tasks
 		SET name = ?, description = ?, cron_expression = ?, task_type = ?,
-		    payload = ?, timezone = ?, enabled = ?, next_run_at = ?, updated_at = ?
+		    payload = ?, timezone = ?, enabled = ?, next_run_at = ?, updated_at = ?,
+		    max_retries = ?, retry_backoff = ?, catch_up = ?, depends_on = ?
 		WHERE id = ?
 	`,
 		task.Name, task.Description, task.CronExpression, task.TaskType,
 		task.Payload, task.Timezone, task.Enabled, task.NextRunAt,
-		task.UpdatedAt, task.ID,
+		task.UpdatedAt, task.MaxRetries, task.RetryBackoff, task.CatchUp,
+		encodeDependsOn(task.DependsOn), task.ID,
 	)
 	if err != nil {
 		return err
@@ -622,9 +873,10 @@ func (s *Scheduler) UpdateTask(task *ScheduledTask) error {
 	return nil
 }
 
-// DeleteTask deletes a task and its runs
+// DeleteTask deletes a task, its runs and its run lock
 func (s *Scheduler) DeleteTask(id string) error {
 	s.removeTask(id)
+	s.cancelRetries(id)
 
 	// Delete runs first (foreign key constraint)
 	_, err := s.db.Exec("DELETE FROM scheduled_task_runs WHERE task_id = ?", id)
@@ -632,6 +884,10 @@ func (s *Scheduler) DeleteTask(id string) error {
 		return err
 	}
 
+	if _, err := s.db.Exec("DELETE FROM scheduler_locks WHERE task_id = ?", id); err != nil {
+		return err
+	}
+
 	_, err = s.db.Exec("DELETE FROM scheduled_tasks WHERE id = ?", id)
 	return err
 }
@@ -658,6 +914,7 @@ func (s *Scheduler) EnableTask(id string) error {
 // DisableTask disables an active task
 func (s *Scheduler) DisableTask(id string) error {
 	s.removeTask(id)
+	s.cancelRetries(id)
 
 	_, err := s.db.Exec("UPDATE scheduled_tasks SET enabled = 0, updated_at = ? WHERE id = ?",
 		time.Now(), id)
@@ -667,7 +924,7 @@ func (s *Scheduler) DisableTask(id string) error {
 // GetTaskRuns returns the execution history for a task
 func (s *Scheduler) GetTaskRuns(taskID string, limit int) ([]*TaskRun, error) {
 	rows, err := s.db.Query(`
-		SELECT id, task_id, started_at, completed_at, status, error, output
+		SELECT id, task_id, started_at, completed_at, status, COALESCE(run_trigger, 'schedule'), error, output, attempt
 		FROM scheduled_task_runs
 		WHERE task_id = ?
 		ORDER BY started_at DESC
@@ -683,7 +940,7 @@ func (s *Scheduler) GetTaskRuns(taskID string, limit int) ([]*TaskRun, error) {
 		run := &TaskRun{}
 		err := rows.Scan(
 			&run.ID, &run.TaskID, &run.StartedAt, &run.CompletedAt,
-			&run.Status, &run.Error, &run.Output,
+			&run.Status, &run.Trigger, &run.Error, &run.Output, &run.Attempt,
 		)
 		if err != nil {
 			return nil, err
@@ -702,9 +959,16 @@ func ValidateCronExpression(expr string) error {
 
 // TriggerEvent executes all event-based tasks for the given event name.
 func (s *Scheduler) TriggerEvent(eventName string) (int, error) {
-	eventName = strings.ToLower(strings.TrimSpace(eventName))
-	if eventName == "" {
-		return 0, fmt.Errorf("event name is required")
+	return s.TriggerEventWithPayload(eventName, nil)
+}
+
+// TriggerEventWithPayload fires the tasks registered for eventName. A non-nil
+// payload is passed to each task under the "event" key of its payload object,
+// alongside the event name. It returns the number of tasks triggered.
+func (s *Scheduler) TriggerEventWithPayload(eventName string, payload map[string]interface{}) (int, error) {
+	eventName, err := ValidateEventName(eventName)
+	if err != nil {
+		return 0, err
 	}
 
 	s.mu.RLock()
@@ -716,14 +980,56 @@ func (s *Scheduler) TriggerEvent(eventName string) (int, error) {
 	s.mu.RUnlock()
 
 	for _, task := range tasks {
-		go s.executeTask(task)
+		if payload != nil {
+			task = withEventPayload(task, eventName, payload)
+		}
+		go s.executeTask(task, RunTriggerEvent)
 	}
 
 	return len(tasks), nil
 }
 
+// ValidateEventName normalizes an event name and checks it against the
+// pattern accepted in event: schedules.
+func ValidateEventName(eventName string) (string, error) {
+	eventName = strings.ToLower(strings.TrimSpace(eventName))
+	if eventName == "" {
+		return "", fmt.Errorf("event name is required")
+	}
+	if !eventNamePattern.MatchString(eventName) {
+		return "", fmt.Errorf("invalid event name: %s", eventName)
+	}
+	return eventName, nil
+}
+
+// withEventPayload returns a copy of task whose payload carries the event.
+// Tasks with a non-object payload are returned unchanged.
+func withEventPayload(task *ScheduledTask, eventName string, payload map[string]interface{}) *ScheduledTask {
+	fields := map[string]interface{}{}
+	if strings.TrimSpace(task.Payload) != "" {
+		if err := json.Unmarshal([]byte(task.Payload), &fields); err != nil {
+			log.Printf("Task %s (%s) payload is not an object, event payload dropped", task.ID, task.Name)
+			return task
+		}
+	}
+	fields["event"] = map[string]interface{}{"name": eventName, "payload": payload}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return task
+	}
+	copied := *task
+	copied.Payload = string(data)
+	return &copied
+}
+
 // PreviewNextRuns returns next run timestamps for a schedule expression.
 func PreviewNextRuns(expr string, count int) ([]time.Time, error) {
+	return PreviewNextRunsIn(expr, "", count)
+}
+
+// PreviewNextRunsIn returns next run timestamps for a schedule expression whose
+// cron fields are read in timezone (the server's local zone when empty).
+func PreviewNextRunsIn(expr, timezone string, count int) ([]time.Time, error) {
 	if count <= 0 {
 		count = 1
 	}
@@ -737,7 +1043,10 @@ func PreviewNextRuns(expr string, count int) ([]time.Time, error) {
 		return []time.Time{}, nil
 	}
 
-	schedule, err := scheduleParser.Parse(normalizedExpr)
+	if err := config.ValidateTimezone(timezone); err != nil {
+		return nil, err
+	}
+	schedule, err := scheduleParser.Parse(cronSpec(normalizedExpr, timezone))
 	if err != nil {
 		return nil, fmt.Errorf("invalid schedule expression: %w", err)
 	}
@@ -753,6 +1062,17 @@ func PreviewNextRuns(expr string, count int) ([]time.Time, error) {
 	return nextRuns, nil
 }
 
+// NormalizeSchedule returns the form a schedule expression is stored and parsed
+// in (e.g. "every 5 minutes" becomes "@every 5m0s") and its kind, "cron" or
+// "event".
+func NormalizeSchedule(expr string) (string, string, error) {
+	normalized, kind, _, err := normalizeTriggerExpression(expr)
+	if err != nil {
+		return "", "", err
+	}
+	return normalized, string(kind), nil
+}
+
 func normalizeTriggerExpression(expr string) (string, triggerKind, string, error) {
 	trimmedExpr := strings.TrimSpace(expr)
 	if trimmedExpr == "" {
@@ -155,6 +155,52 @@ func TestTriggerEventExecutesEventTasks(t *testing.T) {
 	t.Fatal("expected successful run record for triggered event task")
 }
 
+func TestPausedSchedulerSkipsRuns(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	exec := &testExecutor{ch: make(chan *ScheduledTask, 1)}
+	s.RegisterExecutor(TaskTypeMessage, exec)
+
+	task := &ScheduledTask{
+		Name:           "event-task",
+		CronExpression: "event:user.login",
+		TaskType:       TaskTypeMessage,
+		Enabled:        true,
+	}
+	if err := s.CreateTask(task); err != nil {
+		t.Fatalf("failed to create event task: %v", err)
+	}
+
+	s.Pause()
+	if !s.IsPaused() {
+		t.Fatal("expected scheduler to report paused")
+	}
+	if _, err := s.TriggerEvent("user.login"); err != nil {
+		t.Fatalf("failed to trigger event: %v", err)
+	}
+
+	select {
+	case <-exec.ch:
+		t.Fatal("expected paused scheduler not to execute tasks")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s.Resume()
+	if _, err := s.TriggerEvent("user.login"); err != nil {
+		t.Fatalf("failed to trigger event: %v", err)
+	}
+	select {
+	case <-exec.ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for task execution after resume")
+	}
+}
+
 func TestSchedulerLoadsEnabledTasksOnStart(t *testing.T) {
 	st, err := store.New(":memory:")
 	if err != nil {
@@ -199,3 +245,116 @@ func TestSchedulerLoadsEnabledTasksOnStart(t *testing.T) {
 		t.Fatal("expected at least one persisted task run after scheduler start")
 	}
 }
+
+func TestRunNowRecordsManualRun(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	s.RegisterExecutor(TaskTypeMessage, &testExecutor{})
+
+	task := &ScheduledTask{
+		Name:           "daily",
+		CronExpression: "0 9 * * *",
+		TaskType:       TaskTypeMessage,
+		Enabled:        true,
+	}
+	if err := s.CreateTask(task); err != nil {
+		t.Fatalf("failed to create task: %v", err)
+	}
+	before, err := s.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+
+	run, err := s.RunNow(task.ID)
+	if err != nil {
+		t.Fatalf("RunNow failed: %v", err)
+	}
+	if run.Status != RunStatusSuccess || run.Output != "ok" || run.Trigger != RunTriggerManual {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+
+	runs, err := s.GetTaskRuns(task.ID, 10)
+	if err != nil {
+		t.Fatalf("failed to get runs: %v", err)
+	}
+	if len(runs) != 1 || runs[0].ID != run.ID || runs[0].Trigger != RunTriggerManual {
+		t.Fatalf("expected recorded manual run, got %+v", runs)
+	}
+
+	after, err := s.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("failed to get task: %v", err)
+	}
+	if before.NextRunAt == nil || after.NextRunAt == nil || !after.NextRunAt.Equal(*before.NextRunAt) {
+		t.Fatalf("expected next run to be unchanged, before %v after %v", before.NextRunAt, after.NextRunAt)
+	}
+
+	if _, err := s.RunNow("missing"); err != ErrTaskNotFound {
+		t.Fatalf("expected ErrTaskNotFound, got %v", err)
+	}
+	s.Pause()
+	if _, err := s.RunNow(task.ID); err != ErrSchedulerPaused {
+		t.Fatalf("expected ErrSchedulerPaused, got %v", err)
+	}
+}
+
+func TestTriggerEventWithPayload(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	exec := &testExecutor{ch: make(chan *ScheduledTask, 1)}
+	s.RegisterExecutor(TaskTypeMessage, exec)
+
+	task := &ScheduledTask{
+		Name:           "deploy-notice",
+		CronExpression: "event:ci.deploy",
+		TaskType:       TaskTypeMessage,
+		Payload:        `{"content":"deployed"}`,
+		Enabled:        true,
+	}
+	if err := s.CreateTask(task); err != nil {
+		t.Fatalf("failed to create event task: %v", err)
+	}
+
+	if _, err := s.TriggerEventWithPayload("bad name!", nil); err == nil {
+		t.Fatal("expected invalid event name to be rejected")
+	}
+
+	triggered, err := s.TriggerEventWithPayload("CI.Deploy", map[string]interface{}{"ref": "main"})
+	if err != nil || triggered != 1 {
+		t.Fatalf("TriggerEventWithPayload = %d, %v", triggered, err)
+	}
+
+	var got *ScheduledTask
+	select {
+	case got = <-exec.ch:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for event task execution")
+	}
+
+	var payload struct {
+		Content string `json:"content"`
+		Event   struct {
+			Name    string            `json:"name"`
+			Payload map[string]string `json:"payload"`
+		} `json:"event"`
+	}
+	if err := ParseTaskPayload(got.Payload, &payload); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if payload.Content != "deployed" || payload.Event.Name != "ci.deploy" || payload.Event.Payload["ref"] != "main" {
+		t.Fatalf("unexpected payload: %s", got.Payload)
+	}
+	if task.Payload != `{"content":"deployed"}` {
+		t.Fatalf("stored task payload changed: %s", task.Payload)
+	}
+}
@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"strings"
+
+	"pryx-core/internal/config"
+)
+
+// SetDefaultTimezone sets the IANA zone given to new tasks that do not name
+// one. An empty name keeps the server's local zone.
+func (s *Scheduler) SetDefaultTimezone(name string) error {
+	name = strings.TrimSpace(name)
+	if err := config.ValidateTimezone(name); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.defaultTimezone = name
+	s.mu.Unlock()
+	return nil
+}
+
+// DefaultTimezone returns the zone given to new tasks without one.
+func (s *Scheduler) DefaultTimezone() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.defaultTimezone
+}
+
+// cronSpec returns expr evaluated in timezone. Cron fields are interpreted in
+// that zone; intervals and event triggers are unaffected.
+func cronSpec(expr, timezone string) string {
+	timezone = strings.TrimSpace(timezone)
+	if timezone == "" || strings.HasPrefix(expr, "@every ") || strings.HasPrefix(expr, eventTriggerPrefix) ||
+		strings.HasPrefix(expr, "TZ=") || strings.HasPrefix(expr, "CRON_TZ=") {
+		return expr
+	}
+	return "CRON_TZ=" + timezone + " " + expr
+}
@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"pryx-core/internal/store"
+)
+
+func TestCreateTaskUsesDefaultTimezone(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	if err := s.SetDefaultTimezone("Nowhere/Zone"); err == nil {
+		t.Fatal("expected invalid default timezone to be rejected")
+	}
+	if err := s.SetDefaultTimezone("Asia/Tokyo"); err != nil {
+		t.Fatalf("SetDefaultTimezone: %v", err)
+	}
+
+	task := &ScheduledTask{Name: "morning", CronExpression: "0 9 * * *", TaskType: TaskTypeReminder}
+	if err := s.CreateTask(task); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if task.Timezone != "Asia/Tokyo" {
+		t.Fatalf("expected default timezone, got %q", task.Timezone)
+	}
+	next := task.NextRunAt.In(mustLoad(t, "Asia/Tokyo"))
+	if next.Hour() != 9 || next.Minute() != 0 {
+		t.Fatalf("expected next run at 09:00 Tokyo time, got %s", next)
+	}
+
+	explicit := &ScheduledTask{Name: "ny", CronExpression: "0 9 * * *", TaskType: TaskTypeReminder, Timezone: "America/New_York"}
+	if err := s.CreateTask(explicit); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if next := explicit.NextRunAt.In(mustLoad(t, "America/New_York")); next.Hour() != 9 {
+		t.Fatalf("expected next run at 09:00 New York time, got %s", next)
+	}
+
+	bad := &ScheduledTask{Name: "bad", CronExpression: "0 9 * * *", TaskType: TaskTypeReminder, Timezone: "Nowhere/Zone"}
+	if err := s.CreateTask(bad); err == nil {
+		t.Fatal("expected invalid task timezone to be rejected")
+	}
+	explicit.Timezone = "Nowhere/Zone"
+	if err := s.UpdateTask(explicit); err == nil {
+		t.Fatal("expected invalid timezone on update to be rejected")
+	}
+
+	explicit.Timezone = ""
+	if err := s.UpdateTask(explicit); err != nil {
+		t.Fatalf("UpdateTask: %v", err)
+	}
+	if explicit.Timezone != "Asia/Tokyo" {
+		t.Fatalf("expected a cleared timezone to fall back to the default, got %q", explicit.Timezone)
+	}
+	if next := explicit.NextRunAt.In(mustLoad(t, "Asia/Tokyo")); next.Hour() != 9 {
+		t.Fatalf("expected next run at 09:00 Tokyo time, got %s", next)
+	}
+}
+
+func TestScheduledEntryFiresInTaskTimezone(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer st.Close()
+
+	s := New(st.DB)
+	task := &ScheduledTask{Name: "ny", CronExpression: "0 9 * * *", TaskType: TaskTypeReminder, Timezone: "America/New_York", Enabled: true}
+	if err := s.CreateTask(task); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+
+	s.mu.RLock()
+	entryID, ok := s.tasks[task.ID]
+	s.mu.RUnlock()
+	if !ok {
+		t.Fatal("expected task to be registered with cron")
+	}
+	now := time.Now()
+	next := s.cron.Entry(entryID).Schedule.Next(now)
+	if local := next.In(mustLoad(t, "America/New_York")); local.Hour() != 9 || local.Minute() != 0 {
+		t.Fatalf("expected cron entry to fire at 09:00 New York time, got %s", local)
+	}
+
+	preview, err := PreviewNextRunsIn(task.CronExpression, task.Timezone, 1)
+	if err != nil {
+		t.Fatalf("PreviewNextRunsIn: %v", err)
+	}
+	if !preview[0].Equal(next) {
+		t.Fatalf("preview %s does not match cron entry %s", preview[0], next)
+	}
+}
+
+func TestCronSpec(t *testing.T) {
+	tests := map[[2]string]string{
+		{"0 9 * * *", ""}:                       "0 9 * * *",
+		{"0 9 * * *", "UTC"}:                    "CRON_TZ=UTC 0 9 * * *",
+		{"@daily", "Europe/Paris"}:              "CRON_TZ=Europe/Paris @daily",
+		{"@every 1m0s", "UTC"}:                  "@every 1m0s",
+		{"event:deploy", "UTC"}:                 "event:deploy",
+		{"CRON_TZ=UTC 0 9 * * *", "Asia/Tokyo"}: "CRON_TZ=UTC 0 9 * * *",
+	}
+	for in, want := range tests {
+		if got := cronSpec(in[0], in[1]); got != want {
+			t.Errorf("cronSpec(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%s): %v", name, err)
+	}
+	return loc
+}
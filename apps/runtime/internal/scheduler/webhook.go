@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultWebhookTimeout bounds a webhook call whose payload sets no timeout.
+	DefaultWebhookTimeout = 30 * time.Second
+	// MaxWebhookTimeout caps timeout_sec in webhook payloads.
+	MaxWebhookTimeout = 5 * time.Minute
+	// MaxWebhookOutput caps how much of the response body is kept in the run output.
+	MaxWebhookOutput = 4096
+)
+
+// WebhookPayload is the payload of a TaskTypeWebhook task. Body may be a JSON
+// string, sent as is, or any other JSON value, sent as JSON.
+type WebhookPayload struct {
+	URL        string            `json:"url"`
+	Method     string            `json:"method,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       json.RawMessage   `json:"body,omitempty"`
+	TimeoutSec int               `json:"timeout_sec,omitempty"`
+}
+
+// ParseWebhookPayload parses and validates a webhook task payload. The URL must
+// be absolute http or https; the method defaults to POST.
+func ParseWebhookPayload(payload string) (*WebhookPayload, error) {
+	var p WebhookPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	u, err := url.Parse(strings.TrimSpace(p.URL))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook url %q", p.URL)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("webhook url must use http or https, got %q", u.Scheme)
+	}
+	p.URL = u.String()
+	p.Method = strings.ToUpper(strings.TrimSpace(p.Method))
+	if p.Method == "" {
+		p.Method = http.MethodPost
+	}
+	if p.TimeoutSec < 0 {
+		return nil, fmt.Errorf("timeout_sec must not be negative")
+	}
+	return &p, nil
+}
+
+// timeout returns the call timeout: TimeoutSec, or the default, capped at MaxWebhookTimeout.
+func (p *WebhookPayload) timeout() time.Duration {
+	if p.TimeoutSec <= 0 {
+		return DefaultWebhookTimeout
+	}
+	if d := time.Duration(p.TimeoutSec) * time.Second; d < MaxWebhookTimeout {
+		return d
+	}
+	return MaxWebhookTimeout
+}
+
+// body returns the request body and whether it is JSON.
+func (p *WebhookPayload) body() ([]byte, bool) {
+	raw := bytes.TrimSpace(p.Body)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, false
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []byte(s), false
+	}
+	return raw, true
+}
+
+// WebhookExecutor runs TaskTypeWebhook tasks by calling the URL in the task
+// payload. The run output is the response status and the start of the body;
+// non-2xx responses fail the run so retries apply.
+type WebhookExecutor struct {
+	client *http.Client
+}
+
+// NewWebhookExecutor returns an executor that sends requests with client, or
+// with a default client when client is nil.
+func NewWebhookExecutor(client *http.Client) *WebhookExecutor {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &WebhookExecutor{client: client}
+}
+
+// Execute calls the task's webhook.
+func (e *WebhookExecutor) Execute(ctx context.Context, task *ScheduledTask) (string, error) {
+	p, err := ParseWebhookPayload(task.Payload)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout())
+	defer cancel()
+
+	body, isJSON := p.body()
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, p.Method, p.URL, reader)
+	if err != nil {
+		return "", fmt.Errorf("invalid webhook request: %w", err)
+	}
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "pryx-scheduler")
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, MaxWebhookOutput+1))
+	text := string(data)
+	if len(data) > MaxWebhookOutput {
+		text = string(data[:MaxWebhookOutput]) + "... (truncated)"
+	}
+	output := resp.Status
+	if text != "" {
+		output += "\n" + text
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("webhook returned %s", output)
+	}
+	return output, nil
+}
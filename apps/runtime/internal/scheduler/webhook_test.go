@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookExecutorSendsRequest(t *testing.T) {
+	var gotMethod, gotBody, gotHeader, gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotHeader = r.Header.Get("X-Token")
+		gotType = r.Header.Get("Content-Type")
+		w.Write([]byte(strings.Repeat("a", MaxWebhookOutput+100)))
+	}))
+	defer server.Close()
+
+	task := &ScheduledTask{
+		TaskType: TaskTypeWebhook,
+		Payload:  `{"url":"` + server.URL + `/hook","method":"put","headers":{"X-Token":"abc"},"body":{"ping":true}}`,
+	}
+	output, err := NewWebhookExecutor(nil).Execute(context.Background(), task)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if gotMethod != http.MethodPut || gotBody != `{"ping":true}` || gotHeader != "abc" || gotType != "application/json" {
+		t.Fatalf("unexpected request: method %s body %s header %s type %s", gotMethod, gotBody, gotHeader, gotType)
+	}
+	if !strings.HasPrefix(output, "200 OK\n") || !strings.HasSuffix(output, "(truncated)") {
+		t.Fatalf("unexpected output prefix/suffix: %.40q", output)
+	}
+	if len(output) > MaxWebhookOutput+100 {
+		t.Fatalf("output not capped: %d bytes", len(output))
+	}
+}
+
+func TestWebhookExecutorFailsOnNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	task := &ScheduledTask{TaskType: TaskTypeWebhook, Payload: `{"url":"` + server.URL + `","body":"hello"}`}
+	_, err := NewWebhookExecutor(nil).Execute(context.Background(), task)
+	if err == nil || !strings.Contains(err.Error(), "503") || !strings.Contains(err.Error(), "maintenance") {
+		t.Fatalf("expected 503 error with body, got %v", err)
+	}
+}
+
+func TestParseWebhookPayload(t *testing.T) {
+	p, err := ParseWebhookPayload(`{"url":"https://example.com/x"}`)
+	if err != nil {
+		t.Fatalf("ParseWebhookPayload: %v", err)
+	}
+	if p.Method != http.MethodPost || p.timeout() != DefaultWebhookTimeout {
+		t.Fatalf("unexpected defaults: %+v", p)
+	}
+	if p, _ := ParseWebhookPayload(`{"url":"https://example.com","timeout_sec":100000}`); p.timeout() != MaxWebhookTimeout {
+		t.Fatalf("timeout not capped: %s", p.timeout())
+	}
+
+	for _, payload := range []string{
+		``,
+		`{"url":"ftp://example.com/file"}`,
+		`{"url":"file:///etc/passwd"}`,
+		`{"url":"/relative"}`,
+		`{"url":"https://example.com","timeout_sec":-1}`,
+	} {
+		if _, err := ParseWebhookPayload(payload); err == nil {
+			t.Errorf("ParseWebhookPayload(%q) = nil error, want error", payload)
+		}
+	}
+}
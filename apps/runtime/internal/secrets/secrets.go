@@ -0,0 +1,151 @@
+// Package secrets resolves secret references so configuration never has to hold
+// plaintext credentials.
+//
+// A reference names where the secret lives:
+//
+//	keychain://name   an entry in the OS keychain (legacy form: keychain:name)
+//	env://VAR         an environment variable
+//	file:///path      the trimmed contents of a file, e.g. a mounted container secret
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Reference schemes.
+const (
+	SchemeKeychain = "keychain"
+	SchemeEnv      = "env"
+	SchemeFile     = "file"
+)
+
+var (
+	// ErrInvalidRef is returned for references with an unknown scheme or empty target.
+	ErrInvalidRef = errors.New("invalid secret reference")
+	// ErrNotFound is returned when a reference points at a missing or empty secret.
+	ErrNotFound = errors.New("secret not found")
+)
+
+// Getter reads keychain entries. *keychain.Keychain satisfies it.
+type Getter interface {
+	Get(user string) (string, error)
+}
+
+// Ref is a parsed secret reference.
+type Ref struct {
+	Scheme string
+	Target string
+}
+
+func (r Ref) String() string {
+	return r.Scheme + "://" + r.Target
+}
+
+// IsRef reports whether s uses one of the reference schemes.
+func IsRef(s string) bool {
+	_, err := Parse(s)
+	return err == nil
+}
+
+// Parse parses a reference. The legacy keychain:name form is accepted.
+func Parse(s string) (Ref, error) {
+	s = strings.TrimSpace(s)
+	scheme, target, ok := strings.Cut(s, "://")
+	if !ok {
+		if rest, legacy := strings.CutPrefix(s, SchemeKeychain+":"); legacy {
+			scheme, target = SchemeKeychain, rest
+		} else {
+			return Ref{}, fmt.Errorf("%w: %q has no scheme", ErrInvalidRef, s)
+		}
+	}
+	switch scheme {
+	case SchemeKeychain, SchemeEnv:
+	case SchemeFile:
+		// file:///etc/secret keeps its leading slash; file://relative/path is allowed too.
+	default:
+		return Ref{}, fmt.Errorf("%w: unsupported scheme %q", ErrInvalidRef, scheme)
+	}
+	if strings.TrimSpace(target) == "" {
+		return Ref{}, fmt.Errorf("%w: %q has no target", ErrInvalidRef, s)
+	}
+	return Ref{Scheme: scheme, Target: target}, nil
+}
+
+// Resolver looks up the secrets that references point at.
+type Resolver struct {
+	keychain  Getter
+	lookupEnv func(string) (string, bool)
+	readFile  func(string) ([]byte, error)
+}
+
+// NewResolver creates a resolver. kc may be nil, in which case keychain
+// references cannot be resolved.
+func NewResolver(kc Getter) *Resolver {
+	return &Resolver{keychain: kc, lookupEnv: os.LookupEnv, readFile: os.ReadFile}
+}
+
+// Resolve returns the secret a reference points at.
+func (r *Resolver) Resolve(ref string) (string, error) {
+	parsed, err := Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	var value string
+	switch parsed.Scheme {
+	case SchemeKeychain:
+		if r.keychain == nil {
+			return "", fmt.Errorf("%w: keychain not available for %s", ErrNotFound, parsed)
+		}
+		value, err = r.keychain.Get(parsed.Target)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrNotFound, parsed, err)
+		}
+	case SchemeEnv:
+		v, ok := r.lookupEnv(parsed.Target)
+		if !ok {
+			return "", fmt.Errorf("%w: %s is not set", ErrNotFound, parsed)
+		}
+		value = v
+	case SchemeFile:
+		data, err := r.readFile(parsed.Target)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrNotFound, parsed, err)
+		}
+		value = string(data)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%w: %s is empty", ErrNotFound, parsed)
+	}
+	return value, nil
+}
+
+// ResolveValue resolves v if it is a reference and returns it unchanged otherwise,
+// for settings that accept either a literal or a reference.
+func (r *Resolver) ResolveValue(v string) (string, error) {
+	if !IsRef(v) {
+		return v, nil
+	}
+	return r.Resolve(v)
+}
+
+// ResolveTokenRef resolves a token_ref field. A bare name without a scheme is
+// treated as a keychain entry, matching how token_ref has always been used.
+func (r *Resolver) ResolveTokenRef(ref string) (string, error) {
+	if strings.TrimSpace(ref) == "" {
+		return "", fmt.Errorf("%w: empty token_ref", ErrInvalidRef)
+	}
+	if !strings.Contains(ref, ":") {
+		ref = SchemeKeychain + "://" + strings.TrimSpace(ref)
+	}
+	return r.Resolve(ref)
+}
+
+// Validate checks that ref is well-formed and that its target exists.
+func (r *Resolver) Validate(ref string) error {
+	_, err := r.Resolve(ref)
+	return err
+}
@@ -0,0 +1,117 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type mapGetter map[string]string
+
+func (m mapGetter) Get(user string) (string, error) {
+	v, ok := m[user]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Ref
+		wantErr bool
+	}{
+		{in: "keychain://telegram", want: Ref{Scheme: SchemeKeychain, Target: "telegram"}},
+		{in: "keychain:telegram", want: Ref{Scheme: SchemeKeychain, Target: "telegram"}},
+		{in: "env://BOT_TOKEN", want: Ref{Scheme: SchemeEnv, Target: "BOT_TOKEN"}},
+		{in: "file:///run/secrets/token", want: Ref{Scheme: SchemeFile, Target: "/run/secrets/token"}},
+		{in: "plain-token", wantErr: true},
+		{in: "vault://kv/token", wantErr: true},
+		{in: "env://", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidRef) {
+				t.Errorf("Parse(%q) error = %v, want ErrInvalidRef", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PRYX_TEST_SECRET", "from-env")
+
+	r := NewResolver(mapGetter{"bot": "from-keychain"})
+	tests := map[string]string{
+		"keychain://bot":         "from-keychain",
+		"keychain:bot":           "from-keychain",
+		"env://PRYX_TEST_SECRET": "from-env",
+		"file://" + path:         "from-file",
+	}
+	for ref, want := range tests {
+		got, err := r.Resolve(ref)
+		if err != nil {
+			t.Errorf("Resolve(%q) unexpected error: %v", ref, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Resolve(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestResolveMissing(t *testing.T) {
+	t.Setenv("PRYX_TEST_EMPTY", "  ")
+	r := NewResolver(mapGetter{})
+	for _, ref := range []string{
+		"keychain://missing",
+		"env://PRYX_TEST_UNSET_VARIABLE",
+		"env://PRYX_TEST_EMPTY",
+		"file://" + filepath.Join(t.TempDir(), "missing"),
+	} {
+		if err := r.Validate(ref); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Validate(%q) error = %v, want ErrNotFound", ref, err)
+		}
+	}
+
+	if _, err := NewResolver(nil).Resolve("keychain://bot"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound without keychain, got %v", err)
+	}
+}
+
+func TestResolveValueAndTokenRef(t *testing.T) {
+	r := NewResolver(mapGetter{"bot": "secret"})
+
+	got, err := r.ResolveValue("literal-token")
+	if err != nil || got != "literal-token" {
+		t.Fatalf("ResolveValue(literal) = %q, %v", got, err)
+	}
+	got, err = r.ResolveValue("keychain://bot")
+	if err != nil || got != "secret" {
+		t.Fatalf("ResolveValue(ref) = %q, %v", got, err)
+	}
+
+	got, err = r.ResolveTokenRef("bot")
+	if err != nil || got != "secret" {
+		t.Fatalf("ResolveTokenRef(bare) = %q, %v", got, err)
+	}
+	if _, err := r.ResolveTokenRef(""); !errors.Is(err, ErrInvalidRef) {
+		t.Fatalf("expected ErrInvalidRef for empty token_ref, got %v", err)
+	}
+}
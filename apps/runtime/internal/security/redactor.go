@@ -32,12 +32,28 @@ var (
 		regexp.MustCompile(`\b(?:[0-9]{1,3}\.){3}[0-9]{1,3}\b`),                        // IP address
 	}
 
+	// credentialPatterns match whole credentials in known formats, wherever
+	// they appear in free text.
+	credentialPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{16,}`),                                                       // OpenAI and Anthropic keys
+		regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9\-]{10,}`),                                               // Slack tokens
+		regexp.MustCompile(`\bxapp-[A-Za-z0-9\-]{10,}`),                                                      // Slack app tokens
+		regexp.MustCompile(`\b\d{6,}:[A-Za-z0-9_\-]{30,}\b`),                                                 // Telegram bot tokens
+		regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),                                                   // GitHub tokens
+		regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),                                                           // AWS access keys
+		regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),                                                     // Google API keys
+		regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]*`),                 // JWTs
+		regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/\-]{16,}=*`),                                       // bearer tokens
+		regexp.MustCompile(`(?i)[?&](?:key|api_key|apikey|token|access_token|secret|sig|signature)=[^&\s]+`), // secret query parameters
+	}
+
 	// Sensitive keys in maps/objects
 	sensitiveKeys = []string{
-		"password", "secret", "token", "key", "auth", "credential",
+		"password", "passwd", "secret", "token", "key", "auth", "credential",
 		"private", "apikey", "api_key", "bearer", "authorization",
 		"access_token", "refresh_token", "client_secret", "client_id",
 		"pin", "passphrase", "seed", "mnemonic", "wallet",
+		"cookie", "signature",
 	}
 )
 
@@ -84,6 +100,8 @@ func (r *Redactor) RedactString(input string) string {
 		})
 	}
 
+	result = ReplaceCredentials(result, func(string) string { return "[REDACTED_CREDENTIAL]" })
+
 	// Redact PII
 	for _, pattern := range piiPatterns {
 		result = pattern.ReplaceAllString(result, "[REDACTED_PII]")
@@ -134,6 +152,12 @@ func (r *Redactor) redactValue(value interface{}) interface{} {
 
 // isSensitiveKey checks if a key might contain sensitive information
 func (r *Redactor) isSensitiveKey(key string) bool {
+	return IsSensitiveKey(key)
+}
+
+// IsSensitiveKey reports whether a field named key likely holds a secret.
+// The audit log, audit exports and debug bundles all decide with it.
+func IsSensitiveKey(key string) bool {
 	keyLower := strings.ToLower(key)
 	for _, sensitive := range sensitiveKeys {
 		if strings.Contains(keyLower, sensitive) {
@@ -143,6 +167,17 @@ func (r *Redactor) isSensitiveKey(key string) bool {
 	return false
 }
 
+// ReplaceCredentials replaces every credential in a known format found in s,
+// such as provider API keys, bot tokens and bearer tokens, with repl applied
+// to it. Callers choose the replacement: the audit log keeps a hash so
+// entries can be correlated, debug bundles drop the value.
+func ReplaceCredentials(s string, repl func(string) string) string {
+	for _, pattern := range credentialPatterns {
+		s = pattern.ReplaceAllStringFunc(s, repl)
+	}
+	return s
+}
+
 // AddPattern adds a custom redaction pattern
 func (r *Redactor) AddPattern(pattern *regexp.Regexp) {
 	r.mu.Lock()
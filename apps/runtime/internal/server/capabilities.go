@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// APIVersion is the version of the HTTP API exposed by the runtime.
+// It is bumped when existing endpoints change incompatibly.
+const APIVersion = "v1"
+
+// routeFeatures maps each advertised feature to the route that implements it.
+// A feature is reported as supported only if its route is registered on the router.
+var routeFeatures = map[string]string{
+	"websocket":            "GET /ws",
+	"events_sse":           "GET /events",
+	"mcp_tools":            "GET /mcp/tools",
+	"mcp_discovery":        "GET /mcp/discovery/curated",
+	"mcp_approvals":        "GET /mcp/approvals",
+	"mcp_approval_resolve": "POST /mcp/approvals/{id}/resolve",
+	"mcp_call_batch":       "POST /mcp/tools/call-batch",
+	"mcp_resources":        "GET /mcp/resources",
+	"mcp_prompts":          "GET /mcp/prompts",
+	"skills":               "GET /skills",
+	"skills_install":       "POST /skills/install",
+	"skills_install_batch": "POST /skills/install-batch",
+	"skills_reload":        "POST /skills/reload",
+	"skills_stats":         "GET /skills/{id}/stats",
+	"providers":            "GET /api/v1/providers",
+	"provider_overrides":   "PUT /api/v1/providers/{id}/overrides",
+	"provider_health":      "GET /api/v1/providers/{id}/health",
+	"models":               "GET /api/v1/models",
+	"cloud_login":          "POST /api/v1/cloud/login/start",
+	"config":               "GET /api/v1/config",
+	"agents":               "GET /api/v1/agents",
+	"sessions":             "GET /api/v1/sessions",
+	"session_update":       "PATCH /api/v1/sessions/{id}",
+	"session_stats":        "GET /api/v1/sessions/{id}/stats",
+	"session_fork":         "POST /api/v1/sessions/fork",
+	"session_summarize":    "POST /api/v1/sessions/{id}/summarize",
+	"message_pin":          "POST /api/v1/sessions/{id}/messages/{mid}/pin",
+	"memory":               "GET /api/v1/memory",
+	"memory_edit":          "PATCH /api/v1/memory/{id}",
+	"mesh":                 "POST /api/mesh/pair",
+	"channels":             "GET /api/v1/channels",
+	"channel_connect":      "POST /api/v1/channels/{id}/connect",
+	"channel_selftest":     "POST /api/v1/channels/{id}/selftest",
+	"channel_health":       "GET /api/v1/channels/{id}/health",
+	"channel_activity":     "GET /api/v1/channels/{id}/activity",
+	"scheduler":            "GET /api/v1/tasks",
+	"scheduler_events":     "POST /api/v1/tasks/events/{event}/trigger",
+	"scheduler_export":     "GET /api/v1/scheduler/export",
+	"scheduler_preview":    "GET /api/v1/scheduler/preview",
+	"admin":                "GET /api/admin/stats",
+	"maintenance":          "GET /api/admin/maintenance",
+	"db_compact":           "POST /api/admin/db/compact",
+	"telemetry_settings":   "GET /api/admin/telemetry/config",
+	"mcp_server_policy":    "GET /api/admin/mcp/policy",
+	"debug_bundle":         "GET /api/admin/debug/bundle",
+}
+
+// stubRoutes maps features whose routes are registered but not implemented yet
+// to those routes. Their handlers respond 501 via writeNotImplemented, the
+// features are reported as unsupported, and the routes are listed under
+// "not_implemented" by /api/v1/capabilities.
+var stubRoutes = map[string]string{
+	"channel_test":       "POST /api/v1/channels/{id}/test",
+	"channel_disconnect": "POST /api/v1/channels/{id}/disconnect",
+}
+
+// stubEndpoint describes a route that is registered but not implemented.
+type stubEndpoint struct {
+	Feature string `json:"feature"`
+	Route   string `json:"route"`
+}
+
+// notImplemented lists the stub routes, sorted by route.
+func notImplemented() []stubEndpoint {
+	stubs := make([]stubEndpoint, 0, len(stubRoutes))
+	for feature, route := range stubRoutes {
+		stubs = append(stubs, stubEndpoint{Feature: feature, Route: route})
+	}
+	sort.Slice(stubs, func(i, j int) bool { return stubs[i].Route < stubs[j].Route })
+	return stubs
+}
+
+// writeNotImplemented responds 501 for a stub handler, so clients do not
+// mistake a placeholder for a working feature.
+func writeNotImplemented(w http.ResponseWriter, feature string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotImplemented)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error":   feature + " is not implemented yet",
+		"feature": feature,
+	})
+}
+
+// registeredRoutes returns the set of "METHOD pattern" strings registered on the router.
+func (s *Server) registeredRoutes() map[string]struct{} {
+	routes := map[string]struct{}{}
+	_ = chi.Walk(s.router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes[strings.ToUpper(method)+" "+route] = struct{}{}
+		return nil
+	})
+	return routes
+}
+
+// Features reports which optional features this runtime supports.
+// Route-backed features are derived from the router; the rest come from configuration.
+func (s *Server) Features() map[string]bool {
+	routes := s.registeredRoutes()
+	features := make(map[string]bool, len(routeFeatures)+len(stubRoutes)+3)
+	for name, route := range routeFeatures {
+		_, ok := routes[route]
+		features[name] = ok
+	}
+	for name := range stubRoutes {
+		features[name] = false
+	}
+
+	s.cfgMu.RLock()
+	features["response_cache"] = s.cfg.LLMCacheEnabled
+	s.cfgMu.RUnlock()
+	features["streaming"] = features["websocket"]
+	features["session_idempotency"] = features["sessions"]
+
+	return features
+}
+
+// handleCapabilities returns the API version and supported features so clients can
+// detect what this runtime offers instead of probing endpoints.
+func (s *Server) handleCapabilities(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"api_version":     APIVersion,
+		"features":        s.Features(),
+		"not_implemented": notImplemented(),
+	})
+}
@@ -1,15 +1,21 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"pryx-core/internal/auth"
 	"pryx-core/internal/config"
 	"pryx-core/internal/store"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestCloudLoginWithRealAPI tests cloud login endpoints structure
@@ -114,3 +120,76 @@ func TestCloudStatusEndpoint(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
+
+// TestCloudStatusTokenStates tests that cloud status tells a missing, invalid
+// and valid token apart and caches the cloud's answer
+func TestCloudStatusTokenStates(t *testing.T) {
+	cfg := &config.Config{
+		ListenAddr:  ":0",
+		CloudAPIUrl: "https://pryx.dev/api",
+	}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	server := New(cfg, s.DB, kc)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	var checks int
+	var checkErr error
+	server.checkCloudToken = func(ctx context.Context, apiUrl, token string) (*auth.TokenInfo, error) {
+		checks++
+		if checkErr != nil {
+			return nil, checkErr
+		}
+		return &auth.TokenInfo{ExpiresAt: expiresAt}, nil
+	}
+
+	status := func() map[string]any {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/cloud/status", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+
+	body := status()
+	assert.Equal(t, false, body["logged_in"])
+	assert.Equal(t, false, body["valid"])
+	assert.Equal(t, "none", body["state"])
+	assert.Equal(t, 0, checks)
+
+	require.NoError(t, auth.SaveCloudToken(kc, &auth.TokenResponse{AccessToken: "tok-1", ExpiresIn: 3600}))
+	body = status()
+	assert.Equal(t, true, body["logged_in"])
+	assert.Equal(t, true, body["valid"])
+	assert.Equal(t, "valid", body["state"])
+	assert.Equal(t, expiresAt.UTC().Format(time.RFC3339), body["expires_at"])
+	status()
+	assert.Equal(t, 1, checks, "the result should be cached")
+
+	// A revoked token is reported with a hint to log in again
+	checkErr = auth.ErrTokenInvalid
+	require.NoError(t, auth.SaveCloudToken(kc, &auth.TokenResponse{AccessToken: "tok-2", ExpiresIn: 3600}))
+	body = status()
+	assert.Equal(t, true, body["logged_in"])
+	assert.Equal(t, false, body["valid"])
+	assert.Equal(t, "invalid", body["state"])
+	assert.NotEmpty(t, body["hint"])
+
+	// An unreachable cloud leaves the token unverified
+	checkErr = errors.New("connection refused")
+	require.NoError(t, auth.SaveCloudToken(kc, &auth.TokenResponse{AccessToken: "tok-3", ExpiresIn: 3600}))
+	body = status()
+	assert.Equal(t, true, body["valid"])
+	assert.Equal(t, false, body["verified"])
+	assert.Equal(t, "unverified", body["state"])
+
+	// A stored expiry in the past needs no cloud call
+	checks = 0
+	require.NoError(t, kc.Set(auth.CloudTokenKey, "tok-4"))
+	require.NoError(t, kc.Set(auth.CloudTokenExpiryKey, time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)))
+	body = status()
+	assert.Equal(t, "invalid", body["state"])
+	assert.Equal(t, 0, checks)
+}
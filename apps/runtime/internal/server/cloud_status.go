@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"pryx-core/internal/auth"
+)
+
+// Cloud token checks are cached so that polling the status does not hit the
+// cloud on every request. Results the cloud could not confirm are retried sooner.
+const (
+	cloudTokenCheckTTL      = time.Minute
+	cloudTokenUnverifiedTTL = 15 * time.Second
+	cloudTokenCheckTimeout  = 5 * time.Second
+	cloudTokenInvalidHint   = "Your Pryx Cloud session has expired or was revoked; log in again."
+)
+
+// Cloud login states reported by GET /api/v1/cloud/status.
+const (
+	cloudTokenNone       = "none"       // not logged in
+	cloudTokenInvalid    = "invalid"    // token present but expired or revoked
+	cloudTokenValid      = "valid"      // token confirmed by the cloud
+	cloudTokenUnverified = "unverified" // token present, the cloud could not confirm it
+)
+
+// cloudTokenStatus is the outcome of checking the stored cloud token.
+type cloudTokenStatus struct {
+	State     string
+	ExpiresAt time.Time
+}
+
+// cloudTokenCache remembers the last check of a token until expires.
+type cloudTokenCache struct {
+	mu      sync.Mutex
+	token   string
+	status  cloudTokenStatus
+	expires time.Time
+}
+
+func (c *cloudTokenCache) get(token string, now time.Time) (cloudTokenStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != token || !now.Before(c.expires) {
+		return cloudTokenStatus{}, false
+	}
+	return c.status, true
+}
+
+func (c *cloudTokenCache) put(token string, status cloudTokenStatus, expires time.Time) {
+	c.mu.Lock()
+	c.token, c.status, c.expires = token, status, expires
+	c.mu.Unlock()
+}
+
+func (s *Server) handleCloudStatus(w http.ResponseWriter, r *http.Request) {
+	if s.keychain == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "keychain not available"})
+		return
+	}
+
+	token, err := s.keychain.Get(auth.CloudTokenKey)
+	if err != nil {
+		token = ""
+	}
+	token = strings.TrimSpace(token)
+
+	status := cloudTokenStatus{State: cloudTokenNone}
+	if token != "" {
+		status = s.cloudTokenStatus(r.Context(), token)
+	}
+
+	resp := map[string]any{
+		"logged_in": token != "",
+		"valid":     status.State == cloudTokenValid || status.State == cloudTokenUnverified,
+		"verified":  status.State == cloudTokenValid || status.State == cloudTokenInvalid,
+		"state":     status.State,
+	}
+	if !status.ExpiresAt.IsZero() {
+		resp["expires_at"] = status.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	if status.State == cloudTokenInvalid {
+		resp["hint"] = cloudTokenInvalidHint
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// cloudTokenStatus checks token, which must be non-empty. A stored expiry in
+// the past marks it invalid without asking the cloud; otherwise the cloud is
+// asked and its answer cached. If the cloud cannot answer, the token counts as
+// unverified.
+func (s *Server) cloudTokenStatus(ctx context.Context, token string) cloudTokenStatus {
+	now := time.Now()
+	if status, ok := s.cloudToken.get(token, now); ok {
+		return status
+	}
+
+	status := cloudTokenStatus{State: cloudTokenUnverified}
+	if expiresAt, ok := auth.CloudTokenExpiry(s.keychain); ok {
+		status.ExpiresAt = expiresAt
+		if !now.Before(expiresAt) {
+			status.State = cloudTokenInvalid
+			s.cloudToken.put(token, status, now.Add(cloudTokenCheckTTL))
+			return status
+		}
+	}
+
+	s.cfgMu.RLock()
+	apiUrl := strings.TrimSpace(s.cfg.CloudAPIUrl)
+	s.cfgMu.RUnlock()
+	if apiUrl == "" {
+		s.cloudToken.put(token, status, now.Add(cloudTokenUnverifiedTTL))
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cloudTokenCheckTimeout)
+	defer cancel()
+	info, err := s.checkCloudToken(ctx, apiUrl, token)
+	ttl := cloudTokenCheckTTL
+	switch {
+	case err == nil:
+		status.State = cloudTokenValid
+		if !info.ExpiresAt.IsZero() {
+			status.ExpiresAt = info.ExpiresAt
+		}
+	case errors.Is(err, auth.ErrTokenInvalid):
+		status.State = cloudTokenInvalid
+	case errors.Is(err, auth.ErrTokenCheckUnsupported):
+		// Only the stored expiry is known; asking again will not help
+	default:
+		log.Printf("Cloud token check failed: %v", err)
+		ttl = cloudTokenUnverifiedTTL
+	}
+
+	expires := now.Add(ttl)
+	if status.State == cloudTokenValid && !status.ExpiresAt.IsZero() && status.ExpiresAt.Before(expires) {
+		expires = status.ExpiresAt
+	}
+	s.cloudToken.put(token, status, expires)
+	return status
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/scheduler"
+	"pryx-core/internal/store"
+)
+
+// compactTaskID is the ID of the built-in task created from db_compact_schedule.
+const compactTaskID = "builtin-db-compact"
+
+// errCompactRunning is returned by CompactDatabase while a compaction is in progress.
+var errCompactRunning = errors.New("database compaction already in progress")
+
+// CompactDatabase reclaims the space left by deleted rows. Only one compaction
+// runs at a time; requests already using the database finish first, as
+// VACUUM waits for their transactions.
+func (s *Server) CompactDatabase(ctx context.Context) (store.CompactResult, error) {
+	if !s.compacting.CompareAndSwap(false, true) {
+		return store.CompactResult{}, errCompactRunning
+	}
+	defer s.compacting.Store(false)
+
+	result, err := s.store.Compact(ctx)
+	if err != nil {
+		return result, err
+	}
+	log.Printf("Database compacted: %d bytes reclaimed (%d -> %d) in %dms",
+		result.Reclaimed, result.SizeBefore, result.SizeAfter, result.DurationMs)
+	s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{
+		"kind":        "db.compacted",
+		"size_before": result.SizeBefore,
+		"size_after":  result.SizeAfter,
+		"reclaimed":   result.Reclaimed,
+		"duration_ms": result.DurationMs,
+	}))
+	return result, nil
+}
+
+// handleAdminDBCompact compacts the database and reports the space reclaimed.
+func (s *Server) handleAdminDBCompact(w http.ResponseWriter, r *http.Request) {
+	layer := getAuthLayer(r)
+	if layer != "superadmin" && layer != "localhost" {
+		http.Error(w, "Forbidden: superadmin access required", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	result, err := s.CompactDatabase(r.Context())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errCompactRunning) || strings.Contains(err.Error(), "database is locked") {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// dbCompactExecutor runs TaskTypeDBCompact tasks.
+type dbCompactExecutor struct {
+	server *Server
+}
+
+func (e *dbCompactExecutor) Execute(ctx context.Context, task *scheduler.ScheduledTask) (string, error) {
+	result, err := e.server.CompactDatabase(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("reclaimed %d bytes (%d -> %d)", result.Reclaimed, result.SizeBefore, result.SizeAfter), nil
+}
+
+// syncCompactTask creates, updates or removes the built-in compaction task so
+// that it runs on schedule, or not at all when schedule is empty.
+func (s *Server) syncCompactTask(schedule string) error {
+	if s.scheduler == nil {
+		return nil
+	}
+	schedule = strings.TrimSpace(schedule)
+	task, err := s.scheduler.GetTask(compactTaskID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case schedule == "" && task == nil:
+		return nil
+	case schedule == "":
+		return s.scheduler.DeleteTask(compactTaskID)
+	case task == nil:
+		return s.scheduler.CreateTask(&scheduler.ScheduledTask{
+			ID:             compactTaskID,
+			Name:           "Compact database",
+			Description:    "Built-in task from db_compact_schedule",
+			CronExpression: schedule,
+			TaskType:       scheduler.TaskTypeDBCompact,
+			Enabled:        true,
+		})
+	case task.CronExpression != schedule:
+		// A task disabled through the API stays disabled
+		task.CronExpression = schedule
+		return s.scheduler.UpdateTask(task)
+	}
+	return nil
+}
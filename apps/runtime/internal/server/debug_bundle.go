@@ -0,0 +1,75 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/debugbundle"
+	"pryx-core/internal/doctor"
+)
+
+// SetBuildInfo sets the version and build date reported in debug bundles.
+func (s *Server) SetBuildInfo(version, buildDate string) {
+	s.version = version
+	s.buildDate = buildDate
+}
+
+// recordDebugEvents keeps recent error and trace events for debug bundles.
+func (s *Server) recordDebugEvents(events <-chan bus.Event, cancel func()) {
+	defer cancel()
+
+	for evt := range events {
+		s.debugEvents.Record(evt)
+	}
+}
+
+// handleAdminDebugBundle returns a redacted support bundle as a zip: doctor
+// report, config, versions, schema, recent errors and events, and MCP and
+// channel status.
+func (s *Server) handleAdminDebugBundle(w http.ResponseWriter, r *http.Request) {
+	layer := getAuthLayer(r)
+	if layer != "superadmin" && layer != "localhost" {
+		http.Error(w, "Forbidden: superadmin access required", http.StatusForbidden)
+		return
+	}
+
+	s.cfgMu.RLock()
+	cfg := *s.cfg
+	s.cfgMu.RUnlock()
+
+	b := &debugbundle.Bundle{
+		Version: debugbundle.NewVersionInfo(s.version, s.buildDate),
+		Config:  &cfg,
+		Events:  s.debugEvents.Events(),
+	}
+	b.Doctor, _ = doctor.Run(r.Context(), &cfg, s.keychain)
+	b.CollectStore(s.store)
+	b.CollectMCP(r.Context(), s.mcp)
+	for _, c := range s.channels.List() {
+		b.Channels = append(b.Channels, debugbundle.ChannelStatus{
+			ID:      c.ID(),
+			Type:    c.Type(),
+			Enabled: true,
+			Status:  string(c.Status()),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		log.Printf("Failed to build debug bundle: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to build debug bundle"})
+		return
+	}
+
+	name := fmt.Sprintf("pryx-debug-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	_, _ = w.Write(buf.Bytes())
+}
@@ -4,16 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"pryx-core/internal/agent"
+	"pryx-core/internal/agent/spawn"
 	"pryx-core/internal/auth"
+	"pryx-core/internal/bus"
 	"pryx-core/internal/config"
+	"pryx-core/internal/llm/providers"
+	"pryx-core/internal/mcp"
 	"pryx-core/internal/memory"
+	"pryx-core/internal/models"
+	"pryx-core/internal/secrets"
 	"pryx-core/internal/skills"
+	"pryx-core/internal/store"
 	"pryx-core/internal/validation"
 
 	"github.com/go-chi/chi/v5"
@@ -25,6 +36,11 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	s.cfgMu.RLock()
 	activeProvider := strings.TrimSpace(s.cfg.ModelProvider)
 	ollamaEndpoint := strings.TrimSpace(s.cfg.OllamaEndpoint)
+	proxyCfg := config.Config{
+		ModelProvider:      activeProvider,
+		CloudProxyFallback: s.cfg.CloudProxyFallback,
+		CloudAPIUrl:        s.cfg.CloudAPIUrl,
+	}
 	s.cfgMu.RUnlock()
 
 	configuredProviders := []string{}
@@ -65,30 +81,36 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"status":          "ok",
-		"providers":       configuredProviders,
-		"cloud_logged_in": cloudLoggedIn,
-	})
-}
+	// Without credentials for the active provider, a cloud login still lets the
+	// user chat when the cloud proxy fallback is enabled. This is the agent's
+	// own decision, so it counts keys from the environment and config too.
+	cloudProxy := activeProvider != "" && agent.UseCloudProxy(&proxyCfg, s.keychain, s.Catalog())
 
-func (s *Server) handleCloudStatus(w http.ResponseWriter, r *http.Request) {
-	if s.keychain == nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]any{"error": "keychain not available"})
-		return
+	status := "ok"
+	maintenance := s.MaintenanceMode()
+	if maintenance {
+		status = "degraded"
 	}
 
-	token, err := s.keychain.Get("cloud_access_token")
-	if err != nil {
-		token = ""
+	resp := map[string]any{
+		"status":          status,
+		"maintenance":     maintenance,
+		"providers":       configuredProviders,
+		"cloud_logged_in": cloudLoggedIn,
+		"cloud_proxy":     cloudProxy,
+		"api_version":     APIVersion,
+		"features":        s.Features(),
+		"bus_dropped":     s.bus.Dropped(),
+	}
+	if gen := s.generations.Load(); gen != nil {
+		resp["generations"] = gen.Stats()
+	}
+	if limits := s.modelLimits.Load(); limits != nil {
+		resp["model_limits"] = limits.Stats()
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"logged_in": strings.TrimSpace(token) != "",
-	})
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func (s *Server) handleCloudLoginStart(w http.ResponseWriter, r *http.Request) {
@@ -223,7 +245,7 @@ func (s *Server) handleCloudLoginPoll(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.keychain.Set("cloud_access_token", token.AccessToken); err != nil {
+	if err := auth.SaveCloudToken(s.keychain, token); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(map[string]any{"error": "failed to store token"})
 		return
@@ -333,6 +355,9 @@ func (s *Server) handleConfigPatch(w http.ResponseWriter, r *http.Request) {
 
 	nextCfg := *s.cfg
 	s.cfgMu.Unlock()
+	if m := s.modelSettings.Load(); m != nil && nextModelName != nil {
+		m.Store(agent.ModelConfigFrom(&nextCfg))
+	}
 
 	if err := nextCfg.Save(config.DefaultPath()); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -362,6 +387,94 @@ func (s *Server) handleMCPTools(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleMCPResources lists the resources of the connected MCP servers.
+// ?refresh=1 bypasses the list cache, as for tools.
+func (s *Server) handleMCPResources(w http.ResponseWriter, r *http.Request) {
+	refresh := strings.TrimSpace(r.URL.Query().Get("refresh")) == "1"
+	resources, err := s.mcp.ListResources(r.Context(), refresh)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"resources": resources,
+	})
+}
+
+// handleMCPPrompts lists the prompts of the connected MCP servers.
+func (s *Server) handleMCPPrompts(w http.ResponseWriter, r *http.Request) {
+	refresh := strings.TrimSpace(r.URL.Query().Get("refresh")) == "1"
+	prompts, err := s.mcp.ListPrompts(r.Context(), refresh)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"prompts": prompts,
+	})
+}
+
+// mcpReadResourceRequest asks for a resource by URI. Server is optional; by
+// default the server listing the URI is asked.
+type mcpReadResourceRequest struct {
+	Server string `json:"server"`
+	URI    string `json:"uri"`
+}
+
+// handleMCPResourceRead fetches the contents of a resource.
+func (s *Server) handleMCPResourceRead(w http.ResponseWriter, r *http.Request) {
+	req := mcpReadResourceRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": "invalid json body",
+		})
+		return
+	}
+	req.Server = strings.TrimSpace(req.Server)
+	req.URI = strings.TrimSpace(req.URI)
+	if req.URI == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": "uri is required",
+		})
+		return
+	}
+	if req.Server != "" {
+		if err := validation.NewValidator().ValidateToolName(req.Server); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	contents, err := s.mcp.ReadResource(r.Context(), req.Server, req.URI)
+	if err != nil {
+		status := http.StatusBadGateway
+		var toolErr *mcp.ToolError
+		if errors.Is(err, mcp.ErrResourceNotFound) || (errors.As(err, &toolErr) && toolErr.Code == mcp.ToolErrNotFound) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"uri":      req.URI,
+		"contents": contents,
+	})
+}
+
 // mcpCallRequest represents a request to call an MCP tool.
 type mcpCallRequest struct {
 	SessionID string                 `json:"session_id"`
@@ -369,8 +482,19 @@ type mcpCallRequest struct {
 	Arguments map[string]interface{} `json:"arguments"`
 }
 
-// handleMCPCall executes an MCP tool call.
+// statusClientClosedRequest is reported for a call abandoned because the
+// client disconnected. Nobody reads it, but it keeps access logs honest.
+const statusClientClosedRequest = 499
+
+// handleMCPCall executes an MCP tool call. The call is bound to the request
+// context, so a client that disconnects aborts it, including while it waits
+// for approval. A server that overruns mcp_call_timeout yields a 504; a tool
+// outside the session's tool_scopes, or denied, yields a 403.
 func (s *Server) handleMCPCall(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfMaintenance(w) {
+		return
+	}
+
 	req := mcpCallRequest{}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -390,7 +514,7 @@ func (s *Server) handleMCPCall(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := validator.ValidateToolName(req.Tool); err != nil {
+	if err := validateMCPToolName(validator, req.Tool); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"error": err.Error(),
@@ -410,31 +534,110 @@ func (s *Server) handleMCPCall(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := s.mcp.CallTool(r.Context(), strings.TrimSpace(req.SessionID), req.Tool, req.Arguments)
+	ctx := mcp.WithRequester(r.Context(), mcp.Requester{Kind: "api", ID: r.RemoteAddr, Name: "An API client"})
+	res, err := s.mcp.CallTool(ctx, strings.TrimSpace(req.SessionID), req.Tool, req.Arguments)
 	if err != nil {
-		w.WriteHeader(http.StatusBadGateway)
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"error": err.Error(),
-		})
+		toolErr := mcp.ClassifyToolError(err)
+		status := http.StatusBadGateway
+		switch toolErr.Code {
+		case mcp.ToolErrCancelled:
+			status = statusClientClosedRequest
+		case mcp.ToolErrTimeout:
+			status = http.StatusGatewayTimeout
+		case mcp.ToolErrPermissionDenied:
+			status = http.StatusForbidden
+		case mcp.ToolErrInvalidArguments:
+			status = http.StatusBadRequest
+		case mcp.ToolErrNotFound:
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(toolErr.Payload())
 		return
 	}
 	_ = json.NewEncoder(w).Encode(res)
 }
 
-// handleSkillsList returns the list of available skills.
-func (s *Server) handleSkillsList(w http.ResponseWriter, r *http.Request) {
-	reg := s.skills
-	if reg == nil {
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"skills": []skills.Skill{},
-		})
+// handleMCPApprovals lists the tool calls waiting for approval, so a client
+// that connected after the approval.needed events can still prompt for them.
+func (s *Server) handleMCPApprovals(w http.ResponseWriter, r *http.Request) {
+	approvals := s.mcp.PendingApprovals()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"approvals": approvals,
+		"count":     len(approvals),
+	})
+}
+
+// handleMCPApprovalResolve approves or denies a pending tool call, as the
+// WebSocket approval.resolve message does.
+func (s *Server) handleMCPApprovalResolve(w http.ResponseWriter, r *http.Request) {
+	approvalID := strings.TrimSpace(chi.URLParam(r, "id"))
+	w.Header().Set("Content-Type", "application/json")
+	if err := validation.NewValidator().ValidateID("approval_id", approvalID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": err.Error()})
 		return
 	}
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"skills": reg.List(),
+
+	var req struct {
+		Approved *bool `json:"approved"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Approved == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "approved is required"})
+		return
+	}
+
+	if !s.mcp.ResolveApproval(approvalID, *req.Approved) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "approval not found or already resolved"})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"approval_id": approvalID,
+		"approved":    *req.Approved,
 	})
 }
 
+// handleMCPApprovalsClear denies every pending tool call, e.g. after the
+// client that should have answered them went away.
+func (s *Server) handleMCPApprovalsClear(w http.ResponseWriter, r *http.Request) {
+	denied := s.mcp.DenyPendingApprovals()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"denied": denied})
+}
+
+// handleSkillsList returns the available skills sorted by category and
+// priority, with the categories and their sizes. ?category= narrows the list
+// to one category.
+func (s *Server) handleSkillsList(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageParams(r)
+	if err != nil {
+		writePageParamsError(w, err)
+		return
+	}
+	list := []skills.Skill{}
+	if reg := s.skills; reg != nil {
+		list = reg.List()
+	}
+	categories := skills.Categories(list)
+	if category := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("category"))); category != "" {
+		filtered := []skills.Skill{}
+		for _, skill := range list {
+			if skill.Category == category {
+				filtered = append(filtered, skill)
+			}
+		}
+		list = filtered
+	}
+	skills.SortByCategory(list)
+
+	resp := listEnvelope("skills", list, page)
+	resp["categories"] = categories
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
 // handleSkillsInfo returns detailed information about a specific skill.
 func (s *Server) handleSkillsInfo(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimSpace(chi.URLParam(r, "id"))
@@ -467,6 +670,40 @@ func (s *Server) handleSkillsInfo(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(skill)
 }
 
+// handleSkillsStats returns execution counts, timing and failure rate for a skill.
+func (s *Server) handleSkillsStats(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(chi.URLParam(r, "id"))
+
+	validator := validation.NewValidator()
+	if err := validator.ValidateID("id", id); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if reg := s.skills; reg != nil {
+		if _, ok := reg.Get(id); !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": "not found",
+			})
+			return
+		}
+	}
+
+	stats, err := s.store.GetSkillStats(id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(stats)
+}
+
 // handleSkillsBody returns the body/content of a specific skill.
 func (s *Server) handleSkillsBody(w http.ResponseWriter, r *http.Request) {
 	id := strings.TrimSpace(chi.URLParam(r, "id"))
@@ -597,7 +834,9 @@ func (s *Server) handleSkillsDisable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	delete(enabledCfg.EnabledSkills, id)
+	// Record the choice rather than dropping the entry, so a skill that is
+	// enabled by default stays off.
+	enabledCfg.EnabledSkills[id] = false
 	if err := skills.SaveEnabledConfig(configPath, enabledCfg); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
@@ -644,15 +883,7 @@ func (s *Server) handleSkillsInstall(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		reg.Upsert(res.Skill)
-
-		configPath := skills.EnabledConfigPath()
-		enabledCfg, err := skills.LoadEnabledConfig(configPath)
-		if err == nil {
-			enabledCfg.EnabledSkills[res.Skill.ID] = true
-			_ = skills.SaveEnabledConfig(configPath, enabledCfg)
-			reg.Enable(res.Skill.ID)
-		}
+		s.addInstalledSkills(reg, res.Skill)
 
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "skill": res.Skill})
 		return
@@ -669,6 +900,99 @@ func (s *Server) handleSkillsInstall(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": "not found"})
 }
 
+// addInstalledSkills registers freshly installed skills and enables them.
+func (s *Server) addInstalledSkills(reg *skills.Registry, installed ...skills.Skill) {
+	for _, skill := range installed {
+		reg.Upsert(skill)
+	}
+
+	configPath := skills.EnabledConfigPath()
+	enabledCfg, err := skills.LoadEnabledConfig(configPath)
+	if err != nil {
+		return
+	}
+	for _, skill := range installed {
+		enabledCfg.EnabledSkills[skill.ID] = true
+		reg.Enable(skill.ID)
+	}
+	_ = skills.SaveEnabledConfig(configPath, enabledCfg)
+}
+
+// maxSkillBatchURLs caps the number of URLs in one install-batch request.
+const maxSkillBatchURLs = 200
+
+type skillBatchInstallRequest struct {
+	URLs        []string `json:"urls"`
+	Concurrency int      `json:"concurrency"`
+	Retries     *int     `json:"retries"`
+	BackoffMs   int      `json:"backoff_ms"`
+	Overwrite   bool     `json:"overwrite"`
+}
+
+// handleSkillsInstallBatch installs skills from many URLs at once. Individual
+// failures are reported per URL; the request only fails as a whole when the
+// body is invalid.
+func (s *Server) handleSkillsInstallBatch(w http.ResponseWriter, r *http.Request) {
+	req := skillBatchInstallRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": "invalid json body"})
+		return
+	}
+	urls := make([]string, 0, len(req.URLs))
+	for _, u := range req.URLs {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	if len(urls) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": "urls is required"})
+		return
+	}
+	if len(urls) > maxSkillBatchURLs {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": fmt.Sprintf("at most %d urls per batch", maxSkillBatchURLs)})
+		return
+	}
+
+	reg := s.skills
+	if reg == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": "skills registry not available"})
+		return
+	}
+
+	batch := skills.BatchInstallOptions{
+		Concurrency: req.Concurrency,
+		Retries:     -1,
+		Backoff:     time.Duration(req.BackoffMs) * time.Millisecond,
+		Overwrite:   req.Overwrite,
+	}
+	if req.Retries != nil {
+		batch.Retries = *req.Retries
+	}
+	report := skills.InstallBatch(r.Context(), urls, skills.DefaultOptions(), batch)
+
+	var installed []skills.Skill
+	for _, item := range report.Results {
+		if item.Skill != nil {
+			installed = append(installed, *item.Skill)
+		}
+	}
+	if len(installed) > 0 {
+		s.addInstalledSkills(reg, installed...)
+	}
+
+	s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{
+		"kind":      "skills.batch_installed",
+		"installed": report.Installed,
+		"failed":    report.Failed,
+		"skipped":   report.Skipped,
+	}))
+	_ = json.NewEncoder(w).Encode(report)
+}
+
 func (s *Server) handleSkillsUninstall(w http.ResponseWriter, r *http.Request) {
 	req := skillActionRequest{}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -723,35 +1047,98 @@ func (s *Server) handleSkillsUninstall(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
 }
 
+// handleSkillsReload re-runs skill discovery and swaps the result into the
+// registry, emitting skills.reloaded with the added, removed and changed IDs.
+// If any skill fails to load, the current registry is kept.
+func (s *Server) handleSkillsReload(w http.ResponseWriter, r *http.Request) {
+	reg := s.skills
+	if reg == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": "skills registry not available"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	diff, err := reg.Reload(ctx, skills.DefaultOptions())
+	if err != nil {
+		s.errors.Emit("", map[string]interface{}{
+			"kind":  "skills.reload_failed",
+			"error": err.Error(),
+		})
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{
+		"kind":    "skills.reloaded",
+		"count":   len(reg.List()),
+		"added":   diff.Added,
+		"removed": diff.Removed,
+		"changed": diff.Changed,
+	}))
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":    true,
+		"count": len(reg.List()),
+		"diff":  diff,
+	})
+}
+
 // handleProvidersList returns the list of available LLM providers.
 func (s *Server) handleProvidersList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	if s.catalog != nil {
-		var providers []map[string]interface{}
-		for id, info := range s.catalog.Providers {
-			requiresKey := len(info.Env) > 0
-			providers = append(providers, map[string]interface{}{
-				"id":               id,
-				"name":             info.Name,
-				"requires_api_key": requiresKey,
-			})
+	catalog := s.Catalog()
+	if catalog != nil {
+		providers := make([]map[string]interface{}, 0, len(catalog.Providers))
+		for id, info := range catalog.Providers {
+			providers = append(providers, providerListEntry(id, info))
 		}
+		sort.Slice(providers, func(i, j int) bool {
+			return providers[i]["id"].(string) < providers[j]["id"].(string)
+		})
 		json.NewEncoder(w).Encode(map[string]interface{}{"providers": providers})
 		return
 	}
 
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"providers": []map[string]interface{}{
-			{"id": "openai", "name": "OpenAI", "requires_api_key": true},
-			{"id": "anthropic", "name": "Anthropic", "requires_api_key": true},
-			{"id": "google", "name": "Google AI", "requires_api_key": true},
-			{"id": "ollama", "name": "Ollama (Local)", "requires_api_key": false},
+			providerListEntry("openai", models.ProviderInfo{Name: "OpenAI", Env: []string{"OPENAI_API_KEY"}}),
+			providerListEntry("anthropic", models.ProviderInfo{Name: "Anthropic", Env: []string{"ANTHROPIC_API_KEY"}}),
+			providerListEntry("google", models.ProviderInfo{Name: "Google AI", Env: []string{"GOOGLE_API_KEY"}}),
+			providerListEntry("ollama", models.ProviderInfo{Name: "Ollama (Local)"}),
 		},
 	})
 }
 
+// providerListEntry describes a provider for setup clients: env_vars lists the
+// environment variables that supply its API key, and doc_url and api_base are
+// included when the catalog provides them.
+func providerListEntry(id string, info models.ProviderInfo) map[string]interface{} {
+	envVars := info.Env
+	if envVars == nil {
+		envVars = []string{}
+	}
+	entry := map[string]interface{}{
+		"id":               id,
+		"name":             info.Name,
+		"requires_api_key": len(envVars) > 0,
+		"env_vars":         envVars,
+	}
+	if info.Doc != "" {
+		entry["doc_url"] = info.Doc
+	}
+	if info.API != "" {
+		entry["api_base"] = info.API
+	}
+	return entry
+}
+
 // handleProviderModels returns the list of models available for a specific provider.
+// With ?live=1 the provider's own model list is merged in, flagging models the
+// catalog does not know yet and catalog models the provider no longer lists;
+// ?refresh=1 bypasses the cached live list.
 func (s *Server) handleProviderModels(w http.ResponseWriter, r *http.Request) {
 	providerID := strings.TrimSpace(chi.URLParam(r, "id"))
 
@@ -764,9 +1151,10 @@ func (s *Server) handleProviderModels(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	if s.catalog != nil {
-		models := s.catalog.GetProviderModels(providerID)
-		var result []map[string]interface{}
+	var result []map[string]interface{}
+	catalog := s.Catalog()
+	if catalog != nil {
+		models := catalog.GetProviderModels(providerID)
 		for _, m := range models {
 			modelData := map[string]interface{}{
 				"id":                 m.ID,
@@ -781,38 +1169,120 @@ func (s *Server) handleProviderModels(w http.ResponseWriter, r *http.Request) {
 			}
 			result = append(result, modelData)
 		}
+	} else {
+		staticModels := map[string][]map[string]interface{}{
+			"openai": {
+				{"id": "gpt-4", "name": "GPT-4"},
+				{"id": "gpt-4-turbo", "name": "GPT-4 Turbo"},
+				{"id": "gpt-3.5-turbo", "name": "GPT-3.5 Turbo"},
+			},
+			"anthropic": {
+				{"id": "claude-3-opus", "name": "Claude 3 Opus"},
+				{"id": "claude-3-sonnet", "name": "Claude 3 Sonnet"},
+				{"id": "claude-3-haiku", "name": "Claude 3 Haiku"},
+			},
+			"google": {
+				{"id": "gemini-pro", "name": "Gemini Pro"},
+				{"id": "gemini-ultra", "name": "Gemini Ultra"},
+			},
+			"ollama": {
+				{"id": "llama3", "name": "Llama 3"},
+				{"id": "llama2", "name": "Llama 2"},
+				{"id": "mistral", "name": "Mistral"},
+			},
+		}
+		providerModels, ok := staticModels[providerID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "provider not found"})
+			return
+		}
+		result = providerModels
+	}
+
+	if r.URL.Query().Get("live") != "1" {
 		json.NewEncoder(w).Encode(map[string]interface{}{"models": result})
 		return
 	}
 
-	staticModels := map[string][]map[string]interface{}{
-		"openai": {
-			{"id": "gpt-4", "name": "GPT-4"},
-			{"id": "gpt-4-turbo", "name": "GPT-4 Turbo"},
-			{"id": "gpt-3.5-turbo", "name": "GPT-3.5 Turbo"},
-		},
-		"anthropic": {
-			{"id": "claude-3-opus", "name": "Claude 3 Opus"},
-			{"id": "claude-3-sonnet", "name": "Claude 3 Sonnet"},
-			{"id": "claude-3-haiku", "name": "Claude 3 Haiku"},
-		},
-		"google": {
-			{"id": "gemini-pro", "name": "Gemini Pro"},
-			{"id": "gemini-ultra", "name": "Gemini Ultra"},
-		},
-		"ollama": {
-			{"id": "llama3", "name": "Llama 3"},
-			{"id": "llama2", "name": "Llama 2"},
-			{"id": "mistral", "name": "Mistral"},
-		},
+	merged, live := s.mergeLiveModels(r.Context(), providerID, result, r.URL.Query().Get("refresh") == "1")
+	json.NewEncoder(w).Encode(map[string]interface{}{"models": merged, "live": live})
+}
+
+// providerLiveModelsTimeout bounds fetching a provider's live model list.
+const providerLiveModelsTimeout = 10 * time.Second
+
+// mergeLiveModels marks each of models with whether the provider lists it
+// ("live") and appends the live models missing from models, marked
+// "in_catalog": false. The second result describes the live list; when it
+// cannot be fetched it carries the error and models are returned unmarked.
+func (s *Server) mergeLiveModels(ctx context.Context, providerID string, models []map[string]interface{}, refresh bool) ([]map[string]interface{}, map[string]interface{}) {
+	info := map[string]interface{}{}
+
+	var key string
+	if s.keychain != nil {
+		if k, err := s.keychain.GetProviderKey(providerID); err == nil {
+			key = strings.TrimSpace(k)
+		}
+	}
+	if key == "" && providerID != "ollama" {
+		info["error"] = "no API key configured"
+		return models, info
 	}
 
-	if providerModels, ok := staticModels[providerID]; ok {
-		json.NewEncoder(w).Encode(map[string]interface{}{"models": providerModels})
-	} else {
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]string{"error": "provider not found"})
+	ctx, cancel := context.WithTimeout(ctx, providerLiveModelsTimeout)
+	defer cancel()
+	liveModels, fetchedAt, err := s.liveModels.List(ctx, providerID, key, s.providerBaseURL(providerID), refresh)
+	if err != nil {
+		info["error"] = redactKey(err.Error(), key)
+		return models, info
+	}
+
+	live := make(map[string]providers.LiveModel, len(liveModels))
+	for _, m := range liveModels {
+		live[m.ID] = m
+	}
+	known := make(map[string]bool, len(models))
+	merged := make([]map[string]interface{}, 0, len(models)+len(liveModels))
+	missingLive := []string{}
+	for _, m := range models {
+		id, _ := m["id"].(string)
+		known[id] = true
+		entry := make(map[string]interface{}, len(m)+2)
+		for k, v := range m {
+			entry[k] = v
+		}
+		_, listed := live[id]
+		entry["live"] = listed
+		entry["in_catalog"] = true
+		if !listed {
+			missingLive = append(missingLive, id)
+		}
+		merged = append(merged, entry)
+	}
+	missingCatalog := []string{}
+	for _, m := range liveModels {
+		if known[m.ID] {
+			continue
+		}
+		name := m.Name
+		if name == "" {
+			name = m.ID
+		}
+		merged = append(merged, map[string]interface{}{
+			"id":         m.ID,
+			"name":       name,
+			"live":       true,
+			"in_catalog": false,
+		})
+		missingCatalog = append(missingCatalog, m.ID)
 	}
+
+	info["fetched_at"] = fetchedAt
+	info["count"] = len(liveModels)
+	info["missing_from_catalog"] = missingCatalog
+	info["missing_live"] = missingLive
+	return merged, info
 }
 
 func (s *Server) handleProviderKeyStatus(w http.ResponseWriter, r *http.Request) {
@@ -851,6 +1321,77 @@ func (s *Server) handleProviderKeyStatus(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// providerHealthTimeout bounds the round trip made by the provider health probe.
+const providerHealthTimeout = 5 * time.Second
+
+// handleProviderHealth makes a lightweight authenticated round trip to the
+// provider with the stored key and reports whether it answered and how fast.
+func (s *Server) handleProviderHealth(w http.ResponseWriter, r *http.Request) {
+	providerID := strings.TrimSpace(chi.URLParam(r, "id"))
+
+	validator := validation.NewValidator()
+	if err := validator.ValidateID("id", providerID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !s.providerExists(providerID) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "provider not found"})
+		return
+	}
+
+	if s.keychain == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "keychain not available"})
+		return
+	}
+
+	key, err := s.keychain.GetProviderKey(providerID)
+	if err != nil && !isKeyNotFound(err) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "failed to read key"})
+		return
+	}
+	key = strings.TrimSpace(key)
+
+	result := map[string]any{
+		"provider_id": providerID,
+		"reachable":   false,
+		"latency_ms":  int64(0),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if key == "" && providerID != "ollama" {
+		result["error"] = "no API key configured"
+		_ = json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), providerHealthTimeout)
+	defer cancel()
+	start := time.Now()
+	health, err := providers.NewHealthChecker().Probe(ctx, providerID, key, s.providerBaseURL(providerID))
+	result["latency_ms"] = time.Since(start).Milliseconds()
+	switch {
+	case err != nil:
+		result["error"] = redactKey(err.Error(), key)
+	case health.Status != providers.StatusHealthy:
+		result["error"] = redactKey(health.LastError, key)
+	default:
+		result["reachable"] = true
+	}
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// redactKey removes key from text, such as a request URL echoed in an error.
+func redactKey(text, key string) string {
+	if key == "" {
+		return text
+	}
+	return strings.ReplaceAll(text, key, "[REDACTED]")
+}
+
 func (s *Server) handleProviderKeySet(w http.ResponseWriter, r *http.Request) {
 	providerID := strings.TrimSpace(chi.URLParam(r, "id"))
 
@@ -893,6 +1434,30 @@ func (s *Server) handleProviderKeySet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := s.validateSecretRef(key); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	validated := false
+	if s.shouldValidateProviderKey(r) {
+		resolved, err := s.secretResolver().ResolveValue(key)
+		if err == nil {
+			err = s.checkProviderKey(r.Context(), providerID, resolved)
+		}
+		if err != nil {
+			status := http.StatusBadRequest
+			if !errors.Is(err, providers.ErrInvalidAPIKey) {
+				status = http.StatusBadGateway
+			}
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		validated = true
+	}
+
 	if err := s.keychain.SetProviderKey(providerID, key); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": "failed to store key"})
@@ -903,9 +1468,64 @@ func (s *Server) handleProviderKeySet(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]any{
 		"ok":          true,
 		"provider_id": providerID,
+		"validated":   validated,
 	})
 }
 
+// shouldValidateProviderKey reports whether a key set request asks for validation,
+// falling back to the validate_provider_keys setting.
+func (s *Server) shouldValidateProviderKey(r *http.Request) bool {
+	switch strings.ToLower(r.URL.Query().Get("validate")) {
+	case "1", "true", "yes":
+		return true
+	case "0", "false", "no":
+		return false
+	}
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg.ValidateProviderKeys
+}
+
+// validateProviderKey makes a lightweight authenticated call to the provider.
+func (s *Server) validateProviderKey(ctx context.Context, providerID, apiKey string) error {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return providers.NewHealthChecker().ValidateKey(ctx, providerID, apiKey, s.providerBaseURL(providerID))
+}
+
+// providerBaseURL returns the catalog's API endpoint for providerID, or the
+// configured endpoint for Ollama.
+func (s *Server) providerBaseURL(providerID string) string {
+	var baseURL string
+	catalog := s.Catalog()
+	if catalog != nil {
+		baseURL = catalog.Providers[providerID].API
+	}
+	if providerID == "ollama" && baseURL == "" {
+		s.cfgMu.RLock()
+		baseURL = s.cfg.OllamaEndpoint
+		s.cfgMu.RUnlock()
+	}
+	return baseURL
+}
+
+// secretResolver resolves secret references against the server's keychain.
+func (s *Server) secretResolver() *secrets.Resolver {
+	if s.keychain == nil {
+		return secrets.NewResolver(nil)
+	}
+	return secrets.NewResolver(s.keychain)
+}
+
+// validateSecretRef checks that value, if it is a secret reference, points at an
+// existing secret. Literal values are accepted as-is.
+func (s *Server) validateSecretRef(value string) error {
+	if !secrets.IsRef(value) {
+		return nil
+	}
+	return s.secretResolver().Validate(value)
+}
+
 func (s *Server) handleProviderKeyDelete(w http.ResponseWriter, r *http.Request) {
 	providerID := strings.TrimSpace(chi.URLParam(r, "id"))
 
@@ -937,9 +1557,119 @@ func (s *Server) handleProviderKeyDelete(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleProviderOverridesGet returns the headers and default params added to
+// requests sent to a provider.
+func (s *Server) handleProviderOverridesGet(w http.ResponseWriter, r *http.Request) {
+	providerID := strings.TrimSpace(chi.URLParam(r, "id"))
+
+	validator := validation.NewValidator()
+	if err := validator.ValidateID("id", providerID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !s.providerExists(providerID) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "provider not found"})
+		return
+	}
+
+	s.cfgMu.RLock()
+	override := s.cfg.ProviderOverrides[providerID]
+	s.cfgMu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"provider_id": providerID,
+		"headers":     override.Headers,
+		"params":      override.Params,
+	})
+}
+
+// handleProviderOverridesSet replaces the headers and default params added to
+// requests sent to a provider. An empty body clears them.
+func (s *Server) handleProviderOverridesSet(w http.ResponseWriter, r *http.Request) {
+	providerID := strings.TrimSpace(chi.URLParam(r, "id"))
+
+	validator := validation.NewValidator()
+	if err := validator.ValidateID("id", providerID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if !s.providerExists(providerID) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "provider not found"})
+		return
+	}
+
+	var override config.ProviderOverride
+	if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "invalid json body"})
+		return
+	}
+	if err := override.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": err.Error()})
+		return
+	}
+
+	s.cfgMu.Lock()
+	overrides := make(map[string]config.ProviderOverride, len(s.cfg.ProviderOverrides)+1)
+	for id, o := range s.cfg.ProviderOverrides {
+		overrides[id] = o
+	}
+	if len(override.Headers) == 0 && len(override.Params) == 0 {
+		delete(overrides, providerID)
+	} else {
+		overrides[providerID] = override
+	}
+	s.cfg.ProviderOverrides = overrides
+	nextCfg := *s.cfg
+	s.cfgMu.Unlock()
+
+	applyProviderOverrides(overrides)
+	if err := nextCfg.Save(config.DefaultPath()); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]any{"error": "failed to save config"})
+		return
+	}
+
+	s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{
+		"kind":        "provider.overrides_updated",
+		"provider_id": providerID,
+		"headers":     len(override.Headers),
+		"params":      len(override.Params),
+	}))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"provider_id": providerID,
+		"headers":     override.Headers,
+		"params":      override.Params,
+	})
+}
+
+// applyProviderOverrides makes the provider clients use overrides. Invalid
+// entries, which can only come from a hand-edited config file, are skipped.
+func applyProviderOverrides(overrides map[string]config.ProviderOverride) {
+	applied := make(map[string]providers.RequestOverrides, len(overrides))
+	for id, o := range overrides {
+		if err := o.Validate(); err != nil {
+			log.Printf("Warning: ignoring provider_overrides for %s: %v", id, err)
+			continue
+		}
+		applied[id] = providers.RequestOverrides{Headers: o.Headers, Params: o.Params}
+	}
+	providers.ConfigureRequestOverrides(applied)
+}
+
 func (s *Server) providerExists(providerID string) bool {
-	if s.catalog != nil {
-		_, ok := s.catalog.Providers[providerID]
+	catalog := s.Catalog()
+	if catalog != nil {
+		_, ok := catalog.Providers[providerID]
 		return ok
 	}
 
@@ -961,10 +1691,16 @@ func isKeyNotFound(err error) bool {
 // handleModelsList returns the list of all available LLM models.
 func (s *Server) handleModelsList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+	page, err := parsePageParams(r)
+	if err != nil {
+		writePageParamsError(w, err)
+		return
+	}
 
-	if s.catalog != nil {
-		var result []map[string]interface{}
-		for _, m := range s.catalog.Models {
+	catalog := s.Catalog()
+	if catalog != nil {
+		result := []map[string]interface{}{}
+		for _, m := range catalog.Models {
 			modelData := map[string]interface{}{
 				"id":                 m.ID,
 				"name":               m.Name,
@@ -979,20 +1715,18 @@ func (s *Server) handleModelsList(w http.ResponseWriter, r *http.Request) {
 			}
 			result = append(result, modelData)
 		}
-		json.NewEncoder(w).Encode(map[string]interface{}{"models": result})
+		json.NewEncoder(w).Encode(listEnvelope("models", result, page))
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"models": []map[string]interface{}{
-			{"id": "gpt-4", "name": "GPT-4", "provider": "openai"},
-			{"id": "gpt-4-turbo", "name": "GPT-4 Turbo", "provider": "openai"},
-			{"id": "gpt-3.5-turbo", "name": "GPT-3.5 Turbo", "provider": "openai"},
-			{"id": "claude-3-opus", "name": "Claude 3 Opus", "provider": "anthropic"},
-			{"id": "claude-3-sonnet", "name": "Claude 3 Sonnet", "provider": "anthropic"},
-			{"id": "claude-3-haiku", "name": "Claude 3 Haiku", "provider": "anthropic"},
-		},
-	})
+	json.NewEncoder(w).Encode(listEnvelope("models", []map[string]interface{}{
+		{"id": "gpt-4", "name": "GPT-4", "provider": "openai"},
+		{"id": "gpt-4-turbo", "name": "GPT-4 Turbo", "provider": "openai"},
+		{"id": "gpt-3.5-turbo", "name": "GPT-3.5 Turbo", "provider": "openai"},
+		{"id": "claude-3-opus", "name": "Claude 3 Opus", "provider": "anthropic"},
+		{"id": "claude-3-sonnet", "name": "Claude 3 Sonnet", "provider": "anthropic"},
+		{"id": "claude-3-haiku", "name": "Claude 3 Haiku", "provider": "anthropic"},
+	}, page))
 }
 
 // handleAgentsList returns the list of active spawned agents.
@@ -1005,8 +1739,13 @@ func (s *Server) handleAgentsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := parsePageParams(r)
+	if err != nil {
+		writePageParamsError(w, err)
+		return
+	}
 	agents := s.spawnTool.ListAgents()
-	json.NewEncoder(w).Encode(map[string]interface{}{"agents": agents})
+	json.NewEncoder(w).Encode(listEnvelope("agents", agents, page))
 }
 
 // handleAgentGet returns the status of a specific agent.
@@ -1044,6 +1783,10 @@ type spawnRequest struct {
 }
 
 func (s *Server) handleAgentSpawn(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfMaintenance(w) {
+		return
+	}
+
 	if s.spawnTool == nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		json.NewEncoder(w).Encode(map[string]string{"error": "spawn tool not available"})
@@ -1076,13 +1819,47 @@ func (s *Server) handleAgentSpawn(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+// handleAgentCancel stops a pending or running sub-agent.
 func (s *Server) handleAgentCancel(w http.ResponseWriter, r *http.Request) {
+	agentID := chi.URLParam(r, "id")
+
+	validator := validation.NewValidator()
+	if err := validator.ValidateID("id", agentID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	if s.spawnTool == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "spawn tool not available"})
+		return
+	}
+
+	if err := s.spawnTool.CancelAgent(agentID); err != nil {
+		switch {
+		case errors.Is(err, spawn.ErrAgentNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, spawn.ErrAgentFinished):
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "cancel not yet implemented"})
+	json.NewEncoder(w).Encode(map[string]string{
+		"agent_id": agentID,
+		"status":   string(spawn.StatusCancelled),
+	})
 }
 
 type forkRequest struct {
 	SourceSessionID string `json:"source_session_id"`
+	// UpToMessageID, if set, forks only the messages up to and including this one.
+	UpToMessageID string `json:"up_to_message_id,omitempty"`
 }
 
 func (s *Server) handleSessionFork(w http.ResponseWriter, r *http.Request) {
@@ -1105,7 +1882,18 @@ func (s *Server) handleSessionFork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newSessionID, err := s.spawnTool.ForkSession(req.SourceSessionID)
+	var newSessionID string
+	var err error
+	if req.UpToMessageID != "" {
+		newSessionID, err = s.spawnTool.ForkSessionAt(req.SourceSessionID, req.UpToMessageID)
+	} else {
+		newSessionID, err = s.spawnTool.ForkSession(req.SourceSessionID)
+	}
+	if errors.Is(err, store.ErrMessageNotInSession) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
@@ -1118,6 +1906,7 @@ func (s *Server) handleSessionFork(w http.ResponseWriter, r *http.Request) {
 		"id":                newSessionID,
 		"source_session_id": req.SourceSessionID,
 		"new_session_id":    newSessionID,
+		"up_to_message_id":  req.UpToMessageID,
 	})
 }
 
@@ -1276,3 +2065,71 @@ func (s *Server) handleMemorySearch(w http.ResponseWriter, r *http.Request) {
 		"count":   len(results),
 	})
 }
+
+func (s *Server) handleMemoryUpdate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.ragMemory == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "memory system not available"})
+		return
+	}
+
+	entryID := chi.URLParam(r, "id")
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "content is required"})
+		return
+	}
+
+	entry, err := s.ragMemory.Update(r.Context(), entryID, req.Content)
+	if err != nil {
+		if errors.Is(err, memory.ErrEntryNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	s.bus.Publish(bus.NewEvent(bus.EventMemoryUpdated, "", map[string]interface{}{
+		"id":   entry.ID,
+		"type": entry.Type,
+	}))
+	json.NewEncoder(w).Encode(entry)
+}
+
+func (s *Server) handleMemoryDelete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.ragMemory == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"error": "memory system not available"})
+		return
+	}
+
+	entryID := chi.URLParam(r, "id")
+	if err := s.ragMemory.Delete(entryID); err != nil {
+		if errors.Is(err, memory.ErrEntryNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	s.bus.Publish(bus.NewEvent(bus.EventMemoryDeleted, "", map[string]interface{}{
+		"id": entryID,
+	}))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -199,81 +200,132 @@ func (s *Server) handleAdminStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
-// handleAdminUsers returns user list for admin dashboard
+// adminActiveUserWindow is how recently a user must have been seen to count as active.
+const adminActiveUserWindow = 30 * 24 * time.Hour
+
+// adminUsersDefaultLimit is the page size of the admin users list.
+const adminUsersDefaultLimit = 50
+
+// adminUserSorts maps the sort query parameter of the admin users list to an
+// ORDER BY expression. u.id is always appended so pages are stable.
+var adminUserSorts = map[string]string{
+	"created_desc":   "u.created_at DESC",
+	"created_asc":    "u.created_at ASC",
+	"cost_desc":      "total_cost DESC",
+	"cost_asc":       "total_cost ASC",
+	"sessions_desc":  "session_count DESC",
+	"last_seen_desc": "u.last_seen DESC",
+	"email_asc":      "u.email ASC",
+}
+
+// handleAdminUsers returns a page of users for the admin dashboard, optionally
+// filtered by status (active or inactive) and an email search, and sorted by
+// the sort parameter (see adminUserSorts).
 func (s *Server) handleAdminUsers(w http.ResponseWriter, r *http.Request) {
 	layer := getAuthLayer(r)
 
-	// Filter by user if not superadmin or localhost
-	var userID string
+	page, err := parsePageParams(r)
+	if err != nil {
+		writePageParamsError(w, err)
+		return
+	}
+	query := r.URL.Query()
+	if strings.TrimSpace(query.Get("limit")) == "" {
+		page.Limit = adminUsersDefaultLimit
+	}
+
+	var conds []string
+	var args []interface{}
+
+	// Users only see themselves
 	if layer == "user" {
-		userID = getUserID(r)
+		conds = append(conds, "u.id = ?")
+		args = append(args, getUserID(r))
+	}
+
+	activeSince := time.Now().UTC().Add(-adminActiveUserWindow)
+	switch status := strings.TrimSpace(query.Get("status")); status {
+	case "":
+	case "active":
+		conds = append(conds, "u.last_seen >= ?")
+		args = append(args, activeSince)
+	case "inactive":
+		conds = append(conds, "(u.last_seen IS NULL OR u.last_seen < ?)")
+		args = append(args, activeSince)
+	default:
+		writePageParamsError(w, fmt.Errorf("status must be active or inactive"))
+		return
 	}
 
-	// Parse query parameters
-	limit := 50
-	if l := r.URL.Query().Get("limit"); l != "" {
-		fmt.Sscanf(l, "%d", &limit)
+	if search := strings.TrimSpace(query.Get("search")); search != "" {
+		conds = append(conds, `u.email LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(search)+"%")
 	}
-	offset := 0
-	if o := r.URL.Query().Get("offset"); o != "" {
-		fmt.Sscanf(o, "%d", &offset)
+
+	orderBy := adminUserSorts["created_desc"]
+	if sort := strings.TrimSpace(query.Get("sort")); sort != "" {
+		var ok bool
+		if orderBy, ok = adminUserSorts[sort]; !ok {
+			writePageParamsError(w, fmt.Errorf("unknown sort %q", sort))
+			return
+		}
 	}
 
-	var users []*UserInfo
-	var query string
-	var args []interface{}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
 
-	if userID != "" {
-		query = `
-			SELECT u.id, u.email, u.created_at, u.last_seen,
-			       COUNT(DISTINCT s.id) as session_count,
-			       COALESCE(SUM(CAST(json_extract(al.payload, '$.cost') AS REAL)), 0) as total_cost,
-			       COALESCE(SUM(CAST(json_extract(al.payload, '$.tokens') AS INTEGER)), 0) as total_tokens,
-			       COUNT(DISTINCT md.id) as device_count
-			FROM users u
-			LEFT JOIN sessions s ON u.id = s.user_id
-			LEFT JOIN audit_log al ON u.id = al.user_id
-			LEFT JOIN mesh_devices md ON u.id = md.user_id
-			WHERE u.id = ?
-			GROUP BY u.id
-		`
-		args = []interface{}{userID}
-	} else {
-		query = `
-			SELECT u.id, u.email, u.created_at, u.last_seen,
-			       COUNT(DISTINCT s.id) as session_count,
-			       COALESCE(SUM(CAST(json_extract(al.payload, '$.cost') AS REAL)), 0) as total_cost,
-			       COALESCE(SUM(CAST(json_extract(al.payload, '$.tokens') AS INTEGER)), 0) as total_tokens,
-			       COUNT(DISTINCT md.id) as device_count
-			FROM users u
-			LEFT JOIN sessions s ON u.id = s.user_id
-			LEFT JOIN audit_log al ON u.id = al.user_id
-			LEFT JOIN mesh_devices md ON u.id = md.user_id
-			GROUP BY u.id
-			ORDER BY u.created_at DESC
-			LIMIT ? OFFSET ?
-		`
-		args = []interface{}{limit, offset}
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM users u "+where, args...).Scan(&total); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to count users: %v", err), http.StatusInternalServerError)
+		return
 	}
 
-	rows, err := s.db.Query(query, args...)
-	if err != nil && err != sql.ErrNoRows {
+	rows, err := s.db.Query(`
+		SELECT u.id, COALESCE(u.email, ''), u.created_at, u.last_seen,
+		       (SELECT COUNT(*) FROM sessions s WHERE s.user_id = u.id) AS session_count,
+		       (SELECT COALESCE(SUM(CAST(json_extract(al.payload, '$.cost') AS REAL)), 0) FROM audit_log al WHERE al.user_id = u.id) AS total_cost,
+		       (SELECT COALESCE(SUM(CAST(json_extract(al.payload, '$.tokens') AS INTEGER)), 0) FROM audit_log al WHERE al.user_id = u.id) AS total_tokens,
+		       (SELECT COUNT(*) FROM mesh_devices md WHERE md.user_id = u.id) AS device_count
+		FROM users u
+		`+where+`
+		ORDER BY `+orderBy+`, u.id ASC
+		LIMIT ? OFFSET ?
+	`, append(args, page.Limit, page.Offset)...)
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to query users: %v", err), http.StatusInternalServerError)
 		return
 	}
 	defer rows.Close()
 
+	users := []*UserInfo{}
 	for rows.Next() {
 		u := &UserInfo{}
 		if err := rows.Scan(&u.ID, &u.Email, &u.CreatedAt, &u.LastSeen,
 			&u.SessionCount, &u.TotalCost, &u.TotalTokens, &u.DeviceCount); err != nil {
 			continue
 		}
+		u.Status = "inactive"
+		if u.LastSeen != nil && !u.LastSeen.Before(activeSince) {
+			u.Status = "active"
+		}
 		users = append(users, u)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"users":    users,
+		"total":    total,
+		"limit":    page.Limit,
+		"offset":   page.Offset,
+		"has_more": page.Offset+len(users) < total,
+	})
+}
+
+// escapeLike escapes the LIKE wildcards in s for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 // handleAdminDevices returns device list for admin dashboard
@@ -3,15 +3,27 @@ package server
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/store"
+	"pryx-core/internal/validation"
 
 	"github.com/go-chi/chi/v5"
 )
 
 func (s *Server) handleSessionsList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
+	page, err := parsePageParams(r)
+	if err != nil {
+		writePageParamsError(w, err)
+		return
+	}
 	if s.store == nil {
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{"sessions": []interface{}{}})
+		_ = json.NewEncoder(w).Encode(listEnvelope("sessions", []interface{}{}, page))
 		return
 	}
 	sessions, err := s.store.ListSessions()
@@ -24,27 +36,40 @@ func (s *Server) handleSessionsList(w http.ResponseWriter, r *http.Request) {
 	resp := make([]map[string]interface{}, 0, len(sessions))
 	for _, sess := range sessions {
 		resp = append(resp, map[string]interface{}{
-			"id":        sess.ID,
-			"title":     sess.Title,
-			"createdAt": sess.CreatedAt.Format(timeRFC3339),
-			"updatedAt": sess.UpdatedAt.Format(timeRFC3339),
+			"id":          sess.ID,
+			"title":       sess.Title,
+			"description": sess.Description,
+			"timezone":    sess.Timezone,
+			"createdAt":   sess.CreatedAt.Format(timeRFC3339),
+			"updatedAt":   sess.UpdatedAt.Format(timeRFC3339),
 		})
 	}
 
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{"sessions": resp})
+	_ = json.NewEncoder(w).Encode(listEnvelope("sessions", resp, page))
 }
 
+// maxIdempotencyKeyLen bounds the Idempotency-Key header accepted on session creation.
+const maxIdempotencyKeyLen = 255
+
 func (s *Server) handleSessionCreate(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	var req struct {
-		Name  string `json:"name"`
-		Title string `json:"title"`
+		Name      string   `json:"name"`
+		Title     string   `json:"title"`
+		MaxCost   *float64 `json:"max_cost"`
+		MaxTokens *int64   `json:"max_tokens"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
 		return
 	}
+	var budget store.SessionBudget
+	if err := applyBudgetLimits(&budget, req.MaxCost, req.MaxTokens); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
 
 	if s.store == nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -60,22 +85,84 @@ func (s *Server) handleSessionCreate(w http.ResponseWriter, r *http.Request) {
 		title = "Session"
 	}
 
-	sess, err := s.store.CreateSession(title)
+	// A retried create with the same Idempotency-Key gets the session made by
+	// the first request instead of a duplicate.
+	key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+	if len(key) > maxIdempotencyKeyLen {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "Idempotency-Key is too long"})
+		return
+	}
+
+	var (
+		sess    *store.Session
+		created = true
+		err     error
+	)
+	if key != "" {
+		s.cfgMu.RLock()
+		ttl := s.cfg.IdempotencyKeyTTL
+		s.cfgMu.RUnlock()
+		sess, created, err = s.store.CreateSessionIdempotent(key, title, ttl)
+	} else {
+		sess, err = s.store.CreateSession(title)
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	if created && !budget.Empty() {
+		if err := s.store.SetSessionBudget(sess.ID, budget); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+	}
+	budget, _ = s.store.GetSessionBudget(sess.ID)
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(http.StatusOK)
+	}
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"id":        sess.ID,
 		"title":     sess.Title,
+		"budget":    budget,
 		"createdAt": sess.CreatedAt.Format(timeRFC3339),
 		"updatedAt": sess.UpdatedAt.Format(timeRFC3339),
 	})
 }
 
+// applyBudgetLimits sets the limits given in a request on budget. A zero limit
+// removes it; negative limits are rejected.
+func applyBudgetLimits(budget *store.SessionBudget, maxCost *float64, maxTokens *int64) error {
+	if maxCost != nil {
+		switch {
+		case *maxCost < 0:
+			return errors.New("max_cost must not be negative")
+		case *maxCost == 0:
+			budget.MaxCost = nil
+		default:
+			budget.MaxCost = maxCost
+		}
+	}
+	if maxTokens != nil {
+		switch {
+		case *maxTokens < 0:
+			return errors.New("max_tokens must not be negative")
+		case *maxTokens == 0:
+			budget.MaxTokens = nil
+		default:
+			budget.MaxTokens = maxTokens
+		}
+	}
+	return nil
+}
+
 func (s *Server) handleSessionGet(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	sessionID := chi.URLParam(r, "id")
@@ -104,20 +191,170 @@ func (s *Server) handleSessionGet(w http.ResponseWriter, r *http.Request) {
 	}
 
 	msgCount, _ := s.store.GetMessageCount(sessionID)
+	budget, _ := s.store.GetSessionBudget(sessionID)
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"id":           sess.ID,
 		"title":        sess.Title,
+		"description":  sess.Description,
+		"timezone":     sess.Timezone,
+		"budget":       budget,
 		"createdAt":    sess.CreatedAt.Format(timeRFC3339),
 		"updatedAt":    sess.UpdatedAt.Format(timeRFC3339),
 		"messageCount": msgCount,
 	})
 }
 
+// handleSessionStats returns message, token, cost and tool call totals for a session.
+func (s *Server) handleSessionStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	sessionID := chi.URLParam(r, "id")
+
+	if s.store == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "store not available"})
+		return
+	}
+
+	stats, err := s.store.GetSessionStats(sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(stats)
+}
+
+// handleMessagePin pins (POST) or unpins (DELETE) a message. Pinned messages
+// are kept when old messages are cleaned up or compacted.
+func (s *Server) handleMessagePin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	sessionID := chi.URLParam(r, "id")
+	messageID := chi.URLParam(r, "mid")
+
+	if s.store == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "store not available"})
+		return
+	}
+
+	pinned := r.Method != http.MethodDelete
+	if err := s.store.SetMessagePinned(sessionID, messageID, pinned); err != nil {
+		if err == sql.ErrNoRows {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "message not found"})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":        messageID,
+		"sessionId": sessionID,
+		"pinned":    pinned,
+	})
+}
+
+// handleSessionUpdate changes per-session settings. A timezone overrides the
+// channel and runtime default for the session; an empty one clears it.
+// max_cost and max_tokens set the session budget; zero removes a limit.
+func (s *Server) handleSessionUpdate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	sessionID := chi.URLParam(r, "id")
+	var req struct {
+		Timezone  *string  `json:"timezone"`
+		MaxCost   *float64 `json:"max_cost"`
+		MaxTokens *int64   `json:"max_tokens"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if s.store == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "store not available"})
+		return
+	}
+
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if err := config.ValidateTimezone(timezone); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		if err := s.store.SetSessionTimezone(sessionID, timezone); err != nil {
+			if err == sql.ErrNoRows {
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.MaxCost != nil || req.MaxTokens != nil {
+		budget, err := s.store.GetSessionBudget(sessionID)
+		if err == nil {
+			if err := applyBudgetLimits(&budget, req.MaxCost, req.MaxTokens); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+				return
+			}
+			err = s.store.SetSessionBudget(sessionID, budget)
+		}
+		if err != nil {
+			if err == sql.ErrNoRows {
+				w.WriteHeader(http.StatusNotFound)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+				return
+			}
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	sess, err := s.store.GetSession(sessionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	budget, _ := s.store.GetSessionBudget(sessionID)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":          sess.ID,
+		"title":       sess.Title,
+		"description": sess.Description,
+		"timezone":    sess.Timezone,
+		"budget":      budget,
+		"createdAt":   sess.CreatedAt.Format(timeRFC3339),
+		"updatedAt":   sess.UpdatedAt.Format(timeRFC3339),
+	})
+}
+
+// handleSessionDelete deletes a session and its messages. Sessions the agent
+// is still handling are refused with 409 unless ?force=true is given.
 func (s *Server) handleSessionDelete(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "id")
-	if sessionID == "" {
+	if err := validation.NewValidator().ValidateID("id", sessionID); err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"error": "session id is required"})
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
 
@@ -127,11 +364,29 @@ func (s *Server) handleSessionDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	force := r.URL.Query().Get("force") == "true"
+	active := s.activity.Load().Active(sessionID)
+	if active && !force {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "session is active; retry with ?force=true to delete it anyway"})
+		return
+	}
+
 	if err := s.store.DeleteSession(sessionID); err != nil {
+		if err == sql.ErrNoRows {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+			return
+		}
 		w.WriteHeader(http.StatusInternalServerError)
 		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
 		return
 	}
+
+	s.bus.Publish(bus.NewEvent(bus.EventSessionDeleted, sessionID, map[string]interface{}{
+		"session_id": sessionID,
+		"was_active": active,
+	}))
 	w.WriteHeader(http.StatusNoContent)
 }
 
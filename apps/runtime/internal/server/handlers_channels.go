@@ -3,11 +3,14 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"pryx-core/internal/channels"
 	"pryx-core/internal/channels/discord"
+	"pryx-core/internal/channels/mattermost"
 	"pryx-core/internal/channels/slack"
 	"pryx-core/internal/channels/telegram"
 	"pryx-core/internal/channels/webhook"
@@ -27,36 +30,18 @@ type Channel struct {
 }
 
 type ChannelConfig struct {
+	ID     string                 `json:"id,omitempty"`
 	Type   string                 `json:"type"`
 	Name   string                 `json:"name"`
 	Config map[string]interface{} `json:"config"`
 }
 
-type ChannelTestResult struct {
-	Success   bool   `json:"success"`
-	Message   string `json:"message,omitempty"`
-	Latency   string `json:"latency,omitempty"`
-	LastCheck string `json:"last_check,omitempty"`
-}
-
-type HealthStatus struct {
-	Healthy    bool      `json:"healthy"`
-	Status     string    `json:"status"`
-	LastSeen   time.Time `json:"last_seen,omitempty"`
-	Uptime     string    `json:"uptime,omitempty"`
-	ErrorCount int       `json:"error_count"`
-}
-
-type ChannelActivity struct {
-	ID        string    `json:"id"`
-	ChannelID string    `json:"channel_id"`
-	SenderID  string    `json:"sender_id,omitempty"`
-	Content   string    `json:"content,omitempty"`
-	Event     string    `json:"event,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-}
-
 func (s *Server) handleChannelsList(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageParams(r)
+	if err != nil {
+		writePageParamsError(w, err)
+		return
+	}
 	channelsList := []Channel{}
 
 	telegramMgr := telegram.NewConfigManager()
@@ -104,6 +89,21 @@ func (s *Server) handleChannelsList(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	mattermostMgr := mattermost.NewConfigManager()
+	mattermostConfigs, _ := mattermostMgr.List()
+	for _, cfg := range mattermostConfigs {
+		channelsList = append(channelsList, Channel{
+			ID:        cfg.ID,
+			Type:      "mattermost",
+			Name:      cfg.Name,
+			Config:    mattermostConfigToMap(&cfg),
+			Enabled:   cfg.Enabled,
+			Status:    getChannelStatus(cfg.ID),
+			CreatedAt: cfg.CreatedAt,
+			UpdatedAt: cfg.UpdatedAt,
+		})
+	}
+
 	webhookMgr := webhook.NewConfigManager()
 	webhookConfigs, _ := webhookMgr.LoadAll()
 	for _, cfg := range webhookConfigs {
@@ -120,10 +120,9 @@ func (s *Server) handleChannelsList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"channels": channelsList,
-		"count":    len(channelsList),
-	})
+	resp := listEnvelope("channels", channelsList, page)
+	resp["count"] = len(legacyItems(channelsList, page))
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func (s *Server) handleChannelGet(w http.ResponseWriter, r *http.Request) {
@@ -168,18 +167,43 @@ func (s *Server) handleChannelCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A channel with a caller-chosen ID may already be running, e.g. because
+	// it is also set up in the config file, or be stored. Refuse to shadow it
+	// unless the caller asks to replace its configuration.
+	replace := r.URL.Query().Get("replace") == "true"
+	if req.ID != "" && !replace {
+		if err := s.checkChannelIDFree(req.ID); err != nil {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+	if req.ID != "" && replace {
+		if stored := storedChannelType(req.ID); stored != "" && stored != req.Type {
+			w.WriteHeader(http.StatusConflict)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": fmt.Sprintf("channel %s is a %s channel", req.ID, stored),
+			})
+			return
+		}
+	}
+
 	var channel Channel
 	var err error
 
 	switch req.Type {
 	case "telegram":
-		channel, err = s.createTelegramChannel(req.Name, req.Config)
+		channel, err = s.createTelegramChannel(req.ID, req.Name, req.Config)
 	case "slack":
-		channel, err = s.createSlackChannel(req.Name, req.Config)
+		channel, err = s.createSlackChannel(req.ID, req.Name, req.Config)
 	case "discord":
-		channel, err = s.createDiscordChannel(req.Name, req.Config)
+		channel, err = s.createDiscordChannel(req.ID, req.Name, req.Config)
+	case "mattermost":
+		channel, err = s.createMattermostChannel(req.ID, req.Name, req.Config)
 	case "webhook":
-		channel, err = s.createWebhookChannel(req.Name, req.Config)
+		channel, err = s.createWebhookChannel(req.ID, req.Name, req.Config)
 	default:
 		w.WriteHeader(http.StatusBadRequest)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
@@ -196,6 +220,22 @@ func (s *Server) handleChannelCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A replaced channel that is running restarts with its new configuration
+	if replace && s.channels != nil {
+		if _, running := s.channels.Get(channel.ID); running {
+			live, err := s.buildChannel(channel.Type, channel.ID)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"error": fmt.Sprintf("channel saved but not restarted: %v", err),
+				})
+				return
+			}
+			s.channels.Upsert(live)
+			channel.Status = live.Status()
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(channel)
 }
@@ -229,6 +269,8 @@ func (s *Server) handleChannelUpdate(w http.ResponseWriter, r *http.Request) {
 		updated, err = s.updateSlackChannel(id, req.Name, req.Config)
 	case "discord":
 		updated, err = s.updateDiscordChannel(id, req.Name, req.Config)
+	case "mattermost":
+		updated, err = s.updateMattermostChannel(id, req.Name, req.Config)
 	case "webhook":
 		updated, err = s.updateWebhookChannel(id, req.Name, req.Config)
 	default:
@@ -273,6 +315,9 @@ func (s *Server) handleChannelDelete(w http.ResponseWriter, r *http.Request) {
 	case "discord":
 		mgr := discord.NewConfigManager()
 		err = mgr.Delete(id)
+	case "mattermost":
+		mgr := mattermost.NewConfigManager()
+		err = mgr.Delete(id)
 	case "webhook":
 		mgr := webhook.NewConfigManager()
 		_ = mgr.Delete(id)
@@ -308,44 +353,90 @@ func (s *Server) handleChannelTest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result := ChannelTestResult{
-		Success:   true,
-		Message:   "Channel test not yet implemented",
-		LastCheck: time.Now().Format(time.RFC3339),
-	}
+	writeNotImplemented(w, "channel_test")
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(result)
+// channelSelfTestRequest overrides the configured self-test destination.
+type channelSelfTestRequest struct {
+	ChatID     string `json:"chat_id"`
+	ExpectEcho *bool  `json:"expect_echo"`
+	TimeoutMS  int64  `json:"timeout_ms"`
 }
 
-func (s *Server) handleChannelHealth(w http.ResponseWriter, r *http.Request) {
+// handleChannelSelfTest sends a test message through a connected channel to
+// its self-test destination and, when an echo is expected, waits for it to come
+// back. The destination comes from the request body or channel_selftests.
+func (s *Server) handleChannelSelfTest(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	w.Header().Set("Content-Type", "application/json")
 
-	_, err := s.getChannel(id)
-	if err != nil {
+	var ch channels.Channel
+	ok := false
+	if s.channels != nil {
+		ch, ok = s.channels.Get(id)
+	}
+	if !ok {
 		w.WriteHeader(http.StatusNotFound)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"error": err.Error(),
+			"error": "channel not found: " + id,
+		})
+		return
+	}
+
+	var req channelSelfTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "invalid json body",
 		})
 		return
 	}
 
-	health := HealthStatus{
-		Healthy:    true,
-		Status:     string(channels.StatusConnected),
-		LastSeen:   time.Now(),
-		Uptime:     "unknown",
-		ErrorCount: 0,
+	s.cfgMu.RLock()
+	dest := s.cfg.ChannelSelfTests[id]
+	s.cfgMu.RUnlock()
+	opts := channels.SelfTestOptions{ChatID: dest.ChatID, ExpectEcho: dest.ExpectEcho, Timeout: dest.Timeout}
+	if chatID := strings.TrimSpace(req.ChatID); chatID != "" {
+		opts.ChatID = chatID
+	}
+	if req.ExpectEcho != nil {
+		opts.ExpectEcho = *req.ExpectEcho
+	}
+	if req.TimeoutMS > 0 {
+		opts.Timeout = time.Duration(req.TimeoutMS) * time.Millisecond
+	}
+	if opts.ChatID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "no self-test destination: set chat_id or channel_selftests." + id + ".chat_id",
+		})
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(health)
+	if status := ch.Status(); status != channels.StatusConnected {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  "channel is not connected",
+			"status": status,
+		})
+		return
+	}
+
+	res := channels.RunSelfTest(r.Context(), s.bus, ch, opts)
+	switch {
+	case res.OK:
+	case !res.Sent:
+		w.WriteHeader(http.StatusBadGateway)
+	default:
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}
+	_ = json.NewEncoder(w).Encode(res)
 }
 
-func (s *Server) handleChannelConnect(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleChannelHealth(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	_, err := s.getChannel(id)
+	channel, err := s.getChannel(id)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
@@ -356,10 +447,62 @@ func (s *Server) handleChannelConnect(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"ok": true,
+		"id":       channel.ID,
+		"type":     channel.Type,
+		"status":   channel.Status,
+		"healthy":  channel.Status == channels.StatusConnected,
+		"outbox":   s.channels.OutboxStats(id),
+		"throttle": channels.PlatformThrottle(channel.Type).Stats(),
 	})
 }
 
+func (s *Server) handleChannelConnect(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	if s.channels == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": "channel manager not initialized",
+		})
+		return
+	}
+
+	// A stored channel is rebuilt so its token_ref is resolved now; one set up
+	// in the config file is restarted as it is.
+	var live channels.Channel
+	if typ := storedChannelType(id); typ != "" {
+		built, err := s.buildChannel(typ, id)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": err.Error(),
+			})
+			return
+		}
+		live = built
+	} else if running, ok := s.channels.Get(id); ok {
+		live = running
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": fmt.Sprintf("channel not found: %s", id),
+		})
+		return
+	}
+	s.channels.Upsert(live)
+
+	channel, err := s.getChannel(id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(channel)
+}
+
 func (s *Server) handleChannelDisconnect(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -372,12 +515,11 @@ func (s *Server) handleChannelDisconnect(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"ok": true,
-	})
+	writeNotImplemented(w, "channel_disconnect")
 }
 
+// handleChannelActivity returns the newest entries of a channel's activity
+// log, honoring the limit and offset query parameters.
 func (s *Server) handleChannelActivity(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
@@ -390,19 +532,26 @@ func (s *Server) handleChannelActivity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit := 50
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsed, err := parseLimit(l); err == nil {
-			limit = parsed
-		}
+	page, err := parsePageParams(r)
+	if err != nil {
+		writePageParamsError(w, err)
+		return
+	}
+	activity, err := s.store.ListChannelActivity(id, page.Limit, page.Offset)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
 	}
-
-	activity := []ChannelActivity{}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"activity": activity,
-		"limit":    limit,
+		"channel_id": id,
+		"activity":   activity,
+		"limit":      page.Limit,
+		"offset":     page.Offset,
 	})
 }
 
@@ -423,6 +572,11 @@ func (s *Server) handleChannelTypes(w http.ResponseWriter, r *http.Request) {
 			"name":        "Slack",
 			"description": "Connect to Slack channels and DMs",
 		},
+		{
+			"type":        "mattermost",
+			"name":        "Mattermost",
+			"description": "Connect to Mattermost channels and DMs",
+		},
 		{
 			"type":        "webhook",
 			"name":        "Webhook",
@@ -458,14 +612,102 @@ func (s *Server) getChannel(id string) (Channel, error) {
 	}, nil
 }
 
-func getChannelStatus(id string) channels.Status {
-	return channels.StatusDisconnected
+// checkChannelIDFree returns a *channels.DuplicateChannelError if a channel
+// with the given ID is registered with the channel manager or stored.
+func (s *Server) checkChannelIDFree(id string) error {
+	if s.channels != nil {
+		if _, exists := s.channels.Get(id); exists {
+			return &channels.DuplicateChannelError{ID: id}
+		}
+	}
+	if storedChannelType(id) != "" {
+		return &channels.DuplicateChannelError{ID: id}
+	}
+	return nil
+}
+
+// storedChannelType returns the type of the stored channel config with the
+// given ID, or "" if there is none.
+func storedChannelType(id string) string {
+	if _, err := telegram.NewConfigManager().Get(id); err == nil {
+		return "telegram"
+	}
+	if _, err := slack.NewSlackConfigManager().Get(id); err == nil {
+		return "slack"
+	}
+	if _, err := discord.NewConfigManager().Get(id); err == nil {
+		return "discord"
+	}
+	if _, err := mattermost.NewConfigManager().Get(id); err == nil {
+		return "mattermost"
+	}
+	if _, err := webhook.NewConfigManager().Get(id); err == nil {
+		return "webhook"
+	}
+	return ""
+}
+
+// buildChannel creates the channel for the stored config of the given type
+// and ID, with its secrets resolved.
+func (s *Server) buildChannel(typ, id string) (channels.Channel, error) {
+	resolver := s.secretResolver()
+	switch typ {
+	case "telegram":
+		cfg, err := telegram.NewConfigManager().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		token, err := resolver.ResolveTokenRef(cfg.TokenRef)
+		if err != nil {
+			return nil, err
+		}
+		return telegram.NewTelegramChannel(cfg.ID, token, s.bus), nil
+	case "slack":
+		cfg, err := slack.NewSlackConfigManager().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		botToken, err := resolver.ResolveValue(cfg.BotToken)
+		if err != nil {
+			return nil, err
+		}
+		appToken, err := resolver.ResolveValue(cfg.AppToken)
+		if err != nil {
+			return nil, err
+		}
+		return slack.NewSlackChannel(cfg.ID, botToken, appToken, s.bus), nil
+	case "discord":
+		cfg, err := discord.NewConfigManager().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		// bot_token is stored in TokenRef and may be a literal token
+		if cfg.Token, err = resolver.ResolveValue(cfg.TokenRef); err != nil {
+			return nil, err
+		}
+		return discord.NewDiscordChannelFromConfig(*cfg, s.bus), nil
+	case "mattermost":
+		cfg, err := mattermost.NewConfigManager().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		token, err := resolver.ResolveTokenRef(cfg.TokenRef)
+		if err != nil {
+			return nil, err
+		}
+		return mattermost.NewMattermostChannel(cfg.ID, cfg.ServerURL, token, cfg.AllowedChannels, s.bus), nil
+	case "webhook":
+		cfg, err := webhook.NewConfigManager().Get(id)
+		if err != nil {
+			return nil, err
+		}
+		return webhook.NewWebhookChannel(*cfg, s.bus), nil
+	}
+	return nil, fmt.Errorf("unknown channel type: %s", typ)
 }
 
-func parseLimit(s string) (int, error) {
-	var limit int
-	_, err := fmt.Sscanf(s, "%d", &limit)
-	return limit, err
+func getChannelStatus(id string) channels.Status {
+	return channels.StatusDisconnected
 }
 
 func telegramConfigToMap(cfg *telegram.Config) map[string]interface{} {
@@ -498,22 +740,39 @@ func discordConfigToMap(cfg *discord.Config) map[string]interface{} {
 	}
 }
 
+func mattermostConfigToMap(cfg *mattermost.Config) map[string]interface{} {
+	return map[string]interface{}{
+		"server_url":       cfg.ServerURL,
+		"token_ref":        cfg.TokenRef,
+		"allowed_channels": cfg.AllowedChannels,
+	}
+}
+
 func webhookConfigToMap(cfg *webhook.WebhookConfig) map[string]interface{} {
 	return map[string]interface{}{
-		"port":       cfg.Port,
-		"path":       cfg.Path,
-		"secret":     cfg.Secret,
-		"target_url": cfg.TargetURL,
-		"headers":    cfg.Headers,
+		"port":                cfg.Port,
+		"path":                cfg.Path,
+		"secret":              cfg.Secret,
+		"target_url":          cfg.TargetURL,
+		"headers":             cfg.Headers,
+		"signature_header":    cfg.SignatureHeader,
+		"signature_algorithm": cfg.SignatureAlgorithm,
+
+		"inbound_signature_header":    cfg.InboundSignatureHeader,
+		"inbound_signature_algorithm": cfg.InboundSignatureAlgorithm,
 	}
 }
 
-func (s *Server) createTelegramChannel(name string, config map[string]interface{}) (Channel, error) {
+func (s *Server) createTelegramChannel(id string, name string, config map[string]interface{}) (Channel, error) {
 	mgr := telegram.NewConfigManager()
 	cfg := telegram.DefaultConfig()
+	cfg.ID = id
 	cfg.Name = name
 
 	if tokenRef, ok := config["token_ref"].(string); ok {
+		if _, err := s.secretResolver().ResolveTokenRef(tokenRef); err != nil {
+			return Channel{}, err
+		}
 		cfg.TokenRef = tokenRef
 	}
 
@@ -542,15 +801,22 @@ func (s *Server) createTelegramChannel(name string, config map[string]interface{
 	}, nil
 }
 
-func (s *Server) createSlackChannel(name string, config map[string]interface{}) (Channel, error) {
+func (s *Server) createSlackChannel(id string, name string, config map[string]interface{}) (Channel, error) {
 	mgr := slack.NewSlackConfigManager()
 	cfg := slack.NewBotConfig(name, "", "")
+	cfg.ID = id
 
 	if botToken, ok := config["bot_token"].(string); ok {
+		if err := s.validateSecretRef(botToken); err != nil {
+			return Channel{}, err
+		}
 		cfg.BotToken = botToken
 	}
 
 	if appToken, ok := config["app_token"].(string); ok {
+		if err := s.validateSecretRef(appToken); err != nil {
+			return Channel{}, err
+		}
 		cfg.AppToken = appToken
 	}
 
@@ -571,12 +837,16 @@ func (s *Server) createSlackChannel(name string, config map[string]interface{})
 	}, nil
 }
 
-func (s *Server) createDiscordChannel(name string, config map[string]interface{}) (Channel, error) {
+func (s *Server) createDiscordChannel(id string, name string, config map[string]interface{}) (Channel, error) {
 	mgr := discord.NewConfigManager()
 	cfg := discord.DefaultConfig()
+	cfg.ID = id
 	cfg.Name = name
 
 	if botToken, ok := config["bot_token"].(string); ok {
+		if err := s.validateSecretRef(botToken); err != nil {
+			return Channel{}, err
+		}
 		cfg.TokenRef = botToken
 	}
 
@@ -597,9 +867,52 @@ func (s *Server) createDiscordChannel(name string, config map[string]interface{}
 	}, nil
 }
 
-func (s *Server) createWebhookChannel(name string, config map[string]interface{}) (Channel, error) {
+func (s *Server) createMattermostChannel(id string, name string, config map[string]interface{}) (Channel, error) {
+	mgr := mattermost.NewConfigManager()
+	cfg := mattermost.DefaultConfig()
+	cfg.ID = id
+	cfg.Name = name
+
+	if serverURL, ok := config["server_url"].(string); ok {
+		cfg.ServerURL = serverURL
+	}
+
+	if tokenRef, ok := config["token_ref"].(string); ok {
+		if _, err := s.secretResolver().ResolveTokenRef(tokenRef); err != nil {
+			return Channel{}, err
+		}
+		cfg.TokenRef = tokenRef
+	}
+
+	if allowed, ok := config["allowed_channels"].([]interface{}); ok {
+		for _, v := range allowed {
+			if channelID, ok := v.(string); ok {
+				cfg.AllowedChannels = append(cfg.AllowedChannels, channelID)
+			}
+		}
+	}
+
+	created, err := mgr.Create(cfg)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return Channel{
+		ID:        created.ID,
+		Type:      "mattermost",
+		Name:      created.Name,
+		Config:    mattermostConfigToMap(created),
+		Enabled:   created.Enabled,
+		Status:    channels.StatusDisconnected,
+		CreatedAt: created.CreatedAt,
+		UpdatedAt: created.UpdatedAt,
+	}, nil
+}
+
+func (s *Server) createWebhookChannel(id string, name string, config map[string]interface{}) (Channel, error) {
 	mgr := webhook.NewConfigManager()
 	cfg := webhook.WebhookConfig{
+		ID:      id,
 		Name:    name,
 		Enabled: true,
 	}
@@ -620,6 +933,28 @@ func (s *Server) createWebhookChannel(name string, config map[string]interface{}
 		cfg.TargetURL = targetURL
 	}
 
+	if header, ok := config["signature_header"].(string); ok {
+		cfg.SignatureHeader = header
+	}
+
+	if alg, ok := config["signature_algorithm"].(string); ok {
+		if err := webhook.ValidateSignatureAlgorithm(alg); err != nil {
+			return Channel{}, err
+		}
+		cfg.SignatureAlgorithm = alg
+	}
+
+	if header, ok := config["inbound_signature_header"].(string); ok {
+		cfg.InboundSignatureHeader = header
+	}
+
+	if alg, ok := config["inbound_signature_algorithm"].(string); ok {
+		if err := webhook.ValidateSignatureAlgorithm(alg); err != nil {
+			return Channel{}, err
+		}
+		cfg.InboundSignatureAlgorithm = alg
+	}
+
 	if headers, ok := config["headers"].(map[string]interface{}); ok {
 		cfg.Headers = map[string]string{}
 		for k, v := range headers {
@@ -721,6 +1056,37 @@ func (s *Server) updateDiscordChannel(id string, name string, config map[string]
 	}, nil
 }
 
+func (s *Server) updateMattermostChannel(id string, name string, config map[string]interface{}) (Channel, error) {
+	mgr := mattermost.NewConfigManager()
+	updates := config
+
+	if name != "" {
+		updates["name"] = name
+	}
+
+	if tokenRef, ok := updates["token_ref"].(string); ok {
+		if err := s.validateSecretRef(tokenRef); err != nil {
+			return Channel{}, err
+		}
+	}
+
+	updated, err := mgr.Update(id, updates)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return Channel{
+		ID:        updated.ID,
+		Type:      "mattermost",
+		Name:      updated.Name,
+		Config:    mattermostConfigToMap(updated),
+		Enabled:   updated.Enabled,
+		Status:    channels.StatusDisconnected,
+		CreatedAt: updated.CreatedAt,
+		UpdatedAt: updated.UpdatedAt,
+	}, nil
+}
+
 func (s *Server) updateWebhookChannel(id string, name string, config map[string]interface{}) (Channel, error) {
 	mgr := webhook.NewConfigManager()
 
@@ -749,6 +1115,28 @@ func (s *Server) updateWebhookChannel(id string, name string, config map[string]
 		updated.TargetURL = targetURL
 	}
 
+	if header, ok := config["signature_header"].(string); ok {
+		updated.SignatureHeader = header
+	}
+
+	if alg, ok := config["signature_algorithm"].(string); ok {
+		if err := webhook.ValidateSignatureAlgorithm(alg); err != nil {
+			return Channel{}, err
+		}
+		updated.SignatureAlgorithm = alg
+	}
+
+	if header, ok := config["inbound_signature_header"].(string); ok {
+		updated.InboundSignatureHeader = header
+	}
+
+	if alg, ok := config["inbound_signature_algorithm"].(string); ok {
+		if err := webhook.ValidateSignatureAlgorithm(alg); err != nil {
+			return Channel{}, err
+		}
+		updated.InboundSignatureAlgorithm = alg
+	}
+
 	if headers, ok := config["headers"].(map[string]interface{}); ok {
 		updated.Headers = map[string]string{}
 		for k, v := range headers {
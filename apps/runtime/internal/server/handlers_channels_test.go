@@ -1,12 +1,30 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+	"pryx-core/internal/config"
+	"pryx-core/internal/store"
 )
 
+type stubChannel struct{ id string }
+
+func (c *stubChannel) ID() string                                           { return c.id }
+func (c *stubChannel) Type() string                                         { return "telegram" }
+func (c *stubChannel) Connect(ctx context.Context) error                    { return nil }
+func (c *stubChannel) Disconnect(ctx context.Context) error                 { return nil }
+func (c *stubChannel) Send(ctx context.Context, msg channels.Message) error { return nil }
+func (c *stubChannel) Status() channels.Status                              { return channels.StatusConnected }
+
 func TestHandleChannelTypes(t *testing.T) {
 	s := &Server{}
 	s.channels = nil
@@ -26,10 +44,302 @@ func TestHandleChannelTypes(t *testing.T) {
 	}
 
 	if types, ok := result["types"].([]interface{}); ok {
-		if len(types) != 4 {
-			t.Errorf("expected 4 channel types, got %d", len(types))
+		if len(types) != 5 {
+			t.Errorf("expected 5 channel types, got %d", len(types))
 		}
 	} else {
 		t.Error("expected types in response")
 	}
 }
+
+func TestHandleChannelCreateDuplicateID(t *testing.T) {
+	s := &Server{channels: channels.NewManager(bus.New())}
+	defer s.channels.Shutdown()
+	if err := s.channels.Register(&stubChannel{id: "telegram-main"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	body := `{"id":"telegram-main","type":"telegram","name":"Main bot"}`
+	req := httptest.NewRequest("POST", "/api/v1/channels", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	s.handleChannelCreate(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "already registered") {
+		t.Errorf("expected duplicate error in body, got %s", w.Body.String())
+	}
+}
+
+func TestHandleChannelCreateStoredID(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := &Server{channels: channels.NewManager(bus.New())}
+	defer s.channels.Shutdown()
+
+	create := func(query, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/channels"+query, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		s.handleChannelCreate(w, req)
+		return w
+	}
+
+	body := `{"id":"hook","type":"webhook","name":"Hook","config":{"target_url":"http://127.0.0.1:1/out"}}`
+	if w := create("", body); w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if w := create("", body); w.Code != http.StatusConflict {
+		t.Fatalf("expected status %d for a stored ID, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+	}
+	if w := create("?replace=true", `{"id":"hook","type":"telegram","name":"Hook"}`); w.Code != http.StatusConflict {
+		t.Fatalf("expected status %d for a type change, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+	}
+
+	if err := s.channels.Register(&stubChannel{id: "hook"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if w := create("?replace=true", body); w.Code != http.StatusOK {
+		t.Fatalf("expected status %d for a replace, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	live, ok := s.channels.Get("hook")
+	if !ok || live.Type() != "webhook" {
+		t.Errorf("expected the running channel to be replaced by the stored webhook, got %#v", live)
+	}
+}
+
+func TestHandleChannelConnectResolvesTokenRef(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PRYX_TEST_TG_TOKEN", "123:abc")
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("store.New failed: %v", err)
+	}
+	defer st.Close()
+	s := New(&config.Config{ListenAddr: ":0"}, st.DB, newTestKeychain(t))
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		return w
+	}
+
+	if w := post("/api/v1/channels", `{"id":"hook","type":"webhook","name":"Hook"}`); w.Code != http.StatusOK {
+		t.Fatalf("create webhook: status %d: %s", w.Code, w.Body.String())
+	}
+	if w := post("/api/v1/channels/hook/connect", ""); w.Code != http.StatusOK {
+		t.Fatalf("connect webhook: status %d: %s", w.Code, w.Body.String())
+	}
+	if live, ok := s.channels.Get("hook"); !ok || live.Type() != "webhook" {
+		t.Errorf("expected the stored webhook to be running, got %#v", live)
+	}
+
+	body := `{"id":"tg","type":"telegram","name":"Bot","config":{"token_ref":"env://PRYX_TEST_TG_TOKEN"}}`
+	if w := post("/api/v1/channels", body); w.Code != http.StatusOK {
+		t.Fatalf("create telegram: status %d: %s", w.Code, w.Body.String())
+	}
+	os.Unsetenv("PRYX_TEST_TG_TOKEN")
+	if w := post("/api/v1/channels/tg/connect", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unresolvable token_ref, got %d", http.StatusBadRequest, w.Code)
+	}
+	if _, ok := s.channels.Get("tg"); ok {
+		t.Error("expected a channel whose token does not resolve to stay stopped")
+	}
+
+	if w := post("/api/v1/channels/nope/connect", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown channel, got %d", http.StatusNotFound, w.Code)
+	}
+
+	// Mattermost token_ref names a keychain entry, as for Telegram.
+	body = `{"type":"mattermost","name":"MM","config":{"server_url":"https://mm.example.com","token_ref":"missing-entry"}}`
+	if w := post("/api/v1/channels", body); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a missing keychain entry, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestChannelStubsNotImplemented(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("store.New failed: %v", err)
+	}
+	defer st.Close()
+	s := New(&config.Config{ListenAddr: ":0"}, st.DB, newTestKeychain(t))
+	if err := s.Channels().Register(&stubChannel{id: "telegram-main"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	for feature, route := range stubRoutes {
+		method, pattern, _ := strings.Cut(route, " ")
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(method, strings.Replace(pattern, "{id}", "telegram-main", 1), nil))
+		if w.Code != http.StatusNotImplemented {
+			t.Errorf("%s: expected status %d, got %d", route, http.StatusNotImplemented, w.Code)
+		}
+		var body map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: failed to unmarshal response: %v", route, err)
+		}
+		if body["feature"] != feature || !strings.Contains(body["error"], "not implemented") {
+			t.Errorf("%s: unexpected body %v", route, body)
+		}
+	}
+
+	// Unknown channels are still reported as missing.
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/channels/nope/test", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown channel, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+// echoChannel delivers sent messages back to the bus as inbound messages.
+type echoChannel struct {
+	stubChannel
+	bus *bus.Bus
+}
+
+func (c *echoChannel) Send(ctx context.Context, msg channels.Message) error {
+	c.bus.Publish(bus.NewEvent(bus.EventChannelMessage, "", channels.Message{Content: msg.Content, Source: c.id, ChannelID: msg.ChannelID}))
+	return nil
+}
+
+func TestHandleChannelSelfTest(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("store.New failed: %v", err)
+	}
+	defer st.Close()
+	cfg := &config.Config{ListenAddr: ":0", ChannelSelfTests: map[string]config.ChannelSelfTest{
+		"hook": {ChatID: "loopback", ExpectEcho: true},
+	}}
+	s := New(cfg, st.DB, newTestKeychain(t))
+	if err := s.Channels().Register(&echoChannel{stubChannel: stubChannel{id: "hook"}, bus: s.bus}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := s.Channels().Register(&stubChannel{id: "telegram-main"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	post := func(id, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/channels/"+id+"/selftest", strings.NewReader(body)))
+		return w
+	}
+
+	w := post("hook", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var res channels.SelfTestResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if !res.OK || !res.EchoReceived || res.ChatID != "loopback" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	// A channel without a configured destination needs one in the request
+	if w := post("telegram-main", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a destination, got %d", w.Code)
+	}
+	w = post("telegram-main", `{"chat_id":"12345"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 with a destination, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil || !res.OK || res.EchoExpected {
+		t.Fatalf("unexpected send-only result %+v (%v)", res, err)
+	}
+
+	// The stub never echoes, so waiting for one times out
+	if w := post("telegram-main", `{"chat_id":"12345","expect_echo":true,"timeout_ms":20}`); w.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected 504 without an echo, got %d", w.Code)
+	}
+	if w := post("nope", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown channel, got %d", w.Code)
+	}
+}
+
+func TestHandleChannelHealth(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("store.New failed: %v", err)
+	}
+	defer st.Close()
+	s := New(&config.Config{ListenAddr: ":0"}, st.DB, newTestKeychain(t))
+	if err := s.Channels().Register(&stubChannel{id: "telegram-main"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/channels/telegram-main/health", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var body struct {
+		ID       string                 `json:"id"`
+		Healthy  bool                   `json:"healthy"`
+		Outbox   channels.OutboxStats   `json:"outbox"`
+		Throttle channels.ThrottleStats `json:"throttle"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if body.ID != "telegram-main" || !body.Healthy || body.Outbox.Depth != 0 || body.Outbox.Capacity != channels.DefaultOutboxSize {
+		t.Errorf("unexpected health: %s", w.Body.String())
+	}
+	if body.Throttle.Platform != "telegram" || body.Throttle.RequestsPerSecond <= 0 {
+		t.Errorf("expected the Telegram throttle state, got %+v", body.Throttle)
+	}
+
+	w = httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/channels/missing/health", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for an unknown channel, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestHandleChannelActivity(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatalf("store.New failed: %v", err)
+	}
+	defer st.Close()
+	s := New(&config.Config{ListenAddr: ":0"}, st.DB, newTestKeychain(t))
+	if err := s.Channels().Register(&stubChannel{id: "telegram-main"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	for _, content := range []string{"first", "second"} {
+		s.Bus().Publish(bus.NewEvent(bus.EventChannelMessage, "", channels.Message{Source: "telegram-main", ChannelID: "42", SenderID: "7", Content: content}))
+	}
+
+	var body struct {
+		Activity []channels.Activity `json:"activity"`
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/channels/telegram-main/activity?limit=1", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if len(body.Activity) == 1 && body.Activity[0].Snippet == "second" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for activity, got %s", w.Body.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if body.Activity[0].EventType != channels.ActivityInbound || body.Activity[0].SenderID != "7" {
+		t.Errorf("unexpected entry: %+v", body.Activity[0])
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/channels/telegram-main/activity?limit=abc", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for a bad limit, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"pryx-core/internal/mcp"
 	"pryx-core/internal/mcp/discovery"
 	"pryx-core/internal/validation"
 )
@@ -156,6 +157,12 @@ func (s *Server) handleMCPDiscoveryAddCustom(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if err := s.mcpServerPolicy().Check(req.Name, mcp.ServerConfig{URL: req.URL}); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
 	entry, err := s.mcpDiscovery.AddCustomServer(req.Name, req.URL)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -207,3 +214,30 @@ func (s *Server) handleMCPDiscoveryRemoveCustom(w http.ResponseWriter, r *http.R
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// mcpServerPolicy returns the admin-configured policy for adding MCP servers.
+func (s *Server) mcpServerPolicy() *mcp.ServerPolicy {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return mcp.NewServerPolicy(s.cfg.MCPServerPolicy.Allow, s.cfg.MCPServerPolicy.Deny)
+}
+
+// handleAdminMCPPolicy returns the MCP server allow and deny lists.
+func (s *Server) handleAdminMCPPolicy(w http.ResponseWriter, r *http.Request) {
+	s.cfgMu.RLock()
+	policy := s.cfg.MCPServerPolicy
+	s.cfgMu.RUnlock()
+
+	if policy.Allow == nil {
+		policy.Allow = []string{}
+	}
+	if policy.Deny == nil {
+		policy.Deny = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"allow":      policy.Allow,
+		"deny":       policy.Deny,
+		"restricted": len(policy.Allow) > 0 || len(policy.Deny) > 0,
+	})
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"pryx-core/internal/mcp"
+	"pryx-core/internal/validation"
+)
+
+// Limits for POST /mcp/tools/call-batch. The parallelism and timeout defaults
+// match the agent's, which the config can change for both.
+const (
+	maxMCPBatchCalls        = 32
+	defaultMCPBatchParallel = 4
+	// defaultMCPBatchTimeout exceeds the MCP approval window so approvals are not cut short.
+	defaultMCPBatchTimeout = 3 * time.Minute
+)
+
+// mcpBatchCall is one call of a batch. TimeoutMS can shorten, but not extend,
+// the per-call timeout.
+type mcpBatchCall struct {
+	ID        string                 `json:"id,omitempty"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+	TimeoutMS int64                  `json:"timeout_ms,omitempty"`
+}
+
+// mcpBatchRequest represents a request to call several MCP tools at once.
+type mcpBatchRequest struct {
+	SessionID string         `json:"session_id"`
+	Calls     []mcpBatchCall `json:"calls"`
+}
+
+// mcpBatchResult is the outcome of one call of a batch. Exactly one of Result
+// and Error is set.
+type mcpBatchResult struct {
+	Index      int                    `json:"index"`
+	ID         string                 `json:"id,omitempty"`
+	Tool       string                 `json:"tool"`
+	OK         bool                   `json:"ok"`
+	Result     *mcp.ToolResult        `json:"result,omitempty"`
+	Error      map[string]interface{} `json:"error,omitempty"`
+	DurationMS int64                  `json:"duration_ms"`
+}
+
+// mcpInvoker executes one tool call; mcp.Manager.CallTool satisfies it.
+type mcpInvoker func(ctx context.Context, sessionID, tool string, args map[string]interface{}) (mcp.ToolResult, error)
+
+// handleMCPCallBatch executes several MCP tool calls with bounded parallelism
+// and returns their results in request order. Each call is validated and run
+// independently: one failing call does not fail the others or the request.
+func (s *Server) handleMCPCallBatch(w http.ResponseWriter, r *http.Request) {
+	if s.rejectIfMaintenance(w) {
+		return
+	}
+
+	req := mcpBatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": "invalid json body",
+		})
+		return
+	}
+
+	validator := validation.NewValidator()
+	if err := validator.ValidateSessionID(req.SessionID); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Calls) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": "calls is required",
+		})
+		return
+	}
+	if len(req.Calls) > maxMCPBatchCalls {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"error": fmt.Sprintf("too many calls: %d (max %d)", len(req.Calls), maxMCPBatchCalls),
+		})
+		return
+	}
+
+	s.cfgMu.RLock()
+	maxParallel := s.cfg.AgentMaxParallelTools
+	timeout := s.cfg.AgentToolTimeout
+	s.cfgMu.RUnlock()
+
+	ctx := mcp.WithRequester(r.Context(), mcp.Requester{Kind: "api", ID: r.RemoteAddr, Name: "An API client"})
+	results := runMCPBatch(ctx, s.mcp.CallTool, strings.TrimSpace(req.SessionID), req.Calls, maxParallel, timeout)
+
+	failed := 0
+	for _, res := range results {
+		if !res.OK {
+			failed++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"results": results,
+		"count":   len(results),
+		"failed":  failed,
+	})
+}
+
+// runMCPBatch validates and runs calls, at most maxParallel at a time, and
+// returns one result per call in the same order. Non-positive limits fall
+// back to the defaults.
+func runMCPBatch(ctx context.Context, invoke mcpInvoker, sessionID string, calls []mcpBatchCall, maxParallel int, timeout time.Duration) []mcpBatchResult {
+	if maxParallel <= 0 {
+		maxParallel = defaultMCPBatchParallel
+	}
+	if timeout <= 0 {
+		timeout = defaultMCPBatchTimeout
+	}
+
+	results := make([]mcpBatchResult, len(calls))
+	sem := make(chan struct{}, maxParallel)
+	validator := validation.NewValidator()
+
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		results[i] = mcpBatchResult{Index: i, ID: call.ID, Tool: call.Tool}
+		if err := validateMCPBatchCall(validator, &call); err != nil {
+			results[i].Error = (&mcp.ToolError{Code: mcp.ToolErrInvalidArguments, Message: err.Error()}).Payload()
+			continue
+		}
+
+		callTimeout := timeout
+		if call.TimeoutMS > 0 {
+			if d := time.Duration(call.TimeoutMS) * time.Millisecond; d < callTimeout {
+				callTimeout = d
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, call mcpBatchCall) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].Error = mcp.ClassifyToolError(ctx.Err()).Payload()
+				return
+			}
+			defer func() { <-sem }()
+			runMCPBatchCall(ctx, invoke, sessionID, call, callTimeout, &results[i])
+		}(i, call)
+	}
+	wg.Wait()
+	return results
+}
+
+func validateMCPBatchCall(validator *validation.Validator, call *mcpBatchCall) error {
+	if err := validateMCPToolName(validator, call.Tool); err != nil {
+		return err
+	}
+	if call.Arguments == nil {
+		call.Arguments = map[string]interface{}{}
+	}
+	if err := validator.ValidateMap("arguments", call.Arguments); err != nil {
+		return err
+	}
+	if call.TimeoutMS < 0 {
+		return fmt.Errorf("timeout_ms must not be negative")
+	}
+	return nil
+}
+
+// validateMCPToolName validates a tool name as the MCP manager expects it,
+// "server:tool" or "server/tool", checking the server and tool parts
+// separately since the separator itself is not a valid name character.
+func validateMCPToolName(validator *validation.Validator, name string) error {
+	server, tool, ok := strings.Cut(name, ":")
+	if !ok {
+		server, tool, ok = strings.Cut(name, "/")
+	}
+	if !ok {
+		return validator.ValidateToolName(name)
+	}
+	if err := validator.ValidateToolName(server); err != nil {
+		return err
+	}
+	return validator.ValidateToolName(tool)
+}
+
+func runMCPBatchCall(ctx context.Context, invoke mcpInvoker, sessionID string, call mcpBatchCall, timeout time.Duration, out *mcpBatchResult) {
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	res, err := invoke(callCtx, sessionID, call.Tool, call.Arguments)
+	out.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		if callCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			err = &mcp.ToolError{
+				Code:      mcp.ToolErrTimeout,
+				Message:   fmt.Sprintf("tool %s timed out after %s: %v", call.Tool, timeout, err),
+				Retriable: true,
+				Err:       err,
+			}
+		}
+		out.Error = mcp.ClassifyToolError(err).Payload()
+		return
+	}
+	out.OK = true
+	out.Result = &res
+}
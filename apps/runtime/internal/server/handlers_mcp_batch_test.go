@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pryx-core/internal/config"
+	"pryx-core/internal/mcp"
+	"pryx-core/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMCPBatch(t *testing.T) {
+	var running, peak int32
+	invoke := func(ctx context.Context, sessionID, tool string, args map[string]interface{}) (mcp.ToolResult, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+
+		switch tool {
+		case "fs:slow":
+			<-ctx.Done()
+			return mcp.ToolResult{}, ctx.Err()
+		case "fs:broken":
+			return mcp.ToolResult{}, errors.New("boom")
+		}
+		time.Sleep(20 * time.Millisecond)
+		return mcp.ToolResult{Content: []mcp.ToolContent{{Type: "text", Text: tool + ":" + args["path"].(string)}}}, nil
+	}
+
+	calls := []mcpBatchCall{
+		{ID: "a", Tool: "fs:read", Arguments: map[string]interface{}{"path": "a"}},
+		{ID: "b", Tool: "fs:not a tool!"},
+		{ID: "c", Tool: "fs:slow", TimeoutMS: 30},
+		{ID: "d", Tool: "fs:read", Arguments: map[string]interface{}{"path": "d"}},
+		{ID: "e", Tool: "fs:broken"},
+		{ID: "f", Tool: "fs:read", Arguments: map[string]interface{}{"path": "f"}},
+	}
+	results := runMCPBatch(context.Background(), invoke, "", calls, 2, time.Minute)
+
+	require.Len(t, results, len(calls))
+	for i, res := range results {
+		assert.Equal(t, i, res.Index)
+		assert.Equal(t, calls[i].ID, res.ID)
+	}
+
+	for _, i := range []int{0, 3, 5} {
+		require.True(t, results[i].OK, "call %d: %v", i, results[i].Error)
+		assert.Equal(t, "fs:read:"+calls[i].Arguments["path"].(string), results[i].Result.Content[0].Text)
+	}
+	assert.Equal(t, string(mcp.ToolErrInvalidArguments), results[1].Error["code"])
+	assert.Equal(t, string(mcp.ToolErrTimeout), results[2].Error["code"])
+	assert.Equal(t, true, results[2].Error["retriable"])
+	assert.False(t, results[4].OK)
+	assert.Contains(t, results[4].Error["error"], "boom")
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+}
+
+func TestHandleMCPCallBatch(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0"}, s.DB, newTestKeychain(t))
+
+	for _, tc := range []struct {
+		name string
+		body string
+	}{
+		{"invalid json", "invalid json"},
+		{"no calls", `{"calls":[]}`},
+		{"bad session", `{"session_id":"nope","calls":[{"tool":"fs:read"}]}`},
+		{"too many calls", `{"calls":[` + strings.Repeat(`{"tool":"fs:read"},`, maxMCPBatchCalls) + `{"tool":"fs:read"}]}`},
+	} {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp/tools/call-batch", strings.NewReader(tc.body)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, tc.name)
+	}
+
+	// Individual failures do not fail the request.
+	body := `{"calls":[{"id":"x","tool":"nosuch:tool"},{"id":"y","tool":""}]}`
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp/tools/call-batch", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp struct {
+		Results []mcpBatchResult `json:"results"`
+		Count   int              `json:"count"`
+		Failed  int              `json:"failed"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Count)
+	assert.Equal(t, 2, resp.Failed)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "x", resp.Results[0].ID)
+	assert.Equal(t, string(mcp.ToolErrNotFound), resp.Results[0].Error["code"])
+	assert.Equal(t, "y", resp.Results[1].ID)
+	assert.Equal(t, string(mcp.ToolErrInvalidArguments), resp.Results[1].Error["code"])
+}
@@ -2,11 +2,14 @@ package server
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
+	"pryx-core/internal/config"
 	"pryx-core/internal/scheduler"
 
 	"github.com/go-chi/chi/v5"
@@ -14,23 +17,31 @@ import (
 
 // Scheduled task request/response types
 type CreateTaskRequest struct {
-	Name           string `json:"name"`
-	Description    string `json:"description"`
-	CronExpression string `json:"cron_expression"`
-	TaskType       string `json:"task_type"`
-	Payload        string `json:"payload"`
-	Timezone       string `json:"timezone"`
-	Enabled        bool   `json:"enabled"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	CronExpression string   `json:"cron_expression"`
+	TaskType       string   `json:"task_type"`
+	Payload        string   `json:"payload"`
+	Timezone       string   `json:"timezone"`
+	Enabled        bool     `json:"enabled"`
+	MaxRetries     int      `json:"max_retries"`
+	RetryBackoff   string   `json:"retry_backoff"`
+	CatchUp        bool     `json:"catch_up"`
+	DependsOn      []string `json:"depends_on"`
 }
 
 type UpdateTaskRequest struct {
-	Name           *string `json:"name,omitempty"`
-	Description    *string `json:"description,omitempty"`
-	CronExpression *string `json:"cron_expression,omitempty"`
-	TaskType       *string `json:"task_type,omitempty"`
-	Payload        *string `json:"payload,omitempty"`
-	Timezone       *string `json:"timezone,omitempty"`
-	Enabled        *bool   `json:"enabled,omitempty"`
+	Name           *string   `json:"name,omitempty"`
+	Description    *string   `json:"description,omitempty"`
+	CronExpression *string   `json:"cron_expression,omitempty"`
+	TaskType       *string   `json:"task_type,omitempty"`
+	Payload        *string   `json:"payload,omitempty"`
+	Timezone       *string   `json:"timezone,omitempty"`
+	Enabled        *bool     `json:"enabled,omitempty"`
+	MaxRetries     *int      `json:"max_retries,omitempty"`
+	RetryBackoff   *string   `json:"retry_backoff,omitempty"`
+	CatchUp        *bool     `json:"catch_up,omitempty"`
+	DependsOn      *[]string `json:"depends_on,omitempty"`
 }
 
 type TaskResponse struct {
@@ -42,6 +53,11 @@ type TaskResponse struct {
 	Payload        string     `json:"payload"`
 	Timezone       string     `json:"timezone"`
 	Enabled        bool       `json:"enabled"`
+	MaxRetries     int        `json:"max_retries"`
+	RetryBackoff   string     `json:"retry_backoff,omitempty"`
+	Status         string     `json:"status,omitempty"`
+	CatchUp        bool       `json:"catch_up"`
+	DependsOn      []string   `json:"depends_on,omitempty"`
 	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
 	LastRunStatus  string     `json:"last_run_status,omitempty"`
 	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
@@ -56,8 +72,29 @@ type RunResponse struct {
 	StartedAt   time.Time  `json:"started_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	Status      string     `json:"status"`
+	Trigger     string     `json:"trigger"`
 	Error       string     `json:"error,omitempty"`
 	Output      string     `json:"output,omitempty"`
+	Attempt     int        `json:"attempt"`
+}
+
+func runResponse(run *scheduler.TaskRun) RunResponse {
+	return RunResponse{
+		ID:          run.ID,
+		TaskID:      run.TaskID,
+		StartedAt:   run.StartedAt,
+		CompletedAt: run.CompletedAt,
+		Status:      string(run.Status),
+		Trigger:     string(run.Trigger),
+		Error:       run.Error,
+		Output:      run.Output,
+		Attempt:     run.Attempt,
+	}
+}
+
+// isTaskDependencyError reports whether err rejects a task's DependsOn.
+func isTaskDependencyError(err error) bool {
+	return errors.Is(err, scheduler.ErrDependencyCycle) || errors.Is(err, scheduler.ErrUnknownDependency)
 }
 
 // handleTasksList returns all scheduled tasks for the user
@@ -81,6 +118,11 @@ func (s *Server) handleTasksList(w http.ResponseWriter, r *http.Request) {
 			Payload:        task.Payload,
 			Timezone:       task.Timezone,
 			Enabled:        task.Enabled,
+			MaxRetries:     task.MaxRetries,
+			RetryBackoff:   task.RetryBackoff,
+			Status:         string(task.Status),
+			CatchUp:        task.CatchUp,
+			DependsOn:      task.DependsOn,
 			LastRunAt:      task.LastRunAt,
 			LastRunStatus:  task.LastRunStatus,
 			NextRunAt:      task.NextRunAt,
@@ -117,6 +159,11 @@ func (s *Server) handleTaskGet(w http.ResponseWriter, r *http.Request) {
 		Payload:        task.Payload,
 		Timezone:       task.Timezone,
 		Enabled:        task.Enabled,
+		MaxRetries:     task.MaxRetries,
+		RetryBackoff:   task.RetryBackoff,
+		Status:         string(task.Status),
+		CatchUp:        task.CatchUp,
+		DependsOn:      task.DependsOn,
 		LastRunAt:      task.LastRunAt,
 		LastRunStatus:  task.LastRunStatus,
 		NextRunAt:      task.NextRunAt,
@@ -144,17 +191,33 @@ func (s *Server) handleTaskCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate task type
-	validTypes := map[string]bool{
-		string(scheduler.TaskTypeMessage):  true,
-		string(scheduler.TaskTypeWorkflow): true,
-		string(scheduler.TaskTypeReminder): true,
-		string(scheduler.TaskTypeWebhook):  true,
-	}
-	if !validTypes[req.TaskType] {
+	if !scheduler.IsValidTaskType(scheduler.TaskType(req.TaskType)) {
 		http.Error(w, fmt.Sprintf("Invalid task type: %s", req.TaskType), http.StatusBadRequest)
 		return
 	}
 
+	if err := s.validateTaskModel(req.Payload); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid payload model: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if scheduler.TaskType(req.TaskType) == scheduler.TaskTypeWebhook {
+		if _, err := scheduler.ParseWebhookPayload(req.Payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := config.ValidateTimezone(req.Timezone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := scheduler.ValidateRetryPolicy(req.MaxRetries, req.RetryBackoff); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	task := &scheduler.ScheduledTask{
 		Name:           req.Name,
 		Description:    req.Description,
@@ -163,9 +226,17 @@ func (s *Server) handleTaskCreate(w http.ResponseWriter, r *http.Request) {
 		Payload:        req.Payload,
 		Timezone:       req.Timezone,
 		Enabled:        req.Enabled,
+		MaxRetries:     req.MaxRetries,
+		RetryBackoff:   req.RetryBackoff,
+		CatchUp:        req.CatchUp,
+		DependsOn:      req.DependsOn,
 	}
 
 	if err := s.scheduler.CreateTask(task); err != nil {
+		if isTaskDependencyError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to create task: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -181,6 +252,11 @@ func (s *Server) handleTaskCreate(w http.ResponseWriter, r *http.Request) {
 		Payload:        task.Payload,
 		Timezone:       task.Timezone,
 		Enabled:        task.Enabled,
+		MaxRetries:     task.MaxRetries,
+		RetryBackoff:   task.RetryBackoff,
+		Status:         string(task.Status),
+		CatchUp:        task.CatchUp,
+		DependsOn:      task.DependsOn,
 		NextRunAt:      task.NextRunAt,
 		CreatedAt:      task.CreatedAt,
 		UpdatedAt:      task.UpdatedAt,
@@ -225,16 +301,50 @@ func (s *Server) handleTaskUpdate(w http.ResponseWriter, r *http.Request) {
 		task.TaskType = scheduler.TaskType(*req.TaskType)
 	}
 	if req.Payload != nil {
+		if err := s.validateTaskModel(*req.Payload); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid payload model: %v", err), http.StatusBadRequest)
+			return
+		}
 		task.Payload = *req.Payload
 	}
 	if req.Timezone != nil {
+		if err := config.ValidateTimezone(*req.Timezone); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		task.Timezone = *req.Timezone
 	}
 	if req.Enabled != nil {
 		task.Enabled = *req.Enabled
 	}
+	if req.MaxRetries != nil {
+		task.MaxRetries = *req.MaxRetries
+	}
+	if req.RetryBackoff != nil {
+		task.RetryBackoff = *req.RetryBackoff
+	}
+	if req.CatchUp != nil {
+		task.CatchUp = *req.CatchUp
+	}
+	if req.DependsOn != nil {
+		task.DependsOn = *req.DependsOn
+	}
+	if err := scheduler.ValidateRetryPolicy(task.MaxRetries, task.RetryBackoff); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if task.TaskType == scheduler.TaskTypeWebhook {
+		if _, err := scheduler.ParseWebhookPayload(task.Payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
 
 	if err := s.scheduler.UpdateTask(task); err != nil {
+		if isTaskDependencyError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, fmt.Sprintf("Failed to update task: %v", err), http.StatusInternalServerError)
 		return
 	}
@@ -249,6 +359,11 @@ func (s *Server) handleTaskUpdate(w http.ResponseWriter, r *http.Request) {
 		Payload:        task.Payload,
 		Timezone:       task.Timezone,
 		Enabled:        task.Enabled,
+		MaxRetries:     task.MaxRetries,
+		RetryBackoff:   task.RetryBackoff,
+		Status:         string(task.Status),
+		CatchUp:        task.CatchUp,
+		DependsOn:      task.DependsOn,
 		LastRunAt:      task.LastRunAt,
 		LastRunStatus:  task.LastRunStatus,
 		NextRunAt:      task.NextRunAt,
@@ -290,6 +405,11 @@ func (s *Server) handleTaskEnable(w http.ResponseWriter, r *http.Request) {
 		Payload:        task.Payload,
 		Timezone:       task.Timezone,
 		Enabled:        true,
+		MaxRetries:     task.MaxRetries,
+		RetryBackoff:   task.RetryBackoff,
+		Status:         string(task.Status),
+		CatchUp:        task.CatchUp,
+		DependsOn:      task.DependsOn,
 		NextRunAt:      task.NextRunAt,
 		UpdatedAt:      task.UpdatedAt,
 	})
@@ -315,6 +435,11 @@ func (s *Server) handleTaskDisable(w http.ResponseWriter, r *http.Request) {
 		Payload:        task.Payload,
 		Timezone:       task.Timezone,
 		Enabled:        false,
+		MaxRetries:     task.MaxRetries,
+		RetryBackoff:   task.RetryBackoff,
+		Status:         string(task.Status),
+		CatchUp:        task.CatchUp,
+		DependsOn:      task.DependsOn,
 		UpdatedAt:      task.UpdatedAt,
 	})
 }
@@ -338,25 +463,57 @@ func (s *Server) handleTaskRuns(w http.ResponseWriter, r *http.Request) {
 
 	response := make([]RunResponse, 0, len(runs))
 	for _, run := range runs {
-		response = append(response, RunResponse{
-			ID:          run.ID,
-			TaskID:      run.TaskID,
-			StartedAt:   run.StartedAt,
-			CompletedAt: run.CompletedAt,
-			Status:      string(run.Status),
-			Error:       run.Error,
-			Output:      run.Output,
-		})
+		response = append(response, runResponse(run))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleTaskRunNow starts a task immediately and returns the new run with 202
+// Accepted; poll the task's runs for its outcome. With ?wait=true the task runs
+// synchronously and the finished run is returned instead. The run is tagged as
+// manual and does not change the task's schedule.
+func (s *Server) handleTaskRunNow(w http.ResponseWriter, r *http.Request) {
+	taskID := chi.URLParam(r, "id")
+	wait, _ := strconv.ParseBool(r.URL.Query().Get("wait"))
+
+	var (
+		run *scheduler.TaskRun
+		err error
+	)
+	if wait {
+		run, err = s.scheduler.RunNow(taskID)
+	} else {
+		run, err = s.scheduler.StartRun(taskID)
+	}
+	switch {
+	case errors.Is(err, scheduler.ErrTaskNotFound):
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	case errors.Is(err, scheduler.ErrSchedulerPaused):
+		http.Error(w, "Scheduler is paused", http.StatusConflict)
+		return
+	case errors.Is(err, scheduler.ErrTaskRunning):
+		http.Error(w, "Task is already running", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, fmt.Sprintf("Failed to run task: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !wait {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(runResponse(run))
+}
+
 // handleTaskValidate validates a cron expression
 func (s *Server) handleTaskValidate(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		CronExpression string `json:"cron_expression"`
+		Timezone       string `json:"timezone"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -374,7 +531,11 @@ func (s *Server) handleTaskValidate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	nextRuns, err := scheduler.PreviewNextRuns(req.CronExpression, 5)
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = s.scheduler.DefaultTimezone()
+	}
+	nextRuns, err := scheduler.PreviewNextRunsIn(req.CronExpression, timezone, 5)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -398,10 +559,71 @@ func (s *Server) handleTaskValidate(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleTaskEventTrigger triggers tasks for an event-based schedule.
+// maxSchedulePreviewCount caps the count parameter of the schedule preview.
+const maxSchedulePreviewCount = 50
+
+// handleSchedulerPreview returns the next fire times of a schedule expression
+// without creating a task, along with the expression as it was parsed.
+func (s *Server) handleSchedulerPreview(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	writeError := func(msg string) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": msg})
+	}
+
+	query := r.URL.Query()
+	expr := query.Get("expr")
+	count := 5
+	if v := query.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxSchedulePreviewCount {
+			writeError(fmt.Sprintf("count must be between 1 and %d", maxSchedulePreviewCount))
+			return
+		}
+		count = n
+	}
+
+	normalized, kind, err := scheduler.NormalizeSchedule(expr)
+	if err != nil {
+		writeError(err.Error())
+		return
+	}
+
+	timezone := query.Get("timezone")
+	if timezone == "" {
+		timezone = s.scheduler.DefaultTimezone()
+	}
+	nextRuns, err := scheduler.PreviewNextRunsIn(expr, timezone, count)
+	if err != nil {
+		writeError(err.Error())
+		return
+	}
+
+	runs := make([]string, 0, len(nextRuns))
+	for _, next := range nextRuns {
+		runs = append(runs, next.Format(time.RFC3339))
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"expr":       expr,
+		"normalized": normalized,
+		"kind":       kind,
+		"timezone":   timezone,
+		"next_runs":  runs,
+	})
+}
+
+// handleTaskEventTrigger triggers tasks for an event-based schedule. An
+// optional JSON object body is passed to the triggered tasks.
 func (s *Server) handleTaskEventTrigger(w http.ResponseWriter, r *http.Request) {
 	eventName := chi.URLParam(r, "event")
-	triggered, err := s.scheduler.TriggerEvent(eventName)
+
+	var payload map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	triggered, err := s.scheduler.TriggerEventWithPayload(eventName, payload)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to trigger event tasks: %v", err), http.StatusBadRequest)
 		return
@@ -415,3 +637,37 @@ func (s *Server) handleTaskEventTrigger(w http.ResponseWriter, r *http.Request)
 		"triggeredAt": time.Now().Format(time.RFC3339),
 	})
 }
+
+// handleSchedulerExport returns all task definitions as a portable JSON array.
+// Run history and computed fields are not included.
+func (s *Server) handleSchedulerExport(w http.ResponseWriter, r *http.Request) {
+	defs, err := s.scheduler.ExportTasks()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to export tasks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="pryx-tasks.json"`)
+	json.NewEncoder(w).Encode(defs)
+}
+
+// handleSchedulerImport recreates tasks from a JSON array of definitions.
+// Pass ?preserve_ids=true to keep the exported IDs.
+func (s *Server) handleSchedulerImport(w http.ResponseWriter, r *http.Request) {
+	var defs []scheduler.TaskDefinition
+	if err := json.NewDecoder(r.Body).Decode(&defs); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	preserveIDs := r.URL.Query().Get("preserve_ids") == "true"
+	result, err := s.scheduler.ImportTasks(defs, scheduler.ImportOptions{PreserveIDs: preserveIDs})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to import tasks: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
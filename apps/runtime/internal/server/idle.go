@@ -0,0 +1,129 @@
+package server
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pryx-core/internal/bus"
+)
+
+// maxIdleWarningLead caps how far ahead of an idle shutdown the warning event is sent.
+const maxIdleWarningLead = time.Minute
+
+// idleMonitor tracks the last HTTP, WebSocket, channel or scheduler activity and
+// signals shutdown once nothing has happened for the configured timeout.
+type idleMonitor struct {
+	timeout time.Duration
+	last    atomic.Int64
+	warned  atomic.Bool
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newIdleMonitor(timeout time.Duration) *idleMonitor {
+	m := &idleMonitor{timeout: timeout, done: make(chan struct{})}
+	m.touch()
+	return m
+}
+
+// touch records activity. It is nil-safe so callers need not check whether idle shutdown is enabled.
+func (m *idleMonitor) touch() {
+	if m == nil {
+		return
+	}
+	m.last.Store(time.Now().UnixNano())
+	m.warned.Store(false)
+}
+
+func (m *idleMonitor) idleFor(now time.Time) time.Duration {
+	return now.Sub(time.Unix(0, m.last.Load()))
+}
+
+// warningLead returns how long before shutdown the warning event is published.
+func (m *idleMonitor) warningLead() time.Duration {
+	lead := m.timeout / 5
+	if lead > maxIdleWarningLead {
+		lead = maxIdleWarningLead
+	}
+	return lead
+}
+
+// startIdleMonitor begins watching for inactivity when cfg.IdleTimeout is set.
+func (s *Server) startIdleMonitor() {
+	timeout := s.cfg.IdleTimeout
+	if timeout <= 0 {
+		return
+	}
+	s.idle = newIdleMonitor(timeout)
+
+	events, cancel := s.bus.Subscribe(
+		bus.EventChatRequest,
+		bus.EventChatInject,
+		bus.EventChannelMessage,
+		bus.EventChannelOutboundMessage,
+		bus.EventSessionMessage,
+	)
+	go func() {
+		for range events {
+			s.idle.touch()
+		}
+	}()
+	go s.watchIdle(cancel)
+}
+
+func (s *Server) watchIdle(unsubscribe func()) {
+	m := s.idle
+	interval := m.timeout / 10
+	if interval > 30*time.Second {
+		interval = 30 * time.Second
+	}
+	if interval <= 0 {
+		interval = m.timeout
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	defer unsubscribe()
+
+	for range ticker.C {
+		idle := m.idleFor(time.Now())
+		if idle >= m.timeout {
+			s.bus.Publish(bus.NewEvent(bus.EventIdleShutdown, "", map[string]interface{}{
+				"state":        "shutting_down",
+				"idle_seconds": int(idle.Seconds()),
+			}))
+			m.once.Do(func() { close(m.done) })
+			return
+		}
+		if idle >= m.timeout-m.warningLead() && !m.warned.Swap(true) {
+			s.bus.Publish(bus.NewEvent(bus.EventIdleShutdown, "", map[string]interface{}{
+				"state":               "warning",
+				"idle_seconds":        int(idle.Seconds()),
+				"shutdown_in_seconds": int((m.timeout - idle).Seconds()),
+				"timeout_seconds":     int(m.timeout.Seconds()),
+			}))
+		}
+	}
+}
+
+// IdleShutdown returns a channel that is closed when the runtime has been idle for
+// the configured timeout. It never fires when idle shutdown is disabled.
+func (s *Server) IdleShutdown() <-chan struct{} {
+	if s.idle == nil {
+		return nil
+	}
+	return s.idle.done
+}
+
+// idleActivityMiddleware counts every request except health probes as activity,
+// both for idle shutdown and so warm-up can stay out of the way of real requests.
+func (s *Server) idleActivityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			s.idle.touch()
+			s.lastRequest.Store(time.Now().UnixNano())
+		}
+		next.ServeHTTP(w, r)
+	})
+}
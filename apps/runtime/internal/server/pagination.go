@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultListLimit is the page size of list endpoints when no limit is given.
+	defaultListLimit = 100
+	// maxListLimit caps the limit query parameter of list endpoints.
+	maxListLimit = 1000
+)
+
+// pageParams is the limit/offset window requested from a list endpoint.
+type pageParams struct {
+	Limit  int
+	Offset int
+	// Explicit reports whether the request gave a limit or an offset.
+	Explicit bool
+}
+
+// parsePageParams reads the limit and offset query parameters. Missing values
+// select the first defaultListLimit items; limits above maxListLimit are capped.
+func parsePageParams(r *http.Request) (pageParams, error) {
+	p := pageParams{Limit: defaultListLimit}
+	query := r.URL.Query()
+	if v := strings.TrimSpace(query.Get("limit")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return p, fmt.Errorf("limit must be a positive integer")
+		}
+		p.Limit = min(n, maxListLimit)
+		p.Explicit = true
+	}
+	if v := strings.TrimSpace(query.Get("offset")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("offset must be a non-negative integer")
+		}
+		p.Offset = n
+		p.Explicit = true
+	}
+	return p, nil
+}
+
+// paginate returns the window of items selected by p.
+func paginate[T any](items []T, p pageParams) []T {
+	if p.Offset >= len(items) {
+		return []T{}
+	}
+	end := min(p.Offset+p.Limit, len(items))
+	return items[p.Offset:end]
+}
+
+// listEnvelope builds the response shared by list endpoints: the page of
+// items plus total, limit, offset and has_more. legacyKey repeats the items
+// under the field the endpoint used before the envelope, kept for one release
+// so existing clients keep working. Clients that don't page get every item
+// there, as before; only requests with a limit or offset get the page.
+func listEnvelope[T any](legacyKey string, items []T, p pageParams) map[string]interface{} {
+	page := paginate(items, p)
+	resp := map[string]interface{}{
+		"items":    page,
+		"total":    len(items),
+		"limit":    p.Limit,
+		"offset":   p.Offset,
+		"has_more": p.Offset+len(page) < len(items),
+	}
+	if legacyKey != "" {
+		resp[legacyKey] = legacyItems(items, p)
+	}
+	return resp
+}
+
+// legacyItems returns the items listed under an endpoint's legacy field: all
+// of them, unless the request asked for a page.
+func legacyItems[T any](items []T, p pageParams) []T {
+	if !p.Explicit && items != nil {
+		return items
+	}
+	return paginate(items, p)
+}
+
+// writePageParamsError answers a list request whose paging parameters are invalid.
+func writePageParamsError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error()})
+}
@@ -21,6 +21,28 @@ func GetAvailablePort() (int, error) {
 	return addr.Port, nil
 }
 
+// ListenWithFallback binds a TCP listener on all interfaces, trying the
+// preferred port first and falling back to an OS-assigned port if it is taken
+// or preferred is 0. The listener is returned bound, so there is no window in
+// which another process can claim the port. It also returns the bound port.
+func ListenWithFallback(preferred int) (net.Listener, int, error) {
+	candidates := []int{0}
+	if preferred > 0 && preferred < 65536 {
+		candidates = []int{preferred, 0}
+	}
+
+	var lastErr error
+	for _, port := range candidates {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return listener, listener.Addr().(*net.TCPAddr).Port, nil
+	}
+	return nil, 0, lastErr
+}
+
 // WritePortFile writes the port number to ~/.pryx/runtime.port.
 // This allows clients (like the TUI) to discover the runtime's port.
 // Creates the .pryx directory if it doesn't exist.
@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"pryx-core/internal/server"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -179,3 +181,30 @@ func itow(n int) string {
 	}
 	return result
 }
+
+func TestListenWithFallback(t *testing.T) {
+	t.Run("preferred_free", func(t *testing.T) {
+		probe, err := net.Listen("tcp", ":0")
+		require.NoError(t, err)
+		preferred := probe.Addr().(*net.TCPAddr).Port
+		probe.Close()
+
+		listener, port, err := server.ListenWithFallback(preferred)
+		require.NoError(t, err)
+		defer listener.Close()
+		assert.Equal(t, preferred, port)
+	})
+
+	t.Run("preferred_taken", func(t *testing.T) {
+		taken, err := net.Listen("tcp", ":0")
+		require.NoError(t, err)
+		defer taken.Close()
+		preferred := taken.Addr().(*net.TCPAddr).Port
+
+		listener, port, err := server.ListenWithFallback(preferred)
+		require.NoError(t, err)
+		defer listener.Close()
+		assert.NotEqual(t, preferred, port)
+		assert.Equal(t, port, listener.Addr().(*net.TCPAddr).Port)
+	})
+}
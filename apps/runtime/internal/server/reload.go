@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"time"
+
+	"pryx-core/internal/agent"
+	"pryx-core/internal/bus"
+	"pryx-core/internal/channels"
+	"pryx-core/internal/config"
+	"pryx-core/internal/models"
+	"pryx-core/internal/policy"
+	"pryx-core/internal/skills"
+)
+
+// reloadStepTimeout bounds the skills reload and the catalog refresh.
+var reloadStepTimeout = 15 * time.Second
+
+// ReloadReport describes what a Reload applied in place and what still needs a
+// full restart.
+type ReloadReport struct {
+	// Applied lists the config keys that changed and now take effect.
+	Applied []string `json:"applied"`
+	// RestartRequired lists the config keys that changed but only take effect
+	// after a restart.
+	RestartRequired []string `json:"restart_required"`
+	// Skills is the result of re-running skill discovery, nil if it failed.
+	Skills *skills.RegistryDiff `json:"skills,omitempty"`
+	// CatalogRefreshed reports whether the model catalog was re-fetched.
+	CatalogRefreshed bool     `json:"catalog_refreshed"`
+	Errors           []string `json:"errors,omitempty"`
+}
+
+// liveConfigKeys are the config keys the server applies without a restart.
+var liveConfigKeys = []struct {
+	key string
+	get func(*config.Config) any
+	set func(dst, src *config.Config)
+}{
+	{"model_name", func(c *config.Config) any { return c.ModelName }, func(d, s *config.Config) { d.ModelName = s.ModelName }},
+	{"model_fallbacks", func(c *config.Config) any { return c.ModelFallbacks }, func(d, s *config.Config) { d.ModelFallbacks = s.ModelFallbacks }},
+	{"model_fallback_timeout", func(c *config.Config) any { return c.ModelFallbackTimeout }, func(d, s *config.Config) { d.ModelFallbackTimeout = s.ModelFallbackTimeout }},
+	{"provider_overrides", func(c *config.Config) any { return c.ProviderOverrides }, func(d, s *config.Config) { d.ProviderOverrides = s.ProviderOverrides }},
+	{"mcp_call_timeout", func(c *config.Config) any { return c.MCPCallTimeout }, func(d, s *config.Config) { d.MCPCallTimeout = s.MCPCallTimeout }},
+	{"mcp_approval_ttl", func(c *config.Config) any { return c.MCPApprovalTTL }, func(d, s *config.Config) { d.MCPApprovalTTL = s.MCPApprovalTTL }},
+	{"tool_scopes", func(c *config.Config) any { return c.ToolScopes }, func(d, s *config.Config) { d.ToolScopes = s.ToolScopes }},
+	{"channel_rate_limits", func(c *config.Config) any { return c.ChannelRateLimits }, func(d, s *config.Config) { d.ChannelRateLimits = s.ChannelRateLimits }},
+}
+
+// restartConfigKeys are the config keys that are only read at startup. The
+// agent's provider client is built once, so changing the provider or the
+// Ollama endpoint needs a restart.
+var restartConfigKeys = []struct {
+	key string
+	get func(*config.Config) any
+}{
+	{"model_provider", func(c *config.Config) any { return c.ModelProvider }},
+	{"ollama_endpoint", func(c *config.Config) any { return c.OllamaEndpoint }},
+	{"listen_addr", func(c *config.Config) any { return c.ListenAddr }},
+	{"preferred_port", func(c *config.Config) any { return c.PreferredPort }},
+	{"database_path", func(c *config.Config) any { return c.DatabasePath }},
+	{"tool_approvals", func(c *config.Config) any { return c.ToolApprovals }},
+}
+
+// Reload applies next in place without dropping HTTP, WebSocket or channel
+// connections: it copies the live config keys that changed, re-runs skill
+// discovery and refreshes the model catalog. Changed keys that need a restart
+// are reported but not applied. It publishes runtime.reloaded with the report.
+func (s *Server) Reload(ctx context.Context, next *config.Config) ReloadReport {
+	report := ReloadReport{Applied: []string{}, RestartRequired: []string{}}
+
+	s.cfgMu.Lock()
+	for _, k := range liveConfigKeys {
+		if !reflect.DeepEqual(k.get(s.cfg), k.get(next)) {
+			k.set(s.cfg, next)
+			report.Applied = append(report.Applied, k.key)
+		}
+	}
+	for _, k := range restartConfigKeys {
+		if !reflect.DeepEqual(k.get(s.cfg), k.get(next)) {
+			report.RestartRequired = append(report.RestartRequired, k.key)
+		}
+	}
+	overrides := s.cfg.ProviderOverrides
+	rateLimits := s.cfg.ChannelRateLimits
+	mcpCallTimeout := s.cfg.MCPCallTimeout
+	mcpApprovalTTL := s.cfg.MCPApprovalTTL
+	toolScopes := s.cfg.ToolScopes
+	modelCfg := agent.ModelConfigFrom(s.cfg)
+	s.cfgMu.Unlock()
+	if m := s.modelSettings.Load(); m != nil {
+		m.Store(modelCfg)
+	}
+	applyProviderOverrides(overrides)
+	channels.SetPlatformLimits(rateLimits)
+	if s.mcp != nil {
+		s.mcp.SetCallTimeout(mcpCallTimeout)
+		s.mcp.SetApprovalTTL(mcpApprovalTTL)
+	}
+	if s.toolPolicy != nil {
+		if scopes, err := policy.ToolScopesFromConfig(toolScopes); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		} else {
+			s.toolPolicy.SetToolScopes(scopes)
+		}
+	}
+
+	if s.skills != nil {
+		stepCtx, cancel := context.WithTimeout(ctx, reloadStepTimeout)
+		diff, err := s.skills.Reload(stepCtx, skills.DefaultOptions())
+		cancel()
+		if err != nil {
+			report.Errors = append(report.Errors, "skills: "+err.Error())
+		} else {
+			report.Skills = &diff
+		}
+	}
+
+	catalog, err := s.refreshCatalog()
+	if err != nil {
+		report.Errors = append(report.Errors, "catalog: "+err.Error())
+	} else {
+		s.SetCatalog(catalog)
+		report.CatalogRefreshed = true
+	}
+
+	payload := map[string]interface{}{
+		"kind":              "runtime.reloaded",
+		"applied":           report.Applied,
+		"restart_required":  report.RestartRequired,
+		"catalog_refreshed": report.CatalogRefreshed,
+	}
+	if report.Skills != nil {
+		payload["skills_added"] = report.Skills.Added
+		payload["skills_removed"] = report.Skills.Removed
+		payload["skills_changed"] = report.Skills.Changed
+	}
+	if len(report.Errors) > 0 {
+		payload["errors"] = report.Errors
+	}
+	s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", payload))
+	return report
+}
+
+// refreshCatalog re-fetches the model catalog from the models API.
+func (s *Server) refreshCatalog() (*models.Catalog, error) {
+	if s.catalogLoader != nil {
+		return s.catalogLoader()
+	}
+	return models.NewService().Refresh()
+}
+
+// LogReloadReport logs what a Reload applied and what needs a restart.
+func LogReloadReport(r ReloadReport) {
+	if len(r.Applied) > 0 {
+		log.Printf("Reload: applied config changes: %v", r.Applied)
+	} else {
+		log.Println("Reload: no live config changes")
+	}
+	if r.Skills != nil {
+		log.Printf("Reload: skills reloaded (added %d, removed %d, changed %d)",
+			len(r.Skills.Added), len(r.Skills.Removed), len(r.Skills.Changed))
+	}
+	if r.CatalogRefreshed {
+		log.Println("Reload: model catalog refreshed")
+	}
+	for _, e := range r.Errors {
+		log.Printf("Reload: %s", e)
+	}
+	if len(r.RestartRequired) > 0 {
+		log.Printf("Reload: %v changed; a full restart is required to apply them", r.RestartRequired)
+	}
+}
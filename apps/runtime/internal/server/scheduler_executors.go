@@ -6,11 +6,15 @@ import (
 	"fmt"
 
 	"pryx-core/internal/bus"
+	"pryx-core/internal/models"
 	"pryx-core/internal/scheduler"
 )
 
 type taskEventExecutor struct {
-	bus *bus.Bus
+	bus  *bus.Bus
+	idle *idleMonitor
+	// defaultModel returns the global model, used when the task payload names none.
+	defaultModel func() string
 }
 
 func (e *taskEventExecutor) Execute(ctx context.Context, task *scheduler.ScheduledTask) (string, error) {
@@ -20,6 +24,8 @@ func (e *taskEventExecutor) Execute(ctx context.Context, task *scheduler.Schedul
 	default:
 	}
 
+	e.idle.touch()
+
 	var payload interface{}
 	if task.Payload != "" {
 		if err := json.Unmarshal([]byte(task.Payload), &payload); err != nil {
@@ -31,27 +37,161 @@ func (e *taskEventExecutor) Execute(ctx context.Context, task *scheduler.Schedul
 		payload = map[string]interface{}{}
 	}
 
+	model, modelSource := e.resolveModel(payload)
+
 	if e.bus != nil {
 		e.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{
-			"kind":      "scheduler.task.executed",
-			"task_id":   task.ID,
-			"task_name": task.Name,
-			"task_type": task.TaskType,
-			"payload":   payload,
+			"kind":         "scheduler.task.executed",
+			"task_id":      task.ID,
+			"task_name":    task.Name,
+			"task_type":    task.TaskType,
+			"payload":      payload,
+			"model":        model,
+			"model_source": modelSource,
 		}))
+		if task.TaskType == scheduler.TaskTypeMessage {
+			e.requestGeneration(task, payload, model, modelSource)
+		}
 	}
 
 	return fmt.Sprintf("executed %s task", task.TaskType), nil
 }
 
+// requestGeneration asks the agent to answer the "content" of a message task,
+// in the payload's "session_id" if set. A model the task names is sent as the
+// request's model; otherwise the agent resolves the session's or the default.
+func (e *taskEventExecutor) requestGeneration(task *scheduler.ScheduledTask, payload interface{}, model, modelSource string) {
+	fields, _ := payload.(map[string]interface{})
+	content, _ := fields["content"].(string)
+	if content == "" {
+		return
+	}
+	sessionID, _ := fields["session_id"].(string)
+	request := map[string]interface{}{
+		"content": content,
+		"task_id": task.ID,
+	}
+	if modelSource == models.SourceTask {
+		request["model"] = model
+	}
+	e.bus.Publish(bus.NewEvent(bus.EventChatRequest, sessionID, request))
+}
+
+// resolveModel picks the task's model: the payload's "model" field, else the global default.
+func (e *taskEventExecutor) resolveModel(payload interface{}) (string, string) {
+	sel := models.Selection{ScopeSource: models.SourceTask}
+	if m, ok := payload.(map[string]interface{}); ok {
+		sel.Scope, _ = m["model"].(string)
+	}
+	if e.defaultModel != nil {
+		sel.Default = e.defaultModel()
+	}
+	return sel.Resolve()
+}
+
+// validateTaskModel checks the "model" field of a task payload, if present, against the catalog.
+func (s *Server) validateTaskModel(payload string) error {
+	if payload == "" {
+		return nil
+	}
+	var fields struct {
+		Model string `json:"model"`
+	}
+	if err := json.Unmarshal([]byte(payload), &fields); err != nil || fields.Model == "" {
+		return nil
+	}
+	s.cfgMu.RLock()
+	provider := s.cfg.ModelProvider
+	s.cfgMu.RUnlock()
+	return s.Catalog().ValidateModel(provider, fields.Model)
+}
+
 func (s *Server) registerSchedulerExecutors() {
 	if s.scheduler == nil {
 		return
 	}
 
-	executor := &taskEventExecutor{bus: s.bus}
+	s.scheduler.SetRunHook(s.reportTaskRun)
+	succeeded, cancel := s.bus.Subscribe(bus.EventSchedulerTaskSucceeded)
+	go s.triggerTaskDependents(succeeded, cancel)
+
+	executor := &taskEventExecutor{bus: s.bus, idle: s.idle, defaultModel: func() string {
+		s.cfgMu.RLock()
+		defer s.cfgMu.RUnlock()
+		return s.cfg.ModelName
+	}}
 	s.scheduler.RegisterExecutor(scheduler.TaskTypeMessage, executor)
 	s.scheduler.RegisterExecutor(scheduler.TaskTypeWorkflow, executor)
 	s.scheduler.RegisterExecutor(scheduler.TaskTypeReminder, executor)
-	s.scheduler.RegisterExecutor(scheduler.TaskTypeWebhook, executor)
+	s.scheduler.RegisterExecutor(scheduler.TaskTypeWebhook, &idleTouchExecutor{
+		idle: s.idle,
+		next: scheduler.NewWebhookExecutor(nil),
+	})
+	s.scheduler.RegisterExecutor(scheduler.TaskTypeDBCompact, &dbCompactExecutor{server: s})
+}
+
+// idleTouchExecutor counts a task run as activity for the idle monitor.
+type idleTouchExecutor struct {
+	idle *idleMonitor
+	next scheduler.TaskExecutor
+}
+
+func (e *idleTouchExecutor) Execute(ctx context.Context, task *scheduler.ScheduledTask) (string, error) {
+	e.idle.touch()
+	return e.next.Execute(ctx, task)
+}
+
+// reportTaskRun publishes a scheduler.task.succeeded event for successful
+// scheduled task runs, an error event for failed ones, and a
+// scheduler.task.failed event once a task has used up its retries.
+func (s *Server) reportTaskRun(task *scheduler.ScheduledTask, run *scheduler.TaskRun) {
+	if run.Status == scheduler.RunStatusSuccess {
+		s.bus.Publish(bus.NewEvent(bus.EventSchedulerTaskSucceeded, "", map[string]interface{}{
+			"task_id":   task.ID,
+			"task_name": task.Name,
+			"run_id":    run.ID,
+			"trigger":   string(run.Trigger),
+			"output":    run.Output,
+		}))
+		return
+	}
+	if run.Status != scheduler.RunStatusFailed {
+		return
+	}
+	s.errors.Emit("", map[string]interface{}{
+		"kind":      "scheduler.task_failed",
+		"task_id":   task.ID,
+		"task_name": task.Name,
+		"error":     run.Error,
+		"attempt":   run.Attempt,
+	})
+	if run.RetriesExhausted(task) {
+		s.bus.Publish(bus.NewEvent(bus.EventSchedulerTaskFailed, "", map[string]interface{}{
+			"task_id":   task.ID,
+			"task_name": task.Name,
+			"attempts":  run.Attempt,
+			"error":     run.Error,
+		}))
+	}
+}
+
+// triggerTaskDependents fires the tasks that depend on each task reported as
+// succeeded.
+func (s *Server) triggerTaskDependents(events <-chan bus.Event, cancel func()) {
+	defer cancel()
+
+	for evt := range events {
+		payload, _ := evt.Payload.(map[string]interface{})
+		taskID, _ := payload["task_id"].(string)
+		if taskID == "" {
+			continue
+		}
+		if n := s.scheduler.TriggerDependents(taskID, payload); n > 0 {
+			s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{
+				"kind":      "scheduler.dependents_triggered",
+				"task_id":   taskID,
+				"triggered": n,
+			}))
+		}
+	}
 }
@@ -10,16 +10,23 @@ import (
 	"net/http"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"pryx-core/internal/agent"
+	"pryx-core/internal/agent/summary"
 	"pryx-core/internal/agentbus"
+	"pryx-core/internal/alerts"
 	"pryx-core/internal/audit"
 	"pryx-core/internal/auth"
 	"pryx-core/internal/bus"
 	"pryx-core/internal/channels"
 	"pryx-core/internal/config"
 	"pryx-core/internal/cost"
+	"pryx-core/internal/debugbundle"
 	"pryx-core/internal/keychain"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/llm/providers"
 	"pryx-core/internal/mcp"
 	"pryx-core/internal/mcp/discovery"
 	"pryx-core/internal/memory"
@@ -28,6 +35,7 @@ import (
 	"pryx-core/internal/scheduler"
 	"pryx-core/internal/skills"
 	"pryx-core/internal/store"
+	"pryx-core/internal/workspace"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -41,7 +49,9 @@ type SpawnTool interface {
 	Execute(ctx context.Context, params json.RawMessage, parentID string) (interface{}, error)
 	GetAgentStatus(agentID string) (map[string]interface{}, error)
 	ListAgents() []map[string]interface{}
+	CancelAgent(agentID string) error
 	ForkSession(sourceSessionID string) (string, error)
+	ForkSessionAt(sourceSessionID string, messageID string) (string, error)
 }
 
 type pkceEntry struct {
@@ -58,23 +68,66 @@ type Server struct {
 	keychain     *keychain.Keychain
 	router       *chi.Mux
 	bus          *bus.Bus
+	errors       *bus.ErrorEmitter
 	agentbus     *agentbus.Service
 	mcp          *mcp.Manager
+	toolPolicy   *policy.Engine
 	mcpDiscovery *discovery.DiscoveryService
 	skills       *skills.Registry
-	catalog      *models.Catalog
 	spawnTool    SpawnTool
 	ragMemory    *memory.RAGManager
+	summarizer   *summary.Summarizer
+	llmProvider  summary.ProviderFunc
 	store        *store.Store
 	auditRepo    *audit.AuditRepository
 	costService  *cost.CostService
+	costCalc     *cost.CostCalculator
 	channels     *channels.ChannelManager
+	alerts       *alerts.Bridge
 	scheduler    *scheduler.Scheduler
-	pkceParams   map[string]pkceEntry // Temporary storage for PKCE during OAuth flow
-	mu           sync.Mutex           // Protects pkceParams
+	// checkProviderKey verifies an API key with the provider; replaced in tests.
+	checkProviderKey func(ctx context.Context, providerID, apiKey string) error
+	// checkCloudToken asks the cloud whether a login token is accepted; replaced in tests.
+	checkCloudToken func(ctx context.Context, apiUrl, token string) (*auth.TokenInfo, error)
+	cloudToken      cloudTokenCache
+	// catalogLoader loads the model catalog during warm-up and reload; replaced in tests.
+	catalogLoader func() (*models.Catalog, error)
+	// catalog is the model catalog. It arrives after startup and is replaced
+	// on reload, while handlers read it.
+	catalog atomic.Pointer[models.Catalog]
+	// catalogMu orders SetCatalog with the hooks added by OnCatalog.
+	catalogMu    sync.Mutex
+	catalogHooks []func(*models.Catalog)
+	// catalogPending, if set, is closed once the startup catalog load has finished.
+	catalogPending <-chan struct{}
+	// liveModels fetches and caches the model lists providers serve.
+	liveModels *providers.ModelLister
+	// sseStreams holds the event streams served by /events.
+	sseStreams *sseRegistry
+	// mcpReady is closed once the startup MCP connection attempt has finished.
+	mcpReady   chan struct{}
+	pkceParams map[string]pkceEntry // Temporary storage for PKCE during OAuth flow
+	mu         sync.Mutex           // Protects pkceParams
 
 	httpMu     sync.Mutex
 	httpServer *http.Server
+
+	maintenance atomic.Bool
+	// compacting is set while the database is being compacted.
+	compacting  atomic.Bool
+	idle        *idleMonitor
+	lastRequest atomic.Int64 // UnixNano of the last non-health request
+	generations atomic.Pointer[agent.GenerationLimiter]
+	activity    atomic.Pointer[agent.SessionActivity]
+	modelLimits atomic.Pointer[llm.ModelLimiter]
+	// modelSettings is the agent's live model selection, updated on reload.
+	modelSettings atomic.Pointer[agent.ModelSettings]
+
+	// debugEvents keeps recent error and trace events for debug bundles.
+	debugEvents *debugbundle.EventRecorder
+	// version and buildDate identify the binary in debug bundles.
+	version   string
+	buildDate string
 }
 
 // New creates a new Server instance with the provided configuration and dependencies.
@@ -86,7 +139,16 @@ func New(cfg *config.Config, db *sql.DB, kc *keychain.Keychain) *Server {
 	r.Use(corsMiddleware(cfg))
 	r.Use(DefaultRateLimiter().Middleware)
 
-	p := policy.NewEngine(nil)
+	approvalPolicy, err := policy.FromToolApprovals(cfg.ToolApprovals)
+	if err != nil {
+		log.Printf("Warning: %v; asking before every tool call", err)
+	}
+	p := policy.NewEngine(approvalPolicy)
+	if scopes, err := policy.ToolScopesFromConfig(cfg.ToolScopes); err != nil {
+		log.Printf("Warning: %v; ignoring tool scopes", err)
+	} else {
+		p.SetToolScopes(scopes)
+	}
 
 	s := &Server{
 		cfg:      cfg,
@@ -95,13 +157,53 @@ func New(cfg *config.Config, db *sql.DB, kc *keychain.Keychain) *Server {
 		router:   r,
 		bus:      bus.New(),
 	}
+	s.toolPolicy = p
+	s.liveModels = providers.NewModelLister(providers.DefaultLiveModelsTTL)
+	s.sseStreams = newSSERegistry()
+	s.errors = bus.NewErrorEmitter(s.bus, bus.DefaultErrorWindow)
+	s.startIdleMonitor()
+	r.Use(s.idleActivityMiddleware)
 	s.store = store.NewFromDB(db)
+	s.checkProviderKey = s.validateProviderKey
+	s.checkCloudToken = auth.CheckToken
 	s.auditRepo = audit.NewAuditRepository(db)
+	retention, err := audit.ParseContentRetention(cfg.AuditContentRetention)
+	if err != nil {
+		log.Printf("Warning: %v; keeping no audit content", err)
+	}
+	s.auditRepo.SetRedactionPolicy(audit.RedactionPolicy{Retention: retention, MaxChars: cfg.AuditContentMaxChars})
 
 	pricingMgr := cost.NewPricingManager()
 	costTracker := cost.NewCostTracker(s.auditRepo, pricingMgr)
-	costCalc := cost.NewCostCalculator(pricingMgr)
-	s.costService = cost.NewCostService(costTracker, costCalc, pricingMgr, s.store)
+	s.costCalc = cost.NewCostCalculator(pricingMgr)
+	s.costService = cost.NewCostService(costTracker, s.costCalc, pricingMgr, s.store)
+
+	// Tool output, captures and managed skills are written under the workspace;
+	// skills are discovered below, so their root is set first.
+	cacheDir := filepath.Dir(cfg.DatabasePath)
+	if ws, err := workspace.Open(cfg.WorkspaceRoot); err != nil {
+		log.Printf("Warning: workspace unavailable, using database directory: %v", err)
+	} else {
+		if err := ws.EnsureDirs(); err != nil {
+			log.Printf("Warning: failed to create workspace directories: %v", err)
+		}
+		if dir, err := ws.Resolve(workspace.DirCache); err != nil {
+			log.Printf("Warning: workspace cache unavailable, using database directory: %v", err)
+		} else {
+			cacheDir = dir
+		}
+		if dir, err := ws.Resolve(workspace.DirCaptures); err != nil {
+			log.Printf("Warning: workspace captures unavailable: %v", err)
+		} else {
+			mcp.SetCaptureDir(dir)
+		}
+		if dir, err := ws.Resolve(workspace.DirSkills); err != nil {
+			log.Printf("Warning: workspace skills unavailable: %v", err)
+		} else {
+			skills.SetManagedRoot(dir)
+		}
+	}
+	mcp.InitTruncator(cacheDir)
 
 	{
 		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
@@ -109,10 +211,10 @@ func New(cfg *config.Config, db *sql.DB, kc *keychain.Keychain) *Server {
 		reg, err := skills.Discover(ctx, skills.DefaultOptions())
 		s.skills = reg
 		if err != nil {
-			s.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
+			s.errors.Emit("", map[string]interface{}{
 				"kind":  "skills.load_failed",
 				"error": err.Error(),
-			}))
+			})
 		} else {
 			s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{
 				"kind":  "skills.loaded",
@@ -121,10 +223,9 @@ func New(cfg *config.Config, db *sql.DB, kc *keychain.Keychain) *Server {
 		}
 	}
 
-	s.mcp = mcp.NewManager(s.bus, p, kc)
-
-	dataDir := filepath.Dir(cfg.DatabasePath)
-	mcp.InitTruncator(dataDir)
+	s.mcp = mcp.NewManager(s.bus, s.toolPolicy, kc)
+	s.mcp.SetCallTimeout(cfg.MCPCallTimeout)
+	s.mcp.SetApprovalTTL(cfg.MCPApprovalTTL)
 
 	// Initialize agentbus (agent connectivity hub)
 	s.agentbus = agentbus.NewService(s.bus, agentbus.HubConfig{
@@ -133,40 +234,86 @@ func New(cfg *config.Config, db *sql.DB, kc *keychain.Keychain) *Server {
 		LogLevel:           "info",
 		AutoDetectEnabled:  cfg.AgentDetectEnabled,
 		AutoDetectInterval: cfg.AgentDetectInterval,
+		DetectTimeout:      cfg.AgentDetectTimeout,
+		DetectConcurrency:  cfg.AgentDetectConcurrency,
 		PackageDir:         cfg.SkillsPath,
 		CacheDir:           cfg.CachePath,
 		MaxConnections:     20,
 		ReconnectEnabled:   true,
 	})
 
+	go s.recordLLMCacheHits()
+	toolEvents, cancelToolEvents := s.bus.Subscribe(bus.EventToolComplete, bus.EventToolDenied)
+	go s.recordToolCalls(toolEvents, cancelToolEvents)
+	skillEvents, cancelSkillEvents := s.bus.Subscribe(bus.EventSkillExecuted)
+	go s.recordSkillExecutions(skillEvents, cancelSkillEvents)
+	s.bus.Handle(s.transcriptRecorder(), bus.SubscribeOptions{
+		Topics: []bus.EventType{bus.EventChatRequest, bus.EventSessionMessage, bus.EventAgentInjected},
+		Buffer: transcriptBuffer,
+	})
+	proxyEvents, cancelProxyEvents := s.bus.Subscribe(bus.EventLLMCloudProxied)
+	go s.recordCloudProxiedUsage(proxyEvents, cancelProxyEvents)
+	fallbackEvents, cancelFallbackEvents := s.bus.Subscribe(bus.EventLLMFallback)
+	go s.recordModelFallbacks(fallbackEvents, cancelFallbackEvents)
+	usageEvents, cancelUsageEvents := s.bus.Subscribe(bus.EventLLMUsage)
+	go s.recordLLMUsage(usageEvents, cancelUsageEvents)
+	llmErrorEvents, cancelLLMErrorEvents := s.bus.Subscribe(bus.EventErrorOccurred)
+	go s.recordLLMErrors(llmErrorEvents, cancelLLMErrorEvents)
+	s.debugEvents = debugbundle.NewEventRecorder(0)
+	debugEvents, cancelDebugEvents := s.bus.Subscribe(bus.EventErrorOccurred, bus.EventTraceEvent)
+	go s.recordDebugEvents(debugEvents, cancelDebugEvents)
+
 	s.channels = channels.NewManager(s.bus)
+	if err := s.channels.SetOutbox(s.store); err != nil {
+		log.Printf("Warning: %v; undelivered channel messages from the last run are lost", err)
+	}
+	s.channels.SetActivityLog(s.store, cfg.ChannelActivityRetention, cfg.ChannelActivityMaxRows)
+	channels.SetPlatformLimits(cfg.ChannelRateLimits)
+	s.alerts = alerts.New(s.bus, cfg.Alerts)
+	s.alerts.Start()
 	s.scheduler = scheduler.New(db)
+	if err := s.scheduler.SetDefaultTimezone(cfg.Timezone); err != nil {
+		log.Printf("Warning: %v; scheduled tasks default to the server's local zone", err)
+	}
+	if cfg.SchedulerLocking {
+		s.scheduler.EnableLocking(cfg.SchedulerLockLease)
+	}
+	s.scheduler.SetRunRetention(cfg.SchedulerMaxRunsPerTask, cfg.SchedulerRunRetention)
 	s.registerSchedulerExecutors()
+	if err := s.syncCompactTask(cfg.DBCompactSchedule); err != nil {
+		log.Printf("Warning: failed to schedule database compaction: %v", err)
+	}
+	applyProviderOverrides(cfg.ProviderOverrides)
+	if cfg.MaintenanceMode {
+		s.SetMaintenanceMode(true)
+	}
 
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		if err := s.agentbus.Start(ctx); err != nil {
-			s.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
+			s.errors.Emit("", map[string]interface{}{
 				"kind":  "agentbus.start_failed",
 				"error": err.Error(),
-			}))
+			})
 			return
 		}
 		s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, "", map[string]interface{}{
 			"kind": "agentbus.started",
 		}))
 	}()
+	s.mcpReady = make(chan struct{})
 	go func() {
+		defer close(s.mcpReady)
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 		path, err := s.mcp.LoadAndConnect(ctx)
 		if err != nil {
-			s.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
+			s.errors.Emit("", map[string]interface{}{
 				"kind":  "mcp.connect_failed",
 				"error": err.Error(),
 				"path":  path,
-			}))
+			})
 			return
 		}
 		if path != "" {
@@ -182,14 +329,27 @@ func New(cfg *config.Config, db *sql.DB, kc *keychain.Keychain) *Server {
 	s.ragMemory = memory.NewRAGManager(db, cfg.MemoryEnabled)
 	log.Printf("RAG Memory system initialized (enabled: %v)", cfg.MemoryEnabled)
 
+	s.llmProvider = s.newLLMProvider
+	s.summarizer = summary.New(s.store, s.ragMemory, func() (llm.Provider, error) { return s.llmProvider() }, "", cfg.SummaryMaxWords)
+	s.summarizer.SetModelFunc(s.summaryModel)
+
 	return s
 }
 
 func (s *Server) routes() {
 	s.router.Get("/health", s.handleHealth)
+	s.router.Get("/api/v1/capabilities", s.handleCapabilities)
 	s.router.Get("/ws", s.handleWS)
+	s.router.Get("/events", s.handleEvents)
 	s.router.Get("/mcp/tools", s.handleMCPTools)
 	s.router.Post("/mcp/tools/call", s.handleMCPCall)
+	s.router.Post("/mcp/tools/call-batch", s.handleMCPCallBatch)
+	s.router.Get("/mcp/resources", s.handleMCPResources)
+	s.router.Post("/mcp/resources/read", s.handleMCPResourceRead)
+	s.router.Get("/mcp/prompts", s.handleMCPPrompts)
+	s.router.Get("/mcp/approvals", s.handleMCPApprovals)
+	s.router.Delete("/mcp/approvals", s.handleMCPApprovalsClear)
+	s.router.Post("/mcp/approvals/{id}/resolve", s.handleMCPApprovalResolve)
 	s.router.Get("/mcp/discovery/curated", s.handleMCPDiscoveryCurated)
 	s.router.Get("/mcp/discovery/categories", s.handleMCPDiscoveryCategories)
 	s.router.Get("/mcp/discovery/curated/{id}", s.handleMCPDiscoveryServer)
@@ -200,15 +360,21 @@ func (s *Server) routes() {
 	s.router.Get("/skills", s.handleSkillsList)
 	s.router.Get("/skills/{id}", s.handleSkillsInfo)
 	s.router.Get("/skills/{id}/body", s.handleSkillsBody)
+	s.router.Get("/skills/{id}/stats", s.handleSkillsStats)
 	s.router.Post("/skills/enable", s.handleSkillsEnable)
 	s.router.Post("/skills/disable", s.handleSkillsDisable)
 	s.router.Post("/skills/install", s.handleSkillsInstall)
+	s.router.Post("/skills/install-batch", s.handleSkillsInstallBatch)
 	s.router.Post("/skills/uninstall", s.handleSkillsUninstall)
+	s.router.Post("/skills/reload", s.handleSkillsReload)
 	s.router.Get("/api/v1/providers", s.handleProvidersList)
 	s.router.Get("/api/v1/providers/{id}/models", s.handleProviderModels)
 	s.router.Get("/api/v1/providers/{id}/key", s.handleProviderKeyStatus)
+	s.router.Get("/api/v1/providers/{id}/health", s.handleProviderHealth)
 	s.router.Post("/api/v1/providers/{id}/key", s.handleProviderKeySet)
 	s.router.Delete("/api/v1/providers/{id}/key", s.handleProviderKeyDelete)
+	s.router.Get("/api/v1/providers/{id}/overrides", s.handleProviderOverridesGet)
+	s.router.Put("/api/v1/providers/{id}/overrides", s.handleProviderOverridesSet)
 	s.router.Get("/api/v1/cloud/status", s.handleCloudStatus)
 	s.router.Post("/api/v1/cloud/login/start", s.handleCloudLoginStart)
 	s.router.Post("/api/v1/cloud/login/poll", s.handleCloudLoginPoll)
@@ -222,12 +388,19 @@ func (s *Server) routes() {
 	s.router.Get("/api/v1/sessions", s.handleSessionsList)
 	s.router.Post("/api/v1/sessions", s.handleSessionCreate)
 	s.router.Get("/api/v1/sessions/{id}", s.handleSessionGet)
+	s.router.Get("/api/v1/sessions/{id}/stats", s.handleSessionStats)
+	s.router.Patch("/api/v1/sessions/{id}", s.handleSessionUpdate)
 	s.router.Delete("/api/v1/sessions/{id}", s.handleSessionDelete)
 	s.router.Post("/api/v1/sessions/fork", s.handleSessionFork)
+	s.router.Post("/api/v1/sessions/{id}/summarize", s.handleSessionSummarize)
+	s.router.Post("/api/v1/sessions/{id}/messages/{mid}/pin", s.handleMessagePin)
+	s.router.Delete("/api/v1/sessions/{id}/messages/{mid}/pin", s.handleMessagePin)
 
 	s.router.Get("/api/v1/memory", s.handleMemoryList)
 	s.router.Post("/api/v1/memory", s.handleMemoryWrite)
 	s.router.Post("/api/v1/memory/search", s.handleMemorySearch)
+	s.router.Patch("/api/v1/memory/{id}", s.handleMemoryUpdate)
+	s.router.Delete("/api/v1/memory/{id}", s.handleMemoryDelete)
 
 	// Mesh pairing endpoints (pryx-jot)
 	s.router.Post("/api/mesh/pair", s.handleMeshPair)
@@ -243,6 +416,7 @@ func (s *Server) routes() {
 	s.router.Put("/api/v1/channels/{id}", s.handleChannelUpdate)
 	s.router.Delete("/api/v1/channels/{id}", s.handleChannelDelete)
 	s.router.Post("/api/v1/channels/{id}/test", s.handleChannelTest)
+	s.router.Post("/api/v1/channels/{id}/selftest", s.handleChannelSelfTest)
 	s.router.Get("/api/v1/channels/{id}/health", s.handleChannelHealth)
 	s.router.Post("/api/v1/channels/{id}/connect", s.handleChannelConnect)
 	s.router.Post("/api/v1/channels/{id}/disconnect", s.handleChannelDisconnect)
@@ -259,6 +433,11 @@ func (s *Server) routes() {
 	s.router.Get("/api/v1/tasks/{id}/runs", s.handleTaskRuns)
 	s.router.Post("/api/v1/tasks/validate", s.handleTaskValidate)
 	s.router.Post("/api/v1/tasks/events/{event}/trigger", s.handleTaskEventTrigger)
+	s.router.Get("/api/v1/scheduler/export", s.handleSchedulerExport)
+	s.router.Get("/api/v1/scheduler/preview", s.handleSchedulerPreview)
+	s.router.Post("/api/v1/scheduler/import", s.handleSchedulerImport)
+	s.router.Post("/api/v1/scheduler/tasks/{id}/run", s.handleTaskRunNow)
+	s.router.Post("/api/v1/scheduler/events/{event}", s.handleTaskEventTrigger)
 
 	s.router.Get("/api/admin/stats", s.handleAdminStats)
 	s.router.Get("/api/admin/users", s.handleAdminUsers)
@@ -267,6 +446,11 @@ func (s *Server) routes() {
 	s.router.Get("/api/admin/health", s.handleAdminHealth)
 	s.router.Get("/api/admin/telemetry/config", s.handleAdminTelemetryConfig)
 	s.router.Put("/api/admin/telemetry/config", s.handleAdminTelemetryConfigUpdate)
+	s.router.Get("/api/admin/maintenance", s.handleAdminMaintenance)
+	s.router.Put("/api/admin/maintenance", s.handleAdminMaintenanceUpdate)
+	s.router.Post("/api/admin/db/compact", s.handleAdminDBCompact)
+	s.router.Get("/api/admin/mcp/policy", s.handleAdminMCPPolicy)
+	s.router.Get("/api/admin/debug/bundle", s.handleAdminDebugBundle)
 }
 
 // Bus returns the event bus instance.
@@ -294,6 +478,11 @@ func (s *Server) Memory() *memory.RAGManager {
 	return s.ragMemory
 }
 
+// Summarizer returns the session summarizer.
+func (s *Server) Summarizer() *summary.Summarizer {
+	return s.summarizer
+}
+
 // Channels returns the channel manager instance.
 func (s *Server) Channels() *channels.ChannelManager {
 	return s.channels
@@ -320,20 +509,21 @@ func (s *Server) Handler() http.Handler {
 }
 
 // Start starts the HTTP server and blocks until it stops.
-// It automatically allocates a port if configured to do so.
+// It automatically allocates a port if configured to do so, preferring
+// cfg.PreferredPort when it is free.
 func (s *Server) Start() error {
-	// Find an available port if not explicitly set or if set to :0
 	addr := s.cfg.ListenAddr
-	var port int
 
 	if addr == ":3000" || addr == ":0" || addr == "" {
-		// Dynamic port allocation
-		availablePort, err := GetAvailablePort()
+		// Dynamic port allocation: the listener stays bound from here on, so the
+		// port written below cannot be taken by another process first.
+		listener, port, err := ListenWithFallback(s.cfg.PreferredPort)
 		if err != nil {
 			return fmt.Errorf("failed to find available port: %w", err)
 		}
-		port = availablePort
-		addr = fmt.Sprintf(":%d", port)
+		if s.cfg.PreferredPort > 0 && port != s.cfg.PreferredPort {
+			log.Printf("Preferred port %d unavailable, using %d", s.cfg.PreferredPort, port)
+		}
 
 		// Write port to file for clients to discover
 		if err := WritePortFile(port); err != nil {
@@ -348,10 +538,17 @@ func (s *Server) Start() error {
 				log.Printf("Warning: failed to cleanup port file: %v", err)
 			}
 		}()
+
+		log.Printf("Starting server on http://localhost:%d", port)
+		return s.Serve(listener)
 	}
 
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
 	log.Printf("Starting server on http://localhost%s", addr)
-	return http.ListenAndServe(addr, s.router)
+	return s.Serve(listener)
 }
 
 // Serve serves HTTP requests on the provided listener.
@@ -379,12 +576,354 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return srv.Shutdown(ctx)
 }
 
-// SetCatalog sets the model catalog for the server.
+// Catalog returns the model catalog, or nil before it has loaded.
+func (s *Server) Catalog() *models.Catalog {
+	return s.catalog.Load()
+}
+
+// SetCatalog sets the model catalog for the server and passes it to the
+// hooks added with OnCatalog.
 func (s *Server) SetCatalog(catalog *models.Catalog) {
-	s.catalog = catalog
+	s.catalogMu.Lock()
+	defer s.catalogMu.Unlock()
+	s.catalog.Store(catalog)
+	for _, fn := range s.catalogHooks {
+		fn(catalog)
+	}
+}
+
+// OnCatalog calls fn with every catalog set from now on, and right away with
+// the current one if it has loaded.
+func (s *Server) OnCatalog(fn func(*models.Catalog)) {
+	s.catalogMu.Lock()
+	defer s.catalogMu.Unlock()
+	s.catalogHooks = append(s.catalogHooks, fn)
+	if catalog := s.catalog.Load(); catalog != nil {
+		fn(catalog)
+	}
+}
+
+// SetCatalogPending tells Warmup that the startup catalog load runs
+// elsewhere and finishes when done is closed, so it waits for that load
+// instead of fetching the catalog again. Call it before Warmup.
+func (s *Server) SetCatalogPending(done <-chan struct{}) {
+	s.catalogPending = done
+}
+
+// SetGenerationLimiter sets the limiter whose load /health reports.
+func (s *Server) SetGenerationLimiter(l *agent.GenerationLimiter) {
+	s.generations.Store(l)
+}
+
+// SetSessionActivity sets the tracker used to refuse deleting sessions the
+// agent is still handling.
+func (s *Server) SetSessionActivity(a *agent.SessionActivity) {
+	s.activity.Store(a)
+}
+
+// SetModelLimiter sets the per-model rate limiter whose queues /health reports.
+func (s *Server) SetModelLimiter(l *llm.ModelLimiter) {
+	s.modelLimits.Store(l)
+}
+
+// SetModelSettings sets the agent's model settings, which Reload and config
+// updates keep in step with the config.
+func (s *Server) SetModelSettings(m *agent.ModelSettings) {
+	s.modelSettings.Store(m)
 }
 
 // SetSpawnTool sets the spawn tool for the server.
 func (s *Server) SetSpawnTool(tool SpawnTool) {
 	s.spawnTool = tool
 }
+
+// recordToolCalls writes tool calls to the audit log. Failed calls are also added,
+// for known sessions, to the transcript as a tool message holding the structured error.
+func (s *Server) recordToolCalls(events <-chan bus.Event, cancel func()) {
+	defer cancel()
+
+	for evt := range events {
+		payload, _ := evt.Payload.(map[string]interface{})
+		tool, _ := payload["tool"].(string)
+		if evt.Event == bus.EventToolDenied {
+			if s.auditRepo != nil {
+				reason, _ := payload["reason"].(string)
+				_ = s.auditRepo.Create(&audit.AuditEntry{
+					SessionID:   evt.SessionID,
+					Tool:        tool,
+					Action:      audit.ActionToolDenied,
+					Description: fmt.Sprintf("Tool %s denied by tool scope", tool),
+					Success:     false,
+					ErrorMsg:    reason,
+					Metadata:    map[string]interface{}{"requester": payload["requester"]},
+				})
+			}
+			continue
+		}
+		toolErr, ok := payload["error"].(map[string]interface{})
+		if !ok {
+			if s.auditRepo != nil && tool != "" {
+				_ = s.auditRepo.Create(&audit.AuditEntry{
+					SessionID:   evt.SessionID,
+					Tool:        tool,
+					Action:      audit.ActionToolComplete,
+					Description: fmt.Sprintf("Tool %s completed", tool),
+					Success:     true,
+				})
+			}
+			continue
+		}
+		message, _ := toolErr["error"].(string)
+
+		if s.auditRepo != nil {
+			_ = s.auditRepo.Create(&audit.AuditEntry{
+				SessionID:   evt.SessionID,
+				Tool:        tool,
+				Action:      audit.ActionToolError,
+				Description: fmt.Sprintf("Tool %s failed", tool),
+				Success:     false,
+				ErrorMsg:    message,
+				Metadata:    map[string]interface{}{"code": toolErr["code"], "retriable": toolErr["retriable"]},
+			})
+		}
+
+		if evt.SessionID == "" {
+			continue
+		}
+		if _, err := s.store.GetSession(evt.SessionID); err != nil {
+			continue
+		}
+		record := map[string]interface{}{"tool": tool}
+		for k, v := range toolErr {
+			record[k] = v
+		}
+		if data, err := json.Marshal(record); err == nil {
+			_, _ = s.store.AddMessage(evt.SessionID, store.RoleTool, string(data))
+		}
+	}
+}
+
+// SkillExecutionObserver returns an observer that publishes skill.executed events
+// for the executions it is notified of. Register it on skill bridges.
+func (s *Server) SkillExecutionObserver() skills.ExecutionObserver {
+	return func(e skills.Execution) {
+		payload := map[string]interface{}{
+			"skill_id":    e.SkillID,
+			"duration_ms": e.Duration.Milliseconds(),
+			"success":     e.Err == nil,
+		}
+		if e.Err != nil {
+			payload["error"] = e.Err.Error()
+		}
+		s.bus.Publish(bus.NewEvent(bus.EventSkillExecuted, "", payload))
+	}
+}
+
+// recordSkillExecutions persists skill.executed events as per-skill stats.
+func (s *Server) recordSkillExecutions(events <-chan bus.Event, cancel func()) {
+	defer cancel()
+
+	for evt := range events {
+		payload, _ := evt.Payload.(map[string]interface{})
+		skillID, _ := payload["skill_id"].(string)
+		if skillID == "" {
+			continue
+		}
+		var duration time.Duration
+		switch ms := payload["duration_ms"].(type) {
+		case int64:
+			duration = time.Duration(ms) * time.Millisecond
+		case float64:
+			duration = time.Duration(ms) * time.Millisecond
+		}
+		errMsg, _ := payload["error"].(string)
+		if err := s.store.RecordSkillRun(skillID, duration, errMsg); err != nil {
+			log.Printf("Failed to record skill run for %s: %v", skillID, err)
+		}
+	}
+}
+
+// recordLLMCacheHits writes a zero-cost audit entry for every response served from the LLM cache.
+func (s *Server) recordLLMCacheHits() {
+	events, cancel := s.bus.Subscribe(bus.EventLLMCacheHit)
+	defer cancel()
+
+	for evt := range events {
+		if s.auditRepo == nil {
+			continue
+		}
+		payload, _ := evt.Payload.(map[string]interface{})
+		model, _ := payload["model"].(string)
+		_ = s.auditRepo.Create(&audit.AuditEntry{
+			SessionID:   evt.SessionID,
+			Action:      audit.ActionMessageSend,
+			Description: fmt.Sprintf("LLM cache hit for %s", model),
+			Cost:        &audit.CostInfo{Model: model},
+			Success:     true,
+			Metadata:    map[string]interface{}{"cache_hit": true},
+		})
+	}
+}
+
+// recordModelFallbacks writes an audit entry for every generation that was
+// handed to a fallback model, naming the model that served it and the models
+// that failed before it.
+func (s *Server) recordModelFallbacks(events <-chan bus.Event, cancel func()) {
+	defer cancel()
+
+	for evt := range events {
+		if s.auditRepo == nil {
+			continue
+		}
+		payload, _ := evt.Payload.(map[string]interface{})
+		requested, _ := payload["requested_model"].(string)
+		model, _ := payload["model"].(string)
+		success, _ := payload["success"].(bool)
+		errMsg, _ := payload["error"].(string)
+		metadata := map[string]interface{}{
+			"fallback":        true,
+			"requested_model": requested,
+			"failed":          payload["failed"],
+		}
+		if provider, ok := payload["provider"].(string); ok {
+			metadata["provider"] = provider
+		}
+		entry := &audit.AuditEntry{
+			SessionID: evt.SessionID,
+			Action:    audit.ActionMessageSend,
+			Success:   success,
+			ErrorMsg:  errMsg,
+			Metadata:  metadata,
+		}
+		if model != "" {
+			entry.Description = fmt.Sprintf("Generation for %s served by fallback model %s", requested, model)
+			entry.Cost = s.pricedCost(model, llm.Usage{})
+		} else {
+			entry.Description = fmt.Sprintf("Generation for %s failed on every fallback model", requested)
+		}
+		_ = s.auditRepo.Create(entry)
+	}
+}
+
+// usageFromPayload reads the token counts of a generation event.
+func usageFromPayload(payload map[string]interface{}) llm.Usage {
+	var usage llm.Usage
+	usage.PromptTokens, _ = payload["prompt_tokens"].(int)
+	usage.CompletionTokens, _ = payload["completion_tokens"].(int)
+	usage.TotalTokens, _ = payload["total_tokens"].(int)
+	usage.ReasoningTokens, _ = payload["reasoning_tokens"].(int)
+	return usage
+}
+
+// pricedCost is the audit cost of a generation by model, priced when it is
+// recorded so session budgets can sum total_cost; models without known
+// pricing cost nothing.
+func (s *Server) pricedCost(model string, usage llm.Usage) *audit.CostInfo {
+	info := &audit.CostInfo{
+		Model:           model,
+		InputTokens:     int64(usage.PromptTokens),
+		OutputTokens:    int64(usage.CompletionTokens),
+		TotalTokens:     int64(usage.TotalTokens),
+		ReasoningTokens: int64(usage.ReasoningTokens),
+	}
+	if s.costCalc == nil {
+		return info
+	}
+	if priced, err := s.costCalc.CalculateFromUsage(model, usage); err == nil {
+		info.InputCost = priced.InputCost
+		info.OutputCost = priced.OutputCost
+		info.TotalCost = priced.TotalCost
+	}
+	return info
+}
+
+// recordLLMUsage writes an audit entry with the token usage of every
+// generation whose provider reported it, counting reasoning tokens apart from
+// the rest of the output.
+func (s *Server) recordLLMUsage(events <-chan bus.Event, cancel func()) {
+	defer cancel()
+
+	for evt := range events {
+		if s.auditRepo == nil {
+			continue
+		}
+		payload, _ := evt.Payload.(map[string]interface{})
+		model, _ := payload["model"].(string)
+		var metadata map[string]interface{}
+		if effort, ok := payload["reasoning_effort"].(string); ok {
+			metadata = map[string]interface{}{"reasoning_effort": effort}
+		}
+		_ = s.auditRepo.Create(&audit.AuditEntry{
+			SessionID:   evt.SessionID,
+			Action:      audit.ActionMessageSend,
+			Description: fmt.Sprintf("Generation for %s", model),
+			Cost:        s.pricedCost(model, usageFromPayload(payload)),
+			Success:     true,
+			Metadata:    metadata,
+		})
+	}
+}
+
+// recordLLMErrors writes an audit entry for every failed provider call of a
+// chat or channel generation, telling timeouts apart from provider errors.
+func (s *Server) recordLLMErrors(events <-chan bus.Event, cancel func()) {
+	defer cancel()
+
+	for evt := range events {
+		payload, _ := evt.Payload.(map[string]interface{})
+		errorKind, _ := payload["error_kind"].(string)
+		if s.auditRepo == nil || errorKind == "" {
+			continue
+		}
+		kind, _ := payload["kind"].(string)
+		errMsg, _ := payload["error"].(string)
+		description := "LLM provider error"
+		if errorKind == agent.LLMErrorTimeout {
+			description = "LLM request timed out"
+		}
+		metadata := map[string]interface{}{"kind": kind, "error_kind": errorKind}
+		if channel, ok := payload["channel"].(string); ok {
+			metadata["channel"] = channel
+		}
+		_ = s.auditRepo.Create(&audit.AuditEntry{
+			SessionID:   evt.SessionID,
+			Action:      audit.ActionErrorOccurred,
+			Description: description,
+			Success:     false,
+			ErrorMsg:    errMsg,
+			Metadata:    metadata,
+		})
+	}
+}
+
+// recordCloudProxiedUsage writes an audit entry for every generation routed through
+// the Pryx Cloud proxy, marked cloud_proxy so it can be told apart from local-key usage.
+func (s *Server) recordCloudProxiedUsage(events <-chan bus.Event, cancel func()) {
+	defer cancel()
+
+	for evt := range events {
+		if s.auditRepo == nil {
+			continue
+		}
+		payload, _ := evt.Payload.(map[string]interface{})
+		model, _ := payload["model"].(string)
+		success, _ := payload["success"].(bool)
+		errMsg, _ := payload["error"].(string)
+		metadata := map[string]interface{}{"cloud_proxy": true}
+		if stream, ok := payload["stream"].(bool); ok {
+			metadata["stream"] = stream
+		}
+		if length, ok := payload["length"].(int); ok {
+			metadata["length"] = length
+		}
+		_ = s.auditRepo.Create(&audit.AuditEntry{
+			SessionID:   evt.SessionID,
+			Action:      audit.ActionMessageSend,
+			Description: fmt.Sprintf("Generation for %s via Pryx Cloud proxy", model),
+			Cost:        s.pricedCost(model, usageFromPayload(payload)),
+			Success:     success,
+			ErrorMsg:    errMsg,
+			Metadata:    metadata,
+		})
+	}
+}
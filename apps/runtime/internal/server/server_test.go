@@ -1,22 +1,37 @@
 package server
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"pryx-core/internal/agent"
+	"pryx-core/internal/agent/spawn"
+	"pryx-core/internal/audit"
+	"pryx-core/internal/bus"
 	"pryx-core/internal/config"
 	"pryx-core/internal/keychain"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/llm/providers"
+	"pryx-core/internal/models"
+	"pryx-core/internal/scheduler"
 	"pryx-core/internal/skills"
 	"pryx-core/internal/store"
 
@@ -193,6 +208,53 @@ description: from url
 	}
 }
 
+func TestHandleSkillsInstallBatch(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, _ := store.New(":memory:")
+	defer st.Close()
+	kc := newTestKeychain(t)
+
+	t.Setenv("PRYX_MANAGED_SKILLS_DIR", t.TempDir())
+	t.Setenv("PRYX_SKILLS_CONFIG_PATH", filepath.Join(t.TempDir(), "skills.yaml"))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("---\nname: batch-" + r.URL.Path[1:] + "\ndescription: from batch\n---\n# Batch skill"))
+	}))
+	defer ts.Close()
+
+	server := New(cfg, st.DB, kc)
+	server.skills = skills.NewRegistry()
+
+	reqBody := `{"urls":["` + ts.URL + `/one","` + ts.URL + `/gone","` + ts.URL + `/two"],"retries":0}`
+	req := httptest.NewRequest("POST", "/skills/install-batch", strings.NewReader(reqBody))
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var report skills.BatchInstallReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.Installed)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, skills.BatchStatusFailed, report.Results[1].Status)
+	assert.Contains(t, report.Results[1].Error, "404")
+
+	for _, id := range []string{"batch-one", "batch-two"} {
+		s, ok := server.skills.Get(id)
+		require.True(t, ok, id)
+		assert.True(t, s.Enabled)
+	}
+
+	req = httptest.NewRequest("POST", "/skills/install-batch", strings.NewReader(`{"urls":[]}`))
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
 func TestHandleHealth(t *testing.T) {
 	cfg := &config.Config{ListenAddr: ":0"}
 	s, _ := store.New(":memory:")
@@ -213,6 +275,430 @@ func TestHandleHealth(t *testing.T) {
 	assert.Equal(t, "ok", response["status"])
 }
 
+func TestHandleHealthReportsGenerations(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	limiter := agent.NewGenerationLimiter(2, 5)
+	release, err := limiter.Acquire(context.Background(), "session:a", nil)
+	require.NoError(t, err)
+	defer release()
+	server.SetGenerationLimiter(limiter)
+
+	rec := httptest.NewRecorder()
+	server.handleHealth(rec, httptest.NewRequest("GET", "/health", nil))
+
+	var response struct {
+		Generations agent.GenerationStats `json:"generations"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, agent.GenerationStats{Active: 1, Max: 2, QueueSize: 5}, response.Generations)
+}
+
+func TestHandleHealthReportsBusDropped(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	_, cancel := server.bus.Subscribe(bus.EventType("test.unread"))
+	defer cancel()
+	for i := 0; i < bus.DefaultBuffer+3; i++ {
+		server.bus.Publish(bus.NewEvent(bus.EventType("test.unread"), "", nil))
+	}
+
+	rec := httptest.NewRecorder()
+	server.handleHealth(rec, httptest.NewRequest("GET", "/health", nil))
+
+	var response struct {
+		BusDropped int64 `json:"bus_dropped"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, int64(3), response.BusDropped)
+}
+
+func TestHandleHealthReportsModelLimits(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	limiter := llm.NewModelLimiter("openai", nil)
+	release, err := limiter.Acquire(context.Background(), "gpt-4o")
+	require.NoError(t, err)
+	defer release()
+	server.SetModelLimiter(limiter)
+
+	rec := httptest.NewRecorder()
+	server.handleHealth(rec, httptest.NewRequest("GET", "/health", nil))
+
+	var response struct {
+		ModelLimits map[string]llm.ModelLimitStats `json:"model_limits"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, llm.ModelLimitStats{Active: 1, Remaining: -1}, response.ModelLimits["gpt-4o"])
+}
+
+func TestCloudProxyHealthAndAudit(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	cfg := &config.Config{ListenAddr: ":0", ModelProvider: "openai", CloudAPIUrl: "https://cloud.example", CloudProxyFallback: true}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+	require.NoError(t, kc.Set("cloud_access_token", "cloud-token"))
+
+	server := New(cfg, s.DB, kc)
+	health := func() map[string]any {
+		rec := httptest.NewRecorder()
+		server.handleHealth(rec, httptest.NewRequest("GET", "/health", nil))
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+	assert.Equal(t, true, health()["cloud_proxy"])
+
+	server.bus.Publish(bus.NewEvent(bus.EventLLMCloudProxied, "sess-1", map[string]interface{}{
+		"model": "gpt-4o", "stream": false, "success": true, "prompt_tokens": 3, "completion_tokens": 4, "total_tokens": 7,
+	}))
+	require.Eventually(t, func() bool {
+		entries, err := server.AuditRepo().Query(audit.QueryOptions{SessionID: "sess-1"})
+		return err == nil && len(entries) == 1 && entries[0].Cost != nil && entries[0].Cost.TotalTokens == 7
+	}, time.Second, 10*time.Millisecond)
+	entries, _ := server.AuditRepo().Query(audit.QueryOptions{SessionID: "sess-1"})
+	assert.Equal(t, true, entries[0].Metadata.(map[string]interface{})["cloud_proxy"])
+
+	// A key from the environment is used before the proxy, as in the agent.
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	assert.Equal(t, false, health()["cloud_proxy"])
+	t.Setenv("OPENAI_API_KEY", "")
+
+	require.NoError(t, kc.SetProviderKey("openai", "sk-local"))
+	assert.Equal(t, false, health()["cloud_proxy"])
+}
+
+func TestModelFallbackAudit(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0"}, s.DB, newTestKeychain(t))
+
+	server.bus.Publish(bus.NewEvent(bus.EventLLMFallback, "sess-1", map[string]interface{}{
+		"requested_model": "gpt-4o",
+		"model":           "gpt-4o-mini",
+		"provider":        "openai",
+		"failed":          []map[string]interface{}{{"model": "gpt-4o", "error": "llm rate limited", "timeout": false}},
+		"success":         true,
+	}))
+	require.Eventually(t, func() bool {
+		entries, err := server.AuditRepo().Query(audit.QueryOptions{SessionID: "sess-1"})
+		return err == nil && len(entries) == 1
+	}, time.Second, 10*time.Millisecond)
+	entries, _ := server.AuditRepo().Query(audit.QueryOptions{SessionID: "sess-1"})
+	require.NotNil(t, entries[0].Cost)
+	assert.Equal(t, "gpt-4o-mini", entries[0].Cost.Model)
+	assert.True(t, entries[0].Success)
+	metadata := entries[0].Metadata.(map[string]interface{})
+	assert.Equal(t, true, metadata["fallback"])
+	assert.Equal(t, "gpt-4o", metadata["requested_model"])
+}
+
+func TestAdminDBCompact(t *testing.T) {
+	s, err := store.New(t.TempDir() + "/pryx.db")
+	require.NoError(t, err)
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0", DBCompactSchedule: "0 4 * * 0"}, s.DB, newTestKeychain(t))
+
+	task, err := server.Scheduler().GetTask(compactTaskID)
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, scheduler.TaskTypeDBCompact, task.TaskType)
+	require.NoError(t, server.syncCompactTask("0 5 * * *"))
+	task, _ = server.Scheduler().GetTask(compactTaskID)
+	assert.Equal(t, "0 5 * * *", task.CronExpression)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/admin/db/compact", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result store.CompactResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Positive(t, result.SizeBefore)
+
+	server.compacting.Store(true)
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/admin/db/compact", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	server.compacting.Store(false)
+
+	require.NoError(t, server.syncCompactTask(""))
+	task, _ = server.Scheduler().GetTask(compactTaskID)
+	assert.Nil(t, task)
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	server := New(cfg, s.DB, kc)
+
+	req := httptest.NewRequest("PUT", "/api/admin/maintenance", strings.NewReader(`{"enabled":true}`))
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, server.MaintenanceMode())
+	assert.True(t, server.Scheduler().IsPaused())
+
+	rec = httptest.NewRecorder()
+	server.handleHealth(rec, httptest.NewRequest("GET", "/health", nil))
+	var health map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+	assert.Equal(t, "degraded", health["status"])
+	assert.Equal(t, true, health["maintenance"])
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp/tools/call", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+
+	req = httptest.NewRequest("PUT", "/api/admin/maintenance", strings.NewReader(`{"enabled":false}`))
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, server.MaintenanceMode())
+	assert.False(t, server.Scheduler().IsPaused())
+}
+
+func TestIdleShutdown(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	disabled := New(&config.Config{ListenAddr: ":0"}, s.DB, kc)
+	assert.Nil(t, disabled.IdleShutdown())
+
+	server := New(&config.Config{ListenAddr: ":0", IdleTimeout: 300 * time.Millisecond}, s.DB, kc)
+	events, cancel := server.Bus().Subscribe(bus.EventIdleShutdown)
+	defer cancel()
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/capabilities", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	select {
+	case <-server.IdleShutdown():
+	case <-time.After(3 * time.Second):
+		t.Fatal("idle shutdown did not fire")
+	}
+
+	var states []string
+	for len(states) < 2 {
+		select {
+		case evt := <-events:
+			states = append(states, evt.Payload.(map[string]interface{})["state"].(string))
+		case <-time.After(time.Second):
+			t.Fatalf("missing idle events, got %v", states)
+		}
+	}
+	assert.Equal(t, []string{"warning", "shutting_down"}, states)
+}
+
+func TestRecordToolErrors(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	server := New(cfg, s.DB, kc)
+	sess, err := s.CreateSession("tools")
+	require.NoError(t, err)
+
+	server.Bus().Publish(bus.NewEvent(bus.EventToolComplete, sess.ID, map[string]interface{}{
+		"tool":  "fs/write",
+		"error": map[string]interface{}{"error": "denied by policy", "code": "permission_denied", "retriable": false},
+	}))
+
+	require.Eventually(t, func() bool {
+		msgs, err := s.GetMessages(sess.ID)
+		return err == nil && len(msgs) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	msgs, _ := s.GetMessages(sess.ID)
+	assert.Equal(t, store.RoleTool, msgs[0].Role)
+	var recorded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(msgs[0].Content), &recorded))
+	assert.Equal(t, "fs/write", recorded["tool"])
+	assert.Equal(t, "permission_denied", recorded["code"])
+	assert.Equal(t, false, recorded["retriable"])
+
+	entries, err := server.AuditRepo().Query(audit.QueryOptions{SessionID: sess.ID, Action: audit.ActionToolError})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "denied by policy", entries[0].ErrorMsg)
+	assert.False(t, entries[0].Success)
+}
+
+func TestHandleMCPCall_ToolScope(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	sess, err := s.CreateSession("scoped")
+	require.NoError(t, err)
+	cfg := &config.Config{
+		ListenAddr: ":0",
+		ToolScopes: map[string]config.ToolScope{
+			sess.ID:   {Allow: []string{"fs.*"}, Deny: []string{"shell.*"}},
+			"default": {Deny: []string{"shell.*"}},
+		},
+	}
+	server := New(cfg, s.DB, newTestKeychain(t))
+
+	body := fmt.Sprintf(`{"session_id":%q,"tool":"shell:exec","arguments":{}}`, sess.ID)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp/tools/call", strings.NewReader(body)))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "permission_denied", resp["code"])
+
+	require.Eventually(t, func() bool {
+		entries, err := server.AuditRepo().Query(audit.QueryOptions{SessionID: sess.ID, Action: audit.ActionToolDenied})
+		return err == nil && len(entries) == 1 && entries[0].Tool == "mcp.shell.exec"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Tools within the scope get past it to the server lookup
+	body = fmt.Sprintf(`{"session_id":%q,"tool":"fs:read","arguments":{}}`, sess.ID)
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp/tools/call", strings.NewReader(body)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "not_found", resp["code"])
+
+	// Leaving out the session falls under the default scope
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp/tools/call", strings.NewReader(`{"tool":"shell:exec","arguments":{}}`)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestRecordLLMUsage(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0"}, s.DB, newTestKeychain(t))
+
+	server.Bus().Publish(bus.NewEvent(bus.EventLLMUsage, "usage-session", map[string]interface{}{
+		"model":             "o3",
+		"prompt_tokens":     5,
+		"completion_tokens": 40,
+		"reasoning_tokens":  32,
+		"total_tokens":      45,
+		"reasoning_effort":  "high",
+	}))
+
+	var entries []*audit.AuditEntry
+	require.Eventually(t, func() bool {
+		var err error
+		entries, err = server.AuditRepo().Query(audit.QueryOptions{SessionID: "usage-session", Action: audit.ActionMessageSend})
+		return err == nil && len(entries) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NotNil(t, entries[0].Cost)
+	assert.Equal(t, int64(32), entries[0].Cost.ReasoningTokens)
+	assert.Equal(t, int64(40), entries[0].Cost.OutputTokens)
+	assert.Equal(t, "o3", entries[0].Cost.Model)
+}
+
+func TestRecordLLMErrors(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0"}, s.DB, newTestKeychain(t))
+
+	server.Bus().Publish(bus.NewEvent(bus.EventErrorOccurred, "error-session", map[string]interface{}{
+		"kind":       "agent.llm_timeout",
+		"error":      "llm request timed out",
+		"timeout":    true,
+		"error_kind": agent.LLMErrorTimeout,
+	}))
+	server.Bus().Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
+		"kind":       "agent.channel.llm_error",
+		"error":      "bad gateway",
+		"timeout":    false,
+		"error_kind": agent.LLMErrorProvider,
+		"channel":    "telegram:42",
+	}))
+	server.Bus().Publish(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{
+		"kind": "ws.writer.panic",
+	}))
+
+	var entries []*audit.AuditEntry
+	require.Eventually(t, func() bool {
+		var err error
+		entries, err = server.AuditRepo().Query(audit.QueryOptions{Action: audit.ActionErrorOccurred})
+		return err == nil && len(entries) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	byKind := map[string]*audit.AuditEntry{}
+	for _, e := range entries {
+		metadata, _ := e.Metadata.(map[string]interface{})
+		kind, _ := metadata["error_kind"].(string)
+		byKind[kind] = e
+	}
+	require.Contains(t, byKind, agent.LLMErrorTimeout)
+	require.Contains(t, byKind, agent.LLMErrorProvider)
+	assert.Equal(t, "error-session", byKind[agent.LLMErrorTimeout].SessionID)
+	assert.Equal(t, "LLM request timed out", byKind[agent.LLMErrorTimeout].Description)
+	assert.False(t, byKind[agent.LLMErrorTimeout].Success)
+	assert.Equal(t, "bad gateway", byKind[agent.LLMErrorProvider].ErrorMsg)
+	assert.Equal(t, "telegram:42", byKind[agent.LLMErrorProvider].Metadata.(map[string]interface{})["channel"])
+}
+
+func TestRecordLLMUsage_PricesSessionBudget(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0"}, s.DB, newTestKeychain(t))
+
+	sess, err := s.CreateSession("budget")
+	require.NoError(t, err)
+	maxCost := 3.0
+	require.NoError(t, s.SetSessionBudget(sess.ID, store.SessionBudget{MaxCost: &maxCost}))
+
+	server.Bus().Publish(bus.NewEvent(bus.EventLLMUsage, sess.ID, map[string]interface{}{
+		"model":             "gpt-4o",
+		"prompt_tokens":     1000,
+		"completion_tokens": 100,
+		"total_tokens":      1100,
+	}))
+
+	var exceeded *store.SessionBudgetExceededError
+	require.Eventually(t, func() bool {
+		return errors.As(s.CheckSessionBudget(sess.ID), &exceeded)
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "max_cost", exceeded.Limit)
+	assert.InDelta(t, 3.5, exceeded.Used, 1e-9)
+}
+
+func TestMCPServerPolicy(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	cfg.MCPServerPolicy.Deny = []string{"https://untrusted.example.com/*"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	server := New(cfg, s.DB, kc)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/admin/mcp/policy", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var policy map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &policy))
+	assert.Equal(t, true, policy["restricted"])
+	assert.Equal(t, []interface{}{"https://untrusted.example.com/*"}, policy["deny"])
+
+	body := `{"name":"shady","url":"https://untrusted.example.com/mcp"}`
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/mcp/discovery/custom", strings.NewReader(body)))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "deny list")
+}
+
 func TestHandleSkillsList(t *testing.T) {
 	cfg := &config.Config{ListenAddr: ":0"}
 	s, _ := store.New(":memory:")
@@ -234,6 +720,37 @@ func TestHandleSkillsList(t *testing.T) {
 	assert.Contains(t, response, "skills")
 }
 
+func TestHandleSkillsList_ByCategory(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, _ := store.New(":memory:")
+	defer st.Close()
+
+	server := New(cfg, st.DB, newTestKeychain(t))
+	server.skills = skills.NewRegistry()
+	server.skills.Upsert(skills.Skill{ID: "misc", Category: skills.UncategorizedCategory})
+	server.skills.Upsert(skills.Skill{ID: "lint", Category: "dev"})
+	server.skills.Upsert(skills.Skill{ID: "deploy", Category: "dev", Priority: 5})
+
+	var resp struct {
+		Skills     []skills.Skill         `json:"skills"`
+		Categories []skills.CategoryCount `json:"categories"`
+	}
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/skills", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Skills, 3)
+	assert.Equal(t, []string{"deploy", "lint", "misc"}, []string{resp.Skills[0].ID, resp.Skills[1].ID, resp.Skills[2].ID})
+	assert.Equal(t, []skills.CategoryCount{{Name: "dev", Count: 2}, {Name: skills.UncategorizedCategory, Count: 1}}, resp.Categories)
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/skills?category=DEV", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Skills, 2)
+	assert.Len(t, resp.Categories, 2)
+}
+
 func TestHandleSkillsInfo_MissingID(t *testing.T) {
 	cfg := &config.Config{ListenAddr: ":0"}
 	s, _ := store.New(":memory:")
@@ -331,6 +848,79 @@ func TestHandleMCPCall_MissingTool(t *testing.T) {
 	server.handleMCPCall(rec, req)
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// A tool name without a server is refused by the manager as invalid arguments
+	rec = httptest.NewRecorder()
+	server.handleMCPCall(rec, httptest.NewRequest("POST", "/mcp/tools/call", strings.NewReader(`{"tool":"bogus"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "invalid_arguments", resp["code"])
+}
+
+func TestHandleMCPApprovalResolve(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0"}, s.DB, newTestKeychain(t))
+
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{"missing approved", "session-1-1", `{}`, http.StatusBadRequest},
+		{"invalid json", "session-1-1", `nope`, http.StatusBadRequest},
+		{"unknown approval", "session-1-1", `{"approved":true}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/mcp/approvals/"+tt.id+"/resolve", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+
+	req := httptest.NewRequest("DELETE", "/mcp/approvals", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, float64(0), resp["denied"])
+}
+
+func TestHandleMCPResources(t *testing.T) {
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0"}, s.DB, newTestKeychain(t))
+
+	for _, path := range []string{"/mcp/resources", "/mcp/prompts"} {
+		req := httptest.NewRequest("GET", path, nil)
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+	}
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid json", `nope`, http.StatusBadRequest},
+		{"missing uri", `{"server":"docs"}`, http.StatusBadRequest},
+		{"invalid server", `{"server":"../x","uri":"file:///a"}`, http.StatusBadRequest},
+		{"unknown server", `{"server":"docs","uri":"file:///a"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/mcp/resources/read", strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			server.router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
 }
 
 func TestCorsMiddleware(t *testing.T) {
@@ -610,6 +1200,61 @@ func TestHandleProviderKey_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestHandleProviderKeySet_Validate(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	var checked []string
+	server.checkProviderKey = func(ctx context.Context, providerID, apiKey string) error {
+		checked = append(checked, apiKey)
+		switch apiKey {
+		case "sk-good":
+			return nil
+		case "sk-offline":
+			return errors.New("could not validate key: connection refused")
+		default:
+			return fmt.Errorf("%w: Incorrect API key provided", providers.ErrInvalidAPIKey)
+		}
+	}
+
+	set := func(query, key string) (int, map[string]any) {
+		req := httptest.NewRequest("POST", "/api/v1/providers/openai/key"+query, strings.NewReader(`{"api_key":"`+key+`"}`))
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	code, body := set("?validate=1", "sk-typo")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, body["error"], "Incorrect API key provided")
+	stored, _ := server.keychain.GetProviderKey("openai")
+	assert.Empty(t, stored, "rejected key must not be stored")
+
+	code, _ = set("?validate=1", "sk-offline")
+	assert.Equal(t, http.StatusBadGateway, code)
+
+	code, body = set("?validate=1", "sk-good")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, body["validated"])
+
+	// Without the parameter or config default the key is stored unchecked.
+	checked = nil
+	code, body = set("", "sk-typo")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, body["validated"])
+	assert.Empty(t, checked)
+
+	server.cfg.ValidateProviderKeys = true
+	code, _ = set("", "sk-typo")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = set("?validate=0", "sk-typo")
+	assert.Equal(t, http.StatusOK, code)
+}
+
 func TestHandleProviderKeySet_InvalidBody(t *testing.T) {
 	cfg := &config.Config{ListenAddr: ":0"}
 	s, _ := store.New(":memory:")
@@ -631,6 +1276,14 @@ func TestHandleProviderKeySet_InvalidBody(t *testing.T) {
 		server.router.ServeHTTP(rec, req)
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 	}
+
+	{
+		req := httptest.NewRequest("POST", "/api/v1/providers/openai/key", strings.NewReader(`{"api_key":"env://PRYX_TEST_UNSET_PROVIDER_KEY"}`))
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "secret not found")
+	}
 }
 
 func TestHandleProviderKey_InvalidProviderID(t *testing.T) {
@@ -676,20 +1329,102 @@ func TestHandleProviderKey_KeychainUnavailable(t *testing.T) {
 	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
 }
 
-func TestServer_Bus(t *testing.T) {
-	cfg := &config.Config{ListenAddr: ":0"}
+func TestHandleProviderHealth(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"models": []map[string]string{{"name": "llama3"}}})
+	}))
+	defer ollama.Close()
+
 	s, _ := store.New(":memory:")
 	defer s.Close()
-	kc := newTestKeychain(t)
+	server := New(&config.Config{ListenAddr: ":0", OllamaEndpoint: ollama.URL}, s.DB, newTestKeychain(t))
 
-	server := New(cfg, s.DB, kc)
+	health := func(provider string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/providers/"+provider+"/health", nil))
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
 
-	b := server.Bus()
-	assert.NotNil(t, b)
-	assert.Equal(t, server.bus, b)
-}
+	code, body := health("ollama")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, body["reachable"], body)
+	assert.Contains(t, body, "latency_ms")
 
-func TestServer_Handler(t *testing.T) {
+	code, body = health("openai")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, false, body["reachable"])
+	assert.Equal(t, "no API key configured", body["error"])
+
+	code, _ = health("unknown-provider")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	assert.Equal(t, `Get "https://example.com/?key=[REDACTED]": timeout`, redactKey(`Get "https://example.com/?key=AIza-secret": timeout`, "AIza-secret"))
+
+	noKeychain := New(&config.Config{ListenAddr: ":0"}, s.DB, nil)
+	rec := httptest.NewRecorder()
+	noKeychain.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/providers/openai/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestHandleProviderModels_Live(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"models": []map[string]string{{"name": "llama3"}, {"name": "qwen3"}}})
+	}))
+	defer ollama.Close()
+
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(&config.Config{ListenAddr: ":0", OllamaEndpoint: ollama.URL}, s.DB, newTestKeychain(t))
+
+	models := func(path string) map[string]any {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+
+	body := models("/api/v1/providers/ollama/models")
+	assert.NotContains(t, body, "live")
+
+	body = models("/api/v1/providers/ollama/models?live=1")
+	live := body["live"].(map[string]any)
+	assert.Equal(t, float64(2), live["count"])
+	assert.Equal(t, []any{"qwen3"}, live["missing_from_catalog"])
+	assert.ElementsMatch(t, []any{"llama2", "mistral"}, live["missing_live"])
+
+	byID := map[string]map[string]any{}
+	for _, m := range body["models"].([]any) {
+		entry := m.(map[string]any)
+		byID[entry["id"].(string)] = entry
+	}
+	assert.Equal(t, true, byID["llama3"]["live"])
+	assert.Equal(t, true, byID["llama3"]["in_catalog"])
+	assert.Equal(t, false, byID["llama2"]["live"])
+	assert.Equal(t, false, byID["qwen3"]["in_catalog"])
+
+	body = models("/api/v1/providers/openai/models?live=1")
+	assert.Equal(t, "no API key configured", body["live"].(map[string]any)["error"])
+	assert.Len(t, body["models"], 3)
+}
+
+func TestServer_Bus(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	server := New(cfg, s.DB, kc)
+
+	b := server.Bus()
+	assert.NotNil(t, b)
+	assert.Equal(t, server.bus, b)
+}
+
+func TestServer_Handler(t *testing.T) {
 	cfg := &config.Config{ListenAddr: ":0"}
 	s, _ := store.New(":memory:")
 	defer s.Close()
@@ -820,6 +1555,43 @@ func TestServer_DynamicPortAllocation(t *testing.T) {
 	cancel()
 }
 
+func TestServer_PreferredPortFallback(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	taken, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer taken.Close()
+	preferred := taken.Addr().(*net.TCPAddr).Port
+
+	cfg := &config.Config{ListenAddr: ":0", PreferredPort: preferred}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(cfg, s.DB, newTestKeychain(t))
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Start() }()
+
+	var port int
+	require.Eventually(t, func() bool {
+		port, err = ReadPortFile()
+		return err == nil
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.NotEqual(t, preferred, port)
+
+	// The port file names the port the server is actually serving on.
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/health", port))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	assert.ErrorIs(t, <-errCh, http.ErrServerClosed)
+	_, err = ReadPortFile()
+	assert.Error(t, err, "port file should be removed on shutdown")
+}
+
 func BenchmarkHandleHealth(b *testing.B) {
 	cfg := &config.Config{ListenAddr: ":0"}
 	s, _ := store.New(":memory:")
@@ -835,3 +1607,1298 @@ func BenchmarkHandleHealth(b *testing.B) {
 		server.handleHealth(rec, req)
 	}
 }
+
+func TestCapabilities(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	server := New(cfg, s.DB, kc)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/capabilities", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		APIVersion     string          `json:"api_version"`
+		Features       map[string]bool `json:"features"`
+		NotImplemented []stubEndpoint  `json:"not_implemented"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, APIVersion, body.APIVersion)
+	assert.Len(t, body.NotImplemented, len(stubRoutes))
+	routes := server.registeredRoutes()
+	for _, stub := range body.NotImplemented {
+		assert.Equal(t, stubRoutes[stub.Feature], stub.Route)
+		assert.Contains(t, routes, stub.Route)
+		assert.False(t, body.Features[stub.Feature], "stub feature %s should be unsupported", stub.Feature)
+	}
+	assert.True(t, body.Features["websocket"])
+	assert.True(t, body.Features["scheduler"])
+	assert.True(t, body.Features["scheduler_events"])
+	assert.False(t, body.Features["response_cache"])
+	for name := range routeFeatures {
+		assert.True(t, body.Features[name], "feature %s should be backed by a registered route", name)
+	}
+}
+
+type stubProvider struct {
+	model string
+}
+
+func (p *stubProvider) Complete(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.model = req.Model
+	return &llm.ChatResponse{Content: "Discussed deployment.", Role: llm.RoleAssistant}, nil
+}
+
+func (p *stubProvider) Stream(ctx context.Context, req llm.ChatRequest) (<-chan llm.StreamChunk, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestSessionSummarize(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0", ModelProvider: "openai", ModelName: "gpt-4o"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	server := New(cfg, s.DB, kc)
+	provider := &stubProvider{}
+	server.llmProvider = func() (llm.Provider, error) { return provider, nil }
+
+	sess, err := server.store.CreateSession("Deploy")
+	require.NoError(t, err)
+	_, err = server.store.AddMessage(sess.ID, store.RoleUser, "How do we deploy?")
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/sessions/"+sess.ID+"/summarize", strings.NewReader(`{"persist":true}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "Discussed deployment.", body["summary"])
+	assert.Equal(t, "gpt-4o-mini", provider.model)
+
+	fetched, err := server.store.GetSession(sess.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Discussed deployment.", fetched.Description)
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/sessions/missing/summarize", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSchedulerExportImport(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	server := New(cfg, s.DB, kc)
+
+	body := `[{"name":"nightly","cron_expression":"0 2 * * *","task_type":"reminder","enabled":true},{"name":"bad","cron_expression":"nope","task_type":"reminder"}]`
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/import", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var result map[string][]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Len(t, result["created"], 1)
+	assert.Len(t, result["invalid"], 1)
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/scheduler/export", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var defs []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &defs))
+	require.Len(t, defs, 1)
+	assert.Equal(t, "nightly", defs[0]["name"])
+	assert.NotContains(t, defs[0], "run_count")
+}
+
+func TestHandleTaskCreate_ValidatesPayloadModel(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0", ModelProvider: "openai", ModelName: "gpt-4o"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	server.SetCatalog(&models.Catalog{Models: map[string]models.ModelInfo{
+		"gpt-4o":      {ID: "gpt-4o", Provider: "openai"},
+		"gpt-4o-mini": {ID: "gpt-4o-mini", Provider: "openai"},
+	}})
+
+	create := func(payload string) int {
+		body, _ := json.Marshal(map[string]interface{}{
+			"name":            "summary",
+			"cron_expression": "0 9 * * *",
+			"task_type":       "message",
+			"payload":         payload,
+		})
+		req := httptest.NewRequest("POST", "/api/v1/tasks", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusBadRequest, create(`{"content":"summarize","model":"no-such-model"}`))
+	assert.Equal(t, http.StatusCreated, create(`{"content":"summarize","model":"gpt-4o-mini"}`))
+}
+
+func TestTaskEventExecutor_ResolvesModel(t *testing.T) {
+	exec := &taskEventExecutor{defaultModel: func() string { return "gpt-4o" }}
+
+	model, source := exec.resolveModel(map[string]interface{}{"model": "gpt-4o-mini"})
+	assert.Equal(t, "gpt-4o-mini", model)
+	assert.Equal(t, models.SourceTask, source)
+
+	model, source = exec.resolveModel(map[string]interface{}{})
+	assert.Equal(t, "gpt-4o", model)
+	assert.Equal(t, models.SourceDefault, source)
+
+	exec.bus = bus.New()
+	requests, cancel := exec.bus.Subscribe(bus.EventChatRequest)
+	defer cancel()
+	task := &scheduler.ScheduledTask{
+		ID:       "t1",
+		TaskType: scheduler.TaskTypeMessage,
+		Payload:  `{"content":"summarize","model":"gpt-4o-mini","session_id":"s1"}`,
+	}
+	_, err := exec.Execute(context.Background(), task)
+	require.NoError(t, err)
+	select {
+	case evt := <-requests:
+		payload := evt.Payload.(map[string]interface{})
+		assert.Equal(t, "s1", evt.SessionID)
+		assert.Equal(t, "summarize", payload["content"])
+		assert.Equal(t, "gpt-4o-mini", payload["model"])
+	case <-time.After(time.Second):
+		t.Fatal("expected the task to request a generation")
+	}
+}
+
+func TestHandleSchedulerPreview(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	server := New(cfg, s.DB, newTestKeychain(t))
+
+	preview := func(query string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/scheduler/preview?"+query, nil))
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+		return rec.Code, body
+	}
+
+	code, body := preview(url.Values{"expr": {"every 5 minutes"}, "count": {"3"}, "timezone": {"UTC"}}.Encode())
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, "@every 5m0s", body["normalized"])
+	assert.Equal(t, "cron", body["kind"])
+	assert.Len(t, body["next_runs"], 3)
+
+	code, body = preview(url.Values{"expr": {"event:user.login"}}.Encode())
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, "event", body["kind"])
+	assert.Empty(t, body["next_runs"])
+
+	code, body = preview(url.Values{"expr": {"not a schedule"}}.Encode())
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.NotEmpty(t, body["error"])
+
+	code, _ = preview(url.Values{"expr": {"* * * * *"}, "count": {"500"}}.Encode())
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestHandleTaskRunNow(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	task := &scheduler.ScheduledTask{
+		Name:           "nightly",
+		CronExpression: "0 2 * * *",
+		TaskType:       scheduler.TaskTypeReminder,
+		Enabled:        true,
+	}
+	require.NoError(t, server.Scheduler().CreateTask(task))
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/tasks/"+task.ID+"/run?wait=true", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var run RunResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &run))
+	assert.Equal(t, task.ID, run.TaskID)
+	assert.Equal(t, string(scheduler.RunStatusSuccess), run.Status)
+	assert.Equal(t, string(scheduler.RunTriggerManual), run.Trigger)
+	assert.NotEmpty(t, run.Output)
+
+	// Without wait the run starts in the background and a second request
+	// conflicts until it finishes.
+	release := make(chan struct{})
+	server.Scheduler().RegisterExecutor(scheduler.TaskTypeReminder, blockingExecutor(release))
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/tasks/"+task.ID+"/run", nil))
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	var started RunResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &started))
+	assert.NotEmpty(t, started.ID)
+	assert.Equal(t, string(scheduler.RunStatusRunning), started.Status)
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/tasks/"+task.ID+"/run", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		runs, err := server.Scheduler().GetTaskRuns(task.ID, 10)
+		if err != nil {
+			return false
+		}
+		for _, r := range runs {
+			if r.ID == started.ID {
+				return r.Status == scheduler.RunStatusSuccess
+			}
+		}
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/tasks/missing/run", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	server.Scheduler().Pause()
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/tasks/"+task.ID+"/run", nil))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+// blockingExecutor succeeds once release is closed.
+type blockingExecutor chan struct{}
+
+func (e blockingExecutor) Execute(ctx context.Context, task *scheduler.ScheduledTask) (string, error) {
+	<-e
+	return "done", nil
+}
+
+func TestSchedulerDefaultTimezone(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0", Timezone: "Asia/Tokyo"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+
+	create := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/tasks", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := create(`{"name":"standup","cron_expression":"0 9 * * *","task_type":"reminder","payload":"{}"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var task TaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &task))
+	assert.Equal(t, "Asia/Tokyo", task.Timezone)
+
+	rec = create(`{"name":"bad","cron_expression":"0 9 * * *","task_type":"reminder","payload":"{}","timezone":"Nowhere/Zone"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("PUT", "/api/v1/tasks/"+task.ID, strings.NewReader(`{"timezone":"Nowhere/Zone"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandleSessionUpdateTimezone(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	sess, err := s.CreateSession("Zoned")
+	require.NoError(t, err)
+
+	patch := func(id, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("PATCH", "/api/v1/sessions/"+id, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := patch(sess.ID, `{"timezone":"America/New_York"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "America/New_York", body["timezone"])
+
+	assert.Equal(t, http.StatusBadRequest, patch(sess.ID, `{"timezone":"Nowhere/Zone"}`).Code)
+	assert.Equal(t, http.StatusNotFound, patch("missing", `{"timezone":"UTC"}`).Code)
+
+	require.Equal(t, http.StatusOK, patch(sess.ID, `{"timezone":""}`).Code)
+	fetched, err := s.GetSession(sess.ID)
+	require.NoError(t, err)
+	assert.Empty(t, fetched.Timezone)
+}
+
+func TestHandleSessionCreateIdempotencyKey(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	create := func(key string) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest("POST", "/api/v1/sessions", strings.NewReader(`{"title":"Mobile"}`))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body
+	}
+
+	rec, first := create("retry-1")
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	rec, replay := create("retry-1")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+	assert.Equal(t, first["id"], replay["id"])
+
+	rec, other := create("retry-2")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.NotEqual(t, first["id"], other["id"])
+
+	rec, _ = create("")
+	require.Equal(t, http.StatusCreated, rec.Code)
+	sessions, err := s.ListSessions()
+	require.NoError(t, err)
+	assert.Len(t, sessions, 3)
+
+	rec, _ = create(strings.Repeat("k", 256))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandleMessagePin(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	sess, err := s.CreateSession("Pins")
+	require.NoError(t, err)
+	msg, err := s.AddMessage(sess.ID, store.RoleUser, "The deadline is Friday.")
+	require.NoError(t, err)
+
+	pin := func(method, sessionID, messageID string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest(method, "/api/v1/sessions/"+sessionID+"/messages/"+messageID+"/pin", nil))
+		return rec
+	}
+
+	rec := pin("POST", sess.ID, msg.ID)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	messages, err := s.GetMessagesWithLimit(sess.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.True(t, messages[0].Pinned)
+
+	assert.Equal(t, http.StatusNotFound, pin("POST", sess.ID, "missing").Code)
+	assert.Equal(t, http.StatusNotFound, pin("POST", "other", msg.ID).Code)
+
+	require.Equal(t, http.StatusOK, pin("DELETE", sess.ID, msg.ID).Code)
+	messages, _ = s.GetMessagesWithLimit(sess.ID, 0)
+	assert.False(t, messages[0].Pinned)
+}
+
+func TestHandleSchedulerEvent(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	require.NoError(t, server.Scheduler().CreateTask(&scheduler.ScheduledTask{
+		Name:           "on-deploy",
+		CronExpression: "event:ci.deploy",
+		TaskType:       scheduler.TaskTypeReminder,
+		Enabled:        true,
+	}))
+	require.NoError(t, server.Scheduler().Start(context.Background()))
+	defer server.Scheduler().Stop()
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/events/ci.deploy", strings.NewReader(`{"ref":"main"}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "ci.deploy", resp["event"])
+	assert.Equal(t, float64(1), resp["triggered"])
+	assert.NotEmpty(t, resp["triggeredAt"])
+
+	// The tasks route shares the handler, payload included.
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/tasks/events/ci.deploy/trigger", strings.NewReader(`{"ref":"main"}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/events/unknown.event", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, float64(0), resp["triggered"])
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/events/bad!name", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/scheduler/events/ci.deploy", strings.NewReader(`[1]`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandleSkillsStats(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	reg := skills.NewRegistry()
+	reg.Upsert(skills.Skill{ID: "weather"})
+	server.skills = reg
+
+	observe := server.SkillExecutionObserver()
+	observe(skills.Execution{SkillID: "weather", Duration: 120 * time.Millisecond})
+	observe(skills.Execution{SkillID: "weather", Duration: 80 * time.Millisecond, Err: errors.New("upstream down")})
+
+	var stats store.SkillStats
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/skills/weather/stats", nil))
+		if rec.Code != http.StatusOK || json.Unmarshal(rec.Body.Bytes(), &stats) != nil {
+			return false
+		}
+		return stats.RunCount == 2
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Equal(t, int64(1), stats.FailureCount)
+	assert.Equal(t, int64(100), stats.AvgDurationMs)
+	assert.Equal(t, "upstream down", stats.LastError)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/skills/unknown/stats", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestWarmupLoadsCatalogAndPublishesEvent(t *testing.T) {
+	defer func(d, q time.Duration) { warmupDelay, warmupQuietPeriod = d, q }(warmupDelay, warmupQuietPeriod)
+	warmupDelay, warmupQuietPeriod = 0, 0
+
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	loaded := &models.Catalog{Providers: map[string]models.ProviderInfo{"openai": {Name: "OpenAI"}}}
+	server.catalogLoader = func() (*models.Catalog, error) { return loaded, nil }
+
+	events, cancel := server.bus.Subscribe(bus.EventRuntimeWarmed)
+	defer cancel()
+
+	ctx, stop := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stop()
+	server.Warmup(ctx)
+
+	select {
+	case evt := <-events:
+		payload := evt.Payload.(map[string]interface{})
+		assert.Equal(t, "loaded", payload["catalog"])
+		assert.Contains(t, payload, "tools")
+		assert.Contains(t, payload, "duration_ms")
+		assert.NotContains(t, payload, "errors")
+	case <-time.After(2 * time.Second):
+		t.Fatal("runtime.warmed was not published")
+	}
+	assert.Same(t, loaded, server.Catalog())
+}
+
+func TestWarmupReusesPendingCatalogLoad(t *testing.T) {
+	defer func(d, q time.Duration) { warmupDelay, warmupQuietPeriod = d, q }(warmupDelay, warmupQuietPeriod)
+	warmupDelay, warmupQuietPeriod = 0, 0
+
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	server.catalogLoader = func() (*models.Catalog, error) {
+		t.Error("warm-up should not load the catalog while the startup load runs")
+		return nil, errors.New("unexpected")
+	}
+	var hooked atomic.Pointer[models.Catalog]
+	server.OnCatalog(func(c *models.Catalog) { hooked.Store(c) })
+
+	done := make(chan struct{})
+	server.SetCatalogPending(done)
+	loaded := &models.Catalog{Providers: map[string]models.ProviderInfo{"openai": {Name: "OpenAI"}}}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.SetCatalog(loaded)
+		close(done)
+	}()
+
+	events, cancel := server.bus.Subscribe(bus.EventRuntimeWarmed)
+	defer cancel()
+	ctx, stop := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stop()
+	server.Warmup(ctx)
+
+	select {
+	case evt := <-events:
+		payload := evt.Payload.(map[string]interface{})
+		assert.Equal(t, "ready", payload["catalog"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("runtime.warmed was not published")
+	}
+	assert.Same(t, loaded, server.Catalog())
+	assert.Same(t, loaded, hooked.Load())
+}
+
+func TestWarmupStopsWhenCancelled(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	server.catalogLoader = func() (*models.Catalog, error) {
+		t.Error("catalog should not load after cancellation")
+		return nil, errors.New("unexpected")
+	}
+	events, cancel := server.bus.Subscribe(bus.EventRuntimeWarmed)
+	defer cancel()
+
+	ctx, stop := context.WithCancel(context.Background())
+	stop()
+	done := make(chan struct{})
+	go func() {
+		server.Warmup(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Warmup did not return after cancellation")
+	}
+	select {
+	case <-events:
+		t.Fatal("runtime.warmed published after cancellation")
+	default:
+	}
+}
+
+func TestAdminDebugBundle(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0", ModelProvider: "openai", TelegramToken: "123456789:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw1"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	server.SetBuildInfo("9.9.9", "2026-01-01")
+	server.debugEvents.Record(bus.NewEvent(bus.EventErrorOccurred, "", map[string]interface{}{"kind": "channel", "error": "boom"}))
+
+	req := httptest.NewRequest("GET", "/api/admin/debug/bundle", nil)
+	req.Header.Set("Authorization", "Bearer user-token")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/admin/debug/bundle", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/zip", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "pryx-debug-")
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	assert.Contains(t, files["version.json"], "9.9.9")
+	assert.Contains(t, files["events.json"], "boom")
+	assert.Contains(t, files["config.yaml"], "model_provider: openai")
+	for name, content := range files {
+		assert.NotContains(t, content, "AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw1", name)
+	}
+}
+
+func TestHandleSkillsReload(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, _ := store.New(":memory:")
+	defer st.Close()
+
+	managed := t.TempDir()
+	t.Setenv("PRYX_SKILLS_CONFIG_PATH", filepath.Join(t.TempDir(), "skills.yaml"))
+	t.Setenv("PRYX_WORKSPACE_ROOT", t.TempDir())
+	t.Setenv("PRYX_MANAGED_SKILLS_DIR", managed)
+	t.Setenv("PRYX_BUNDLED_SKILLS_DIR", t.TempDir())
+
+	server := New(cfg, st.DB, newTestKeychain(t))
+	events, cancel := server.bus.Subscribe(bus.EventTraceEvent)
+	defer cancel()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(managed, "notes"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(managed, "notes", "SKILL.md"), []byte("---\nname: notes\ndescription: take notes\n---\nbody"), 0o644))
+
+	req := httptest.NewRequest("POST", "/skills/reload", nil)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp struct {
+		Diff skills.RegistryDiff `json:"diff"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"notes"}, resp.Diff.Added)
+	_, ok := server.Skills().Get("notes")
+	assert.True(t, ok)
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case evt := <-events:
+			payload, _ := evt.Payload.(map[string]interface{})
+			if payload["kind"] == "skills.reloaded" {
+				assert.Equal(t, []string{"notes"}, payload["added"])
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected skills.reloaded event")
+		}
+	}
+}
+
+func TestServerReload(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0", ModelProvider: "openai", ModelName: "gpt-4"}
+	st, _ := store.New(":memory:")
+	defer st.Close()
+
+	managed := t.TempDir()
+	t.Setenv("PRYX_SKILLS_CONFIG_PATH", filepath.Join(t.TempDir(), "skills.yaml"))
+	t.Setenv("PRYX_WORKSPACE_ROOT", t.TempDir())
+	t.Setenv("PRYX_MANAGED_SKILLS_DIR", managed)
+	t.Setenv("PRYX_BUNDLED_SKILLS_DIR", t.TempDir())
+
+	server := New(cfg, st.DB, newTestKeychain(t))
+	refreshed := &models.Catalog{Providers: map[string]models.ProviderInfo{"anthropic": {Name: "Anthropic"}}}
+	server.catalogLoader = func() (*models.Catalog, error) { return refreshed, nil }
+	events, cancel := server.bus.Subscribe(bus.EventTraceEvent)
+	defer cancel()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(managed, "notes"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(managed, "notes", "SKILL.md"), []byte("---\nname: notes\ndescription: take notes\n---\nbody"), 0o644))
+
+	settings := agent.NewModelSettings(cfg)
+	server.SetModelSettings(settings)
+
+	next := *cfg
+	next.ModelProvider = "anthropic"
+	next.ModelName = "gpt-4o"
+	next.ModelFallbacks = []string{"gpt-4o-mini"}
+	next.ListenAddr = ":8080"
+	report := server.Reload(context.Background(), &next)
+
+	assert.Equal(t, []string{"model_name", "model_fallbacks"}, report.Applied)
+	assert.Equal(t, []string{"model_provider", "listen_addr"}, report.RestartRequired)
+	assert.Empty(t, report.Errors)
+	require.NotNil(t, report.Skills)
+	assert.Equal(t, []string{"notes"}, report.Skills.Added)
+	assert.True(t, report.CatalogRefreshed)
+	assert.Same(t, refreshed, server.Catalog())
+
+	assert.Equal(t, "openai", cfg.ModelProvider, "model_provider needs a restart")
+	assert.Equal(t, ":0", cfg.ListenAddr, "listen_addr needs a restart")
+	assert.Equal(t, agent.ModelConfig{Name: "gpt-4o", Fallbacks: []string{"gpt-4o-mini"}}, settings.Load())
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case evt := <-events:
+			payload, _ := evt.Payload.(map[string]interface{})
+			if payload["kind"] == "runtime.reloaded" {
+				assert.Equal(t, []string{"model_provider", "listen_addr"}, payload["restart_required"])
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected runtime.reloaded event")
+		}
+	}
+}
+
+func TestHandleSessionsListPagination(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+
+	for i := 0; i < 5; i++ {
+		_, err := st.CreateSession(fmt.Sprintf("session %d", i))
+		require.NoError(t, err)
+	}
+
+	type listResp struct {
+		Items    []map[string]interface{} `json:"items"`
+		Sessions []map[string]interface{} `json:"sessions"`
+		Total    int                      `json:"total"`
+		Limit    int                      `json:"limit"`
+		Offset   int                      `json:"offset"`
+		HasMore  bool                     `json:"has_more"`
+	}
+	get := func(query string) (int, listResp) {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/sessions"+query, nil))
+		var resp listResp
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := get("?limit=2&offset=1")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, resp.Items, 2)
+	assert.Equal(t, resp.Items, resp.Sessions)
+	assert.Equal(t, 5, resp.Total)
+	assert.Equal(t, 2, resp.Limit)
+	assert.Equal(t, 1, resp.Offset)
+	assert.True(t, resp.HasMore)
+
+	_, resp = get("?limit=2&offset=4")
+	assert.Len(t, resp.Items, 1)
+	assert.False(t, resp.HasMore)
+
+	_, resp = get("?offset=10")
+	assert.Empty(t, resp.Items)
+	assert.Equal(t, defaultListLimit, resp.Limit)
+	assert.False(t, resp.HasMore)
+
+	code, _ = get("?limit=0")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = get("?offset=-1")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestListEnvelopeLegacyKey(t *testing.T) {
+	items := make([]int, defaultListLimit+50)
+	for i := range items {
+		items[i] = i
+	}
+
+	// Clients that don't page keep getting every item under the legacy field
+	resp := listEnvelope("sessions", items, pageParams{Limit: defaultListLimit})
+	assert.Len(t, resp["items"], defaultListLimit)
+	assert.Len(t, resp["sessions"], len(items))
+	assert.Equal(t, true, resp["has_more"])
+
+	resp = listEnvelope("sessions", items, pageParams{Limit: 10, Offset: 5, Explicit: true})
+	assert.Equal(t, resp["items"], resp["sessions"])
+	assert.Len(t, resp["sessions"], 10)
+
+	resp = listEnvelope("sessions", []int(nil), pageParams{Limit: defaultListLimit})
+	assert.NotNil(t, resp["sessions"])
+}
+
+func TestTaskDependenciesRunOnSuccess(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	s, _ := store.New(":memory:")
+	defer s.Close()
+
+	server := New(cfg, s.DB, newTestKeychain(t))
+	fetch := &scheduler.ScheduledTask{Name: "fetch", CronExpression: "0 2 * * *", TaskType: scheduler.TaskTypeReminder, Enabled: true}
+	require.NoError(t, server.Scheduler().CreateTask(fetch))
+
+	body := fmt.Sprintf(`{"name":"summarize","cron_expression":"0 3 * * *","task_type":"reminder","enabled":true,"depends_on":[%q]}`, fetch.ID)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/tasks", strings.NewReader(body)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var summarize TaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summarize))
+	assert.Equal(t, []string{fetch.ID}, summarize.DependsOn)
+
+	rec = httptest.NewRecorder()
+	update := fmt.Sprintf(`{"depends_on":[%q]}`, summarize.ID)
+	server.router.ServeHTTP(rec, httptest.NewRequest("PUT", "/api/v1/tasks/"+fetch.ID, strings.NewReader(update)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	_, err := server.Scheduler().RunNow(fetch.ID)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		runs, err := server.Scheduler().GetTaskRuns(summarize.ID, 10)
+		return err == nil && len(runs) == 1 && runs[0].Trigger == scheduler.RunTriggerDependency &&
+			runs[0].Status == scheduler.RunStatusSuccess
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestHandleProviderOverrides(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+	defer applyProviderOverrides(nil)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("PUT", "/api/v1/providers/openai/overrides", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := put(`{"headers":{"Authorization":"Bearer stolen"}}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+
+	rec = put(`{"headers":{"OpenAI-Organization":"org-123"},"params":{"user":"pryx"}}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "org-123", server.cfg.ProviderOverrides["openai"].Headers["OpenAI-Organization"])
+
+	saved, err := config.LoadFromFile(config.DefaultPath())
+	require.NoError(t, err)
+	assert.Equal(t, "pryx", saved.ProviderOverrides["openai"].Params["user"])
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/providers/openai/overrides", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got struct {
+		Headers map[string]string `json:"headers"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, "org-123", got.Headers["OpenAI-Organization"])
+
+	rec = put(`{}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, server.cfg.ProviderOverrides, "openai")
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/providers/nope/overrides", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandleAgentCancel(t *testing.T) {
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ollama.Close()
+
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	kc := newTestKeychain(t)
+	server := New(cfg, st.DB, kc)
+
+	spawner := spawn.NewSpawner(&config.Config{ModelProvider: "ollama", OllamaEndpoint: ollama.URL}, server.bus, kc, nil)
+	server.SetSpawnTool(spawn.NewSpawnTool(spawner, server.bus))
+	events, unsubscribe := server.bus.Subscribe(bus.EventAgentCancelled)
+	defer unsubscribe()
+
+	agent, err := spawner.Spawn(context.Background(), "parent-1", "session-1", "wait", "")
+	require.NoError(t, err)
+
+	cancel := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/v1/agents/"+id+"/cancel", nil))
+		return rec
+	}
+
+	rec := cancel(agent.ID)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"status":"cancelled"`)
+
+	select {
+	case evt := <-events:
+		assert.Equal(t, "session-1", evt.SessionID)
+		payload, _ := evt.Payload.(map[string]interface{})
+		assert.Equal(t, agent.ID, payload["agent_id"])
+	case <-time.After(time.Second):
+		t.Fatal("expected agent.cancelled event")
+	}
+
+	status, err := server.spawnTool.GetAgentStatus(agent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, spawn.StatusCancelled, status["status"])
+
+	assert.Equal(t, http.StatusConflict, cancel(agent.ID).Code)
+	assert.Equal(t, http.StatusNotFound, cancel("agent-missing").Code)
+}
+
+func TestHandleSessionBudget(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+
+	do := func(method, path, body string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var resp map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	code, resp := do("POST", "/api/v1/sessions", `{"title":"automation","max_tokens":5000}`)
+	require.Equal(t, http.StatusCreated, code, resp)
+	id := resp["id"].(string)
+	assert.Equal(t, map[string]interface{}{"max_tokens": float64(5000)}, resp["budget"])
+
+	code, resp = do("PATCH", "/api/v1/sessions/"+id, `{"max_cost":2.5,"max_tokens":0}`)
+	require.Equal(t, http.StatusOK, code, resp)
+	assert.Equal(t, map[string]interface{}{"max_cost": 2.5}, resp["budget"])
+
+	code, resp = do("GET", "/api/v1/sessions/"+id, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"max_cost": 2.5}, resp["budget"])
+
+	code, _ = do("PATCH", "/api/v1/sessions/"+id, `{"max_cost":-1}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do("POST", "/api/v1/sessions", `{"title":"bad","max_tokens":-5}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do("PATCH", "/api/v1/sessions/missing", `{"max_cost":1}`)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestHandleSessionDelete(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+	activity := agent.NewSessionActivity()
+	server.SetSessionActivity(activity)
+	events, cancel := server.bus.Subscribe(bus.EventSessionDeleted)
+	defer cancel()
+
+	del := func(path string) int {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("DELETE", path, nil))
+		return rec.Code
+	}
+
+	sess, err := st.CreateSession("to delete")
+	require.NoError(t, err)
+	_, err = st.AddMessage(sess.ID, store.RoleUser, "hello")
+	require.NoError(t, err)
+
+	end := activity.Begin(sess.ID)
+	assert.Equal(t, http.StatusConflict, del("/api/v1/sessions/"+sess.ID))
+	_, err = st.GetSession(sess.ID)
+	require.NoError(t, err, "an active session must not be deleted without force")
+
+	assert.Equal(t, http.StatusNoContent, del("/api/v1/sessions/"+sess.ID+"?force=true"))
+	end()
+	_, err = st.GetSession(sess.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	count, err := st.GetMessageCount(sess.ID)
+	require.NoError(t, err)
+	assert.Zero(t, count, "messages should be deleted with the session")
+
+	select {
+	case evt := <-events:
+		assert.Equal(t, sess.ID, evt.SessionID)
+		assert.Equal(t, true, evt.Payload.(map[string]interface{})["was_active"])
+	case <-time.After(time.Second):
+		t.Fatal("expected session.deleted event")
+	}
+
+	assert.Equal(t, http.StatusNotFound, del("/api/v1/sessions/"+sess.ID))
+	assert.Equal(t, http.StatusBadRequest, del("/api/v1/sessions/bad%20id"))
+}
+
+func TestHandleMemoryUpdateDelete(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0", MemoryEnabled: true}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+	events, cancel := server.bus.Subscribe(bus.EventMemoryUpdated, bus.EventMemoryDeleted)
+	defer cancel()
+
+	do := func(method, path, body string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var resp map[string]interface{}
+		_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+	expectEvent := func(want bus.EventType, id string) {
+		t.Helper()
+		select {
+		case evt := <-events:
+			assert.Equal(t, want, evt.Event)
+			assert.Equal(t, id, evt.Payload.(map[string]interface{})["id"])
+		case <-time.After(time.Second):
+			t.Fatalf("expected %s event", want)
+		}
+	}
+
+	code, resp := do("POST", "/api/v1/memory", `{"type":"longterm","content":"Deploys on Fridays"}`)
+	require.Equal(t, http.StatusCreated, code, resp)
+	id := resp["id"].(string)
+
+	code, resp = do("PATCH", "/api/v1/memory/"+id, `{"content":"Never deploys on Fridays"}`)
+	require.Equal(t, http.StatusOK, code, resp)
+	assert.Equal(t, "Never deploys on Fridays", resp["content"])
+	expectEvent(bus.EventMemoryUpdated, id)
+
+	code, _ = do("PATCH", "/api/v1/memory/"+id, `{"content":""}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = do("PATCH", "/api/v1/memory/missing", `{"content":"x"}`)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = do("DELETE", "/api/v1/memory/"+id, "")
+	require.Equal(t, http.StatusNoContent, code)
+	expectEvent(bus.EventMemoryDeleted, id)
+
+	code, resp = do("GET", "/api/v1/memory", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(0), resp["count"])
+	code, _ = do("DELETE", "/api/v1/memory/"+id, "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestHandleProvidersList_DocsAndEnv(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+	server.SetCatalog(&models.Catalog{Providers: map[string]models.ProviderInfo{
+		"openai": {
+			Name: "OpenAI",
+			Env:  []string{"OPENAI_API_KEY"},
+			Doc:  "https://platform.openai.com/docs/models",
+		},
+		"lmstudio": {Name: "LM Studio", API: "http://127.0.0.1:1234/v1"},
+	}})
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/providers", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Providers []map[string]interface{} `json:"providers"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Providers, 2)
+
+	lmstudio, openai := resp.Providers[0], resp.Providers[1]
+	assert.Equal(t, "lmstudio", lmstudio["id"])
+	assert.Equal(t, false, lmstudio["requires_api_key"])
+	assert.Equal(t, []interface{}{}, lmstudio["env_vars"])
+	assert.Equal(t, "http://127.0.0.1:1234/v1", lmstudio["api_base"])
+	assert.NotContains(t, lmstudio, "doc_url")
+
+	assert.Equal(t, "openai", openai["id"])
+	assert.Equal(t, true, openai["requires_api_key"])
+	assert.Equal(t, []interface{}{"OPENAI_API_KEY"}, openai["env_vars"])
+	assert.Equal(t, "https://platform.openai.com/docs/models", openai["doc_url"])
+	assert.NotContains(t, openai, "api_base")
+}
+
+func TestHandleSessionStats(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+
+	sess, err := st.CreateSession("stats")
+	require.NoError(t, err)
+	_, err = st.AddMessage(sess.ID, store.RoleUser, "hello")
+	require.NoError(t, err)
+	require.NoError(t, server.AuditRepo().Create(&audit.AuditEntry{
+		SessionID: sess.ID,
+		Action:    audit.ActionMessageSend,
+		Cost:      &audit.CostInfo{InputTokens: 40, OutputTokens: 10, TotalTokens: 50, TotalCost: 0.02, Model: "gpt-4o"},
+		Success:   true,
+	}))
+	server.Bus().Publish(bus.NewEvent(bus.EventToolComplete, sess.ID, map[string]interface{}{
+		"tool":   "fs/read",
+		"result": map[string]interface{}{},
+	}))
+
+	var stats store.SessionStats
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/sessions/"+sess.ID+"/stats", nil))
+		return rec.Code == http.StatusOK && json.Unmarshal(rec.Body.Bytes(), &stats) == nil && stats.ToolCalls == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), stats.MessageCount)
+	assert.Equal(t, int64(40), stats.InputTokens)
+	assert.Equal(t, int64(10), stats.OutputTokens)
+	assert.InDelta(t, 0.02, stats.TotalCost, 1e-9)
+	assert.Equal(t, []string{"gpt-4o"}, stats.Models)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/sessions/missing/stats", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestRecordTranscript(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+
+	sess, err := st.CreateSession("transcript")
+	require.NoError(t, err)
+
+	publish := func(evt bus.EventType, payload map[string]interface{}) {
+		server.Bus().Publish(bus.NewEvent(evt, sess.ID, payload))
+	}
+	publish(bus.EventChatRequest, map[string]interface{}{"content": "which model are you?"})
+	publish(bus.EventSessionMessage, map[string]interface{}{"content": "I am ", "done": false})
+	publish(bus.EventSessionMessage, map[string]interface{}{"role": "subagent", "content": "ignored"})
+	publish(bus.EventSessionMessage, map[string]interface{}{"content": "GPT-4o.", "done": true, "model": "gpt-4o", "provider": "openai"})
+	// Unknown sessions are not recorded
+	server.Bus().Publish(bus.NewEvent(bus.EventChatRequest, "missing", map[string]interface{}{"content": "hi"}))
+
+	var messages []*store.Message
+	require.Eventually(t, func() bool {
+		messages, err = st.GetMessages(sess.ID)
+		return err == nil && len(messages) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, store.RoleUser, messages[0].Role)
+	assert.Equal(t, "which model are you?", messages[0].Content)
+	assert.Empty(t, messages[0].Model)
+	assert.Equal(t, store.RoleAssistant, messages[1].Role)
+	assert.Equal(t, "I am GPT-4o.", messages[1].Content)
+	assert.Equal(t, "gpt-4o", messages[1].Model)
+	assert.Equal(t, "openai", messages[1].Provider)
+
+	stats, err := st.GetSessionStats(sess.ID)
+	require.NoError(t, err)
+	require.Len(t, stats.ModelUsage, 1)
+	assert.Equal(t, store.ModelUsage{Model: "gpt-4o", Provider: "openai", Messages: 1}, stats.ModelUsage[0])
+}
+
+func TestRecordTranscript_Injections(t *testing.T) {
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(&config.Config{ListenAddr: ":0"}, st.DB, newTestKeychain(t))
+
+	sess, err := st.CreateSession("injected")
+	require.NoError(t, err)
+
+	publish := func(evt bus.EventType, payload map[string]interface{}) {
+		server.Bus().Publish(bus.NewEvent(evt, sess.ID, payload))
+	}
+	publish(bus.EventChatRequest, map[string]interface{}{"content": "write a script"})
+	publish(bus.EventSessionMessage, map[string]interface{}{"content": "writing it in Go", "done": false})
+	publish(bus.EventAgentInjected, map[string]interface{}{"state": "queued", "chars": 23, "pending": 1})
+	publish(bus.EventAgentInjected, map[string]interface{}{"state": "applied", "count": 1, "messages": []string{"also, use Python not Go"}, "model": "gpt-4o", "provider": "openai"})
+	publish(bus.EventSessionMessage, map[string]interface{}{"content": "\n\n", "done": false})
+	publish(bus.EventSessionMessage, map[string]interface{}{"content": "Switching to Python.", "done": true, "model": "gpt-4o", "provider": "openai"})
+
+	var messages []*store.Message
+	require.Eventually(t, func() bool {
+		messages, err = st.GetMessages(sess.ID)
+		return err == nil && len(messages) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "write a script", messages[0].Content)
+	assert.Equal(t, store.RoleAssistant, messages[1].Role)
+	assert.Equal(t, "writing it in Go", messages[1].Content)
+	assert.Equal(t, "gpt-4o", messages[1].Model)
+	assert.Equal(t, store.RoleUser, messages[2].Role)
+	assert.Equal(t, "also, use Python not Go", messages[2].Content)
+	assert.Equal(t, store.RoleAssistant, messages[3].Role)
+	assert.Equal(t, "Switching to Python.", messages[3].Content)
+}
+
+func TestHandleAdminUsers(t *testing.T) {
+	cfg := &config.Config{ListenAddr: ":0"}
+	st, err := store.New(":memory:")
+	require.NoError(t, err)
+	defer st.Close()
+	server := New(cfg, st.DB, newTestKeychain(t))
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Now().UTC().Add(-time.Hour)
+	for _, u := range []struct {
+		id, email string
+		lastSeen  interface{}
+		cost      float64
+	}{
+		{"u3", "a_b@example.com", recent, 1},
+		{"u1", "axb@example.com", nil, 5},
+		{"u2", "100%@example.com", recent, 1},
+		{"u4", `back\slash@example.com`, nil, 1},
+	} {
+		_, err := st.DB.Exec(`INSERT INTO users (id, email, created_at, last_seen) VALUES (?, ?, ?, ?)`, u.id, u.email, created, u.lastSeen)
+		require.NoError(t, err)
+		_, err = st.DB.Exec(`INSERT INTO audit_log (id, timestamp, user_id, action, payload) VALUES (?, ?, ?, 'message.send', ?)`,
+			"audit-"+u.id, created, u.id, fmt.Sprintf(`{"cost":%v}`, u.cost))
+		require.NoError(t, err)
+	}
+
+	type usersPage struct {
+		Users   []UserInfo `json:"users"`
+		Total   int        `json:"total"`
+		Limit   int        `json:"limit"`
+		Offset  int        `json:"offset"`
+		HasMore bool       `json:"has_more"`
+	}
+	list := func(query string) usersPage {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/admin/users?"+query, nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var page usersPage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		return page
+	}
+	ids := func(page usersPage) []string {
+		var out []string
+		for _, u := range page.Users {
+			out = append(out, u.ID)
+		}
+		return out
+	}
+
+	page := list("")
+	assert.Equal(t, 4, page.Total)
+	assert.Equal(t, adminUsersDefaultLimit, page.Limit)
+	// Equal created_at values fall back to id order.
+	assert.Equal(t, []string{"u1", "u2", "u3", "u4"}, ids(page))
+
+	// Ties on cost keep a stable order across pages.
+	first := list("sort=cost_desc&limit=2")
+	second := list("sort=cost_desc&limit=2&offset=2")
+	assert.Equal(t, []string{"u1", "u2"}, ids(first))
+	assert.True(t, first.HasMore)
+	assert.Equal(t, []string{"u3", "u4"}, ids(second))
+	assert.False(t, second.HasMore)
+
+	assert.Equal(t, []string{"u3"}, ids(list("search="+url.QueryEscape("a_b"))))
+	assert.Equal(t, []string{"u2"}, ids(list("search="+url.QueryEscape("100%"))))
+	assert.Equal(t, []string{"u4"}, ids(list("search="+url.QueryEscape(`k\s`))))
+	assert.Equal(t, []string{"u2"}, ids(list("search="+url.QueryEscape("%@"))))
+
+	active := list("status=active")
+	assert.Equal(t, 2, active.Total)
+	assert.Equal(t, []string{"u2", "u3"}, ids(active))
+	assert.Equal(t, "active", active.Users[0].Status)
+	assert.Equal(t, []string{"u1", "u4"}, ids(list("status=inactive")))
+
+	for _, query := range []string{"status=suspended", "sort=email", "limit=0"} {
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/admin/users?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
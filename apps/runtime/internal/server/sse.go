@@ -0,0 +1,244 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/validation"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// sseReplayBuffer is how many recent events a stream keeps for a client
+	// resuming with Last-Event-ID.
+	sseReplayBuffer = 256
+	// sseResumeWindow is how long a disconnected stream keeps buffering
+	// events for its client to resume.
+	sseResumeWindow = time.Minute
+)
+
+// sseHeartbeatInterval is how often a stream sends a comment line so proxies
+// don't close it while no events flow; tests shorten it.
+var sseHeartbeatInterval = 15 * time.Second
+
+// sseStream is one subscriber's filtered view of the bus. It outlives its
+// HTTP connection by sseResumeWindow so a reconnecting client can pick up the
+// events it missed.
+type sseStream struct {
+	id     string
+	notify chan struct{}
+
+	mu         sync.Mutex
+	buf        []bus.Event
+	attached   bool
+	detachedAt time.Time
+}
+
+// sseRegistry holds the open and recently detached SSE streams.
+type sseRegistry struct {
+	mu      sync.Mutex
+	streams map[string]*sseStream
+}
+
+func newSSERegistry() *sseRegistry {
+	return &sseRegistry{streams: make(map[string]*sseStream)}
+}
+
+// open subscribes a new attached stream to topics (all when empty), keeping
+// only the events of sessionFilter when it is set.
+func (reg *sseRegistry) open(b *bus.Bus, topics []bus.EventType, sessionFilter string) *sseStream {
+	st := &sseStream{id: uuid.NewString(), notify: make(chan struct{}, 1), attached: true}
+
+	reg.mu.Lock()
+	reg.streams[st.id] = st
+	reg.mu.Unlock()
+
+	cancel := b.Handle(func(evt bus.Event) {
+		if sessionFilter != "" && evt.SessionID != sessionFilter {
+			return
+		}
+		st.add(evt)
+	}, bus.SubscribeOptions{Topics: topics})
+
+	go func() {
+		defer cancel()
+		ticker := time.NewTicker(sseResumeWindow / 4)
+		defer ticker.Stop()
+		for range ticker.C {
+			if reg.expire(st) {
+				return
+			}
+		}
+	}()
+	return st
+}
+
+// resume reattaches the detached stream id, or returns nil if it expired or
+// another connection holds it.
+func (reg *sseRegistry) resume(id string) *sseStream {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	st := reg.streams[id]
+	if st == nil {
+		return nil
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.attached {
+		return nil
+	}
+	st.attached = true
+	return st
+}
+
+// expire drops st once it has been detached for sseResumeWindow.
+func (reg *sseRegistry) expire(st *sseStream) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.attached || time.Since(st.detachedAt) < sseResumeWindow {
+		return false
+	}
+	delete(reg.streams, st.id)
+	return true
+}
+
+func (st *sseStream) add(evt bus.Event) {
+	st.mu.Lock()
+	st.buf = append(st.buf, evt)
+	if len(st.buf) > sseReplayBuffer {
+		st.buf = st.buf[len(st.buf)-sseReplayBuffer:]
+	}
+	st.mu.Unlock()
+	select {
+	case st.notify <- struct{}{}:
+	default:
+	}
+}
+
+// since returns the buffered events newer than version.
+func (st *sseStream) since(version int) []bus.Event {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var out []bus.Event
+	for _, evt := range st.buf {
+		if evt.Version > version {
+			out = append(out, evt)
+		}
+	}
+	return out
+}
+
+func (st *sseStream) detach() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.attached = false
+	st.detachedAt = time.Now()
+}
+
+// parseSSEEventID splits an event ID of the form "<stream>:<version>".
+func parseSSEEventID(id string) (string, int, bool) {
+	i := strings.LastIndex(id, ":")
+	if i <= 0 {
+		return "", 0, false
+	}
+	version, err := strconv.Atoi(id[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return id[:i], version, true
+}
+
+// handleEvents streams bus events as Server-Sent Events, for clients behind
+// proxies that break WebSocket upgrades. It takes the same event= and
+// session_id= filters as /ws and each data line carries the JSON a WebSocket
+// frame would. Event IDs are "<stream>:<version>"; a client reconnecting with
+// Last-Event-ID within sseResumeWindow gets the events it missed, up to
+// sseReplayBuffer of them, with the filters of its original request.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sessionFilter := strings.TrimSpace(query.Get("session_id"))
+	if err := validation.NewValidator().ValidateSessionID(sessionFilter); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	var topics []bus.EventType
+	for _, ev := range query["event"] {
+		if ev = strings.TrimSpace(ev); ev != "" {
+			topics = append(topics, bus.EventType(ev))
+		}
+	}
+
+	var st *sseStream
+	lastVersion := 0
+	if id, version, ok := parseSSEEventID(r.Header.Get("Last-Event-ID")); ok {
+		if st = s.sseStreams.resume(id); st != nil {
+			lastVersion = version
+		}
+	}
+	resumed := st != nil
+	if st == nil {
+		st = s.sseStreams.open(s.bus, topics, sessionFilter)
+	}
+	defer st.detach()
+
+	rc := http.NewResponseController(w)
+	// The stream is long-lived; lift any server write deadline
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, sessionFilter, map[string]interface{}{
+		"kind":        "sse.connected",
+		"remote_addr": r.RemoteAddr,
+		"resumed":     resumed,
+	}))
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		for _, evt := range st.since(lastVersion) {
+			data, err := json.Marshal(evt)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s:%d\ndata: %s\n\n", st.id, evt.Version, data); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			lastVersion = evt.Version
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-st.notify:
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// readSSEFrame reads the next event or comment from an SSE stream as a map of
+// field to value; comments are returned under ":".
+func readSSEFrame(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+	frame := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(frame) > 0 {
+				return frame
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			frame[":"] = strings.TrimSpace(line[1:])
+			continue
+		}
+		field, value, _ := strings.Cut(line, ": ")
+		frame[field] = value
+	}
+}
+
+func openSSE(t *testing.T, ctx context.Context, url, lastEventID string) (*http.Response, *bufio.Reader) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	return resp, bufio.NewReader(resp.Body)
+}
+
+func TestHandleEvents(t *testing.T) {
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	s := New(&config.Config{ListenAddr: "127.0.0.1:0"}, st.DB, newTestKeychain(t))
+	traces, cancelSub := s.bus.Subscribe(bus.EventTraceEvent)
+	defer cancelSub()
+	srv := httptest.NewServer(s.router)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/events?session_id=nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad session filter, got %d", resp.StatusCode)
+	}
+
+	session := uuid.NewString()
+	url := srv.URL + "/events?event=session.message&session_id=" + session
+	waitConnected := func() {
+		t.Helper()
+		for {
+			select {
+			case evt := <-traces:
+				if evt.Payload.(map[string]interface{})["kind"] == "sse.connected" {
+					return
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("stream did not connect")
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, reader := openSSE(t, ctx, url, "")
+	waitConnected()
+
+	s.bus.Publish(bus.NewEvent(bus.EventSessionMessage, uuid.NewString(), map[string]interface{}{"content": "other session"}))
+	s.bus.Publish(bus.NewEvent(bus.EventChatRequest, session, map[string]interface{}{"content": "other event"}))
+	s.bus.Publish(bus.NewEvent(bus.EventSessionMessage, session, map[string]interface{}{"content": "first"}))
+
+	frame := readSSEFrame(t, reader)
+	var evt bus.Event
+	if err := json.Unmarshal([]byte(frame["data"]), &evt); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if evt.SessionID != session || evt.Payload.(map[string]interface{})["content"] != "first" {
+		t.Fatalf("expected only the filtered event, got %+v", evt)
+	}
+	lastID := frame["id"]
+	if !strings.HasSuffix(lastID, ":"+strconv.Itoa(evt.Version)) {
+		t.Fatalf("unexpected event id %q for version %d", lastID, evt.Version)
+	}
+
+	// Events published while the client is away are replayed on resume
+	cancel()
+	resp.Body.Close()
+	time.Sleep(100 * time.Millisecond)
+	s.bus.Publish(bus.NewEvent(bus.EventSessionMessage, session, map[string]interface{}{"content": "missed"}))
+
+	oldHeartbeat := sseHeartbeatInterval
+	sseHeartbeatInterval = 50 * time.Millisecond
+	defer func() { sseHeartbeatInterval = oldHeartbeat }()
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	resp, reader = openSSE(t, ctx, srv.URL+"/events", lastID)
+	defer resp.Body.Close()
+
+	frame = readSSEFrame(t, reader)
+	if err := json.Unmarshal([]byte(frame["data"]), &evt); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if evt.Payload.(map[string]interface{})["content"] != "missed" {
+		t.Fatalf("expected the missed event on resume, got %+v", evt)
+	}
+	if frame = readSSEFrame(t, reader); frame[":"] != "heartbeat" {
+		t.Fatalf("expected a heartbeat comment, got %v", frame)
+	}
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"pryx-core/internal/agent/summary"
+	"pryx-core/internal/llm"
+	"pryx-core/internal/llm/factory"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newLLMProvider creates a provider for the currently configured model provider.
+func (s *Server) newLLMProvider() (llm.Provider, error) {
+	s.cfgMu.RLock()
+	providerID := strings.ToLower(strings.TrimSpace(s.cfg.ModelProvider))
+	baseURL := s.cfg.OllamaEndpoint
+	s.cfgMu.RUnlock()
+
+	var apiKey string
+	switch providerID {
+	case "openai", "anthropic", "openrouter", "together", "groq", "xai", "mistral", "cohere", "google", "glm":
+		if s.keychain != nil {
+			if key, err := s.keychain.GetProviderKey(providerID); err == nil {
+				apiKey = key
+			}
+		}
+		baseURL = ""
+	case "ollama":
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", providerID)
+	}
+	return factory.NewProvider(providerID, apiKey, baseURL)
+}
+
+// summaryModel returns the configured summary model, falling back to a cheap model for the provider.
+func (s *Server) summaryModel() string {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	if m := strings.TrimSpace(s.cfg.SummaryModel); m != "" {
+		return m
+	}
+	return summary.DefaultModel(s.cfg.ModelProvider, s.cfg.ModelName)
+}
+
+// handleSessionSummarize asks the model for a concise summary of a session,
+// optionally saving it as the session description and to long-term memory.
+func (s *Server) handleSessionSummarize(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "session id is required"})
+		return
+	}
+	if s.summarizer == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "summarizer not available"})
+		return
+	}
+
+	var req struct {
+		Model    string `json:"model"`
+		MaxWords int    `json:"max_words"`
+		Persist  bool   `json:"persist"`
+		Remember bool   `json:"remember"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid request body"})
+			return
+		}
+	}
+	if req.MaxWords < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "max_words must be positive"})
+		return
+	}
+	result, err := s.summarizer.Summarize(r.Context(), summary.Request{
+		SessionID: sessionID,
+		Model:     req.Model,
+		MaxWords:  req.MaxWords,
+		Persist:   req.Persist,
+		Remember:  req.Remember,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, summary.ErrSessionNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, summary.ErrEmptySession):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case llm.IsTimeout(err):
+			w.WriteHeader(http.StatusGatewayTimeout)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(result)
+}
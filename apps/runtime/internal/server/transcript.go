@@ -0,0 +1,107 @@
+package server
+
+import (
+	"log"
+	"strings"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/store"
+)
+
+// transcriptBuffer is how many streamed chunks the recorder may fall behind;
+// a dropped chunk would leave a hole in the stored response.
+const transcriptBuffer = 1000
+
+// transcriptRecorder returns a bus handler that adds chat turns of known
+// sessions to their transcript. User messages are taken from chat requests;
+// the agent's streamed response is collected until its final session.message
+// event and stored with the model and provider that produced it. Messages
+// injected into a turn are stored as user messages after the part of the
+// response that preceded them. The handler keeps per-session state and must
+// see events in order.
+func (s *Server) transcriptRecorder() func(bus.Event) {
+	// pending holds the response streamed so far per session
+	pending := make(map[string]*strings.Builder)
+	// resumed marks sessions whose response continues after injected messages
+	resumed := make(map[string]bool)
+	return func(evt bus.Event) {
+		if evt.SessionID == "" {
+			return
+		}
+		payload, _ := evt.Payload.(map[string]interface{})
+		content, _ := payload["content"].(string)
+
+		switch evt.Event {
+		case bus.EventChatRequest:
+			// A response cut short by an error never completes
+			delete(pending, evt.SessionID)
+			delete(resumed, evt.SessionID)
+			if strings.TrimSpace(content) == "" || !s.knownSession(evt.SessionID) {
+				return
+			}
+			if _, err := s.store.AddMessage(evt.SessionID, store.RoleUser, content); err != nil {
+				log.Printf("Failed to record user message for session %s: %v", evt.SessionID, err)
+			}
+		case bus.EventAgentInjected:
+			injected, _ := payload["messages"].([]string)
+			if payload["state"] != "applied" || len(injected) == 0 || !s.knownSession(evt.SessionID) {
+				return
+			}
+			if b, ok := pending[evt.SessionID]; ok && strings.TrimSpace(b.String()) != "" {
+				model, _ := payload["model"].(string)
+				provider, _ := payload["provider"].(string)
+				if _, err := s.store.AddAssistantMessage(evt.SessionID, b.String(), model, provider); err != nil {
+					log.Printf("Failed to record assistant message for session %s: %v", evt.SessionID, err)
+				}
+			}
+			delete(pending, evt.SessionID)
+			resumed[evt.SessionID] = true
+			for _, msg := range injected {
+				if _, err := s.store.AddMessage(evt.SessionID, store.RoleUser, msg); err != nil {
+					log.Printf("Failed to record injected message for session %s: %v", evt.SessionID, err)
+				}
+			}
+		case bus.EventSessionMessage:
+			// Sub-agent reports carry a role and are not part of the response
+			if _, ok := payload["role"]; ok {
+				return
+			}
+			b, ok := pending[evt.SessionID]
+			if !ok {
+				b = &strings.Builder{}
+				pending[evt.SessionID] = b
+			}
+			if resumed[evt.SessionID] {
+				// Drop the separator between the steps around the injection
+				content = strings.TrimLeft(content, "\n")
+				if content != "" {
+					delete(resumed, evt.SessionID)
+				}
+			}
+			b.WriteString(content)
+			if done, _ := payload["done"].(bool); !done {
+				return
+			}
+			delete(pending, evt.SessionID)
+			delete(resumed, evt.SessionID)
+
+			response := b.String()
+			if strings.TrimSpace(response) == "" || !s.knownSession(evt.SessionID) {
+				return
+			}
+			model, _ := payload["model"].(string)
+			provider, _ := payload["provider"].(string)
+			if _, err := s.store.AddAssistantMessage(evt.SessionID, response, model, provider); err != nil {
+				log.Printf("Failed to record assistant message for session %s: %v", evt.SessionID, err)
+			}
+		}
+	}
+}
+
+func (s *Server) knownSession(sessionID string) bool {
+	if s.store == nil {
+		return false
+	}
+	_, err := s.store.GetSession(sessionID)
+	return err == nil
+}
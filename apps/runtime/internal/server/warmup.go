@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/models"
+)
+
+var (
+	// warmupDelay lets startup and the first requests settle before warming caches.
+	warmupDelay = 2 * time.Second
+	// warmupQuietPeriod is how long the server must have gone without a request
+	// before a warm-up step runs; warmupMaxDeferral bounds the wait.
+	warmupQuietPeriod = 500 * time.Millisecond
+	warmupMaxDeferral = 10 * time.Second
+	// warmupStepTimeout bounds each warm-up step.
+	warmupStepTimeout = 15 * time.Second
+)
+
+// Warmup loads what the first chat would otherwise pay for: the MCP tool cache
+// and the model catalog. It waits for startup to settle, yields to incoming
+// requests, and publishes runtime.warmed when done. Cancelling ctx stops it
+// without publishing. Failures are reported in the event rather than returned.
+func (s *Server) Warmup(ctx context.Context) {
+	start := time.Now()
+	if !sleepCtx(ctx, warmupDelay) {
+		return
+	}
+
+	payload := map[string]interface{}{}
+	var errs []string
+
+	// MCP servers connect in the background from New; warm their tool lists once connected.
+	select {
+	case <-s.mcpReady:
+	case <-ctx.Done():
+		return
+	}
+	if !s.waitForQuiet(ctx) {
+		return
+	}
+	stepCtx, cancel := context.WithTimeout(ctx, warmupStepTimeout)
+	perServer, err := s.mcp.ListTools(stepCtx, false)
+	cancel()
+	if ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		errs = append(errs, "mcp: "+err.Error())
+	}
+	tools := 0
+	for _, t := range perServer {
+		tools += len(t)
+	}
+	payload["mcp_servers"] = len(perServer)
+	payload["tools"] = tools
+
+	if !s.waitForQuiet(ctx) {
+		return
+	}
+	catalogState := "ready"
+	switch {
+	case s.catalogPending != nil:
+		// The startup load is already running; wait for its result rather
+		// than fetching the catalog a second time.
+		stepCtx, cancel := context.WithTimeout(ctx, warmupStepTimeout)
+		select {
+		case <-s.catalogPending:
+		case <-stepCtx.Done():
+		}
+		cancel()
+		switch {
+		case ctx.Err() != nil:
+			return
+		case s.Catalog() == nil:
+			catalogState = "unavailable"
+			errs = append(errs, "catalog: startup load did not produce a catalog")
+		}
+	case s.Catalog() == nil:
+		// Load prefers a fresh cache, then the models API, then a stale cache.
+		catalog, err := s.loadCatalog()
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			catalogState = "unavailable"
+			errs = append(errs, "catalog: "+err.Error())
+		case s.Catalog() == nil:
+			s.SetCatalog(catalog)
+			catalogState = "loaded"
+		}
+	}
+	payload["catalog"] = catalogState
+
+	payload["duration_ms"] = time.Since(start).Milliseconds()
+	if len(errs) > 0 {
+		payload["errors"] = errs
+	}
+	log.Printf("Runtime warmed in %s (tools: %d, catalog: %s)", time.Since(start).Round(time.Millisecond), tools, catalogState)
+	s.bus.Publish(bus.NewEvent(bus.EventRuntimeWarmed, "", payload))
+}
+
+// loadCatalog loads the model catalog with its cache fallback.
+func (s *Server) loadCatalog() (*models.Catalog, error) {
+	if s.catalogLoader != nil {
+		return s.catalogLoader()
+	}
+	return models.NewService().Load()
+}
+
+// waitForQuiet blocks until no request has arrived for warmupQuietPeriod, or
+// warmupMaxDeferral has passed. It returns false if ctx is cancelled.
+func (s *Server) waitForQuiet(ctx context.Context) bool {
+	deadline := time.Now().Add(warmupMaxDeferral)
+	for {
+		since := time.Since(time.Unix(0, s.lastRequest.Load()))
+		if since >= warmupQuietPeriod || time.Now().After(deadline) {
+			return ctx.Err() == nil
+		}
+		if !sleepCtx(ctx, warmupQuietPeriod-since) {
+			return false
+		}
+	}
+}
+
+// sleepCtx sleeps for d and reports whether ctx is still live.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -1,14 +1,19 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/store"
 	"pryx-core/internal/validation"
 
 	"golang.org/x/time/rate"
@@ -20,6 +25,7 @@ const (
 	defaultMaxMessageSize     = 10 * 1024 * 1024 // 10MB
 	defaultMaxConnections     = 1000
 	defaultRateLimitPerMinute = 60
+	defaultPingInterval       = 30 * time.Second
 )
 
 // wsConnectionPool tracks active WebSocket connections
@@ -65,6 +71,28 @@ func init() {
 	go cleanupOldRateLimiters()
 }
 
+// websocketOriginPatterns converts allowed origins into host patterns for the
+// WebSocket library's origin check: a full origin such as
+// "https://app.example.com" becomes its host, while a host pattern such as
+// "*.example.com" is kept as is.
+func websocketOriginPatterns(allowed []string) []string {
+	patterns := make([]string, 0, len(allowed))
+	for _, origin := range allowed {
+		origin = strings.TrimSpace(origin)
+		if strings.Contains(origin, "://") {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host == "" {
+				continue
+			}
+			origin = u.Host
+		}
+		if origin != "" {
+			patterns = append(patterns, origin)
+		}
+	}
+	return patterns
+}
+
 func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 	cfg := s.cfg
 
@@ -112,15 +140,11 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 		allowedOrigins = cfg.AllowedOrigins
 	}
 
-	// Accept WebSocket with origin validation
+	// Accept WebSocket with origin validation: the library accepts same-host
+	// origins and those matching OriginPatterns, and rejects the rest with 403
 	acceptOpts := &websocket.AcceptOptions{
-		InsecureSkipVerify: false,
-	}
-
-	// Only set OriginPatterns if we have specific origins configured
-	// Otherwise, use default behavior which validates against the Host header
-	if len(allowedOrigins) > 0 {
-		acceptOpts.OriginPatterns = allowedOrigins
+		InsecureSkipVerify: cfg.SkipWebSocketOriginVerify(),
+		OriginPatterns:     websocketOriginPatterns(allowedOrigins),
 	}
 
 	c, err := websocket.Accept(w, r, acceptOpts)
@@ -155,15 +179,6 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 		topics = append(topics, bus.EventType(ev))
 	}
 
-	var events <-chan bus.Event
-	var cancel func()
-	if len(topics) == 0 {
-		events, cancel = s.bus.Subscribe()
-	} else {
-		events, cancel = s.bus.Subscribe(topics...)
-	}
-	defer cancel()
-
 	ctx := r.Context()
 	var writeMu sync.Mutex
 	sendJSON := func(v any) error {
@@ -182,11 +197,17 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 		"surface":     surface,
 	}))
 
-	// Use buffered channel for event distribution
-	eventCh := make(chan bus.Event, WebSocketBufferSize)
+	pingInterval := cfg.WebSocketPingInterval
+	if pingInterval == 0 {
+		pingInterval = defaultPingInterval
+	}
+	// heartbeatErr is set when the connection is closed for missing a pong
+	var heartbeatErr atomic.Value
 
-	// Event pump goroutine with panic recovery
-	go func() {
+	// Use buffered channel for event distribution. The bus hands events over
+	// in publish order; the writer sends them while the next ones queue here.
+	eventCh := make(chan bus.Event, WebSocketBufferSize)
+	cancel := s.bus.Handle(func(evt bus.Event) {
 		defer func() {
 			if r := recover(); r != nil {
 				s.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionFilter, map[string]interface{}{
@@ -194,28 +215,17 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 					"error": r,
 				}))
 			}
-			close(eventCh)
 		}()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case evt, ok := <-events:
-				if !ok {
-					return
-				}
-				if sessionFilter != "" && evt.SessionID != sessionFilter {
-					continue
-				}
-				select {
-				case eventCh <- evt:
-				default:
-					// Channel full, drop event
-				}
-			}
+		if sessionFilter != "" && evt.SessionID != sessionFilter {
+			return
 		}
-	}()
+		select {
+		case eventCh <- evt:
+		default:
+			// Channel full, drop event
+		}
+	}, bus.SubscribeOptions{Topics: topics})
+	defer cancel()
 
 	// Writer goroutine with panic recovery
 	go func() {
@@ -228,13 +238,44 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 			}
 		}()
 
-		for evt := range eventCh {
-			if err := sendJSON(evt); err != nil {
+		// A nil channel never fires, which leaves heartbeats off
+		var pings <-chan time.Time
+		if pingInterval > 0 {
+			ticker := time.NewTicker(pingInterval)
+			defer ticker.Stop()
+			pings = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
 				return
+			case evt := <-eventCh:
+				if err := sendJSON(evt); err != nil {
+					return
+				}
+			case <-pings:
+				// Ping waits for the pong, which the read loop below receives
+				pingCtx, cancelPing := context.WithTimeout(ctx, pingInterval)
+				err := c.Ping(pingCtx)
+				cancelPing()
+				if err != nil && ctx.Err() == nil {
+					heartbeatErr.Store(err.Error())
+					// A half-open connection would stall the close handshake
+					_ = c.CloseNow()
+					return
+				}
 			}
 		}
 	}()
 
+	// Inbound messages share a per-connection bucket of rateLimitPerMinute,
+	// which may all be spent at once
+	msgLimiter := rate.NewLimiter(rps, rateLimitPerMinute)
+
+	// implicitSession is the session created for chat.send requests without one.
+	var implicitSession string
+
 	// Main read loop with panic recovery
 	defer func() {
 		if r := recover(); r != nil {
@@ -250,10 +291,27 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			break
 		}
+		s.idle.touch()
 		if msgType != websocket.MessageText {
 			continue
 		}
 
+		if !msgLimiter.Allow() {
+			next := msgLimiter.Reserve()
+			retryAfter := next.Delay()
+			next.Cancel()
+			_ = sendJSON(map[string]any{
+				"event": "error",
+				"payload": map[string]any{
+					"kind":           "ws.rate_limited",
+					"error":          "too many messages",
+					"limit_per_min":  rateLimitPerMinute,
+					"retry_after_ms": retryAfter.Milliseconds(),
+				},
+			})
+			continue
+		}
+
 		// Check message size
 		if int64(len(data)) > maxMessageSize {
 			s.bus.Publish(bus.NewEvent(bus.EventErrorOccurred, sessionFilter, map[string]interface{}{
@@ -369,13 +427,19 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 			}
 			mresp := make([]map[string]any, 0, len(msgs))
 			for _, m := range msgs {
-				mresp = append(mresp, map[string]any{
+				entry := map[string]any{
 					"id":        m.ID,
 					"sessionId": m.SessionID,
 					"role":      m.Role,
 					"content":   m.Content,
+					"pinned":    m.Pinned,
 					"createdAt": m.CreatedAt.UTC().Format(time.RFC3339),
-				})
+				}
+				if m.Model != "" {
+					entry["model"] = m.Model
+					entry["provider"] = m.Provider
+				}
+				mresp = append(mresp, entry)
 			}
 			_ = sendJSON(map[string]any{
 				"event":      "session.resume",
@@ -396,25 +460,163 @@ func (s *Server) handleWS(w http.ResponseWriter, r *http.Request) {
 				_ = s.mcp.ResolveApproval(approvalID, in.Approved)
 			}
 		case "chat.send":
-			if in.Payload != nil && in.Payload["content"] != nil {
-				if content, ok := in.Payload["content"].(string); ok {
-					if err := validator.ValidateChatContent(content); err == nil {
-						s.bus.Publish(bus.NewEvent(bus.EventChatRequest, sessionFilter, in.Payload))
-					}
+			if s.MaintenanceMode() {
+				_ = sendJSON(map[string]any{
+					"event": "error",
+					"payload": map[string]any{
+						"kind":        "chat.maintenance",
+						"error":       "runtime is in maintenance mode",
+						"retry_after": maintenanceRetryAfterSeconds,
+					},
+				})
+				continue
+			}
+			content, _ := in.Payload["content"].(string)
+			if content == "" || validator.ValidateChatContent(content) != nil {
+				continue
+			}
+			sessionID := strings.TrimSpace(in.SessionID)
+			if sessionID == "" {
+				if v, ok := in.Payload["session_id"].(string); ok {
+					sessionID = strings.TrimSpace(v)
+				}
+			}
+			if sessionID == "" {
+				sessionID = sessionFilter
+			}
+			if sessionID == "" {
+				sessionID = implicitSession
+			}
+			if err := validator.ValidateSessionID(sessionID); err != nil {
+				continue
+			}
+			if sessionID == "" {
+				sess, errPayload := s.createChatSession(content)
+				if errPayload != nil {
+					_ = sendJSON(map[string]any{"event": "error", "payload": errPayload})
+					continue
+				}
+				// Later messages on this connection that name no session reuse it.
+				implicitSession = sess.ID
+				sessionID = sess.ID
+				evt := bus.NewEvent(bus.EventSessionCreated, sess.ID, map[string]interface{}{
+					"session_id": sess.ID,
+					"title":      sess.Title,
+					"reason":     "chat.send without session_id",
+					"behavior":   config.ChatWithoutSessionCreate,
+				})
+				s.bus.Publish(evt)
+				if !subscribedTo(topics, bus.EventSessionCreated) {
+					_ = sendJSON(evt)
+				}
+			}
+			// The agent gives the model the time in the session's zone.
+			if _, ok := in.Payload["timezone"]; !ok && s.store != nil {
+				if sess, err := s.store.GetSession(sessionID); err == nil && sess.Timezone != "" {
+					in.Payload["timezone"] = sess.Timezone
 				}
 			}
+			s.bus.Publish(bus.NewEvent(bus.EventChatRequest, sessionID, in.Payload))
+		case "chat.inject":
+			// Adds a message to the turn in progress; the agent answers with
+			// agent.injected, or an error when no turn is running.
+			content, _ := in.Payload["content"].(string)
+			if content == "" || validator.ValidateChatContent(content) != nil {
+				continue
+			}
+			sessionID := strings.TrimSpace(in.SessionID)
+			if sessionID == "" {
+				if v, ok := in.Payload["session_id"].(string); ok {
+					sessionID = strings.TrimSpace(v)
+				}
+			}
+			if sessionID == "" {
+				sessionID = sessionFilter
+			}
+			if sessionID == "" {
+				sessionID = implicitSession
+			}
+			if sessionID == "" || validator.ValidateSessionID(sessionID) != nil {
+				continue
+			}
+			s.bus.Publish(bus.NewEvent(bus.EventChatInject, sessionID, map[string]interface{}{
+				"content": content,
+			}))
 		}
 	}
 
-	s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, sessionFilter, map[string]interface{}{
+	disconnected := map[string]interface{}{
 		"kind":        "ws.disconnected",
 		"remote_addr": r.RemoteAddr,
 		"surface":     surface,
-	}))
+		"reason":      "closed",
+	}
+	if errMsg, ok := heartbeatErr.Load().(string); ok {
+		disconnected["reason"] = "heartbeat_timeout"
+		disconnected["error"] = errMsg
+	}
+	s.bus.Publish(bus.NewEvent(bus.EventTraceEvent, sessionFilter, disconnected))
 
 	c.Close(websocket.StatusNormalClosure, "")
 }
 
+// createChatSession applies the chat_without_session setting. It returns the
+// new session, or an error payload to send to the client.
+func (s *Server) createChatSession(content string) (*store.Session, map[string]any) {
+	s.cfgMu.RLock()
+	behavior := strings.ToLower(strings.TrimSpace(s.cfg.ChatWithoutSession))
+	s.cfgMu.RUnlock()
+
+	if behavior == config.ChatWithoutSessionReject {
+		return nil, map[string]any{
+			"kind":     "chat.session_required",
+			"error":    "session_id is required",
+			"behavior": config.ChatWithoutSessionReject,
+		}
+	}
+	if s.store == nil {
+		return nil, map[string]any{
+			"kind":  "chat.session_create_failed",
+			"error": "store not available",
+		}
+	}
+	sess, err := s.store.CreateSession(chatSessionTitle(content))
+	if err != nil {
+		return nil, map[string]any{
+			"kind":  "chat.session_create_failed",
+			"error": err.Error(),
+		}
+	}
+	return sess, nil
+}
+
+// chatSessionTitle derives a session title from the first line of a message.
+func chatSessionTitle(content string) string {
+	const maxTitleLen = 50
+	title, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	runes := []rune(strings.TrimSpace(title))
+	if len(runes) > maxTitleLen {
+		return string(runes[:maxTitleLen]) + "…"
+	}
+	if len(runes) == 0 {
+		return "Session"
+	}
+	return string(runes)
+}
+
+// subscribedTo reports whether a connection subscribed to topics receives evt.
+func subscribedTo(topics []bus.EventType, evt bus.EventType) bool {
+	if len(topics) == 0 {
+		return true
+	}
+	for _, t := range topics {
+		if t == evt {
+			return true
+		}
+	}
+	return false
+}
+
 // getClientIP extracts the client IP from the request
 func getClientIP(r *http.Request) string {
 	// Check X-Forwarded-For header (for proxies)
@@ -1,11 +1,20 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
+
+	"pryx-core/internal/bus"
+	"pryx-core/internal/config"
+	"pryx-core/internal/store"
+
+	"golang.org/x/time/rate"
+	"nhooyr.io/websocket"
 )
 
 func TestGetClientIP(t *testing.T) {
@@ -117,3 +126,252 @@ func TestDefaultConstants(t *testing.T) {
 		t.Errorf("defaultRateLimitPerMinute = %d, want 60", defaultRateLimitPerMinute)
 	}
 }
+
+func TestChatSessionTitle(t *testing.T) {
+	tests := map[string]string{
+		"Plan the release\nwith details": "Plan the release",
+		"   ":                            "Session",
+		strings.Repeat("a", 60):          strings.Repeat("a", 50) + "…",
+	}
+	for content, want := range tests {
+		if got := chatSessionTitle(content); got != want {
+			t.Errorf("chatSessionTitle(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestWebSocketOriginPatterns(t *testing.T) {
+	got := websocketOriginPatterns([]string{"https://app.example.com", " *.trusted.dev ", "http://localhost:5173", "", "://bad"})
+	want := []string{"app.example.com", "*.trusted.dev", "localhost:5173"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("websocketOriginPatterns() = %v, want %v", got, want)
+	}
+}
+
+func TestHandleWSOrigin(t *testing.T) {
+	resetRateLimiters()
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	dial := func(t *testing.T, cfg *config.Config, origin string) (*http.Response, error) {
+		resetRateLimiters()
+		srv := httptest.NewServer(New(cfg, st.DB, newTestKeychain(t)).router)
+		defer srv.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		header := http.Header{}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		c, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", &websocket.DialOptions{HTTPHeader: header})
+		if err == nil {
+			c.Close(websocket.StatusNormalClosure, "")
+		}
+		return resp, err
+	}
+
+	cfg := &config.Config{ListenAddr: "127.0.0.1:0", WebSocketAllowedOrigins: []string{"https://app.example.com", "*.trusted.dev"}}
+	for _, origin := range []string{"", "https://app.example.com", "https://ui.trusted.dev"} {
+		if _, err := dial(t, cfg, origin); err != nil {
+			t.Errorf("origin %q rejected: %v", origin, err)
+		}
+	}
+	// Loopback origins get no special treatment, even on a loopback listener
+	for _, origin := range []string{"https://evil.com", "http://localhost:5173", "https://app.example.com.evil.com"} {
+		resp, err := dial(t, cfg, origin)
+		if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected 403 for origin %s, got resp=%v err=%v", origin, resp, err)
+		}
+	}
+
+	skip := true
+	cfg.WebSocketSkipOriginVerify = &skip
+	if _, err := dial(t, cfg, "https://evil.com"); err != nil {
+		t.Errorf("origin rejected with verification skipped: %v", err)
+	}
+}
+
+// resetRateLimiters clears the per-IP connection limiters so a test's dials
+// don't count against the budget used up by earlier tests.
+func resetRateLimiters() {
+	rateLimitMutex.Lock()
+	rateLimiters = make(map[string]*rate.Limiter)
+	rateLimitMutex.Unlock()
+}
+
+func TestHandleWSChatSendUsesMessageSessionID(t *testing.T) {
+	resetRateLimiters()
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	s := New(&config.Config{ListenAddr: "127.0.0.1:0"}, st.DB, newTestKeychain(t))
+	events, cancelSub := s.bus.Subscribe(bus.EventChatRequest)
+	defer cancelSub()
+
+	srv := httptest.NewServer(s.router)
+	defer srv.Close()
+
+	const (
+		connSession = "11111111-1111-4111-8111-111111111111"
+		sessionA    = "aaaaaaaa-aaaa-4aaa-8aaa-aaaaaaaaaaaa"
+		sessionB    = "bbbbbbbb-bbbb-4bbb-8bbb-bbbbbbbbbbbb"
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?session_id="+connSession, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close(websocket.StatusNormalClosure, "")
+
+	for _, sid := range []string{sessionA, sessionB, ""} {
+		msg := `{"event":"chat.send","payload":{"content":"hello"}}`
+		if sid != "" {
+			msg = `{"event":"chat.send","session_id":"` + sid + `","payload":{"content":"hello"}}`
+		}
+		if err := c.Write(ctx, websocket.MessageText, []byte(msg)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	for _, want := range []string{sessionA, sessionB, connSession} {
+		select {
+		case evt := <-events:
+			if evt.SessionID != want {
+				t.Fatalf("expected chat request for session %s, got %s", want, evt.SessionID)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for chat request for session %s", want)
+		}
+	}
+}
+
+func TestHandleWSHeartbeat(t *testing.T) {
+	resetRateLimiters()
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	s := New(&config.Config{ListenAddr: "127.0.0.1:0", WebSocketPingInterval: 50 * time.Millisecond}, st.DB, newTestKeychain(t))
+	traces, cancelSub := s.bus.Subscribe(bus.EventTraceEvent)
+	defer cancelSub()
+
+	srv := httptest.NewServer(s.router)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?event=none"
+
+	waitDisconnect := func(t *testing.T, timeout time.Duration) map[string]interface{} {
+		t.Helper()
+		deadline := time.After(timeout)
+		for {
+			select {
+			case evt := <-traces:
+				payload, _ := evt.Payload.(map[string]interface{})
+				if payload["kind"] == "ws.disconnected" {
+					return payload
+				}
+			case <-deadline:
+				return nil
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A client that reads answers pings and stays connected
+	alive, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	aliveCtx := alive.CloseRead(ctx)
+	if payload := waitDisconnect(t, 300*time.Millisecond); payload != nil {
+		t.Fatalf("responsive client was disconnected: %v", payload)
+	}
+	alive.Close(websocket.StatusNormalClosure, "")
+	<-aliveCtx.Done()
+	if payload := waitDisconnect(t, 2*time.Second); payload == nil || payload["reason"] != "closed" {
+		t.Fatalf("expected clean disconnect, got %v", payload)
+	}
+
+	// A client that never reads cannot answer pings
+	dead, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer dead.CloseNow()
+	payload := waitDisconnect(t, 2*time.Second)
+	if payload == nil || payload["reason"] != "heartbeat_timeout" {
+		t.Fatalf("expected heartbeat_timeout disconnect, got %v", payload)
+	}
+}
+
+func TestHandleWSMessageRateLimit(t *testing.T) {
+	resetRateLimiters()
+	st, err := store.New(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	s := New(&config.Config{ListenAddr: "127.0.0.1:0", WebSocketRateLimitPerMinute: 3}, st.DB, newTestKeychain(t))
+	requests, cancelSub := s.bus.Subscribe(bus.EventChatRequest)
+	defer cancelSub()
+
+	srv := httptest.NewServer(s.router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?event=none", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer c.Close(websocket.StatusNormalClosure, "")
+
+	msg := `{"event":"chat.send","session_id":"aaaaaaaa-aaaa-4aaa-8aaa-aaaaaaaaaaaa","payload":{"content":"hello"}}`
+	for i := 0; i < 5; i++ {
+		if err := c.Write(ctx, websocket.MessageText, []byte(msg)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	// The two messages over the limit come back as errors
+	for i := 0; i < 2; i++ {
+		_, data, err := c.Read(ctx)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		var evt struct {
+			Event   string                 `json:"event"`
+			Payload map[string]interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal(data, &evt); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if evt.Event != "error" || evt.Payload["kind"] != "ws.rate_limited" {
+			t.Fatalf("expected ws.rate_limited error, got %s", data)
+		}
+	}
+
+	got := 0
+	for done := false; !done; {
+		select {
+		case <-requests:
+			got++
+		case <-time.After(100 * time.Millisecond):
+			done = true
+		}
+	}
+	if got != 3 {
+		t.Fatalf("expected 3 chat requests within the limit, got %d", got)
+	}
+}
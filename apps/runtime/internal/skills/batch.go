@@ -0,0 +1,237 @@
+package skills
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBatchConcurrency is the number of URLs installed in parallel when
+	// BatchInstallOptions.Concurrency is zero.
+	DefaultBatchConcurrency = 4
+	// MaxBatchConcurrency caps BatchInstallOptions.Concurrency.
+	MaxBatchConcurrency = 16
+	// DefaultBatchRetries is the number of retries per URL when
+	// BatchInstallOptions.Retries is negative.
+	DefaultBatchRetries = 2
+	// MaxBatchRetries caps BatchInstallOptions.Retries.
+	MaxBatchRetries = 5
+	// DefaultBatchBackoff is the delay before the first retry of a URL; it
+	// doubles with each further retry.
+	DefaultBatchBackoff = time.Second
+	// MaxBatchBackoff caps the delay between two attempts on one URL.
+	MaxBatchBackoff = 30 * time.Second
+)
+
+// Batch install result statuses.
+const (
+	BatchStatusInstalled = "installed"
+	BatchStatusFailed    = "failed"
+	BatchStatusSkipped   = "skipped"
+)
+
+// BatchInstallOptions controls InstallBatch. Zero values select the defaults,
+// except Retries where a negative value does.
+type BatchInstallOptions struct {
+	Concurrency int
+	Retries     int
+	Backoff     time.Duration
+	// Overwrite reinstalls skills that already exist in the managed root;
+	// otherwise they are reported as skipped.
+	Overwrite bool
+}
+
+// BatchInstallItem is the outcome of installing one URL.
+type BatchInstallItem struct {
+	URL      string `json:"url"`
+	Status   string `json:"status"`
+	SkillID  string `json:"skill_id,omitempty"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+
+	// Skill is set for installed items.
+	Skill *Skill `json:"-"`
+}
+
+// BatchInstallReport summarizes InstallBatch. Results are in input order.
+type BatchInstallReport struct {
+	Installed int                `json:"installed"`
+	Failed    int                `json:"failed"`
+	Skipped   int                `json:"skipped"`
+	Results   []BatchInstallItem `json:"results"`
+}
+
+func (o BatchInstallOptions) normalized() BatchInstallOptions {
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultBatchConcurrency
+	}
+	if o.Concurrency > MaxBatchConcurrency {
+		o.Concurrency = MaxBatchConcurrency
+	}
+	if o.Retries < 0 {
+		o.Retries = DefaultBatchRetries
+	}
+	if o.Retries > MaxBatchRetries {
+		o.Retries = MaxBatchRetries
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultBatchBackoff
+	}
+	return o
+}
+
+// InstallBatch installs skills from urls with bounded parallelism. Each URL is
+// retried with exponential backoff on network errors, 5xx and 429 responses.
+// A failing URL does not stop the others; its error is recorded in the report.
+// Repeated URLs, and skills already installed unless opts.Overwrite is set,
+// are skipped.
+func InstallBatch(ctx context.Context, urls []string, opts Options, batch BatchInstallOptions) *BatchInstallReport {
+	batch = batch.normalized()
+	report := &BatchInstallReport{Results: make([]BatchInstallItem, len(urls))}
+
+	// Two URLs naming the same skill would race on its directory, so claim
+	// skill IDs before writing.
+	var claimMu sync.Mutex
+	claimed := map[string]bool{}
+	claim := func(id string) bool {
+		claimMu.Lock()
+		defer claimMu.Unlock()
+		if claimed[id] {
+			return false
+		}
+		claimed[id] = true
+		return true
+	}
+
+	seen := map[string]bool{}
+	sem := make(chan struct{}, batch.Concurrency)
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		item := &report.Results[i]
+		item.URL = u
+		if seen[u] {
+			item.Status = BatchStatusSkipped
+			item.Reason = "duplicate url"
+			continue
+		}
+		seen[u] = true
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				item.Status = BatchStatusFailed
+				item.Error = ctx.Err().Error()
+				return
+			}
+			defer func() { <-sem }()
+			installBatchItem(ctx, item, opts, batch, claim)
+		}()
+	}
+	wg.Wait()
+
+	for _, item := range report.Results {
+		switch item.Status {
+		case BatchStatusInstalled:
+			report.Installed++
+		case BatchStatusFailed:
+			report.Failed++
+		case BatchStatusSkipped:
+			report.Skipped++
+		}
+	}
+	return report
+}
+
+func installBatchItem(ctx context.Context, item *BatchInstallItem, opts Options, batch BatchInstallOptions, claim func(string) bool) {
+	fail := func(err error) {
+		item.Status = BatchStatusFailed
+		item.Error = err.Error()
+	}
+
+	if parsed, err := url.Parse(item.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		fail(fmt.Errorf("invalid skill url %q", item.URL))
+		return
+	}
+
+	var data []byte
+	backoff := batch.Backoff
+	for {
+		item.Attempts++
+		var err error
+		data, err = downloadSkillFile(ctx, item.URL)
+		if err == nil {
+			break
+		}
+		if item.Attempts > batch.Retries || !retryableDownloadError(err) {
+			fail(err)
+			return
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			fail(ctx.Err())
+			return
+		}
+		backoff *= 2
+		if backoff > MaxBatchBackoff {
+			backoff = MaxBatchBackoff
+		}
+	}
+
+	fm, body, err := parseSkillFile(data)
+	if err != nil {
+		fail(fmt.Errorf("parse skill: %w", err))
+		return
+	}
+	item.SkillID = fm.Name
+	if fm.Name != "" && !claim(fm.Name) {
+		item.Status = BatchStatusSkipped
+		item.Reason = "skill installed by another url in this batch"
+		return
+	}
+	if !batch.Overwrite && managedSkillExists(opts, fm.Name) {
+		item.Status = BatchStatusSkipped
+		item.Reason = "already installed"
+		return
+	}
+
+	res, err := saveRemoteSkill(item.URL, data, fm, body, opts)
+	if err != nil {
+		fail(err)
+		return
+	}
+	item.Status = BatchStatusInstalled
+	item.Skill = &res.Skill
+}
+
+// retryableDownloadError reports whether a download error may be transient.
+func retryableDownloadError(err error) bool {
+	var statusErr *downloadStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func managedSkillExists(opts Options, skillID string) bool {
+	if skillID == "" {
+		return false
+	}
+	dir, err := managedSkillDir(opts, skillID)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(dir, "SKILL.md"))
+	return err == nil
+}
@@ -0,0 +1,106 @@
+package skills
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func skillFile(name string) string {
+	return "---\nname: " + name + "\ndescription: test skill\n---\nbody\n"
+}
+
+func TestInstallBatchContinuesPastFailures(t *testing.T) {
+	var flakyCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/alpha":
+			w.Write([]byte(skillFile("alpha")))
+		case "/flaky":
+			if flakyCalls.Add(1) < 3 {
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(skillFile("flaky")))
+		case "/existing":
+			w.Write([]byte(skillFile("existing")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	opts := Options{ManagedRoot: t.TempDir()}
+	if err := os.MkdirAll(filepath.Join(opts.ManagedRoot, "existing"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.ManagedRoot, "existing", "SKILL.md"), []byte(skillFile("existing")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	urls := []string{
+		server.URL + "/alpha",
+		server.URL + "/missing",
+		server.URL + "/flaky",
+		server.URL + "/existing",
+		server.URL + "/alpha",
+		"ftp://example.com/skill",
+	}
+	report := InstallBatch(context.Background(), urls, opts, BatchInstallOptions{Concurrency: 2, Retries: 3, Backoff: time.Millisecond})
+
+	want := []string{BatchStatusInstalled, BatchStatusFailed, BatchStatusInstalled, BatchStatusSkipped, BatchStatusSkipped, BatchStatusFailed}
+	for i, item := range report.Results {
+		if item.URL != urls[i] || item.Status != want[i] {
+			t.Errorf("result %d = %s %s (%s%s), want %s", i, item.URL, item.Status, item.Error, item.Reason, want[i])
+		}
+	}
+	if report.Installed != 2 || report.Failed != 2 || report.Skipped != 2 {
+		t.Errorf("counts = %d/%d/%d, want 2/2/2", report.Installed, report.Failed, report.Skipped)
+	}
+	if got := report.Results[2].Attempts; got != 3 {
+		t.Errorf("flaky url attempts = %d, want 3", got)
+	}
+	if got := report.Results[1].Attempts; got != 1 {
+		t.Errorf("404 was retried: %d attempts", got)
+	}
+	if report.Results[0].Skill == nil || report.Results[0].Skill.ID != "alpha" {
+		t.Errorf("installed item missing skill: %+v", report.Results[0])
+	}
+	if _, err := os.Stat(filepath.Join(opts.ManagedRoot, "flaky", "SKILL.md")); err != nil {
+		t.Errorf("flaky skill not saved: %v", err)
+	}
+}
+
+func TestInstallBatchBoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(skillFile(r.URL.Path[1:])))
+	}))
+	defer server.Close()
+
+	var urls []string
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		urls = append(urls, server.URL+"/"+name)
+	}
+	report := InstallBatch(context.Background(), urls, Options{ManagedRoot: t.TempDir()}, BatchInstallOptions{Concurrency: 2})
+	if report.Installed != len(urls) {
+		t.Fatalf("installed %d of %d: %+v", report.Installed, len(urls), report.Results)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", p)
+	}
+}
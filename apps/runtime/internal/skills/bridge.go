@@ -16,8 +16,20 @@ type AgentBridge struct {
 	baseURL   string
 	authToken string
 	authType  string
+	observer  ExecutionObserver
 }
 
+// Execution describes one completed skill execution.
+type Execution struct {
+	SkillID  string
+	Duration time.Duration
+	// Err is set when the call failed or the skill answered with an error status.
+	Err error
+}
+
+// ExecutionObserver is notified after every skill execution.
+type ExecutionObserver func(Execution)
+
 type BridgeRequest struct {
 	Method  string
 	Path    string
@@ -45,7 +57,25 @@ func NewAgentBridge(skill Skill, baseURL, authToken, authType string) *AgentBrid
 	}
 }
 
+// SetObserver registers fn to be notified after every Execute call.
+func (b *AgentBridge) SetObserver(fn ExecutionObserver) {
+	b.observer = fn
+}
+
 func (b *AgentBridge) Execute(ctx context.Context, req BridgeRequest) (*BridgeResponse, error) {
+	start := time.Now()
+	resp, err := b.execute(ctx, req)
+	if b.observer != nil {
+		runErr := err
+		if runErr == nil && resp.StatusCode >= 400 {
+			runErr = fmt.Errorf("skill returned status %d", resp.StatusCode)
+		}
+		b.observer(Execution{SkillID: b.skill.ID, Duration: time.Since(start), Err: runErr})
+	}
+	return resp, err
+}
+
+func (b *AgentBridge) execute(ctx context.Context, req BridgeRequest) (*BridgeResponse, error) {
 	url := b.baseURL + req.Path
 
 	if len(req.Query) > 0 {
@@ -129,8 +159,9 @@ func (b *AgentBridge) Post(path string, body interface{}) (*BridgeResponse, erro
 	})
 }
 
+// IsHealthy probes the skill's /health endpoint. Probes are not reported as executions.
 func (b *AgentBridge) IsHealthy(ctx context.Context) bool {
-	resp, err := b.Execute(ctx, BridgeRequest{
+	resp, err := b.execute(ctx, BridgeRequest{
 		Method: "GET",
 		Path:   "/health",
 	})
@@ -0,0 +1,43 @@
+package skills
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAgentBridgeReportsExecutions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	var got []Execution
+	bridge := NewAgentBridge(Skill{ID: "weather"}, srv.URL, "", "")
+	bridge.SetObserver(func(e Execution) { got = append(got, e) })
+
+	if _, err := bridge.Get("/forecast", nil); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := bridge.Post("/fail", nil); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if !bridge.IsHealthy(context.Background()) {
+		t.Fatal("expected healthy bridge")
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 executions (health probes excluded), got %d", len(got))
+	}
+	if got[0].SkillID != "weather" || got[0].Err != nil {
+		t.Errorf("unexpected first execution: %+v", got[0])
+	}
+	if got[1].Err == nil {
+		t.Error("expected error status to be reported as a failure")
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+
+	"pryx-core/internal/workspace"
 )
 
 type Options struct {
@@ -19,9 +21,31 @@ type Options struct {
 	MaxConcurrent int
 }
 
+var (
+	managedRootMu      sync.RWMutex
+	defaultManagedRoot string
+)
+
+// SetManagedRoot sets the directory DefaultOptions installs managed skills
+// into when PRYX_MANAGED_SKILLS_DIR is unset, normally the workspace's skills
+// directory.
+func SetManagedRoot(dir string) {
+	managedRootMu.Lock()
+	defer managedRootMu.Unlock()
+	defaultManagedRoot = dir
+}
+
+func workspaceManagedRoot() string {
+	managedRootMu.RLock()
+	defer managedRootMu.RUnlock()
+	if defaultManagedRoot != "" {
+		return defaultManagedRoot
+	}
+	return filepath.Join(workspace.DefaultRoot(), workspace.DirSkills)
+}
+
 func DefaultOptions() Options {
 	wd, _ := os.Getwd()
-	home, _ := os.UserHomeDir()
 	execPath, _ := os.Executable()
 	execDir := filepath.Dir(execPath)
 
@@ -31,7 +55,7 @@ func DefaultOptions() Options {
 	}
 	managedRoot := strings.TrimSpace(os.Getenv("PRYX_MANAGED_SKILLS_DIR"))
 	if managedRoot == "" {
-		managedRoot = filepath.Join(home, ".pryx", "skills")
+		managedRoot = workspaceManagedRoot()
 	}
 	bundledRoot := strings.TrimSpace(os.Getenv("PRYX_BUNDLED_SKILLS_DIR"))
 	if bundledRoot == "" {
@@ -144,6 +168,16 @@ func Discover(ctx context.Context, opts Options) (*Registry, error) {
 	return r, nil
 }
 
+// Reload re-runs discovery with opts and, if every skill loads, replaces r's
+// skills with the result. On any error r is left unchanged.
+func (r *Registry) Reload(ctx context.Context, opts Options) (RegistryDiff, error) {
+	next, err := Discover(ctx, opts)
+	if err != nil {
+		return RegistryDiff{}, err
+	}
+	return r.Replace(next), nil
+}
+
 func applySkillState(reg *Registry, enabled map[string]bool) {
 	for _, s := range reg.List() {
 		s.Name = s.Frontmatter.Name
@@ -181,8 +215,21 @@ func applySkillState(reg *Registry, enabled map[string]bool) {
 			}
 		}
 
+		s.Category = strings.ToLower(strings.TrimSpace(s.Frontmatter.Category))
+		if s.Category == "" {
+			s.Category = UncategorizedCategory
+		}
+		s.Priority = s.Frontmatter.Priority
+		// Only bundled skills may turn themselves on; a skill dropped into a
+		// workspace or the managed directory stays off until the user opts in.
+		s.EnabledByDefault = s.Source == SourceBundled && s.Frontmatter.EnabledByDefault
+
 		s.Eligible = eligible
-		s.Enabled = enabled[strings.TrimSpace(s.ID)]
+		if on, explicit := enabled[strings.TrimSpace(s.ID)]; explicit {
+			s.Enabled = on
+		} else {
+			s.Enabled = s.EnabledByDefault
+		}
 		reg.Upsert(s)
 	}
 }
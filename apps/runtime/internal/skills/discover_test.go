@@ -272,3 +272,134 @@ metadata:
 		t.Fatalf("expected ineligible skill")
 	}
 }
+
+func TestRegistryReloadReportsDiffAndKeepsOnError(t *testing.T) {
+	t.Setenv("PRYX_SKILLS_CONFIG_PATH", filepath.Join(t.TempDir(), "skills.yaml"))
+	managedRoot := t.TempDir()
+	opts := Options{WorkspaceRoot: t.TempDir(), ManagedRoot: managedRoot, MaxConcurrent: 2}
+
+	writeSkill := func(dir, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(managedRoot, dir), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(managedRoot, dir, "SKILL.md"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write skill: %v", err)
+		}
+	}
+	writeSkill("linter", "---\nname: linter\ndescription: lint\n---\nv1")
+	writeSkill("old", "---\nname: old\ndescription: old\n---\nbody")
+
+	reg, err := Discover(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+
+	writeSkill("linter", "---\nname: linter\ndescription: lint\n---\nv2")
+	writeSkill("fresh", "---\nname: fresh\ndescription: new\n---\nbody")
+	if err := os.RemoveAll(filepath.Join(managedRoot, "old")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	diff, err := reg.Reload(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("reload failed: %v", err)
+	}
+	if fmt.Sprint(diff.Added, diff.Removed, diff.Changed) != "[fresh] [old] [linter]" {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	if s, _ := reg.Get("linter"); s.SystemPrompt != "v2" {
+		t.Fatalf("expected reloaded body, got %q", s.SystemPrompt)
+	}
+
+	writeSkill("broken", "---\ndescription: no name\n---\n")
+	if _, err := reg.Reload(context.Background(), opts); err == nil {
+		t.Fatal("expected reload to fail on a broken skill")
+	}
+	if _, ok := reg.Get("fresh"); !ok || len(reg.List()) != 2 {
+		t.Fatalf("expected previous skills to be kept, got %+v", reg.List())
+	}
+}
+
+func TestDiscoverAppliesDefaultsAndCategories(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "skills.yaml")
+	t.Setenv("PRYX_SKILLS_CONFIG_PATH", configPath)
+	bundledRoot := t.TempDir()
+	managedRoot := t.TempDir()
+
+	writeSkill := func(root, dir, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "SKILL.md"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write skill: %v", err)
+		}
+	}
+	writeSkill(bundledRoot, "weather", "---\nname: weather\ndescription: w\ncategory: Utilities\nenabled_by_default: true\npriority: 5\n---\nbody")
+	writeSkill(bundledRoot, "notes", "---\nname: notes\ndescription: n\nenabled_by_default: true\n---\nbody")
+	writeSkill(bundledRoot, "deploy", "---\nname: deploy\ndescription: d\ncategory: devops\n---\nbody")
+	writeSkill(managedRoot, "sneaky", "---\nname: sneaky\ndescription: s\nenabled_by_default: true\n---\nbody")
+
+	opts := Options{WorkspaceRoot: t.TempDir(), BundledRoot: bundledRoot, ManagedRoot: managedRoot, MaxConcurrent: 2}
+	reg, err := Discover(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+
+	weather, _ := reg.Get("weather")
+	if !weather.Enabled || !weather.EnabledByDefault {
+		t.Fatalf("expected weather enabled by default, got %+v", weather)
+	}
+	if weather.Category != "utilities" || weather.Priority != 5 {
+		t.Fatalf("expected utilities/5, got %s/%d", weather.Category, weather.Priority)
+	}
+	if notes, _ := reg.Get("notes"); notes.Category != UncategorizedCategory {
+		t.Fatalf("expected uncategorized, got %q", notes.Category)
+	}
+	if deploy, _ := reg.Get("deploy"); deploy.Enabled {
+		t.Fatalf("expected deploy disabled without a default")
+	}
+	if sneaky, _ := reg.Get("sneaky"); sneaky.Enabled {
+		t.Fatalf("expected managed skill to ignore enabled_by_default")
+	}
+
+	// Explicit choices win over the default in both directions
+	cfg := "enabled_skills:\n  weather: false\n  deploy: true\n"
+	if err := os.WriteFile(configPath, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write enabled config: %v", err)
+	}
+	reg, err = Discover(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+	if weather, _ := reg.Get("weather"); weather.Enabled {
+		t.Fatalf("expected explicit disable to override the default")
+	}
+	if deploy, _ := reg.Get("deploy"); !deploy.Enabled {
+		t.Fatalf("expected explicit enable")
+	}
+}
+
+func TestDefaultOptionsManagedRoot(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("PRYX_WORKSPACE_ROOT", base)
+	t.Setenv("PRYX_MANAGED_SKILLS_DIR", "")
+	t.Cleanup(func() { SetManagedRoot("") })
+
+	if got, want := DefaultOptions().ManagedRoot, filepath.Join(base, ".pryx", "skills"); got != want {
+		t.Errorf("expected the default workspace skills dir %q, got %q", want, got)
+	}
+
+	configured := filepath.Join(t.TempDir(), "skills")
+	SetManagedRoot(configured)
+	if got := DefaultOptions().ManagedRoot; got != configured {
+		t.Errorf("expected the configured workspace skills dir %q, got %q", configured, got)
+	}
+
+	override := t.TempDir()
+	t.Setenv("PRYX_MANAGED_SKILLS_DIR", override)
+	if got := DefaultOptions().ManagedRoot; got != override {
+		t.Errorf("expected PRYX_MANAGED_SKILLS_DIR to win, got %q", got)
+	}
+}
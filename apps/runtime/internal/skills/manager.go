@@ -12,6 +12,7 @@ type UnifiedManager struct {
 	registry *Registry
 	opts     Options
 	bridges  map[string]*AgentBridge
+	observer ExecutionObserver
 }
 
 func NewUnifiedManager(opts Options) *UnifiedManager {
@@ -22,6 +23,24 @@ func NewUnifiedManager(opts Options) *UnifiedManager {
 	}
 }
 
+// NewUnifiedManagerFor creates a manager whose bridges serve the skills of
+// registry, so it follows reloads of an already discovered registry.
+func NewUnifiedManagerFor(registry *Registry, opts Options) *UnifiedManager {
+	m := NewUnifiedManager(opts)
+	m.registry = registry
+	return m
+}
+
+// SetExecutionObserver registers fn on every bridge the manager creates.
+func (m *UnifiedManager) SetExecutionObserver(fn ExecutionObserver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observer = fn
+	for _, bridge := range m.bridges {
+		bridge.SetObserver(fn)
+	}
+}
+
 func (m *UnifiedManager) Initialize(ctx context.Context) error {
 	registry, err := Discover(ctx, m.opts)
 	if err != nil {
@@ -84,6 +103,7 @@ func (m *UnifiedManager) GetBridge(skillID string) (*AgentBridge, error) {
 	bridge = NewAgentBridge(skill, endpoint, authToken, authType)
 
 	m.mu.Lock()
+	bridge.SetObserver(m.observer)
 	m.bridges[skillID] = bridge
 	m.mu.Unlock()
 
@@ -1,6 +1,7 @@
 package skills
 
 import (
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -73,6 +74,49 @@ func (r *Registry) List() []Skill {
 	return out
 }
 
+// UncategorizedCategory is the category of skills whose frontmatter names none.
+const UncategorizedCategory = "uncategorized"
+
+// SortByCategory orders skills by category, with uncategorized skills last,
+// then by descending priority and ID.
+func SortByCategory(list []Skill) {
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Category != b.Category {
+			if a.Category == UncategorizedCategory || b.Category == UncategorizedCategory {
+				return b.Category == UncategorizedCategory
+			}
+			return a.Category < b.Category
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.ID < b.ID
+	})
+}
+
+// CategoryCount is a skill category and how many skills it holds.
+type CategoryCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Categories returns the distinct categories in list, in SortByCategory order,
+// with the number of skills in each.
+func Categories(list []Skill) []CategoryCount {
+	sorted := append([]Skill(nil), list...)
+	SortByCategory(sorted)
+	out := []CategoryCount{}
+	for _, s := range sorted {
+		if n := len(out); n > 0 && out[n-1].Name == s.Category {
+			out[n-1].Count++
+			continue
+		}
+		out = append(out, CategoryCount{Name: s.Category, Count: 1})
+	}
+	return out
+}
+
 func (r *Registry) MetadataSummary() string {
 	skills := r.List()
 	if len(skills) == 0 {
@@ -108,3 +152,56 @@ func (r *Registry) MetadataSummary() string {
 	}
 	return b.String()
 }
+
+// RegistryDiff lists the skill IDs added, removed and changed by Replace.
+type RegistryDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// Empty reports whether nothing changed.
+func (d RegistryDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Replace swaps the registry's skills for those in next in one step, so
+// holders of r see either the old or the new set, and returns what changed.
+func (r *Registry) Replace(next *Registry) RegistryDiff {
+	incoming := make(map[string]Skill)
+	for _, s := range next.List() {
+		incoming[s.ID] = s
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	diff := RegistryDiff{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	for id, s := range incoming {
+		old, ok := r.skills[id]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, id)
+		case skillChanged(old, s):
+			diff.Changed = append(diff.Changed, id)
+		}
+	}
+	for id := range r.skills {
+		if _, ok := incoming[id]; !ok {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	r.skills = incoming
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+// skillChanged compares the parts of a skill that come from its file and config.
+func skillChanged(a, b Skill) bool {
+	return a.Source != b.Source || a.Path != b.Path || a.Version != b.Version ||
+		a.Enabled != b.Enabled || a.Eligible != b.Eligible ||
+		a.SystemPrompt != b.SystemPrompt || a.UserPrompt != b.UserPrompt ||
+		!reflect.DeepEqual(a.Frontmatter, b.Frontmatter)
+}
@@ -347,3 +347,26 @@ func BenchmarkRegistry_List(b *testing.B) {
 		_ = reg.List()
 	}
 }
+
+func TestSortByCategory(t *testing.T) {
+	list := []Skill{
+		{ID: "misc", Category: UncategorizedCategory},
+		{ID: "lint", Category: "dev", Priority: 1},
+		{ID: "weather", Category: "utilities"},
+		{ID: "build", Category: "dev", Priority: 1},
+		{ID: "deploy", Category: "dev", Priority: 9},
+	}
+
+	SortByCategory(list)
+
+	ids := make([]string, 0, len(list))
+	for _, s := range list {
+		ids = append(ids, s.ID)
+	}
+	assert.Equal(t, []string{"deploy", "build", "lint", "weather", "misc"}, ids)
+	assert.Equal(t, []CategoryCount{
+		{Name: "dev", Count: 3},
+		{Name: "utilities", Count: 1},
+		{Name: UncategorizedCategory, Count: 1},
+	}, Categories(list))
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"pryx-core/internal/workspace"
 )
 
 type RemoteInstallResult struct {
@@ -16,7 +18,28 @@ type RemoteInstallResult struct {
 	SourceURL    string
 }
 
+// downloadStatusError is returned when a skill URL answers with a non-200 status.
+type downloadStatusError struct {
+	StatusCode int
+}
+
+func (e *downloadStatusError) Error() string {
+	return fmt.Sprintf("download failed: status %d", e.StatusCode)
+}
+
 func InstallFromURL(ctx context.Context, url string, opts Options) (*RemoteInstallResult, error) {
+	data, err := downloadSkillFile(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	fm, body, err := parseSkillFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse skill: %w", err)
+	}
+	return saveRemoteSkill(url, data, fm, body, opts)
+}
+
+func downloadSkillFile(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
@@ -29,25 +52,27 @@ func InstallFromURL(ctx context.Context, url string, opts Options) (*RemoteInsta
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("download failed: status %d", resp.StatusCode)
+		return nil, &downloadStatusError{StatusCode: resp.StatusCode}
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("read response: %w", err)
 	}
+	return data, nil
+}
 
-	fm, body, err := parseSkillFile(data)
-	if err != nil {
-		return nil, fmt.Errorf("parse skill: %w", err)
-	}
-
+// saveRemoteSkill writes a downloaded SKILL.md into the managed root.
+func saveRemoteSkill(url string, data []byte, fm Frontmatter, body string, opts Options) (*RemoteInstallResult, error) {
 	skillID := fm.Name
 	if skillID == "" {
 		return nil, fmt.Errorf("skill missing required 'name' field")
 	}
 
-	skillDir := filepath.Join(opts.ManagedRoot, skillID)
+	skillDir, err := managedSkillDir(opts, skillID)
+	if err != nil {
+		return nil, err
+	}
 	if err := os.MkdirAll(skillDir, 0755); err != nil {
 		return nil, fmt.Errorf("create skill dir: %w", err)
 	}
@@ -79,13 +104,30 @@ func InstallFromURL(ctx context.Context, url string, opts Options) (*RemoteInsta
 }
 
 func UninstallSkill(skillID string, opts Options) error {
-	skillDir := filepath.Join(opts.ManagedRoot, skillID)
+	skillDir, err := managedSkillDir(opts, skillID)
+	if err != nil {
+		return err
+	}
 	if _, err := os.Stat(skillDir); os.IsNotExist(err) {
 		return fmt.Errorf("skill not found: %s", skillID)
 	}
 	return os.RemoveAll(skillDir)
 }
 
+// managedSkillDir resolves the directory of skillID under the managed root,
+// rejecting IDs that would escape it or name the root itself.
+func managedSkillDir(opts Options, skillID string) (string, error) {
+	ws, err := workspace.New(opts.ManagedRoot)
+	if err != nil {
+		return "", fmt.Errorf("managed skills root: %w", err)
+	}
+	dir, err := ws.Resolve(skillID)
+	if err != nil || dir == ws.Root() {
+		return "", fmt.Errorf("invalid skill id: %q", skillID)
+	}
+	return dir, nil
+}
+
 func ListInstalled(opts Options) ([]Skill, error) {
 	entries, err := os.ReadDir(opts.ManagedRoot)
 	if err != nil {
@@ -0,0 +1,26 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUninstallSkillRejectsEscapingID(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "managed")
+	outside := filepath.Join(base, "outside")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := Options{ManagedRoot: root}
+	for _, id := range []string{"../outside", ".", ""} {
+		if err := UninstallSkill(id, opts); err == nil {
+			t.Errorf("UninstallSkill(%q) expected error", id)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Fatalf("directory outside managed root was removed: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Tool exposes remote agent skills as an agent tool: the model calls a
+// skill's endpoint through the manager's bridges, so every call is reported
+// to the manager's execution observer.
+type Tool struct {
+	manager *UnifiedManager
+}
+
+// NewTool creates a skill call tool backed by manager.
+func NewTool(manager *UnifiedManager) *Tool {
+	return &Tool{manager: manager}
+}
+
+// Name returns the tool name
+func (t *Tool) Name() string {
+	return "skills_call"
+}
+
+// Description returns the tool description for LLM
+func (t *Tool) Description() string {
+	return `Call a remote agent skill.
+
+Use this to send a request to an enabled skill of type agent. The skill's
+instructions describe the paths it serves and the body it expects.`
+}
+
+// Schema returns the JSON schema for the tool parameters
+func (t *Tool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"skill_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the skill to call",
+			},
+			"method": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional: HTTP method (defaults to POST)",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Path on the skill's endpoint, e.g. /run",
+			},
+			"body": map[string]interface{}{
+				"type":        "object",
+				"description": "Optional: JSON body to send",
+			},
+		},
+		"required": []string{"skill_id", "path"},
+	}
+}
+
+// Execute runs the skill call tool
+func (t *Tool) Execute(ctx context.Context, params json.RawMessage, sessionID string) (interface{}, error) {
+	var args struct {
+		SkillID string      `json:"skill_id"`
+		Method  string      `json:"method"`
+		Path    string      `json:"path"`
+		Body    interface{} `json:"body"`
+	}
+	if err := json.Unmarshal(params, &args); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	if args.SkillID == "" {
+		return nil, fmt.Errorf("skill_id is required")
+	}
+	if skill, ok := t.manager.GetSkill(args.SkillID); !ok || !skill.Enabled {
+		return nil, fmt.Errorf("skill not enabled: %s", args.SkillID)
+	}
+	if args.Method == "" {
+		args.Method = "POST"
+	}
+	if !strings.HasPrefix(args.Path, "/") {
+		args.Path = "/" + args.Path
+	}
+
+	bridge, err := t.manager.GetBridge(args.SkillID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bridge.Execute(ctx, BridgeRequest{
+		Method: strings.ToUpper(args.Method),
+		Path:   args.Path,
+		Body:   args.Body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("skill %s returned status %d: %s", args.SkillID, resp.StatusCode, strings.TrimSpace(string(resp.RawBody)))
+	}
+	if resp.Body != nil {
+		return resp.Body, nil
+	}
+	return string(resp.RawBody), nil
+}
@@ -0,0 +1,52 @@
+package skills
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestToolCallsAgentSkill(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/run" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"forecast":"sunny"}`))
+	}))
+	defer srv.Close()
+
+	registry := NewRegistry()
+	skill := Skill{ID: "weather", Enabled: true}
+	skill.Frontmatter.Metadata.Pryx = PryxMetadata{Type: "agent", Endpoint: srv.URL}
+	registry.Upsert(skill)
+	registry.Upsert(Skill{ID: "off", Frontmatter: skill.Frontmatter})
+
+	var got []Execution
+	manager := NewUnifiedManagerFor(registry, DefaultOptions())
+	manager.SetExecutionObserver(func(e Execution) { got = append(got, e) })
+	tool := NewTool(manager)
+
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"skill_id":"weather","path":"run"}`), "s1")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if body, _ := result.(map[string]interface{}); body["forecast"] != "sunny" {
+		t.Errorf("unexpected result: %v", result)
+	}
+	if len(got) != 1 || got[0].SkillID != "weather" || got[0].Err != nil {
+		t.Errorf("expected one successful execution to be observed, got %+v", got)
+	}
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"skill_id":"weather","path":"/missing"}`), "s1"); err == nil {
+		t.Error("expected an error status to fail the call")
+	}
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"skill_id":"off","path":"/run"}`), "s1"); err == nil {
+		t.Error("expected a disabled skill to be refused")
+	}
+	if len(got) != 2 {
+		t.Errorf("expected the refused call not to run, got %d executions", len(got))
+	}
+}
@@ -26,9 +26,16 @@ type SkillMetadata struct {
 }
 
 type Frontmatter struct {
-	Name        string        `yaml:"name"`
-	Description string        `yaml:"description"`
-	Metadata    SkillMetadata `yaml:"metadata,omitempty"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Category groups related skills in listings, e.g. "utilities".
+	Category string `yaml:"category,omitempty"`
+	// EnabledByDefault turns a bundled skill on until the user enables or
+	// disables it explicitly.
+	EnabledByDefault bool `yaml:"enabled_by_default,omitempty"`
+	// Priority orders skills within a category, highest first.
+	Priority int           `yaml:"priority,omitempty"`
+	Metadata SkillMetadata `yaml:"metadata,omitempty"`
 }
 
 type Installer struct {
@@ -51,20 +58,23 @@ type PryxMetadata struct {
 }
 
 type Skill struct {
-	ID           string                 `yaml:"id" json:"id"`
-	Source       Source                 `yaml:"source" json:"source"`
-	Name         string                 `yaml:"name" json:"name"`
-	Title        string                 `yaml:"title,omitempty" json:"title"`
-	Description  string                 `yaml:"description" json:"description"`
-	Version      string                 `yaml:"version" json:"version"`
-	Author       string                 `yaml:"author,omitempty" json:"author"`
-	Path         string                 `yaml:"path" json:"path"`
-	Frontmatter  Frontmatter            `yaml:"frontmatter"`
-	Enabled      bool                   `yaml:"enabled" json:"enabled"`
-	Eligible     bool                   `yaml:"eligible" json:"eligible"`
-	SystemPrompt string                 `yaml:"system_prompt,omitempty" json:"system_prompt"`
-	UserPrompt   string                 `yaml:"user_prompt,omitempty" json:"user_prompt"`
-	Metadata     map[string]interface{} `yaml:"metadata,omitempty" json:"metadata"`
+	ID               string                 `yaml:"id" json:"id"`
+	Source           Source                 `yaml:"source" json:"source"`
+	Name             string                 `yaml:"name" json:"name"`
+	Title            string                 `yaml:"title,omitempty" json:"title"`
+	Description      string                 `yaml:"description" json:"description"`
+	Version          string                 `yaml:"version" json:"version"`
+	Author           string                 `yaml:"author,omitempty" json:"author"`
+	Category         string                 `yaml:"category" json:"category"`
+	Priority         int                    `yaml:"priority" json:"priority"`
+	Path             string                 `yaml:"path" json:"path"`
+	Frontmatter      Frontmatter            `yaml:"frontmatter"`
+	Enabled          bool                   `yaml:"enabled" json:"enabled"`
+	EnabledByDefault bool                   `yaml:"enabled_by_default" json:"enabled_by_default"`
+	Eligible         bool                   `yaml:"eligible" json:"eligible"`
+	SystemPrompt     string                 `yaml:"system_prompt,omitempty" json:"system_prompt"`
+	UserPrompt       string                 `yaml:"user_prompt,omitempty" json:"user_prompt"`
+	Metadata         map[string]interface{} `yaml:"metadata,omitempty" json:"metadata"`
 
 	bodyLoader func() (string, error)
 }
@@ -0,0 +1,71 @@
+package store
+
+import (
+	"time"
+
+	"pryx-core/internal/channels"
+)
+
+// RecordChannelActivity appends an entry to the channel activity log.
+func (s *Store) RecordChannelActivity(a channels.Activity) error {
+	_, err := s.DB.Exec(
+		`INSERT INTO channel_activity (channel_id, event_type, conversation_id, sender_id, sender, snippet, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.ChannelID, a.EventType, a.ConversationID, a.SenderID, a.Sender, a.Snippet, a.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// ListChannelActivity returns up to limit of a channel's activity log entries,
+// newest first, skipping the newest offset entries.
+func (s *Store) ListChannelActivity(channelID string, limit, offset int) ([]channels.Activity, error) {
+	rows, err := s.DB.Query(
+		`SELECT id, channel_id, event_type, COALESCE(conversation_id, ''), COALESCE(sender_id, ''), COALESCE(sender, ''), snippet, created_at
+		 FROM channel_activity WHERE channel_id = ?
+		 ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`,
+		channelID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []channels.Activity{}
+	for rows.Next() {
+		var a channels.Activity
+		if err := rows.Scan(&a.ID, &a.ChannelID, &a.EventType, &a.ConversationID, &a.SenderID, &a.Sender, &a.Snippet, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, a)
+	}
+	return entries, rows.Err()
+}
+
+// PruneChannelActivity deletes activity log entries created before cutoff and
+// all but the newest maxPerChannel entries of each channel. It returns how
+// many entries were removed.
+func (s *Store) PruneChannelActivity(cutoff time.Time, maxPerChannel int) (int64, error) {
+	res, err := s.DB.Exec(`DELETE FROM channel_activity WHERE created_at < ?`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	removed, _ := res.RowsAffected()
+	if maxPerChannel <= 0 {
+		return removed, nil
+	}
+
+	res, err = s.DB.Exec(`
+		DELETE FROM channel_activity WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY channel_id ORDER BY created_at DESC, id DESC) AS rn
+				FROM channel_activity
+			) WHERE rn > ?
+		)`, maxPerChannel)
+	if err != nil {
+		return removed, err
+	}
+	n, _ := res.RowsAffected()
+	return removed + n, nil
+}
+
+var _ channels.ActivityLog = (*Store)(nil)
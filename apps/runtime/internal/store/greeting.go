@@ -0,0 +1,20 @@
+package store
+
+import "time"
+
+// MarkGreeted records that a channel conversation has been greeted. It reports
+// true the first time it is called for a conversation and false afterwards.
+func (s *Store) MarkGreeted(source, conversationID string) (bool, error) {
+	res, err := s.DB.Exec(
+		`INSERT OR IGNORE INTO channel_greetings (source, conversation_id, greeted_at) VALUES (?, ?, ?)`,
+		source, conversationID, time.Now().UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// DefaultIdempotencyKeyTTL is how long an idempotency key maps to its session
+// when no window is given.
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+// errIdempotencyKeyTaken means a concurrent create claimed the key first.
+var errIdempotencyKeyTaken = errors.New("idempotency key claimed concurrently")
+
+// CreateSessionIdempotent creates a session for key, or returns the session
+// already created for it within ttl (DefaultIdempotencyKeyTTL when zero).
+// created reports whether a new session was made. Expired keys are pruned,
+// and a key whose session has since been deleted starts over.
+func (s *Store) CreateSessionIdempotent(key, title string, ttl time.Duration) (sess *Session, created bool, err error) {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+	// A concurrent request with the same key may win the insert; the retry
+	// then finds its session.
+	for attempt := 0; attempt < 2; attempt++ {
+		sess, created, err = s.createSessionIdempotent(key, title, ttl)
+		if !errors.Is(err, errIdempotencyKeyTaken) {
+			return sess, created, err
+		}
+	}
+	return nil, false, err
+}
+
+func (s *Store) createSessionIdempotent(key, title string, ttl time.Duration) (sess *Session, created bool, err error) {
+	now := time.Now().UTC()
+	err = s.WithTx(func(tx *Tx) error {
+		if _, err := tx.Exec(`DELETE FROM session_idempotency_keys WHERE created_at < ?`, now.Add(-ttl)); err != nil {
+			return err
+		}
+
+		var sessionID string
+		err := tx.QueryRow(`SELECT session_id FROM session_idempotency_keys WHERE idempotency_key = ?`, key).Scan(&sessionID)
+		switch {
+		case err == nil:
+			existing := &Session{}
+			err = tx.QueryRow(
+				`SELECT id, title, COALESCE(description, ''), COALESCE(timezone, ''), created_at, updated_at FROM sessions WHERE id = ?`,
+				sessionID,
+			).Scan(&existing.ID, &existing.Title, &existing.Description, &existing.Timezone, &existing.CreatedAt, &existing.UpdatedAt)
+			if err == nil {
+				sess = existing
+				return nil
+			}
+			if err != sql.ErrNoRows {
+				return err
+			}
+			if _, err := tx.Exec(`DELETE FROM session_idempotency_keys WHERE idempotency_key = ?`, key); err != nil {
+				return err
+			}
+		case err != sql.ErrNoRows:
+			return err
+		}
+
+		newSess, err := tx.CreateSession(title)
+		if err != nil {
+			return err
+		}
+		res, err := tx.Exec(
+			`INSERT OR IGNORE INTO session_idempotency_keys (idempotency_key, session_id, created_at) VALUES (?, ?, ?)`,
+			key, newSess.ID, now,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return errIdempotencyKeyTaken
+		}
+		sess, created = newSess, true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return sess, created, nil
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// orphanRule describes one kind of orphaned row: rows matched by where have
+// lost the record they reference. repair fixes them, usually by deleting.
+type orphanRule struct {
+	kind   string
+	table  string
+	where  string
+	repair string
+}
+
+var orphanRules = []orphanRule{
+	{
+		kind:   "messages",
+		table:  "messages",
+		where:  `NOT EXISTS (SELECT 1 FROM sessions s WHERE s.id = messages.session_id)`,
+		repair: `DELETE FROM messages WHERE %s`,
+	},
+	{
+		kind:   "task_runs",
+		table:  "scheduled_task_runs",
+		where:  `NOT EXISTS (SELECT 1 FROM scheduled_tasks t WHERE t.id = scheduled_task_runs.task_id)`,
+		repair: `DELETE FROM scheduled_task_runs WHERE %s`,
+	},
+	{
+		kind:   "scheduler_locks",
+		table:  "scheduler_locks",
+		where:  `NOT EXISTS (SELECT 1 FROM scheduled_tasks t WHERE t.id = scheduler_locks.task_id)`,
+		repair: `DELETE FROM scheduler_locks WHERE %s`,
+	},
+	{
+		kind:   "memory_sources",
+		table:  "memory_sources",
+		where:  `NOT EXISTS (SELECT 1 FROM memory_entries e WHERE e.id = memory_sources.entry_id)`,
+		repair: `DELETE FROM memory_sources WHERE %s`,
+	},
+	{
+		kind:   "memory_vectors",
+		table:  "memory_vectors",
+		where:  `NOT EXISTS (SELECT 1 FROM memory_entries e WHERE e.id = memory_vectors.entry_id)`,
+		repair: `DELETE FROM memory_vectors WHERE %s`,
+	},
+	{
+		// User IDs only count as references once the users table is in use;
+		// the audit trail itself is kept and just loses the dangling user.
+		kind:  "audit_users",
+		table: "audit_log",
+		where: `COALESCE(user_id, '') != '' AND EXISTS (SELECT 1 FROM users)
+			AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = audit_log.user_id)`,
+		repair: `UPDATE audit_log SET user_id = NULL WHERE %s`,
+	},
+}
+
+// OrphanCount reports orphaned rows of one kind.
+type OrphanCount struct {
+	Kind     string `json:"kind"`
+	Count    int64  `json:"count"`
+	Repaired int64  `json:"repaired,omitempty"`
+}
+
+// IntegrityReport is the result of an orphaned-record check or repair.
+type IntegrityReport struct {
+	Orphans []OrphanCount `json:"orphans"`
+}
+
+// Total returns the number of orphaned rows found.
+func (r *IntegrityReport) Total() int64 {
+	var n int64
+	for _, o := range r.Orphans {
+		n += o.Count
+	}
+	return n
+}
+
+// String summarises the non-zero counts, e.g. "messages=3, task_runs=1".
+func (r *IntegrityReport) String() string {
+	var parts []string
+	for _, o := range r.Orphans {
+		if o.Count > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", o.Kind, o.Count))
+		}
+	}
+	if len(parts) == 0 {
+		return "no orphaned records"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CheckIntegrity counts orphaned rows without changing anything. It is cheap
+// enough to run on every startup.
+func (s *Store) CheckIntegrity() (*IntegrityReport, error) {
+	report := &IntegrityReport{}
+	for _, rule := range orphanRules {
+		var n int64
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, rule.table, rule.where)
+		if err := s.DB.QueryRow(query).Scan(&n); err != nil {
+			return nil, fmt.Errorf("check %s: %w", rule.kind, err)
+		}
+		report.Orphans = append(report.Orphans, OrphanCount{Kind: rule.kind, Count: n})
+	}
+	return report, nil
+}
+
+// RepairOrphans removes or detaches orphaned rows in a single transaction and
+// reports what was found and repaired.
+func (s *Store) RepairOrphans() (*IntegrityReport, error) {
+	report := &IntegrityReport{}
+	err := s.WithTx(func(tx *Tx) error {
+		for _, rule := range orphanRules {
+			res, err := tx.Exec(fmt.Sprintf(rule.repair, rule.where))
+			if err != nil {
+				return fmt.Errorf("repair %s: %w", rule.kind, err)
+			}
+			n, _ := res.RowsAffected()
+			report.Orphans = append(report.Orphans, OrphanCount{Kind: rule.kind, Count: n, Repaired: n})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
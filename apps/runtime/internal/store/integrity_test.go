@@ -0,0 +1,102 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func orphanCount(r *IntegrityReport, kind string) int64 {
+	for _, o := range r.Orphans {
+		if o.Kind == kind {
+			return o.Count
+		}
+	}
+	return -1
+}
+
+func TestCheckAndRepairOrphans(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	sess, _ := s.CreateSession("Kept")
+	if _, err := s.AddMessage(sess.ID, RoleUser, "kept"); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	seed := []string{
+		`INSERT INTO messages (id, session_id, role, content, created_at) VALUES ('m1', 'gone', 'user', 'x', CURRENT_TIMESTAMP)`,
+		`INSERT INTO messages (id, session_id, role, content, created_at) VALUES ('m2', 'gone', 'user', 'y', CURRENT_TIMESTAMP)`,
+		`INSERT INTO users (id, email) VALUES ('u1', 'a@example.com')`,
+		`INSERT INTO audit_log (id, timestamp, action, user_id) VALUES ('a1', CURRENT_TIMESTAMP, 'test', 'u1')`,
+		`INSERT INTO audit_log (id, timestamp, action, user_id) VALUES ('a2', CURRENT_TIMESTAMP, 'test', 'deleted-user')`,
+	}
+	for _, q := range seed {
+		if _, err := s.DB.Exec(q); err != nil {
+			t.Fatalf("seed %q: %v", q, err)
+		}
+	}
+	if _, err := s.DB.Exec(`INSERT INTO scheduled_task_runs (id, task_id, started_at, status) VALUES ('r1', 'missing-task', ?, 'success')`, now); err != nil {
+		t.Fatalf("seed task run: %v", err)
+	}
+
+	report, err := s.CheckIntegrity()
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if got := orphanCount(report, "messages"); got != 2 {
+		t.Errorf("Expected 2 orphaned messages, got %d", got)
+	}
+	if got := orphanCount(report, "task_runs"); got != 1 {
+		t.Errorf("Expected 1 orphaned task run, got %d", got)
+	}
+	if got := orphanCount(report, "audit_users"); got != 1 {
+		t.Errorf("Expected 1 audit entry with a missing user, got %d", got)
+	}
+
+	repaired, err := s.RepairOrphans()
+	if err != nil {
+		t.Fatalf("RepairOrphans failed: %v", err)
+	}
+	if repaired.Total() != 4 {
+		t.Errorf("Expected 4 repaired rows, got %d (%s)", repaired.Total(), repaired)
+	}
+
+	after, err := s.CheckIntegrity()
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if after.Total() != 0 {
+		t.Errorf("Expected no orphans after repair, got %s", after)
+	}
+	if n, _ := s.GetMessageCount(sess.ID); n != 1 {
+		t.Errorf("Expected the valid message to survive, got %d", n)
+	}
+	var audits int
+	_ = s.DB.QueryRow(`SELECT COUNT(*) FROM audit_log`).Scan(&audits)
+	if audits != 2 {
+		t.Errorf("Expected audit entries to be kept, got %d", audits)
+	}
+}
+
+func TestCheckIntegrityIgnoresUnmanagedUsers(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.DB.Exec(`INSERT INTO audit_log (id, timestamp, action, user_id) VALUES ('a1', CURRENT_TIMESTAMP, 'test', 'cli')`); err != nil {
+		t.Fatal(err)
+	}
+	report, err := s.CheckIntegrity()
+	if err != nil {
+		t.Fatalf("CheckIntegrity failed: %v", err)
+	}
+	if report.Total() != 0 {
+		t.Errorf("Expected no orphans while the users table is empty, got %s", report)
+	}
+}
@@ -13,38 +13,53 @@ const (
 	RoleUser      Role = "user"
 	RoleAssistant Role = "assistant"
 	RoleSystem    Role = "system"
+	RoleTool      Role = "tool"
 )
 
 type Message struct {
-	ID        string    `json:"id"`
-	SessionID string    `json:"session_id"`
-	Role      Role      `json:"role"`
-	Content   string    `json:"content"`
+	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
+	Role      Role   `json:"role"`
+	Content   string `json:"content"`
+	// Pinned messages are kept through cleanup and compaction.
+	Pinned bool `json:"pinned,omitempty"`
+	// Model and Provider record which model produced an assistant message.
+	Model     string    `json:"model,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
 func (s *Store) AddMessage(sessionID string, role Role, content string) (*Message, error) {
-	id := uuid.New().String()
-	now := time.Now().UTC()
+	return s.addMessage(&Message{SessionID: sessionID, Role: role, Content: content})
+}
 
-	msg := &Message{
-		ID:        id,
+// AddAssistantMessage adds an assistant message along with the model and
+// provider that produced it.
+func (s *Store) AddAssistantMessage(sessionID, content, model, provider string) (*Message, error) {
+	return s.addMessage(&Message{
 		SessionID: sessionID,
-		Role:      role,
+		Role:      RoleAssistant,
 		Content:   content,
-		CreatedAt: now,
-	}
+		Model:     model,
+		Provider:  provider,
+	})
+}
 
-	query := `INSERT INTO messages (id, session_id, role, content, created_at) VALUES (?, ?, ?, ?, ?)`
-	_, err := s.DB.Exec(query, msg.ID, msg.SessionID, msg.Role, msg.Content, msg.CreatedAt)
+func (s *Store) addMessage(msg *Message) (*Message, error) {
+	now := time.Now().UTC()
+	msg.ID = uuid.New().String()
+	msg.CreatedAt = now
+
+	query := `INSERT INTO messages (id, session_id, role, content, model, provider, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+	_, err := s.DB.Exec(query, msg.ID, msg.SessionID, msg.Role, msg.Content, msg.Model, msg.Provider, msg.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 
-	_, _ = s.DB.Exec(`UPDATE sessions SET updated_at = ? WHERE id = ?`, now, sessionID)
+	_, _ = s.DB.Exec(`UPDATE sessions SET updated_at = ? WHERE id = ?`, now, msg.SessionID)
 
 	if s.maxMessages > 0 {
-		go s.CleanupOldMessages(sessionID)
+		go s.CleanupOldMessages(msg.SessionID)
 	}
 
 	return msg, nil
@@ -54,20 +69,23 @@ func (s *Store) GetMessages(sessionID string) ([]*Message, error) {
 	return s.GetMessagesWithLimit(sessionID, s.maxMessages)
 }
 
+// GetMessagesWithLimit returns the last limit messages of a session, oldest
+// first, plus any pinned messages older than that window.
 func (s *Store) GetMessagesWithLimit(sessionID string, limit int) ([]*Message, error) {
 	var rows *sql.Rows
 	var err error
 
 	if limit > 0 {
-		query := `SELECT id, session_id, role, content, created_at FROM (
-			SELECT * FROM messages 
-			WHERE session_id = ? 
-			ORDER BY created_at DESC 
-			LIMIT ?
-		) ORDER BY created_at ASC`
-		rows, err = s.DB.Query(query, sessionID, limit)
+		query := `SELECT id, session_id, role, content, pinned, COALESCE(model, ''), COALESCE(provider, ''), created_at FROM messages
+			WHERE session_id = ? AND (pinned = 1 OR id IN (
+				SELECT id FROM messages
+				WHERE session_id = ?
+				ORDER BY created_at DESC
+				LIMIT ?
+			)) ORDER BY created_at ASC`
+		rows, err = s.DB.Query(query, sessionID, sessionID, limit)
 	} else {
-		query := `SELECT id, session_id, role, content, created_at FROM messages 
+		query := `SELECT id, session_id, role, content, pinned, COALESCE(model, ''), COALESCE(provider, ''), created_at FROM messages 
 			WHERE session_id = ? ORDER BY created_at ASC`
 		rows, err = s.DB.Query(query, sessionID)
 	}
@@ -80,7 +98,7 @@ func (s *Store) GetMessagesWithLimit(sessionID string, limit int) ([]*Message, e
 	var messages []*Message
 	for rows.Next() {
 		msg := &Message{}
-		if err := rows.Scan(&msg.ID, &msg.SessionID, &msg.Role, &msg.Content, &msg.CreatedAt); err != nil {
+		if err := rows.Scan(&msg.ID, &msg.SessionID, &msg.Role, &msg.Content, &msg.Pinned, &msg.Model, &msg.Provider, &msg.CreatedAt); err != nil {
 			return nil, err
 		}
 		messages = append(messages, msg)
@@ -94,3 +112,20 @@ func (s *Store) GetRecentMessages(sessionID string, n int) ([]*Message, error) {
 	}
 	return s.GetMessagesWithLimit(sessionID, n)
 }
+
+// SetMessagePinned pins or unpins a message in a session. It returns
+// sql.ErrNoRows if the session has no such message.
+func (s *Store) SetMessagePinned(sessionID, messageID string, pinned bool) error {
+	res, err := s.DB.Exec(`UPDATE messages SET pinned = ? WHERE id = ? AND session_id = ?`, pinned, messageID, sessionID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"pryx-core/internal/channels"
+)
+
+// SaveOutbox inserts or updates an undelivered outbound channel message.
+func (s *Store) SaveOutbox(entry channels.OutboxEntry) error {
+	message, err := json.Marshal(entry.Message)
+	if err != nil {
+		return err
+	}
+	var nextAttempt interface{}
+	if !entry.NextAttempt.IsZero() {
+		nextAttempt = entry.NextAttempt.UTC()
+	}
+	_, err = s.DB.Exec(
+		`INSERT INTO channel_outbox (id, source, message, attempts, next_attempt_at, last_error, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET attempts = excluded.attempts, next_attempt_at = excluded.next_attempt_at, last_error = excluded.last_error`,
+		entry.ID, entry.Message.Source, string(message), entry.Attempts, nextAttempt, entry.LastError, entry.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// DeleteOutbox removes a delivered or dropped outbound message.
+func (s *Store) DeleteOutbox(id string) error {
+	_, err := s.DB.Exec(`DELETE FROM channel_outbox WHERE id = ?`, id)
+	return err
+}
+
+// LoadOutbox returns the undelivered outbound messages, oldest first.
+func (s *Store) LoadOutbox() ([]channels.OutboxEntry, error) {
+	rows, err := s.DB.Query(
+		`SELECT id, message, attempts, next_attempt_at, COALESCE(last_error, ''), created_at
+		 FROM channel_outbox ORDER BY created_at, rowid`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []channels.OutboxEntry
+	for rows.Next() {
+		var entry channels.OutboxEntry
+		var message string
+		var nextAttempt sql.NullTime
+		if err := rows.Scan(&entry.ID, &message, &entry.Attempts, &nextAttempt, &entry.LastError, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(message), &entry.Message); err != nil {
+			return nil, err
+		}
+		if nextAttempt.Valid {
+			entry.NextAttempt = nextAttempt.Time
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+var _ channels.OutboxStore = (*Store)(nil)
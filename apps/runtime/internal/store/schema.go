@@ -154,7 +154,12 @@ CREATE TABLE IF NOT EXISTS scheduled_tasks (
     run_count INTEGER DEFAULT 0,
     user_id TEXT,
     created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    max_retries INTEGER NOT NULL DEFAULT 0,
+    retry_backoff TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'active',
+    catch_up INTEGER NOT NULL DEFAULT 0,
+    depends_on TEXT NOT NULL DEFAULT ''
 );
 
 CREATE INDEX IF NOT EXISTS idx_scheduled_tasks_enabled ON scheduled_tasks(enabled);
@@ -168,11 +173,75 @@ CREATE TABLE IF NOT EXISTS scheduled_task_runs (
     started_at DATETIME NOT NULL,
     completed_at DATETIME,
     status TEXT NOT NULL,
+    run_trigger TEXT,
     error TEXT,
     output TEXT,
+    attempt INTEGER NOT NULL DEFAULT 1,
     FOREIGN KEY (task_id) REFERENCES scheduled_tasks(id) ON DELETE CASCADE
 );
 
 CREATE INDEX IF NOT EXISTS idx_task_runs_task ON scheduled_task_runs(task_id);
 CREATE INDEX IF NOT EXISTS idx_task_runs_started ON scheduled_task_runs(started_at DESC);
+
+-- Run locks for runtimes sharing one database (opt-in via scheduler_locking).
+-- run_window and expires_at are unix seconds.
+CREATE TABLE IF NOT EXISTS scheduler_locks (
+    task_id TEXT PRIMARY KEY,
+    run_window INTEGER NOT NULL,
+    owner TEXT NOT NULL,
+    expires_at INTEGER NOT NULL,
+    released INTEGER NOT NULL DEFAULT 0
+);
+
+-- Channel conversations that have received the greeting
+CREATE TABLE IF NOT EXISTS channel_greetings (
+    source TEXT NOT NULL,
+    conversation_id TEXT NOT NULL,
+    greeted_at DATETIME NOT NULL,
+    PRIMARY KEY (source, conversation_id)
+);
+
+-- Outbound channel messages waiting for delivery
+CREATE TABLE IF NOT EXISTS channel_outbox (
+    id TEXT PRIMARY KEY,
+    source TEXT NOT NULL,
+    message TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME,
+    last_error TEXT,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_channel_outbox_created_at ON channel_outbox(created_at);
+
+-- Messages received and delivered by channels, pruned by age and per-channel count
+CREATE TABLE IF NOT EXISTS channel_activity (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    channel_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    conversation_id TEXT,
+    sender_id TEXT,
+    sender TEXT,
+    snippet TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_channel_activity_channel ON channel_activity(channel_id, created_at);
+
+-- Idempotency keys for session creation: a retried create with the same key
+-- returns the session made the first time.
+CREATE TABLE IF NOT EXISTS session_idempotency_keys (
+    idempotency_key TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_session_idempotency_created_at ON session_idempotency_keys(created_at);
+
+-- Per-skill execution counters
+CREATE TABLE IF NOT EXISTS skill_stats (
+    skill_id TEXT PRIMARY KEY,
+    run_count INTEGER NOT NULL DEFAULT 0,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    total_duration_ms INTEGER NOT NULL DEFAULT 0,
+    last_run_at DATETIME,
+    last_error TEXT
+);
 `
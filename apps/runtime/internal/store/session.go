@@ -8,10 +8,12 @@ import (
 )
 
 type Session struct {
-	ID        string    `json:"id"`
-	Title     string    `json:"title"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Timezone    string    `json:"timezone,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 func (s *Store) CreateSession(title string) (*Session, error) {
@@ -36,8 +38,8 @@ func (s *Store) CreateSession(title string) (*Session, error) {
 
 func (s *Store) GetSession(id string) (*Session, error) {
 	sess := &Session{}
-	query := `SELECT id, title, created_at, updated_at FROM sessions WHERE id = ?`
-	err := s.DB.QueryRow(query, id).Scan(&sess.ID, &sess.Title, &sess.CreatedAt, &sess.UpdatedAt)
+	query := `SELECT id, title, COALESCE(description, ''), COALESCE(timezone, ''), created_at, updated_at FROM sessions WHERE id = ?`
+	err := s.DB.QueryRow(query, id).Scan(&sess.ID, &sess.Title, &sess.Description, &sess.Timezone, &sess.CreatedAt, &sess.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +47,7 @@ func (s *Store) GetSession(id string) (*Session, error) {
 }
 
 func (s *Store) ListSessions() ([]*Session, error) {
-	query := `SELECT id, title, created_at, updated_at FROM sessions ORDER BY updated_at DESC LIMIT 100` // Cap for now
+	query := `SELECT id, title, COALESCE(description, ''), COALESCE(timezone, ''), created_at, updated_at FROM sessions ORDER BY updated_at DESC LIMIT 100` // Cap for now
 	rows, err := s.DB.Query(query)
 	if err != nil {
 		return nil, err
@@ -55,7 +57,7 @@ func (s *Store) ListSessions() ([]*Session, error) {
 	var sessions []*Session
 	for rows.Next() {
 		sess := &Session{}
-		if err := rows.Scan(&sess.ID, &sess.Title, &sess.CreatedAt, &sess.UpdatedAt); err != nil {
+		if err := rows.Scan(&sess.ID, &sess.Title, &sess.Description, &sess.Timezone, &sess.CreatedAt, &sess.UpdatedAt); err != nil {
 			return nil, err
 		}
 		sessions = append(sessions, sess)
@@ -87,21 +89,57 @@ func (s *Store) EnsureSession(id string, title string) (*Session, error) {
 	return s.GetSession(id)
 }
 
-func (s *Store) DeleteSession(id string) error {
-	if id == "" {
+// SetSessionDescription sets the description of a session, such as a generated summary.
+func (s *Store) SetSessionDescription(id string, description string) error {
+	res, err := s.DB.Exec(`UPDATE sessions SET description = ?, updated_at = ? WHERE id = ?`, description, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
 		return sql.ErrNoRows
 	}
-	tx, err := s.DB.Begin()
+	return nil
+}
+
+// SetSessionTimezone sets the IANA zone used for the session's timestamps and
+// reminders. An empty timezone clears the override.
+func (s *Store) SetSessionTimezone(id string, timezone string) error {
+	res, err := s.DB.Exec(`UPDATE sessions SET timezone = NULLIF(?, ''), updated_at = ? WHERE id = ?`, timezone, time.Now().UTC(), id)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
 
-	if _, err := tx.Exec(`DELETE FROM messages WHERE session_id = ?`, id); err != nil {
+// SetSessionModel sets the model used for the session's requests that do not
+// name one. An empty model clears the override.
+func (s *Store) SetSessionModel(id string, model string) error {
+	res, err := s.DB.Exec(`UPDATE sessions SET model = NULLIF(?, ''), updated_at = ? WHERE id = ?`, model, time.Now().UTC(), id)
+	if err != nil {
 		return err
 	}
-	if _, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, id); err != nil {
-		return err
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSessionModel returns the session's model override, or "" if it has none.
+// It returns sql.ErrNoRows if the session does not exist.
+func (s *Store) GetSessionModel(id string) (string, error) {
+	var model string
+	err := s.DB.QueryRow(`SELECT COALESCE(model, '') FROM sessions WHERE id = ?`, id).Scan(&model)
+	return model, err
+}
+
+func (s *Store) DeleteSession(id string) error {
+	if id == "" {
+		return sql.ErrNoRows
 	}
-	return tx.Commit()
+	return s.WithTx(func(tx *Tx) error {
+		return tx.DeleteSession(id)
+	})
 }
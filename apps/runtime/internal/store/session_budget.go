@@ -0,0 +1,100 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SessionBudget caps the tokens and cost a session may use. A nil limit is
+// unlimited.
+type SessionBudget struct {
+	MaxCost   *float64 `json:"max_cost,omitempty"`
+	MaxTokens *int64   `json:"max_tokens,omitempty"`
+}
+
+// Empty reports whether the budget sets no limit.
+func (b SessionBudget) Empty() bool {
+	return b.MaxCost == nil && b.MaxTokens == nil
+}
+
+// SessionBudgetExceededError is returned by CheckSessionBudget when a session
+// has used up one of its limits.
+type SessionBudgetExceededError struct {
+	SessionID string
+	// Limit is the exhausted limit, "max_cost" or "max_tokens".
+	Limit string
+	Used  float64
+	Max   float64
+}
+
+func (e *SessionBudgetExceededError) Error() string {
+	return fmt.Sprintf("session %s exceeded its %s budget (%g of %g)", e.SessionID, e.Limit, e.Used, e.Max)
+}
+
+// SetSessionBudget replaces the limits of a session. It returns sql.ErrNoRows
+// if the session does not exist.
+func (s *Store) SetSessionBudget(id string, budget SessionBudget) error {
+	res, err := s.DB.Exec(`UPDATE sessions SET max_cost = ?, max_tokens = ? WHERE id = ?`, budget.MaxCost, budget.MaxTokens, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetSessionBudget returns the limits of a session. It returns sql.ErrNoRows
+// if the session does not exist.
+func (s *Store) GetSessionBudget(id string) (SessionBudget, error) {
+	var (
+		budget    SessionBudget
+		maxCost   sql.NullFloat64
+		maxTokens sql.NullInt64
+	)
+	if err := s.DB.QueryRow(`SELECT max_cost, max_tokens FROM sessions WHERE id = ?`, id).Scan(&maxCost, &maxTokens); err != nil {
+		return budget, err
+	}
+	if maxCost.Valid {
+		budget.MaxCost = &maxCost.Float64
+	}
+	if maxTokens.Valid {
+		budget.MaxTokens = &maxTokens.Int64
+	}
+	return budget, nil
+}
+
+// CheckSessionBudget compares a session's accumulated usage, as recorded in
+// the audit log, with its limits. It returns a *SessionBudgetExceededError once
+// a limit is reached, and nil for sessions without limits or that do not exist.
+func (s *Store) CheckSessionBudget(id string) error {
+	budget, err := s.GetSessionBudget(id)
+	if err == sql.ErrNoRows || (err == nil && budget.Empty()) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// cost holds the JSON-encoded audit.CostInfo, or "null" for entries without one.
+	var tokens int64
+	var cost float64
+	err = s.DB.QueryRow(`
+		SELECT
+			COALESCE(SUM(json_extract(cost, '$.total_tokens')), 0),
+			COALESCE(SUM(json_extract(cost, '$.total_cost')), 0.0)
+		FROM audit_log
+		WHERE session_id = ? AND json_valid(cost)
+	`, id).Scan(&tokens, &cost)
+	if err != nil {
+		return err
+	}
+
+	if budget.MaxTokens != nil && tokens >= *budget.MaxTokens {
+		return &SessionBudgetExceededError{SessionID: id, Limit: "max_tokens", Used: float64(tokens), Max: float64(*budget.MaxTokens)}
+	}
+	if budget.MaxCost != nil && cost >= *budget.MaxCost {
+		return &SessionBudgetExceededError{SessionID: id, Limit: "max_cost", Used: cost, Max: *budget.MaxCost}
+	}
+	return nil
+}
@@ -1,10 +1,8 @@
 package store
 
 import (
+	"errors"
 	"fmt"
-	"time"
-
-	"github.com/google/uuid"
 )
 
 func (s *Store) CopySession(sourceSessionID string, newTitle string) (*Session, error) {
@@ -13,40 +11,84 @@ func (s *Store) CopySession(sourceSessionID string, newTitle string) (*Session,
 		return nil, fmt.Errorf("failed to get source session: %w", err)
 	}
 
-	newSession, err := s.CreateSession(newTitle)
+	messages, err := s.GetMessages(sourceSessionID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new session: %w", err)
+		return nil, fmt.Errorf("failed to get source messages: %w", err)
 	}
 
-	messages, err := s.GetMessages(sourceSessionID)
+	return s.copyInto(newTitle, messages)
+}
+
+// ErrMessageNotInSession is returned when a message does not belong to the given session.
+var ErrMessageNotInSession = errors.New("message does not belong to session")
+
+// CopySessionUpTo copies sourceSessionID into a new session, including messages
+// up to and including messageID and ignoring everything after it.
+func (s *Store) CopySessionUpTo(sourceSessionID string, messageID string, newTitle string) (*Session, error) {
+	_, err := s.GetSession(sourceSessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source session: %w", err)
+	}
+
+	messages, err := s.GetSessionMessages(sourceSessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get source messages: %w", err)
 	}
 
-	for _, msg := range messages {
-		newMsg := &Message{
-			ID:        uuid.New().String(),
-			SessionID: newSession.ID,
-			Role:      msg.Role,
-			Content:   msg.Content,
-			CreatedAt: time.Now().UTC(),
+	cut := -1
+	for i, msg := range messages {
+		if msg.ID == messageID {
+			cut = i
+			break
 		}
+	}
+	if cut < 0 {
+		return nil, ErrMessageNotInSession
+	}
 
-		query := `INSERT INTO messages (id, session_id, role, content, created_at) VALUES (?, ?, ?, ?, ?)`
-		_, err := s.DB.Exec(query, newMsg.ID, newMsg.SessionID, newMsg.Role, newMsg.Content, newMsg.CreatedAt)
+	return s.copyInto(newTitle, messages[:cut+1])
+}
+
+// copyInto creates a session titled newTitle holding copies of messages. The
+// session and its messages are written in one transaction, so a failure leaves
+// no partial copy behind.
+func (s *Store) copyInto(newTitle string, messages []*Message) (*Session, error) {
+	var newSession *Session
+	err := s.WithTx(func(tx *Tx) error {
+		sess, err := tx.CreateSession(newTitle)
 		if err != nil {
-			return nil, fmt.Errorf("failed to copy message: %w", err)
+			return fmt.Errorf("failed to create new session: %w", err)
+		}
+		for _, msg := range messages {
+			copied, err := tx.AddMessage(sess.ID, msg.Role, msg.Content)
+			if err != nil {
+				return fmt.Errorf("failed to copy message: %w", err)
+			}
+			if msg.Pinned {
+				if _, err := tx.Exec(`UPDATE messages SET pinned = 1 WHERE id = ?`, copied.ID); err != nil {
+					return fmt.Errorf("failed to copy message pin: %w", err)
+				}
+			}
+			if msg.Model != "" || msg.Provider != "" {
+				if _, err := tx.Exec(`UPDATE messages SET model = ?, provider = ? WHERE id = ?`, msg.Model, msg.Provider, copied.ID); err != nil {
+					return fmt.Errorf("failed to copy message model: %w", err)
+				}
+			}
 		}
+		if err := tx.TouchSession(sess.ID); err != nil {
+			return err
+		}
+		newSession = sess
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	now := time.Now().UTC()
-	_, _ = s.DB.Exec(`UPDATE sessions SET updated_at = ? WHERE id = ?`, now, newSession.ID)
-
 	return newSession, nil
 }
 
 func (s *Store) GetSessionMessages(sessionID string) ([]*Message, error) {
-	query := `SELECT id, session_id, role, content, created_at FROM messages 
+	query := `SELECT id, session_id, role, content, pinned, COALESCE(model, ''), COALESCE(provider, ''), created_at FROM messages 
 		WHERE session_id = ? ORDER BY created_at ASC`
 
 	rows, err := s.DB.Query(query, sessionID)
@@ -58,7 +100,7 @@ func (s *Store) GetSessionMessages(sessionID string) ([]*Message, error) {
 	var messages []*Message
 	for rows.Next() {
 		msg := &Message{}
-		if err := rows.Scan(&msg.ID, &msg.SessionID, &msg.Role, &msg.Content, &msg.CreatedAt); err != nil {
+		if err := rows.Scan(&msg.ID, &msg.SessionID, &msg.Role, &msg.Content, &msg.Pinned, &msg.Model, &msg.Provider, &msg.CreatedAt); err != nil {
 			return nil, err
 		}
 		messages = append(messages, msg)
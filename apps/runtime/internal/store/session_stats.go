@@ -0,0 +1,161 @@
+package store
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// SessionStats summarizes the activity and spend of one session. Token,
+// cost and tool call figures come from the session's audit log entries.
+type SessionStats struct {
+	SessionID      string       `json:"session_id"`
+	MessageCount   int64        `json:"message_count"`
+	InputTokens    int64        `json:"input_tokens"`
+	OutputTokens   int64        `json:"output_tokens"`
+	TotalTokens    int64        `json:"total_tokens"`
+	TotalCost      float64      `json:"total_cost"`
+	ToolCalls      int64        `json:"tool_calls"`
+	Models         []string     `json:"models"`
+	ModelUsage     []ModelUsage `json:"model_usage"`
+	StartedAt      time.Time    `json:"started_at"`
+	LastActivityAt *time.Time   `json:"last_activity_at,omitempty"`
+	DurationMs     int64        `json:"duration_ms"`
+}
+
+// ModelUsage summarizes what one model contributed to a session. Messages
+// counts the assistant messages it produced; token and cost figures come
+// from the audit log entries attributed to it.
+type ModelUsage struct {
+	Model        string  `json:"model"`
+	Provider     string  `json:"provider,omitempty"`
+	Messages     int64   `json:"messages"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	TotalCost    float64 `json:"total_cost"`
+}
+
+// GetSessionStats aggregates a session's messages and audit log entries. Both
+// queries are served by the session_id indexes. It returns sql.ErrNoRows if
+// the session does not exist.
+func (s *Store) GetSessionStats(sessionID string) (*SessionStats, error) {
+	sess, err := s.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	stats := &SessionStats{SessionID: sess.ID, StartedAt: sess.CreatedAt, Models: []string{}, ModelUsage: []ModelUsage{}}
+
+	if err := s.DB.QueryRow(`SELECT COUNT(*) FROM messages WHERE session_id = ?`, sessionID).Scan(&stats.MessageCount); err != nil {
+		return nil, err
+	}
+	var last time.Time
+	err = s.DB.QueryRow(`SELECT created_at FROM messages WHERE session_id = ? ORDER BY created_at DESC LIMIT 1`, sessionID).Scan(&last)
+	switch {
+	case err == nil:
+		stats.LastActivityAt = &last
+		if d := last.Sub(sess.CreatedAt); d > 0 {
+			stats.DurationMs = d.Milliseconds()
+		}
+	case err != sql.ErrNoRows:
+		return nil, err
+	}
+
+	// cost holds the JSON-encoded audit.CostInfo, or "null" for entries without one.
+	err = s.DB.QueryRow(`
+		SELECT
+			COALESCE(SUM(json_extract(cost, '$.input_tokens')), 0),
+			COALESCE(SUM(json_extract(cost, '$.output_tokens')), 0),
+			COALESCE(SUM(json_extract(cost, '$.total_tokens')), 0),
+			COALESCE(SUM(json_extract(cost, '$.total_cost')), 0.0),
+			COALESCE(SUM(CASE WHEN action IN ('tool.complete', 'tool.error') THEN 1 ELSE 0 END), 0)
+		FROM (
+			SELECT action, CASE WHEN json_valid(cost) THEN cost END AS cost
+			FROM audit_log WHERE session_id = ?
+		)
+	`, sessionID).Scan(&stats.InputTokens, &stats.OutputTokens, &stats.TotalTokens, &stats.TotalCost, &stats.ToolCalls)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.collectModelUsage(stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// collectModelUsage fills in stats.ModelUsage and stats.Models from the
+// session's assistant messages and audit log entries.
+func (s *Store) collectModelUsage(stats *SessionStats) error {
+	byModel := make(map[string]*ModelUsage)
+	usage := func(model string) *ModelUsage {
+		u, ok := byModel[model]
+		if !ok {
+			u = &ModelUsage{Model: model}
+			byModel[model] = u
+		}
+		return u
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT model, COALESCE(MAX(provider), ''), COUNT(*)
+		FROM messages
+		WHERE session_id = ? AND role = ? AND COALESCE(model, '') != ''
+		GROUP BY model
+	`, stats.SessionID, RoleAssistant)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var model, provider string
+		var count int64
+		if err := rows.Scan(&model, &provider, &count); err != nil {
+			rows.Close()
+			return err
+		}
+		u := usage(model)
+		u.Provider = provider
+		u.Messages = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = s.DB.Query(`
+		SELECT
+			json_extract(cost, '$.model'),
+			COALESCE(SUM(json_extract(cost, '$.input_tokens')), 0),
+			COALESCE(SUM(json_extract(cost, '$.output_tokens')), 0),
+			COALESCE(SUM(json_extract(cost, '$.total_cost')), 0.0)
+		FROM audit_log
+		WHERE session_id = ? AND json_valid(cost) AND COALESCE(json_extract(cost, '$.model'), '') != ''
+		GROUP BY json_extract(cost, '$.model')
+	`, stats.SessionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var model string
+		var input, output int64
+		var cost float64
+		if err := rows.Scan(&model, &input, &output, &cost); err != nil {
+			return err
+		}
+		u := usage(model)
+		u.InputTokens, u.OutputTokens, u.TotalCost = input, output, cost
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for model, u := range byModel {
+		stats.Models = append(stats.Models, model)
+		stats.ModelUsage = append(stats.ModelUsage, *u)
+	}
+	sort.Strings(stats.Models)
+	sort.Slice(stats.ModelUsage, func(i, j int) bool {
+		return stats.ModelUsage[i].Model < stats.ModelUsage[j].Model
+	})
+	return nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// SkillStats aggregates the executions of one skill.
+type SkillStats struct {
+	SkillID         string     `json:"skill_id"`
+	RunCount        int64      `json:"run_count"`
+	FailureCount    int64      `json:"failure_count"`
+	TotalDurationMs int64      `json:"total_duration_ms"`
+	AvgDurationMs   int64      `json:"avg_duration_ms"`
+	FailureRate     float64    `json:"failure_rate"`
+	LastRunAt       *time.Time `json:"last_run_at,omitempty"`
+	LastError       string     `json:"last_error,omitempty"`
+}
+
+// RecordSkillRun adds one execution to a skill's stats. A non-empty errMsg counts
+// as a failure and is kept as the last error until the next failure.
+func (s *Store) RecordSkillRun(skillID string, duration time.Duration, errMsg string) error {
+	failed := 0
+	if errMsg != "" {
+		failed = 1
+	}
+	_, err := s.DB.Exec(`
+		INSERT INTO skill_stats (skill_id, run_count, failure_count, total_duration_ms, last_run_at, last_error)
+		VALUES (?, 1, ?, ?, ?, ?)
+		ON CONFLICT(skill_id) DO UPDATE SET
+			run_count = run_count + 1,
+			failure_count = failure_count + excluded.failure_count,
+			total_duration_ms = total_duration_ms + excluded.total_duration_ms,
+			last_run_at = excluded.last_run_at,
+			last_error = CASE WHEN excluded.failure_count > 0 THEN excluded.last_error ELSE last_error END
+	`, skillID, failed, duration.Milliseconds(), time.Now().UTC(), errMsg)
+	return err
+}
+
+// GetSkillStats returns a skill's stats, or zero stats if it has never run.
+func (s *Store) GetSkillStats(skillID string) (*SkillStats, error) {
+	row := s.DB.QueryRow(`
+		SELECT skill_id, run_count, failure_count, total_duration_ms, last_run_at, COALESCE(last_error, '')
+		FROM skill_stats WHERE skill_id = ?
+	`, skillID)
+	st, err := scanSkillStats(row)
+	if err == sql.ErrNoRows {
+		return &SkillStats{SkillID: skillID}, nil
+	}
+	return st, err
+}
+
+// ListSkillStats returns stats for every skill that has run, most used first.
+func (s *Store) ListSkillStats() ([]*SkillStats, error) {
+	rows, err := s.DB.Query(`
+		SELECT skill_id, run_count, failure_count, total_duration_ms, last_run_at, COALESCE(last_error, '')
+		FROM skill_stats ORDER BY run_count DESC, skill_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*SkillStats
+	for rows.Next() {
+		st, err := scanSkillStats(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, st)
+	}
+	return out, rows.Err()
+}
+
+func scanSkillStats(row interface{ Scan(...interface{}) error }) (*SkillStats, error) {
+	var st SkillStats
+	var lastRun sql.NullTime
+	if err := row.Scan(&st.SkillID, &st.RunCount, &st.FailureCount, &st.TotalDurationMs, &lastRun, &st.LastError); err != nil {
+		return nil, err
+	}
+	if lastRun.Valid {
+		st.LastRunAt = &lastRun.Time
+	}
+	if st.RunCount > 0 {
+		st.AvgDurationMs = st.TotalDurationMs / st.RunCount
+		st.FailureRate = float64(st.FailureCount) / float64(st.RunCount)
+	}
+	return &st, nil
+}
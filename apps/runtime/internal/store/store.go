@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -97,7 +98,8 @@ func (s *Store) loadMaxMessages() int {
 	return DefaultMaxMessagesPerSession
 }
 
-// CleanupOldMessages removes excess messages for a session
+// CleanupOldMessages removes the oldest unpinned messages of a session beyond
+// the message limit. Pinned messages are never removed.
 func (s *Store) CleanupOldMessages(sessionID string) error {
 	if s.maxMessages <= 0 {
 		return nil
@@ -119,7 +121,7 @@ func (s *Store) CleanupOldMessages(sessionID string) error {
 		WHERE session_id = ? 
 		AND id IN (
 			SELECT id FROM messages 
-			WHERE session_id = ? 
+			WHERE session_id = ? AND pinned = 0
 			ORDER BY created_at ASC 
 			LIMIT ?
 		)`
@@ -142,6 +144,31 @@ func (s *Store) migrate() error {
 		return err
 	}
 
+	// Add columns introduced after the initial schema. SQLite has no
+	// ADD COLUMN IF NOT EXISTS, so "duplicate column" errors are ignored.
+	columns := []string{
+		`ALTER TABLE sessions ADD COLUMN description TEXT`,
+		`ALTER TABLE sessions ADD COLUMN timezone TEXT`,
+		`ALTER TABLE messages ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE scheduled_task_runs ADD COLUMN run_trigger TEXT`,
+		`ALTER TABLE scheduled_tasks ADD COLUMN max_retries INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE scheduled_tasks ADD COLUMN retry_backoff TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE scheduled_tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'active'`,
+		`ALTER TABLE scheduled_task_runs ADD COLUMN attempt INTEGER NOT NULL DEFAULT 1`,
+		`ALTER TABLE scheduled_tasks ADD COLUMN catch_up INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE scheduled_tasks ADD COLUMN depends_on TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE sessions ADD COLUMN max_cost REAL`,
+		`ALTER TABLE sessions ADD COLUMN max_tokens INTEGER`,
+		`ALTER TABLE messages ADD COLUMN model TEXT`,
+		`ALTER TABLE messages ADD COLUMN provider TEXT`,
+		`ALTER TABLE sessions ADD COLUMN model TEXT`,
+	}
+	for _, col := range columns {
+		if _, err := s.DB.Exec(col); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			return err
+		}
+	}
+
 	// Create indexes for better query performance
 	indexes := []string{
 		`CREATE INDEX IF NOT EXISTS idx_sessions_updated_at ON sessions(updated_at DESC)`,
@@ -154,5 +181,38 @@ func (s *Store) migrate() error {
 		}
 	}
 
+	// Record how many column migrations the database has seen as its schema version.
+	if _, err := s.DB.Exec(fmt.Sprintf("PRAGMA user_version = %d", len(columns))); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// SchemaInfo describes the database schema.
+type SchemaInfo struct {
+	// Version counts the column migrations applied on top of the initial schema.
+	Version int      `json:"version"`
+	Tables  []string `json:"tables"`
+}
+
+// SchemaInfo reports the schema version and table names.
+func (s *Store) SchemaInfo() (SchemaInfo, error) {
+	var info SchemaInfo
+	if err := s.DB.QueryRow("PRAGMA user_version").Scan(&info.Version); err != nil {
+		return info, err
+	}
+	rows, err := s.DB.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return info, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return info, err
+		}
+		info.Tables = append(info.Tables, name)
+	}
+	return info, rows.Err()
+}
@@ -1,8 +1,16 @@
 package store
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"pryx-core/internal/channels"
 )
 
 func TestStore(t *testing.T) {
@@ -64,3 +72,585 @@ func TestStore(t *testing.T) {
 		t.Errorf("Expected at least one session")
 	}
 }
+
+func TestSetSessionDescription(t *testing.T) {
+	dbPath := t.TempDir() + "/pryx.db"
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	sess, err := s.CreateSession("Described")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if err := s.SetSessionDescription(sess.ID, "A short summary"); err != nil {
+		t.Fatalf("Failed to set description: %v", err)
+	}
+	if err := s.SetSessionDescription("missing", "x"); err == nil {
+		t.Errorf("Expected error for unknown session")
+	}
+	s.Close()
+
+	// Reopening runs the migration again and must keep existing data.
+	s, err = New(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to reopen store: %v", err)
+	}
+	defer s.Close()
+
+	fetched, err := s.GetSession(sess.ID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if fetched.Description != "A short summary" {
+		t.Errorf("Expected description 'A short summary', got '%s'", fetched.Description)
+	}
+}
+
+func TestSetSessionTimezone(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	sess, err := s.CreateSession("Zoned")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if err := s.SetSessionTimezone(sess.ID, "Asia/Tokyo"); err != nil {
+		t.Fatalf("Failed to set timezone: %v", err)
+	}
+	fetched, err := s.GetSession(sess.ID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if fetched.Timezone != "Asia/Tokyo" {
+		t.Errorf("Expected timezone 'Asia/Tokyo', got '%s'", fetched.Timezone)
+	}
+
+	if err := s.SetSessionTimezone(sess.ID, ""); err != nil {
+		t.Fatalf("Failed to clear timezone: %v", err)
+	}
+	sessions, err := s.ListSessions()
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("ListSessions() = %v, %v", sessions, err)
+	}
+	if sessions[0].Timezone != "" {
+		t.Errorf("Expected cleared timezone, got '%s'", sessions[0].Timezone)
+	}
+	if err := s.SetSessionTimezone("missing", "UTC"); err == nil {
+		t.Errorf("Expected error for unknown session")
+	}
+}
+
+func TestSetSessionModel(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	sess, err := s.CreateSession("Pinned")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if err := s.SetSessionModel(sess.ID, "gpt-4o-mini"); err != nil {
+		t.Fatalf("Failed to set model: %v", err)
+	}
+	if model, err := s.GetSessionModel(sess.ID); err != nil || model != "gpt-4o-mini" {
+		t.Errorf("GetSessionModel() = %q, %v", model, err)
+	}
+	if err := s.SetSessionModel(sess.ID, ""); err != nil {
+		t.Fatalf("Failed to clear model: %v", err)
+	}
+	if model, err := s.GetSessionModel(sess.ID); err != nil || model != "" {
+		t.Errorf("Expected cleared model, got %q, %v", model, err)
+	}
+	if err := s.SetSessionModel("missing", "gpt-4o"); err == nil {
+		t.Errorf("Expected error for unknown session")
+	}
+}
+
+func TestCreateSessionIdempotent(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	first, created, err := s.CreateSessionIdempotent("key-1", "First", time.Hour)
+	if err != nil || !created {
+		t.Fatalf("CreateSessionIdempotent() = %v, %v, %v", first, created, err)
+	}
+	again, created, err := s.CreateSessionIdempotent("key-1", "Retry", time.Hour)
+	if err != nil || created {
+		t.Fatalf("retry = %v, %v, %v; want existing session", again, created, err)
+	}
+	if again.ID != first.ID || again.Title != "First" {
+		t.Errorf("Expected retry to return session %s, got %s (%s)", first.ID, again.ID, again.Title)
+	}
+
+	other, created, err := s.CreateSessionIdempotent("key-2", "Other", time.Hour)
+	if err != nil || !created || other.ID == first.ID {
+		t.Fatalf("different key = %v, %v, %v; want a new session", other, created, err)
+	}
+
+	// An expired key no longer maps to its session.
+	if _, err := s.DB.Exec(`UPDATE session_idempotency_keys SET created_at = ? WHERE idempotency_key = ?`,
+		time.Now().UTC().Add(-2*time.Hour), "key-1"); err != nil {
+		t.Fatalf("Failed to age key: %v", err)
+	}
+	fresh, created, err := s.CreateSessionIdempotent("key-1", "Fresh", time.Hour)
+	if err != nil || !created || fresh.ID == first.ID {
+		t.Fatalf("expired key = %v, %v, %v; want a new session", fresh, created, err)
+	}
+
+	// Deleting the session frees its key.
+	if err := s.DeleteSession(fresh.ID); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if _, created, err := s.CreateSessionIdempotent("key-1", "After delete", time.Hour); err != nil || !created {
+		t.Fatalf("key of deleted session: created=%v, err=%v; want a new session", created, err)
+	}
+}
+
+func TestPinnedMessagesSurviveCleanup(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+	s.maxMessages = 0 // cleanup is run by hand below
+
+	sess, _ := s.CreateSession("Pins")
+	pinned, _ := s.AddMessage(sess.ID, RoleUser, "Always answer in French.")
+	for i := 0; i < 4; i++ {
+		time.Sleep(time.Millisecond)
+		_, _ = s.AddMessage(sess.ID, RoleUser, "filler")
+	}
+	if err := s.SetMessagePinned(sess.ID, pinned.ID, true); err != nil {
+		t.Fatalf("Failed to pin message: %v", err)
+	}
+	if err := s.SetMessagePinned("other-session", pinned.ID, true); err != sql.ErrNoRows {
+		t.Errorf("Expected sql.ErrNoRows for a message outside the session, got %v", err)
+	}
+
+	window, err := s.GetMessagesWithLimit(sess.ID, 2)
+	if err != nil {
+		t.Fatalf("Failed to get messages: %v", err)
+	}
+	if len(window) != 3 || window[0].ID != pinned.ID || !window[0].Pinned {
+		t.Fatalf("Expected the pinned message plus the last 2, got %d messages", len(window))
+	}
+
+	s.maxMessages = 2
+	if err := s.CleanupOldMessages(sess.ID); err != nil {
+		t.Fatalf("Cleanup failed: %v", err)
+	}
+	all, _ := s.GetMessagesWithLimit(sess.ID, 0)
+	if len(all) != 2 || all[0].ID != pinned.ID {
+		t.Fatalf("Expected cleanup to keep the pinned message and 1 other, got %d messages", len(all))
+	}
+
+	if err := s.SetMessagePinned(sess.ID, pinned.ID, false); err != nil {
+		t.Fatalf("Failed to unpin message: %v", err)
+	}
+	all, _ = s.GetMessagesWithLimit(sess.ID, 0)
+	if all[0].Pinned {
+		t.Errorf("Expected the message to be unpinned")
+	}
+}
+
+func TestMarkGreeted(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	first, err := s.MarkGreeted("telegram-main", "42")
+	if err != nil || !first {
+		t.Fatalf("Expected first greeting, got %v, %v", first, err)
+	}
+	again, err := s.MarkGreeted("telegram-main", "42")
+	if err != nil || again {
+		t.Fatalf("Expected repeat to be reported as already greeted, got %v, %v", again, err)
+	}
+	other, err := s.MarkGreeted("slack-main", "42")
+	if err != nil || !other {
+		t.Fatalf("Expected conversations to be tracked per channel, got %v, %v", other, err)
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	created := time.Now().UTC().Truncate(time.Second)
+	first := channels.OutboxEntry{
+		ID:        "m1",
+		Message:   channels.Message{Source: "telegram-main", ChannelID: "42", Content: "hello"},
+		CreatedAt: created,
+	}
+	second := channels.OutboxEntry{
+		ID:        "m2",
+		Message:   channels.Message{Source: "telegram-main", ChannelID: "42", Content: "world"},
+		CreatedAt: created.Add(time.Second),
+	}
+	for _, entry := range []channels.OutboxEntry{first, second} {
+		if err := s.SaveOutbox(entry); err != nil {
+			t.Fatalf("SaveOutbox failed: %v", err)
+		}
+	}
+
+	first.Attempts = 2
+	first.LastError = "rate limited"
+	first.NextAttempt = created.Add(time.Minute)
+	if err := s.SaveOutbox(first); err != nil {
+		t.Fatalf("SaveOutbox update failed: %v", err)
+	}
+
+	entries, err := s.LoadOutbox()
+	if err != nil {
+		t.Fatalf("LoadOutbox failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "m1" || entries[1].ID != "m2" {
+		t.Fatalf("Expected both entries oldest first, got %+v", entries)
+	}
+	got := entries[0]
+	if got.Attempts != 2 || got.LastError != "rate limited" || !got.NextAttempt.Equal(first.NextAttempt) || got.Message.Content != "hello" || got.Message.ChannelID != "42" {
+		t.Errorf("Unexpected entry after update: %+v", got)
+	}
+	if !entries[1].NextAttempt.IsZero() {
+		t.Errorf("Expected no next attempt for a fresh entry, got %v", entries[1].NextAttempt)
+	}
+
+	if err := s.DeleteOutbox("m1"); err != nil {
+		t.Fatalf("DeleteOutbox failed: %v", err)
+	}
+	entries, err = s.LoadOutbox()
+	if err != nil || len(entries) != 1 || entries[0].ID != "m2" {
+		t.Fatalf("Expected only m2 to remain, got %+v, %v", entries, err)
+	}
+}
+
+func TestChannelActivity(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	now := time.Now().UTC()
+	record := func(channel, snippet string, at time.Time) {
+		t.Helper()
+		if err := s.RecordChannelActivity(channels.Activity{ChannelID: channel, EventType: channels.ActivityInbound, SenderID: "42", Snippet: snippet, CreatedAt: at}); err != nil {
+			t.Fatalf("RecordChannelActivity failed: %v", err)
+		}
+	}
+	record("telegram-main", "stale", now.Add(-48*time.Hour))
+	for i := 0; i < 3; i++ {
+		record("telegram-main", fmt.Sprintf("msg %d", i), now.Add(time.Duration(i)*time.Minute))
+	}
+	record("slack-main", "other", now)
+
+	entries, err := s.ListChannelActivity("telegram-main", 2, 0)
+	if err != nil {
+		t.Fatalf("ListChannelActivity failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Snippet != "msg 2" || entries[1].Snippet != "msg 1" || entries[0].SenderID != "42" {
+		t.Fatalf("Expected the two newest entries, got %+v", entries)
+	}
+
+	removed, err := s.PruneChannelActivity(now.Add(-24*time.Hour), 2)
+	if err != nil {
+		t.Fatalf("PruneChannelActivity failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("Expected the stale entry and the oldest overflow to be removed, removed %d", removed)
+	}
+	entries, err = s.ListChannelActivity("telegram-main", 10, 0)
+	if err != nil || len(entries) != 2 || entries[1].Snippet != "msg 1" {
+		t.Fatalf("Unexpected entries after pruning: %+v, %v", entries, err)
+	}
+	if entries, _ := s.ListChannelActivity("slack-main", 10, 0); len(entries) != 1 {
+		t.Errorf("Expected other channels to keep their entries, got %+v", entries)
+	}
+}
+
+func TestSkillStats(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	empty, err := s.GetSkillStats("weather")
+	if err != nil || empty.RunCount != 0 {
+		t.Fatalf("Expected zero stats for unused skill, got %+v, %v", empty, err)
+	}
+
+	for _, run := range []struct {
+		d   time.Duration
+		err string
+	}{{100 * time.Millisecond, ""}, {300 * time.Millisecond, "timeout"}, {200 * time.Millisecond, ""}, {200 * time.Millisecond, ""}} {
+		if err := s.RecordSkillRun("weather", run.d, run.err); err != nil {
+			t.Fatalf("RecordSkillRun failed: %v", err)
+		}
+	}
+
+	stats, err := s.GetSkillStats("weather")
+	if err != nil {
+		t.Fatalf("GetSkillStats failed: %v", err)
+	}
+	if stats.RunCount != 4 || stats.FailureCount != 1 || stats.AvgDurationMs != 200 || stats.FailureRate != 0.25 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.LastRunAt == nil || stats.LastError != "timeout" {
+		t.Errorf("Expected last run and last error to be kept, got %+v", stats)
+	}
+
+	all, err := s.ListSkillStats()
+	if err != nil || len(all) != 1 {
+		t.Fatalf("Expected one skill in list, got %v, %v", all, err)
+	}
+}
+
+func TestSessionStats(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	if _, err := s.GetSessionStats("missing"); err != sql.ErrNoRows {
+		t.Fatalf("Expected sql.ErrNoRows for unknown session, got %v", err)
+	}
+
+	sess, err := s.CreateSession("stats")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	for _, content := range []string{"hi", "hello"} {
+		if _, err := s.AddMessage(sess.ID, RoleUser, content); err != nil {
+			t.Fatalf("AddMessage failed: %v", err)
+		}
+	}
+	audit := []struct{ action, cost string }{
+		{"message.send", `{"input_tokens":100,"output_tokens":20,"total_tokens":120,"total_cost":0.5,"model":"gpt-4o"}`},
+		{"message.send", `{"input_tokens":10,"output_tokens":5,"total_tokens":15,"total_cost":0.25,"model":"claude-sonnet"}`},
+		{"message.send", `{"input_tokens":1,"output_tokens":1,"total_tokens":2,"total_cost":0,"model":"gpt-4o"}`},
+		{"tool.complete", "null"},
+		{"tool.error", ""},
+	}
+	for i, e := range audit {
+		if _, err := s.DB.Exec(`INSERT INTO audit_log (id, timestamp, session_id, action, cost) VALUES (?, ?, ?, ?, ?)`,
+			fmt.Sprintf("a%d", i), time.Now().UTC(), sess.ID, e.action, e.cost); err != nil {
+			t.Fatalf("insert audit entry: %v", err)
+		}
+	}
+	if _, err := s.DB.Exec(`INSERT INTO audit_log (id, timestamp, session_id, action, cost) VALUES ('other', ?, 'other-session', 'tool.complete', 'null')`, time.Now().UTC()); err != nil {
+		t.Fatalf("insert audit entry: %v", err)
+	}
+
+	stats, err := s.GetSessionStats(sess.ID)
+	if err != nil {
+		t.Fatalf("GetSessionStats failed: %v", err)
+	}
+	if stats.MessageCount != 2 || stats.ToolCalls != 2 {
+		t.Errorf("Unexpected counts: %+v", stats)
+	}
+	if stats.InputTokens != 111 || stats.OutputTokens != 26 || stats.TotalTokens != 137 || stats.TotalCost != 0.75 {
+		t.Errorf("Unexpected usage: %+v", stats)
+	}
+	if len(stats.Models) != 2 || stats.Models[0] != "claude-sonnet" || stats.Models[1] != "gpt-4o" {
+		t.Errorf("Unexpected models: %v", stats.Models)
+	}
+	if stats.LastActivityAt == nil || stats.DurationMs < 0 {
+		t.Errorf("Expected last activity to be set, got %+v", stats)
+	}
+}
+
+func TestAssistantMessageModel(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	sess, err := s.CreateSession("models")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if _, err := s.AddMessage(sess.ID, RoleUser, "compare"); err != nil {
+		t.Fatalf("AddMessage failed: %v", err)
+	}
+	for _, m := range []struct{ model, provider string }{
+		{"gpt-4o", "openai"},
+		{"claude-sonnet", "anthropic"},
+		{"gpt-4o", "openai"},
+	} {
+		if _, err := s.AddAssistantMessage(sess.ID, "answer", m.model, m.provider); err != nil {
+			t.Fatalf("AddAssistantMessage failed: %v", err)
+		}
+	}
+	if _, err := s.DB.Exec(`INSERT INTO audit_log (id, timestamp, session_id, action, cost) VALUES ('a1', ?, ?, 'message.send', ?)`,
+		time.Now().UTC(), sess.ID, `{"input_tokens":10,"output_tokens":4,"total_tokens":14,"total_cost":0.5,"model":"claude-sonnet"}`); err != nil {
+		t.Fatalf("insert audit entry: %v", err)
+	}
+
+	messages, err := s.GetMessages(sess.ID)
+	if err != nil || len(messages) != 4 {
+		t.Fatalf("Expected 4 messages, got %d, %v", len(messages), err)
+	}
+	if messages[0].Model != "" || messages[1].Model != "gpt-4o" || messages[1].Provider != "openai" || messages[2].Model != "claude-sonnet" {
+		t.Errorf("Unexpected message models: %+v %+v %+v", messages[0], messages[1], messages[2])
+	}
+
+	stats, err := s.GetSessionStats(sess.ID)
+	if err != nil {
+		t.Fatalf("GetSessionStats failed: %v", err)
+	}
+	want := []ModelUsage{
+		{Model: "claude-sonnet", Provider: "anthropic", Messages: 1, InputTokens: 10, OutputTokens: 4, TotalCost: 0.5},
+		{Model: "gpt-4o", Provider: "openai", Messages: 2},
+	}
+	if len(stats.ModelUsage) != len(want) || stats.ModelUsage[0] != want[0] || stats.ModelUsage[1] != want[1] {
+		t.Errorf("Unexpected model usage: %+v", stats.ModelUsage)
+	}
+	if len(stats.Models) != 2 || stats.Models[0] != "claude-sonnet" || stats.Models[1] != "gpt-4o" {
+		t.Errorf("Unexpected models: %v", stats.Models)
+	}
+
+	fork, err := s.CopySession(sess.ID, "copy")
+	if err != nil {
+		t.Fatalf("CopySession failed: %v", err)
+	}
+	copied, err := s.GetMessages(fork.ID)
+	if err != nil || len(copied) != 4 || copied[1].Model != "gpt-4o" || copied[1].Provider != "openai" {
+		t.Errorf("Expected the fork to keep message models, got %+v, %v", copied, err)
+	}
+}
+
+func TestSessionBudget(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	maxCost := 1.0
+	if err := s.SetSessionBudget("missing", SessionBudget{MaxCost: &maxCost}); err != sql.ErrNoRows {
+		t.Fatalf("Expected sql.ErrNoRows for unknown session, got %v", err)
+	}
+	if err := s.CheckSessionBudget("missing"); err != nil {
+		t.Fatalf("Expected no budget for unknown session, got %v", err)
+	}
+
+	sess, err := s.CreateSession("budget")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	if budget, err := s.GetSessionBudget(sess.ID); err != nil || !budget.Empty() {
+		t.Fatalf("Expected an empty budget, got %+v, %v", budget, err)
+	}
+	maxTokens := int64(1000)
+	if err := s.SetSessionBudget(sess.ID, SessionBudget{MaxCost: &maxCost, MaxTokens: &maxTokens}); err != nil {
+		t.Fatalf("SetSessionBudget failed: %v", err)
+	}
+	budget, err := s.GetSessionBudget(sess.ID)
+	if err != nil || budget.MaxCost == nil || *budget.MaxCost != 1.0 || budget.MaxTokens == nil || *budget.MaxTokens != 1000 {
+		t.Fatalf("Unexpected budget %+v, %v", budget, err)
+	}
+
+	addUsage := func(id, cost string) {
+		if _, err := s.DB.Exec(`INSERT INTO audit_log (id, timestamp, session_id, action, cost) VALUES (?, ?, ?, 'message.send', ?)`,
+			id, time.Now().UTC(), sess.ID, cost); err != nil {
+			t.Fatalf("insert audit entry: %v", err)
+		}
+	}
+	addUsage("a1", `{"total_tokens":400,"total_cost":0.6}`)
+	addUsage("a2", "null")
+	if err := s.CheckSessionBudget(sess.ID); err != nil {
+		t.Fatalf("Expected session within budget, got %v", err)
+	}
+
+	addUsage("a3", `{"total_tokens":100,"total_cost":0.4}`)
+	var exceeded *SessionBudgetExceededError
+	if err := s.CheckSessionBudget(sess.ID); !errors.As(err, &exceeded) {
+		t.Fatalf("Expected SessionBudgetExceededError, got %v", err)
+	}
+	if exceeded.Limit != "max_cost" || exceeded.Used != 1.0 || exceeded.Max != 1.0 {
+		t.Errorf("Unexpected exceeded error %+v", exceeded)
+	}
+
+	if err := s.SetSessionBudget(sess.ID, SessionBudget{}); err != nil {
+		t.Fatalf("SetSessionBudget failed: %v", err)
+	}
+	if err := s.CheckSessionBudget(sess.ID); err != nil {
+		t.Fatalf("Expected cleared budget to allow generations, got %v", err)
+	}
+}
+
+func TestSchemaInfo(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	info, err := s.SchemaInfo()
+	if err != nil {
+		t.Fatalf("SchemaInfo failed: %v", err)
+	}
+	if info.Version == 0 {
+		t.Error("Expected a non-zero schema version after migration")
+	}
+	found := false
+	for _, table := range info.Tables {
+		if table == "sessions" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected sessions table in %v", info.Tables)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	s, err := New(t.TempDir() + "/pryx.db")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	sess, err := s.CreateSession("Churned")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	content := strings.Repeat("x", 4096)
+	for i := 0; i < 200; i++ {
+		if _, err := s.AddMessage(sess.ID, RoleUser, content); err != nil {
+			t.Fatalf("Failed to add message: %v", err)
+		}
+	}
+	if err := s.DeleteSession(sess.ID); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+
+	result, err := s.Compact(context.Background())
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if result.FreePages == 0 || result.Reclaimed <= 0 || result.SizeAfter >= result.SizeBefore {
+		t.Errorf("expected the deleted messages to be reclaimed, got %+v", result)
+	}
+	if _, err := s.CreateSession("After"); err != nil {
+		t.Errorf("expected the store to stay usable after compacting: %v", err)
+	}
+}
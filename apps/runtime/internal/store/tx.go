@@ -0,0 +1,103 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tx is a store transaction. Multi-step writes go through it so a failure
+// part-way leaves no half-copied sessions or orphaned messages behind.
+type Tx struct {
+	*sql.Tx
+}
+
+// WithTx runs fn in a single transaction. The transaction is committed if fn
+// returns nil and rolled back if it returns an error or panics.
+func (s *Store) WithTx(fn func(*Tx) error) (err error) {
+	sqlTx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	tx := &Tx{Tx: sqlTx}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = sqlTx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = sqlTx.Rollback()
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	if err = sqlTx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// CreateSession inserts a new session within the transaction.
+func (tx *Tx) CreateSession(title string) (*Session, error) {
+	now := time.Now().UTC()
+	sess := &Session{
+		ID:        uuid.New().String(),
+		Title:     title,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	_, err := tx.Exec(`INSERT INTO sessions (id, title, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		sess.ID, sess.Title, sess.CreatedAt, sess.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// AddMessage inserts a message within the transaction. Unlike Store.AddMessage
+// it does not touch the session or trim old messages.
+func (tx *Tx) AddMessage(sessionID string, role Role, content string) (*Message, error) {
+	msg := &Message{
+		ID:        uuid.New().String(),
+		SessionID: sessionID,
+		Role:      role,
+		Content:   content,
+		CreatedAt: time.Now().UTC(),
+	}
+	_, err := tx.Exec(`INSERT INTO messages (id, session_id, role, content, created_at) VALUES (?, ?, ?, ?, ?)`,
+		msg.ID, msg.SessionID, msg.Role, msg.Content, msg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// TouchSession sets a session's updated_at to now.
+func (tx *Tx) TouchSession(sessionID string) error {
+	_, err := tx.Exec(`UPDATE sessions SET updated_at = ? WHERE id = ?`, time.Now().UTC(), sessionID)
+	return err
+}
+
+// DeleteSession removes a session and its messages. It returns sql.ErrNoRows
+// if the session does not exist.
+func (tx *Tx) DeleteSession(id string) error {
+	if _, err := tx.Exec(`DELETE FROM messages WHERE session_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM session_idempotency_keys WHERE session_id = ?`, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
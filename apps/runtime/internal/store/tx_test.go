@@ -0,0 +1,101 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithTxRollsBackOnError(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	boom := errors.New("boom")
+	var sessionID string
+	err = s.WithTx(func(tx *Tx) error {
+		sess, err := tx.CreateSession("Partial")
+		if err != nil {
+			return err
+		}
+		sessionID = sess.ID
+		if _, err := tx.AddMessage(sess.ID, RoleUser, "first"); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Expected closure error, got %v", err)
+	}
+
+	if _, err := s.GetSession(sessionID); err == nil {
+		t.Error("Expected session to be rolled back")
+	}
+	if n, _ := s.GetMessageCount(sessionID); n != 0 {
+		t.Errorf("Expected no messages after rollback, got %d", n)
+	}
+}
+
+func TestCopySessionFailureLeavesNoPartialCopy(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	src, _ := s.CreateSession("Source")
+	for _, content := range []string{"one", "two", "poison", "four"} {
+		if _, err := s.AddMessage(src.ID, RoleUser, content); err != nil {
+			t.Fatalf("Failed to add message: %v", err)
+		}
+	}
+
+	// Fail the copy part-way through, after the session and some messages are written.
+	_, err = s.DB.Exec(`CREATE TRIGGER fail_copy BEFORE INSERT ON messages
+		WHEN NEW.content = 'poison' BEGIN SELECT RAISE(ABORT, 'forced failure'); END`)
+	if err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+
+	if _, err := s.CopySession(src.ID, "Fork"); err == nil || !strings.Contains(err.Error(), "forced failure") {
+		t.Fatalf("Expected forced failure, got %v", err)
+	}
+
+	sessions, err := s.ListSessions()
+	if err != nil {
+		t.Fatalf("Failed to list sessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != src.ID {
+		t.Errorf("Expected only the source session, got %d sessions", len(sessions))
+	}
+	var total int
+	if err := s.DB.QueryRow(`SELECT COUNT(*) FROM messages`).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 {
+		t.Errorf("Expected 4 messages (source only), got %d", total)
+	}
+}
+
+func TestDeleteSessionRemovesMessages(t *testing.T) {
+	s, err := New(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	sess, _ := s.CreateSession("Doomed")
+	_, _ = s.AddMessage(sess.ID, RoleUser, "hello")
+
+	if err := s.DeleteSession(sess.ID); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+	if _, err := s.GetSession(sess.ID); err == nil {
+		t.Error("Expected session to be deleted")
+	}
+	if n, _ := s.GetMessageCount(sess.ID); n != 0 {
+		t.Errorf("Expected messages to be deleted, got %d", n)
+	}
+}
@@ -0,0 +1,163 @@
+// Package workspace provides a single root directory for files written by the runtime
+// and helpers that keep resolved paths inside it.
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Standard subdirectories of a workspace. Screen captures keep the captures
+// directory they were written to before the workspace existed.
+const (
+	DirSkills   = "skills"
+	DirMedia    = "media"
+	DirCache    = "cache"
+	DirExports  = "exports"
+	DirCaptures = "captures"
+)
+
+// StandardDirs lists the subdirectories created by EnsureDirs.
+var StandardDirs = []string{DirSkills, DirMedia, DirCache, DirExports, DirCaptures}
+
+// ErrOutsideRoot is returned when a path would resolve outside the workspace root.
+var ErrOutsideRoot = errors.New("path escapes workspace root")
+
+// Workspace is a directory tree rooted at a fixed absolute path.
+type Workspace struct {
+	root string
+}
+
+// New returns a workspace rooted at root. The root is made absolute but not created.
+func New(root string) (*Workspace, error) {
+	root = strings.TrimSpace(root)
+	if root == "" {
+		return nil, fmt.Errorf("workspace root is required")
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve workspace root: %w", err)
+	}
+	return &Workspace{root: filepath.Clean(abs)}, nil
+}
+
+// DefaultRoot returns the default workspace root.
+// It is $PRYX_WORKSPACE_ROOT/.pryx when that variable is set, otherwise ~/.pryx.
+func DefaultRoot() string {
+	if base := strings.TrimSpace(os.Getenv("PRYX_WORKSPACE_ROOT")); base != "" {
+		return filepath.Join(base, ".pryx")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || strings.TrimSpace(home) == "" {
+		return ".pryx"
+	}
+	return filepath.Join(home, ".pryx")
+}
+
+// Open returns the workspace at root, falling back to DefaultRoot when root is empty.
+func Open(root string) (*Workspace, error) {
+	if strings.TrimSpace(root) == "" {
+		root = DefaultRoot()
+	}
+	return New(root)
+}
+
+// Root returns the absolute workspace root.
+func (w *Workspace) Root() string {
+	return w.root
+}
+
+// Resolve joins relPath onto the root and rejects results that escape it,
+// including through symlinks inside the workspace. Absolute paths are
+// accepted only if they already lie inside the root.
+func (w *Workspace) Resolve(relPath string) (string, error) {
+	var candidate string
+	if filepath.IsAbs(relPath) {
+		candidate = filepath.Clean(relPath)
+	} else {
+		candidate = filepath.Join(w.root, relPath)
+	}
+	if !w.Contains(candidate) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRoot, relPath)
+	}
+
+	root, err := evalExisting(w.root)
+	if err != nil {
+		return "", fmt.Errorf("resolve workspace root: %w", err)
+	}
+	real, err := evalExisting(candidate)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", relPath, err)
+	}
+	if !within(root, real) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideRoot, relPath)
+	}
+	return candidate, nil
+}
+
+// Contains reports whether path lies inside the workspace root. It compares
+// the paths lexically; use Resolve to follow symlinks.
+func (w *Workspace) Contains(path string) bool {
+	return within(w.root, filepath.Clean(path))
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// evalExisting evaluates the symlinks in the longest existing prefix of path
+// and appends the rest, which does not exist yet and so cannot be a link.
+func evalExisting(path string) (string, error) {
+	real, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return real, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	realParent, err := evalExisting(parent)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(realParent, filepath.Base(path)), nil
+}
+
+// Dir returns the path of a subdirectory of the workspace.
+func (w *Workspace) Dir(name string) string {
+	return filepath.Join(w.root, name)
+}
+
+// EnsureDirs creates the root and all standard subdirectories.
+func (w *Workspace) EnsureDirs() error {
+	for _, name := range StandardDirs {
+		if err := os.MkdirAll(w.Dir(name), 0o755); err != nil {
+			return fmt.Errorf("create %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// CheckWritable verifies that files can be created in the workspace root.
+func (w *Workspace) CheckWritable() error {
+	if err := os.MkdirAll(w.root, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(w.root, ".write-check-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
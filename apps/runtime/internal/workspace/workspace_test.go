@@ -0,0 +1,99 @@
+package workspace
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	root := t.TempDir()
+	ws, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "relative", path: "media/a.png", want: filepath.Join(root, "media", "a.png")},
+		{name: "root", path: ".", want: root},
+		{name: "dot dot inside", path: "media/../cache/x", want: filepath.Join(root, "cache", "x")},
+		{name: "escape", path: "../outside", wantErr: true},
+		{name: "nested escape", path: "media/../../outside", wantErr: true},
+		{name: "absolute inside", path: filepath.Join(root, "exports"), want: filepath.Join(root, "exports")},
+		{name: "absolute outside", path: "/etc/passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ws.Resolve(tt.path)
+			if tt.wantErr {
+				if !errors.Is(err, ErrOutsideRoot) {
+					t.Fatalf("Resolve(%q) error = %v, want ErrOutsideRoot", tt.path, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRejectsSymlinkEscape(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	ws, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(root, DirCache), filepath.Join(root, "inner")); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	for _, path := range []string{"link", "link/secret", "link/new/file"} {
+		if _, err := ws.Resolve(path); !errors.Is(err, ErrOutsideRoot) {
+			t.Errorf("Resolve(%q) error = %v, want ErrOutsideRoot", path, err)
+		}
+	}
+	if got, err := ws.Resolve("inner/x"); err != nil || got != filepath.Join(root, "inner", "x") {
+		t.Errorf("Resolve(inner/x) = %q, %v", got, err)
+	}
+}
+
+func TestEnsureDirsAndWritable(t *testing.T) {
+	ws, err := New(filepath.Join(t.TempDir(), "ws"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := ws.EnsureDirs(); err != nil {
+		t.Fatalf("EnsureDirs() error = %v", err)
+	}
+	for _, name := range StandardDirs {
+		if info, err := os.Stat(ws.Dir(name)); err != nil || !info.IsDir() {
+			t.Errorf("expected directory %s to exist", name)
+		}
+	}
+	if err := ws.CheckWritable(); err != nil {
+		t.Errorf("CheckWritable() error = %v", err)
+	}
+}
+
+func TestDefaultRootHonorsEnv(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("PRYX_WORKSPACE_ROOT", base)
+	if got, want := DefaultRoot(), filepath.Join(base, ".pryx"); got != want {
+		t.Errorf("DefaultRoot() = %q, want %q", got, want)
+	}
+}
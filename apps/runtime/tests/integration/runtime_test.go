@@ -910,8 +910,8 @@ func TestWebSocketMultiMessageChat(t *testing.T) {
 	t.Log("Multi-message chat conversation completed successfully")
 }
 
-// TestWebSocketChatWithoutSession tests chat.send without session_id
-// Server handles gracefully - may create implicit session
+// TestWebSocketChatWithoutSession tests chat.send without session_id.
+// By default the server creates a session and emits session.created with its id.
 func TestWebSocketChatWithoutSession(t *testing.T) {
 	cfg := newWebSocketTestConfig()
 	s, _ := store.New(":memory:")
@@ -927,7 +927,7 @@ func TestWebSocketChatWithoutSession(t *testing.T) {
 	go srv.Serve(listener)
 	time.Sleep(10 * time.Millisecond)
 
-	wsURL := "ws://" + listener.Addr().String() + "/ws"
+	wsURL := "ws://" + listener.Addr().String() + "/ws?event=session.created"
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -948,10 +948,69 @@ func TestWebSocketChatWithoutSession(t *testing.T) {
 	err = ws.Write(ctx, websocket.MessageText, reqBytes)
 	require.NoError(t, err)
 
-	// Give server time to process
-	time.Sleep(50 * time.Millisecond)
+	created := readWebSocketEvent(t, ctx, ws, "session.created")
+	payload := created["payload"].(map[string]any)
+	sessionID, _ := payload["session_id"].(string)
+	require.NotEmpty(t, sessionID)
+	assert.Equal(t, sessionID, created["session_id"])
+	assert.Equal(t, "create", payload["behavior"])
+
+	sess, err := s.GetSession(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello without session!", sess.Title)
+}
+
+// TestWebSocketChatWithoutSessionRejected tests chat_without_session: reject.
+func TestWebSocketChatWithoutSessionRejected(t *testing.T) {
+	cfg := newWebSocketTestConfig()
+	cfg.ChatWithoutSession = config.ChatWithoutSessionReject
+	s, _ := store.New(":memory:")
+	defer s.Close()
+	kc := newTestKeychain(t)
+
+	srv := server.New(cfg, s.DB, kc)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go srv.Serve(listener)
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ws, _, err := websocket.Dial(ctx, "ws://"+listener.Addr().String()+"/ws", &websocket.DialOptions{})
+	require.NoError(t, err)
+	defer ws.Close(websocket.StatusNormalClosure, "test complete")
+
+	reqBytes, err := json.Marshal(map[string]any{
+		"event":   "chat.send",
+		"payload": map[string]any{"content": "Hello without session!"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, ws.Write(ctx, websocket.MessageText, reqBytes))
+
+	errEvt := readWebSocketEvent(t, ctx, ws, "error")
+	payload := errEvt["payload"].(map[string]any)
+	assert.Equal(t, "chat.session_required", payload["kind"])
+
+	sessions, err := s.ListSessions()
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
 
-	t.Log("Chat message sent without explicit session_id")
+// readWebSocketEvent reads messages until one with the given event name arrives.
+func readWebSocketEvent(t *testing.T, ctx context.Context, ws *websocket.Conn, event string) map[string]any {
+	t.Helper()
+	for {
+		_, data, err := ws.Read(ctx)
+		require.NoError(t, err)
+		var msg map[string]any
+		if json.Unmarshal(data, &msg) == nil && msg["event"] == event {
+			return msg
+		}
+	}
 }
 
 // TestWebSocketChatMessageFormat tests various chat message formats